@@ -2,6 +2,7 @@ package autonity
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"math/big"
 	"strings"
@@ -225,14 +226,45 @@ func (c *Cache) EpochPeriod() *big.Int {
 	return new(big.Int).Set(c.epochPeriod.Load())
 }
 
-func (c *AutonityContract) CommitteeEnodes(block *types.Block, db vm.StateDB, asACN bool) (*types.Nodes, error) {
-	return c.callGetCommitteeEnodes(db, block.Header(), asACN)
+// CommitteeEnodes returns the enodes of the committee, the invalid ones being skipped and reported in the
+// Errors of the result.
+func (c *AutonityContract) CommitteeEnodes(ctx context.Context, block *types.Block, db vm.StateDB, asACN bool) (*types.Nodes, error) {
+	return c.callGetCommitteeEnodes(ctx, db, block.Header(), asACN)
 }
 
 func (c *AutonityContract) Committee(header *types.Header, db vm.StateDB) ([]types.CommitteeMember, error) {
 	return c.callGetCommittee(db, header)
 }
 
+// Version returns the version of the deployed protocol contract.
+func (c *AutonityContract) Version(header *types.Header, db vm.StateDB) (*big.Int, error) {
+	return c.callGetVersion(db, header)
+}
+
+func (c *AutonityContract) MaxCommitteeSize(header *types.Header, db vm.StateDB) (*big.Int, error) {
+	return c.callGetMaxCommitteeSize(db, header)
+}
+
+// Validators returns the registered validators, in the order of the contract validator list.
+func (c *AutonityContract) Validators(header *types.Header, db vm.StateDB) ([]*AutonityValidator, error) {
+	addresses, err := c.callGetValidators(db, header)
+	if err != nil {
+		return nil, err
+	}
+	validators := make([]*AutonityValidator, len(addresses))
+	for i, address := range addresses {
+		if validators[i], err = c.callGetValidator(db, header, address); err != nil {
+			return nil, err
+		}
+	}
+	return validators, nil
+}
+
+// Validator returns the registration of the validator of the given node address.
+func (c *AutonityContract) Validator(header *types.Header, db vm.StateDB, address common.Address) (*AutonityValidator, error) {
+	return c.callGetValidator(db, header, address)
+}
+
 func (c *AutonityContract) MinimumBaseFee(block *types.Header, db vm.StateDB) (*big.Int, error) {
 	if block.Number.Uint64() <= 1 {
 		return new(big.Int).SetUint64(c.chainConfig.AutonityContractConfig.MinBaseFee), nil
@@ -247,6 +279,16 @@ func (c *AutonityContract) EpochPeriod(block *types.Header, db vm.StateDB) (*big
 	return c.callGetEpochPeriod(db, block)
 }
 
+// LastEpochBlock returns the block at which the current epoch started.
+func (c *AutonityContract) LastEpochBlock(header *types.Header, db vm.StateDB) (*big.Int, error) {
+	return c.callGetLastEpochBlock(db, header)
+}
+
+// EpochID returns the identifier of the current epoch.
+func (c *AutonityContract) EpochID(header *types.Header, db vm.StateDB) (*big.Int, error) {
+	return c.callEpochID(db, header)
+}
+
 func (c *AutonityContract) Proposer(header *types.Header, _ vm.StateDB, height uint64, round int64) (proposer common.Address) {
 	c.Lock()
 	defer c.Unlock()
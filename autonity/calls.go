@@ -1,10 +1,12 @@
 package autonity
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"reflect"
 
+	"github.com/autonity/autonity/accounts/abi"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/math"
 	"github.com/autonity/autonity/core/types"
@@ -551,13 +553,13 @@ func (c *EVMContract) CallContractFuncAs(statedb vm.StateDB, header *types.Heade
 	return packedResult, err
 }
 
-func (c *AutonityContract) callGetCommitteeEnodes(state vm.StateDB, header *types.Header, asACN bool) (*types.Nodes, error) {
+func (c *AutonityContract) callGetCommitteeEnodes(ctx context.Context, state vm.StateDB, header *types.Header, asACN bool) (*types.Nodes, error) {
 	var returnedEnodes []string
 	err := c.AutonityContractCall(state, header, "getCommitteeEnodes", &returnedEnodes)
 	if err != nil {
 		return nil, err
 	}
-	return types.NewNodes(returnedEnodes, asACN), nil
+	return types.NewNodes(ctx, returnedEnodes, asACN)
 }
 
 func (c *AutonityContract) callGetCommittee(state vm.StateDB, header *types.Header) ([]types.CommitteeMember, error) {
@@ -591,6 +593,63 @@ func (c *AutonityContract) callGetEpochPeriod(state vm.StateDB, header *types.He
 	return epochPeriod, nil
 }
 
+func (c *AutonityContract) callGetLastEpochBlock(state vm.StateDB, header *types.Header) (*big.Int, error) {
+	lastEpochBlock := new(big.Int)
+	err := c.AutonityContractCall(state, header, "getLastEpochBlock", &lastEpochBlock)
+	if err != nil {
+		return nil, err
+	}
+	return lastEpochBlock, nil
+}
+
+func (c *AutonityContract) callEpochID(state vm.StateDB, header *types.Header) (*big.Int, error) {
+	epochID := new(big.Int)
+	err := c.AutonityContractCall(state, header, "epochID", &epochID)
+	if err != nil {
+		return nil, err
+	}
+	return epochID, nil
+}
+
+func (c *AutonityContract) callGetVersion(state vm.StateDB, header *types.Header) (*big.Int, error) {
+	version := new(big.Int)
+	err := c.AutonityContractCall(state, header, "getVersion", &version)
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func (c *AutonityContract) callGetMaxCommitteeSize(state vm.StateDB, header *types.Header) (*big.Int, error) {
+	maxCommitteeSize := new(big.Int)
+	err := c.AutonityContractCall(state, header, "getMaxCommitteeSize", &maxCommitteeSize)
+	if err != nil {
+		return nil, err
+	}
+	return maxCommitteeSize, nil
+}
+
+func (c *AutonityContract) callGetValidators(state vm.StateDB, header *types.Header) ([]common.Address, error) {
+	var validators []common.Address
+	if err := c.AutonityContractCall(state, header, "getValidators", &validators); err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
+
+func (c *AutonityContract) callGetValidator(state vm.StateDB, header *types.Header, address common.Address) (*AutonityValidator, error) {
+	// the validator struct is returned as a single tuple, which UnpackIntoInterface can't copy into a struct
+	var ret raw
+	if err := c.AutonityContractCall(state, header, "getValidator", &ret, address); err != nil {
+		return nil, err
+	}
+	out, err := c.contractABI.Unpack("getValidator", ret)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ConvertType(out[0], new(AutonityValidator)).(*AutonityValidator), nil
+}
+
 func (c *AutonityContract) callFinalize(state vm.StateDB, header *types.Header) (bool, types.Committee, error) {
 	var updateReady bool
 	var committee types.Committee
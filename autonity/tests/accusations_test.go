@@ -67,7 +67,7 @@ func TestAccusation(t *testing.T) {
 	defer ctrl.Finish()
 	chainMock := accountability.NewMockChainContext(ctrl)
 	chainMock.EXPECT().GetHeaderByNumber(gomock.Any()).AnyTimes().Return(header)
-	accountability.LoadPrecompiles(chainMock)
+	accountability.LoadPrecompiles(chainMock, 0)
 
 	// setup current height
 	currentHeight := uint64(1024)
@@ -109,7 +109,7 @@ func TestAccusationTiming(t *testing.T) {
 	chainMock.EXPECT().GetHeaderByNumber(gomock.Any()).AnyTimes().Return(header)
 	// set value to not committed for all tests, here we want to really tests only the height related checks
 	chainMock.EXPECT().GetBlock(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
-	accountability.LoadPrecompiles(chainMock)
+	accountability.LoadPrecompiles(chainMock, 0)
 
 	currentHeight := uint64(1024) // height of current consensus instance
 	r.evm.Context.BlockNumber = new(big.Int).SetUint64(currentHeight)
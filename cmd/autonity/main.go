@@ -69,6 +69,10 @@ var (
 		utils.InitGenesisFlag,
 		utils.AncientFlag,
 		utils.MinFreeDiskSpaceFlag,
+		utils.DiskSoftFreeSpaceFlag,
+		utils.DiskHardFreeSpaceFlag,
+		utils.AutoResyncFlag,
+		utils.AutoResyncTimeoutFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
 		utils.NoUSBFlag,
@@ -85,11 +89,18 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolStrictMinBaseFeeFlag,
 		utils.SyncModeFlag,
+		utils.SnapSyncMinBlocksFlag,
+		utils.SnapSyncProtocolFirstFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.BloomBitsBlocksFlag,
+		utils.MeshHistoryEntriesFlag,
+		utils.CommitJournalEntriesFlag,
+		utils.CommitJournalAgeFlag,
 		utils.EthRequiredBlocksFlag,
 		utils.BloomFilterSizeFlag,
 		utils.CacheFlag,
@@ -114,6 +125,18 @@ var (
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerifyFlag,
+		utils.AccountabilityDeadlineMarginFlag,
+		utils.AccountabilityMaxFeeCapFlag,
+		utils.AccountabilityMaxSubmissionsPerHeightFlag,
+		utils.AccountabilityMaxSubmissionsPerEpochFlag,
+		utils.AccountabilityMsgStoreFlag,
+		utils.AccountabilityMsgStoreCapacityFlag,
+		utils.AccountabilityPersistMsgStoreFlag,
+		utils.AccountabilityLateMessageWindowFlag,
+		utils.AccountabilityMaxRescansPerHeightFlag,
+		utils.AccountabilityMaxOffChainMessagesFlag,
+		utils.AccountabilityOffChainWindowFlag,
+		utils.AccountabilityMaxOffChainViolationsFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -128,6 +151,7 @@ var (
 		utils.DeveloperGasLimitFlag,
 		utils.DeveloperEtherbaseFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMInternalCallTracingFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
 		utils.NoCompactionFlag,
@@ -141,6 +165,14 @@ var (
 		utils.ConsensusListenPortFlag,
 		utils.ConsensusNATFlag,
 		utils.NoGossip,
+		utils.ConsensusTraceSamplingFlag,
+		utils.ConsensusDebugFlag,
+		utils.ConsensusVoteFairnessFlag,
+		utils.ConsensusMessageQueueSizeFlag,
+		utils.ConsensusImportPacingFlag,
+		utils.ConsensusCompressionThresholdFlag,
+		utils.ConsensusProtocolVersionsFlag,
+		utils.ConsensusHandshakeTimeoutFlag,
 		configFileFlag,
 	}
 
@@ -167,6 +199,8 @@ var (
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
+		utils.RPCGlobalTxLookupRangeFlag,
+		utils.RPCRegistrationProbeFlag,
 		utils.AllowUnprotectedTxs,
 	}
 
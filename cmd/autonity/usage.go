@@ -39,16 +39,26 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.DataDirFlag,
 			utils.AncientFlag,
 			utils.MinFreeDiskSpaceFlag,
+			utils.DiskSoftFreeSpaceFlag,
+			utils.DiskHardFreeSpaceFlag,
+			utils.AutoResyncFlag,
+			utils.AutoResyncTimeoutFlag,
 			utils.KeyStoreDirFlag,
 			utils.USBFlag,
 			utils.SmartCardDaemonPathFlag,
 			utils.NetworkIdFlag,
 			utils.SyncModeFlag,
+			utils.SnapSyncMinBlocksFlag,
+			utils.SnapSyncProtocolFirstFlag,
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
 			utils.PiccadillyFlag,
 			utils.BakerlooFlag,
 			utils.TxLookupLimitFlag,
+			utils.BloomBitsBlocksFlag,
+			utils.MeshHistoryEntriesFlag,
+			utils.CommitJournalEntriesFlag,
+			utils.CommitJournalAgeFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
@@ -69,6 +79,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolStrictMinBaseFeeFlag,
 		},
 	},
 	{
@@ -118,6 +129,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.RPCGlobalGasCapFlag,
 			utils.RPCGlobalEVMTimeoutFlag,
 			utils.RPCGlobalTxFeeCapFlag,
+			utils.RPCGlobalTxLookupRangeFlag,
+			utils.RPCRegistrationProbeFlag,
 			utils.AllowUnprotectedTxs,
 			utils.JSpathFlag,
 			utils.ExecFlag,
@@ -143,6 +156,14 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ConsensusListenPortFlag,
 			utils.ConsensusNATFlag,
 			utils.NoGossip,
+			utils.ConsensusTraceSamplingFlag,
+			utils.ConsensusDebugFlag,
+			utils.ConsensusVoteFairnessFlag,
+			utils.ConsensusMessageQueueSizeFlag,
+			utils.ConsensusImportPacingFlag,
+			utils.ConsensusCompressionThresholdFlag,
+			utils.ConsensusProtocolVersionsFlag,
+			utils.ConsensusHandshakeTimeoutFlag,
 		},
 	},
 	{
@@ -159,6 +180,23 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerNoVerifyFlag,
 		},
 	},
+	{
+		Name: "ACCOUNTABILITY",
+		Flags: []cli.Flag{
+			utils.AccountabilityDeadlineMarginFlag,
+			utils.AccountabilityMaxFeeCapFlag,
+			utils.AccountabilityMaxSubmissionsPerHeightFlag,
+			utils.AccountabilityMaxSubmissionsPerEpochFlag,
+			utils.AccountabilityMsgStoreFlag,
+			utils.AccountabilityMsgStoreCapacityFlag,
+			utils.AccountabilityPersistMsgStoreFlag,
+			utils.AccountabilityLateMessageWindowFlag,
+			utils.AccountabilityMaxRescansPerHeightFlag,
+			utils.AccountabilityMaxOffChainMessagesFlag,
+			utils.AccountabilityOffChainWindowFlag,
+			utils.AccountabilityMaxOffChainViolationsFlag,
+		},
+	},
 	{
 		Name: "GAS PRICE ORACLE",
 		Flags: []cli.Flag{
@@ -172,6 +210,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
+			utils.VMInternalCallTracingFlag,
 		},
 	},
 	{
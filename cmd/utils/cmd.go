@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/diskusage"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
@@ -119,7 +120,7 @@ func StartNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 
 func monitorFreeDiskSpace(sigc chan os.Signal, path string, freeDiskSpaceCritical uint64) {
 	for {
-		freeSpace, err := getFreeDiskSpace(path)
+		freeSpace, err := diskusage.FreeSpace(path)
 		if err != nil {
 			log.Warn("Failed to get free disk space", "path", path, "err", err)
 			break
@@ -50,6 +50,7 @@ import (
 	"github.com/autonity/autonity/common/fdlimit"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/vm"
 	"github.com/autonity/autonity/crypto"
@@ -141,6 +142,25 @@ var (
 		Name:  "datadir.minfreedisk",
 		Usage: "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
 	}
+	DiskSoftFreeSpaceFlag = cli.Uint64Flag{
+		Name:  "datadir.softfreedisk",
+		Usage: "Free disk space in MB of the chain database below which the non-essential writes are paused (0 = disabled)",
+		Value: ethconfig.Defaults.DiskSoftFreeSpace,
+	}
+	DiskHardFreeSpaceFlag = cli.Uint64Flag{
+		Name:  "datadir.hardfreedisk",
+		Usage: "Free disk space in MB of the chain database below which the block import is suspended (0 = disabled)",
+		Value: ethconfig.Defaults.DiskHardFreeSpace,
+	}
+	AutoResyncFlag = cli.BoolFlag{
+		Name:  "autoresync",
+		Usage: "Rewind to the last verified epoch boundary and resync once the local state diverged from the network",
+	}
+	AutoResyncTimeoutFlag = cli.DurationFlag{
+		Name:  "autoresync.timeout",
+		Usage: "Time given to the automatic resync to recover before giving up",
+		Value: ethconfig.Defaults.AutoResyncTimeout,
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -212,6 +232,15 @@ var (
 		Usage: `Blockchain sync mode ("snap", "full" or "light")`,
 		Value: &defaultSyncMode,
 	}
+	SnapSyncMinBlocksFlag = cli.Uint64Flag{
+		Name:  "snapsync.minblocks",
+		Usage: "Minimum chain length to snap sync, shorter chains are full synced (0 = no minimum)",
+		Value: ethconfig.Defaults.SnapSyncMinBlocks,
+	}
+	SnapSyncProtocolFirstFlag = cli.BoolFlag{
+		Name:  "snapsync.protocolfirst",
+		Usage: "Snap sync the protocol contracts state first, to serve the aut APIs and the committee before the sync completes",
+	}
 	GCModeFlag = cli.StringFlag{
 		Name:  "gcmode",
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
@@ -226,6 +255,25 @@ var (
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value: ethconfig.Defaults.TxLookupLimit,
 	}
+	BloomBitsBlocksFlag = cli.Uint64Flag{
+		Name:  "bloombits.sectionsize",
+		Usage: "Number of blocks per log filtering index section, a power of two (default = derived from the block period)",
+	}
+	MeshHistoryEntriesFlag = cli.Uint64Flag{
+		Name:  "meshhistory.entries",
+		Usage: "Number of consensus mesh membership changes to keep for forensics (0 = disabled)",
+		Value: ethconfig.Defaults.MeshHistoryEntries,
+	}
+	CommitJournalEntriesFlag = cli.Uint64Flag{
+		Name:  "commitjournal.entries",
+		Usage: "Number of committed blocks to keep in the commit journal served to downstream services (0 = disabled)",
+		Value: ethconfig.Defaults.CommitJournalEntries,
+	}
+	CommitJournalAgeFlag = cli.DurationFlag{
+		Name:  "commitjournal.age",
+		Usage: "Maximum age of the commit journal entries, relative to the head block (0 = no limit)",
+		Value: ethconfig.Defaults.CommitJournalAge,
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -359,6 +407,10 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolStrictMinBaseFeeFlag = cli.BoolTFlag{
+		Name:  "txpool.strictminbasefee",
+		Usage: "Rejects transactions whose fee cap is below the protocol minimum base fee (default = enable)",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -433,7 +485,7 @@ var (
 	}
 	MinerExtraDataFlag = cli.StringFlag{
 		Name:  "miner.extradata",
-		Usage: "Block extra data set by the miner (default = client version)",
+		Usage: "Metadata appended to the client version in the block extra data set by the miner",
 	}
 	MinerRecommitIntervalFlag = cli.DurationFlag{
 		Name:  "miner.recommit",
@@ -464,6 +516,10 @@ var (
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
 	}
+	VMInternalCallTracingFlag = cli.BoolFlag{
+		Name:  "vmdebug.internalcalls",
+		Usage: "Trace the protocol contract calls made by the node itself, queryable with debug_internalCallTraces",
+	}
 	InsecureUnlockAllowedFlag = cli.BoolFlag{
 		Name:  "allow-insecure-unlock",
 		Usage: "Allow insecure account unlocking when account-related RPCs are exposed by http",
@@ -483,6 +539,15 @@ var (
 		Usage: "Sets a cap on transaction fee (in auton) that can be sent via the RPC APIs (0 = no cap)",
 		Value: ethconfig.Defaults.RPCTxFeeCap,
 	}
+	RPCGlobalTxLookupRangeFlag = cli.Uint64Flag{
+		Name:  "rpc.txlookuprange",
+		Usage: "Sets a cap on the number of blocks scanned by the ranged lookups of the unindexed transactions (0 = no cap)",
+		Value: ethconfig.Defaults.RPCTxLookupRange,
+	}
+	RPCRegistrationProbeFlag = cli.BoolFlag{
+		Name:  "rpc.registrationprobe",
+		Usage: "Allows aut_validateRegistration to dial the enode of the validator to check its reachability",
+	}
 	// Logging and debug settings
 	EthStatsURLFlag = cli.StringFlag{
 		Name:  "ethstats",
@@ -599,6 +664,40 @@ var (
 		Name:  "nogossip",
 		Usage: "disable consensus message gossip",
 	}
+	ConsensusTraceSamplingFlag = cli.Uint64Flag{
+		Name:  "consensus.tracesampling",
+		Usage: "Trace the processing of one in N consensus messages, queryable with debug_consensusTraces (0 = disabled)",
+	}
+	ConsensusDebugFlag = cli.BoolFlag{
+		Name:  "consensus.debug",
+		Usage: "Enable the debug RPC calls altering the consensus state, such as debug_retriggerProposal",
+	}
+	ConsensusVoteFairnessFlag = cli.Uint64Flag{
+		Name:  "consensus.votefairness",
+		Usage: "Maximum number of proposals processed in a row while consensus votes are waiting (0 = default of 8)",
+	}
+	ConsensusMessageQueueSizeFlag = cli.Uint64Flag{
+		Name:  "consensus.messagequeue",
+		Usage: "Maximum number of consensus messages queued, the oldest future height ones are dropped first (0 = default of 4096)",
+	}
+	ConsensusImportPacingFlag = cli.Uint64Flag{
+		Name:  "consensus.importpacing",
+		Usage: "Import backlog in blocks from which consensus delays the start of the next height (0 = disabled)",
+	}
+	ConsensusCompressionThresholdFlag = cli.Uint64Flag{
+		Name:  "consensus.compression.threshold",
+		Usage: "Size in bytes from which the consensus message payloads are compressed (0 = disabled)",
+		Value: node.DefaultConfig.ConsensusCompressionThreshold,
+	}
+	ConsensusProtocolVersionsFlag = cli.StringFlag{
+		Name:  "consensus.versions",
+		Usage: "Comma separated consensus network protocol versions to advertise, all the supported versions if empty (e.g. 1 to keep a network upgrade on the old version)",
+	}
+	ConsensusHandshakeTimeoutFlag = cli.DurationFlag{
+		Name:  "consensus.handshaketimeout",
+		Usage: "Deadline of the consensus protocol handshake, the connections not completing it in time are dropped",
+		Value: node.DefaultConfig.ConsensusHandshakeTimeout,
+	}
 	//Consensus Network settings
 	ConsensusListenPortFlag = cli.IntFlag{
 		Name:  "consensus.port",
@@ -702,6 +801,67 @@ var (
 		Value: ethconfig.Defaults.GPO.IgnorePrice.Int64(),
 	}
 
+	// Accountability settings
+	AccountabilityDeadlineMarginFlag = cli.Uint64Flag{
+		Name:  "accountability.deadlinemargin",
+		Usage: "Number of blocks before its deadline from which a pending accountability transaction is replaced with a higher fee",
+		Value: ethconfig.Defaults.Accountability.DeadlineMargin,
+	}
+	AccountabilityMaxFeeCapFlag = BigFlag{
+		Name:  "accountability.maxfeecap",
+		Usage: "Maximum fee cap per gas of the accountability transaction replacements",
+		Value: ethconfig.Defaults.Accountability.MaxFeeCap,
+	}
+	AccountabilityMaxSubmissionsPerHeightFlag = cli.Uint64Flag{
+		Name:  "accountability.maxsubmissionsperheight",
+		Usage: "Maximum number of accountability events submitted at each height, the others being queued (0 = no cap)",
+		Value: ethconfig.Defaults.Accountability.MaxSubmissionsPerHeight,
+	}
+	AccountabilityMaxSubmissionsPerEpochFlag = cli.Uint64Flag{
+		Name:  "accountability.maxsubmissionsperepoch",
+		Usage: "Maximum number of accountability events submitted in each epoch, the others being queued (0 = no cap)",
+		Value: ethconfig.Defaults.Accountability.MaxSubmissionsPerEpoch,
+	}
+	AccountabilityMsgStoreFlag = cli.StringFlag{
+		Name:  "accountability.msgstore",
+		Usage: `Consensus messages buffered by the fault detector: "full", "digest" (statistics only, no proof is built), or empty for digests while the node is not a registered validator`,
+		Value: ethconfig.Defaults.Accountability.MsgStoreMode,
+	}
+	AccountabilityMsgStoreCapacityFlag = cli.Uint64Flag{
+		Name:  "accountability.msgstore.capacity",
+		Usage: "Maximum number of consensus messages, or digests, buffered by the fault detector, the oldest heights being evicted beyond (0 = no limit)",
+		Value: ethconfig.Defaults.Accountability.MsgStoreCapacity,
+	}
+	AccountabilityPersistMsgStoreFlag = cli.BoolFlag{
+		Name:  "accountability.msgstore.persist",
+		Usage: "Persist the consensus messages buffered by the fault detector, for the accusations about the heights seen before a restart",
+	}
+	AccountabilityLateMessageWindowFlag = cli.Uint64Flag{
+		Name:  "accountability.latewindow",
+		Usage: "Number of heights behind the last height scanned for which a late consensus message is re-evaluated, older ones being dropped",
+		Value: ethconfig.Defaults.Accountability.LateMessageWindow,
+	}
+	AccountabilityMaxRescansPerHeightFlag = cli.Uint64Flag{
+		Name:  "accountability.maxrescans",
+		Usage: "Maximum number of re-evaluations of a height triggered by late consensus messages",
+		Value: ethconfig.Defaults.Accountability.MaxRescansPerHeight,
+	}
+	AccountabilityMaxOffChainMessagesFlag = cli.Uint64Flag{
+		Name:  "accountability.offchain.maxmessages",
+		Usage: "Maximum number of off-chain accountability messages accepted from a peer over the window, the others being dropped",
+		Value: ethconfig.Defaults.Accountability.MaxOffChainMessages,
+	}
+	AccountabilityOffChainWindowFlag = cli.DurationFlag{
+		Name:  "accountability.offchain.window",
+		Usage: "Sliding window over which the off-chain accountability messages of a peer are rate limited",
+		Value: ethconfig.Defaults.Accountability.OffChainMessageWindow,
+	}
+	AccountabilityMaxOffChainViolationsFlag = cli.Uint64Flag{
+		Name:  "accountability.offchain.maxviolations",
+		Usage: "Number of consecutive off-chain accountability messages over the rate limit after which a peer is disconnected",
+		Value: ethconfig.Defaults.Accountability.MaxOffChainViolations,
+	}
+
 	// Metrics flags
 	MetricsEnabledFlag = cli.BoolFlag{
 		Name:  "metrics",
@@ -1237,6 +1397,37 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(NoGossip.Name) {
 		cfg.NoGossip = ctx.GlobalBool(NoGossip.Name)
 	}
+	if ctx.GlobalIsSet(ConsensusTraceSamplingFlag.Name) {
+		cfg.ConsensusTraceSampling = ctx.GlobalUint64(ConsensusTraceSamplingFlag.Name)
+	}
+	if ctx.GlobalIsSet(ConsensusDebugFlag.Name) {
+		cfg.ConsensusDebug = ctx.GlobalBool(ConsensusDebugFlag.Name)
+	}
+	if ctx.GlobalIsSet(ConsensusVoteFairnessFlag.Name) {
+		cfg.ConsensusVoteFairness = ctx.GlobalUint64(ConsensusVoteFairnessFlag.Name)
+	}
+	if ctx.GlobalIsSet(ConsensusMessageQueueSizeFlag.Name) {
+		cfg.ConsensusMessageQueueSize = ctx.GlobalUint64(ConsensusMessageQueueSizeFlag.Name)
+	}
+	if ctx.GlobalIsSet(ConsensusImportPacingFlag.Name) {
+		cfg.ConsensusImportPacing = ctx.GlobalUint64(ConsensusImportPacingFlag.Name)
+	}
+	if ctx.GlobalIsSet(ConsensusCompressionThresholdFlag.Name) {
+		cfg.ConsensusCompressionThreshold = ctx.GlobalUint64(ConsensusCompressionThresholdFlag.Name)
+	}
+	if ctx.GlobalIsSet(ConsensusHandshakeTimeoutFlag.Name) {
+		cfg.ConsensusHandshakeTimeout = ctx.GlobalDuration(ConsensusHandshakeTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(ConsensusProtocolVersionsFlag.Name) {
+		cfg.ConsensusProtocolVersions = nil
+		for _, v := range SplitAndTrim(ctx.GlobalString(ConsensusProtocolVersionsFlag.Name)) {
+			version, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				Fatalf("Invalid consensus network protocol version %q: %v", v, err)
+			}
+			cfg.ConsensusProtocolVersions = append(cfg.ConsensusProtocolVersions, uint(version))
+		}
+	}
 	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
 	}
@@ -1355,6 +1546,48 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolStrictMinBaseFeeFlag.Name) {
+		cfg.StrictMinBaseFee = ctx.GlobalBool(TxPoolStrictMinBaseFeeFlag.Name)
+	}
+}
+
+func setAccountability(ctx *cli.Context, cfg *accountability.Config) {
+	if ctx.GlobalIsSet(AccountabilityDeadlineMarginFlag.Name) {
+		cfg.DeadlineMargin = ctx.GlobalUint64(AccountabilityDeadlineMarginFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMaxFeeCapFlag.Name) {
+		cfg.MaxFeeCap = GlobalBig(ctx, AccountabilityMaxFeeCapFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMaxSubmissionsPerHeightFlag.Name) {
+		cfg.MaxSubmissionsPerHeight = ctx.GlobalUint64(AccountabilityMaxSubmissionsPerHeightFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMaxSubmissionsPerEpochFlag.Name) {
+		cfg.MaxSubmissionsPerEpoch = ctx.GlobalUint64(AccountabilityMaxSubmissionsPerEpochFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMsgStoreFlag.Name) {
+		cfg.MsgStoreMode = ctx.GlobalString(AccountabilityMsgStoreFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMsgStoreCapacityFlag.Name) {
+		cfg.MsgStoreCapacity = ctx.GlobalUint64(AccountabilityMsgStoreCapacityFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityPersistMsgStoreFlag.Name) {
+		cfg.PersistMsgStore = ctx.GlobalBool(AccountabilityPersistMsgStoreFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityLateMessageWindowFlag.Name) {
+		cfg.LateMessageWindow = ctx.GlobalUint64(AccountabilityLateMessageWindowFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMaxRescansPerHeightFlag.Name) {
+		cfg.MaxRescansPerHeight = ctx.GlobalUint64(AccountabilityMaxRescansPerHeightFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMaxOffChainMessagesFlag.Name) {
+		cfg.MaxOffChainMessages = ctx.GlobalUint64(AccountabilityMaxOffChainMessagesFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityOffChainWindowFlag.Name) {
+		cfg.OffChainMessageWindow = ctx.GlobalDuration(AccountabilityOffChainWindowFlag.Name)
+	}
+	if ctx.GlobalIsSet(AccountabilityMaxOffChainViolationsFlag.Name) {
+		cfg.MaxOffChainViolations = ctx.GlobalUint64(AccountabilityMaxOffChainViolationsFlag.Name)
+	}
 }
 
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
@@ -1466,6 +1699,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setGPO(ctx, &cfg.GPO, ctx.GlobalString(SyncModeFlag.Name) == "light")
 	setTxPool(ctx, &cfg.TxPool)
 	setMiner(ctx, &cfg.Miner)
+	setAccountability(ctx, &cfg.Accountability)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
 
@@ -1521,6 +1755,36 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(BloomBitsBlocksFlag.Name) {
+		cfg.BloomBitsBlocks = ctx.GlobalUint64(BloomBitsBlocksFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapSyncMinBlocksFlag.Name) {
+		cfg.SnapSyncMinBlocks = ctx.GlobalUint64(SnapSyncMinBlocksFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapSyncProtocolFirstFlag.Name) {
+		cfg.SnapSyncProtocolFirst = ctx.GlobalBool(SnapSyncProtocolFirstFlag.Name)
+	}
+	if ctx.GlobalIsSet(MeshHistoryEntriesFlag.Name) {
+		cfg.MeshHistoryEntries = ctx.GlobalUint64(MeshHistoryEntriesFlag.Name)
+	}
+	if ctx.GlobalIsSet(CommitJournalEntriesFlag.Name) {
+		cfg.CommitJournalEntries = ctx.GlobalUint64(CommitJournalEntriesFlag.Name)
+	}
+	if ctx.GlobalIsSet(CommitJournalAgeFlag.Name) {
+		cfg.CommitJournalAge = ctx.GlobalDuration(CommitJournalAgeFlag.Name)
+	}
+	if ctx.GlobalIsSet(DiskSoftFreeSpaceFlag.Name) {
+		cfg.DiskSoftFreeSpace = ctx.GlobalUint64(DiskSoftFreeSpaceFlag.Name)
+	}
+	if ctx.GlobalIsSet(DiskHardFreeSpaceFlag.Name) {
+		cfg.DiskHardFreeSpace = ctx.GlobalUint64(DiskHardFreeSpaceFlag.Name)
+	}
+	if ctx.GlobalIsSet(AutoResyncFlag.Name) {
+		cfg.AutoResync = ctx.GlobalBool(AutoResyncFlag.Name)
+	}
+	if ctx.GlobalIsSet(AutoResyncTimeoutFlag.Name) {
+		cfg.AutoResyncTimeout = ctx.GlobalDuration(AutoResyncTimeoutFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
@@ -1552,6 +1816,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMInternalCallTracingFlag.Name) {
+		cfg.InternalCallTracing = ctx.GlobalBool(VMInternalCallTracingFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.GlobalUint64(RPCGlobalGasCapFlag.Name)
@@ -1567,6 +1834,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.GlobalFloat64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.GlobalIsSet(RPCGlobalTxLookupRangeFlag.Name) {
+		cfg.RPCTxLookupRange = ctx.GlobalUint64(RPCGlobalTxLookupRangeFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRegistrationProbeFlag.Name) {
+		cfg.RPCRegistrationProbe = ctx.GlobalBool(RPCRegistrationProbeFlag.Name)
+	}
 	if ctx.GlobalIsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.GlobalIsSet(DNSDiscoveryFlag.Name) {
@@ -1671,7 +1944,9 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 }
 
 func RegisterConsensusService(stack *node.Node, backend *eth.Ethereum, netID uint64) {
-	acn.New(stack, backend, netID)
+	if _, err := acn.New(stack, backend, netID); err != nil {
+		Fatalf("Failed to register the consensus network service: %v", err)
+	}
 }
 
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to
@@ -17,7 +17,8 @@
 //go:build !windows && !openbsd
 // +build !windows,!openbsd
 
-package utils
+// Package diskusage reads the free space of the file systems.
+package diskusage
 
 import (
 	"fmt"
@@ -25,7 +26,8 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-func getFreeDiskSpace(path string) (uint64, error) {
+// FreeSpace returns the space available to the process on the file system holding path.
+func FreeSpace(path string) (uint64, error) {
 	var stat unix.Statfs_t
 	if err := unix.Statfs(path, &stat); err != nil {
 		return 0, fmt.Errorf("failed to call Statfs: %v", err)
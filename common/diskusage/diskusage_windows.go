@@ -14,7 +14,7 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-package utils
+package diskusage
 
 import (
 	"fmt"
@@ -22,7 +22,8 @@ import (
 	"golang.org/x/sys/windows"
 )
 
-func getFreeDiskSpace(path string) (uint64, error) {
+// FreeSpace returns the space available to the process on the file system holding path.
+func FreeSpace(path string) (uint64, error) {
 
 	cwd, err := windows.UTF16PtrFromString(path)
 	if err != nil {
@@ -0,0 +1,120 @@
+// Package logthrottle deduplicates the log records of recurring anomalies.
+//
+// Some anomalies, e.g. the messages of a misbehaving peer, come in bursts of thousands of
+// occurrences which would otherwise drown the logs and slow the node down through the logging
+// alone. A Throttle logs the occurrences of an anomaly at most once per interval for each key,
+// the record following a suppressed burst summarizing it, while the metric of the anomaly
+// counts every occurrence.
+package logthrottle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/common/mclock"
+	"github.com/autonity/autonity/metrics"
+)
+
+// DefaultInterval is the interval at which an anomaly is logged at most once per key.
+const DefaultInterval = time.Minute
+
+// maxKeys bounds the keys tracked by a throttle. Once reached, the occurrences of the new keys are
+// suppressed until the keys not logged for an interval are dropped.
+const maxKeys = 1024
+
+// Summary is the burst of occurrences suppressed since the last record logged for a key.
+type Summary struct {
+	Suppressed uint64
+	Span       time.Duration // time elapsed since the last record logged for the key
+}
+
+// Annotate appends the summary to the message of the record logged, unless nothing was suppressed.
+func (s Summary) Annotate(msg string) string {
+	if s.Suppressed == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s …and %d similar in the last %ds", msg, s.Suppressed, int64(s.Span.Round(time.Second)/time.Second))
+}
+
+type entry struct {
+	logged     mclock.AbsTime
+	suppressed uint64
+}
+
+// Throttle logs the occurrences of an anomaly at most once per interval for each key, the key holding
+// the fields telling the occurrences apart, e.g. the sender of a message. It is safe for concurrent use.
+//
+// The occurrences are recorded with Allow, the record being logged only if it returns true:
+//
+//	if summary, ok := throttle.Allow(sender); ok {
+//		logger.Warn(summary.Annotate("Message from unknown peer"), "sender", sender)
+//	}
+//
+// so that the suppressed occurrences do not pay for building the record.
+type Throttle[K comparable] struct {
+	interval time.Duration
+	counter  metrics.Counter
+	clock    mclock.Clock
+
+	mu      sync.Mutex
+	entries map[K]entry
+	expired mclock.AbsTime // last time the keys were expired
+}
+
+// New creates a throttle logging an anomaly at most once per interval for each key, every occurrence
+// being counted by the metric of the given name.
+func New[K comparable](metric string, interval time.Duration) *Throttle[K] {
+	return &Throttle[K]{
+		interval: interval,
+		counter:  metrics.NewRegisteredCounter(metric, nil),
+		clock:    mclock.System{},
+		entries:  make(map[K]entry),
+	}
+}
+
+// Allow records an occurrence of the anomaly for key and reports whether it is to be logged, along with
+// the summary of the occurrences suppressed since the last record logged for the key.
+func (t *Throttle[K]) Allow(key K) (Summary, bool) {
+	t.counter.Inc(1)
+	now := t.clock.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		if len(t.entries) >= maxKeys && !t.expire(now) {
+			return Summary{}, false
+		}
+		t.entries[key] = entry{logged: now}
+		return Summary{}, true
+	}
+	if elapsed := time.Duration(now - e.logged); elapsed < t.interval {
+		e.suppressed++
+		t.entries[key] = e
+		return Summary{}, false
+	}
+	t.entries[key] = entry{logged: now}
+	return Summary{Suppressed: e.suppressed, Span: time.Duration(now - e.logged)}, true
+}
+
+// expire drops the keys not logged for an interval and reports whether a key can be tracked again.
+// Their suppressed occurrences are no longer summarized, they are still counted by the metric. The
+// keys are walked at most once per interval, a burst of new keys costing no more than a lookup.
+func (t *Throttle[K]) expire(now mclock.AbsTime) bool {
+	if time.Duration(now-t.expired) < t.interval {
+		return false
+	}
+	t.expired = now
+	for key, e := range t.entries {
+		if time.Duration(now-e.logged) >= t.interval {
+			delete(t.entries, key)
+		}
+	}
+	return len(t.entries) < maxKeys
+}
+
+// Count returns the occurrences of the anomaly counted by its metric.
+func (t *Throttle[K]) Count() int64 {
+	return t.counter.Count()
+}
@@ -0,0 +1,174 @@
+package logthrottle
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/mclock"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+)
+
+func newTestThrottle[K comparable](interval time.Duration) (*Throttle[K], *mclock.Simulated) {
+	clock := new(mclock.Simulated)
+	throttle := New[K]("test/anomaly", interval)
+	throttle.clock = clock
+	// the metrics are disabled in tests, swap the counter for a working one
+	throttle.counter = metrics.NewCounterForced()
+	return throttle, clock
+}
+
+// recordingLogger returns a logger keeping the messages of the records logged.
+func recordingLogger() (log.Logger, func() []string) {
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+	logger := log.New()
+	logger.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, r.Msg)
+		return nil
+	}))
+	return logger, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), messages...)
+	}
+}
+
+func TestSummaryAnnotate(t *testing.T) {
+	require.Equal(t, "Message from unknown peer", Summary{}.Annotate("Message from unknown peer"))
+	require.Equal(t, "Message from unknown peer …and 4821 similar in the last 60s",
+		Summary{Suppressed: 4821, Span: 60*time.Second + 300*time.Millisecond}.Annotate("Message from unknown peer"))
+}
+
+func TestThrottleSuppression(t *testing.T) {
+	throttle, clock := newTestThrottle[common.Address](time.Minute)
+	a, b := common.Address{0xa}, common.Address{0xb}
+
+	summary, ok := throttle.Allow(a)
+	require.True(t, ok)
+	require.Zero(t, summary)
+	// the keys are throttled independently
+	_, ok = throttle.Allow(b)
+	require.True(t, ok)
+
+	for i := 0; i < 5; i++ {
+		clock.Run(10 * time.Second)
+		_, ok = throttle.Allow(a)
+		require.False(t, ok, "logged %v after the previous record", clock.Now())
+	}
+	clock.Run(10 * time.Second)
+	summary, ok = throttle.Allow(a)
+	require.True(t, ok)
+	require.Equal(t, Summary{Suppressed: 5, Span: time.Minute}, summary)
+
+	// b was logged an interval ago without any occurrence in between
+	summary, ok = throttle.Allow(b)
+	require.True(t, ok)
+	require.Zero(t, summary.Suppressed)
+	require.Equal(t, int64(9), throttle.Count())
+}
+
+func TestThrottleBurst(t *testing.T) {
+	const (
+		events   = 10_000
+		interval = 60 * time.Second
+		step     = 30 * time.Millisecond // the burst lasts 5 minutes
+	)
+	throttle, clock := newTestThrottle[common.Address](interval)
+	logger, messages := recordingLogger()
+	sender := common.Address{0x1}
+
+	for i := 0; i < events; i++ {
+		if summary, ok := throttle.Allow(sender); ok {
+			logger.Warn(summary.Annotate("Consensus message from a non-committee member"), "sender", sender)
+		}
+		clock.Run(step)
+	}
+
+	// one record per interval, each summarizing the burst suppressed since the previous one
+	require.Equal(t, []string{
+		"Consensus message from a non-committee member",
+		"Consensus message from a non-committee member …and 1999 similar in the last 60s",
+		"Consensus message from a non-committee member …and 1999 similar in the last 60s",
+		"Consensus message from a non-committee member …and 1999 similar in the last 60s",
+		"Consensus message from a non-committee member …and 1999 similar in the last 60s",
+	}, messages())
+	// every occurrence is counted, logged or not
+	require.Equal(t, int64(events), throttle.Count())
+}
+
+func TestThrottleConcurrentBurst(t *testing.T) {
+	const (
+		workers = 10
+		events  = 10_000
+	)
+	throttle, _ := newTestThrottle[common.Address](time.Minute)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		logged = make(map[common.Address]int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < events/workers; i++ {
+				sender := common.Address{byte(i % 4)}
+				if _, ok := throttle.Allow(sender); ok {
+					mu.Lock()
+					logged[sender]++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the clock did not move, each sender is logged once
+	require.Len(t, logged, 4)
+	for sender, n := range logged {
+		require.Equal(t, 1, n, "sender %v", sender)
+	}
+	require.Equal(t, int64(events), throttle.Count())
+}
+
+func TestThrottleMaxKeys(t *testing.T) {
+	throttle, clock := newTestThrottle[int](time.Minute)
+	clock.Run(time.Minute)
+	for key := 0; key < maxKeys; key++ {
+		_, ok := throttle.Allow(key)
+		require.True(t, ok)
+	}
+	// the new keys are suppressed until the tracked ones expire
+	_, ok := throttle.Allow(maxKeys)
+	require.False(t, ok)
+	clock.Run(time.Minute)
+	_, ok = throttle.Allow(maxKeys)
+	require.True(t, ok)
+	require.Len(t, throttle.entries, 1)
+	require.Equal(t, int64(maxKeys+2), throttle.Count())
+}
+
+func TestThrottleSuppressedAllocations(t *testing.T) {
+	type key struct {
+		sender common.Address
+		height uint64
+	}
+	throttle, _ := newTestThrottle[key](time.Minute)
+	k := key{sender: common.Address{0x1}, height: 7}
+	throttle.Allow(k)
+	allocs := testing.AllocsPerRun(1000, func() {
+		if _, ok := throttle.Allow(k); ok {
+			t.Fatal("occurrence not suppressed")
+		}
+	})
+	require.Zero(t, allocs)
+}
@@ -0,0 +1,26 @@
+package randutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sequence(source Source) []int64 {
+	var seq []int64
+	for i := 0; i < 16; i++ {
+		seq = append(seq, int64(source.Intn(100)), source.Int63n(1<<40))
+	}
+	perm := []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	source.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+	return append(seq, perm...)
+}
+
+func TestSeededSourceIsDeterministic(t *testing.T) {
+	require.Equal(t, sequence(NewSeeded(42)), sequence(NewSeeded(42)))
+	require.NotEqual(t, sequence(NewSeeded(42)), sequence(NewSeeded(43)))
+}
+
+func TestDefaultSourcesDiffer(t *testing.T) {
+	require.NotEqual(t, sequence(New()), sequence(New()))
+}
@@ -0,0 +1,145 @@
+// Package rotatingbloom implements a bloom filter of message hashes rotated on height change, to track the
+// consensus messages known to a peer in a fixed memory.
+package rotatingbloom
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/autonity/autonity/common"
+)
+
+const (
+	// DefaultCapacity is the number of hashes of a height held at the false positive rate below.
+	DefaultCapacity = 2048
+	// DefaultFalsePositiveRate is the probability that a hash is reported known while it is not.
+	DefaultFalsePositiveRate = 0.001
+)
+
+// generation is the bloom filter of the hashes of a height.
+type generation struct {
+	bits   []uint64
+	height uint64
+	count  int
+}
+
+func (g *generation) reset(height uint64) {
+	clear(g.bits)
+	g.height, g.count = height, 0
+}
+
+// Filter holds the hashes of the current and the previous heights, in two generations. A hash is added to
+// the generation of its height: a newer height rotates the filter, the current generation becoming the
+// previous one and the oldest being cleared, so that the hashes of the old heights do not fill the filter
+// and raise false positives on the current ones. A generation filled beyond its capacity rotates early.
+//
+// The hashes are expected to be uniformly distributed, such as keccak hashes, the bit positions are derived
+// from them without hashing them again.
+type Filter struct {
+	mu       sync.Mutex
+	current  generation
+	previous generation
+	capacity int
+	hashes   int // bit positions per hash
+}
+
+// New returns a filter holding capacity hashes per height at the given false positive rate.
+func New(capacity int, falsePositiveRate float64) *Filter {
+	if capacity < 1 {
+		capacity = DefaultCapacity
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = DefaultFalsePositiveRate
+	}
+	bits := math.Ceil(-float64(capacity) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	words := int(math.Ceil(bits / 64))
+	hashes := int(math.Round(float64(words*64) / float64(capacity) * math.Ln2))
+	return &Filter{
+		current:  generation{bits: make([]uint64, words)},
+		previous: generation{bits: make([]uint64, words)},
+		capacity: capacity,
+		hashes:   max(hashes, 1),
+	}
+}
+
+// NewDefault returns a filter of the default capacity and false positive rate.
+func NewDefault() *Filter {
+	return New(DefaultCapacity, DefaultFalsePositiveRate)
+}
+
+// Add records the hash of a message of the given height.
+func (f *Filter) Add(hash common.Hash, height uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	g := &f.current
+	switch {
+	case height < f.current.height:
+		// the late messages of the previous height, the older ones are not tracked any more
+		if height != f.previous.height {
+			return
+		}
+		g = &f.previous
+	case height > f.current.height || f.current.count >= f.capacity:
+		f.rotate(height)
+	}
+	h1, h2 := positions(hash)
+	size := uint64(len(g.bits)) * 64
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % size
+		g.bits[bit/64] |= 1 << (bit % 64)
+	}
+	g.count++
+}
+
+// Contains reports whether the hash was added, of the current or the previous height. It can report a hash
+// known while it was not, at the false positive rate of the filter.
+func (f *Filter) Contains(hash common.Hash) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h1, h2 := positions(hash)
+	return f.current.contains(h1, h2, f.hashes) || f.previous.contains(h1, h2, f.hashes)
+}
+
+func (g *generation) contains(h1, h2 uint64, hashes int) bool {
+	if g.count == 0 {
+		return false
+	}
+	size := uint64(len(g.bits)) * 64
+	for i := 0; i < hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % size
+		if g.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rotate moves the filter to height. The current generation is kept as the previous one if it holds the
+// hashes of the height right before, or of height itself when rotating early.
+func (f *Filter) rotate(height uint64) {
+	if height <= f.current.height+1 {
+		f.current, f.previous = f.previous, f.current
+	} else {
+		f.previous.reset(height - 1)
+	}
+	f.current.reset(height)
+}
+
+// Height returns the height of the current generation.
+func (f *Filter) Height() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.current.height
+}
+
+// Size returns the memory held by the bits of the filter, in bytes.
+func (f *Filter) Size() int {
+	return (len(f.current.bits) + len(f.previous.bits)) * 8
+}
+
+// positions derives the base and the step of the double hashing of the bit positions from the hash.
+func positions(hash common.Hash) (uint64, uint64) {
+	return binary.LittleEndian.Uint64(hash[:8]), binary.LittleEndian.Uint64(hash[8:16]) | 1
+}
@@ -0,0 +1,114 @@
+package rotatingbloom
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+)
+
+func randomHashes(n int) []common.Hash {
+	hashes := make([]common.Hash, n)
+	for i := range hashes {
+		rand.Read(hashes[i][:]) //nolint
+	}
+	return hashes
+}
+
+func TestFilterFalsePositiveRate(t *testing.T) {
+	f := NewDefault()
+	for _, hash := range randomHashes(DefaultCapacity) {
+		f.Add(hash, 1)
+		require.True(t, f.Contains(hash))
+	}
+	falsePositives := 0
+	probes := 100000
+	for _, hash := range randomHashes(probes) {
+		if f.Contains(hash) {
+			falsePositives++
+		}
+	}
+	// a margin over the expected rate, for the randomness of the probes
+	require.Less(t, float64(falsePositives)/float64(probes), 3*DefaultFalsePositiveRate)
+}
+
+func TestFilterRotation(t *testing.T) {
+	f := New(64, 0.001)
+	first, second, third := randomHashes(10), randomHashes(10), randomHashes(10)
+	for _, hash := range first {
+		f.Add(hash, 1)
+	}
+	for _, hash := range second {
+		f.Add(hash, 2)
+	}
+	require.Equal(t, uint64(2), f.Height())
+
+	// the previous height is kept, and its late messages still recorded
+	late := randomHashes(1)[0]
+	f.Add(late, 1)
+	for _, hash := range append(first, late) {
+		require.True(t, f.Contains(hash))
+	}
+	// the older ones are not tracked
+	older := randomHashes(1)[0]
+	f.Add(older, 0)
+	require.False(t, f.Contains(older))
+
+	for _, hash := range third {
+		f.Add(hash, 3)
+	}
+	for _, hash := range append(first, late) {
+		require.False(t, f.Contains(hash))
+	}
+	for _, hash := range append(second, third...) {
+		require.True(t, f.Contains(hash))
+	}
+
+	// a jump of heights clears both generations
+	f.Add(randomHashes(1)[0], 10)
+	for _, hash := range append(second, third...) {
+		require.False(t, f.Contains(hash))
+	}
+	require.Equal(t, uint64(10), f.Height())
+}
+
+func TestFilterEarlyRotation(t *testing.T) {
+	f := New(16, 0.001)
+	full := randomHashes(16)
+	for _, hash := range full {
+		f.Add(hash, 5)
+	}
+	// beyond its capacity, the height spans both generations
+	more := randomHashes(16)
+	for _, hash := range more {
+		f.Add(hash, 5)
+	}
+	require.Equal(t, uint64(5), f.Height())
+	for _, hash := range append(full, more...) {
+		require.True(t, f.Contains(hash))
+	}
+	f.Add(randomHashes(1)[0], 5)
+	for _, hash := range full {
+		require.False(t, f.Contains(hash))
+	}
+}
+
+// BenchmarkFilterMemory reports the memory held by the filters of 100 peers, to compare with the previous
+// per-peer hash caches.
+func BenchmarkFilterMemory(b *testing.B) {
+	hashes := randomHashes(DefaultCapacity)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		size := 0
+		for i := 0; i < 100; i++ {
+			f := NewDefault()
+			for _, hash := range hashes {
+				f.Add(hash, 1)
+			}
+			size += f.Size()
+		}
+		b.ReportMetric(float64(size), "bytes/100peers")
+	}
+}
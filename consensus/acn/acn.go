@@ -20,6 +20,7 @@ import (
 	"context"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/autonity/autonity/consensus/acn/protocol"
 	"github.com/autonity/autonity/eth"
@@ -36,27 +37,48 @@ import (
 )
 
 type ACN struct {
-	networkID  uint64
-	peers      *peerSet
-	chain      *core.BlockChain
-	wg         sync.WaitGroup
-	forkFilter forkid.Filter // Fork ID filter, constant across the lifetime of the node
-	server     *p2p.Server
-	log        log.Logger
-	address    common.Address
-	cancel     context.CancelFunc
+	networkID   uint64
+	peers       *peerSet
+	chain       *core.BlockChain
+	wg          sync.WaitGroup
+	forkFilter  forkid.Filter // Fork ID filter, constant across the lifetime of the node
+	server      *p2p.Server
+	meshHistory *p2p.MeshHistory
+	compression protocol.CompressionConfig
+	identity    *protocol.Identity
+	versions    []uint // advertised `acn` versions, in order of preference
+	versionMix  versionMix
+	timeouts    handshakeTimeouts
+	log         log.Logger
+	address     common.Address
+	cancel      context.CancelFunc
+
+	handshakeTimeout time.Duration // deadline of the `acn` handshake, protocol.DefaultHandshakeTimeout if zero
 }
 
-func New(stack *node.Node, backend *eth.Ethereum, netID uint64) {
-	nodeKey, _ := stack.Config().AutonityKeys()
+func New(stack *node.Node, backend *eth.Ethereum, netID uint64) (*ACN, error) {
+	versions, err := protocol.AdvertisedVersions(stack.Config().ConsensusProtocolVersions)
+	if err != nil {
+		return nil, err
+	}
+	nodeKey, consensusKey := stack.Config().AutonityKeys()
 	acn := &ACN{
-		peers:      newPeerSet(),
-		chain:      backend.BlockChain(),
-		networkID:  netID,
-		forkFilter: forkid.NewFilter(backend.BlockChain()),
-		server:     stack.ConsensusServer(),
-		log:        log.New(),
-		address:    crypto.PubkeyToAddress(nodeKey.PublicKey),
+		peers:       newPeerSet(),
+		chain:       backend.BlockChain(),
+		networkID:   netID,
+		forkFilter:  forkid.NewFilter(backend.BlockChain()),
+		server:      stack.ConsensusServer(),
+		meshHistory: backend.MeshHistory(),
+		compression: protocol.NewCompressionConfig(stack.Config().ConsensusCompressionThreshold),
+		versions:    versions,
+		log:         log.New(),
+		address:     crypto.PubkeyToAddress(nodeKey.PublicKey),
+	}
+	acn.handshakeTimeout = stack.Config().ConsensusHandshakeTimeout
+	acn.identity = &protocol.Identity{
+		Self:         enode.PubkeyToIDV4(&nodeKey.PublicKey),
+		ConsensusKey: consensusKey,
+		Registered:   acn.registeredConsensusKey,
 	}
 
 	acn.server.MaxPeers = math.MaxInt
@@ -67,17 +89,19 @@ func New(stack *node.Node, backend *eth.Ethereum, netID uint64) {
 	}
 	// once p2p protocol handler is initialized, set it for accountability module for the off-chain accountability protocol.
 	backend.FD().SetBroadcaster(acn)
+	return acn, nil
 }
 
 func (acn *ACN) Start() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	acn.watchCommittee(ctx)
+	acn.sweepIdleStreams(ctx)
 	acn.cancel = cancel
 	return nil
 }
 
 func (acn *ACN) Protocols() []p2p.Protocol {
-	protos := protocol.MakeProtocols(acn, acn.networkID)
+	protos := protocol.MakeProtocols(acn, acn.networkID, acn.versions)
 	return protos
 }
 
@@ -95,10 +119,13 @@ func (acn *ACN) runConsensusPeer(peer *protocol.Peer, handler protocol.HandlerFu
 	acn.wg.Add(1)
 	defer acn.wg.Done()
 
+	// the connection is visible from the start of the handshake
+	acn.peers.connect(peer)
+	defer acn.peers.disconnect(peer)
+
 	genesis := acn.chain.Genesis()
 	forkID := forkid.NewID(acn.chain.Config(), acn.chain.Genesis().Hash(), acn.chain.CurrentHeader().Number.Uint64())
-	if err := peer.Handshake(acn.networkID, genesis.Hash(), forkID, acn.forkFilter); err != nil {
-		peer.Log().Debug("Consensus handshake failed", "err", err)
+	if err := acn.handshake(peer, genesis.Hash(), forkID); err != nil {
 		return err
 	}
 
@@ -126,15 +153,29 @@ func (acn *ACN) Stop() error {
 
 func (acn *ACN) Chain() *core.BlockChain { return acn.chain }
 
+// CommitteeVersionMix returns the number of connected committee peers by negotiated `acn` version,
+// for each advertised version. It is refreshed at every chain head.
+func (acn *ACN) CommitteeVersionMix() map[uint]int {
+	return acn.versionMix.get()
+}
+
+// HandshakeTimeouts returns the number of `acn` handshakes timed out by remote ID.
+func (acn *ACN) HandshakeTimeouts() map[enode.ID]uint64 {
+	return acn.timeouts.get()
+}
+
 // RunPeer is invoked when a peer joins on the `acn` protocol.
 func (acn *ACN) RunPeer(peer *protocol.Peer, hand protocol.HandlerFunc) error {
 	return acn.runConsensusPeer(peer, hand)
 }
 
-// PeerInfo retrieves all known `acn` information about a peer.
+// PeerInfo retrieves all known `acn` information about a peer, including its handshake
+// while in progress.
 func (acn *ACN) PeerInfo(id enode.ID) interface{} {
-	if p, ok := acn.peers.peerByID(id); ok {
-		return p.ConsensusPeerInfo()
+	if p, ok := acn.peers.conn(id); ok {
+		info := p.ConsensusPeerInfo()
+		info.HandshakeTimeouts = acn.timeouts.count(id)
+		return info
 	}
 	return nil
 }
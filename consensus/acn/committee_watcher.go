@@ -5,6 +5,7 @@ import (
 
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/p2p"
 )
 
 // TODO: watch for epoch rotation event instead
@@ -13,23 +14,31 @@ func (acn *ACN) watchCommittee(ctx context.Context) {
 
 	chainHeadCh := make(chan core.ChainHeadEvent)
 	chainHeadSub := acn.chain.SubscribeChainHeadEvent(chainHeadCh)
+	enodesUpdater := p2p.NewConsensusEnodesUpdater(acn.server)
+	enodesUpdater.SetRecorder(acn.meshHistory.Recorder(acn.server))
+	// the state reads are given up after repeated failures, until the head root changes
+	states := core.NewStateGuard("acn committee watcher", acn.chain.StateAt, core.DefaultStateGuardFailures, acn.log)
 
 	updateConsensusEnodes := func(block *types.Block) {
-		state, err := acn.chain.StateAt(block.Header().Root)
+		state, err := states.StateAt(block.Header().Root)
 		if err != nil {
-			acn.log.Error("Could not retrieve state at head block", "err", err)
 			return
 		}
-		enodesList, err := acn.chain.ProtocolContracts().CommitteeEnodes(block, state, true)
+		enodesList, err := acn.chain.ProtocolContracts().CommitteeEnodes(ctx, block, state, true)
 		if err != nil {
 			acn.log.Error("Could not retrieve consensus whitelist at head block", "err", err)
 			return
 		}
-		acn.server.UpdateConsensusEnodes(enodesList.List, enodesList.List)
+		// the skipped enodes are logged by the execution layer
+		for _, err := range enodesList.Errors {
+			acn.log.Debug("Skipped invalid committee enode", "enode", err.Enode, "err", err.Err)
+		}
+		enodesUpdater.Update(enodesList.List, enodesList.List)
 	}
 
 	wasValidating := false
 	currentBlock := acn.chain.CurrentBlock()
+	acn.server.SetCurrentBlockNumber(currentBlock.NumberU64())
 	if currentBlock.Header().CommitteeMember(acn.address) != nil {
 		updateConsensusEnodes(currentBlock)
 		wasValidating = true
@@ -38,18 +47,20 @@ func (acn *ACN) watchCommittee(ctx context.Context) {
 	go func() {
 		defer acn.wg.Done()
 		defer chainHeadSub.Unsubscribe()
+		defer enodesUpdater.Stop()
 		for {
 			select {
 			case ev := <-chainHeadCh:
 				acn.server.SetCurrentBlockNumber(ev.Block.NumberU64())
 				header := ev.Block.Header()
+				acn.versionMix.update(header.Committee, acn.address, acn.peers, acn.versions)
 				// check if the local node belongs to the consensus committee.
 				if header.CommitteeMember(acn.address) == nil {
 					// if the local node was part of the committee set for the previous block
 					// there is no longer the need to retain the full connections and the
 					// consensus engine enabled.
 					if wasValidating {
-						acn.server.UpdateConsensusEnodes(nil, nil)
+						enodesUpdater.Update(nil, nil)
 						wasValidating = false
 					}
 					continue
@@ -0,0 +1,120 @@
+package acn
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/acn/protocol"
+	"github.com/autonity/autonity/core/forkid"
+	"github.com/autonity/autonity/metrics"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+const (
+	// idleSweepInterval is the interval at which the established connections are checked
+	// for protocol messages.
+	idleSweepInterval = time.Minute
+	// idleStreamTimeout is the time after the handshake a connection is dropped at if it has
+	// not sent any protocol message. The committee members exchange votes at every height,
+	// such a connection is stuck at the stream layer.
+	idleStreamTimeout = 5 * time.Minute
+	// maxTrackedTimeouts bounds the number of remote IDs the handshake timeouts are counted for.
+	maxTrackedTimeouts = 1024
+)
+
+var (
+	handshakeTimeoutsCounter = metrics.NewRegisteredCounter("acn/handshake/timeouts", nil)
+	idleStreamsCounter       = metrics.NewRegisteredCounter("acn/stream/idle", nil)
+)
+
+// handshakeTimeouts counts the `acn` handshakes timed out by remote ID.
+type handshakeTimeouts struct {
+	sync.RWMutex
+	counts map[enode.ID]uint64
+}
+
+func (h *handshakeTimeouts) add(id enode.ID) {
+	h.Lock()
+	defer h.Unlock()
+	if h.counts == nil {
+		h.counts = make(map[enode.ID]uint64)
+	}
+	if _, ok := h.counts[id]; !ok && len(h.counts) >= maxTrackedTimeouts {
+		// evict an arbitrary remote, the counts are diagnostics
+		for evicted := range h.counts {
+			delete(h.counts, evicted)
+			break
+		}
+	}
+	h.counts[id]++
+	handshakeTimeoutsCounter.Inc(1)
+}
+
+func (h *handshakeTimeouts) count(id enode.ID) uint64 {
+	h.RLock()
+	defer h.RUnlock()
+	return h.counts[id]
+}
+
+func (h *handshakeTimeouts) get() map[enode.ID]uint64 {
+	h.RLock()
+	defer h.RUnlock()
+	counts := make(map[enode.ID]uint64, len(h.counts))
+	for id, count := range h.counts {
+		counts[id] = count
+	}
+	return counts
+}
+
+// handshake runs the `acn` handshake of the peer within the configured deadline, and maps
+// its failures to the disconnect reasons.
+func (acn *ACN) handshake(peer *protocol.Peer, genesis common.Hash, forkID forkid.ID) error {
+	err := peer.Handshake(acn.networkID, genesis, forkID, acn.forkFilter, acn.compression, acn.identity, acn.handshakeTimeout)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, protocol.ErrIdentityMismatch):
+		peer.Log().Warn("Consensus peer identity rejected", "address", peer.Address(), "err", err)
+		return p2p.DiscIdentityMismatch
+	case errors.Is(err, p2p.DiscHandshakeTimeout):
+		acn.timeouts.add(peer.ID())
+		peer.Log().Debug("Consensus handshake timed out", "timeout", acn.handshakeTimeout, "count", acn.timeouts.count(peer.ID()))
+		return p2p.DiscHandshakeTimeout
+	}
+	peer.Log().Debug("Consensus handshake failed", "err", err)
+	return err
+}
+
+// sweepIdleStreams periodically drops the connections which have not sent any protocol message
+// since their handshake. Unlike the committee membership, it targets the connections stuck at the
+// handshake or stream layer.
+func (acn *ACN) sweepIdleStreams(ctx context.Context) {
+	acn.wg.Add(1)
+	go func() {
+		defer acn.wg.Done()
+		ticker := time.NewTicker(idleSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				acn.dropIdleStreams(now.Add(-idleStreamTimeout))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// dropIdleStreams drops the connections established before the given time which have not sent
+// any protocol message since.
+func (acn *ACN) dropIdleStreams(before time.Time) {
+	for _, peer := range acn.peers.idle(before) {
+		peer.Log().Debug("Dropping consensus connection idle since handshake", "address", peer.Address())
+		idleStreamsCounter.Inc(1)
+		peer.Disconnect(p2p.DiscIdleStream)
+	}
+}
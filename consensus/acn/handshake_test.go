@@ -0,0 +1,133 @@
+package acn
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/acn/protocol"
+	"github.com/autonity/autonity/core/forkid"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// stalledTransport accepts the messages written but never delivers any, like a remote end stuck
+// before its handshake.
+type stalledTransport struct {
+	closed chan struct{}
+}
+
+func newStalledTransport(t *testing.T) *stalledTransport {
+	rw := &stalledTransport{closed: make(chan struct{})}
+	t.Cleanup(func() { close(rw.closed) })
+	return rw
+}
+
+func (rw *stalledTransport) ReadMsg() (p2p.Msg, error) {
+	<-rw.closed
+	return p2p.Msg{}, p2p.ErrPipeClosed
+}
+
+func (rw *stalledTransport) WriteMsg(msg p2p.Msg) error {
+	return msg.Discard()
+}
+
+func newTestACN(handshakeTimeout time.Duration) *ACN {
+	return &ACN{
+		networkID:        1,
+		peers:            newPeerSet(),
+		forkFilter:       func(forkid.ID) error { return nil },
+		log:              log.New(),
+		handshakeTimeout: handshakeTimeout,
+	}
+}
+
+// peerInfo returns the `acn` information of the peer as served by admin_acnPeers.
+func peerInfo(t *testing.T, acn *ACN, id enode.ID) map[string]interface{} {
+	info := acn.PeerInfo(id)
+	require.NotNil(t, info)
+	blob, err := json.Marshal(info)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(blob, &fields))
+	return fields
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	acn := newTestACN(200 * time.Millisecond)
+	caps := []p2p.Cap{{Name: protocol.ProtocolName, Version: protocol.ACNv2}}
+	peer := protocol.NewPeer(protocol.ACNv2, p2p.NewPeer(enode.ID{}, "stalled", caps), newStalledTransport(t))
+	acn.peers.connect(peer)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- acn.handshake(peer, common.Hash{1}, forkid.ID{})
+	}()
+	info := peerInfo(t, acn, peer.ID())
+	require.Equal(t, "pending", info["handshake"])
+	require.Equal(t, float64(0), info["handshakeTimeouts"])
+
+	select {
+	case err := <-errCh:
+		require.Equal(t, p2p.DiscHandshakeTimeout, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("handshake did not time out")
+	}
+	info = peerInfo(t, acn, peer.ID())
+	require.Equal(t, "timed out", info["handshake"])
+	require.GreaterOrEqual(t, info["handshakeDuration"], float64(200*time.Millisecond))
+	require.Equal(t, float64(1), info["handshakeTimeouts"])
+	require.Equal(t, map[enode.ID]uint64{peer.ID(): 1}, acn.HandshakeTimeouts())
+
+	// the connection is no longer visible once the protocol returned, the timeouts are kept
+	acn.peers.disconnect(peer)
+	require.Nil(t, acn.PeerInfo(peer.ID()))
+	require.Equal(t, uint64(1), acn.timeouts.count(peer.ID()))
+}
+
+func TestHandshakeTimeoutsBound(t *testing.T) {
+	var timeouts handshakeTimeouts
+	for i := 0; i < maxTrackedTimeouts+10; i++ {
+		timeouts.add(enode.ID{byte(i), byte(i >> 8)})
+	}
+	timeouts.add(enode.ID{0xff, 0xff})
+	timeouts.add(enode.ID{0xff, 0xff})
+	counts := timeouts.get()
+	require.Len(t, counts, maxTrackedTimeouts)
+	require.Equal(t, uint64(2), counts[enode.ID{0xff, 0xff}])
+}
+
+func TestDropIdleStreams(t *testing.T) {
+	acn := newTestACN(0)
+	rw1, rw2 := p2p.MsgPipe()
+	t.Cleanup(func() {
+		rw1.Close()
+		rw2.Close()
+	})
+	caps := []p2p.Cap{{Name: protocol.ProtocolName, Version: protocol.ACNv2}}
+	// the disconnection closes the pipe
+	peer := protocol.NewPeer(protocol.ACNv2, p2p.NewPeerPipe(enode.ID{}, "idle", caps, rw1), rw1)
+	remote := protocol.NewPeer(protocol.ACNv2, p2p.NewPeer(enode.ID{}, "remote", caps), rw2)
+	acn.peers.connect(peer)
+
+	forkFilter := func(forkid.ID) error { return nil }
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- remote.Handshake(1, common.Hash{1}, forkid.ID{}, forkFilter, protocol.CompressionConfig{}, nil, 0)
+	}()
+	require.NoError(t, acn.handshake(peer, common.Hash{1}, forkid.ID{}))
+	require.NoError(t, <-errCh)
+	require.Equal(t, "done", peerInfo(t, acn, peer.ID())["handshake"])
+
+	// a connection is given time to send its first message
+	acn.dropIdleStreams(time.Now().Add(-time.Hour))
+	require.Empty(t, acn.peers.idle(time.Now().Add(-time.Hour)))
+
+	acn.dropIdleStreams(time.Now().Add(time.Hour))
+	_, err := rw2.ReadMsg()
+	require.ErrorIs(t, err, p2p.ErrPipeClosed)
+}
@@ -0,0 +1,25 @@
+package acn
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/crypto/blst"
+)
+
+// registeredConsensusKey returns the consensus key registered on-chain for the validator of the given
+// node address. The committee of the head is looked up first, then the validator registry for the
+// validators not in the committee yet.
+func (acn *ACN) registeredConsensusKey(address common.Address) (blst.PublicKey, error) {
+	header := acn.chain.CurrentHeader()
+	if member := header.CommitteeMember(address); member != nil {
+		return blst.PublicKeyFromBytes(member.ConsensusKeyBytes)
+	}
+	state, err := acn.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := acn.chain.ProtocolContracts().Validator(header, state, address)
+	if err != nil {
+		return nil, err
+	}
+	return blst.PublicKeyFromBytes(validator.ConsensusKey)
+}
@@ -19,6 +19,7 @@ package acn
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/acn/protocol"
@@ -46,6 +47,7 @@ var (
 type peerSet struct {
 	peers     map[common.Address]*protocol.Peer // Peers connected on the `acn` protocol by address
 	peersByID map[enode.ID]*protocol.Peer       // Peers connected on the `acn` protocol by ID
+	conns     map[enode.ID]*protocol.Peer       // Connections running the `acn` protocol, handshake included
 	sync.RWMutex
 	closed bool
 }
@@ -55,9 +57,48 @@ func newPeerSet() *peerSet {
 	return &peerSet{
 		peers:     make(map[common.Address]*protocol.Peer),
 		peersByID: make(map[enode.ID]*protocol.Peer),
+		conns:     make(map[enode.ID]*protocol.Peer),
 	}
 }
 
+// connect tracks a connection running the `acn` protocol, from before its handshake.
+func (ps *peerSet) connect(peer *protocol.Peer) {
+	ps.Lock()
+	defer ps.Unlock()
+	ps.conns[peer.ID()] = peer
+}
+
+// disconnect stops tracking a connection once the `acn` protocol returned.
+func (ps *peerSet) disconnect(peer *protocol.Peer) {
+	ps.Lock()
+	defer ps.Unlock()
+	if ps.conns[peer.ID()] == peer {
+		delete(ps.conns, peer.ID())
+	}
+}
+
+// conn retrieves the connection with the given id, whether its handshake completed or not.
+func (ps *peerSet) conn(id enode.ID) (*protocol.Peer, bool) {
+	ps.RLock()
+	defer ps.RUnlock()
+	p, ok := ps.conns[id]
+	return p, ok
+}
+
+// idle returns the connections established before the given time which have not sent
+// any protocol message since their handshake.
+func (ps *peerSet) idle(before time.Time) []*protocol.Peer {
+	ps.RLock()
+	defer ps.RUnlock()
+	var idle []*protocol.Peer
+	for _, p := range ps.conns {
+		if established, ok := p.Established(); ok && established.Before(before) && p.Received() == 0 {
+			idle = append(idle, p)
+		}
+	}
+	return idle
+}
+
 // register injects a new `consensus` peer into the working set, or returns an error
 // if the peer is already known.
 func (ps *peerSet) register(peer *protocol.Peer) error {
@@ -0,0 +1,167 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+)
+
+// Codec is a compression algorithm of the consensus message payloads. From ACNv2 on, each payload
+// is prefixed by the byte of the codec it is compressed with, CodecNone for a raw payload.
+type Codec uint8
+
+const (
+	CodecNone Codec = iota
+	CodecSnappy
+	CodecGzip
+)
+
+// SupportedCodecs are the codecs supported by the local node, in order of preference.
+var SupportedCodecs = []Codec{CodecSnappy, CodecGzip}
+
+// DefaultCompressionThreshold is the default size from which the payloads are compressed.
+const DefaultCompressionThreshold = 4 * 1024
+
+// maxExpansionRatio bounds the ratio between the decompressed and the compressed size of a payload,
+// protecting against decompression bombs. The payloads compressing better are sent raw.
+const maxExpansionRatio = 32
+
+var (
+	errUnsupportedCodec = constants.NewError(constants.ClassDecode, "unsupported payload codec")
+	errExpansionRatio   = constants.NewError(constants.ClassDecode, "payload expansion ratio exceeded")
+	errEmptyPayload     = constants.NewError(constants.ClassDecode, "empty payload")
+)
+
+func (c Codec) String() string {
+	switch c {
+	case CodecNone:
+		return "none"
+	case CodecSnappy:
+		return "snappy"
+	case CodecGzip:
+		return "gzip"
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(c))
+}
+
+// CompressionConfig are the compression settings of the consensus payloads.
+type CompressionConfig struct {
+	Threshold uint64  // payloads of at least Threshold bytes are compressed, compression is disabled if zero
+	Codecs    []Codec // codecs advertised to the peers, in order of preference
+}
+
+// NewCompressionConfig returns the compression settings for the given threshold.
+func NewCompressionConfig(threshold uint64) CompressionConfig {
+	if threshold == 0 {
+		return CompressionConfig{}
+	}
+	return CompressionConfig{Threshold: threshold, Codecs: SupportedCodecs}
+}
+
+// negotiateCodec returns the codec of ours most preferred which is supported by the remote peer.
+func negotiateCodec(ours, theirs []Codec) Codec {
+	for _, codec := range ours {
+		for _, their := range theirs {
+			if codec == their {
+				return codec
+			}
+		}
+	}
+	return CodecNone
+}
+
+// supportsCodec reports whether codec is one of codecs.
+func supportsCodec(codecs []Codec, codec Codec) bool {
+	for _, c := range codecs {
+		if c == codec {
+			return true
+		}
+	}
+	return false
+}
+
+// encodePayload frames the payload, compressing it with codec if it reaches threshold.
+func encodePayload(codec Codec, threshold uint64, payload []byte) ([]byte, error) {
+	if codec != CodecNone && threshold > 0 && uint64(len(payload)) >= threshold {
+		compressed, err := compress(codec, payload)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(payload) && len(payload) <= len(compressed)*maxExpansionRatio {
+			return append([]byte{byte(codec)}, compressed...), nil
+		}
+	}
+	return append([]byte{byte(CodecNone)}, payload...), nil
+}
+
+// decodePayload unframes the payload, decompressing it if compressed with one of the accepted codecs.
+func decodePayload(accepted []Codec, framed []byte) ([]byte, error) {
+	if len(framed) == 0 {
+		return nil, errEmptyPayload
+	}
+	codec, payload := Codec(framed[0]), framed[1:]
+	if codec == CodecNone {
+		return payload, nil
+	}
+	if !supportsCodec(accepted, codec) {
+		return nil, fmt.Errorf("%w: %v", errUnsupportedCodec, codec)
+	}
+	limit := len(payload) * maxExpansionRatio
+	if limit > MaxMessageSize {
+		limit = MaxMessageSize
+	}
+	return decompress(codec, payload, limit)
+}
+
+func compress(codec Codec, data []byte) ([]byte, error) {
+	switch codec {
+	case CodecSnappy:
+		return snappy.Encode(nil, data), nil
+	case CodecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w: %v", errUnsupportedCodec, codec)
+}
+
+// decompress decompresses data, failing if it expands to more than limit bytes.
+func decompress(codec Codec, data []byte, limit int) ([]byte, error) {
+	switch codec {
+	case CodecSnappy:
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, constants.WrapError(constants.ClassDecode, err)
+		}
+		if size > limit {
+			return nil, fmt.Errorf("%w: %d bytes from %d", errExpansionRatio, size, len(data))
+		}
+		decoded, err := snappy.Decode(nil, data)
+		return decoded, constants.WrapError(constants.ClassDecode, err)
+	case CodecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, constants.WrapError(constants.ClassDecode, err)
+		}
+		decoded, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil {
+			return nil, constants.WrapError(constants.ClassDecode, err)
+		}
+		if len(decoded) > limit {
+			return nil, fmt.Errorf("%w: more than %d bytes from %d", errExpansionRatio, limit, len(data))
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("%w: %v", errUnsupportedCodec, codec)
+}
@@ -0,0 +1,283 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/core/forkid"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+	"github.com/autonity/autonity/rlp"
+	"github.com/autonity/autonity/trie"
+)
+
+// testProposalPayload returns the RLP encoding of a block of transfers, the bulk of a proposal.
+func testProposalPayload(t testing.TB, txs int) []byte {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(65111111))
+	list := make([]*types.Transaction, txs)
+	for i := range list {
+		to := common.BigToAddress(big.NewInt(int64(i % 16)))
+		tx := types.NewTransaction(uint64(i), to, big.NewInt(1e18), 21000, big.NewInt(1e9), nil)
+		list[i], err = types.SignTx(tx, signer, key)
+		require.NoError(t, err)
+	}
+	header := &types.Header{Number: big.NewInt(100), GasLimit: 30_000_000, Time: 1700000000}
+	payload, err := rlp.EncodeToBytes(types.NewBlock(header, list, nil, nil, trie.NewStackTrie(nil)))
+	require.NoError(t, err)
+	return payload
+}
+
+func randomBytes(t testing.TB, n int) []byte {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
+
+func TestNegotiateCodec(t *testing.T) {
+	tests := []struct {
+		ours, theirs []Codec
+		want         Codec
+	}{
+		{SupportedCodecs, SupportedCodecs, CodecSnappy},
+		{SupportedCodecs, []Codec{CodecGzip, CodecSnappy}, CodecSnappy},
+		{SupportedCodecs, []Codec{CodecGzip}, CodecGzip},
+		{SupportedCodecs, []Codec{Codec(42)}, CodecNone},
+		{SupportedCodecs, nil, CodecNone},
+		{nil, SupportedCodecs, CodecNone},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, negotiateCodec(test.ours, test.theirs), "ours %v theirs %v", test.ours, test.theirs)
+	}
+}
+
+func TestPayloadFraming(t *testing.T) {
+	proposal := testProposalPayload(t, 200)
+	small := proposal[:DefaultCompressionThreshold-1]
+
+	for _, codec := range SupportedCodecs {
+		t.Run(codec.String(), func(t *testing.T) {
+			// the payloads under the threshold are sent raw
+			framed, err := encodePayload(codec, DefaultCompressionThreshold, small)
+			require.NoError(t, err)
+			require.Equal(t, append([]byte{byte(CodecNone)}, small...), framed)
+
+			// the payloads reaching it are compressed
+			framed, err = encodePayload(codec, DefaultCompressionThreshold, proposal)
+			require.NoError(t, err)
+			require.Equal(t, byte(codec), framed[0])
+			require.Less(t, len(framed), len(proposal))
+			decoded, err := decodePayload(SupportedCodecs, framed)
+			require.NoError(t, err)
+			require.Equal(t, proposal, decoded)
+
+			// the incompressible payloads are sent raw
+			random := randomBytes(t, 2*DefaultCompressionThreshold)
+			framed, err = encodePayload(codec, DefaultCompressionThreshold, random)
+			require.NoError(t, err)
+			require.Equal(t, byte(CodecNone), framed[0])
+			decoded, err = decodePayload(SupportedCodecs, framed)
+			require.NoError(t, err)
+			require.Equal(t, random, decoded)
+		})
+	}
+
+	t.Run("no codec negotiated", func(t *testing.T) {
+		framed, err := encodePayload(CodecNone, DefaultCompressionThreshold, proposal)
+		require.NoError(t, err)
+		require.Equal(t, append([]byte{byte(CodecNone)}, proposal...), framed)
+	})
+
+	t.Run("unaccepted codec", func(t *testing.T) {
+		framed, err := encodePayload(CodecGzip, DefaultCompressionThreshold, proposal)
+		require.NoError(t, err)
+		_, err = decodePayload([]Codec{CodecSnappy}, framed)
+		require.ErrorIs(t, err, errUnsupportedCodec)
+		require.Equal(t, constants.ClassDecode, constants.ClassOf(err))
+
+		_, err = decodePayload(SupportedCodecs, []byte{42, 1, 2, 3})
+		require.ErrorIs(t, err, errUnsupportedCodec)
+		_, err = decodePayload(SupportedCodecs, nil)
+		require.ErrorIs(t, err, errEmptyPayload)
+	})
+}
+
+func TestExpansionRatioGuard(t *testing.T) {
+	zeros := make([]byte, 1024*1024)
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	_, err := w.Write(zeros)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// snappy cannot expand by more than about 21 times, the bomb declares a larger size than it holds
+	snappyBomb := binary.AppendUvarint(nil, uint64(len(zeros)))
+	snappyBomb = append(snappyBomb, snappy.Encode(nil, zeros[:1024])[2:]...)
+
+	bombs := map[Codec][]byte{
+		CodecSnappy: snappyBomb,
+		CodecGzip:   gzipped.Bytes(),
+	}
+	for codec, bomb := range bombs {
+		t.Run(codec.String(), func(t *testing.T) {
+			require.Greater(t, len(zeros), len(bomb)*maxExpansionRatio)
+			_, err := decodePayload(SupportedCodecs, append([]byte{byte(codec)}, bomb...))
+			require.ErrorIs(t, err, errExpansionRatio)
+			require.Equal(t, constants.ClassDecode, constants.ClassOf(err))
+
+		})
+	}
+
+	t.Run("the sender does not produce payloads the receiver would reject", func(t *testing.T) {
+		framed, err := encodePayload(CodecGzip, DefaultCompressionThreshold, zeros)
+		require.NoError(t, err)
+		require.Equal(t, byte(CodecNone), framed[0])
+	})
+
+	t.Run("corrupted payload", func(t *testing.T) {
+		framed, err := encodePayload(CodecSnappy, DefaultCompressionThreshold, testProposalPayload(t, 100))
+		require.NoError(t, err)
+		framed[1] ^= 0xff
+		_, err = decodePayload(SupportedCodecs, framed)
+		require.Error(t, err)
+		require.Equal(t, constants.ClassDecode, constants.ClassOf(err))
+	})
+}
+
+// newTestPeers returns two connected peers of the given protocol version, after their handshake.
+func newTestPeers(t *testing.T, version uint, ours, theirs CompressionConfig) (*Peer, *Peer, *p2p.MsgPipeRW) {
+	rw1, rw2 := p2p.MsgPipe()
+	t.Cleanup(func() {
+		rw1.Close()
+		rw2.Close()
+	})
+	caps := []p2p.Cap{{Name: ProtocolName, Version: version}}
+	peer1 := NewPeer(version, p2p.NewPeer(enode.ID{1}, "peer1", caps), rw1)
+	peer2 := NewPeer(version, p2p.NewPeer(enode.ID{2}, "peer2", caps), rw2)
+
+	var (
+		genesis    = common.Hash{1}
+		forkID     = forkid.ID{}
+		forkFilter = func(forkid.ID) error { return nil }
+		errCh      = make(chan error, 1)
+	)
+	go func() {
+		errCh <- peer2.Handshake(1, genesis, forkID, forkFilter, theirs, nil, 0)
+	}()
+	require.NoError(t, peer1.Handshake(1, genesis, forkID, forkFilter, ours, nil, 0))
+	require.NoError(t, <-errCh)
+	return peer1, peer2, rw2
+}
+
+func TestCompressionNegotiation(t *testing.T) {
+	var (
+		enabled  = NewCompressionConfig(DefaultCompressionThreshold)
+		disabled = NewCompressionConfig(0)
+		gzipOnly = CompressionConfig{Threshold: DefaultCompressionThreshold, Codecs: []Codec{CodecGzip}}
+		proposal = testProposalPayload(t, 200)
+	)
+	tests := []struct {
+		name          string
+		version       uint
+		ours, theirs  CompressionConfig
+		ourCodec      Codec
+		theirCodec    Codec
+		wantFramed    bool
+		wantOurPrefix Codec
+	}{
+		{"both enabled", ACNv2, enabled, enabled, CodecSnappy, CodecSnappy, true, CodecSnappy},
+		{"remote with gzip only", ACNv2, enabled, gzipOnly, CodecGzip, CodecGzip, true, CodecGzip},
+		{"remote with compression disabled", ACNv2, enabled, disabled, CodecNone, CodecNone, true, CodecNone},
+		{"local with compression disabled", ACNv2, disabled, enabled, CodecNone, CodecNone, true, CodecNone},
+		{"ACNv1 connection", ACNv1, enabled, enabled, CodecNone, CodecNone, false, CodecNone},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ours, theirs, rw := newTestPeers(t, test.version, test.ours, test.theirs)
+			require.Equal(t, test.ourCodec, ours.codec)
+			require.Equal(t, test.theirCodec, theirs.codec)
+
+			// the payload as read from the wire
+			errCh := make(chan error, 1)
+			go func() { errCh <- ours.SendRaw(0x11, proposal) }()
+			msg, err := rw.ReadMsg()
+			require.NoError(t, err)
+			wire := make([]byte, msg.Size)
+			_, err = msg.Payload.Read(wire)
+			require.NoError(t, err)
+			require.NoError(t, <-errCh)
+			if !test.wantFramed {
+				require.Equal(t, proposal, wire)
+				return
+			}
+			require.Equal(t, byte(test.wantOurPrefix), wire[0])
+
+			// the payload as handed to the consensus engine
+			go func() { errCh <- ours.SendRaw(0x11, proposal) }()
+			msg, err = theirs.readMsg()
+			require.NoError(t, err)
+			require.Equal(t, uint64(0x11), msg.Code)
+			require.Equal(t, uint32(len(proposal)), msg.Size)
+			received := make([]byte, msg.Size)
+			_, err = msg.Payload.Read(received)
+			require.NoError(t, err)
+			require.Equal(t, proposal, received)
+			require.NoError(t, <-errCh)
+		})
+	}
+
+	t.Run("RLP encoded messages", func(t *testing.T) {
+		ours, theirs, _ := newTestPeers(t, ACNv2, enabled, enabled)
+		errCh := make(chan error, 1)
+		go func() { errCh <- ours.Send(0x12, []uint64{1, 2, 3}) }()
+		msg, err := theirs.readMsg()
+		require.NoError(t, err)
+		var decoded []uint64
+		require.NoError(t, msg.Decode(&decoded))
+		require.Equal(t, []uint64{1, 2, 3}, decoded)
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("codec not advertised by the receiver", func(t *testing.T) {
+		ours, theirs, _ := newTestPeers(t, ACNv2, enabled, disabled)
+		// a misbehaving sender compressing anyway
+		ours.codec = CodecSnappy
+		errCh := make(chan error, 1)
+		go func() { errCh <- ours.SendRaw(0x11, proposal) }()
+		_, err := theirs.readMsg()
+		require.ErrorIs(t, err, errUnsupportedCodec)
+		require.NoError(t, <-errCh)
+	})
+}
+
+func BenchmarkPayloadCompression(b *testing.B) {
+	for _, txs := range []int{50, 500, 2000} {
+		proposal := testProposalPayload(b, txs)
+		for _, codec := range SupportedCodecs {
+			b.Run(fmt.Sprintf("%s/%dtxs", codec, txs), func(b *testing.B) {
+				var framed []byte
+				b.SetBytes(int64(len(proposal)))
+				for i := 0; i < b.N; i++ {
+					framed, _ = encodePayload(codec, DefaultCompressionThreshold, proposal)
+				}
+				b.ReportMetric(float64(len(proposal)), "raw-bytes")
+				b.ReportMetric(float64(len(framed)), "wire-bytes")
+				b.ReportMetric(100*(1-float64(len(framed))/float64(len(proposal))), "%saved")
+			})
+		}
+	}
+}
@@ -2,8 +2,10 @@ package protocol
 
 import (
 	"errors"
+	"time"
 
-	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	tendermint "github.com/autonity/autonity/consensus/tendermint/backend"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/p2p"
 )
 
@@ -13,6 +15,9 @@ const (
 
 const acnErrorSuspensionSpan = 60 // num of blocks, peer is not allowed to set up connection
 
+// strikeWindow is the span over which the tolerated consensus message errors of a peer add up.
+const strikeWindow = time.Minute
+
 var errorToString = map[int]string{
 	errACNHandler: "acn message handling error",
 }
@@ -24,13 +29,22 @@ func newACNError(backend Backend, err error) *p2p.ProtocolError {
 	}
 	pError := &p2p.ProtocolError{Suspension: func() uint64 {
 		var suspension = uint64(acnErrorSuspensionSpan)
-		if errors.Is(err, message.ErrBadSignature) {
+		if tendermint.MessageErrorPenalty(err).EpochSuspension {
 			// TODO: implement more harsh exponential approach disconnection?
 			suspension = backend.Chain().ProtocolContracts().Cache.EpochPeriod().Uint64()
 		}
 		//Note: Can add more errors here for different suspension span
 		return suspension
-	}, Code: errACNHandler, Message: desc}
+	}, Severity: banSeverity(err), Code: errACNHandler, Message: desc}
 	pError.Message += ": " + err.Error()
 	return pError
 }
+
+// banSeverity grades the error for the consensus network ban list, the consensus message errors
+// being graded by the consensus engine.
+func banSeverity(err error) p2p.BanSeverity {
+	if errors.Is(err, constants.ErrAccusationSpam) {
+		return p2p.BanMajor
+	}
+	return tendermint.MessageErrorPenalty(err).Severity
+}
@@ -3,6 +3,7 @@ package protocol
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
@@ -54,9 +55,11 @@ func nodeInfo(chain *core.BlockChain, network uint64) *NodeInfo {
 	}
 }
 
-func MakeProtocols(backend Backend, network uint64) []p2p.Protocol {
-	protocols := make([]p2p.Protocol, len(ProtocolVersions))
-	for i, version := range ProtocolVersions {
+// MakeProtocols returns the `acn` protocols of the given versions, registered side by side.
+// The devp2p handshake runs each peer on the highest version both ends support.
+func MakeProtocols(backend Backend, network uint64, versions []uint) []p2p.Protocol {
+	protocols := make([]p2p.Protocol, len(versions))
+	for i, version := range versions {
 		version := version // Closure
 
 		protocols[i] = p2p.Protocol{
@@ -91,12 +94,19 @@ func Handle(backend Backend, peer *Peer) error {
 	errCh := make(chan error, 1)
 	for {
 		if err := handleMessage(backend, peer, errCh); err != nil {
-			peer.Log().Debug("Message handling failed in `acn`", "err", err)
-			err = newACNError(backend, err)
-			return err
+			if peer.tolerate(err, time.Now()) {
+				peer.Log().Debug("Message handling failed in `acn`, strike recorded", "err", err, "strikes", peer.strikes)
+			} else {
+				peer.Log().Debug("Message handling failed in `acn`", "err", err)
+				return newACNError(backend, err)
+			}
 		}
 		select {
 		case err := <-errCh:
+			if peer.tolerate(err, time.Now()) {
+				peer.Log().Warn("Message handling failed in aggregator or consensus core, strike recorded", "err", err, "strikes", peer.strikes)
+				break
+			}
 			err = newACNError(backend, err)
 			peer.Log().Error("Message handling failed in aggregator or consensus core", "err", err)
 			return err
@@ -110,7 +120,7 @@ func Handle(backend Backend, peer *Peer) error {
 // peer. The remote connection is torn down upon returning any error.
 func handleMessage(backend Backend, peer *Peer, errCh chan<- error) error {
 	// Read the next message from the remote peer, and ensure it's fully consumed
-	msg, err := peer.rw.ReadMsg()
+	msg, err := peer.readMsg()
 	if err != nil {
 		return err
 	}
@@ -17,6 +17,7 @@
 package protocol
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -26,42 +27,96 @@ import (
 )
 
 const (
-	// handshakeTimeout is the maximum allowed time for the `atc` handshake to
-	// complete before dropping the connection.= as malicious.
-	handshakeTimeout = 5 * time.Second
+	// DefaultHandshakeTimeout is the default maximum allowed time for the `acn`
+	// handshake to complete before dropping the connection.
+	DefaultHandshakeTimeout = 5 * time.Second
 )
 
 // Handshake executes the eth protocol handshake, negotiating version number,
-// network IDs, difficulties, head and genesis blocks.
-func (p *Peer) Handshake(network uint64, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
+// network IDs, difficulties, head and genesis blocks. From ACNv3 on, the peers then
+// prove their identity, which must be set. The connection has to be dropped with
+// p2p.DiscHandshakeTimeout, which is returned, if the handshake does not complete
+// within timeout, DefaultHandshakeTimeout if zero.
+func (p *Peer) Handshake(network uint64, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, compression CompressionConfig, identity *Identity, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultHandshakeTimeout
+	}
+	start := time.Now()
+	err := p.handshake(network, genesis, forkID, forkFilter, compression, identity, timeout)
+	p.handshakeDuration.Store(int64(time.Since(start)))
+	switch {
+	case err == nil:
+		p.handshakeState.Store(uint32(HandshakeDone))
+	case errors.Is(err, p2p.DiscHandshakeTimeout):
+		p.handshakeState.Store(uint32(HandshakeTimedOut))
+	default:
+		p.handshakeState.Store(uint32(HandshakeFailed))
+	}
+	return err
+}
+
+func (p *Peer) handshake(network uint64, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, compression CompressionConfig, identity *Identity, timeout time.Duration) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
 
 	var status StatusPacket // safe to read after two values have been received from errc
 
+	ours := &StatusPacket{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		Genesis:         genesis,
+		ForkID:          forkID,
+	}
+	if p.version >= ACNv2 {
+		ours.Codecs = compression.Codecs
+	}
+	if p.version >= ACNv3 {
+		challenge, err := newChallenge()
+		if err != nil {
+			return err
+		}
+		ours.Challenge = challenge
+	}
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
-			ProtocolVersion: uint32(p.version),
-			NetworkID:       network,
-			Genesis:         genesis,
-			ForkID:          forkID,
-		})
+		errc <- p2p.Send(p.rw, StatusMsg, ours)
 	}()
 	go func() {
 		errc <- p.readStatus(network, &status, genesis, forkFilter)
 	}()
-	timeout := time.NewTimer(handshakeTimeout)
-	defer timeout.Stop()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
 	for i := 0; i < 2; i++ {
 		select {
 		case err := <-errc:
 			if err != nil {
 				return err
 			}
-		case <-timeout.C:
-			return p2p.DiscReadTimeout
+		case <-deadline.C:
+			return p2p.DiscHandshakeTimeout
+		}
+	}
+	if p.version >= ACNv3 {
+		go func() {
+			errc <- p.sendIdentity(identity, status.Challenge)
+		}()
+		go func() {
+			errc <- p.readIdentity(identity, ours.Challenge)
+		}()
+		for i := 0; i < 2; i++ {
+			select {
+			case err := <-errc:
+				if err != nil {
+					return err
+				}
+			case <-deadline.C:
+				return p2p.DiscHandshakeTimeout
+			}
 		}
 	}
+	if p.version >= ACNv2 {
+		p.compression = compression
+		p.codec = negotiateCodec(compression.Codecs, status.Codecs)
+	}
 	return nil
 }
 
@@ -93,5 +148,8 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 	if err := forkFilter(status.ForkID); err != nil {
 		return fmt.Errorf("%w: %v", errForkIDRejected, err)
 	}
+	if p.version >= ACNv3 && len(status.Challenge) != challengeLength {
+		return fmt.Errorf("%w: length %d (!= %d)", errInvalidChallenge, len(status.Challenge), challengeLength)
+	}
 	return nil
 }
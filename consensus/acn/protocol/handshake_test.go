@@ -0,0 +1,91 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/forkid"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// stalledTransport accepts the messages written but never delivers any, like a remote end stuck
+// before its handshake.
+type stalledTransport struct {
+	closed chan struct{}
+}
+
+func newStalledTransport(t *testing.T) *stalledTransport {
+	rw := &stalledTransport{closed: make(chan struct{})}
+	t.Cleanup(func() { close(rw.closed) })
+	return rw
+}
+
+func (rw *stalledTransport) ReadMsg() (p2p.Msg, error) {
+	<-rw.closed
+	return p2p.Msg{}, p2p.ErrPipeClosed
+}
+
+func (rw *stalledTransport) WriteMsg(msg p2p.Msg) error {
+	return msg.Discard()
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	caps := []p2p.Cap{{Name: ProtocolName, Version: ACNv3}}
+	peer := NewPeer(ACNv3, p2p.NewPeer(enode.ID{1}, "stalled", caps), newStalledTransport(t))
+	require.Equal(t, HandshakePending, peer.HandshakeState())
+
+	forkFilter := func(forkid.ID) error { return nil }
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- peer.Handshake(1, common.Hash{1}, forkid.ID{}, forkFilter, CompressionConfig{}, nil, 200*time.Millisecond)
+	}()
+
+	// the pending handshake is visible
+	info := peer.ConsensusPeerInfo()
+	require.Equal(t, "pending", info.Handshake)
+	require.Empty(t, info.Codec)
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handshake did not time out")
+	}
+	require.Equal(t, p2p.DiscHandshakeTimeout, err)
+	require.Equal(t, HandshakeTimedOut, peer.HandshakeState())
+	_, established := peer.Established()
+	require.False(t, established)
+
+	info = peer.ConsensusPeerInfo()
+	require.Equal(t, "timed out", info.Handshake)
+	require.GreaterOrEqual(t, info.HandshakeDuration, 200*time.Millisecond)
+	require.Zero(t, info.Messages)
+}
+
+func TestHandshakeState(t *testing.T) {
+	peer1, peer2, _ := newTestPeers(t, ACNv2, CompressionConfig{}, CompressionConfig{})
+	for _, peer := range []*Peer{peer1, peer2} {
+		require.Equal(t, HandshakeDone, peer.HandshakeState())
+		established, ok := peer.Established()
+		require.True(t, ok)
+		require.False(t, established.After(time.Now()))
+		require.Equal(t, "done", peer.ConsensusPeerInfo().Handshake)
+	}
+
+	// the protocol messages are counted from the handshake on
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- peer2.Send(0x11, []byte{1})
+	}()
+	msg, err := peer1.readMsg()
+	require.NoError(t, err)
+	require.NoError(t, msg.Discard())
+	require.NoError(t, <-errCh)
+	require.Equal(t, uint64(1), peer1.Received())
+	require.Equal(t, uint64(1), peer1.ConsensusPeerInfo().Messages)
+	require.Zero(t, peer2.Received())
+}
@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// challengeLength is the length of the challenge sent in the status message from ACNv3 on.
+const challengeLength = 32
+
+// identityDomain separates the identity signatures from the other signatures of the consensus key.
+var identityDomain = []byte("autonity acn identity")
+
+// ErrIdentityMismatch is returned by the handshake if the peer does not prove that its node key is
+// the one registered on-chain for its validator.
+var ErrIdentityMismatch = errors.New("consensus identity mismatch")
+
+// IdentityPacket is the network packet proving the identity of the validator, from ACNv3 on.
+type IdentityPacket struct {
+	Signature []byte // signature of the identity hash by the consensus key of the validator
+}
+
+// Identity binds the node key of a peer, which is proven by the devp2p handshake, to the registration
+// of its validator from ACNv3 on. Each end signs the challenge of the other end with the consensus
+// key of its validator, which the other end checks against the consensus key registered on-chain
+// for the node address of the peer.
+//
+// The consensus key is used rather than the key of the validator account (treasury), since it is
+// held by the node itself and registered along with its enode.
+type Identity struct {
+	Self         enode.ID       // local node ID
+	ConsensusKey blst.SecretKey // local consensus key
+
+	// Registered returns the consensus key registered on-chain for the validator of the node address.
+	Registered func(address common.Address) (blst.PublicKey, error)
+}
+
+// newChallenge returns a fresh challenge for the remote validator to sign.
+func newChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// identityHash is the hash signed by the validator of signer for the challenge of verifier. Both node
+// IDs are included so that the signature cannot be relayed to another peer.
+func identityHash(challenge []byte, signer, verifier enode.ID) common.Hash {
+	return crypto.Keccak256Hash(identityDomain, challenge, signer[:], verifier[:])
+}
+
+// sendIdentity signs the challenge of the remote peer.
+func (p *Peer) sendIdentity(identity *Identity, challenge []byte) error {
+	signature := identity.ConsensusKey.Sign(identityHash(challenge, identity.Self, p.id).Bytes())
+	return p2p.Send(p.rw, IdentityMsg, &IdentityPacket{Signature: signature.Marshal()})
+}
+
+// readIdentity reads the remote identity message and checks it against the consensus key
+// registered for the remote node address.
+func (p *Peer) readIdentity(identity *Identity, challenge []byte) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != IdentityMsg {
+		return fmt.Errorf("%w: second msg has code %x (!= %x)", errNoIdentityMsg, msg.Code, IdentityMsg)
+	}
+	if msg.Size > MaxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, MaxMessageSize)
+	}
+	var packet IdentityPacket
+	if err := msg.Decode(&packet); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	key, err := identity.Registered(p.address)
+	if err != nil {
+		return fmt.Errorf("%w: no registered consensus key for %v: %v", ErrIdentityMismatch, p.address, err)
+	}
+	signature, err := blst.SignatureFromBytes(packet.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrIdentityMismatch, err)
+	}
+	if !signature.Verify(key, identityHash(challenge, p.id, identity.Self).Bytes()) {
+		return fmt.Errorf("%w: not signed by the consensus key registered for %v", ErrIdentityMismatch, p.address)
+	}
+	return nil
+}
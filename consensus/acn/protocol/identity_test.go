@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/forkid"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+func TestIdentityHandshake(t *testing.T) {
+	newKey := func() blst.SecretKey {
+		key, err := blst.RandKey()
+		require.NoError(t, err)
+		return key
+	}
+	remoteKey := newKey()
+	errUnregistered := errors.New("validator not registered")
+
+	tests := []struct {
+		name       string
+		version    uint
+		signingKey blst.SecretKey // key the remote signs with
+		registered blst.PublicKey // key registered for the remote, nil if unregistered
+		relayed    bool           // the remote signature is made for another peer
+		wantErr    error
+	}{
+		{"registered consensus key", ACNv3, remoteKey, remoteKey.PublicKey(), false, nil},
+		{"another consensus key", ACNv3, newKey(), remoteKey.PublicKey(), false, ErrIdentityMismatch},
+		{"unregistered validator", ACNv3, remoteKey, nil, false, ErrIdentityMismatch},
+		{"relayed signature", ACNv3, remoteKey, remoteKey.PublicKey(), true, ErrIdentityMismatch},
+		{"ACNv2 connection", ACNv2, newKey(), remoteKey.PublicKey(), false, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rw1, rw2 := p2p.MsgPipe()
+			t.Cleanup(func() {
+				rw1.Close()
+				rw2.Close()
+			})
+			// remote is the peer as seen by the local node, local the peer as seen by the remote node
+			caps := []p2p.Cap{{Name: ProtocolName, Version: test.version}}
+			remote := NewPeer(test.version, p2p.NewPeer(enode.ID{1}, "remote", caps), rw1)
+			local := NewPeer(test.version, p2p.NewPeer(enode.ID{2}, "local", caps), rw2)
+
+			localKey := newKey()
+			localIdentity := &Identity{
+				Self:         local.ID(),
+				ConsensusKey: localKey,
+				Registered: func(address common.Address) (blst.PublicKey, error) {
+					if address != remote.Address() || test.registered == nil {
+						return nil, errUnregistered
+					}
+					return test.registered, nil
+				},
+			}
+			remoteIdentity := &Identity{
+				Self:         remote.ID(),
+				ConsensusKey: test.signingKey,
+				Registered: func(address common.Address) (blst.PublicKey, error) {
+					if address != local.Address() {
+						return nil, errUnregistered
+					}
+					return localKey.PublicKey(), nil
+				},
+			}
+			if test.relayed {
+				remoteIdentity.Self = enode.ID{9}
+			}
+
+			var (
+				genesis    = common.Hash{1}
+				forkFilter = func(forkid.ID) error { return nil }
+				errCh      = make(chan error, 1)
+			)
+			go func() {
+				errCh <- local.Handshake(1, genesis, forkid.ID{}, forkFilter, CompressionConfig{}, remoteIdentity, 0)
+			}()
+			err := remote.Handshake(1, genesis, forkid.ID{}, forkFilter, CompressionConfig{}, localIdentity, 0)
+			require.ErrorIs(t, err, test.wantErr)
+			// the remote end checks the local identity regardless, the relaying end expects it bound
+			// to the relayed ID
+			if test.relayed {
+				require.ErrorIs(t, <-errCh, ErrIdentityMismatch)
+			} else {
+				require.NoError(t, <-errCh)
+			}
+		})
+	}
+}
@@ -1,14 +1,20 @@
 package protocol
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/autonity/autonity/common"
-	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/rotatingbloom"
+	tendermint "github.com/autonity/autonity/consensus/tendermint/backend"
 	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/metrics"
 	"github.com/autonity/autonity/p2p"
 	"github.com/autonity/autonity/p2p/enode"
+	"github.com/autonity/autonity/rlp"
 )
 
 var (
@@ -16,28 +22,67 @@ var (
 	PrevoteWriteBg   = metrics.NewRegisteredBufferedGauge("acn/prevote/write", nil, metrics.GetIntPointer(5000))   // time to write prevote to wire
 	PrecommitWriteBg = metrics.NewRegisteredBufferedGauge("acn/precommit/write", nil, metrics.GetIntPointer(5000)) // time to write precommit to wire
 	DefaultWriteBg   = metrics.NewRegisteredBufferedGauge("acn/any/write", nil, nil)
+
+	PayloadBytesMeter     = metrics.NewRegisteredMeter("acn/payload/bytes", nil)      // size of the payloads sent, before compression
+	PayloadWireBytesMeter = metrics.NewRegisteredMeter("acn/payload/wire/bytes", nil) // size of the payloads sent, after compression
 )
 
+// HandshakeState is the progress of the `acn` handshake of a connection.
+type HandshakeState uint32
+
 const (
-	buckets = 199
-	entries = 10
+	HandshakePending HandshakeState = iota
+	HandshakeDone
+	HandshakeFailed
+	HandshakeTimedOut
 )
 
+func (s HandshakeState) String() string {
+	switch s {
+	case HandshakePending:
+		return "pending"
+	case HandshakeDone:
+		return "done"
+	case HandshakeFailed:
+		return "failed"
+	case HandshakeTimedOut:
+		return "timed out"
+	}
+	return fmt.Sprintf("unknown(%d)", uint32(s))
+}
+
 // Peer is a collection of relevant information we have about a `acn` peer.
 type Peer struct {
 	id      enode.ID // Unique ID for the peer, cached
 	address common.Address
 
-	*p2p.Peer                   // The embedded P2P package peer
-	rw        p2p.MsgReadWriter // Input/output streams for snap
-	version   uint              // Protocol version negotiated
-	cache     *fixsizecache.Cache[common.Hash, bool]
+	*p2p.Peer                       // The embedded P2P package peer
+	rw        p2p.MsgReadWriter     // Input/output streams for snap
+	version   uint                  // Protocol version negotiated
+	known     *rotatingbloom.Filter // consensus messages the peer has
+
+	compression CompressionConfig // local compression settings, the advertised codecs are accepted
+	codec       Codec             // codec negotiated to compress the payloads sent, set by the handshake
+
+	started           time.Time     // start of the `acn` connection, before the handshake
+	handshakeState    atomic.Uint32 // HandshakeState
+	handshakeDuration atomic.Int64  // set once the handshake is over
+	received          atomic.Uint64 // protocol messages received since the handshake
+
+	// consensus message errors tolerated so far, only accessed by the message handling loop
+	strikes      int
+	strikesSince time.Time
 }
 
 // peerInfo represents a short summary of the `acn` protocol metadata known
 // about a connected peer.
 type peerInfo struct {
-	Version uint `json:"version"` // Acn protocol version negotiated
+	Version           uint          `json:"version"`           // Acn protocol version negotiated
+	Codec             string        `json:"codec"`             // Codec negotiated to compress the payloads sent
+	Handshake         string        `json:"handshake"`         // State of the handshake
+	HandshakeDuration time.Duration `json:"handshakeDuration"` // Time taken by the handshake, or elapsed while pending
+	Messages          uint64        `json:"messages"`          // Protocol messages received since the handshake
+	HandshakeTimeouts uint64        `json:"handshakeTimeouts"` // Handshakes timed out from the remote ID, set by the backend
 }
 
 // NewPeer create a wrapper for a network connection and negotiated  protocol
@@ -49,13 +94,15 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
 		Peer:    p,
 		rw:      rw,
 		version: version,
-		cache:   fixsizecache.New[common.Hash, bool](buckets, entries, fixsizecache.HashKey[common.Hash]),
+		known:   rotatingbloom.NewDefault(),
+		started: time.Now(),
 	}
 	return peer
 }
 
-func (p *Peer) Cache() *fixsizecache.Cache[common.Hash, bool] {
-	return p.cache
+// KnownMessages returns the filter of the consensus messages the peer has.
+func (p *Peer) KnownMessages() *rotatingbloom.Filter {
+	return p.known
 }
 
 // Close can be used to do peer related clean up, nothing for now
@@ -78,7 +125,14 @@ func (p *Peer) Send(msgcode uint64, data interface{}) error {
 			getWriteMetric(msgcode).Add(time.Since(start).Nanoseconds())
 		}(time.Now())
 	}
-	return p2p.Send(p.rw, msgcode, data)
+	if p.version < ACNv2 {
+		return p2p.Send(p.rw, msgcode, data)
+	}
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	return p.sendFramed(msgcode, payload)
 }
 
 func (p *Peer) SendRaw(msgcode uint64, data []byte) error {
@@ -87,7 +141,48 @@ func (p *Peer) SendRaw(msgcode uint64, data []byte) error {
 			getWriteMetric(msgcode).Add(time.Since(start).Nanoseconds())
 		}(time.Now())
 	}
-	return p2p.SendRaw(p.rw, msgcode, data)
+	if p.version < ACNv2 {
+		return p2p.SendRaw(p.rw, msgcode, data)
+	}
+	return p.sendFramed(msgcode, data)
+}
+
+// sendFramed sends the payload prefixed by its codec, compressing it if large enough.
+func (p *Peer) sendFramed(msgcode uint64, payload []byte) error {
+	framed, err := encodePayload(p.codec, p.compression.Threshold, payload)
+	if err != nil {
+		return err
+	}
+	if metrics.Enabled {
+		PayloadBytesMeter.Mark(int64(len(payload)))
+		PayloadWireBytesMeter.Mark(int64(len(framed)))
+	}
+	return p2p.SendRaw(p.rw, msgcode, framed)
+}
+
+// readMsg reads the next message, unframing its payload from ACNv2 on.
+func (p *Peer) readMsg() (p2p.Msg, error) {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return msg, err
+	}
+	p.received.Add(1)
+	if p.version < ACNv2 {
+		return msg, nil
+	}
+	if msg.Size > MaxMessageSize {
+		return msg, fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, MaxMessageSize)
+	}
+	framed := make([]byte, msg.Size)
+	if _, err := io.ReadFull(msg.Payload, framed); err != nil {
+		return msg, err
+	}
+	payload, err := decodePayload(p.compression.Codecs, framed)
+	if err != nil {
+		return msg, err
+	}
+	msg.Size, msg.Payload = uint32(len(payload)), bytes.NewReader(payload)
+	return msg, nil
 }
 
 func getWriteMetric(msgCode uint64) metrics.BufferedGauge {
@@ -107,9 +202,59 @@ func (p *Peer) Version() uint {
 	return p.version
 }
 
+// HandshakeState returns the progress of the `acn` handshake.
+func (p *Peer) HandshakeState() HandshakeState {
+	return HandshakeState(p.handshakeState.Load())
+}
+
+// HandshakeDuration returns the time taken by the handshake, or elapsed since the
+// connection started while the handshake is pending.
+func (p *Peer) HandshakeDuration() time.Duration {
+	if p.HandshakeState() == HandshakePending {
+		return time.Since(p.started)
+	}
+	return time.Duration(p.handshakeDuration.Load())
+}
+
+// Established returns when the handshake completed, and whether it did.
+func (p *Peer) Established() (time.Time, bool) {
+	if p.HandshakeState() != HandshakeDone {
+		return time.Time{}, false
+	}
+	return p.started.Add(time.Duration(p.handshakeDuration.Load())), true
+}
+
+// tolerate records a consensus message error relayed by the peer and reports whether the connection
+// survives it. The errors of the classes having a strike tolerance disconnect the peer once they add
+// up within strikeWindow, the others right away.
+func (p *Peer) tolerate(err error, now time.Time) bool {
+	strikes := tendermint.MessageErrorPenalty(err).Strikes
+	if strikes <= 1 {
+		return false
+	}
+	if now.Sub(p.strikesSince) > strikeWindow {
+		p.strikes, p.strikesSince = 0, now
+	}
+	p.strikes++
+	return p.strikes < strikes
+}
+
+// Received returns the number of protocol messages received since the handshake.
+func (p *Peer) Received() uint64 {
+	return p.received.Load()
+}
+
 // ConsensusPeerInfo gathers and returns some `acn` protocol metadata known about a peer.
 func (p *Peer) ConsensusPeerInfo() *peerInfo {
-	return &peerInfo{
-		Version: p.Version(),
+	info := &peerInfo{
+		Version:           p.Version(),
+		Handshake:         p.HandshakeState().String(),
+		HandshakeDuration: p.HandshakeDuration(),
+		Messages:          p.Received(),
+	}
+	// the codec is negotiated by the handshake
+	if p.HandshakeState() == HandshakeDone {
+		info.Codec = p.codec.String()
 	}
+	return info
 }
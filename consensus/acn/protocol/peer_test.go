@@ -0,0 +1,44 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+func TestPeerTolerate(t *testing.T) {
+	newPeer := func() *Peer {
+		caps := []p2p.Cap{{Name: ProtocolName, Version: ACNv3}}
+		return NewPeer(ACNv3, p2p.NewPeer(enode.ID{1}, "strikes", caps), newStalledTransport(t))
+	}
+	start := time.Now()
+
+	t.Run("the third bad signature disconnects", func(t *testing.T) {
+		peer := newPeer()
+		require.True(t, peer.tolerate(message.ErrBadSignature, start))
+		require.True(t, peer.tolerate(message.ErrNonCommitteeSender, start.Add(time.Second)))
+		require.False(t, peer.tolerate(message.ErrBadSignature, start.Add(2*time.Second)))
+	})
+
+	t.Run("the strikes expire", func(t *testing.T) {
+		peer := newPeer()
+		require.True(t, peer.tolerate(message.ErrNonCommitteeSender, start))
+		require.True(t, peer.tolerate(message.ErrNonCommitteeSender, start.Add(time.Second)))
+		require.True(t, peer.tolerate(message.ErrNonCommitteeSender, start.Add(strikeWindow+time.Second)))
+		require.True(t, peer.tolerate(message.ErrNonCommitteeSender, start.Add(strikeWindow+2*time.Second)))
+		require.False(t, peer.tolerate(message.ErrNonCommitteeSender, start.Add(strikeWindow+3*time.Second)))
+	})
+
+	t.Run("the other errors disconnect right away", func(t *testing.T) {
+		peer := newPeer()
+		require.False(t, peer.tolerate(constants.ErrNotFromProposer, start))
+		require.False(t, peer.tolerate(errMsgTooLarge, start))
+		require.Zero(t, peer.strikes)
+	})
+}
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/core/forkid"
@@ -10,27 +11,30 @@ import (
 // Constants to match up protocol versions and messages
 const (
 	ACNv1 = 1
+	ACNv2 = 2 // negotiates the compression of the payloads, which are prefixed by their codec
+	ACNv3 = 3 // binds the node key of the peer to the consensus key registered for its validator
 )
 
 // ProtocolName is the official short name of the autonity consensus network protocol used during
 // devp2p capability negotiation.
 const ProtocolName = "acn"
 
-// ProtocolVersions are the supported versions of the `snap` protocol (first
+// ProtocolVersions are the supported versions of the `acn` protocol (first
 // is primary).
-var ProtocolVersions = []uint{ACNv1}
+var ProtocolVersions = []uint{ACNv3, ACNv2, ACNv1}
 
 // todo(piyush): length for ACN should be 6 because of 1 status message(0x00) and
 // and 5 protocol message which have legacy codes(staring from 0x11) i.e. length 22 for now.
 // protocolLengths are the number of implemented message corresponding to
 // different protocol versions.
-var protocolLengths = map[uint]uint64{ACNv1: 22}
+var protocolLengths = map[uint]uint64{ACNv3: 22, ACNv2: 22, ACNv1: 22}
 
 // MaxMessageSize is the maximum cap on the size of a consensus protocol message.
 const MaxMessageSize = 10 * 1024 * 1024
 
 const (
-	StatusMsg = 0x00
+	StatusMsg   = 0x00
+	IdentityMsg = 0x01 // from ACNv3 on
 )
 
 var (
@@ -42,6 +46,9 @@ var (
 	errNetworkIDMismatch       = errors.New("network ID mismatch")
 	errGenesisMismatch         = errors.New("genesis mismatch")
 	errForkIDRejected          = errors.New("fork ID rejected")
+	errUnsupportedVersion      = errors.New("unsupported protocol version")
+	errNoIdentityMsg           = errors.New("no identity message")
+	errInvalidChallenge        = errors.New("invalid identity challenge")
 )
 
 // StatusPacket is the network packet for the status message for eth/64 and later.
@@ -50,4 +57,30 @@ type StatusPacket struct {
 	NetworkID       uint64
 	Genesis         common.Hash
 	ForkID          forkid.ID
+	Codecs          []Codec `rlp:"optional"` // supported payload codecs, from ACNv2 on
+	Challenge       []byte  `rlp:"optional"` // fresh challenge to be signed by the remote validator, from ACNv3 on
+}
+
+// AdvertisedVersions returns the supported versions among the configured ones, in order of
+// preference. All the supported versions are advertised if none is configured. Advertising
+// the old version only lets the upgraded nodes join a network which did not upgrade yet.
+func AdvertisedVersions(configured []uint) ([]uint, error) {
+	if len(configured) == 0 {
+		return ProtocolVersions, nil
+	}
+	for _, version := range configured {
+		if _, ok := protocolLengths[version]; !ok {
+			return nil, fmt.Errorf("%w: %d", errUnsupportedVersion, version)
+		}
+	}
+	var versions []uint
+	for _, version := range ProtocolVersions {
+		for _, c := range configured {
+			if c == version {
+				versions = append(versions, version)
+				break
+			}
+		}
+	}
+	return versions, nil
 }
@@ -0,0 +1,26 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvertisedVersions(t *testing.T) {
+	versions, err := AdvertisedVersions(nil)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolVersions, versions)
+
+	// the old version only, during an upgrade
+	versions, err = AdvertisedVersions([]uint{ACNv1})
+	require.NoError(t, err)
+	require.Equal(t, []uint{ACNv1}, versions)
+
+	// the order of preference does not depend on the configuration
+	versions, err = AdvertisedVersions([]uint{ACNv1, ACNv2})
+	require.NoError(t, err)
+	require.Equal(t, []uint{ACNv2, ACNv1}, versions)
+
+	_, err = AdvertisedVersions([]uint{ACNv2, 42})
+	require.ErrorIs(t, err, errUnsupportedVersion)
+}
@@ -0,0 +1,63 @@
+package acn
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/acn/protocol"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/metrics"
+)
+
+// committeePeersGauges count the connected committee peers by negotiated `acn` version. Once no
+// committee peer is left on an old version, the nodes can stop advertising it.
+var committeePeersGauges = func() map[uint]metrics.Gauge {
+	gauges := make(map[uint]metrics.Gauge, len(protocol.ProtocolVersions))
+	for _, version := range protocol.ProtocolVersions {
+		gauges[version] = metrics.NewRegisteredGauge(fmt.Sprintf("acn/committee/peers/v%d", version), nil)
+	}
+	return gauges
+}()
+
+// versionMix is the number of connected committee peers by negotiated `acn` version.
+type versionMix struct {
+	sync.RWMutex
+	counts map[uint]int
+}
+
+// update recounts the committee peers, the local node excluded, by negotiated version.
+func (m *versionMix) update(committee types.Committee, self common.Address, peers *peerSet, versions []uint) {
+	counts := make(map[uint]int, len(versions))
+	for _, version := range versions {
+		counts[version] = 0
+	}
+	for _, member := range committee {
+		if member.Address == self {
+			continue
+		}
+		if peer, ok := peers.peer(member.Address); ok {
+			counts[peer.Version()]++
+		}
+	}
+	m.Lock()
+	m.counts = counts
+	m.Unlock()
+
+	if metrics.Enabled {
+		for version, gauge := range committeePeersGauges {
+			gauge.Update(int64(counts[version]))
+		}
+	}
+}
+
+// get returns a copy of the last counts.
+func (m *versionMix) get() map[uint]int {
+	m.RLock()
+	defer m.RUnlock()
+	counts := make(map[uint]int, len(m.counts))
+	for version, count := range m.counts {
+		counts[version] = count
+	}
+	return counts
+}
@@ -21,11 +21,13 @@ import (
 	"context"
 	"math/big"
 
-	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/rotatingbloom"
 
+	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/core/state"
 	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/p2p"
 	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/rpc"
@@ -165,8 +167,25 @@ type BFT interface {
 	Start(ctx context.Context) error
 }
 
+// BFTLifecycle is a BFT engine wired to the chain it seals before it runs: it is
+// attached once, before being started or handling any message, and reports when
+// it is ready. C is the chain type, the consensus package not depending on it.
+type BFTLifecycle[C any] interface {
+	// Attach wires the engine to the chain and its protocol contracts.
+	Attach(chain C, contracts *autonity.ProtocolContracts) error
+
+	// Ready returns a channel closed once the engine is attached.
+	Ready() <-chan struct{}
+}
+
+// CandidateRequester is a consensus engine which can ask the miner for a fresh candidate block.
+type CandidateRequester interface {
+	// SubscribeCandidateRequests subscribes to the requests of a fresh candidate block.
+	SubscribeCandidateRequests(ch chan<- struct{}) event.Subscription
+}
+
 type Syncer interface {
-	SyncPeer(address common.Address)
+	SyncPeer(address common.Address, request []byte)
 }
 
 // Enqueuer defines the interface to enqueue blocks to fetcher
@@ -189,5 +208,7 @@ type Peer interface {
 
 	SendRaw(msgcode uint64, data []byte) error
 
-	Cache() *fixsizecache.Cache[common.Hash, bool]
+	// KnownMessages holds the hashes of the consensus messages the peer has, of the current and the
+	// previous heights
+	KnownMessages() *rotatingbloom.Filter
 }
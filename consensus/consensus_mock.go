@@ -14,7 +14,7 @@ import (
 	reflect "reflect"
 
 	common "github.com/autonity/autonity/common"
-	fixsizecache "github.com/autonity/autonity/common/fixsizecache"
+	rotatingbloom "github.com/autonity/autonity/common/rotatingbloom"
 	state "github.com/autonity/autonity/core/state"
 	types "github.com/autonity/autonity/core/types"
 	p2p "github.com/autonity/autonity/p2p"
@@ -1031,15 +1031,15 @@ func (m *MockSyncer) EXPECT() *MockSyncerMockRecorder {
 }
 
 // SyncPeer mocks base method.
-func (m *MockSyncer) SyncPeer(address common.Address) {
+func (m *MockSyncer) SyncPeer(address common.Address, request []byte) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SyncPeer", address)
+	m.ctrl.Call(m, "SyncPeer", address, request)
 }
 
 // SyncPeer indicates an expected call of SyncPeer.
-func (mr *MockSyncerMockRecorder) SyncPeer(address any) *gomock.Call {
+func (mr *MockSyncerMockRecorder) SyncPeer(address, request any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPeer", reflect.TypeOf((*MockSyncer)(nil).SyncPeer), address)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPeer", reflect.TypeOf((*MockSyncer)(nil).SyncPeer), address, request)
 }
 
 // MockEnqueuer is a mock of Enqueuer interface.
@@ -1152,18 +1152,18 @@ func (m *MockPeer) EXPECT() *MockPeerMockRecorder {
 	return m.recorder
 }
 
-// Cache mocks base method.
-func (m *MockPeer) Cache() *fixsizecache.Cache[common.Hash, bool] {
+// KnownMessages mocks base method.
+func (m *MockPeer) KnownMessages() *rotatingbloom.Filter {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Cache")
-	ret0, _ := ret[0].(*fixsizecache.Cache[common.Hash, bool])
+	ret := m.ctrl.Call(m, "KnownMessages")
+	ret0, _ := ret[0].(*rotatingbloom.Filter)
 	return ret0
 }
 
-// Cache indicates an expected call of Cache.
-func (mr *MockPeerMockRecorder) Cache() *gomock.Call {
+// KnownMessages indicates an expected call of KnownMessages.
+func (mr *MockPeerMockRecorder) KnownMessages() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cache", reflect.TypeOf((*MockPeer)(nil).Cache))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KnownMessages", reflect.TypeOf((*MockPeer)(nil).KnownMessages))
 }
 
 // Send mocks base method.
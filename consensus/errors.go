@@ -41,4 +41,11 @@ var (
 	// ErrCommitteeMemberNotFound is returned if the committee member is missing from
 	// the committee set.
 	ErrCommitteeMemberNotFound = errors.New("committee member not found")
+
+	// ErrEngineNotAttached is returned if a BFT engine is started, or handles a
+	// message, before being attached to its chain.
+	ErrEngineNotAttached = errors.New("consensus engine not attached to the chain")
+
+	// ErrEngineAttached is returned if a BFT engine is attached more than once.
+	ErrEngineAttached = errors.New("consensus engine already attached to a chain")
 )
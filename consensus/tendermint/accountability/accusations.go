@@ -0,0 +1,263 @@
+package accountability
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/rlp"
+	"github.com/autonity/autonity/rpc"
+)
+
+// accusationsPrefix is the database key prefix of the accusations against the local node awaiting resolution,
+// followed by the hash of the accusation event.
+var accusationsPrefix = []byte("AccountabilityAccusation-")
+
+// pendingAccusation is an on-chain accusation against the local node, along with the proof of innocence
+// built for it.
+type pendingAccusation struct {
+	Event     autonity.AccountabilityEvent
+	Innocence *autonity.AccountabilityEvent `rlp:"nil"` // nil while no proof of innocence was found
+}
+
+// accusationStore tracks the accusations against the local node until they are resolved on-chain, either by
+// the inclusion of the proof of innocence or by their promotion to a fault at the end of the innocence proof
+// window. They are persisted so that a node restarting within the window still submits its proof of innocence,
+// the messages the proof is built out of being lost on restart.
+type accusationStore struct {
+	db     ethdb.KeyValueStore
+	logger log.Logger
+
+	mu      sync.Mutex
+	pending map[common.Hash]*pendingAccusation
+}
+
+func newAccusationStore(db ethdb.KeyValueStore, logger log.Logger) *accusationStore {
+	s := &accusationStore{
+		db:      db,
+		logger:  logger,
+		pending: make(map[common.Hash]*pendingAccusation),
+	}
+	it := db.NewIterator(accusationsPrefix, nil)
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) != len(accusationsPrefix)+common.HashLength {
+			continue
+		}
+		accusation := new(pendingAccusation)
+		if err := rlp.DecodeBytes(it.Value(), accusation); err != nil {
+			logger.Error("Dropping invalid pending accusation", "key", common.Bytes2Hex(it.Key()), "err", err)
+			s.deleteKey(common.CopyBytes(it.Key()))
+			continue
+		}
+		s.pending[common.BytesToHash(it.Key()[len(accusationsPrefix):])] = accusation
+	}
+	return s
+}
+
+// accusationHash returns the hash the accusation event is tracked by.
+func accusationHash(ev *autonity.AccountabilityEvent) common.Hash {
+	return crypto.Keccak256Hash(ev.Id.Bytes(), ev.RawProof)
+}
+
+func accusationKey(hash common.Hash) []byte {
+	return append(append([]byte{}, accusationsPrefix...), hash.Bytes()...)
+}
+
+// add tracks the accusation, along with its proof of innocence if one was found.
+func (s *accusationStore) add(ev *autonity.AccountabilityEvent, innocence *autonity.AccountabilityEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash := accusationHash(ev)
+	accusation := &pendingAccusation{Event: *ev, Innocence: innocence}
+	s.pending[hash] = accusation
+	s.store(hash, accusation)
+}
+
+// setInnocence records the proof of innocence found for a tracked accusation.
+func (s *accusationStore) setInnocence(hash common.Hash, innocence *autonity.AccountabilityEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accusation, ok := s.pending[hash]
+	if !ok {
+		return
+	}
+	accusation.Innocence = innocence
+	s.store(hash, accusation)
+}
+
+// remove stops tracking a resolved accusation.
+func (s *accusationStore) remove(hash common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pending[hash]; !ok {
+		return
+	}
+	delete(s.pending, hash)
+	s.deleteKey(accusationKey(hash))
+}
+
+// list returns the tracked accusations by hash, the oldest first.
+func (s *accusationStore) list() ([]common.Hash, []*pendingAccusation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hashes := make([]common.Hash, 0, len(s.pending))
+	for hash := range s.pending {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return s.pending[hashes[i]].Event.Id.Cmp(s.pending[hashes[j]].Event.Id) < 0
+	})
+	accusations := make([]*pendingAccusation, len(hashes))
+	for i, hash := range hashes {
+		accusations[i] = s.pending[hash]
+	}
+	return hashes, accusations
+}
+
+func (s *accusationStore) store(hash common.Hash, accusation *pendingAccusation) {
+	blob, err := rlp.EncodeToBytes(accusation)
+	if err != nil {
+		s.logger.Error("Cannot encode pending accusation", "err", err)
+		return
+	}
+	if err := s.db.Put(accusationKey(hash), blob); err != nil {
+		s.logger.Error("Cannot store pending accusation", "err", err)
+	}
+}
+
+func (s *accusationStore) deleteKey(key []byte) {
+	if err := s.db.Delete(key); err != nil {
+		s.logger.Error("Cannot delete pending accusation", "err", err)
+	}
+}
+
+// handleAccusation tracks an on-chain accusation against the local node, and reports its proof of innocence
+// if it can be built out of the buffered messages.
+func (fd *FaultDetector) handleAccusation(ev *autonity.AccountabilityEvent) {
+	proof, committee, err := fd.accusationProof(ev)
+	if err != nil {
+		fd.logger.Error("Can't handle accusation", "err", err)
+		return
+	}
+	fd.journal.record(ActionAccusationReceived, proof, committee)
+
+	innocenceProof, err := fd.innocenceProof(proof, committee)
+	if err != nil {
+		innocenceProof = nil
+	}
+	fd.accusations.add(ev, innocenceProof)
+	if innocenceProof != nil {
+		// send on chain innocence proof ASAP since the client is on challenge that requires the proof to be
+		// provided before the client get slashed.
+		fd.logger.Warn("Innocence proof found! reporting...")
+		fd.eventReporterCh <- innocenceProof
+		return
+	}
+	fd.logger.Warn("************************** SLASHING EVENT **************************")
+	fd.logger.Warn("Your local node has been accused of malicious behavior")
+	fd.logger.Warn("A proof of innocence has not been found: the local node is at high risk of slashing")
+	fd.logger.Warn("Reach out to Autonity social media channels for more informations")
+	fd.logger.Warn("********************************************************************")
+	if err != nil {
+		fd.logger.Error("Could not handle accusation", "error", err)
+	}
+}
+
+// accusationProof decodes the proof of an accusation event and recovers the senders of its messages.
+func (fd *FaultDetector) accusationProof(ev *autonity.AccountabilityEvent) (*Proof, types.Committee, error) {
+	proof, err := decodeRawProof(ev.RawProof)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't decode accusation: %w", err)
+	}
+	h := proof.Message.H()
+	lastHeader := fd.blockchain.GetHeaderByNumber(h - 1)
+	if lastHeader == nil {
+		return nil, nil, fmt.Errorf("can't get header %d", h-1)
+	}
+	// The signatures must be valid at this stage, however we have to recover the original
+	// senders, hence the following call.
+	if err = verifyProofSignatures(lastHeader, proof); err != nil {
+		return nil, nil, fmt.Errorf("can't verify proof signatures: %w", err)
+	}
+	return proof, lastHeader.Committee, nil
+}
+
+// resumeAccusations reports again the proofs of innocence of the accusations still pending after a restart, as
+// they might not have been included yet. The submissions already pending are not sent twice.
+func (fd *FaultDetector) resumeAccusations() {
+	_, accusations := fd.pendingAccusations()
+	for _, accusation := range accusations {
+		if accusation.Innocence == nil {
+			fd.logger.Warn("Accusation pending against the local node, no proof of innocence", "id", accusation.Event.Id,
+				"rule", autonity.Rule(accusation.Event.Rule).String())
+			continue
+		}
+		fd.logger.Warn("Resuming the innocence proof submission", "id", accusation.Event.Id,
+			"rule", autonity.Rule(accusation.Event.Rule).String())
+		fd.eventReporterCh <- accusation.Innocence
+	}
+}
+
+// checkAccusations is called at each block to stop tracking the resolved accusations, and to report the
+// proof of innocence of the pending ones which can be built since.
+func (fd *FaultDetector) checkAccusations() {
+	hashes, accusations := fd.pendingAccusations()
+	for i, accusation := range accusations {
+		if accusation.Innocence != nil {
+			continue
+		}
+		proof, committee, err := fd.accusationProof(&accusation.Event)
+		if err != nil {
+			continue
+		}
+		if innocenceProof, err := fd.innocenceProof(proof, committee); err == nil && innocenceProof != nil {
+			fd.logger.Warn("Innocence proof found! reporting...", "id", accusation.Event.Id)
+			fd.accusations.setInnocence(hashes[i], innocenceProof)
+			fd.eventReporterCh <- innocenceProof
+		}
+	}
+}
+
+// pendingAccusations drops the tracked accusations which are not pending on-chain anymore, and returns the
+// others.
+func (fd *FaultDetector) pendingAccusations() ([]common.Hash, []*pendingAccusation) {
+	hashes, accusations := fd.accusations.list()
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	// the contract reverts if no accusation is pending against the validator
+	onChain, err := fd.protocolContracts.GetValidatorAccusation(nil, fd.address)
+	if err != nil && !isReverted(err) {
+		fd.logger.Debug("Cannot retrieve the accusation pending against the local node", "err", err)
+		return hashes, accusations
+	}
+	var (
+		pendingHashes []common.Hash
+		pending       []*pendingAccusation
+	)
+	for i, accusation := range accusations {
+		if err == nil && onChain.Id.Cmp(accusation.Event.Id) == 0 {
+			pendingHashes = append(pendingHashes, hashes[i])
+			pending = append(pending, accusation)
+			continue
+		}
+		fd.logger.Info("Accusation against the local node resolved", "id", accusation.Event.Id)
+		fd.accusations.remove(hashes[i])
+	}
+	return pendingHashes, pending
+}
+
+// isReverted reports whether the contract call error is an execution revert.
+func isReverted(err error) bool {
+	var dataErr rpc.DataError
+	return errors.Is(err, vm.ErrExecutionReverted) || errors.As(err, &dataErr)
+}
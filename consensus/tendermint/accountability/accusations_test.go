@@ -0,0 +1,113 @@
+package accountability
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/accounts/abi/bind/backends"
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+	ccore "github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/internal/ethapi"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rlp"
+)
+
+// chainDbBackend provides the chain database to the fault detector.
+type chainDbBackend struct {
+	ethapi.Backend
+	db ethdb.Database
+}
+
+func (b *chainDbBackend) ChainDb() ethdb.Database {
+	return b.db
+}
+
+func TestPendingAccusationsPersistence(t *testing.T) {
+	height := uint64(100)
+	round := int64(3)
+	validRound := int64(1)
+	lastHeader := &types.Header{Number: new(big.Int).SetUint64(height - 1), Committee: committee}
+	db := rawdb.NewMemoryDatabase()
+
+	newFaultDetector := func() *FaultDetector {
+		ctrl := gomock.NewController(t)
+		chainMock := NewMockChainContext(ctrl)
+		chainMock.EXPECT().GetHeaderByNumber(height - 1).AnyTimes().Return(lastHeader)
+		chainMock.EXPECT().CurrentHeader().AnyTimes().Return(&types.Header{Number: new(big.Int).SetUint64(height + 1)})
+		chainMock.EXPECT().Config().AnyTimes().Return(&params.ChainConfig{ChainID: common.Big1})
+		var chainFeed event.Feed
+		chainMock.EXPECT().SubscribeChainEvent(gomock.Any()).AnyTimes().DoAndReturn(func(ch chan<- ccore.ChainEvent) event.Subscription {
+			return chainFeed.Subscribe(ch)
+		})
+		bindings, _ := autonity.NewAccountability(proposer, backends.NewSimulatedBackend(ccore.GenesisAlloc{proposer: {Balance: big.NewInt(params.Ether)}}, 10000000))
+		return NewFaultDetector(chainMock, proposer, new(event.TypeMux).Subscribe(events.MessageEvent{}), core.NewMsgStore(), nil,
+			&chainDbBackend{db: db}, proposerNodeKey, &autonity.ProtocolContracts{Accountability: bindings}, log.Root())
+	}
+
+	// the local node proposed an old value, with a quorum of prevotes for it at the valid round
+	fd := newFaultDetector()
+	proposal := newValidatedProposalMessage(height, round, validRound, signer, committee, nil, proposerIdx)
+	fd.msgStore.Save(proposal)
+	fd.msgStore.Save(aggregatedPreVote(len(committee), height, validRound, proposal.Value(), keys, committee))
+
+	rawProof, err := rlp.EncodeToBytes(&Proof{
+		OffenderIndex: proposerIdx,
+		Type:          autonity.Accusation,
+		Rule:          autonity.PO,
+		Message:       proposal.ToLight(),
+	})
+	require.NoError(t, err)
+	accusation := &autonity.AccountabilityEvent{
+		EventType:      uint8(autonity.Accusation),
+		Rule:           uint8(autonity.PO),
+		Reporter:       remotePeer,
+		Offender:       proposer,
+		RawProof:       rawProof,
+		Id:             big.NewInt(7),
+		Block:          new(big.Int).SetUint64(height),
+		Epoch:          common.Big0,
+		ReportingBlock: new(big.Int).SetUint64(height + 1),
+		MessageHash:    common.Big0,
+	}
+	fd.handleAccusation(accusation)
+	innocence := <-fd.eventReporterCh
+	require.Equal(t, uint8(autonity.Innocence), innocence.EventType)
+
+	// the node restarts before the proof of innocence got included, the messages are lost
+	fd = newFaultDetector()
+	require.Empty(t, fd.msgStore.GetProposals(height, func(*message.Propose) bool { return true }))
+	hashes, pending := fd.accusations.list()
+	require.Equal(t, []common.Hash{accusationHash(accusation)}, hashes)
+	require.Equal(t, accusation.RawProof, pending[0].Event.RawProof)
+
+	chain := newSimulatedSubmissionChain(common.Big1)
+	fd.submissions = newSubmissionMonitor(chain, db, fd.txOpts, log.Root())
+	fd.Start()
+	defer fd.Stop()
+	require.Eventually(t, func() bool {
+		return len(fd.submissions.pendingSubmissions()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	submitted := fd.submissions.pendingSubmissions()[0].Event
+	require.Equal(t, innocence.EventType, submitted.EventType)
+	require.Equal(t, innocence.Rule, submitted.Rule)
+	require.Equal(t, proposer, submitted.Offender)
+	require.Equal(t, innocence.RawProof, submitted.RawProof)
+
+	// resolved accusations are deleted
+	fd.accusations.remove(hashes[0])
+	hashes, _ = newAccusationStore(db, log.Root()).list()
+	require.Empty(t, hashes)
+}
@@ -0,0 +1,269 @@
+package accountability
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/ethdb"
+)
+
+const (
+	// arrivalCodes is the number of message codes the arrivals are tracked for: proposals, prevotes
+	// and precommits, indexed by their code.
+	arrivalCodes = int(message.PrecommitCode) + 1
+	// ArrivalMemoryWindow is the number of heights below the highest one recorded whose arrivals are
+	// kept in memory, for the features reading the recent heights. The older heights are spilled to
+	// the database.
+	ArrivalMemoryWindow = 2 * DeltaBlocks
+	// ArrivalWindow is the number of heights below the highest one recorded whose arrivals are kept
+	// at all, the accountability window.
+	ArrivalWindow = HeightRange
+
+	// noArrival marks the absence of arrival in the offsets, which are shifted by one.
+	noArrival = 0
+	// maxArrivalOffset saturates the offsets, at about 49 days.
+	maxArrivalOffset = math.MaxUint32 - 1
+	// heightArrivalsOverhead is the memory taken by a height besides its offsets: the struct, the slice
+	// header and the index entry.
+	heightArrivalsOverhead = 64
+)
+
+var errCorruptedArrivals = errors.New("corrupted consensus arrivals")
+
+// heightArrivals holds the first arrival of the messages of a height, per committee member and code.
+// The arrivals are offsets in milliseconds from the start of the height, the first arrival recorded.
+type heightArrivals struct {
+	start   int64    // unix milliseconds
+	offsets []uint32 // committee size * arrivalCodes, shifted by one, noArrival if none
+}
+
+func newHeightArrivals(committeeSize int, start int64) *heightArrivals {
+	return &heightArrivals{start: start, offsets: make([]uint32, committeeSize*arrivalCodes)}
+}
+
+func (h *heightArrivals) committeeSize() int {
+	return len(h.offsets) / arrivalCodes
+}
+
+func saturatingOffset(offset int64) uint32 {
+	if offset > maxArrivalOffset {
+		return maxArrivalOffset
+	}
+	return uint32(offset)
+}
+
+// record records the arrival unless an earlier one is known.
+func (h *heightArrivals) record(index int, code uint8, at int64) {
+	if at < h.start {
+		// the start of the height moves to the earliest arrival
+		shift := h.start - at
+		for i, offset := range h.offsets {
+			if offset != noArrival {
+				h.offsets[i] = saturatingOffset(int64(offset) + shift)
+			}
+		}
+		h.start = at
+	}
+	slot := index*arrivalCodes + int(code)
+	offset := saturatingOffset(at - h.start + 1)
+	if h.offsets[slot] == noArrival || offset < h.offsets[slot] {
+		h.offsets[slot] = offset
+	}
+}
+
+// encode encodes the arrivals as the start, followed by the offsets, big endian.
+func (h *heightArrivals) encode() []byte {
+	data := make([]byte, 8+4*len(h.offsets))
+	binary.BigEndian.PutUint64(data, uint64(h.start))
+	for i, offset := range h.offsets {
+		binary.BigEndian.PutUint32(data[8+4*i:], offset)
+	}
+	return data
+}
+
+func decodeHeightArrivals(data []byte) (*heightArrivals, error) {
+	if len(data) < 8 || (len(data)-8)%(4*arrivalCodes) != 0 {
+		return nil, errCorruptedArrivals
+	}
+	h := &heightArrivals{start: int64(binary.BigEndian.Uint64(data)), offsets: make([]uint32, (len(data)-8)/4)}
+	for i := range h.offsets {
+		h.offsets[i] = binary.BigEndian.Uint32(data[8+4*i:])
+	}
+	return h, nil
+}
+
+// HeightArrivals is a read-only view of the first arrivals of the messages of a height.
+type HeightArrivals struct {
+	h *heightArrivals
+}
+
+// Start returns the first arrival recorded for the height, all the arrivals being offsets from it.
+func (a HeightArrivals) Start() time.Time {
+	return time.UnixMilli(a.h.start)
+}
+
+// CommitteeSize returns the number of committee members the arrivals are tracked for.
+func (a HeightArrivals) CommitteeSize() int {
+	return a.h.committeeSize()
+}
+
+// First returns the offset from the start of the height of the first message of the given code
+// signed by the committee member, and whether there was one.
+func (a HeightArrivals) First(index int, code uint8) (time.Duration, bool) {
+	if index < 0 || index >= a.h.committeeSize() || int(code) >= arrivalCodes {
+		return 0, false
+	}
+	offset := a.h.offsets[index*arrivalCodes+int(code)]
+	if offset == noArrival {
+		return 0, false
+	}
+	return time.Duration(offset-1) * time.Millisecond, true
+}
+
+// ArrivalIndex tracks the first arrival of the consensus messages per height, committee member and
+// code, for the features working on the arrival times over the accountability window. The recent
+// heights are kept in memory, the older ones are spilled to the database and read through on demand.
+type ArrivalIndex struct {
+	db        ethdb.KeyValueStore
+	memWindow uint64
+	window    uint64
+
+	mu      sync.RWMutex
+	heights map[uint64]*heightArrivals // in memory heights
+	highest uint64                     // highest height recorded
+	tail    uint64                     // lowest height possibly stored in the database, noTail if none
+}
+
+// noTail is the tail of an index without height stored in the database.
+const noTail = math.MaxUint64
+
+// NewArrivalIndex creates an arrival index keeping memWindow heights in memory and window heights
+// overall, below the highest height recorded. The heights stored by a previous run are kept until
+// they leave the window.
+func NewArrivalIndex(db ethdb.KeyValueStore, memWindow, window uint64) *ArrivalIndex {
+	ix := &ArrivalIndex{
+		db:        db,
+		memWindow: memWindow,
+		window:    max(window, memWindow),
+		heights:   make(map[uint64]*heightArrivals),
+		tail:      noTail,
+	}
+	if tail, ok := rawdb.ReadConsensusArrivalsTail(db); ok {
+		ix.tail = tail
+	}
+	return ix
+}
+
+// Record records the arrival of a message of the given code signed by the committee member, unless an
+// earlier one is known. The committee size sizes the height on its first arrival. The arrivals of the
+// heights out of the memory window are dropped.
+func (ix *ArrivalIndex) Record(height uint64, committeeSize int, index int, code uint8, at time.Time) {
+	if int(code) >= arrivalCodes || index < 0 {
+		return
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if height > ix.highest {
+		ix.highest = height
+		ix.spill()
+	}
+	if height+ix.memWindow < ix.highest {
+		return
+	}
+	h, ok := ix.heights[height]
+	if !ok {
+		h = newHeightArrivals(committeeSize, at.UnixMilli())
+		ix.heights[height] = h
+	}
+	if index >= h.committeeSize() {
+		return
+	}
+	h.record(index, code, at.UnixMilli())
+}
+
+// RecordMsg records the arrival of the message for each of its signers.
+func (ix *ArrivalIndex) RecordMsg(m message.Msg, committeeSize int, at time.Time) {
+	switch msg := m.(type) {
+	case *message.Propose:
+		ix.Record(msg.H(), committeeSize, msg.SignerIndex(), msg.Code(), at)
+	case *message.Prevote:
+		for _, signer := range msg.Signers().FlattenUniq() {
+			ix.Record(msg.H(), committeeSize, signer, msg.Code(), at)
+		}
+	case *message.Precommit:
+		for _, signer := range msg.Signers().FlattenUniq() {
+			ix.Record(msg.H(), committeeSize, signer, msg.Code(), at)
+		}
+	}
+}
+
+// spill moves the heights out of the memory window to the database, and deletes the heights out of
+// the window. ix.mu must be held.
+func (ix *ArrivalIndex) spill() {
+	for height, h := range ix.heights {
+		if height+ix.memWindow < ix.highest {
+			rawdb.WriteConsensusArrivals(ix.db, height, h.encode())
+			delete(ix.heights, height)
+			ix.tail = min(ix.tail, height)
+		}
+	}
+	if ix.highest <= ix.window || ix.tail == noTail {
+		return
+	}
+	if threshold := ix.highest - ix.window; ix.tail < threshold {
+		rawdb.DeleteConsensusArrivalsBelow(ix.db, threshold)
+		ix.tail = threshold
+	}
+}
+
+// Flush writes the in-memory heights to the database, for a restarted index to read them through.
+func (ix *ArrivalIndex) Flush() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for height, h := range ix.heights {
+		rawdb.WriteConsensusArrivals(ix.db, height, h.encode())
+		ix.tail = min(ix.tail, height)
+	}
+}
+
+// Arrivals returns the first arrivals of the messages of the height, from memory or from the
+// database, and whether any is known within the window.
+func (ix *ArrivalIndex) Arrivals(height uint64) (HeightArrivals, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if ix.highest > ix.window && height < ix.highest-ix.window {
+		return HeightArrivals{}, false
+	}
+	if h, ok := ix.heights[height]; ok {
+		// the view is a copy, the height keeps being recorded
+		return HeightArrivals{h: &heightArrivals{start: h.start, offsets: append([]uint32(nil), h.offsets...)}}, true
+	}
+	data := rawdb.ReadConsensusArrivals(ix.db, height)
+	if data == nil {
+		return HeightArrivals{}, false
+	}
+	h, err := decodeHeightArrivals(data)
+	if err != nil {
+		return HeightArrivals{}, false
+	}
+	return HeightArrivals{h: h}, true
+}
+
+// MemoryFootprint returns an estimate of the memory taken by the in-memory heights, in bytes.
+func (ix *ArrivalIndex) MemoryFootprint() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	size := 0
+	for _, h := range ix.heights {
+		size += heightArrivalsOverhead + 4*cap(h.offsets)
+	}
+	return size
+}
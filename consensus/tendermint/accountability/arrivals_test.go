@@ -0,0 +1,139 @@
+package accountability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
+)
+
+func TestArrivalIndexMemoryFootprint(t *testing.T) {
+	const (
+		committeeSize = 200
+		memWindow     = 20
+		rounds        = 5
+	)
+	ix := NewArrivalIndex(rawdb.NewMemoryDatabase(), memWindow, 100)
+	start := time.UnixMilli(1_000_000)
+	perHeight := heightArrivalsOverhead + committeeSize*arrivalCodes*4
+	for height := uint64(1); height <= 300; height++ {
+		// the messages of every round and every member only keep the first arrival
+		for round := 0; round < rounds; round++ {
+			for index := 0; index < committeeSize; index++ {
+				for code := uint8(0); code < uint8(arrivalCodes); code++ {
+					ix.Record(height, committeeSize, index, code, start.Add(time.Duration(round)*time.Second))
+				}
+			}
+		}
+		require.LessOrEqual(t, ix.MemoryFootprint(), (memWindow+1)*perHeight)
+	}
+	// about 2.4KB per height at 200 members
+	require.Equal(t, (memWindow+1)*perHeight, ix.MemoryFootprint())
+	require.Len(t, ix.heights, memWindow+1)
+}
+
+func TestArrivalIndexFirstArrival(t *testing.T) {
+	ix := NewArrivalIndex(rawdb.NewMemoryDatabase(), 4, 16)
+	start := time.UnixMilli(1_000_000)
+	ix.Record(10, 4, 1, message.PrevoteCode, start)
+	ix.Record(10, 4, 1, message.PrevoteCode, start.Add(time.Second))
+	ix.Record(10, 4, 2, message.PrecommitCode, start.Add(300*time.Millisecond))
+	// an earlier arrival moves the start of the height
+	ix.Record(10, 4, 0, message.ProposalCode, start.Add(-100*time.Millisecond))
+	// out of range members and codes are ignored
+	ix.Record(10, 4, 4, message.PrevoteCode, start)
+	ix.Record(10, 4, 0, message.PrecommitCode+1, start)
+
+	arrivals, ok := ix.Arrivals(10)
+	require.True(t, ok)
+	require.Equal(t, 4, arrivals.CommitteeSize())
+	require.Equal(t, start.Add(-100*time.Millisecond), arrivals.Start())
+	first := func(index int, code uint8) time.Duration {
+		offset, ok := arrivals.First(index, code)
+		require.True(t, ok)
+		return offset
+	}
+	require.Equal(t, time.Duration(0), first(0, message.ProposalCode))
+	require.Equal(t, 100*time.Millisecond, first(1, message.PrevoteCode))
+	require.Equal(t, 400*time.Millisecond, first(2, message.PrecommitCode))
+	_, ok = arrivals.First(3, message.PrevoteCode)
+	require.False(t, ok)
+	_, ok = arrivals.First(4, message.PrevoteCode)
+	require.False(t, ok)
+
+	// the view is not affected by the later arrivals
+	ix.Record(10, 4, 3, message.PrevoteCode, start)
+	_, ok = arrivals.First(3, message.PrevoteCode)
+	require.False(t, ok)
+}
+
+func TestArrivalIndexSpill(t *testing.T) {
+	const (
+		memWindow = 4
+		window    = 16
+	)
+	db := rawdb.NewMemoryDatabase()
+	ix := NewArrivalIndex(db, memWindow, window)
+	start := time.UnixMilli(1_000_000)
+	record := func(ix *ArrivalIndex, from, to uint64) {
+		for height := from; height <= to; height++ {
+			ix.Record(height, 3, int(height%3), message.PrevoteCode, start.Add(time.Duration(height)*time.Second))
+			ix.Record(height, 3, 0, message.ProposalCode, start.Add(time.Duration(height)*time.Second+time.Duration(height)*time.Millisecond))
+		}
+	}
+	check := func(ix *ArrivalIndex, height uint64) {
+		arrivals, ok := ix.Arrivals(height)
+		require.True(t, ok, "height %d", height)
+		require.Equal(t, start.Add(time.Duration(height)*time.Second), arrivals.Start())
+		offset, ok := arrivals.First(int(height%3), message.PrevoteCode)
+		require.True(t, ok)
+		require.Zero(t, offset)
+		offset, ok = arrivals.First(0, message.ProposalCode)
+		require.True(t, ok)
+		require.Equal(t, time.Duration(height)*time.Millisecond, offset)
+	}
+
+	record(ix, 1, 30)
+	require.Len(t, ix.heights, memWindow+1)
+	// the heights are read through from memory and the database alike
+	for height := uint64(30 - window); height <= 30; height++ {
+		check(ix, height)
+	}
+	_, inMemory := ix.heights[30-memWindow-1]
+	require.False(t, inMemory)
+	require.NotNil(t, rawdb.ReadConsensusArrivals(db, 30-memWindow-1))
+
+	// the heights out of the window are deleted
+	for height := uint64(1); height < 30-window; height++ {
+		_, ok := ix.Arrivals(height)
+		require.False(t, ok, "height %d", height)
+		require.Nil(t, rawdb.ReadConsensusArrivals(db, height), "height %d", height)
+	}
+	tail, ok := rawdb.ReadConsensusArrivalsTail(db)
+	require.True(t, ok)
+	require.Equal(t, uint64(30-window), tail)
+
+	// the arrivals too old for the memory window are dropped
+	ix.Record(30-memWindow-1, 3, 2, message.PrecommitCode, start)
+	arrivals, ok := ix.Arrivals(30 - memWindow - 1)
+	require.True(t, ok)
+	_, ok = arrivals.First(2, message.PrecommitCode)
+	require.False(t, ok)
+
+	// a restarted index reads the flushed heights and deletes them once out of the window
+	ix.Flush()
+	restarted := NewArrivalIndex(db, memWindow, window)
+	require.Equal(t, uint64(30-window), restarted.tail)
+	check(restarted, 30-memWindow-1)
+	record(restarted, 31, 40)
+	check(restarted, 30-memWindow-1)
+	for height := uint64(40 - window); height <= 40; height++ {
+		check(restarted, height)
+	}
+	for height := uint64(30 - window); height < 40-window; height++ {
+		require.Nil(t, rawdb.ReadConsensusArrivals(db, height), "height %d", height)
+	}
+}
@@ -0,0 +1,199 @@
+package accountability
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/bft"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/rlp"
+)
+
+// maxJournalEntries is the number of proofs handled kept in the event journal.
+const maxJournalEntries = 1024
+
+var errUnknownCommittee = errors.New("unknown committee")
+
+// AuditSnapshot records the inputs a proof was produced or validated with, for the verdict to be
+// reproduced by later audits. The committee is referenced by hash, see types.Committee.Hash.
+type AuditSnapshot struct {
+	OffenceHeight    uint64
+	CommitteeHash    common.Hash // hash of the committee of the offence height
+	TotalVotingPower *big.Int
+	QuorumThreshold  *big.Int
+	DeltaBlocks      uint64
+	RulesVersion     uint64
+}
+
+// NewAuditSnapshot snapshots the inputs of the proofs of the offence height, given the committee
+// of the parent header.
+func NewAuditSnapshot(height uint64, committee types.Committee) AuditSnapshot {
+	total := committee.TotalVotingPower()
+	return AuditSnapshot{
+		OffenceHeight:    height,
+		CommitteeHash:    committee.Hash(),
+		TotalVotingPower: total,
+		QuorumThreshold:  bft.Quorum(total),
+		DeltaBlocks:      DeltaBlocks,
+		RulesVersion:     RulesVersion,
+	}
+}
+
+// JournalAction is the way a proof was handled by the fault detector.
+type JournalAction string
+
+const (
+	ActionDetected           JournalAction = "detected"           // misbehaviour found by the local rule engine
+	ActionAccused            JournalAction = "accused"            // accusation sent off-chain to the suspect
+	ActionEscalated          JournalAction = "escalated"          // off-chain accusation left unanswered, escalated on-chain
+	ActionInnocenceReceived  JournalAction = "innocenceReceived"  // valid innocence proof received for an off-chain accusation
+	ActionAccusationReceived JournalAction = "accusationReceived" // valid accusation against the local node, on or off-chain
+	ActionSubmitted          JournalAction = "submitted"          // proof sent on-chain
+)
+
+// JournalEntry is a proof handled by the fault detector, along with the inputs it was handled with.
+type JournalEntry struct {
+	Seq       uint64
+	Time      time.Time
+	Action    JournalAction
+	Type      autonity.AccountabilityEventType
+	Rule      autonity.Rule
+	Offender  common.Address
+	ProofHash common.Hash // canonical hash of the proof
+	Audit     AuditSnapshot
+}
+
+// eventJournal keeps the last proofs handled, along with the committees they reference.
+type eventJournal struct {
+	mu         sync.RWMutex
+	entries    []*JournalEntry
+	next       uint64
+	committees map[common.Hash]*journalCommittee
+}
+
+type journalCommittee struct {
+	committee types.Committee
+	refs      int
+}
+
+func newEventJournal() *eventJournal {
+	return &eventJournal{next: 1, committees: make(map[common.Hash]*journalCommittee)}
+}
+
+// record journals the handling of the proof, whose offence height has the given committee.
+func (j *eventJournal) record(action JournalAction, proof *Proof, committee types.Committee) *JournalEntry {
+	hash, _ := proof.CanonicalHash()
+	entry := &JournalEntry{
+		Time:      time.Now(),
+		Action:    action,
+		Type:      proof.Type,
+		Rule:      proof.Rule,
+		ProofHash: hash,
+		Audit:     NewAuditSnapshot(proof.Message.H(), committee),
+	}
+	if proof.OffenderIndex >= 0 && proof.OffenderIndex < len(committee) {
+		entry.Offender = committee[proof.OffenderIndex].Address
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry.Seq = j.next
+	j.next++
+	j.entries = append(j.entries, entry)
+	if ref, ok := j.committees[entry.Audit.CommitteeHash]; ok {
+		ref.refs++
+	} else {
+		j.committees[entry.Audit.CommitteeHash] = &journalCommittee{committee: committee, refs: 1}
+	}
+	if len(j.entries) > maxJournalEntries {
+		pruned := j.entries[0]
+		j.entries = j.entries[1:]
+		ref := j.committees[pruned.Audit.CommitteeHash]
+		if ref.refs--; ref.refs == 0 {
+			delete(j.committees, pruned.Audit.CommitteeHash)
+		}
+	}
+	return entry
+}
+
+// Entries returns up to limit entries following afterSeq, in order, along with the sequence numbers of
+// the oldest entry kept and of the next entry to be journaled. A first entry with a sequence number
+// above afterSeq+1 means that the entries in between were pruned.
+func (j *eventJournal) Entries(afterSeq uint64, limit int) (entries []*JournalEntry, first, next uint64) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	first = j.next
+	if len(j.entries) > 0 {
+		first = j.entries[0].Seq
+	}
+	for _, entry := range j.entries {
+		if len(entries) == limit {
+			break
+		}
+		if entry.Seq > afterSeq {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, first, j.next
+}
+
+// committee returns the committee referenced by a journal entry.
+func (j *eventJournal) committee(hash common.Hash) (types.Committee, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	ref, ok := j.committees[hash]
+	if !ok {
+		return nil, false
+	}
+	return ref.committee, true
+}
+
+// AccountabilityEvents returns up to limit entries of the event journal following afterSeq, see
+// eventJournal.Entries.
+func (fd *FaultDetector) AccountabilityEvents(afterSeq uint64, limit int) (entries []*JournalEntry, first, next uint64) {
+	return fd.journal.Entries(afterSeq, limit)
+}
+
+// PendingSubmissions returns the accountability events pending inclusion, along with their audit snapshot.
+func (fd *FaultDetector) PendingSubmissions() []PendingSubmission {
+	return fd.submissions.pendingSubmissions()
+}
+
+// CommitteeByHash resolves the committee referenced by the audit snapshots, either of the journal
+// entries or of the pending submissions.
+func (fd *FaultDetector) CommitteeByHash(hash common.Hash) (types.Committee, error) {
+	if committee, ok := fd.journal.committee(hash); ok {
+		return committee, nil
+	}
+	// the pending submissions are persisted across restarts, unlike the journal
+	for _, s := range fd.submissions.pendingSubmissions() {
+		if s.Audit == nil || s.Audit.CommitteeHash != hash || s.Audit.OffenceHeight == 0 {
+			continue
+		}
+		if header := fd.blockchain.GetHeaderByNumber(s.Audit.OffenceHeight - 1); header != nil && header.Committee.Hash() == hash {
+			return header.Committee, nil
+		}
+	}
+	return nil, errUnknownCommittee
+}
+
+// proofCommittee decodes the proof and returns it along with the committee of its offence height.
+func (fd *FaultDetector) proofCommittee(rawProof []byte) (*Proof, types.Committee, error) {
+	proof := new(Proof)
+	if err := rlp.DecodeBytes(rawProof, proof); err != nil {
+		return nil, nil, err
+	}
+	height := proof.Message.H()
+	if height == 0 {
+		return nil, nil, errNoParentHeader
+	}
+	header := fd.blockchain.GetHeaderByNumber(height - 1)
+	if header == nil {
+		return nil, nil, errNoParentHeader
+	}
+	return proof, header.Committee, nil
+}
@@ -0,0 +1,128 @@
+package accountability
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/accounts/abi/bind/backends"
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/bft"
+	"github.com/autonity/autonity/consensus/tendermint/core"
+	ccore "github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+)
+
+func TestAuditSnapshotAcrossEpochs(t *testing.T) {
+	// the offence happens at the last height of epoch N, it is detected once the chain is in epoch N+1
+	// whose committee differs.
+	const epochPeriod = 30
+	offenceHeight := uint64(2*epochPeriod - 1)
+	chainHead := offenceHeight + DeltaBlocks
+	require.Equal(t, uint64(1), offenceHeight/epochPeriod)
+	require.Equal(t, uint64(2), chainHead/epochPeriod)
+
+	lastHeader := &types.Header{Number: new(big.Int).SetUint64(offenceHeight - 1), Committee: committee}
+	head := &types.Header{Number: new(big.Int).SetUint64(chainHead), Committee: committee2}
+	require.NotEqual(t, committee.Hash(), committee2.Hash())
+
+	ctrl := gomock.NewController(t)
+	chainMock := NewMockChainContext(ctrl)
+	chainMock.EXPECT().GetHeaderByNumber(offenceHeight - 1).AnyTimes().Return(lastHeader)
+	chainMock.EXPECT().CurrentHeader().AnyTimes().Return(head)
+	chainMock.EXPECT().Config().AnyTimes().Return(&params.ChainConfig{ChainID: common.Big1})
+	var blockSub event.Subscription
+	chainMock.EXPECT().SubscribeChainEvent(gomock.Any()).AnyTimes().Return(blockSub)
+	fdAddr := committee[1].Address
+	accountability, _ := autonity.NewAccountability(proposer, backends.NewSimulatedBackend(ccore.GenesisAlloc{fdAddr: {Balance: big.NewInt(params.Ether)}}, 10000000))
+	fd := NewFaultDetector(chainMock, fdAddr, nil, core.NewMsgStore(), nil, nil, proposerNodeKey, &autonity.ProtocolContracts{Accountability: accountability}, log.Root())
+
+	// the proposer precommits for a value at round 0, then proposes a new value at a later round: rule PN.
+	fd.msgStore.Save(newValidatedProposalMessage(offenceHeight-1, 0, -1, makeSigner(keys[1]), committee, nil, 1))
+	initProposal := newValidatedProposalMessage(offenceHeight, 0, -1, makeSigner(keys[1]), committee, nil, 1)
+	fd.msgStore.Save(initProposal)
+	fd.msgStore.Save(aggregatedPreVote(len(committee), offenceHeight, 0, initProposal.Value(), keys, committee))
+	fd.msgStore.Save(newValidatedPrecommit(0, offenceHeight, initProposal.Value(), signer, self, cSize))
+	fd.msgStore.Save(newValidatedProposalMessage(offenceHeight, 3, -1, signer, committee, nil, proposerIdx))
+
+	events := fd.runRuleEngine(offenceHeight)
+	require.Len(t, events, 1)
+
+	total := committee.TotalVotingPower()
+	expected := AuditSnapshot{
+		OffenceHeight:    offenceHeight,
+		CommitteeHash:    committee.Hash(),
+		TotalVotingPower: total,
+		QuorumThreshold:  bft.Quorum(total),
+		DeltaBlocks:      DeltaBlocks,
+		RulesVersion:     RulesVersion,
+	}
+
+	entries, first, next := fd.AccountabilityEvents(0, maxJournalEntries)
+	require.Len(t, entries, 1)
+	require.Equal(t, uint64(1), first)
+	require.Equal(t, uint64(2), next)
+	entry := entries[0]
+	require.Equal(t, ActionDetected, entry.Action)
+	require.Equal(t, autonity.Misbehaviour, entry.Type)
+	require.Equal(t, autonity.PN, entry.Rule)
+	require.Equal(t, proposer, entry.Offender)
+	require.Equal(t, expected, entry.Audit)
+	require.NotEqual(t, head.Committee.Hash(), entry.Audit.CommitteeHash)
+
+	// the submission of the proof snapshots the same inputs, and persists them.
+	submissionChain := newSimulatedSubmissionChain(big.NewInt(params.GWei))
+	db := rawdb.NewMemoryDatabase()
+	fd.submissions = newTestSubmissionMonitor(submissionChain, db)
+	proof, proofCommittee, err := fd.proofCommittee(events[0].RawProof)
+	require.NoError(t, err)
+	snapshot := NewAuditSnapshot(proof.Message.H(), proofCommittee)
+	_, _, err = fd.submissions.submit(*events[0], canonicalProofHash(events[0].RawProof), chainHead+HeightRange, &snapshot)
+	require.NoError(t, err)
+
+	fd.submissions = newTestSubmissionMonitor(submissionChain, db)
+	pending := fd.PendingSubmissions()
+	require.Len(t, pending, 1)
+	require.Equal(t, expected, *pending[0].Audit)
+	require.Equal(t, entry.ProofHash, pending[0].ProofHash)
+
+	// the committee is resolved from the journal, and from the chain for the persisted submissions.
+	resolved, err := fd.CommitteeByHash(expected.CommitteeHash)
+	require.NoError(t, err)
+	require.Equal(t, committee, resolved)
+	fd.journal = newEventJournal()
+	resolved, err = fd.CommitteeByHash(expected.CommitteeHash)
+	require.NoError(t, err)
+	require.Equal(t, committee, resolved)
+	_, err = fd.CommitteeByHash(committee2.Hash())
+	require.ErrorIs(t, err, errUnknownCommittee)
+}
+
+func TestEventJournalPruning(t *testing.T) {
+	journal := newEventJournal()
+	proposal := newValidatedProposalMessage(10, 0, -1, signer, committee, nil, proposerIdx)
+	proof := &Proof{Type: autonity.Misbehaviour, Rule: autonity.Equivocation, OffenderIndex: proposerIdx, Message: proposal}
+
+	journal.record(ActionDetected, proof, committee2)
+	for i := 0; i < maxJournalEntries; i++ {
+		journal.record(ActionDetected, proof, committee)
+	}
+	entries, first, next := journal.Entries(0, 10)
+	require.Len(t, entries, 10)
+	require.Equal(t, uint64(2), first)
+	require.Equal(t, uint64(maxJournalEntries+2), next)
+	require.Equal(t, first, entries[0].Seq)
+
+	// the committees no longer referenced are released.
+	_, ok := journal.committee(committee2.Hash())
+	require.False(t, ok)
+	_, ok = journal.committee(committee.Hash())
+	require.True(t, ok)
+}
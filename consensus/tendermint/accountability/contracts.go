@@ -1,6 +1,7 @@
 package accountability
 
 import (
+	"bytes"
 	"errors"
 	"math/big"
 
@@ -10,8 +11,10 @@ import (
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/bft"
+	engineCore "github.com/autonity/autonity/consensus/tendermint/core"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/rlp"
 )
@@ -20,9 +23,6 @@ import (
 // a part of consensus.
 
 var (
-	checkAccusationAddress   = common.BytesToAddress([]byte{0xfc})
-	checkInnocenceAddress    = common.BytesToAddress([]byte{0xfd})
-	checkMisbehaviourAddress = common.BytesToAddress([]byte{0xfe})
 	// error codes of the execution of precompiled contract to verify the input Proof.
 	successResult          = common.LeftPadBytes([]byte{1}, 32)
 	failureReturn          = make([]byte, 128)
@@ -37,6 +37,17 @@ var (
 
 const KB = 1024
 
+const (
+	// canonicalEvidenceVersion is the version of the Autonity contract from which the proofs whose evidences
+	// are not in canonical order are rejected. Until the contract is upgraded to it, the proofs built by the
+	// older clients are normalised and verified in any order.
+	canonicalEvidenceVersion = 2
+	// versionCallGas is the gas available to read the Autonity contract version.
+	versionCallGas = 50_000
+)
+
+var getVersionSelector = crypto.Keccak256([]byte("getVersion()"))[:4]
+
 /* TODO: This function subtly breaks the accusation, misbehavior and innocence e2e test.
 * This is because since the Precompiled maps are global variables, all nodes in the e2e test
 * end up using the same precompiled contracts, which contain the same chain reference.
@@ -44,17 +55,19 @@ const KB = 1024
 * We decided not to fix this issue since it does not affect a standalone client in a production test.
 * The real fix here is to remove the chain dependency from precompiled contracts.
  */
-// LoadPrecompiles init the instances of Fault Detector contracts, and register them into EVM's context
-func LoadPrecompiles(chain ChainContext) {
+// LoadPrecompiles init the instances of Fault Detector contracts, and register them into EVM's context.
+// cacheSize is the number of verification outcomes kept in memory, zero disables the cache.
+func LoadPrecompiles(chain ChainContext, cacheSize int) {
 	vm.PrecompiledContractRWMutex.Lock()
 	defer vm.PrecompiledContractRWMutex.Unlock()
-	pv := InnocenceVerifier{chain: chain}
-	cv := MisbehaviourVerifier{chain: chain}
-	av := AccusationVerifier{chain: chain}
+	cache := newVerificationCache(cacheSize, RulesVersion)
+	pv := InnocenceVerifier{chain: chain, cache: cache}
+	cv := MisbehaviourVerifier{chain: chain, cache: cache}
+	av := AccusationVerifier{chain: chain, cache: cache}
 	setPrecompiles := func(set map[common.Address]vm.PrecompiledContract) {
-		set[checkInnocenceAddress] = &pv
-		set[checkMisbehaviourAddress] = &cv
-		set[checkAccusationAddress] = &av
+		set[params.CheckInnocenceAddress] = &pv
+		set[params.CheckMisbehaviourAddress] = &cv
+		set[params.CheckAccusationAddress] = &av
 	}
 	setPrecompiles(vm.PrecompiledContractsByzantium)
 	setPrecompiles(vm.PrecompiledContractsHomestead)
@@ -66,6 +79,7 @@ func LoadPrecompiles(chain ChainContext) {
 // AccusationVerifier implemented as a native contract to validate if an accusation is valid
 type AccusationVerifier struct {
 	chain ChainContext
+	cache *verificationCache
 }
 
 // RequiredGas the gas cost to execute AccusationVerifier contract, weighted by input data size.
@@ -74,6 +88,16 @@ func (a *AccusationVerifier) RequiredGas(input []byte) uint64 {
 	return params.AutonityAFDContractGasPerKB * times
 }
 
+// Address returns the address AccusationVerifier is registered at.
+func (a *AccusationVerifier) Address() common.Address {
+	return params.CheckAccusationAddress
+}
+
+// Succeeded reports whether the accusation verified by a run is valid.
+func (a *AccusationVerifier) Succeeded(output []byte) bool {
+	return verified(output)
+}
+
 // executes checks that can be done before even verifying signatures
 func preVerifyAccusation(chain ChainContext, m message.Msg, currentHeight uint64) error {
 	accusationHeight := m.H()
@@ -98,10 +122,13 @@ func preVerifyAccusation(chain ChainContext, m message.Msg, currentHeight uint64
 
 // Run take the rlp encoded Proof of accusation in byte array, decode it and validate it, if the Proof is valid, then
 // the rlp hash of the msg payload and the msg signer is returned.
-func (a *AccusationVerifier) Run(input []byte, blockNumber uint64, _ *vm.EVM, _ common.Address) ([]byte, error) {
+func (a *AccusationVerifier) Run(input []byte, blockNumber uint64, evm *vm.EVM, _ common.Address) ([]byte, error) {
 	if len(input) <= 32 {
 		return failureReturn, nil
 	}
+	if strictEvidenceOrder(evm, params.CheckAccusationAddress) && !rawProofInCanonicalOrder(input[32:]) {
+		return failureReturn, nil
+	}
 	// the 1st 32 bytes are length of bytes array in solidity, take RLP bytes after it.
 	p, err := decodeRawProof(input[32:])
 	if err != nil {
@@ -114,22 +141,27 @@ func (a *AccusationVerifier) Run(input []byte, blockNumber uint64, _ *vm.EVM, _
 	if err = preVerifyAccusation(a.chain, p.Message, blockNumber); err != nil {
 		return failureReturn, nil
 	}
+	// the remaining checks only depend on the proof and on finalized headers, their outcome can be cached.
+	return a.cache.run(params.CheckAccusationAddress, input, func() ([]byte, bool) { return a.verify(p) }), nil
+}
 
+func (a *AccusationVerifier) verify(p *Proof) ([]byte, bool) {
 	h := p.Message.H()
 	lastHeader := a.chain.GetHeaderByNumber(h - 1)
 	if lastHeader == nil {
-		return failureReturn, nil
+		// the header might be available later on, do not cache
+		return failureReturn, false
 	}
 
-	if err = verifyProofSignatures(lastHeader, p); err != nil {
-		return failureReturn, nil
+	if err := verifyProofSignatures(lastHeader, p); err != nil {
+		return failureReturn, true
 	}
 	committee := lastHeader.Committee
 	if verifyAccusation(p, committee) {
 		// the proof carry valid info.
-		return validReturn(p.Message, committee[p.OffenderIndex].Address, p.Rule), nil
+		return validReturn(p.Message, committee[p.OffenderIndex].Address, p.Rule), true
 	}
-	return failureReturn, nil
+	return failureReturn, true
 }
 
 // validate the submitted accusation by the contract call.
@@ -220,6 +252,7 @@ func verifyAccusation(p *Proof, committee types.Committee) bool {
 // MisbehaviourVerifier implemented as a native contract to validate if misbehaviour is valid
 type MisbehaviourVerifier struct {
 	chain ChainContext
+	cache *verificationCache
 }
 
 // RequiredGas the gas cost to execute MisbehaviourVerifier contract, weighted by input data size.
@@ -228,28 +261,46 @@ func (c *MisbehaviourVerifier) RequiredGas(input []byte) uint64 {
 	return params.AutonityAFDContractGasPerKB * times
 }
 
+// Address returns the address MisbehaviourVerifier is registered at.
+func (c *MisbehaviourVerifier) Address() common.Address {
+	return params.CheckMisbehaviourAddress
+}
+
+// Succeeded reports whether the misbehaviour proof verified by a run is valid.
+func (c *MisbehaviourVerifier) Succeeded(output []byte) bool {
+	return verified(output)
+}
+
 // Run take the rlp encoded Proof of challenge in byte array, decode it and validate it, if the Proof is valid, then
 // the rlp hash of the msg payload and the msg signer is returned as the valid identity for Proof management.
-func (c *MisbehaviourVerifier) Run(input []byte, _ uint64, _ *vm.EVM, _ common.Address) ([]byte, error) {
+func (c *MisbehaviourVerifier) Run(input []byte, _ uint64, evm *vm.EVM, _ common.Address) ([]byte, error) {
 	if len(input) <= 32 {
 		return failureReturn, nil
 	}
+	if strictEvidenceOrder(evm, params.CheckMisbehaviourAddress) && !rawProofInCanonicalOrder(input[32:]) {
+		return failureReturn, nil
+	}
+	return c.cache.run(params.CheckMisbehaviourAddress, input, func() ([]byte, bool) { return c.verify(input) }), nil
+}
+
+func (c *MisbehaviourVerifier) verify(input []byte) ([]byte, bool) {
 	// the 1st 32 bytes are length of bytes array in solidity, take RLP bytes after it.
 	p, err := decodeRawProof(input[32:])
 	if err != nil {
-		return failureReturn, nil
+		return failureReturn, true
 	}
 
 	h := p.Message.H()
 	lastHeader := c.chain.GetHeaderByNumber(h - 1)
 	if lastHeader == nil {
-		return failureReturn, nil
+		// the header might be available later on, do not cache
+		return failureReturn, false
 	}
 
 	if err = verifyProofSignatures(lastHeader, p); err != nil {
-		return failureReturn, nil
+		return failureReturn, true
 	}
-	return c.validateFault(p, lastHeader.Committee), nil
+	return c.validateFault(p, lastHeader.Committee), true
 }
 
 // validate a misbehavior proof, doesn't check the proof signatures.
@@ -603,6 +654,7 @@ func (c *MisbehaviourVerifier) validMisbehaviourOfC(p *Proof, committee types.Co
 // InnocenceVerifier implemented as a native contract to validate an innocence Proof.
 type InnocenceVerifier struct {
 	chain ChainContext
+	cache *verificationCache
 }
 
 // RequiredGas the gas cost to execute this Proof validator contract, weighted by input data size.
@@ -611,34 +663,52 @@ func (c *InnocenceVerifier) RequiredGas(input []byte) uint64 {
 	return params.AutonityAFDContractGasPerKB * times
 }
 
+// Address returns the address InnocenceVerifier is registered at.
+func (c *InnocenceVerifier) Address() common.Address {
+	return params.CheckInnocenceAddress
+}
+
+// Succeeded reports whether the innocence proof verified by a run is valid.
+func (c *InnocenceVerifier) Succeeded(output []byte) bool {
+	return verified(output)
+}
+
 // Run InnocenceVerifier, take the rlp encoded Proof of innocence, decode it and validate it, if the Proof is valid, then
 // return the rlp hash of msg and the rlp hash of msg signer as the valid identity for on-chain management of proofs,
 // AC need the check the value returned to match the ID which is on challenge, to remove the challenge from chain.
-func (c *InnocenceVerifier) Run(input []byte, blockNumber uint64, _ *vm.EVM, _ common.Address) ([]byte, error) {
+func (c *InnocenceVerifier) Run(input []byte, blockNumber uint64, evm *vm.EVM, _ common.Address) ([]byte, error) {
 	if len(input) <= 32 || blockNumber == 0 {
 		return failureReturn, nil
 	}
+	if strictEvidenceOrder(evm, params.CheckInnocenceAddress) && !rawProofInCanonicalOrder(input[32:]) {
+		return failureReturn, nil
+	}
+	return c.cache.run(params.CheckInnocenceAddress, input, func() ([]byte, bool) { return c.verify(input) }), nil
+}
+
+func (c *InnocenceVerifier) verify(input []byte) ([]byte, bool) {
 	// the 1st 32 bytes are length of bytes array in solidity, take RLP bytes after it.
 	p, err := decodeRawProof(input[32:])
 	if err != nil {
-		return failureReturn, nil
+		return failureReturn, true
 	}
 
 	h := p.Message.H()
 	lastHeader := c.chain.GetHeaderByNumber(h - 1)
 	if lastHeader == nil {
-		return failureReturn, nil
+		// the header might be available later on, do not cache
+		return failureReturn, false
 	}
 
 	if err = verifyProofSignatures(lastHeader, p); err != nil {
-		return failureReturn, nil
+		return failureReturn, true
 	}
 
 	committee := lastHeader.Committee
 	if !verifyInnocenceProof(p, committee) {
-		return failureReturn, nil
+		return failureReturn, true
 	}
-	return validReturn(p.Message, committee[p.OffenderIndex].Address, p.Rule), nil
+	return validReturn(p.Message, committee[p.OffenderIndex].Address, p.Rule), true
 }
 
 func verifyInnocenceProof(p *Proof, committee types.Committee) bool {
@@ -826,9 +896,46 @@ func decodeRawProof(b []byte) (*Proof, error) {
 	if p.Message == nil {
 		return p, errors.New("invalid proof")
 	}
+	// the decoders only check the wire format, the messages must also be within the admission bounds
+	if err := engineCore.CheckMessageBounds(p.Message); err != nil {
+		return p, err
+	}
+	for _, m := range p.Evidences {
+		if err := engineCore.CheckMessageBounds(m); err != nil {
+			return p, err
+		}
+	}
 	return p, nil
 }
 
+// rawProofInCanonicalOrder reports whether the evidences of the RLP-encoded proof are in canonical order, as
+// decoding a proof normalises it.
+func rawProofInCanonicalOrder(b []byte) bool {
+	encoded := encodedProof{}
+	if err := rlp.DecodeBytes(b, &encoded); err != nil {
+		return false
+	}
+	evidences := make([]message.Msg, len(encoded.Evidences))
+	for i := range encoded.Evidences {
+		evidences[i] = encoded.Evidences[i].Msg
+	}
+	return IsCanonicalOrder(evidences)
+}
+
+// strictEvidenceOrder reports whether the proofs verified by the precompiled contract at address must have their
+// evidences in canonical order, that is once the Autonity contract is upgraded to canonicalEvidenceVersion. The
+// check is done outside the verification cache, as the outcome for the same input changes at the upgrade.
+func strictEvidenceOrder(evm *vm.EVM, address common.Address) bool {
+	if evm == nil {
+		return false
+	}
+	ret, _, err := evm.StaticCall(vm.AccountRef(address), params.AutonityContractAddress, getVersionSelector, versionCallGas)
+	if err != nil || len(ret) != common.HashLength {
+		return false
+	}
+	return new(big.Int).SetBytes(ret).Cmp(big.NewInt(canonicalEvidenceVersion)) >= 0
+}
+
 // verifyProofSignatures checks if the consensus message is from valid member of the committee.
 func verifyProofSignatures(lastHeader *types.Header, p *Proof) error {
 	// before signature verification, check if the offender index is valid
@@ -934,6 +1041,11 @@ func validMisbehaviourOfEquivocation(proof *Proof, committee types.Committee) bo
 	return false
 }
 
+// verified reports whether the output of a proof verification is a success.
+func verified(output []byte) bool {
+	return bytes.HasPrefix(output, successResult)
+}
+
 func validReturn(m message.Msg, signer common.Address, rule autonity.Rule) []byte {
 	offender := common.LeftPadBytes(signer.Bytes(), 32)
 	ruleID := common.LeftPadBytes([]byte{byte(rule)}, 32)
@@ -76,26 +76,26 @@ var (
 
 func TestContractsManagement(t *testing.T) {
 	// register contracts into evm package.
-	LoadPrecompiles(nil)
-	assert.NotNil(t, vm.PrecompiledContractsByzantium[checkInnocenceAddress])
-	assert.NotNil(t, vm.PrecompiledContractsByzantium[checkMisbehaviourAddress])
-	assert.NotNil(t, vm.PrecompiledContractsByzantium[checkAccusationAddress])
+	LoadPrecompiles(nil, DefaultVerificationCacheSize)
+	assert.NotNil(t, vm.PrecompiledContractsByzantium[params.CheckInnocenceAddress])
+	assert.NotNil(t, vm.PrecompiledContractsByzantium[params.CheckMisbehaviourAddress])
+	assert.NotNil(t, vm.PrecompiledContractsByzantium[params.CheckAccusationAddress])
 
-	assert.NotNil(t, vm.PrecompiledContractsHomestead[checkInnocenceAddress])
-	assert.NotNil(t, vm.PrecompiledContractsHomestead[checkMisbehaviourAddress])
-	assert.NotNil(t, vm.PrecompiledContractsHomestead[checkAccusationAddress])
+	assert.NotNil(t, vm.PrecompiledContractsHomestead[params.CheckInnocenceAddress])
+	assert.NotNil(t, vm.PrecompiledContractsHomestead[params.CheckMisbehaviourAddress])
+	assert.NotNil(t, vm.PrecompiledContractsHomestead[params.CheckAccusationAddress])
 
-	assert.NotNil(t, vm.PrecompiledContractsIstanbul[checkInnocenceAddress])
-	assert.NotNil(t, vm.PrecompiledContractsIstanbul[checkMisbehaviourAddress])
-	assert.NotNil(t, vm.PrecompiledContractsIstanbul[checkAccusationAddress])
+	assert.NotNil(t, vm.PrecompiledContractsIstanbul[params.CheckInnocenceAddress])
+	assert.NotNil(t, vm.PrecompiledContractsIstanbul[params.CheckMisbehaviourAddress])
+	assert.NotNil(t, vm.PrecompiledContractsIstanbul[params.CheckAccusationAddress])
 
-	assert.NotNil(t, vm.PrecompiledContractsBerlin[checkInnocenceAddress])
-	assert.NotNil(t, vm.PrecompiledContractsBerlin[checkAccusationAddress])
-	assert.NotNil(t, vm.PrecompiledContractsBerlin[checkMisbehaviourAddress])
+	assert.NotNil(t, vm.PrecompiledContractsBerlin[params.CheckInnocenceAddress])
+	assert.NotNil(t, vm.PrecompiledContractsBerlin[params.CheckAccusationAddress])
+	assert.NotNil(t, vm.PrecompiledContractsBerlin[params.CheckMisbehaviourAddress])
 
-	assert.NotNil(t, vm.PrecompiledContractsBLS[checkInnocenceAddress])
-	assert.NotNil(t, vm.PrecompiledContractsBLS[checkAccusationAddress])
-	assert.NotNil(t, vm.PrecompiledContractsBLS[checkMisbehaviourAddress])
+	assert.NotNil(t, vm.PrecompiledContractsBLS[params.CheckInnocenceAddress])
+	assert.NotNil(t, vm.PrecompiledContractsBLS[params.CheckAccusationAddress])
+	assert.NotNil(t, vm.PrecompiledContractsBLS[params.CheckMisbehaviourAddress])
 
 }
 
@@ -140,7 +140,7 @@ func TestDecodeAndVerifyProofs(t *testing.T) {
 		},
 		{
 			p4,
-			message.ErrUnauthorizedAddress,
+			message.ErrNonCommitteeSender,
 		},
 		{
 			proofWithInvalidSignature,
@@ -1433,3 +1433,100 @@ func newValidatedPrevote(r int64, height uint64, v common.Hash, signer message.S
 	prevote := message.NewPrevote(r, height, v, signer, s, cSize)
 	return prevote
 }
+
+// newInnocenceProofInput returns a valid PO innocence proof, encoded as passed to the precompiled contracts.
+func newInnocenceProofInput(t require.TestingT) []byte {
+	proposal := newValidatedLightProposal(height, 1, 0, signer, committee, nil, proposerIdx)
+	votes := make([]message.Vote, len(committee))
+	for i := range committee {
+		votes[i] = newValidatedPrevote(0, height, proposal.Value(), makeSigner(keys[i]), &committee[i], cSize)
+	}
+	p := &Proof{
+		Rule:          autonity.PO,
+		Message:       proposal,
+		OffenderIndex: proposerIdx,
+		Evidences:     []message.Msg{message.AggregatePrevotes(votes)},
+	}
+	raw, err := rlp.EncodeToBytes(p)
+	require.NoError(t, err)
+	return append(make([]byte, 32), raw...)
+}
+
+func TestVerificationCache(t *testing.T) {
+	input := newInnocenceProofInput(t)
+
+	t.Run("repeated verification is served from the cache", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		chainMock := NewMockChainContext(ctrl)
+		// the proof is only verified once
+		chainMock.EXPECT().GetHeaderByNumber(lastHeight).Times(1).Return(lastHeader)
+		iv := InnocenceVerifier{chain: chainMock, cache: newVerificationCache(DefaultVerificationCacheSize, RulesVersion)}
+		for i := 0; i < 5; i++ {
+			ret, err := iv.Run(input, height, nil, common.Address{})
+			require.NoError(t, err)
+			require.Equal(t, successResult, ret[:32])
+		}
+	})
+
+	t.Run("outcomes are not shared across rules versions and precompiles", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		chainMock := NewMockChainContext(ctrl)
+		chainMock.EXPECT().GetHeaderByNumber(lastHeight).Times(3).Return(lastHeader)
+		cache := newVerificationCache(DefaultVerificationCacheSize, RulesVersion)
+		upgraded := &verificationCache{version: RulesVersion + 1, results: cache.results}
+
+		iv := InnocenceVerifier{chain: chainMock, cache: cache}
+		ret, err := iv.Run(input, height, nil, common.Address{})
+		require.NoError(t, err)
+		require.Equal(t, successResult, ret[:32])
+
+		iv = InnocenceVerifier{chain: chainMock, cache: upgraded}
+		ret, err = iv.Run(input, height, nil, common.Address{})
+		require.NoError(t, err)
+		require.Equal(t, successResult, ret[:32])
+		require.Equal(t, 2, cache.results.Len())
+
+		// the same input is not a valid misbehaviour proof
+		mv := MisbehaviourVerifier{chain: chainMock, cache: cache}
+		ret, err = mv.Run(input, height, nil, common.Address{})
+		require.NoError(t, err)
+		require.Equal(t, failureReturn, ret)
+		require.Equal(t, 3, cache.results.Len())
+	})
+
+	t.Run("outcomes depending on missing headers are not cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		chainMock := NewMockChainContext(ctrl)
+		gomock.InOrder(
+			chainMock.EXPECT().GetHeaderByNumber(lastHeight).Return(nil),
+			chainMock.EXPECT().GetHeaderByNumber(lastHeight).Return(lastHeader),
+		)
+		iv := InnocenceVerifier{chain: chainMock, cache: newVerificationCache(DefaultVerificationCacheSize, RulesVersion)}
+		ret, err := iv.Run(input, height, nil, common.Address{})
+		require.NoError(t, err)
+		require.Equal(t, failureReturn, ret)
+		ret, err = iv.Run(input, height, nil, common.Address{})
+		require.NoError(t, err)
+		require.Equal(t, successResult, ret[:32])
+	})
+}
+
+func BenchmarkInnocenceVerifier(b *testing.B) {
+	input := newInnocenceProofInput(b)
+	ctrl := gomock.NewController(b)
+	chainMock := NewMockChainContext(ctrl)
+	chainMock.EXPECT().GetHeaderByNumber(lastHeight).AnyTimes().Return(lastHeader)
+
+	b.Run("uncached", func(b *testing.B) {
+		iv := InnocenceVerifier{chain: chainMock}
+		for i := 0; i < b.N; i++ {
+			iv.Run(input, height, nil, common.Address{}) //nolint
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		iv := InnocenceVerifier{chain: chainMock, cache: newVerificationCache(DefaultVerificationCacheSize, RulesVersion)}
+		for i := 0; i < b.N; i++ {
+			iv.Run(input, height, nil, common.Address{}) //nolint
+		}
+	})
+}
@@ -20,8 +20,10 @@ import (
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/state"
 	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/internal/ethapi"
 	"github.com/autonity/autonity/log"
@@ -63,6 +65,8 @@ var (
 	errNoEvidenceForC1  = errors.New("no proof of innocence found for rule C1")
 	errUnprovableRule   = errors.New("unprovable rule")
 
+	errInvalidMsgStoreMode = errors.New("invalid msg store mode")
+
 	nilValue = common.Hash{}
 )
 
@@ -78,9 +82,13 @@ type FaultDetector struct {
 	wg               sync.WaitGroup
 	tendermintMsgSub *event.TypeMuxSubscription
 
-	txPool     *core.TxPool
-	ethBackend ethapi.Backend
-	txOpts     *bind.TransactOpts // transactor options for accountability events
+	txPool      *core.TxPool
+	ethBackend  ethapi.Backend
+	txOpts      *bind.TransactOpts // transactor options for accountability events
+	submissions *submissionMonitor // tracks the accountability transactions until their inclusion
+	queue       *submissionQueue   // caps the accountability events submitted per height and per epoch
+	journal     *eventJournal      // last proofs handled, along with the inputs they were handled with
+	accusations *accusationStore   // accusations against the local node awaiting resolution
 
 	eventReporterCh chan *autonity.AccountabilityEvent
 	stopRetry       chan struct{}
@@ -93,8 +101,11 @@ type FaultDetector struct {
 	accountabilityEventSub event.Subscription
 
 	blockchain ChainContext
+	headStates *core.StateGuard // reads the head state for the proposer checks
 	address    common.Address
 	msgStore   *engineCore.MsgStore
+	// the msg store mode follows the validator registration of the local node
+	autoMsgStoreMode bool
 
 	chainEventCh  chan core.ChainEvent
 	chainEventSub event.Subscription
@@ -102,6 +113,10 @@ type FaultDetector struct {
 	misbehaviourProofCh chan *autonity.AccountabilityEvent
 	pendingEvents       []*autonity.AccountabilityEvent // accountability event buffer.
 
+	late     *lateMessages          // policy of the messages arriving after the rules were applied to their height
+	arrivals *ArrivalIndex          // first arrival of the consensus messages over the accountability window
+	scans    map[uint64]*heightScan // outcome of the rules applied per height, owned by the rule engine
+
 	offChainAccusationsMu sync.RWMutex
 	offChainAccusations   []*Proof // off chain accusations list, ordered in chain height from low to high.
 	broadcaster           consensus.Broadcaster
@@ -139,14 +154,27 @@ func NewFaultDetector(
 		ruleEngineBlockCh:     make(chan core.ChainEvent, 300),
 		accountabilityEventCh: make(chan *autonity.AccountabilityNewAccusation),
 		blockchain:            chain,
+		headStates:            core.NewStateGuard("fault detector", chain.StateAt, core.DefaultStateGuardFailures, logger),
 		address:               nodeAddress,
 		msgStore:              ms,
 		chainEventCh:          make(chan core.ChainEvent, 300),
 		eventReporterCh:       make(chan *autonity.AccountabilityEvent, 10),
 		stopRetry:             make(chan struct{}),
 		misbehaviourProofCh:   make(chan *autonity.AccountabilityEvent, 100),
+		journal:               newEventJournal(),
+		queue:                 newSubmissionQueue(DefaultConfig.MaxSubmissionsPerHeight, DefaultConfig.MaxSubmissionsPerEpoch),
+		late:                  newLateMessages(DefaultConfig.LateMessageWindow, DefaultConfig.MaxRescansPerHeight),
+		scans:                 make(map[uint64]*heightScan),
 		logger:                logger, // Todo(youssef): remove context
 	}
+	// the pending accountability transactions are persisted in the chain database, tests run without one
+	var db ethdb.KeyValueStore = rawdb.NewMemoryDatabase()
+	if ethBackend != nil {
+		db = ethBackend.ChainDb()
+	}
+	fd.submissions = newSubmissionMonitor(&apiSubmissionBackend{fd: fd}, db, txOpts, logger)
+	fd.arrivals = NewArrivalIndex(db, ArrivalMemoryWindow, ArrivalWindow)
+	fd.accusations = newAccusationStore(db, logger)
 	// todo(youssef): analyze chainEvent vs chainHeadEvent and very important: what to do during sync !
 	fd.ruleEngineBlockSub = fd.blockchain.SubscribeChainEvent(fd.ruleEngineBlockCh)
 	fd.chainEventSub = fd.blockchain.SubscribeChainEvent(fd.chainEventCh)
@@ -162,8 +190,10 @@ func NewFaultDetector(
 // Start listen for new block events from blockchain, do the tasks like take challenge and provide Proof for innocent, the
 // Fault Detector rule engine could also trigger from here to scan those msgs of msg store by applying rules.
 func (fd *FaultDetector) Start() {
+	fd.submissions.resume(fd.blockchain.CurrentHeader)
 	fd.wg.Add(1)
 	go fd.eventReporter()
+	fd.resumeAccusations()
 	go fd.ruleEngine()
 	go fd.consensusMsgHandlerLoop()
 }
@@ -176,9 +206,126 @@ func (fd *FaultDetector) SetBroadcaster(broadcaster consensus.Broadcaster) {
 	fd.broadcaster = broadcaster
 }
 
+// SetSubmissionConfig sets the fee management settings and the submission cap of the accountability
+// transactions.
+func (fd *FaultDetector) SetSubmissionConfig(config Config) {
+	fd.submissions.setConfig(config)
+	fd.queue.setCap(config.MaxSubmissionsPerHeight, config.MaxSubmissionsPerEpoch)
+}
+
+// SetOffChainRateLimit sets the number of off-chain accountability messages accepted from a peer over the
+// sliding window, and the number of consecutive messages over the limit after which the peer is disconnected.
+func (fd *FaultDetector) SetOffChainRateLimit(maxMessages uint64, window time.Duration, maxViolations uint64) {
+	fd.rateLimiter.setMessageRate(maxMessages, window, maxViolations)
+}
+
+// AccusationRates returns the rate limiting state of the off-chain accountability messages of each peer.
+func (fd *FaultDetector) AccusationRates() []PeerAccusationRate {
+	return fd.rateLimiter.rates()
+}
+
+// SetSubmissionCap overrides the number of accountability events submitted per height and per epoch,
+// 0 for no cap. The queued events are released from the next block on.
+func (fd *FaultDetector) SetSubmissionCap(perHeight, perEpoch uint64) {
+	fd.logger.Warn("Accountability submission cap updated", "perHeight", perHeight, "perEpoch", perEpoch)
+	fd.queue.setCap(perHeight, perEpoch)
+}
+
+// SubmissionCap returns the number of accountability events submitted per height and per epoch, and
+// the number of events queued beyond the cap.
+func (fd *FaultDetector) SubmissionCap() (perHeight, perEpoch uint64, queued int) {
+	perHeight, perEpoch = fd.queue.limits()
+	return perHeight, perEpoch, fd.queue.size()
+}
+
+// releaseSubmissions hands the queued accountability events allowed by the submission cap over to
+// the reporter.
+func (fd *FaultDetector) releaseSubmissions(header *types.Header) {
+	height := header.Number.Uint64()
+	var epoch uint64
+	if fd.protocolContracts != nil && fd.protocolContracts.Cache != nil {
+		epoch = height / max(fd.protocolContracts.Cache.EpochPeriod().Uint64(), 1)
+	}
+	for _, ev := range fd.queue.release(height, epoch) {
+		fd.eventReporterCh <- ev
+	}
+	if queued := fd.queue.size(); queued > 0 {
+		fd.logger.Info("Accountability events queued by the submission cap", "queued", queued, "height", height)
+	}
+}
+
+// SetMsgStoreMode sets the way the consensus messages are buffered, see Config.MsgStoreMode. It must
+// be called before Start.
+func (fd *FaultDetector) SetMsgStoreMode(mode string) error {
+	switch mode {
+	case MsgStoreFull:
+		fd.msgStore.SetMode(engineCore.FullMode)
+	case MsgStoreDigest:
+		fd.msgStore.SetMode(engineCore.DigestMode)
+	case MsgStoreAuto:
+		fd.autoMsgStoreMode = true
+		fd.updateMsgStoreMode(fd.blockchain.CurrentHeader())
+	default:
+		return fmt.Errorf("%w: %q", errInvalidMsgStoreMode, mode)
+	}
+	return nil
+}
+
+// SetMsgStoreCapacity sets the number of consensus messages, or digests, buffered, see Config.MsgStoreCapacity.
+func (fd *FaultDetector) SetMsgStoreCapacity(capacity uint64) {
+	fd.msgStore.SetCapacity(int(capacity))
+}
+
+// updateMsgStoreMode keeps the full messages while the local node is a registered validator, for it
+// to defend itself against accusations, and their digests only otherwise. The mode is left unchanged
+// if the head state is not available.
+func (fd *FaultDetector) updateMsgStoreMode(header *types.Header) {
+	mode := engineCore.FullMode
+	if header.CommitteeMember(fd.address) == nil {
+		statedb, err := fd.headStates.StateAt(header.Root)
+		if err != nil {
+			return
+		}
+		if _, err := fd.protocolContracts.Validator(header, statedb, fd.address); err != nil {
+			mode = engineCore.DigestMode
+		}
+	}
+	if previous := fd.msgStore.Mode(); mode != previous {
+		fd.logger.Info("Switching the consensus msg store mode", "from", previous, "to", mode, "height", header.Number)
+		fd.msgStore.SetMode(mode)
+	}
+}
+
+// Arrivals returns the first arrivals of the consensus messages of the height, and whether any is
+// known within the accountability window.
+func (fd *FaultDetector) Arrivals(height uint64) (HeightArrivals, bool) {
+	return fd.arrivals.Arrivals(height)
+}
+
+// recordArrival records the arrival of the message for its signers.
+func (fd *FaultDetector) recordArrival(m message.Msg, at time.Time) {
+	var committeeSize int
+	switch msg := m.(type) {
+	case *message.Prevote:
+		committeeSize = msg.Signers().CommitteeSize()
+	case *message.Precommit:
+		committeeSize = msg.Signers().CommitteeSize()
+	default:
+		lastHeader := fd.blockchain.GetHeaderByNumber(m.H() - 1)
+		if lastHeader == nil {
+			return
+		}
+		committeeSize = len(lastHeader.Committee)
+	}
+	fd.arrivals.RecordMsg(m, committeeSize, at)
+}
+
+// MsgStoreStats returns a summary of the consensus messages buffered for the rule engine.
+func (fd *FaultDetector) MsgStoreStats() engineCore.MsgStoreStats {
+	return fd.msgStore.Stats()
+}
+
 func (fd *FaultDetector) consensusMsgHandlerLoop() {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
 tendermintMsgLoop:
 	for {
 		select {
@@ -194,6 +341,7 @@ tendermintMsgLoop:
 					fd.logger.Debug("Fault detector: discarding old message")
 					continue tendermintMsgLoop
 				}
+				fd.recordArrival(e.Message, time.Now())
 				if err := fd.processMsg(e.Message); err != nil {
 					if !errors.Is(err, errDuplicatedMsg) {
 						fd.logger.Warn("Detected faulty message", "err", err)
@@ -206,11 +354,7 @@ tendermintMsgLoop:
 					continue tendermintMsgLoop
 				}
 			case events.OldMessageEvent:
-				if fd.isHeightExpired(currentHeight, e.Message.H()) {
-					fd.logger.Debug("Fault detector: discarding old message")
-					continue tendermintMsgLoop
-				}
-				if err := fd.processMsg(e.Message); err != nil {
+				if err := fd.handleLateMsg(e.Message, currentHeight); err != nil {
 					if !errors.Is(err, errDuplicatedMsg) {
 						fd.logger.Warn("Detected faulty message", "err", err)
 					} else {
@@ -228,6 +372,7 @@ tendermintMsgLoop:
 					// the errors return from handler could freeze the peer connection for 30 seconds by according to dev p2p protocol.
 					select {
 					case e.ErrCh <- err:
+						fd.rateLimiter.disconnected(e.Sender)
 					default: // do nothing
 					}
 					continue tendermintMsgLoop
@@ -238,14 +383,12 @@ tendermintMsgLoop:
 				break tendermintMsgLoop
 			}
 
-			// on every 60 blocks, reset Peer Justified Accusations and height accusations counters.
+			// on every 60 blocks, reset Peer Justified Accusations and height accusations counters, and the idle rates.
 			if e.Block.NumberU64()%msgGCInterval == 0 {
 				fd.rateLimiter.resetHeightRateLimiter()
 				fd.rateLimiter.resetPeerJustifiedAccusations()
+				fd.rateLimiter.resetIdleRates()
 			}
-		case <-ticker.C:
-			// on each 1 seconds, reset the rate limiter counters.
-			fd.rateLimiter.resetRateLimiter()
 		case err, ok := <-fd.chainEventSub.Err():
 			if ok {
 				// why crit? what can happen here?
@@ -263,6 +406,7 @@ func (fd *FaultDetector) checkMsgStoreGC(height uint64) {
 	if height > HeightRange && height%msgGCInterval == 0 {
 		threshold := height - HeightRange
 		fd.msgStore.DeleteOlds(threshold)
+		fd.pruneScans(threshold)
 	}
 }
 
@@ -276,6 +420,15 @@ loop:
 				break loop
 			}
 
+			// track the inclusion of the pending accountability transactions.
+			fd.submissions.check(ev.Block.Header())
+			if fd.autoMsgStoreMode {
+				fd.updateMsgStoreMode(ev.Block.Header())
+			}
+
+			// stop tracking the resolved accusations against the local node, and defend the pending ones.
+			fd.checkAccusations()
+
 			// try to escalate expired off chain accusation on chain.
 			fd.escalateExpiredAccusations(ev.Block.NumberU64())
 
@@ -285,10 +438,13 @@ loop:
 				if events := fd.runRuleEngine(checkpoint); len(events) > 0 {
 					fd.pendingEvents = append(fd.pendingEvents, events...)
 				}
+				// re-evaluate the heights already scanned which received late messages since.
+				fd.rescanLateMessages()
 				if len(fd.pendingEvents) != 0 && fd.canReport(checkpoint) {
 					fd.pendingEvents = fd.reportEvents(fd.pendingEvents)
 				}
 			}
+			fd.releaseSubmissions(ev.Block.Header())
 			// msg store delete msgs out of buffering window on every 60 blocks.
 			fd.checkMsgStoreGC(ev.Block.NumberU64())
 		case accusation := <-fd.accountabilityEventCh:
@@ -298,42 +454,8 @@ loop:
 				// this should never happen
 				fd.logger.Crit("Can't retrieve accountability event", "id", accusation.Id.Uint64())
 			}
-			decodedProof, err := decodeRawProof(accusationEvent.RawProof)
-			if err != nil {
-				fd.logger.Error("Can't decode accusation", "err", err)
-				break
-			}
-
-			h := decodedProof.Message.H()
-			lastHeader := fd.blockchain.GetHeaderByNumber(h - 1)
-			if lastHeader == nil {
-				fd.logger.Error("Can't get header", "header", h-1)
-				break
-			}
-
-			// The signatures must be valid at this stage, however we have to recover the original
-			// senders, hence the following call.
-			if err = verifyProofSignatures(lastHeader, decodedProof); err != nil {
-				fd.logger.Error("Can't verify proof signatures", "err", err)
-				break
-			}
-
-			innocenceProof, err := fd.innocenceProof(decodedProof, lastHeader.Committee)
-			if err == nil && innocenceProof != nil {
-				// send on chain innocence proof ASAP since the client is on challenge that requires the proof to be
-				// provided before the client get slashed.
-				fd.logger.Warn("Innocence proof found! reporting...")
-				fd.eventReporterCh <- innocenceProof
-			} else {
-				fd.logger.Warn("************************** SLASHING EVENT **************************")
-				fd.logger.Warn("Your local node has been accused of malicious behavior")
-				fd.logger.Warn("A proof of innocence has not been found: the local node is at high risk of slashing")
-				fd.logger.Warn("Reach out to Autonity social media channels for more informations")
-				fd.logger.Warn("********************************************************************")
-				if err != nil {
-					fd.logger.Error("Could not handle accusation", "error", err)
-				}
-			}
+			ev := autonity.AccountabilityEvent(accusationEvent)
+			fd.handleAccusation(&ev)
 
 		case m, ok := <-fd.misbehaviourProofCh:
 			if !ok {
@@ -384,6 +506,7 @@ func (fd *FaultDetector) Stop() {
 	close(fd.stopRetry)
 	close(fd.eventReporterCh)
 	fd.wg.Wait()
+	fd.arrivals.Flush()
 }
 
 // convert the raw proofs into on-chain Proof which contains raw bytes of messages.
@@ -400,6 +523,7 @@ func (fd *FaultDetector) eventFromProof(p *Proof, offender common.Address) *auto
 		Epoch:          common.Big0,                           // assigned contract-side
 		MessageHash:    common.Big0,                           // assigned contract-side
 	}
+	SortEvidences(p.Evidences)
 	// panic because encoding must not fail here
 	rProof, err := rlp.EncodeToBytes(p)
 	if err != nil {
@@ -414,6 +538,13 @@ func (fd *FaultDetector) eventFromProof(p *Proof, offender common.Address) *auto
 func (fd *FaultDetector) innocenceProof(p *Proof, committee types.Committee) (*autonity.AccountabilityEvent, error) {
 	// the protocol contains below provable accusations.
 	switch p.Rule {
+	case autonity.PO, autonity.PVN, autonity.PVO, autonity.C1:
+		// the proofs of innocence are built out of the full messages.
+		if err := fd.msgStore.RequireMessages(); err != nil {
+			return nil, err
+		}
+	}
+	switch p.Rule {
 	case autonity.PO:
 		return fd.innocenceProofPO(p)
 	case autonity.PVN:
@@ -577,6 +708,11 @@ func (fd *FaultDetector) innocenceProofPVO(c *Proof) (*autonity.AccountabilityEv
 
 // processMsg, check and submit any auto-incriminating, equivocation challenges, and then only store checked msg in msg store.
 func (fd *FaultDetector) processMsg(m message.Msg) error {
+	// without the full messages, no proof can be built: the digest is recorded only.
+	if err := fd.msgStore.RequireMessages(); err != nil {
+		fd.msgStore.Save(m)
+		return nil
+	}
 	switch msg := m.(type) {
 	case *message.Propose:
 		if err := fd.checkSelfIncriminatingProposal(msg); err != nil {
@@ -599,6 +735,14 @@ func (fd *FaultDetector) processMsg(m message.Msg) error {
 
 // run rule engine over the specific height of consensus msgs, return the accountable events in proofs.
 func (fd *FaultDetector) runRuleEngine(height uint64) []*autonity.AccountabilityEvent {
+	// the messages of this height arriving from now on are late, and rescanned for their signers.
+	fd.late.lastScanned.Store(height)
+	return fd.runRules(height, allOffenders)
+}
+
+// runRules applies the rules to the messages of the offender at the given height, or of every
+// committee member for allOffenders, and handles the proofs not handled yet for this height.
+func (fd *FaultDetector) runRules(height uint64, offenderIndex int) []*autonity.AccountabilityEvent {
 	// To avoid none necessary accusations, we wait for delta blocks to start rule scan.
 	// always skip the heights before first buffered height after the node start up, since it will rise lots of none
 	// sense accusations due to the missing of messages during the startup phase, it cost un-necessary payments
@@ -606,19 +750,33 @@ func (fd *FaultDetector) runRuleEngine(height uint64) []*autonity.Accountability
 	if height <= fd.msgStore.FirstHeightBuffered() {
 		return nil
 	}
+	// the rules are applied over the full messages, they are skipped in digest mode.
+	if err := fd.msgStore.RequireMessages(); err != nil {
+		fd.logger.Trace("Skipping the accountability rules", "height", height, "err", err)
+		return nil
+	}
 	lastHeader := fd.blockchain.GetHeaderByNumber(height - 1)
 	if lastHeader == nil {
 		// youssef: is that even possible?
 		return nil
 	}
 	quorum := bft.Quorum(lastHeader.TotalVotingPower())
-	proofs := fd.runRulesOverHeight(height, quorum, lastHeader.Committee)
+	proofs := fd.runRulesOverHeight(height, quorum, lastHeader.Committee, offenderIndex)
 	events := make([]*autonity.AccountabilityEvent, 0, len(proofs))
 
-	// used to enforce max accusation per committee member per height
-	accused := make(map[common.Address]uint64)
+	// used to enforce max accusation per committee member per height, and to skip the proofs already handled
+	scan := fd.scan(height)
 
 	for _, proof := range proofs {
+		hash, err := proof.CanonicalHash()
+		if err != nil {
+			fd.logger.Error("Can't hash accountability proof", "err", err)
+			continue
+		}
+		if _, ok := scan.proofs[hash]; ok {
+			continue
+		}
+		scan.proofs[hash] = struct{}{}
 		offender := lastHeader.Committee[proof.OffenderIndex].Address
 
 		// skip misbehaviour or accusation against self
@@ -629,16 +787,18 @@ func (fd *FaultDetector) runRuleEngine(height uint64) []*autonity.Accountability
 
 		// attempt off-chain accusation resolution before escalating on-chain
 		if proof.Type == autonity.Accusation {
-			if accused[offender] < maxAccusationPerHeight {
+			if scan.accused[offender] < maxAccusationPerHeight {
 				fd.addOffChainAccusation(proof)
 				fd.sendOffChainAccusationMsg(proof, lastHeader.Committee)
-				accused[offender]++
+				fd.journal.record(ActionAccused, proof, lastHeader.Committee)
+				scan.accused[offender]++
 			} else {
 				fd.logger.Debug("Discarding accusation, maximum already reached for this height", "offender", offender)
 			}
 			continue
 		}
 
+		fd.journal.record(ActionDetected, proof, lastHeader.Committee)
 		p := fd.eventFromProof(proof, lastHeader.Committee[proof.OffenderIndex].Address)
 		events = append(events, p)
 	}
@@ -646,7 +806,9 @@ func (fd *FaultDetector) runRuleEngine(height uint64) []*autonity.Accountability
 	return events
 }
 
-func (fd *FaultDetector) runRulesOverHeight(height uint64, quorum *big.Int, committee types.Committee) (proofs []*Proof) {
+// runRulesOverHeight applies the rules to the messages of the offender at the given height, or to the
+// messages of every committee member for allOffenders.
+func (fd *FaultDetector) runRulesOverHeight(height uint64, quorum *big.Int, committee types.Committee, offender int) (proofs []*Proof) {
 	// Rules read right to left (find  the right and look for the left)
 	//
 	// Rules should be evaluated such that we check all possible instances and if we can't find a single instance that
@@ -661,14 +823,18 @@ func (fd *FaultDetector) runRulesOverHeight(height uint64, quorum *big.Int, comm
 	// We should be here at time t = timestamp(h+1) + delta
 	// In this rule engine context, the symbol `pi` stands for a consensus participant with unique identity `i`.
 
-	proofs = append(proofs, fd.newProposalsAccountabilityCheck(height)...)
-	proofs = append(proofs, fd.oldProposalsAccountabilityCheck(height, quorum)...)
-	proofs = append(proofs, fd.prevotesAccountabilityCheck(height, quorum, committee)...)
-	proofs = append(proofs, fd.precommitsAccountabilityCheck(height, quorum, committee)...)
+	proofs = append(proofs, fd.newProposalsAccountabilityCheck(height, offender)...)
+	proofs = append(proofs, fd.oldProposalsAccountabilityCheck(height, quorum, offender)...)
+	proofs = append(proofs, fd.prevotesAccountabilityCheck(height, quorum, committee, offender)...)
+	proofs = append(proofs, fd.precommitsAccountabilityCheck(height, quorum, committee, offender)...)
+	// the proofs are built with their evidences in canonical order, for them to be deduplicated by any client.
+	for _, proof := range proofs {
+		SortEvidences(proof.Evidences)
+	}
 	return proofs
 }
 
-func (fd *FaultDetector) newProposalsAccountabilityCheck(height uint64) (proofs []*Proof) {
+func (fd *FaultDetector) newProposalsAccountabilityCheck(height uint64, offender int) (proofs []*Proof) {
 	// ------------New Proposal------------
 	// PN:  (Mr′<r,PC|pi)∗ <--- (Mr,P|pi)
 	// PN1: [nil ∨ ⊥] <--- [V]
@@ -678,7 +844,7 @@ func (fd *FaultDetector) newProposalsAccountabilityCheck(height uint64) (proofs
 	// pi in rounds r' < r is for a non-nil value then we have proof of misbehaviour.
 
 	proposalsNew := fd.msgStore.GetProposals(height, func(m *message.Propose) bool {
-		return m.ValidRound() == -1
+		return m.ValidRound() == -1 && inScope(offender, m.SignerIndex())
 	})
 
 	for _, proposal := range proposalsNew {
@@ -712,13 +878,13 @@ func (fd *FaultDetector) newProposalsAccountabilityCheck(height uint64) (proofs
 	return proofs
 }
 
-func (fd *FaultDetector) oldProposalsAccountabilityCheck(height uint64, quorum *big.Int) (proofs []*Proof) {
+func (fd *FaultDetector) oldProposalsAccountabilityCheck(height uint64, quorum *big.Int, offender int) (proofs []*Proof) {
 	// ------------Old Proposal------------
 	// PO: (Mr′<r,PV) ∧ (Mr′,PC|pi) ∧ (Mr′<r′′<r,P C|pi)∗ <--- (Mr,P|pi)
 	// PO1: [#(Mr′,PV|V) ≥ 2f+ 1] ∧ [nil ∨ V ∨ ⊥] ∧ [nil ∨ ⊥] <--- [V]
 
 	proposalsOld := fd.msgStore.GetProposals(height, func(m *message.Propose) bool {
-		return m.ValidRound() > -1
+		return m.ValidRound() > -1 && inScope(offender, m.SignerIndex())
 	})
 
 oldProposalLoop:
@@ -822,16 +988,19 @@ oldProposalLoop:
 	return proofs
 }
 
-func (fd *FaultDetector) prevotesAccountabilityCheck(height uint64, quorum *big.Int, committee types.Committee) (proofs []*Proof) {
+func (fd *FaultDetector) prevotesAccountabilityCheck(height uint64, quorum *big.Int, committee types.Committee, offender int) (proofs []*Proof) {
 	// ------------New and Old prevotes------------
 
 	prevotes := fd.msgStore.GetPrevotes(height, func(m *message.Prevote) bool {
-		return m.Value() != nilValue
+		return m.Value() != nilValue && (offender == allOffenders || m.Signers().Contains(offender))
 	})
 
 	for _, prevote := range prevotes {
 	signersLoop:
 		for _, signerIndex := range prevote.Signers().FlattenUniq() {
+			if !inScope(offender, signerIndex) {
+				continue signersLoop
+			}
 			signer := committee[signerIndex].Address
 			// Skip the prevotes that the signer addressed as equivocated
 			prevotesForR := fd.msgStore.GetPrevotes(height, func(m *message.Prevote) bool {
@@ -1140,18 +1309,21 @@ func (fd *FaultDetector) oldPrevotesAccountabilityCheck(height uint64, quorum *b
 	return nil
 }
 
-func (fd *FaultDetector) precommitsAccountabilityCheck(height uint64, quorum *big.Int, committee types.Committee) (proofs []*Proof) {
+func (fd *FaultDetector) precommitsAccountabilityCheck(height uint64, quorum *big.Int, committee types.Committee, offender int) (proofs []*Proof) {
 	// ------------precommits------------
 	// C: [Mr,P|proposer(r)] ∧ [Mr,PV] <--- [Mr,PC|pi]
 	// C1: [V:Valid(V)] ∧ [#(V) ≥ 2f+ 1] <--- [V]
 
 	precommits := fd.msgStore.GetPrecommits(height, func(m *message.Precommit) bool {
-		return m.Value() != nilValue
+		return m.Value() != nilValue && (offender == allOffenders || m.Signers().Contains(offender))
 	})
 
 	for _, precommit := range precommits {
 	signersLoop:
 		for _, signerIndex := range precommit.Signers().FlattenUniq() {
+			if !inScope(offender, signerIndex) {
+				continue signersLoop
+			}
 			signer := committee[signerIndex].Address
 
 			// Skip if preCommit is equivocated
@@ -1243,8 +1415,12 @@ func (fd *FaultDetector) checkSelfIncriminatingProposal(proposal *message.Propos
 		return errDuplicatedMsg
 	}
 
-	// account for wrong proposer.
-	if !isProposerValid(fd.blockchain, proposal) {
+	// account for wrong proposer, which cannot be checked without the state to elect the proposer from.
+	statedb, err := fd.headStates.StateAt(fd.blockchain.CurrentBlock().Root())
+	if err != nil {
+		return err
+	}
+	if !isProposerValid(fd.blockchain, statedb, proposal) {
 		fd.submitMisbehavior(message.NewLightProposal(proposal), nil, errProposer, proposal.SignerIndex(), proposal.Signer())
 		return errProposer
 	}
@@ -1343,16 +1519,21 @@ func errorToRule(err error) autonity.Rule {
 // it would be better to use that function but it requires sharing the CommitteeSet between Core and the FD.
 // It would reduce code repetition, however the proposer cache is already shared so it would not improve performance.
 func getProposer(chain ChainContext, h uint64, r int64) (common.Address, error) {
-	parentHeader := chain.GetHeaderByNumber(h - 1)
-	// to prevent the panic on node shutdown.
-	if parentHeader == nil {
-		return common.Address{}, fmt.Errorf("cannot find parent header")
-	}
 	statedb, err := chain.State()
 	if err != nil {
 		log.Crit("could not retrieve state")
 		return common.Address{}, err
 	}
+	return proposerAt(chain, statedb, h, r)
+}
+
+// proposerAt returns the proposer of round r at height h, elected from the given state.
+func proposerAt(chain ChainContext, statedb *state.StateDB, h uint64, r int64) (common.Address, error) {
+	parentHeader := chain.GetHeaderByNumber(h - 1)
+	// to prevent the panic on node shutdown.
+	if parentHeader == nil {
+		return common.Address{}, fmt.Errorf("cannot find parent header")
+	}
 	proposer := chain.ProtocolContracts().Proposer(parentHeader, statedb, parentHeader.Number.Uint64(), r)
 	member := parentHeader.CommitteeMember(proposer)
 	if member == nil {
@@ -1361,8 +1542,8 @@ func getProposer(chain ChainContext, h uint64, r int64) (common.Address, error)
 	return proposer, nil
 }
 
-func isProposerValid(chain ChainContext, m message.Msg) bool {
-	proposer, err := getProposer(chain, m.H(), m.R())
+func isProposerValid(chain ChainContext, statedb *state.StateDB, m message.Msg) bool {
+	proposer, err := proposerAt(chain, statedb, m.H(), m.R())
 	if err != nil {
 		log.Error("get proposer err", "err", err)
 		return false
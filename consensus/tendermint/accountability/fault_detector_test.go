@@ -123,6 +123,77 @@ func TestSubmitMisbehaviour(t *testing.T) {
 	require.Equal(t, proposer, p.Offender)
 }
 
+// TestVoteEquivocation checks that two prevotes, or two precommits, of a validator for different values at the
+// same height and round are detected as an equivocation carrying both votes.
+func TestVoteEquivocation(t *testing.T) {
+	height := uint64(100)
+	round := int64(1)
+	lastHeader := &types.Header{Number: new(big.Int).SetUint64(height - 1), Committee: committee}
+
+	newFaultDetector := func(t *testing.T) *FaultDetector {
+		ctrl := gomock.NewController(t)
+		chainMock := NewMockChainContext(ctrl)
+		chainMock.EXPECT().GetHeaderByNumber(height - 1).Return(lastHeader).AnyTimes()
+		return &FaultDetector{
+			blockchain:          chainMock,
+			msgStore:            core.NewMsgStore(),
+			misbehaviourProofCh: make(chan *autonity.AccountabilityEvent, 100),
+			logger:              log.New("FaultDetector", nil),
+		}
+	}
+	checkProof := func(t *testing.T, fd *FaultDetector, vote, equivocated message.Msg) {
+		require.Len(t, fd.misbehaviourProofCh, 1)
+		ev := <-fd.misbehaviourProofCh
+		require.Equal(t, uint8(autonity.Misbehaviour), ev.EventType)
+		require.Equal(t, uint8(autonity.Equivocation), ev.Rule)
+		require.Equal(t, proposer, ev.Offender)
+
+		proof, err := decodeRawProof(ev.RawProof)
+		require.NoError(t, err)
+		require.Equal(t, proposerIdx, proof.OffenderIndex)
+		require.Equal(t, vote.Value(), proof.Message.Value())
+		require.Len(t, proof.Evidences, 1)
+		require.Equal(t, equivocated.Value(), proof.Evidences[0].Value())
+		require.Equal(t, vote.R(), proof.Evidences[0].R())
+		require.Equal(t, vote.Code(), proof.Evidences[0].Code())
+	}
+
+	t.Run("prevotes for different values", func(t *testing.T) {
+		fd := newFaultDetector(t)
+		prevote := newValidatedPrevote(round, height, nilValue, signer, self, cSize)
+		equivocated := newValidatedPrevote(round, height, noneNilValue, signer, self, cSize)
+
+		require.NoError(t, fd.checkSelfIncriminatingPrevote(prevote))
+		require.ErrorIs(t, fd.checkSelfIncriminatingPrevote(equivocated), errEquivocation)
+		checkProof(t, fd, equivocated, prevote)
+	})
+
+	t.Run("precommits for different values", func(t *testing.T) {
+		fd := newFaultDetector(t)
+		precommit := newValidatedPrecommit(round, height, nilValue, signer, self, cSize)
+		equivocated := newValidatedPrecommit(round, height, noneNilValue, signer, self, cSize)
+
+		require.NoError(t, fd.checkSelfIncriminatingPrecommit(precommit))
+		require.ErrorIs(t, fd.checkSelfIncriminatingPrecommit(equivocated), errEquivocation)
+		checkProof(t, fd, equivocated, precommit)
+	})
+
+	t.Run("votes of different rounds are no equivocation", func(t *testing.T) {
+		fd := newFaultDetector(t)
+		require.NoError(t, fd.checkSelfIncriminatingPrevote(newValidatedPrevote(round, height, nilValue, signer, self, cSize)))
+		require.NoError(t, fd.checkSelfIncriminatingPrevote(newValidatedPrevote(round+1, height, noneNilValue, signer, self, cSize)))
+		require.Empty(t, fd.misbehaviourProofCh)
+	})
+
+	t.Run("duplicated vote is no equivocation", func(t *testing.T) {
+		fd := newFaultDetector(t)
+		prevote := newValidatedPrevote(round, height, noneNilValue, signer, self, cSize)
+		require.NoError(t, fd.checkSelfIncriminatingPrevote(prevote))
+		require.ErrorIs(t, fd.checkSelfIncriminatingPrevote(prevote), errDuplicatedMsg)
+		require.Empty(t, fd.misbehaviourProofCh)
+	})
+}
+
 func TestRunRuleEngine(t *testing.T) {
 	round := int64(3)
 	t.Run("test run rules with malicious behaviour should be detected", func(t *testing.T) {
@@ -223,6 +294,43 @@ func TestGenerateOnChainProof(t *testing.T) {
 // todo: (Jason) add test to cover an accusation over a committed block scenario,
 //
 //	in such context, the accusation is considered as useless, it should be dropped.
+func TestRuleEngineDigestMode(t *testing.T) {
+	chainHead := uint64(100)
+	checkPointHeight := chainHead - uint64(DeltaBlocks)
+	ctrl := gomock.NewController(t)
+	chainMock := NewMockChainContext(ctrl)
+	chainMock.EXPECT().Config().AnyTimes().Return(&params.ChainConfig{ChainID: common.Big1})
+	var blockSub event.Subscription
+	chainMock.EXPECT().SubscribeChainEvent(gomock.Any()).AnyTimes().Return(blockSub)
+	fdAddr := committee[1].Address
+	accountability, _ := autonity.NewAccountability(proposer, backends.NewSimulatedBackend(ccore.GenesisAlloc{fdAddr: {Balance: big.NewInt(params.Ether)}}, 10000000))
+	fd := NewFaultDetector(chainMock, fdAddr, nil, core.NewMsgStore(), nil, nil, proposerNodeKey, &autonity.ProtocolContracts{Accountability: accountability}, log.Root())
+	require.ErrorIs(t, fd.SetMsgStoreMode("light"), errInvalidMsgStoreMode)
+	require.NoError(t, fd.SetMsgStoreMode(MsgStoreDigest))
+
+	// the PN scenario detected in full mode, along with an equivocated proposal
+	require.NoError(t, fd.processMsg(newValidatedProposalMessage(checkPointHeight-1, 0, -1, makeSigner(keys[1]), committee, nil, 1)))
+	initProposal := newValidatedProposalMessage(checkPointHeight, 0, -1, makeSigner(keys[1]), committee, nil, 1)
+	require.NoError(t, fd.processMsg(initProposal))
+	require.NoError(t, fd.processMsg(aggregatedPreVote(len(committee), checkPointHeight, 0, initProposal.Value(), keys, committee)))
+	require.NoError(t, fd.processMsg(newValidatedPrecommit(0, checkPointHeight, initProposal.Value(), signer, self, cSize)))
+	maliciousProposal := newValidatedProposalMessage(checkPointHeight, 3, -1, signer, committee, nil, proposerIdx)
+	require.NoError(t, fd.processMsg(maliciousProposal))
+	require.NoError(t, fd.processMsg(newValidatedProposalMessage(checkPointHeight, 3, -1, signer, committee, nil, proposerIdx)))
+
+	// the messages are observed, but no proof is built out of them
+	stats := fd.MsgStoreStats()
+	require.Equal(t, core.DigestMode, stats.Mode)
+	require.Zero(t, stats.Messages)
+	require.Equal(t, 2+len(committee)+1+1, stats.Digests)
+	require.Zero(t, committee.TotalVotingPower().Cmp(fd.msgStore.PrevotesPowerFor(checkPointHeight, 0, initProposal.Value())))
+	require.Empty(t, fd.runRuleEngine(checkPointHeight))
+	require.Empty(t, fd.misbehaviourProofCh)
+
+	_, err := fd.innocenceProof(&Proof{Type: autonity.Accusation, Rule: autonity.PO, OffenderIndex: proposerIdx, Message: maliciousProposal.ToLight()}, committee)
+	require.ErrorIs(t, err, core.ErrDigestMode)
+}
+
 func TestAccusationProvers(t *testing.T) {
 	height := uint64(100)
 	lastHeight := height - 1
@@ -518,7 +626,7 @@ func TestNewProposalAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(newProposal0),
 		}
 
-		proofs := fd.newProposalsAccountabilityCheck(0)
+		proofs := fd.newProposalsAccountabilityCheck(0, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedProof, actualProof)
@@ -530,7 +638,7 @@ func TestNewProposalAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(nonNilPrecommit0)
 		fd.msgStore.Save(newProposal0E)
 
-		proofs := fd.newProposalsAccountabilityCheck(0)
+		proofs := fd.newProposalsAccountabilityCheck(0, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -539,7 +647,7 @@ func TestNewProposalAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(newProposal0)
 		fd.msgStore.Save(newProposal1)
 
-		proofs := fd.newProposalsAccountabilityCheck(0)
+		proofs := fd.newProposalsAccountabilityCheck(0, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -550,7 +658,7 @@ func TestNewProposalAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(newProposal1)
 		fd.msgStore.Save(nilPrecommit1)
 
-		proofs := fd.newProposalsAccountabilityCheck(0)
+		proofs := fd.newProposalsAccountabilityCheck(0, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -576,7 +684,7 @@ func TestNewProposalAccountabilityCheck(t *testing.T) {
 			Message:       newProposal1,
 		}
 
-		proofs := fd.newProposalsAccountabilityCheck(0)
+		proofs := fd.newProposalsAccountabilityCheck(0, allOffenders)
 		require.Equal(t, 2, len(proofs))
 
 		// The order of proofs is non know apriori
@@ -641,7 +749,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(oldProposal0),
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedProof, actualProof)
@@ -660,7 +768,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(oldProposal0),
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedProof, actualProof)
@@ -679,7 +787,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(oldProposal0),
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedProof, actualProof)
@@ -698,7 +806,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(oldProposal0),
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedProof.Type, actualProof.Type)
@@ -718,7 +826,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(oldProposal0),
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedProof, actualProof)
@@ -736,7 +844,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(oldProposal0),
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedProof, actualProof)
@@ -747,7 +855,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(oldProposal0)
 		fd.msgStore.Save(oldProposal0E)
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -756,7 +864,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(oldProposal0)
 		fd.msgStore.Save(oldProposal0E2)
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -769,7 +877,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			fd.msgStore.Save(m)
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -783,7 +891,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			fd.msgStore.Save(m)
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -793,7 +901,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(quorumPrevotes0V)
 		fd.msgStore.Save(nonNilPrecommit0V)
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -803,7 +911,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(quorumPrevotes0V)
 		fd.msgStore.Save(nilPrecommit0)
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -812,7 +920,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(oldProposal0)
 		fd.msgStore.Save(quorumPrevotes0V)
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -837,7 +945,7 @@ func TestOldProposalsAccountabilityCheck(t *testing.T) {
 			Message:       message.NewLightProposal(oldProposal5),
 		}
 
-		proofs := fd.oldProposalsAccountabilityCheck(height, quorum)
+		proofs := fd.oldProposalsAccountabilityCheck(height, quorum, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		require.Contains(t, proofs, expectedMisbehaviour)
 		require.Contains(t, proofs, expectedAccusation)
@@ -907,7 +1015,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			Rule:          autonity.PVN,
 			Message:       prevoteForB,
 		}
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		require.Contains(t, proofs, expectedAccusation)
 	})
@@ -927,7 +1035,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			Rule:          autonity.PVN,
 			Message:       aggregatedPrevoteForB,
 		}
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		require.Contains(t, proofs, expectedAccusation1)
 		require.Contains(t, proofs, expectedAccusation2)
@@ -956,7 +1064,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			Message:       aggregatedPrevoteForB,
 		}
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		require.Contains(t, proofs, expectedMisbehaviour1)
 		require.Contains(t, proofs, expectedMisbehaviour2)
@@ -985,7 +1093,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			Message:       aggregatedPrevoteForB,
 		}
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		require.Contains(t, proofs, expectedMisbehaviour1)
 		require.Contains(t, proofs, expectedMisbehaviour2)
@@ -1013,7 +1121,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		}
 		expectedMisbehaviour.Evidences = append(expectedMisbehaviour.Evidences, precommitNilsAfter0...)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedMisbehaviour.Type, actualProof.Type)
@@ -1047,7 +1155,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		}
 		expectedMisbehaviour.Evidences = append(expectedMisbehaviour.Evidences, precommitNilsAfter1...)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedMisbehaviour.Type, actualProof.Type)
@@ -1064,7 +1172,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(aggregatedPrevoteForB)
 		fd.msgStore.Save(precommitForBIn4)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1074,7 +1182,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(prevoteForB)
 		fd.msgStore.Save(precommitForBIn0)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1085,7 +1193,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(precommitForBIn0)
 		fd.msgStore.Save(newValidatedPrecommit(3, height, nilValue, signer, self, cSize))
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1099,7 +1207,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			fd.msgStore.Save(precommitNil)
 		}
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1118,7 +1226,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 
 		fd.msgStore.Save(precommitForBIn4)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1143,7 +1251,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			Evidences:     []message.Msg{message.NewLightProposal(oldProposalB10)},
 		}
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		require.Contains(t, proofs, expectedAccusation1)
 		require.Contains(t, proofs, expectedAccusation2)
@@ -1183,7 +1291,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		expectedMisbehaviour2.Evidences = append(expectedMisbehaviour2.Evidences, message.NewLightProposal(oldProposalB10))
 		expectedMisbehaviour2.Evidences = append(expectedMisbehaviour2.Evidences, vr5Prevotes...)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		actualProof1 := proofs[0]
 		require.Equal(t, expectedMisbehaviour1.Type, actualProof1.Type)
@@ -1239,7 +1347,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		expectedMisbehaviour.Evidences = append(expectedMisbehaviour.Evidences, message.NewLightProposal(oldProposalB10))
 		expectedMisbehaviour.Evidences = append(expectedMisbehaviour.Evidences, precommitsFromPiAfterLatestPrecommitForB...)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 1, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedMisbehaviour.Type, actualProof.Type)
@@ -1264,7 +1372,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			fd.msgStore.Save(v)
 		}
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 
 	})
@@ -1282,7 +1390,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(precommitForBIn7)
 		fd.msgStore.Save(precommitForB1In8)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 
 	})
@@ -1342,7 +1450,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		expectedMisbehaviour2.Evidences = append(expectedMisbehaviour2.Evidences, message.NewLightProposal(oldProposalB10))
 		expectedMisbehaviour2.Evidences = append(expectedMisbehaviour2.Evidences, precommitsFromPiAfterVR2...)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		actualProof := proofs[0]
 		require.Equal(t, expectedMisbehaviour1.Type, actualProof.Type)
@@ -1375,7 +1483,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 			fd.msgStore.Save(newValidatedPrecommit(i, height, nilValue, signer, self, cSize))
 		}
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1393,7 +1501,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		p := newValidatedPrecommit(precommitForB1In8.R()+1, height, nilValue, signer, self, cSize)
 		fd.msgStore.Save(p)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1411,7 +1519,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		}
 		fd.msgStore.Save(precommitForB1In8)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1431,7 +1539,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		}
 
 		// Misbehaviour of PVN and Accusation of PVO shall rise to both two nodes, thus we will expect 4 proofs.
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 4, len(proofs))
 	})
 
@@ -1440,7 +1548,7 @@ func TestPrevotesAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(aggregatedPrevoteForB)
 		fd.msgStore.Save(aggregatedPrevoteForB1)
 
-		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee)
+		proofs := fd.prevotesAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 }
@@ -1492,7 +1600,7 @@ func TestPrecommitsAccountabilityCheck(t *testing.T) {
 			Message:       aggregatedPrecommitForB,
 		}
 
-		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee)
+		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		require.Equal(t, expectedAccusation1, proofs[0])
 		require.Equal(t, expectedAccusation2, proofs[1])
@@ -1522,7 +1630,7 @@ func TestPrecommitsAccountabilityCheck(t *testing.T) {
 		}
 		expectedMisbehaviour2.Evidences = append(expectedMisbehaviour2.Evidences, preVotesForB1)
 
-		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee)
+		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 		require.Equal(t, expectedMisbehaviour1, proofs[0])
 		require.Equal(t, expectedMisbehaviour2, proofs[1])
@@ -1556,7 +1664,7 @@ func TestPrecommitsAccountabilityCheck(t *testing.T) {
 			Rule:          autonity.C1,
 			Message:       precommitForB1In3,
 		}
-		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee)
+		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 2, len(proofs))
 
 		for _, p := range proofs {
@@ -1587,7 +1695,7 @@ func TestPrecommitsAccountabilityCheck(t *testing.T) {
 			fd.msgStore.Save(newValidatedPrevote(2, height, block.Hash(), makeSigner(keys[i]), &committee[i], cSize))
 		}
 
-		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee)
+		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1600,7 +1708,7 @@ func TestPrecommitsAccountabilityCheck(t *testing.T) {
 			fd.msgStore.Save(newValidatedPrevote(2, height, block.Hash(), makeSigner(keys[i]), &committee[i], cSize))
 		}
 
-		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee)
+		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 
@@ -1609,7 +1717,7 @@ func TestPrecommitsAccountabilityCheck(t *testing.T) {
 		fd.msgStore.Save(aggregatedPrecommitForB)
 		fd.msgStore.Save(aggregatedPrecommitForB1)
 
-		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee)
+		proofs := fd.precommitsAccountabilityCheck(height, quorum, committee, allOffenders)
 		require.Equal(t, 0, len(proofs))
 	})
 }
@@ -1,8 +1,12 @@
 package accountability
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/rlp"
@@ -10,64 +14,174 @@ import (
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/backend"
-	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/eth/protocols/eth"
 )
 
 var (
 	errNoParentHeader              = errors.New("no parent header")
 	errInvalidAccusation           = errors.New("invalid accusation")
-	errPeerDuplicatedAccusation    = errors.New("remote peer is sending duplicated accusation")
+	errPeerDuplicatedAccusation    = fmt.Errorf("%w: remote peer is sending duplicated accusation", constants.ErrAccusationSpam)
 	errInvalidInnocenceProof       = errors.New("invalid proof of innocence")
-	errAccusationRateMalicious     = errors.New("malicious accusation msg rate, peer to be dropped")
+	errAccusationRateMalicious     = fmt.Errorf("%w: malicious accusation msg rate, peer to be dropped", constants.ErrAccusationSpam)
+	errAccusationRateExceeded      = errors.New("accountability msg rate exceeded, msg dropped")
 	errAccusationFromNoneValidator = errors.New("accusation from none validator node")
 )
 
+const (
+	violationPenalty  = 1  // score penalty of an off-chain accountability message dropped over the rate limit
+	disconnectPenalty = 10 // score penalty of a peer disconnected for the off-chain accountability protocol
+)
+
 type AccusationRateLimiter struct {
 	// to track the number of accusation sent by a challenger over a specific height.
 	accusationsPerHeight map[common.Address]map[uint64]int
-	// malicious one might use out of updated accusations to DoS node, so we track those recently accusation rates.
-	accusationRates map[common.Address]int
 	// track if one send duplicated accusation.
 	peerProcessedAccusations map[common.Address]map[common.Hash]struct{}
+
+	// malicious one might use out of updated accusations to DoS node, so we track the rate of the off-chain
+	// accountability messages of each peer over a sliding window. It is read by the debug API.
+	lock          sync.Mutex
+	now           func() time.Time
+	maxMessages   int
+	window        time.Duration
+	maxViolations int
+	peerRates     map[common.Address]*peerMessageRate
+}
+
+// peerMessageRate is the rate limiting state of the off-chain accountability messages of a peer.
+type peerMessageRate struct {
+	accepted    []time.Time // arrival of the messages accepted over the window, oldest first
+	violations  int         // consecutive messages over the limit
+	dropped     uint64
+	disconnects uint64
+	score       int64
+}
+
+// PeerAccusationRate is the rate limiting state of the off-chain accountability messages of a peer.
+type PeerAccusationRate struct {
+	Peer        common.Address `json:"peer"`
+	Messages    int            `json:"messages"`    // messages accepted over the current window
+	Violations  int            `json:"violations"`  // consecutive messages over the limit
+	Dropped     uint64         `json:"dropped"`     // messages dropped over the limit
+	Disconnects uint64         `json:"disconnects"` // disconnections for the off-chain accountability protocol
+	Score       int64          `json:"score"`       // penalties of the drops and disconnections, never positive
 }
 
 func NewAccusationRateLimiter() *AccusationRateLimiter {
 	l := &AccusationRateLimiter{
-		accusationRates:          make(map[common.Address]int),
 		accusationsPerHeight:     make(map[common.Address]map[uint64]int),
 		peerProcessedAccusations: make(map[common.Address]map[common.Hash]struct{}),
+		now:                      time.Now,
+		peerRates:                make(map[common.Address]*peerMessageRate),
 	}
+	l.setMessageRate(DefaultConfig.MaxOffChainMessages, DefaultConfig.OffChainMessageWindow, DefaultConfig.MaxOffChainViolations)
 	return l
 }
 
+// setMessageRate sets the number of off-chain accountability messages accepted from a peer over the sliding
+// window, and the number of consecutive messages over the limit after which the peer is disconnected.
+func (r *AccusationRateLimiter) setMessageRate(maxMessages uint64, window time.Duration, maxViolations uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.maxMessages = int(max(maxMessages, 1))
+	r.window = max(window, time.Second)
+	r.maxViolations = int(max(maxViolations, 1))
+}
+
 // although we have rate limit over per height, but since malicious node can use out of updated consensus msg to send
-// accusation to DoS node, thus we have to track the rate limit of accusation during the recently period, 1 seconds.
-func (r *AccusationRateLimiter) validAccusationRate(sender common.Address) error {
-	// get accusation counters of the last 1 seconds.
-	times, ok := r.accusationRates[sender]
+// accusation to DoS node, thus we have to track the rate of the messages of each peer over a sliding window. A message
+// over the limit is dropped and lowers the score of the peer, which is disconnected only once it sent maxViolations
+// consecutive messages over the limit: pending writes of off-chain accountability messages can burst once a network
+// session recovers.
+func (r *AccusationRateLimiter) checkMessageRate(sender common.Address) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	rate, ok := r.peerRates[sender]
 	if !ok {
-		r.accusationRates[sender] = 1
+		rate = new(peerMessageRate)
+		r.peerRates[sender] = rate
+	}
+	rate.expire(now.Add(-r.window))
+	if len(rate.accepted) < r.maxMessages {
+		rate.accepted = append(rate.accepted, now)
+		rate.violations = 0
 		return nil
 	}
-
-	// since communication channel is asynchronous, those pending write of off chain accusation msgs from a sender could
-	// potentially be received once the network session get established from a disaster, thus it could exceed the number
-	// of accusation that could be produced by rule engine over a height, so we set higher rate limit during 1 second
-	// to be tolerant for such case.
-	if times > maxAccusationPerHeight*2 {
+	rate.violations++
+	rate.dropped++
+	rate.score -= violationPenalty
+	if rate.violations >= r.maxViolations {
 		return errAccusationRateMalicious
 	}
+	return errAccusationRateExceeded
+}
 
-	r.accusationRates[sender]++
-	return nil
+// disconnected records the disconnection of a peer for the off-chain accountability protocol. Its rate starts
+// over with the next session.
+func (r *AccusationRateLimiter) disconnected(sender common.Address) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	rate, ok := r.peerRates[sender]
+	if !ok {
+		rate = new(peerMessageRate)
+		r.peerRates[sender] = rate
+	}
+	rate.accepted = nil
+	rate.violations = 0
+	rate.disconnects++
+	rate.score -= disconnectPenalty
+}
+
+// resetIdleRates drops the rate of the peers which did not send any message over the window and were never
+// penalized.
+func (r *AccusationRateLimiter) resetIdleRates() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	from := r.now().Add(-r.window)
+	for sender, rate := range r.peerRates {
+		rate.expire(from)
+		if len(rate.accepted) == 0 && rate.score == 0 {
+			delete(r.peerRates, sender)
+		}
+	}
 }
 
-// rate limit counters are reset on each 1 seconds.
-func (r *AccusationRateLimiter) resetRateLimiter() {
-	for k := range r.accusationRates {
-		delete(r.accusationRates, k)
+// rates returns the rate limiting state of the peers, sorted by address.
+func (r *AccusationRateLimiter) rates() []PeerAccusationRate {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	from := r.now().Add(-r.window)
+	rates := make([]PeerAccusationRate, 0, len(r.peerRates))
+	for sender, rate := range r.peerRates {
+		rate.expire(from)
+		rates = append(rates, PeerAccusationRate{
+			Peer:        sender,
+			Messages:    len(rate.accepted),
+			Violations:  rate.violations,
+			Dropped:     rate.dropped,
+			Disconnects: rate.disconnects,
+			Score:       rate.score,
+		})
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return bytes.Compare(rates[i].Peer[:], rates[j].Peer[:]) < 0
+	})
+	return rates
+}
+
+// expire forgets the messages accepted before from.
+func (p *peerMessageRate) expire(from time.Time) {
+	i := 0
+	for i < len(p.accepted) && p.accepted[i].Before(from) {
+		i++
 	}
+	p.accepted = p.accepted[i:]
 }
 
 func (r *AccusationRateLimiter) checkPeerDuplicatedAccusation(sender common.Address, msgHash common.Hash) error {
@@ -157,15 +271,27 @@ func (i *InnocenceProofBuffer) getInnocenceProofFromCache(challengeHash common.H
 // protocol. It returns error to freeze remote peer for 30s by according to dev p2p protocol to prevent from DoS attack.
 // NOTE: sender is the p2p sender of the offchain accountability message
 func (fd *FaultDetector) handleOffChainAccountabilityEvent(payload []byte, sender common.Address) error {
-	// drop peer if the accusation exceed the rate limit during the last 1 seconds.
-	err := fd.rateLimiter.validAccusationRate(sender)
+	// drop the msg if the peer exceeds its rate limit over the sliding window, and the peer if it keeps on.
+	err := fd.rateLimiter.checkMessageRate(sender)
+	if errors.Is(err, errAccusationRateExceeded) {
+		fd.logger.Debug("Dropping over rated accountability msg", "sender", sender)
+		return nil
+	}
+	if err != nil {
+		fd.logger.Error("accountability abuse detected!", "sender", sender, "err", err)
+		return err
+	}
+
+	proof, err := decodeRawProof(payload)
 	if err != nil {
-		fd.logger.Error("accountability abuse detected!", "err", err)
 		return err
 	}
 
-	// drop peer if it sent duplicated accusation event.
-	msgHash := crypto.Hash(payload)
+	// drop peer if it sent duplicated accusation event, the same evidences in a different order included.
+	msgHash, err := proof.CanonicalHash()
+	if err != nil {
+		return err
+	}
 	err = fd.rateLimiter.checkPeerDuplicatedAccusation(sender, msgHash)
 	if err != nil {
 		fd.logger.Error("duplicated accusation from peer", "err", err)
@@ -179,12 +305,6 @@ func (fd *FaultDetector) handleOffChainAccountabilityEvent(payload []byte, sende
 		return nil
 	}
 
-	// handle a brand-new accusation event from rlp decoding of proof.
-	proof, err := decodeRawProof(payload)
-	if err != nil {
-		return err
-	}
-
 	// drop peer if the proof does not come from a committee member
 	msgHeight := proof.Message.H()
 	lastHeader := fd.blockchain.GetHeaderByNumber(msgHeight - 1)
@@ -238,6 +358,7 @@ func (fd *FaultDetector) handleOffChainAccusation(accusation *Proof, sender comm
 	if !verifyAccusation(accusation, committee) {
 		return errInvalidAccusation
 	}
+	fd.journal.record(ActionAccusationReceived, accusation, committee)
 
 	// query innocence proof for accusation from msg store.
 	ev, err := fd.innocenceProof(accusation, committee)
@@ -274,13 +395,23 @@ func (fd *FaultDetector) handleOffChainProofOfInnocence(proof *Proof, sender com
 	}
 
 	// the proof is valid, withdraw the off chain challenge.
+	fd.journal.record(ActionInnocenceReceived, proof, committee)
 	fd.removeOffChainAccusation(proof)
 	return nil
 }
 
+// addOffChainAccusation adds the accusation to the pending ones, unless the same accusation is already pending.
 func (fd *FaultDetector) addOffChainAccusation(accusation *Proof) {
 	fd.offChainAccusationsMu.Lock()
 	defer fd.offChainAccusationsMu.Unlock()
+	hash, err := accusation.CanonicalHash()
+	if err == nil {
+		for _, pending := range fd.offChainAccusations {
+			if pendingHash, err := pending.CanonicalHash(); err == nil && pendingHash == hash {
+				return
+			}
+		}
+	}
 	fd.offChainAccusations = append(fd.offChainAccusations, accusation)
 }
 
@@ -328,6 +459,7 @@ func (fd *FaultDetector) escalateExpiredAccusations(currentChainHeight uint64) {
 		lastHeader := fd.blockchain.GetHeaderByNumber(accusation.Message.H() - 1)
 		offender := lastHeader.Committee[accusation.OffenderIndex].Address
 		fd.removeOffChainAccusation(accusation)
+		fd.journal.record(ActionEscalated, accusation, lastHeader.Committee)
 		p := fd.eventFromProof(accusation, offender)
 		// push it to the on chain accountability event list
 		fd.pendingEvents = append(fd.pendingEvents, p)
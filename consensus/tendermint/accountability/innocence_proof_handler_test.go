@@ -28,27 +28,49 @@ func TestNewOffChainAccusationRateLimiter(t *testing.T) {
 	msgSender := common.Address{}
 	msgHash1 := common.Hash{0x1}
 	msgHash2 := common.Hash{0x2}
-	t.Run("test rate limit with a 1st accusation", func(t *testing.T) {
+	t.Run("test rate limit over the sliding window", func(t *testing.T) {
 		rl := NewAccusationRateLimiter()
-		err := rl.validAccusationRate(msgSender)
-		require.NoError(t, err)
-		require.Equal(t, 1, rl.accusationRates[msgSender])
+		now := time.Unix(1700000000, 0)
+		rl.now = func() time.Time { return now }
+		rl.setMessageRate(10, time.Minute, 3)
 
-		rl.resetRateLimiter()
-		require.Equal(t, 0, len(rl.accusationRates))
+		for i := 0; i < 10; i++ {
+			require.NoError(t, rl.checkMessageRate(msgSender))
+			now = now.Add(time.Second)
+		}
+		// the msgs over the limit are dropped, the peer is dropped after 3 consecutive ones
+		require.ErrorIs(t, rl.checkMessageRate(msgSender), errAccusationRateExceeded)
+		require.ErrorIs(t, rl.checkMessageRate(msgSender), errAccusationRateExceeded)
+		require.ErrorIs(t, rl.checkMessageRate(msgSender), errAccusationRateMalicious)
+		require.Equal(t, []PeerAccusationRate{{Peer: msgSender, Messages: 10, Violations: 3, Dropped: 3, Score: -3}}, rl.rates())
+
+		// the window slides: the 1st msg expires
+		now = now.Add(51 * time.Second)
+		require.NoError(t, rl.checkMessageRate(msgSender))
+		require.ErrorIs(t, rl.checkMessageRate(msgSender), errAccusationRateExceeded)
+		require.Equal(t, 1, rl.rates()[0].Violations)
+
+		// a disconnection starts a new session
+		rl.disconnected(msgSender)
+		require.Equal(t, []PeerAccusationRate{{Peer: msgSender, Dropped: 4, Disconnects: 1, Score: -14}}, rl.rates())
+		require.NoError(t, rl.checkMessageRate(msgSender))
 	})
 
-	t.Run("test rate limit with limited rate", func(t *testing.T) {
+	t.Run("test idle rates reset", func(t *testing.T) {
 		rl := NewAccusationRateLimiter()
-		for i := 0; i < maxAccusationPerHeight*2; i++ {
-			err := rl.validAccusationRate(msgSender)
-			require.NoError(t, err)
-		}
-		err := rl.validAccusationRate(msgSender)
-		require.Error(t, errAccusationRateMalicious, err)
-
-		rl.resetRateLimiter()
-		require.Equal(t, 0, len(rl.accusationRates))
+		now := time.Unix(1700000000, 0)
+		rl.now = func() time.Time { return now }
+		rl.setMessageRate(1, time.Minute, 3)
+		penalized := common.Address{0x1}
+		require.NoError(t, rl.checkMessageRate(msgSender))
+		require.NoError(t, rl.checkMessageRate(penalized))
+		require.Error(t, rl.checkMessageRate(penalized))
+
+		rl.resetIdleRates()
+		require.Len(t, rl.rates(), 2)
+		now = now.Add(time.Minute + time.Second)
+		rl.resetIdleRates()
+		require.Equal(t, []PeerAccusationRate{{Peer: penalized, Violations: 1, Dropped: 1, Score: -1}}, rl.rates())
 	})
 
 	t.Run("test duplicated accusation", func(t *testing.T) {
@@ -0,0 +1,212 @@
+package accountability
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/metrics"
+)
+
+// allOffenders scopes the rules to the messages of every committee member.
+const allOffenders = -1
+
+var (
+	lateRescansMeter       = metrics.NewRegisteredMeter("accountability/late/rescans", nil)
+	lateDroppedStaleMeter  = metrics.NewRegisteredMeter("accountability/late/stale", nil)
+	lateCappedRescansMeter = metrics.NewRegisteredMeter("accountability/late/capped", nil)
+)
+
+// inScope returns whether the rules scoped to the offender apply to the messages of the signer.
+func inScope(offender, signerIndex int) bool {
+	return offender == allOffenders || offender == signerIndex
+}
+
+// LateMessageStats counts the late consensus messages, which arrive after the rules were applied to
+// their height.
+type LateMessageStats struct {
+	IncrementalRescans uint64 // rules re-applied to the messages of a single signer
+	DroppedStale       uint64 // messages dropped for being outside the late message window
+	CappedRescans      uint64 // rescans skipped since the cap of their height was reached
+}
+
+// heightScan records the outcome of the rules applied to a height, for the rescans of the late
+// messages not to handle a proof twice.
+type heightScan struct {
+	proofs  map[common.Hash]struct{}  // canonical hashes of the proofs handled
+	accused map[common.Address]uint64 // accusations per committee member
+	rescans uint64
+}
+
+// lateMessages holds the policy applied to the late consensus messages. A late message within the
+// window triggers the rules of its height again, scoped to the messages of its signers, at the next
+// block. The rescans are capped per height, so that a peer replaying old messages cannot force
+// repeated work. The late messages outside the window are counted and dropped.
+type lateMessages struct {
+	window      atomic.Uint64 // heights behind the last height scanned a late message is accepted for
+	maxRescans  atomic.Uint64 // rescans per height, 0 for none
+	lastScanned atomic.Uint64 // last height the rules were applied to
+
+	mu      sync.Mutex
+	pending map[uint64]map[int]struct{} // signers to rescan per height
+
+	rescans      atomic.Uint64
+	droppedStale atomic.Uint64
+	capped       atomic.Uint64
+}
+
+func newLateMessages(window, maxRescans uint64) *lateMessages {
+	l := &lateMessages{pending: make(map[uint64]map[int]struct{})}
+	l.setPolicy(window, maxRescans)
+	return l
+}
+
+// setPolicy sets the late message window, which cannot exceed the msg buffer range, and the number
+// of rescans per height.
+func (l *lateMessages) setPolicy(window, maxRescans uint64) {
+	l.window.Store(min(window, HeightRange))
+	l.maxRescans.Store(maxRescans)
+}
+
+// stale returns whether the late message of the given height is outside the window.
+func (l *lateMessages) stale(height uint64) bool {
+	last := l.lastScanned.Load()
+	return last > l.window.Load() && height < last-l.window.Load()
+}
+
+// scanned returns whether the rules were already applied to the given height.
+func (l *lateMessages) scanned(height uint64) bool {
+	return height <= l.lastScanned.Load()
+}
+
+// add queues a rescan of the height for the signers of the late message.
+func (l *lateMessages) add(m message.Msg) {
+	var signers []int
+	switch msg := m.(type) {
+	case *message.Propose:
+		signers = []int{msg.SignerIndex()}
+	case *message.Prevote:
+		signers = msg.Signers().FlattenUniq()
+	case *message.Precommit:
+		signers = msg.Signers().FlattenUniq()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	offenders, ok := l.pending[m.H()]
+	if !ok {
+		offenders = make(map[int]struct{})
+		l.pending[m.H()] = offenders
+	}
+	for _, signer := range signers {
+		offenders[signer] = struct{}{}
+	}
+}
+
+// take returns the heights to rescan in ascending order, along with the signers to rescan them for.
+func (l *lateMessages) take() ([]uint64, map[uint64][]int) {
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = make(map[uint64]map[int]struct{})
+	l.mu.Unlock()
+
+	heights := make([]uint64, 0, len(pending))
+	offenders := make(map[uint64][]int, len(pending))
+	for height, signers := range pending {
+		heights = append(heights, height)
+		for signer := range signers {
+			offenders[height] = append(offenders[height], signer)
+		}
+		sort.Ints(offenders[height])
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, offenders
+}
+
+func (l *lateMessages) stats() LateMessageStats {
+	return LateMessageStats{
+		IncrementalRescans: l.rescans.Load(),
+		DroppedStale:       l.droppedStale.Load(),
+		CappedRescans:      l.capped.Load(),
+	}
+}
+
+// SetLateMessagePolicy sets the number of heights behind the last height scanned by the rules for
+// which a late consensus message is re-evaluated, capped to the msg buffer range, and the number of
+// re-evaluations per height. It must be called before Start.
+func (fd *FaultDetector) SetLateMessagePolicy(window, maxRescansPerHeight uint64) {
+	fd.late.setPolicy(window, maxRescansPerHeight)
+}
+
+// LateMessageStats returns the counters of the late consensus messages.
+func (fd *FaultDetector) LateMessageStats() LateMessageStats {
+	return fd.late.stats()
+}
+
+// handleLateMsg processes a consensus message of a past height, and queues the rescan of its height
+// for its signers if the rules were already applied to it.
+func (fd *FaultDetector) handleLateMsg(m message.Msg, headHeight uint64) error {
+	if fd.isHeightExpired(headHeight, m.H()) || fd.late.stale(m.H()) {
+		fd.late.droppedStale.Add(1)
+		lateDroppedStaleMeter.Mark(1)
+		fd.logger.Debug("Fault detector: discarding stale late message", "height", m.H())
+		return nil
+	}
+	if err := fd.processMsg(m); err != nil {
+		return err
+	}
+	if fd.late.scanned(m.H()) {
+		fd.late.add(m)
+	}
+	return nil
+}
+
+// rescanLateMessages applies the rules again to the heights which received late messages, scoped to
+// the messages of their signers.
+func (fd *FaultDetector) rescanLateMessages() {
+	heights, offenders := fd.late.take()
+	for _, height := range heights {
+		// the window moved on since the messages were received
+		if fd.late.stale(height) {
+			continue
+		}
+		for _, offender := range offenders[height] {
+			scan := fd.scan(height)
+			if scan.rescans >= fd.late.maxRescans.Load() {
+				fd.late.capped.Add(1)
+				lateCappedRescansMeter.Mark(1)
+				fd.logger.Debug("Skipping late message rescan, maximum reached for this height", "height", height)
+				continue
+			}
+			scan.rescans++
+			fd.late.rescans.Add(1)
+			lateRescansMeter.Mark(1)
+			if events := fd.runRules(height, offender); len(events) > 0 {
+				fd.pendingEvents = append(fd.pendingEvents, events...)
+			}
+		}
+	}
+}
+
+// scan returns the record of the rules applied to the given height.
+func (fd *FaultDetector) scan(height uint64) *heightScan {
+	scan, ok := fd.scans[height]
+	if !ok {
+		scan = &heightScan{
+			proofs:  make(map[common.Hash]struct{}),
+			accused: make(map[common.Address]uint64),
+		}
+		fd.scans[height] = scan
+	}
+	return scan
+}
+
+// pruneScans deletes the records of the heights out of the msg buffer range.
+func (fd *FaultDetector) pruneScans(threshold uint64) {
+	for height := range fd.scans {
+		if height < threshold {
+			delete(fd.scans, height)
+		}
+	}
+}
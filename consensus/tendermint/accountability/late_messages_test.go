@@ -0,0 +1,110 @@
+package accountability
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/accounts/abi/bind/backends"
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/bft"
+	"github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	ccore "github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+)
+
+func TestLateMessages(t *testing.T) {
+	chainHead := uint64(100)
+	height := chainHead - uint64(DeltaBlocks)
+	initProposal := newValidatedProposalMessage(height, 0, -1, makeSigner(keys[1]), committee, nil, 1)
+
+	newFD := func(t *testing.T) *FaultDetector {
+		lastHeader := &types.Header{Number: new(big.Int).SetUint64(height - 1), Committee: committee}
+		ctrl := gomock.NewController(t)
+		chainMock := NewMockChainContext(ctrl)
+		chainMock.EXPECT().GetHeaderByNumber(gomock.Any()).AnyTimes().Return(lastHeader)
+		chainMock.EXPECT().Config().AnyTimes().Return(&params.ChainConfig{ChainID: common.Big1})
+		var blockSub event.Subscription
+		chainMock.EXPECT().SubscribeChainEvent(gomock.Any()).AnyTimes().Return(blockSub)
+		fdAddr := committee[1].Address
+		accountability, _ := autonity.NewAccountability(proposer, backends.NewSimulatedBackend(ccore.GenesisAlloc{fdAddr: {Balance: big.NewInt(params.Ether)}}, 10000000))
+		fd := NewFaultDetector(chainMock, fdAddr, nil, core.NewMsgStore(), nil, nil, proposerNodeKey, &autonity.ProtocolContracts{Accountability: accountability}, log.Root())
+
+		// the proposer proposes a new value at round 3, its precommit for another value at round 0 is late.
+		fd.msgStore.Save(newValidatedProposalMessage(height-1, 0, -1, makeSigner(keys[1]), committee, nil, 1))
+		fd.msgStore.Save(initProposal)
+		fd.msgStore.Save(aggregatedPreVote(len(committee), height, 0, initProposal.Value(), keys, committee))
+		fd.msgStore.Save(newValidatedProposalMessage(height, 3, -1, signer, committee, nil, proposerIdx))
+		require.Empty(t, fd.runRuleEngine(height))
+		return fd
+	}
+	latePrecommit := func(signerIndex int) message.Msg {
+		return newValidatedPrecommit(0, height, initProposal.Value(), makeSigner(keys[signerIndex]), &committee[signerIndex], cSize)
+	}
+
+	t.Run("late message within the window is re-evaluated for its signer", func(t *testing.T) {
+		fd := newFD(t)
+		require.NoError(t, fd.handleLateMsg(latePrecommit(proposerIdx), chainHead))
+		fd.rescanLateMessages()
+		require.Equal(t, LateMessageStats{IncrementalRescans: 1}, fd.LateMessageStats())
+		require.Len(t, fd.pendingEvents, 1)
+		require.Equal(t, uint8(autonity.Misbehaviour), fd.pendingEvents[0].EventType)
+		require.Equal(t, proposer, fd.pendingEvents[0].Offender)
+
+		// a full rescan of the height finds the same proof against the signer
+		quorum := bft.Quorum(committee.TotalVotingPower())
+		var full []*Proof
+		for _, proof := range fd.runRulesOverHeight(height, quorum, committee, allOffenders) {
+			if proof.OffenderIndex == proposerIdx {
+				full = append(full, proof)
+			}
+		}
+		require.Len(t, full, 1)
+		require.Equal(t, fd.eventFromProof(full[0], proposer), fd.pendingEvents[0])
+		require.Equal(t, full, fd.runRulesOverHeight(height, quorum, committee, proposerIdx))
+
+		// the proof is not handled twice
+		require.Empty(t, fd.runRules(height, proposerIdx))
+		require.Empty(t, fd.runRuleEngine(height))
+	})
+
+	t.Run("late message outside the window is dropped", func(t *testing.T) {
+		fd := newFD(t)
+		fd.SetLateMessagePolicy(5, DefaultConfig.MaxRescansPerHeight)
+		stale := newValidatedPrecommit(0, height-6, common.Hash{1}, signer, self, cSize)
+		require.NoError(t, fd.handleLateMsg(stale, chainHead))
+		fd.rescanLateMessages()
+		require.Equal(t, LateMessageStats{DroppedStale: 1}, fd.LateMessageStats())
+		require.Empty(t, fd.msgStore.GetPrecommits(height-6, func(*message.Precommit) bool { return true }))
+		require.Empty(t, fd.pendingEvents)
+	})
+
+	t.Run("late message of a height not scanned yet is not re-evaluated", func(t *testing.T) {
+		fd := newFD(t)
+		next := newValidatedPrecommit(0, height+1, common.Hash{1}, signer, self, cSize)
+		require.NoError(t, fd.handleLateMsg(next, chainHead))
+		fd.rescanLateMessages()
+		require.Equal(t, LateMessageStats{}, fd.LateMessageStats())
+		require.Len(t, fd.msgStore.GetPrecommits(height+1, func(*message.Precommit) bool { return true }), 1)
+	})
+
+	t.Run("rescans are capped per height", func(t *testing.T) {
+		fd := newFD(t)
+		fd.SetLateMessagePolicy(DefaultConfig.LateMessageWindow, 1)
+		require.NoError(t, fd.handleLateMsg(latePrecommit(proposerIdx), chainHead))
+		require.NoError(t, fd.handleLateMsg(latePrecommit(2), chainHead))
+		fd.rescanLateMessages()
+		require.Equal(t, LateMessageStats{IncrementalRescans: 1, CappedRescans: 1}, fd.LateMessageStats())
+
+		require.NoError(t, fd.handleLateMsg(latePrecommit(3), chainHead))
+		fd.rescanLateMessages()
+		require.Equal(t, LateMessageStats{IncrementalRescans: 1, CappedRescans: 2}, fd.LateMessageStats())
+	})
+}
@@ -1,12 +1,12 @@
 package accountability
 
 import (
-	"context"
 	"errors"
 	"time"
 
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/rlp"
 )
 
 const (
@@ -75,10 +75,34 @@ func (fd *FaultDetector) tryReport(ev *autonity.AccountabilityEvent) error {
 		}
 	}
 	fd.logger.Warn("Reporting faulty validator", "offender", ev.Offender, "rule", autonity.Rule(ev.Rule).String(), "block", ev.Block)
-	fd.eventReporterCh <- ev
+	if dropped := fd.queue.push(ev); dropped != nil {
+		fd.logger.Error("Accountability submission queue full, dropping event", "type", autonity.AccountabilityEventType(dropped.EventType),
+			"rule", autonity.Rule(dropped.Rule).String(), "offender", dropped.Offender)
+	}
 	return nil
 }
 
+// reportDeadline returns the last block at which the event can be included in the chain. An innocence proof
+// has to be included within the innocence proof submission window of the accusation, the other events
+// while the messages of the proof are still buffered by the fault detectors.
+func (fd *FaultDetector) reportDeadline(ev *autonity.AccountabilityEvent) uint64 {
+	head := fd.blockchain.CurrentHeader().Number.Uint64()
+	if ev.EventType == uint8(autonity.Innocence) {
+		accusation, accusationErr := fd.protocolContracts.GetValidatorAccusation(nil, ev.Offender)
+		config, configErr := fd.protocolContracts.Accountability.Config(nil)
+		if accusationErr == nil && configErr == nil {
+			return accusation.ReportingBlock.Uint64() + config.InnocenceProofSubmissionWindow.Uint64()
+		}
+		// assume a short deadline, so that the fee is bumped early
+		fd.logger.Error("Cannot retrieve the accusation deadline", "accusationErr", accusationErr, "configErr", configErr)
+		return head + uint64(offChainAccusationProofWindow)
+	}
+	if ev.Block == nil {
+		return head + HeightRange
+	}
+	return ev.Block.Uint64() + HeightRange
+}
+
 func (fd *FaultDetector) eventReporter() {
 	defer fd.wg.Done()
 	for ev := range fd.eventReporterCh {
@@ -87,6 +111,16 @@ func (fd *FaultDetector) eventReporter() {
 			fd.logger.Warn("Ignoring too large proof reporting", "chunks", chunks)
 			continue
 		}
+		deadline := fd.reportDeadline(ev)
+		proofHash := canonicalProofHash(ev.RawProof)
+		var audit *AuditSnapshot
+		proof, committee, err := fd.proofCommittee(ev.RawProof)
+		if err == nil {
+			snapshot := NewAuditSnapshot(proof.Message.H(), committee)
+			audit = &snapshot
+		} else {
+			fd.logger.Warn("Cannot snapshot the accountability proof inputs", "err", err)
+		}
 		for i := 0; i < chunks; i++ {
 			chunkedEvent := autonity.AccountabilityEvent{
 				Chunks:         uint8(chunks),
@@ -102,9 +136,18 @@ func (fd *FaultDetector) eventReporter() {
 				Offender:       ev.Offender,
 				RawProof:       ev.RawProof[i*ChunkProofSize : min((i+1)*ChunkProofSize, len(ev.RawProof))],
 			}
-			if tx, err := fd.protocolContracts.HandleEvent(fd.txOpts, chunkedEvent); err == nil {
-				fd.logger.Warn("Accountability transaction sent", "tx", tx.Hash(), "gas", tx.Gas(), "size", tx.Size())
-				// wait until it get mined before moving to the next one
+			s, tx, err := fd.submissions.submit(chunkedEvent, proofHash, deadline, audit)
+			if errors.Is(err, errDuplicateSubmission) {
+				fd.logger.Info("Accountability proof already pending submission", "rule", autonity.Rule(ev.Rule).String(), "offender", ev.Offender)
+				break
+			}
+			if err == nil {
+				if i == 0 && proof != nil {
+					fd.journal.record(ActionSubmitted, proof, committee)
+				}
+				fd.logger.Warn("Accountability transaction sent", "tx", tx.Hash(), "gas", tx.Gas(), "size", tx.Size(), "deadline", deadline)
+				// wait until it get mined before moving to the next one, the submission monitor replaces
+				// the transaction with a higher fee if it is still pending close to its deadline.
 				attempt := 0
 			GetTxLoop:
 				for ; attempt < MaxSubmissionAttempts; attempt++ {
@@ -113,13 +156,15 @@ func (fd *FaultDetector) eventReporter() {
 						return
 					default:
 						time.Sleep(SubmissionDelay)
-						_, _, blockNumber, _, _ := fd.ethBackend.GetTransaction(context.Background(), tx.Hash())
-						if blockNumber != 0 {
+						if fd.submissions.state(s) != submissionPending {
 							break GetTxLoop
 						}
 					}
 				}
-				if attempt == MaxSubmissionAttempts {
+				if state := fd.submissions.state(s); state == submissionFailed {
+					fd.logger.Error("Accountability transaction didn't get mined before its deadline, cancelling")
+					break
+				} else if state == submissionPending {
 					fd.logger.Error("Accountability transaction didn't get mined, cancelling")
 					break
 				}
@@ -129,3 +174,16 @@ func (fd *FaultDetector) eventReporter() {
 		}
 	}
 }
+
+// canonicalProofHash returns the canonical hash of the RLP-encoded proof, or the zero hash if it cannot be decoded.
+func canonicalProofHash(rawProof []byte) common.Hash {
+	proof := new(Proof)
+	if err := rlp.DecodeBytes(rawProof, proof); err != nil {
+		return common.Hash{}
+	}
+	hash, err := proof.CanonicalHash()
+	if err != nil {
+		return common.Hash{}
+	}
+	return hash
+}
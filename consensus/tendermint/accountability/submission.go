@@ -0,0 +1,375 @@
+package accountability
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/accounts/abi/bind"
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	engineCore "github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rlp"
+	"github.com/autonity/autonity/rpc"
+)
+
+// submissionsKey is the database key of the pending accountability transactions.
+var submissionsKey = []byte("AccountabilitySubmissions")
+
+var errDuplicateSubmission = errors.New("proof already pending submission")
+
+// Config holds the fee management settings and the submission cap of the accountability transactions,
+// the buffering mode and capacity of the consensus messages, the policy of the late consensus messages,
+// and the rate limit of the off-chain accountability messages.
+type Config struct {
+	// DeadlineMargin is the number of blocks before its deadline from which a pending
+	// accountability transaction is replaced with a higher fee at each block.
+	DeadlineMargin uint64
+	// MaxFeeCap is the fee cap per gas that the replacement transactions never exceed.
+	MaxFeeCap *big.Int `toml:",omitempty"`
+	// MaxSubmissionsPerHeight is the number of accountability events submitted at each height, the
+	// events beyond being submitted at the following heights. 0 for no cap.
+	MaxSubmissionsPerHeight uint64
+	// MaxSubmissionsPerEpoch is the number of accountability events submitted in each epoch. 0 for no cap.
+	MaxSubmissionsPerEpoch uint64
+	// MsgStoreMode is the way the consensus messages are buffered, one of MsgStoreAuto,
+	// MsgStoreFull and MsgStoreDigest.
+	MsgStoreMode string `toml:",omitempty"`
+	// MsgStoreCapacity is the number of consensus messages, or digests, buffered, the oldest heights
+	// being evicted beyond. 0 for no limit.
+	MsgStoreCapacity uint64
+	// PersistMsgStore persists the full consensus messages to the database, for the node to raise or defend
+	// against accusations about the heights seen before a restart.
+	PersistMsgStore bool
+	// LateMessageWindow is the number of heights behind the last height scanned by the rules for which
+	// a late consensus message is re-evaluated, up to HeightRange. The older messages are dropped.
+	LateMessageWindow uint64
+	// MaxRescansPerHeight is the number of re-evaluations of a height triggered by late messages. 0 to
+	// never re-evaluate a height.
+	MaxRescansPerHeight uint64
+	// MaxOffChainMessages is the number of off-chain accountability messages accepted from a peer over
+	// OffChainMessageWindow, the messages beyond being dropped.
+	MaxOffChainMessages   uint64
+	OffChainMessageWindow time.Duration
+	// MaxOffChainViolations is the number of consecutive messages over the rate limit after which the
+	// peer is disconnected.
+	MaxOffChainViolations uint64
+}
+
+// Buffering modes of the consensus messages.
+const (
+	MsgStoreAuto   = ""       // digest mode while the local node is not a registered validator
+	MsgStoreFull   = "full"   // full messages, for the proofs to be built
+	MsgStoreDigest = "digest" // digests of the messages only, no proof is built
+)
+
+// DefaultConfig contains the default fee management settings of the accountability transactions.
+var DefaultConfig = Config{
+	DeadlineMargin:          20,
+	MaxFeeCap:               big.NewInt(1000 * params.GWei),
+	MaxSubmissionsPerHeight: 2,
+	MaxSubmissionsPerEpoch:  32,
+	MsgStoreCapacity:        engineCore.DefaultMsgStoreCapacity,
+	LateMessageWindow:       HeightRange,
+	MaxRescansPerHeight:     16,
+	MaxOffChainMessages:     10,
+	OffChainMessageWindow:   time.Minute,
+	MaxOffChainViolations:   3,
+}
+
+type submissionState uint8
+
+const (
+	submissionPending submissionState = iota
+	submissionIncluded
+	submissionFailed
+)
+
+// submission is an accountability transaction which has to be included before its deadline. All the
+// transactions sent for a submission share the same nonce, so that at most one of them can be included.
+type submission struct {
+	Event     autonity.AccountabilityEvent
+	Deadline  uint64 // last block at which the transaction can be included
+	Nonce     uint64
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	Hashes    []common.Hash  // transactions sent for the submission, the last one being the current one
+	ProofHash common.Hash    `rlp:"optional"` // canonical hash of the proof the event is a chunk of
+	Audit     *AuditSnapshot `rlp:"optional"` // inputs of the verdict of the proof, nil if unknown
+
+	state submissionState
+}
+
+// PendingSubmission is an accountability event pending inclusion.
+type PendingSubmission struct {
+	Event     autonity.AccountabilityEvent
+	Deadline  uint64        // last block at which the transaction can be included
+	Hashes    []common.Hash // transactions sent for the submission, the last one being the current one
+	ProofHash common.Hash
+	Audit     *AuditSnapshot // nil for the submissions recorded before the audit snapshots
+}
+
+// submissionBackend is the view of the chain and of the transaction pool used to track the submissions.
+type submissionBackend interface {
+	// Included reports whether the transaction is included in the canonical chain.
+	Included(hash common.Hash) bool
+	// Nonce returns the nonce of the account at the head of the chain.
+	Nonce(address common.Address) (uint64, error)
+	// Send sends the accountability event in a transaction.
+	Send(opts *bind.TransactOpts, ev autonity.AccountabilityEvent) (*types.Transaction, error)
+}
+
+// submissionMonitor tracks the accountability transactions until their inclusion. As the deadline of a pending
+// transaction approaches, it is replaced at each block with a higher fee, up to the configured fee cap, so that
+// a base fee spike does not get the transaction stuck past its deadline. The pending transactions are persisted
+// to be tracked across restarts.
+type submissionMonitor struct {
+	backend submissionBackend
+	db      ethdb.KeyValueStore
+	txOpts  *bind.TransactOpts
+	logger  log.Logger
+
+	mu      sync.Mutex
+	config  Config
+	pending []*submission
+}
+
+func newSubmissionMonitor(backend submissionBackend, db ethdb.KeyValueStore, txOpts *bind.TransactOpts, logger log.Logger) *submissionMonitor {
+	m := &submissionMonitor{
+		backend: backend,
+		db:      db,
+		txOpts:  txOpts,
+		logger:  logger,
+		config:  DefaultConfig,
+	}
+	if blob, err := db.Get(submissionsKey); err == nil {
+		if err := rlp.DecodeBytes(blob, &m.pending); err != nil {
+			logger.Error("Dropping invalid pending accountability transactions", "err", err)
+			m.pending = nil
+		}
+	}
+	return m
+}
+
+func (m *submissionMonitor) setConfig(config Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = config
+}
+
+// submit sends the accountability event and tracks its transaction until its inclusion. The chunk of a proof
+// already pending is not sent again, whatever the order of the proof evidences.
+func (m *submissionMonitor) submit(ev autonity.AccountabilityEvent, proofHash common.Hash, deadline uint64, audit *AuditSnapshot) (*submission, *types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if proofHash != (common.Hash{}) {
+		for _, s := range m.pending {
+			if s.ProofHash == proofHash && s.Event.ChunkId == ev.ChunkId {
+				return nil, nil, errDuplicateSubmission
+			}
+		}
+	}
+	tx, err := m.backend.Send(m.txOpts, ev)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &submission{
+		Event:     ev,
+		Deadline:  deadline,
+		Nonce:     tx.Nonce(),
+		GasTipCap: tx.GasTipCap(),
+		GasFeeCap: tx.GasFeeCap(),
+		Hashes:    []common.Hash{tx.Hash()},
+		ProofHash: proofHash,
+		Audit:     audit,
+	}
+	m.pending = append(m.pending, s)
+	m.store()
+	return s, tx, nil
+}
+
+// resume sends again the transactions of the submissions still pending after a restart,
+// as they might have been dropped from the transaction pool.
+func (m *submissionMonitor) resume(currentHeader func() *types.Header) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pending) == 0 {
+		return
+	}
+	m.resolve(currentHeader())
+	for _, s := range m.pending {
+		if _, err := m.backend.Send(m.replacementOpts(s.Nonce, s.GasTipCap, s.GasFeeCap), s.Event); err != nil {
+			m.logger.Debug("Accountability transaction not resent", "nonce", s.Nonce, "err", err)
+		}
+	}
+	m.store()
+}
+
+// check is called at each block to resolve the included submissions and to replace the pending
+// transactions whose deadline approaches with a higher fee.
+func (m *submissionMonitor) check(head *types.Header) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pending) == 0 {
+		return
+	}
+	m.resolve(head)
+	for _, s := range m.pending {
+		if head.Number.Uint64()+m.config.DeadlineMargin >= s.Deadline {
+			m.replace(s, head)
+		}
+	}
+	m.store()
+}
+
+// resolve drops the submissions that got included or that cannot be included anymore.
+func (m *submissionMonitor) resolve(head *types.Header) {
+	nonce, err := m.backend.Nonce(m.txOpts.From)
+	if err != nil {
+		m.logger.Error("Cannot retrieve the accountability transactor nonce", "err", err)
+		return
+	}
+	pending := m.pending[:0]
+	for _, s := range m.pending {
+		if hash, ok := m.included(s); ok {
+			m.logger.Info("Accountability transaction included", "tx", hash, "replacements", len(s.Hashes)-1)
+			s.state = submissionIncluded
+			continue
+		}
+		switch {
+		case s.Nonce < nonce:
+			// the nonce got used by a transaction which is not ours, the event has to be reported again
+			m.logger.Error("Accountability transaction nonce used by another transaction", "nonce", s.Nonce)
+			s.state = submissionFailed
+		case head.Number.Uint64() >= s.Deadline:
+			m.logger.Error("Accountability transaction missed its deadline", "tx", s.Hashes[len(s.Hashes)-1], "deadline", s.Deadline)
+			s.state = submissionFailed
+		default:
+			pending = append(pending, s)
+		}
+	}
+	m.pending = pending
+}
+
+// included returns the transaction of the submission included in the chain, if any.
+func (m *submissionMonitor) included(s *submission) (common.Hash, bool) {
+	for _, hash := range s.Hashes {
+		if m.backend.Included(hash) {
+			return hash, true
+		}
+	}
+	return common.Hash{}, false
+}
+
+// replace sends the transaction of the submission again, with the fees bumped enough for the transaction
+// pool to accept the replacement and for the transaction to be includable at the current base fee.
+func (m *submissionMonitor) replace(s *submission, head *types.Header) {
+	tip, minFeeCap := bumpPrice(s.GasTipCap), bumpPrice(s.GasFeeCap)
+	feeCap := minFeeCap
+	if head.BaseFee != nil {
+		if includable := new(big.Int).Add(new(big.Int).Mul(head.BaseFee, common.Big2), tip); feeCap.Cmp(includable) < 0 {
+			feeCap = includable
+		}
+	}
+	if maxFeeCap := m.config.MaxFeeCap; maxFeeCap != nil && feeCap.Cmp(maxFeeCap) > 0 {
+		m.logger.Error("!!! Accountability transaction fee cap reached, the transaction may miss its deadline !!!",
+			"tx", s.Hashes[len(s.Hashes)-1], "deadline", s.Deadline, "head", head.Number, "baseFee", head.BaseFee,
+			"feeCap", feeCap, "maxFeeCap", maxFeeCap)
+		if maxFeeCap.Cmp(minFeeCap) < 0 || maxFeeCap.Cmp(tip) < 0 {
+			// the transaction pool would not accept the replacement
+			return
+		}
+		feeCap = new(big.Int).Set(maxFeeCap)
+	}
+
+	tx, err := m.backend.Send(m.replacementOpts(s.Nonce, tip, feeCap), s.Event)
+	if err != nil {
+		m.logger.Error("Cannot replace accountability transaction", "nonce", s.Nonce, "err", err)
+		return
+	}
+	m.logger.Warn("Accountability transaction replaced", "old", s.Hashes[len(s.Hashes)-1], "new", tx.Hash(),
+		"deadline", s.Deadline, "head", head.Number, "tip", tx.GasTipCap(), "feeCap", tx.GasFeeCap())
+	s.GasTipCap, s.GasFeeCap = tx.GasTipCap(), tx.GasFeeCap()
+	s.Hashes = append(s.Hashes, tx.Hash())
+}
+
+// bumpPrice returns the price increased enough for the transaction pool to accept a replacement.
+func bumpPrice(price *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(price, big.NewInt(int64(100+core.DefaultTxPoolConfig.PriceBump)))
+	return bumped.Div(bumped, big.NewInt(100)).Add(bumped, common.Big1)
+}
+
+func (m *submissionMonitor) replacementOpts(nonce uint64, tip, feeCap *big.Int) *bind.TransactOpts {
+	opts := *m.txOpts
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.GasTipCap = tip
+	opts.GasFeeCap = feeCap
+	return &opts
+}
+
+// pendingSubmissions returns a copy of the pending submissions.
+func (m *submissionMonitor) pendingSubmissions() []PendingSubmission {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := make([]PendingSubmission, 0, len(m.pending))
+	for _, s := range m.pending {
+		pending = append(pending, PendingSubmission{
+			Event:     s.Event,
+			Deadline:  s.Deadline,
+			Hashes:    append([]common.Hash{}, s.Hashes...),
+			ProofHash: s.ProofHash,
+			Audit:     s.Audit,
+		})
+	}
+	return pending
+}
+
+// state returns the state of the submission.
+func (m *submissionMonitor) state(s *submission) submissionState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return s.state
+}
+
+func (m *submissionMonitor) store() {
+	blob, err := rlp.EncodeToBytes(m.pending)
+	if err != nil {
+		m.logger.Error("Cannot encode pending accountability transactions", "err", err)
+		return
+	}
+	if err := m.db.Put(submissionsKey, blob); err != nil {
+		m.logger.Error("Cannot store pending accountability transactions", "err", err)
+	}
+}
+
+// apiSubmissionBackend tracks the accountability transactions through the node API backend.
+type apiSubmissionBackend struct {
+	fd *FaultDetector
+}
+
+func (b *apiSubmissionBackend) Included(hash common.Hash) bool {
+	_, _, blockNumber, _, _ := b.fd.ethBackend.GetTransaction(context.Background(), hash)
+	return blockNumber != 0
+}
+
+func (b *apiSubmissionBackend) Nonce(address common.Address) (uint64, error) {
+	state, _, err := b.fd.ethBackend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	return state.GetNonce(address), nil
+}
+
+func (b *apiSubmissionBackend) Send(opts *bind.TransactOpts, ev autonity.AccountabilityEvent) (*types.Transaction, error) {
+	return b.fd.protocolContracts.HandleEvent(opts, ev)
+}
@@ -0,0 +1,115 @@
+package accountability
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/metrics"
+)
+
+// maxQueuedSubmissions bounds the accountability events waiting for the submission cap to be released.
+const maxQueuedSubmissions = 128
+
+var (
+	submissionsQueuedGauge     = metrics.NewRegisteredGauge("accountability/submissions/queued", nil)
+	submissionsReleasedMeter   = metrics.NewRegisteredMeter("accountability/submissions/released", nil)
+	submissionsDroppedMeter    = metrics.NewRegisteredMeter("accountability/submissions/dropped", nil)
+	submissionsCapReachedMeter = metrics.NewRegisteredMeter("accountability/submissions/capped", nil)
+)
+
+// submissionQueue caps the accountability events the local node submits on-chain, per height and per
+// epoch, so that a bug producing false positives en masse neither burns the node funds in fees nor
+// spams the chain. The events beyond the cap are queued and released at the following heights by
+// severity, the misbehaviours before the accusations, the oldest first. A cap of 0 disables it.
+//
+// The innocence proofs are not queued: they defend the local node, and have a deadline.
+type submissionQueue struct {
+	mu        sync.Mutex
+	perHeight uint64
+	perEpoch  uint64
+	events    []*autonity.AccountabilityEvent
+
+	height   uint64 // last height events were released at
+	released uint64 // events released at height
+	epoch    uint64 // last epoch events were released in
+	inEpoch  uint64 // events released in epoch
+}
+
+func newSubmissionQueue(perHeight, perEpoch uint64) *submissionQueue {
+	return &submissionQueue{perHeight: perHeight, perEpoch: perEpoch}
+}
+
+// setCap sets the number of events released per height and per epoch, 0 for no cap.
+func (q *submissionQueue) setCap(perHeight, perEpoch uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.perHeight, q.perEpoch = perHeight, perEpoch
+}
+
+// limits returns the number of events released per height and per epoch.
+func (q *submissionQueue) limits() (uint64, uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.perHeight, q.perEpoch
+}
+
+// push queues the event for release. The least severe event is dropped if the queue is full, and
+// returned.
+func (q *submissionQueue) push(ev *autonity.AccountabilityEvent) *autonity.AccountabilityEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.events = append(q.events, ev)
+	sort.SliceStable(q.events, func(i, j int) bool {
+		return q.events[i].EventType < q.events[j].EventType
+	})
+	var dropped *autonity.AccountabilityEvent
+	if len(q.events) > maxQueuedSubmissions {
+		dropped = q.events[len(q.events)-1]
+		q.events = q.events[:len(q.events)-1]
+		submissionsDroppedMeter.Mark(1)
+	}
+	submissionsQueuedGauge.Update(int64(len(q.events)))
+	return dropped
+}
+
+// release returns the queued events which can be submitted at the given height and epoch.
+func (q *submissionQueue) release(height, epoch uint64) []*autonity.AccountabilityEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if height != q.height {
+		q.height, q.released = height, 0
+	}
+	if epoch != q.epoch {
+		q.epoch, q.inEpoch = epoch, 0
+	}
+	n := uint64(len(q.events))
+	if q.perHeight != 0 {
+		n = min(n, q.perHeight-min(q.released, q.perHeight))
+	}
+	if q.perEpoch != 0 {
+		n = min(n, q.perEpoch-min(q.inEpoch, q.perEpoch))
+	}
+	if n < uint64(len(q.events)) {
+		submissionsCapReachedMeter.Mark(1)
+	}
+	if n == 0 {
+		return nil
+	}
+	events := q.events[:n:n]
+	q.events = q.events[n:]
+	q.released += n
+	q.inEpoch += n
+	submissionsReleasedMeter.Mark(int64(n))
+	submissionsQueuedGauge.Update(int64(len(q.events)))
+	return events
+}
+
+// size returns the number of events queued.
+func (q *submissionQueue) size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
@@ -0,0 +1,75 @@
+package accountability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+)
+
+func TestSubmissionQueue(t *testing.T) {
+	event := func(eventType autonity.AccountabilityEventType, offender byte) *autonity.AccountabilityEvent {
+		return &autonity.AccountabilityEvent{EventType: uint8(eventType), Offender: common.Address{offender}}
+	}
+	offenders := func(events []*autonity.AccountabilityEvent) []byte {
+		var result []byte
+		for _, ev := range events {
+			result = append(result, ev.Offender[0])
+		}
+		return result
+	}
+
+	t.Run("misbehaviours are released first, up to the cap per height", func(t *testing.T) {
+		q := newSubmissionQueue(2, 0)
+		for i := byte(1); i <= 30; i++ {
+			q.push(event(autonity.Accusation, i))
+		}
+		q.push(event(autonity.Misbehaviour, 100))
+		q.push(event(autonity.Misbehaviour, 101))
+
+		require.Equal(t, []byte{100, 101}, offenders(q.release(10, 0)))
+		// the cap of the height is reached
+		require.Empty(t, q.release(10, 0))
+		require.Equal(t, []byte{1, 2}, offenders(q.release(11, 0)))
+		require.Equal(t, 28, q.size())
+	})
+
+	t.Run("cap per epoch", func(t *testing.T) {
+		q := newSubmissionQueue(2, 3)
+		for i := byte(1); i <= 5; i++ {
+			q.push(event(autonity.Accusation, i))
+		}
+		require.Len(t, q.release(10, 1), 2)
+		require.Len(t, q.release(11, 1), 1)
+		require.Empty(t, q.release(12, 1))
+		require.Equal(t, []byte{4, 5}, offenders(q.release(13, 2)))
+	})
+
+	t.Run("override raises the cap", func(t *testing.T) {
+		q := newSubmissionQueue(1, 0)
+		for i := byte(1); i <= 5; i++ {
+			q.push(event(autonity.Accusation, i))
+		}
+		require.Len(t, q.release(10, 0), 1)
+		q.setCap(10, 0)
+		require.Equal(t, []byte{2, 3, 4, 5}, offenders(q.release(10, 0)))
+		q.setCap(0, 0)
+		for i := byte(1); i <= 20; i++ {
+			q.push(event(autonity.Accusation, i))
+		}
+		require.Len(t, q.release(11, 0), 20)
+	})
+
+	t.Run("the least severe events are dropped when the queue is full", func(t *testing.T) {
+		q := newSubmissionQueue(1, 0)
+		for i := 0; i < maxQueuedSubmissions; i++ {
+			require.Nil(t, q.push(event(autonity.Accusation, 1)))
+		}
+		dropped := q.push(event(autonity.Misbehaviour, 2))
+		require.Equal(t, event(autonity.Accusation, 1), dropped)
+		require.Equal(t, maxQueuedSubmissions, q.size())
+		require.Equal(t, []byte{2}, offenders(q.release(10, 0)))
+	})
+}
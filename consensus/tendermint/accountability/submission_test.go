@@ -0,0 +1,238 @@
+package accountability
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/accounts/abi/bind"
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+)
+
+// simulatedSubmissionChain is a chain with a transaction pool including the transactions of a single
+// account whose fee cap covers the base fee.
+type simulatedSubmissionChain struct {
+	head     *types.Header
+	nonce    uint64                        // nonce of the account at head
+	pool     map[uint64]*types.Transaction // pending transactions by nonce
+	included map[common.Hash]bool
+	events   map[uint8]int // number of included transactions by chunk id
+	sent     int
+}
+
+func newSimulatedSubmissionChain(baseFee *big.Int) *simulatedSubmissionChain {
+	return &simulatedSubmissionChain{
+		head:     &types.Header{Number: common.Big1, BaseFee: baseFee},
+		pool:     make(map[uint64]*types.Transaction),
+		included: make(map[common.Hash]bool),
+		events:   make(map[uint8]int),
+	}
+}
+
+func (c *simulatedSubmissionChain) CurrentHeader() *types.Header {
+	return c.head
+}
+
+func (c *simulatedSubmissionChain) Included(hash common.Hash) bool {
+	return c.included[hash]
+}
+
+func (c *simulatedSubmissionChain) Nonce(common.Address) (uint64, error) {
+	return c.nonce, nil
+}
+
+func (c *simulatedSubmissionChain) Send(opts *bind.TransactOpts, ev autonity.AccountabilityEvent) (*types.Transaction, error) {
+	nonce := c.nonce + uint64(len(c.pool))
+	if opts.Nonce != nil {
+		nonce = opts.Nonce.Uint64()
+	}
+	if nonce < c.nonce {
+		return nil, errors.New("nonce too low")
+	}
+	tip, feeCap := opts.GasTipCap, opts.GasFeeCap
+	if feeCap == nil {
+		// default fees of the contract bindings
+		feeCap = new(big.Int).Add(tip, new(big.Int).Mul(c.head.BaseFee, common.Big2))
+	}
+	if old, ok := c.pool[nonce]; ok && (bumpPrice(old.GasFeeCap()).Cmp(new(big.Int).Add(feeCap, common.Big1)) > 0 ||
+		bumpPrice(old.GasTipCap()).Cmp(new(big.Int).Add(tip, common.Big1)) > 0) {
+		return nil, errors.New("replacement transaction underpriced")
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: nonce, GasTipCap: tip, GasFeeCap: feeCap, Data: []byte{ev.ChunkId}})
+	c.pool[nonce] = tx
+	c.sent++
+	return tx, nil
+}
+
+// mine mines a block with the given base fee.
+func (c *simulatedSubmissionChain) mine(baseFee *big.Int) *types.Header {
+	c.head = &types.Header{Number: new(big.Int).Add(c.head.Number, common.Big1), BaseFee: baseFee}
+	for tx, ok := c.pool[c.nonce]; ok && tx.GasFeeCap().Cmp(baseFee) >= 0; tx, ok = c.pool[c.nonce] {
+		c.included[tx.Hash()] = true
+		c.events[tx.Data()[0]]++
+		delete(c.pool, c.nonce)
+		c.nonce++
+	}
+	return c.head
+}
+
+func newTestSubmissionMonitor(chain *simulatedSubmissionChain, db ethdb.KeyValueStore) *submissionMonitor {
+	opts := &bind.TransactOpts{From: common.Address{1}, GasTipCap: common.Big1}
+	if db == nil {
+		db = rawdb.NewMemoryDatabase()
+	}
+	return newSubmissionMonitor(chain, db, opts, log.Root())
+}
+
+func TestSubmissionMonitor(t *testing.T) {
+	var (
+		baseFee  = big.NewInt(params.GWei)
+		spike    = big.NewInt(10 * params.GWei)
+		deadline = uint64(30)
+		margin   = uint64(10)
+	)
+
+	t.Run("base fee spike, the transaction is replaced before its deadline", func(t *testing.T) {
+		chain := newSimulatedSubmissionChain(baseFee)
+		monitor := newTestSubmissionMonitor(chain, nil)
+		monitor.setConfig(Config{DeadlineMargin: margin, MaxFeeCap: big.NewInt(100 * params.GWei)})
+
+		s, _, err := monitor.submit(autonity.AccountabilityEvent{ChunkId: 0}, common.Hash{}, deadline, nil)
+		require.NoError(t, err)
+
+		// the base fee spikes before the transaction is included
+		for head := chain.mine(spike); head.Number.Uint64()+margin < deadline; head = chain.mine(spike) {
+			monitor.check(head)
+			require.Equal(t, 1, chain.sent, "replaced before the deadline margin")
+			require.Equal(t, submissionPending, monitor.state(s))
+		}
+		monitor.check(chain.head)
+		require.Equal(t, 2, chain.sent)
+		require.Len(t, s.Hashes, 2)
+
+		// the replacement is included in the next block, and not replaced again
+		for i := 0; i < 5; i++ {
+			monitor.check(chain.mine(spike))
+		}
+		require.Equal(t, submissionIncluded, monitor.state(s))
+		require.Equal(t, 2, chain.sent)
+		require.Equal(t, 1, chain.events[0])
+		require.Less(t, chain.head.Number.Uint64(), deadline)
+	})
+
+	t.Run("the fee cap is never exceeded", func(t *testing.T) {
+		chain := newSimulatedSubmissionChain(baseFee)
+		monitor := newTestSubmissionMonitor(chain, nil)
+		maxFeeCap := big.NewInt(5 * params.GWei)
+		monitor.setConfig(Config{DeadlineMargin: margin, MaxFeeCap: maxFeeCap})
+
+		s, _, err := monitor.submit(autonity.AccountabilityEvent{ChunkId: 0}, common.Hash{}, deadline, nil)
+		require.NoError(t, err)
+		for chain.head.Number.Uint64() < deadline {
+			monitor.check(chain.mine(spike))
+			for _, tx := range chain.pool {
+				require.LessOrEqual(t, tx.GasFeeCap().Cmp(maxFeeCap), 0)
+			}
+		}
+		require.Equal(t, submissionFailed, monitor.state(s))
+		require.Zero(t, chain.events[0])
+	})
+
+	t.Run("an included transaction is never replaced", func(t *testing.T) {
+		chain := newSimulatedSubmissionChain(baseFee)
+		monitor := newTestSubmissionMonitor(chain, nil)
+		monitor.setConfig(Config{DeadlineMargin: deadline, MaxFeeCap: big.NewInt(100 * params.GWei)})
+
+		s, _, err := monitor.submit(autonity.AccountabilityEvent{ChunkId: 0}, common.Hash{}, deadline, nil)
+		require.NoError(t, err)
+		monitor.check(chain.mine(baseFee))
+		monitor.check(chain.mine(spike))
+		require.Equal(t, submissionIncluded, monitor.state(s))
+		require.Equal(t, 1, chain.sent)
+		require.Equal(t, 1, chain.events[0])
+	})
+
+	t.Run("chunks are included exactly once", func(t *testing.T) {
+		chain := newSimulatedSubmissionChain(baseFee)
+		monitor := newTestSubmissionMonitor(chain, nil)
+		monitor.setConfig(Config{DeadlineMargin: margin, MaxFeeCap: big.NewInt(100 * params.GWei)})
+
+		var submissions []*submission
+		for i := uint8(0); i < 3; i++ {
+			s, _, err := monitor.submit(autonity.AccountabilityEvent{ChunkId: i}, common.Hash{}, deadline, nil)
+			require.NoError(t, err)
+			submissions = append(submissions, s)
+		}
+		for chain.head.Number.Uint64() < deadline {
+			monitor.check(chain.mine(spike))
+		}
+		for i, s := range submissions {
+			require.Equal(t, submissionIncluded, monitor.state(s))
+			require.Equal(t, 1, chain.events[uint8(i)])
+		}
+	})
+
+	t.Run("the chunks of a proof already pending are not sent again", func(t *testing.T) {
+		chain := newSimulatedSubmissionChain(baseFee)
+		monitor := newTestSubmissionMonitor(chain, nil)
+		proofHash := common.Hash{0x1}
+
+		_, _, err := monitor.submit(autonity.AccountabilityEvent{ChunkId: 0}, proofHash, deadline, nil)
+		require.NoError(t, err)
+		_, _, err = monitor.submit(autonity.AccountabilityEvent{ChunkId: 0}, proofHash, deadline, nil)
+		require.ErrorIs(t, err, errDuplicateSubmission)
+		_, _, err = monitor.submit(autonity.AccountabilityEvent{ChunkId: 1}, proofHash, deadline, nil)
+		require.NoError(t, err)
+		_, _, err = monitor.submit(autonity.AccountabilityEvent{ChunkId: 0}, common.Hash{0x2}, deadline, nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, chain.sent)
+
+		// the proof hash is kept across restarts
+		restarted := newTestSubmissionMonitor(chain, monitor.db)
+		_, _, err = restarted.submit(autonity.AccountabilityEvent{ChunkId: 1}, proofHash, deadline, nil)
+		require.ErrorIs(t, err, errDuplicateSubmission)
+	})
+
+	t.Run("pending transactions are tracked across restarts", func(t *testing.T) {
+		chain := newSimulatedSubmissionChain(baseFee)
+		db := rawdb.NewMemoryDatabase()
+		monitor := newTestSubmissionMonitor(chain, db)
+		monitor.setConfig(Config{DeadlineMargin: margin, MaxFeeCap: big.NewInt(100 * params.GWei)})
+		_, _, err := monitor.submit(autonity.AccountabilityEvent{ChunkId: 0}, common.Hash{}, deadline, nil)
+		require.NoError(t, err)
+		_, _, err = monitor.submit(autonity.AccountabilityEvent{ChunkId: 1}, common.Hash{}, deadline, nil)
+		require.NoError(t, err)
+
+		// the second transaction is dropped from the pool while the node is down, the first one lands
+		delete(chain.pool, 1)
+		chain.mine(baseFee)
+
+		restarted := newTestSubmissionMonitor(chain, db)
+		restarted.setConfig(Config{DeadlineMargin: margin, MaxFeeCap: big.NewInt(100 * params.GWei)})
+		require.Len(t, restarted.pending, 2)
+		restarted.resume(chain.CurrentHeader)
+		require.Len(t, restarted.pending, 1)
+		require.Equal(t, uint64(1), restarted.pending[0].Nonce)
+		// the dropped transaction is resent, the included one is not
+		require.Len(t, chain.pool, 1)
+		require.Equal(t, uint64(1), chain.pool[1].Nonce())
+
+		for chain.head.Number.Uint64() < deadline {
+			restarted.check(chain.mine(spike))
+		}
+		require.Empty(t, restarted.pending)
+		require.Equal(t, 1, chain.events[0])
+		require.Equal(t, 1, chain.events[1])
+
+		// the resolved transactions are not tracked anymore after a restart
+		require.Empty(t, newTestSubmissionMonitor(chain, db).pending)
+	})
+}
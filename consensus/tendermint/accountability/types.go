@@ -1,12 +1,17 @@
 package accountability
 
 import (
+	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/rlp"
 )
 
@@ -102,5 +107,53 @@ func (p *Proof) DecodeRLP(stream *rlp.Stream) error {
 	for i := range encoded.Evidences {
 		p.Evidences[i] = encoded.Evidences[i].Msg
 	}
+	// the proofs built before the canonical ordering was introduced are normalised, so that they
+	// are verified and deduplicated as the same proofs in canonical order.
+	SortEvidences(p.Evidences)
 	return nil
 }
+
+// CanonicalHash returns the hash of the proof encoded with its evidences in canonical order. The
+// proofs carrying the same evidences in different orders have the same canonical hash.
+func (p *Proof) CanonicalHash() (common.Hash, error) {
+	canonical := *p
+	canonical.Evidences = slices.Clone(p.Evidences)
+	SortEvidences(canonical.Evidences)
+	encoded, err := rlp.EncodeToBytes(&canonical)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Hash(encoded), nil
+}
+
+// SortEvidences sorts the evidences in canonical order. A leading light proposal stays first, as the
+// rules proven with a proposal expect it, and the other messages are sorted by height, round, code
+// and then hash, the aggregated votes having no single sender.
+func SortEvidences(evidences []message.Msg) {
+	if len(evidences) > 0 && evidences[0].Code() == message.LightProposalCode {
+		evidences = evidences[1:]
+	}
+	slices.SortStableFunc(evidences, compareEvidences)
+}
+
+// IsCanonicalOrder reports whether the evidences are in canonical order.
+func IsCanonicalOrder(evidences []message.Msg) bool {
+	if len(evidences) > 0 && evidences[0].Code() == message.LightProposalCode {
+		evidences = evidences[1:]
+	}
+	return slices.IsSortedFunc(evidences, compareEvidences)
+}
+
+func compareEvidences(a, b message.Msg) int {
+	if c := cmp.Compare(a.H(), b.H()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.R(), b.R()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Code(), b.Code()); c != 0 {
+		return c
+	}
+	ha, hb := a.Hash(), b.Hash()
+	return bytes.Compare(ha[:], hb[:])
+}
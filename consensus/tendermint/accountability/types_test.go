@@ -0,0 +1,85 @@
+package accountability
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/rlp"
+)
+
+func TestProofCanonicalOrder(t *testing.T) {
+	proposal := newValidatedLightProposal(height, newRound, validRound, signer, committee, nil, proposerIdx)
+	var precommits []message.Msg
+	for r := validRound + 1; r < newRound; r++ {
+		precommits = append(precommits,
+			newValidatedPrecommit(r, height, noneNilValue, signer, self, cSize),
+			newValidatedPrecommit(r, height, nilValue, makeSigner(keys[1]), &committee[1], cSize))
+	}
+	votes := append([]message.Msg{aggPrevote, aggPrecommit, aggNilPrevote}, precommits...)
+
+	cases := map[string]*Proof{
+		"leading proposal": {
+			Type:          autonity.Misbehaviour,
+			Rule:          autonity.PVO12,
+			Message:       prevoteForOldProposal1,
+			OffenderIndex: proposerIdx,
+			Evidences:     append([]message.Msg{proposal}, precommits...),
+		},
+		"votes only": {
+			Type:          autonity.Misbehaviour,
+			Rule:          autonity.C,
+			Message:       precommit1,
+			OffenderIndex: proposerIdx,
+			Evidences:     votes,
+		},
+	}
+	for name, proof := range cases {
+		t.Run(name, func(t *testing.T) {
+			canonical := &Proof{Type: proof.Type, Rule: proof.Rule, Message: proof.Message, OffenderIndex: proof.OffenderIndex,
+				Evidences: slices.Clone(proof.Evidences)}
+			SortEvidences(canonical.Evidences)
+			require.True(t, IsCanonicalOrder(canonical.Evidences))
+			require.Equal(t, proof.Evidences[0].Code() == message.LightProposalCode, canonical.Evidences[0] == proposal)
+			hash, err := canonical.CanonicalHash()
+			require.NoError(t, err)
+			raw, err := rlp.EncodeToBytes(canonical)
+			require.NoError(t, err)
+			require.True(t, rawProofInCanonicalOrder(raw))
+
+			for i := 0; i < 20; i++ {
+				shuffled := *proof
+				shuffled.Evidences = slices.Clone(proof.Evidences)
+				start := 0
+				if shuffled.Evidences[0].Code() == message.LightProposalCode {
+					start = 1
+				}
+				rand.Shuffle(len(shuffled.Evidences)-start, func(a, b int) {
+					shuffled.Evidences[start+a], shuffled.Evidences[start+b] = shuffled.Evidences[start+b], shuffled.Evidences[start+a]
+				})
+
+				shuffledHash, err := shuffled.CanonicalHash()
+				require.NoError(t, err)
+				require.Equal(t, hash, shuffledHash)
+
+				// the decoded proof is normalised
+				raw, err := rlp.EncodeToBytes(&shuffled)
+				require.NoError(t, err)
+				require.Equal(t, IsCanonicalOrder(shuffled.Evidences), rawProofInCanonicalOrder(raw))
+				decoded, err := decodeRawProof(raw)
+				require.NoError(t, err)
+				require.True(t, IsCanonicalOrder(decoded.Evidences))
+				decodedHash, err := decoded.CanonicalHash()
+				require.NoError(t, err)
+				require.Equal(t, hash, decodedHash)
+				for j := range decoded.Evidences {
+					require.Equal(t, canonical.Evidences[j].Hash(), decoded.Evidences[j].Hash())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package accountability
+
+import (
+	"encoding/binary"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/metrics"
+)
+
+const (
+	// RulesVersion identifies the verification rules implemented by the precompiled contracts.
+	// It is part of the verification cache key: it must be bumped whenever a change alters
+	// the outcome of a proof verification, so that outcomes computed with different rules are never mixed.
+	RulesVersion uint64 = 1
+	// DefaultVerificationCacheSize is the default number of proof verification outcomes cached by the precompiles.
+	DefaultVerificationCacheSize = 256
+)
+
+var (
+	verificationCacheHitMeter  = metrics.NewRegisteredMeter("accountability/precompile/cache/hit", nil)
+	verificationCacheMissMeter = metrics.NewRegisteredMeter("accountability/precompile/cache/miss", nil)
+)
+
+// verificationCache is a bounded cache of the precompiled contracts outcomes, keyed by the rules version,
+// the precompile address and the keccak of the input. It is shared by the concurrent EVM instances, so that
+// re-executing the same proof (tx retries, eth_call, tracing) does not verify the evidence signatures again.
+// A nil cache disables caching.
+type verificationCache struct {
+	version uint64
+	results *lru.Cache // map[common.Hash][]byte
+}
+
+func newVerificationCache(size int, version uint64) *verificationCache {
+	if size <= 0 {
+		return nil
+	}
+	results, _ := lru.New(size)
+	return &verificationCache{version: version, results: results}
+}
+
+func (c *verificationCache) key(precompile common.Address, input []byte) common.Hash {
+	var version [8]byte
+	binary.BigEndian.PutUint64(version[:], c.version)
+	return crypto.Keccak256Hash(version[:], precompile.Bytes(), input)
+}
+
+// run returns the cached outcome of the verification of input by precompile, computing it with verify on miss.
+// verify must only depend on the input and on finalized chain data, and reports whether its outcome is final:
+// outcomes depending on missing chain data are not cached.
+func (c *verificationCache) run(precompile common.Address, input []byte, verify func() ([]byte, bool)) []byte {
+	if c == nil {
+		result, _ := verify()
+		return result
+	}
+	key := c.key(precompile, input)
+	if result, ok := c.results.Get(key); ok {
+		verificationCacheHitMeter.Mark(1)
+		return common.CopyBytes(result.([]byte))
+	}
+	verificationCacheMissMeter.Mark(1)
+	result, final := verify()
+	if final {
+		c.results.Add(key, common.CopyBytes(result))
+	}
+	return result
+}
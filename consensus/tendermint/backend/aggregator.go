@@ -9,9 +9,11 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/logthrottle"
 	"github.com/autonity/autonity/consensus/tendermint/bft"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto/blst"
@@ -42,6 +44,8 @@ var (
 	BatchesBg                  = metrics.NewRegisteredBufferedGauge("aggregator/batches", nil, metrics.GetIntPointer(100))          // size of batches (aggregated together with a single fastAggregateVerify)
 	InvalidBg                  = metrics.NewRegisteredBufferedGauge("aggregator/invalid", nil, metrics.GetIntPointer(100))          // number of invalid sigs
 	BackendAggregatorTransitBg = metrics.NewRegisteredBufferedGauge("aggregator/backend/transit", nil, metrics.GetIntPointer(1000)) // measures time for message passing from backend to aggregator
+
+	badSignatureLogs = logthrottle.New[common.Address]("acn/anomaly/badsignature", logthrottle.DefaultInterval)
 )
 
 func recordMessageProcessingTime(code uint8, start time.Time) {
@@ -61,19 +65,22 @@ func recordMessageProcessingTime(code uint8, start time.Time) {
 	}
 }
 
-type eventBuilder func(msg message.Msg, errCh chan<- error) interface{}
+type eventBuilder func(msg message.Msg, errCh chan<- error, traces msgtrace.Traces) interface{}
 
 // function to create the event for current height messages (they get picked up by Core and by the FD)
-func currentHeightEventBuilder(msg message.Msg, errCh chan<- error) interface{} {
+func currentHeightEventBuilder(msg message.Msg, errCh chan<- error, traces msgtrace.Traces) interface{} {
 	return events.MessageEvent{
 		Message: msg,
 		ErrCh:   errCh,
 		Posted:  time.Now(),
+		Traces:  traces,
 	}
 }
 
 // function to create the event for old height messages (they get picked up only by the FD)
-func oldHeightEventBuilder(msg message.Msg, errCh chan<- error) interface{} {
+// the lifecycle of old height messages ends here, as they are not processed by Core
+func oldHeightEventBuilder(msg message.Msg, errCh chan<- error, traces msgtrace.Traces) interface{} {
+	traces.Finish(msgtrace.Verify, "old height message, sent to the fault detector")
 	return events.OldMessageEvent{
 		Message: msg,
 		ErrCh:   errCh,
@@ -163,13 +170,16 @@ func (a *aggregator) start(ctx context.Context) {
 }
 
 func (a *aggregator) handleInvalidMessage(errorCh chan<- error, err error, sender common.Address) {
-	tryDisconnect(errorCh, err)
+	a.backend.ReportMessageError(err, errorCh)
 	for _, hash := range a.messagesFrom[sender] {
 		a.toIgnore[hash] = struct{}{}
 	}
 }
 
 func tryDisconnect(errorCh chan<- error, err error) {
+	if errorCh == nil {
+		return
+	}
 	select {
 	case errorCh <- err:
 	default: // do nothing
@@ -301,6 +311,7 @@ func (a *aggregator) processRound(h uint64, r int64) {
 
 	for _, proposalEvent := range roundInfo.proposals {
 		if a.toSkip(proposalEvent.Message) {
+			proposalEvent.Traces.Finish(msgtrace.Verify, "skipped")
 			continue
 		}
 		a.processProposal(proposalEvent, currentHeightEventBuilder)
@@ -510,11 +521,13 @@ func (a *aggregator) processBatches(batches [][]events.UnverifiedMessageEvent, e
 		var messages []message.Vote
 		var senders []common.Address
 		var errChs []chan<- error
+		var traces []msgtrace.Traces
 
 		for _, e := range batch {
 			m := e.Message
 			// skip messages to be ignored or that are already in core
 			if a.toSkip(m) {
+				e.Traces.Finish(msgtrace.Verify, "skipped")
 				continue
 			}
 
@@ -523,6 +536,7 @@ func (a *aggregator) processBatches(batches [][]events.UnverifiedMessageEvent, e
 			signatures = append(signatures, m.Signature())
 			senders = append(senders, e.Sender)
 			errChs = append(errChs, e.ErrCh)
+			traces = append(traces, e.Traces)
 		}
 
 		// if all messages in the batch got skipped, move to the next batch
@@ -535,6 +549,7 @@ func (a *aggregator) processBatches(batches [][]events.UnverifiedMessageEvent, e
 		valid := aggregateSignature.FastAggregateVerify(publicKeys, hash)
 
 		var validVotes []message.Vote
+		var validTraces msgtrace.Traces // traces of the sampled valid votes, carried by the aggregates
 		var invalids []uint
 
 		if !valid {
@@ -550,11 +565,16 @@ func (a *aggregator) processBatches(batches [][]events.UnverifiedMessageEvent, e
 					continue
 				}
 				validVotes = append(validVotes, msg)
+				validTraces = append(validTraces, traces[i]...)
 			}
 		} else {
 			// all messages are valid
 			validVotes = messages
+			for _, t := range traces {
+				validTraces = append(validTraces, t...)
+			}
 		}
+		validTraces.Record(msgtrace.Verify, "signature verified, aggregated")
 
 		sent += len(validVotes)
 
@@ -565,14 +585,14 @@ func (a *aggregator) processBatches(batches [][]events.UnverifiedMessageEvent, e
 			case *message.Prevote:
 				aggregateVotes := message.AggregatePrevotesSimple(validVotes)
 				for _, aggregateVote := range aggregateVotes {
-					a.knownMessages.Add(aggregateVote.Hash(), true) // prevents processing of the same aggregate computed by another peer
-					go a.backend.Post(eventer(aggregateVote, nil))  //TODO(lorenzo) refinements, do we add an errCh here?
+					a.knownMessages.Add(aggregateVote.Hash(), true)             // prevents processing of the same aggregate computed by another peer
+					go a.backend.Post(eventer(aggregateVote, nil, validTraces)) //TODO(lorenzo) refinements, do we add an errCh here?
 				}
 			case *message.Precommit:
 				aggregateVotes := message.AggregatePrecommitsSimple(validVotes)
 				for _, aggregateVote := range aggregateVotes {
-					a.knownMessages.Add(aggregateVote.Hash(), true) // prevents processing of the same aggregate computed by another peer
-					go a.backend.Post(eventer(aggregateVote, nil))  //TODO(lorenzo) refinements, do we add an errCh here?
+					a.knownMessages.Add(aggregateVote.Hash(), true)             // prevents processing of the same aggregate computed by another peer
+					go a.backend.Post(eventer(aggregateVote, nil, validTraces)) //TODO(lorenzo) refinements, do we add an errCh here?
 				}
 			default:
 				a.logger.Crit("messages being aggregated are not votes", "type", reflect.TypeOf(validVotes[0]))
@@ -584,7 +604,10 @@ func (a *aggregator) processBatches(batches [][]events.UnverifiedMessageEvent, e
 			InvalidBg.Add(int64(len(invalids)))
 		}
 		for _, index := range invalids {
-			a.logger.Info("Received invalid bls signature from", "peer", senders[index])
+			if summary, ok := badSignatureLogs.Allow(senders[index]); ok {
+				a.logger.Info(summary.Annotate("Received invalid bls signature from"), "peer", senders[index])
+			}
+			traces[index].Finish(msgtrace.Verify, message.ErrBadSignature.Error())
 			a.handleInvalidMessage(errChs[index], message.ErrBadSignature, senders[index])
 		}
 	}
@@ -594,10 +617,12 @@ func (a *aggregator) processBatches(batches [][]events.UnverifiedMessageEvent, e
 func (a *aggregator) processProposal(proposalEvent events.UnverifiedMessageEvent, eventer eventBuilder) {
 	proposal := proposalEvent.Message
 	if err := proposal.Validate(); err != nil {
+		proposalEvent.Traces.Finish(msgtrace.Verify, err.Error())
 		a.handleInvalidMessage(proposalEvent.ErrCh, err, proposalEvent.Sender)
 		return
 	}
-	go a.backend.Post(eventer(proposal, proposalEvent.ErrCh))
+	proposalEvent.Traces.Record(msgtrace.Verify, "proposal verified")
+	go a.backend.Post(eventer(proposal, proposalEvent.ErrCh, proposalEvent.Traces))
 }
 
 // assumes current or old round vote
@@ -619,10 +644,12 @@ func (a *aggregator) handleVote(voteEvent events.UnverifiedMessageEvent, committ
 	coreVotesPower := a.core.VotesPower(height, round, code)
 	if vote.Signers().IsComplex() && (coreVotesForPower.Power().Cmp(quorum) < 0 || coreVotesPower.Power().Cmp(quorum) < 0) {
 		if err := vote.Validate(); err != nil {
+			voteEvent.Traces.Finish(msgtrace.Verify, err.Error())
 			a.handleInvalidMessage(errCh, err, sender)
 			return
 		}
-		go a.backend.Post(currentHeightEventBuilder(voteEvent.Message, errCh))
+		voteEvent.Traces.Record(msgtrace.Verify, "complex aggregate verified")
+		go a.backend.Post(currentHeightEventBuilder(voteEvent.Message, errCh, voteEvent.Traces))
 		return
 	}
 
@@ -713,6 +740,7 @@ loop:
 			// if message already in Core, drop it
 			if a.alreadyProcessed(msg) {
 				a.logger.Debug("Discarding msg, already processed in Core")
+				event.Traces.Finish(msgtrace.Verify, "already processed by core")
 				break
 			}
 
@@ -778,6 +806,7 @@ loop:
 				// process proposals
 				for _, proposalEvent := range roundInfo.proposals {
 					if a.toSkip(proposalEvent.Message) {
+						proposalEvent.Traces.Finish(msgtrace.Verify, "skipped")
 						continue
 					}
 					a.processProposal(proposalEvent, currentHeightEventBuilder)
@@ -915,6 +944,7 @@ loop:
 				if batch[0].Message.Code() == message.ProposalCode {
 					for _, proposalEvent := range batch {
 						if a.toSkip(proposalEvent.Message) {
+							proposalEvent.Traces.Finish(msgtrace.Verify, "skipped")
 							continue
 						}
 						a.processProposal(proposalEvent, oldHeightEventBuilder)
@@ -17,10 +17,15 @@
 package backend
 
 import (
+	"time"
+
 	"github.com/autonity/autonity/accounts/abi"
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
+	"github.com/autonity/autonity/consensus/tendermint/core/timingdump"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/rpc"
@@ -74,7 +79,102 @@ func (api *API) GetCommitteeEnodes() []string {
 	return api.tendermint.CommitteeEnodes()
 }
 
-// Get current tendermint's core state
-func (api *API) GetCoreState() interfaces.CoreState {
+// GetCoreState returns the round state of the consensus as of the last step transition: height, round and
+// step, the proposer of the round, the locked and valid rounds and values, and the prevote and precommit
+// vote power tallies of each round of the height. It does not wait for the consensus main loop, it is
+// served even if consensus stalls. The full dump of the core state is served by debug_dumpCoreState.
+func (api *API) GetCoreState() (interfaces.StateSnapshot, error) {
+	return api.tendermint.PublishedStateSnapshot()
+}
+
+// GetCommitteeDivergence returns the last height whose proposal committee disagreed with the committee
+// computed by the node, which implies that its state diverged. Zero if none did.
+func (api *API) GetCommitteeDivergence() uint64 {
+	return api.tendermint.CommitteeDivergence()
+}
+
+// GetCommitImportStatus returns the committed blocks not imported into the chain yet. Failing is raised
+// while their import fails, core does not move to the next height until it succeeds.
+func (api *API) GetCommitImportStatus() CommitImportStatus {
+	return api.tendermint.CommitImportStatus()
+}
+
+// GetClockSkew returns the offset of the local clock estimated from the timestamps of the proposals of the
+// other committee members. Detected is raised while it exceeds the threshold.
+func (api *API) GetClockSkew() ClockSkewStatus {
+	return api.tendermint.ClockSkew()
+}
+
+// GetLiveness returns whether the recent heights were decided at the first round and in time, and whether
+// the chain stalled.
+func (api *API) GetLiveness() LivenessStatus {
+	return api.tendermint.Liveness()
+}
+
+// DebugAPI is a private RPC API to debug the processing of consensus messages
+type DebugAPI struct {
+	tendermint *Backend
+}
+
+// ConsensusTraces returns up to count of the most recently completed consensus message traces,
+// the most recent first. Messages are traced only if trace sampling is enabled.
+func (api *DebugAPI) ConsensusTraces(count int) []*msgtrace.Trace {
+	return api.tendermint.ConsensusTraces(count)
+}
+
+// StartConsensusTimingDump starts appending one CSV row per committed height to the file at path: the
+// committed round, whether the local node proposed the block, the time spent in each step, the time taken
+// to commit, the count of messages of each type, the count of prevotes sent by the node for the proposal
+// and for nil per reason, and the error class of the last proposal it found invalid. The rows are flushed
+// every flushInterval seconds, five if zero, and the file is rotated once it reaches 64MB. It fails if a
+// dump is already active.
+func (api *DebugAPI) StartConsensusTimingDump(path string, flushInterval uint) error {
+	return api.tendermint.StartConsensusTimingDump(path, time.Duration(flushInterval)*time.Second, timingdump.DefaultMaxSize)
+}
+
+// StopConsensusTimingDump flushes the buffered rows and stops the consensus timing dump.
+func (api *DebugAPI) StopConsensusTimingDump() error {
+	return api.tendermint.StopConsensusTimingDump()
+}
+
+// DumpCoreState dumps the consensus state along with the messages of the current height, of the future
+// rounds and heights, and the timers of the steps. It is served by the consensus main loop.
+func (api *DebugAPI) DumpCoreState() (interfaces.CoreState, error) {
 	return api.tendermint.CoreState()
 }
+
+// ConsensusStateSnapshot returns a consistent view of the consensus state: height, round and step, locked
+// and valid values, the proposer of the round, the vote power tallies and the time of the last transitions.
+func (api *DebugAPI) ConsensusStateSnapshot() (interfaces.StateSnapshot, error) {
+	return api.tendermint.StateSnapshot()
+}
+
+// ConsensusSupportBundle gathers the consensus diagnostics to attach to a support request: the state
+// snapshot, the candidate block pipeline state and the most recent consensus message traces.
+func (api *DebugAPI) ConsensusSupportBundle() (interfaces.SupportBundle, error) {
+	return api.tendermint.SupportBundle()
+}
+
+// PendingProposalState reports whether core holds a candidate block for the current height, along with
+// its age, and whether the local node is the proposer of the current round.
+func (api *DebugAPI) PendingProposalState() (interfaces.PendingProposalState, error) {
+	return api.tendermint.PendingProposalState()
+}
+
+// DecodeConsensusPayload decodes a consensus message payload captured on the wire, and verifies its signature
+// against the committee of the block number hint, the height of the message, or of the current height if
+// omitted. It reports whether the local MsgStore holds the message, and the error class of a payload failing
+// to decode or verify. It is read-only, nothing is handed over to consensus.
+func (api *DebugAPI) DecodeConsensusPayload(payload hexutil.Bytes, blockNumberHint *rpc.BlockNumber) (*DecodedPayload, error) {
+	var height uint64
+	if blockNumberHint != nil && *blockNumberHint > 0 {
+		height = uint64(*blockNumberHint)
+	}
+	return api.tendermint.DecodeConsensusPayload(payload, height)
+}
+
+// RetriggerProposal asks the miner for a fresh candidate block, proposed if the local node is the proposer
+// of the current round and did not propose yet. It is refused unless the consensus debug calls are enabled.
+func (api *DebugAPI) RetriggerProposal() error {
+	return api.tendermint.RetriggerProposal()
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/params/generated"
@@ -113,3 +114,25 @@ func TestAPIGetContractAddress(t *testing.T) {
 	got := API.GetContractAddress()
 	assert.Equal(t, want, got)
 }
+
+func TestAPIGetCoreState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	core := interfaces.NewMockCore(ctrl)
+	engine := &Backend{core: core}
+	api := &API{tendermint: engine}
+
+	core.EXPECT().PublishedSnapshot().Return(interfaces.StateSnapshot{}, false)
+	_, err := api.GetCoreState()
+	assert.ErrorIs(t, err, errNoPublishedState)
+
+	// the published snapshot is served without going through the core main loop
+	want := interfaces.StateSnapshot{Height: 10, Round: 1, Step: 2, LockedRound: -1, ValidRound: -1}
+	core.EXPECT().PublishedSnapshot().Return(want, true)
+	got, err := api.GetCoreState()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// the full dump needs the core main loop
+	_, err = (&DebugAPI{tendermint: engine}).DumpCoreState()
+	assert.ErrorIs(t, err, ErrStoppedEngine)
+}
@@ -1,7 +1,7 @@
 package backend
 
 import (
-	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"errors"
 	"math"
@@ -14,18 +14,23 @@ import (
 	"github.com/autonity/autonity/accounts/abi"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/logthrottle"
+	"github.com/autonity/autonity/common/randutil"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/misc"
 	tendermintCore "github.com/autonity/autonity/consensus/tendermint/core"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
+	"github.com/autonity/autonity/consensus/tendermint/core/timingdump"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/core/vm"
 	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/ethdb"
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/log"
 )
@@ -43,11 +48,23 @@ const (
 	numBuckets = 499
 	// max number of entries in each packet
 	numEntries = 10
+	// number of consensus message traces attached to the support bundle
+	supportBundleTraces = 100
 )
 
 var (
 	// ErrStoppedEngine is returned if the engine is stopped
 	ErrStoppedEngine = errors.New("stopped engine")
+	// errConsensusDebugDisabled is returned by the debug calls altering the consensus state if they are not enabled
+	errConsensusDebugDisabled = errors.New("consensus debug calls are disabled")
+	// errNoCandidateSource is returned if no miner builds the candidate blocks
+	errNoCandidateSource = errors.New("no miner to build a candidate block")
+	// errNoPublishedState is returned if the core did not publish its state yet
+	errNoPublishedState = errors.New("no consensus state published yet")
+
+	// the state retrieval failures recur for every proposal and peer until the state is restored, the
+	// key of the throttle being the message logged
+	stateLogs = logthrottle.New[string]("acn/anomaly/state", logthrottle.DefaultInterval)
 )
 
 // New creates an Ethereum Backend for BFT core engine.
@@ -74,11 +91,24 @@ func New(nodeKey *ecdsa.PrivateKey,
 		jailed:          make(map[common.Address]uint64),
 		future:          make(map[uint64][]*events.UnverifiedMessageEvent),
 		futureMinHeight: math.MaxUint64,
+		tracer:          msgtrace.New(msgtrace.DefaultCapacity),
+		inbound:         newInboundQueue(),
+		clock:           newConsensusClock(log),
+		skew:            newSkewDetector(log),
+		commitRetry:     newCommitRetry(log),
+		liveness:        newLivenessWatchdog(log),
 	}
 
 	backend.pendingMessages.SetCapacity(ringCapacity)
 
-	backend.gossiper = NewGossiper(backend.knownMessages, backend.address, backend.logger, backend.stopped)
+	backend.rand = randutil.New()
+	if services != nil && services.Rand != nil {
+		backend.rand = services.Rand
+	}
+	if services != nil && services.ClockSkew != 0 {
+		backend.clock.setSkew(services.ClockSkew)
+	}
+	backend.gossiper = NewGossiper(backend.knownMessages, backend.address, backend.logger, backend.stopped, backend.rand)
 	if services != nil {
 		backend.gossiper = services.Gossiper(backend)
 	}
@@ -86,6 +116,10 @@ func New(nodeKey *ecdsa.PrivateKey,
 	core := tendermintCore.New(backend, services, backend.address, log, noGossip)
 	backend.core = core
 	backend.evDispatcher = core
+	backend.timingDump = core.TimingDump()
+	backend.roundJournal = core.Journal()
+	backend.msgQueue = core.MessageQueue()
+	backend.coreConfig = core.Config()
 
 	backend.aggregator = newAggregator(backend, core, log, backend.knownMessages)
 
@@ -100,9 +134,14 @@ type Backend struct {
 	consensusKey blst.SecretKey
 	address      common.Address
 	logger       log.Logger
+	rand         randutil.Source
 	blockchain   *core.BlockChain
 	currentBlock func() *types.Block
 	hasBadBlock  func(hash common.Hash) bool
+	clock        *consensusClock // wall clock the block timestamps are compared to
+	skew         *skewDetector   // estimates the offset of clock from the other committee members
+	ready        chan struct{}   // closed once attached to the chain
+	readyOnce    sync.Once
 
 	// the channels for tendermint engine notifications
 	commitCh          chan<- *types.Block
@@ -130,7 +169,7 @@ type Backend struct {
 	contractsMu sync.RWMutex //todo(youssef): is that necessary?
 	vmConfig    *vm.Config
 
-	MsgStore   *tendermintCore.MsgStore //TODO: we use this only in tests, to easily reach the msg store when having a reference to the backend. It would be better to just have the `accountability` module as a part of the backend object.
+	MsgStore   *tendermintCore.MsgStore // looked up by DecodeConsensusPayload. TODO: otherwise we use this only in tests, to easily reach the msg store when having a reference to the backend. It would be better to just have the `accountability` module as a part of the backend object.
 	jailed     map[common.Address]uint64
 	jailedLock sync.RWMutex
 
@@ -143,6 +182,153 @@ type Backend struct {
 	futureMaxHeight uint64
 	futureSize      uint64
 	futureLock      sync.RWMutex
+
+	// samples the lifecycle of the received consensus messages
+	tracer *msgtrace.Tracer
+
+	// dispatches the consensus messages to core, proposals first
+	inbound *inboundQueue
+
+	// enables the debug calls altering the consensus state
+	consensusDebug atomic.Bool
+	// requests of a fresh candidate block to the miner
+	candidateRequests event.Feed
+	// last height whose proposal committee disagreed with the computed one, zero if none
+	committeeDivergence atomic.Uint64
+	// dumps the consensus timing of the committed heights
+	timingDump *timingdump.Dumper
+	// write-ahead log of the consensus round state
+	roundJournal *tendermintCore.RoundJournal
+	// consensus messages waiting to be handled by core
+	msgQueue *tendermintCore.MessageQueue
+	// tunable parameters of core
+	coreConfig *tendermintCore.Config
+	// imports again the committed blocks whose import failed
+	commitRetry *commitRetry
+	// reports the heights decided late or the chain stalled
+	liveness *livenessWatchdog
+}
+
+// SetTraceSampling sets the sampling rate of the consensus message traces to one in n messages.
+// Tracing is disabled if n is zero.
+func (sb *Backend) SetTraceSampling(n uint64) {
+	sb.tracer.SetSampling(n)
+}
+
+// ConsensusTraces returns up to count of the most recently completed consensus message traces.
+func (sb *Backend) ConsensusTraces(count int) []*msgtrace.Trace {
+	return sb.tracer.Traces(count)
+}
+
+// StartConsensusTimingDump starts dumping the consensus timing of the committed heights to the CSV file
+// at path, flushed every flushInterval and rotated once it exceeds maxSize bytes.
+func (sb *Backend) StartConsensusTimingDump(path string, flushInterval time.Duration, maxSize int64) error {
+	return sb.timingDump.Start(path, flushInterval, maxSize)
+}
+
+// StopConsensusTimingDump flushes and stops the consensus timing dump.
+func (sb *Backend) StopConsensusTimingDump() error {
+	return sb.timingDump.Stop()
+}
+
+// SetRoundJournal persists the consensus round state to the database, for the height in progress to
+// be resumed after a restart. It must be called before the engine starts.
+func (sb *Backend) SetRoundJournal(db ethdb.KeyValueStore) {
+	sb.roundJournal.SetDatabase(db)
+}
+
+// SetCommitRetryDatabase persists the committed blocks whose import failed to the database, for their
+// import to be retried after a restart. It must be called before the engine starts.
+func (sb *Backend) SetCommitRetryDatabase(db ethdb.KeyValueStore) {
+	sb.commitRetry.setDatabase(db, time.Now())
+}
+
+// CommitImportStatus reports the committed blocks not imported into the chain yet, and whether their
+// import failed.
+func (sb *Backend) CommitImportStatus() CommitImportStatus {
+	return sb.commitRetry.status()
+}
+
+// SetClockSkew offsets the time the block timestamps are compared to, to simulate a skewed system time.
+// Zero removes the offset.
+func (sb *Backend) SetClockSkew(skew time.Duration) {
+	sb.clock.setSkew(skew)
+}
+
+// ClockSkew reports the offset of the local clock estimated from the proposals of the other members.
+func (sb *Backend) ClockSkew() ClockSkewStatus {
+	status := sb.skew.status()
+	status.Injected = time.Duration(sb.clock.skew.Load())
+	return status
+}
+
+// Liveness reports whether the recent heights were decided in time.
+func (sb *Backend) Liveness() LivenessStatus {
+	return sb.liveness.status()
+}
+
+// SetVoteFairness sets the maximum number of proposals dispatched to core in a row while votes are
+// waiting. Zero restores the default.
+func (sb *Backend) SetVoteFairness(n uint64) {
+	sb.inbound.setFairness(int(min(n, math.MaxInt32)))
+}
+
+// SetCoreConfig sets the tunable parameters of core, such as the step timeouts. The zero config keeps the
+// defaults. It must be called before the engine starts.
+func (sb *Backend) SetCoreConfig(config tendermintCore.Config) {
+	if config == (tendermintCore.Config{}) {
+		return
+	}
+	*sb.coreConfig = config
+}
+
+// SetImportPacing sets the import backlog, in blocks, above which consensus delays the start of the next
+// height for the import to catch up. Zero disables the pacing.
+func (sb *Backend) SetImportPacing(n uint64) {
+	sb.core.SetImportPacing(n)
+}
+
+// SetMessageQueueSize sets the maximum number of consensus messages queued by core, the oldest future
+// height messages are dropped first when it is reached. Zero restores the default. It must be called
+// before the engine starts.
+func (sb *Backend) SetMessageQueueSize(n uint64) {
+	sb.msgQueue.SetLimit(int(min(n, math.MaxInt32)))
+}
+
+// SetConsensusDebug enables the debug calls altering the consensus state, such as the proposal retrigger.
+func (sb *Backend) SetConsensusDebug(enabled bool) {
+	sb.consensusDebug.Store(enabled)
+}
+
+// SubscribeCandidateRequests implements consensus.CandidateRequester.
+func (sb *Backend) SubscribeCandidateRequests(ch chan<- struct{}) event.Subscription {
+	return sb.candidateRequests.Subscribe(ch)
+}
+
+// PendingProposalState reports the candidate block pipeline feeding the proposals of the local node.
+func (sb *Backend) PendingProposalState() (interfaces.PendingProposalState, error) {
+	if !sb.coreRunning.Load() {
+		return interfaces.PendingProposalState{}, ErrStoppedEngine
+	}
+	return sb.core.PendingProposalState(), nil
+}
+
+// RetriggerProposal asks the miner for a fresh candidate block, proposed by core if the local node is
+// the proposer of the current round and did not propose yet.
+func (sb *Backend) RetriggerProposal() error {
+	if !sb.consensusDebug.Load() {
+		return errConsensusDebugDisabled
+	}
+	if !sb.coreRunning.Load() {
+		return ErrStoppedEngine
+	}
+	if err := sb.core.RetriggerProposal(); err != nil {
+		return err
+	}
+	if sb.candidateRequests.Send(struct{}{}) == 0 {
+		return errNoCandidateSource
+	}
+	return nil
 }
 
 func (sb *Backend) BlockChain() *core.BlockChain {
@@ -159,9 +345,9 @@ func (sb *Backend) Address() common.Address {
 }
 
 // Broadcast implements tendermint.Backend.Broadcast
-func (sb *Backend) Broadcast(committee types.Committee, message message.Msg) {
+func (sb *Backend) Broadcast(ctx context.Context, committee types.Committee, message message.Msg) {
 	// send to others
-	sb.Gossip(committee, message)
+	sb.Gossip(ctx, committee, message)
 	// send to self (directly to Core and FD, no need to verify local messages)
 	go sb.Post(events.MessageEvent{
 		Message: message,
@@ -171,12 +357,18 @@ func (sb *Backend) Broadcast(committee types.Committee, message message.Msg) {
 }
 
 func (sb *Backend) AskSync(header *types.Header) {
-	sb.gossiper.AskSync(header)
+	var known []common.Hash
+	if sb.core != nil {
+		for _, msg := range sb.core.CurrentHeightMessages() {
+			known = append(known, msg.Hash())
+		}
+	}
+	sb.gossiper.AskSync(header, known)
 }
 
 // Gossip implements tendermint.Backend.Gossip
-func (sb *Backend) Gossip(committee types.Committee, msg message.Msg) {
-	sb.gossiper.Gossip(committee, msg)
+func (sb *Backend) Gossip(ctx context.Context, committee types.Committee, msg message.Msg) {
+	sb.gossiper.Gossip(ctx, committee, msg)
 }
 
 // UpdateStopChannel implements tendermint.Backend.Gossip
@@ -210,6 +402,8 @@ func (sb *Backend) Commit(proposal *types.Block, round int64, quorumCertificate
 	// update block's header
 	proposal = proposal.WithSeal(h)
 	sb.logger.Info("Quorum of Precommits received", "proposal", proposal.Hash(), "round", round, "height", proposal.Number().Uint64())
+	// core moves to the next height once the block is imported, retry the import if it fails
+	sb.commitRetry.track(proposal, time.Now())
 	// - if the proposed and committed blocks are the same, send the proposed hash
 	//   to resultCh channel, which is being watched inside the worker.ResultLoop() function.
 	// - otherwise, we try to insert the block.
@@ -233,6 +427,8 @@ func (sb *Backend) Post(ev any) {
 		sb.evDispatcher.Post(ev)
 	case events.NewCandidateBlockEvent:
 		sb.evDispatcher.Post(ev)
+	case events.MessageEvent:
+		sb.inbound.push(ev)
 	case events.UnverifiedMessageEvent:
 		sb.messageCh <- ev
 	default:
@@ -253,6 +449,11 @@ func (sb *Backend) VerifyProposal(proposal *types.Block) (time.Duration, error)
 		return 0, core.ErrBannedHash
 	}
 
+	// an oversized proposal cannot be relayed, all the honest validators treat it as invalid
+	if uint64(proposal.Size()) > sb.blockchain.Config().ProposalSizeLimit(proposal.GasLimit()) {
+		return 0, constants.ErrOversizedProposal
+	}
+
 	// verify if the proposal block is already included in the node's local chain.
 	// This scenario can happen when we are processing a proposal, but in the meantime other peers already reached quorum on it,
 	// therefore we already received the finalized block through p2p block propagation.
@@ -262,6 +463,11 @@ func (sb *Backend) VerifyProposal(proposal *types.Block) (time.Duration, error)
 		return 0, constants.ErrAlreadyHaveBlock
 	}
 
+	// the timestamps of the proposals of the other members tell the offset of the local clock
+	if proposal.Coinbase() != sb.address {
+		sb.skew.observe(proposal.Hash(), proposal.Time(), sb.clock.Now())
+	}
+
 	// verify the header of proposed proposal
 	err := sb.VerifyHeader(sb.blockchain, proposal.Header(), false)
 	// ignore errEmptyQuorumCertificate error because we don't have the quorum certificate yet
@@ -269,11 +475,10 @@ func (sb *Backend) VerifyProposal(proposal *types.Block) (time.Duration, error)
 		var (
 			receipts types.Receipts
 
-			usedGas        = new(uint64)
-			gp             = new(core.GasPool).AddGas(proposal.GasLimit())
-			header         = proposal.Header()
-			proposalNumber = header.Number.Uint64()
-			parent         = sb.blockchain.GetBlock(proposal.ParentHash(), proposal.NumberU64()-1)
+			usedGas = new(uint64)
+			gp      = new(core.GasPool).AddGas(proposal.GasLimit())
+			header  = proposal.Header()
+			parent  = sb.blockchain.GetBlock(proposal.ParentHash(), proposal.NumberU64()-1)
 		)
 
 		// Verify London hard fork attributes including min base fee
@@ -284,6 +489,10 @@ func (sb *Backend) VerifyProposal(proposal *types.Block) (time.Duration, error)
 		// We need to process all the transaction to get the latest state to get the latest committee
 		state, stateErr := sb.blockchain.StateAt(parent.Root())
 		if stateErr != nil {
+			const msg = "Failed to get the parent state of the proposal"
+			if summary, ok := stateLogs.Allow(msg); ok {
+				sb.logger.Error(summary.Annotate(msg), "number", proposal.NumberU64(), "err", stateErr)
+			}
 			return 0, stateErr
 		}
 
@@ -322,48 +531,23 @@ func (sb *Backend) VerifyProposal(proposal *types.Block) (time.Duration, error)
 			return 0, err
 		}
 
-		//Perform the actual comparison
-		if len(header.Committee) != len(committee) {
-			sb.logger.Error("wrong committee set",
-				"proposalNumber", proposalNumber,
-				"extraLen", len(header.Committee),
-				"currentLen", len(committee),
-				"committee", header.Committee,
-				"current", committee,
-			)
-			return 0, consensus.ErrInconsistentCommitteeSet
-		}
-
-		for i := range committee {
-			if header.Committee[i].Address != committee[i].Address ||
-				header.Committee[i].VotingPower.Cmp(committee[i].VotingPower) != 0 ||
-				!bytes.Equal(header.Committee[i].ConsensusKeyBytes, committee[i].ConsensusKeyBytes) ||
-				!bytes.Equal(header.Committee[i].ConsensusKey.Marshal(), committee[i].ConsensusKey.Marshal()) ||
-				header.Committee[i].Index != committee[i].Index {
-				sb.logger.Error("wrong committee member in the set",
-					"index", i,
-					"currentVerifier", sb.address.String(),
-					"proposalNumber", proposalNumber,
-					"headerCommittee", header.Committee[i],
-					"computedCommittee", committee[i],
-					"fullHeader", header.Committee,
-					"fullComputed", committee,
-				)
-				return 0, consensus.ErrInconsistentCommitteeSet
-			}
+		if err = sb.verifyProposalCommittee(header, committee); err != nil {
+			return 0, err
 		}
 		// At this stage committee field is consistent with the validator list returned by Soma-contract
 
 		return 0, nil
 	} else if errors.Is(err, consensus.ErrFutureTimestampBlock) {
-		return time.Unix(int64(proposal.Header().Time), 0).Sub(now()), consensus.ErrFutureTimestampBlock
+		return sb.untilTimestamp(proposal.Header()), consensus.ErrFutureTimestampBlock
 	}
 
 	// Here we are considering this proposal invalid because we pruned the parent's state
 	// however this is our local node fault, not the remote proposer fault.
 	if errors.Is(err, consensus.ErrPrunedAncestor) {
-		sb.logger.Error("Rejecting a proposal because local node has pruned parent's state")
-		sb.logger.Error("Please check your pruning settings")
+		const msg = "Rejecting a proposal because local node has pruned parent's state, please check your pruning settings"
+		if summary, ok := stateLogs.Allow(msg); ok {
+			sb.logger.Error(summary.Annotate(msg))
+		}
 	}
 	return 0, err
 }
@@ -378,6 +562,14 @@ func (sb *Backend) HeadBlock() *types.Block {
 	return sb.currentBlock()
 }
 
+// ImportBacklog returns the number of blocks handed to the chain whose import did not complete yet.
+func (sb *Backend) ImportBacklog() int {
+	if sb.blockchain == nil {
+		return 0
+	}
+	return sb.blockchain.ImportBacklog()
+}
+
 func (sb *Backend) HasBadProposal(hash common.Hash) bool {
 	if sb.hasBadBlock == nil {
 		return false
@@ -390,18 +582,55 @@ func (sb *Backend) GetContractABI() *abi.ABI {
 	return sb.blockchain.ProtocolContracts().ABI()
 }
 
-func (sb *Backend) CoreState() interfaces.CoreState {
-	return sb.core.CoreState()
+// CoreState dumps the consensus state along with the messages known by the core. It is served by the core
+// main loop.
+func (sb *Backend) CoreState() (interfaces.CoreState, error) {
+	if !sb.coreRunning.Load() {
+		return interfaces.CoreState{}, ErrStoppedEngine
+	}
+	return sb.core.CoreState(), nil
+}
+
+// StateSnapshot returns a consistent view of the consensus state.
+func (sb *Backend) StateSnapshot() (interfaces.StateSnapshot, error) {
+	if !sb.coreRunning.Load() {
+		return interfaces.StateSnapshot{}, ErrStoppedEngine
+	}
+	return sb.core.Snapshot(), nil
+}
+
+// PublishedStateSnapshot returns the consensus state as of the last step transition. It is read without going
+// through the core main loop, so that it is served even if consensus stalls.
+func (sb *Backend) PublishedStateSnapshot() (interfaces.StateSnapshot, error) {
+	snapshot, ok := sb.core.PublishedSnapshot()
+	if !ok {
+		return interfaces.StateSnapshot{}, errNoPublishedState
+	}
+	return snapshot, nil
+}
+
+// SupportBundle gathers the consensus state snapshot, the candidate block pipeline state and the most
+// recent consensus message traces.
+func (sb *Backend) SupportBundle() (interfaces.SupportBundle, error) {
+	if !sb.coreRunning.Load() {
+		return interfaces.SupportBundle{}, ErrStoppedEngine
+	}
+	bundle := sb.core.SupportBundle()
+	bundle.Traces = sb.tracer.Traces(supportBundleTraces)
+	return bundle, nil
 }
 
 // CommitteeEnodes retrieve the list of validators enodes for the current block
 func (sb *Backend) CommitteeEnodes() []string {
 	db, err := sb.blockchain.State()
 	if err != nil {
-		sb.logger.Error("Failed to get state", "err", err)
+		const msg = "Failed to get state"
+		if summary, ok := stateLogs.Allow(msg); ok {
+			sb.logger.Error(summary.Annotate(msg), "err", err)
+		}
 		return nil
 	}
-	enodes, err := sb.blockchain.ProtocolContracts().CommitteeEnodes(sb.blockchain.CurrentBlock(), db, false)
+	enodes, err := sb.blockchain.ProtocolContracts().CommitteeEnodes(context.Background(), sb.blockchain.CurrentBlock(), db, false)
 	if err != nil {
 		sb.logger.Error("Failed to get block committee", "err", err)
 		return nil
@@ -409,8 +638,9 @@ func (sb *Backend) CommitteeEnodes() []string {
 	return enodes.StrList
 }
 
-// SyncPeer Synchronize new connected peer with current height messages
-func (sb *Backend) SyncPeer(address common.Address) {
+// SyncPeer Synchronize new connected peer with current height messages, the ones listed in the sync request
+// of the peer are skipped.
+func (sb *Backend) SyncPeer(address common.Address, request []byte) {
 	if sb.Broadcaster == nil {
 		return
 	}
@@ -419,12 +649,20 @@ func (sb *Backend) SyncPeer(address common.Address) {
 	if !ok {
 		return
 	}
-	messages := sb.core.CurrentHeightMessages()
-	sb.logger.Debug("sent current height messages", "peer", address, "n", len(messages), "msgs", messages)
-	for _, msg := range messages {
-		//We do not save sync messages in the arc cache as recipient could not have been able to process some previous sent.
-		go peer.SendRaw(NetworkCodes[msg.Code()], msg.Payload()) //nolint
+	known, err := decodeSyncRequest(request)
+	if err != nil {
+		sb.logger.Debug("Invalid sync request, sending all the messages", "peer", address, "err", err)
 	}
+	current := sb.core.CurrentHeightMessages()
+	messages := missingMessages(current, known)
+	sb.logger.Debug("sent current height messages", "peer", address, "n", len(messages), "known", len(current)-len(messages), "msgs", messages)
+	// The messages are sent in order. We do not save sync messages in the arc cache as recipient could
+	// not have been able to process some previous sent.
+	go func() {
+		for _, msg := range messages {
+			_ = peer.SendRaw(NetworkCodes[msg.Code()], msg.Payload())
+		}
+	}()
 }
 
 // called by tendermint core to dump core state
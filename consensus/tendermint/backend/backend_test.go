@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"os"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -19,9 +20,12 @@ import (
 	"github.com/autonity/autonity/accounts/abi/bind/backends"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/randutil"
+	"github.com/autonity/autonity/common/rotatingbloom"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/misc"
 	tdmcore "github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core"
@@ -82,8 +86,8 @@ func makeSigner(key blst.SecretKey) message.Signer {
 func TestAskSync(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
-	// We are testing for a Quorum Q of peers to be asked for sync.
-	header, _ := headerAndBlsKeys(7) // N=7, F=2, Q=5
+	// We are testing for a few peers to be asked for sync.
+	header, _ := headerAndBlsKeys(7)
 	validators := header.Committee
 	addresses := make([]common.Address, 0, len(validators))
 	peers := make(map[common.Address]consensus.Peer)
@@ -105,17 +109,81 @@ func TestAskSync(t *testing.T) {
 	broadcaster.EXPECT().FindPeers(m).Return(peers)
 	b := &Backend{
 		knownMessages: knownMessages,
-		gossiper:      NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{})),
+		gossiper:      NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{}), randutil.New()),
 		logger:        log.New("backend", "test", "id", 0),
 	}
 	b.SetBroadcaster(broadcaster)
 	b.AskSync(header)
 	<-time.NewTimer(2 * time.Second).C
-	if atomic.LoadUint64(&counter) != 5 {
+	if atomic.LoadUint64(&counter) != syncPeers {
 		t.Fatalf("ask sync message transmission failure")
 	}
 }
 
+func TestAskSyncDeterministicTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	header, _ := headerAndBlsKeys(7) // N=7, F=2, Q=5
+	peers := make(map[common.Address]consensus.Peer)
+	for _, val := range header.Committee {
+		peers[val.Address] = consensus.NewMockPeer(ctrl)
+	}
+	newSeededGossiper := func() *Gossiper {
+		knownMessages := fixsizecache.New[common.Hash, bool](499, 10, fixsizecache.HashKey[common.Hash])
+		return NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{}), randutil.NewSeeded(7))
+	}
+
+	// the same seeded scenario run twice selects the same peers in the same order
+	first, second := newSeededGossiper(), newSeededGossiper()
+	var firstRun, secondRun [][]common.Address
+	for i := 0; i < 5; i++ {
+		firstRun = append(firstRun, first.syncTargets(peers))
+		secondRun = append(secondRun, second.syncTargets(peers))
+	}
+	require.Equal(t, firstRun, secondRun)
+	require.NotEqual(t, firstRun[0], firstRun[1])
+
+	// AskSync asks a few peers at a time, walking the order drawn from the source before drawing another
+	reference := newSeededGossiper()
+	order := append(reference.syncTargets(peers), reference.syncTargets(peers)...)
+	expected := [][]common.Address{order[0:3], order[3:6], {order[6]}}
+	for _, addr := range order[7:] {
+		if len(expected[2]) < syncPeers && addr != order[6] {
+			expected[2] = append(expected[2], addr)
+		}
+	}
+	asked := make(chan common.Address, len(peers))
+	for addr, p := range peers {
+		addr := addr
+		p.(*consensus.MockPeer).EXPECT().Send(SyncNetworkMsg, gomock.Eq([]byte{})).Do(func(_, _ interface{}) {
+			asked <- addr
+		}).AnyTimes()
+	}
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().FindPeers(gomock.Any()).Return(peers).Times(len(expected))
+	gossiper := newSeededGossiper()
+	gossiper.SetBroadcaster(broadcaster)
+	everyone := make(map[common.Address]bool)
+	for _, targets := range expected {
+		gossiper.AskSync(header, nil)
+		var got []common.Address
+		for range targets {
+			select {
+			case addr := <-asked:
+				got = append(got, addr)
+			case <-time.After(2 * time.Second):
+				t.Fatal("ask sync message transmission failure")
+			}
+		}
+		require.ElementsMatch(t, targets, got)
+		for _, addr := range got {
+			everyone[addr] = true
+		}
+	}
+	// the whole committee was asked over the retries
+	require.Len(t, everyone, len(peers))
+}
+
 func BenchmarkGossip(b *testing.B) {
 	ctrl := gomock.NewController(b)
 	defer ctrl.Finish()
@@ -135,14 +203,14 @@ func BenchmarkGossip(b *testing.B) {
 		mockedPeer := consensus.NewMockPeer(ctrl)
 		mockedPeer.EXPECT().SendRaw(gomock.Any(), gomock.Any()).AnyTimes()
 		broadcaster.EXPECT().FindPeer(val.Address).Return(mockedPeer, true).AnyTimes()
-		addressCache := fixsizecache.New[common.Hash, bool](1997, 10, fixsizecache.HashKey[common.Hash])
-		mockedPeer.EXPECT().Cache().Return(addressCache).AnyTimes()
+		peerKnown := rotatingbloom.NewDefault()
+		mockedPeer.EXPECT().KnownMessages().Return(peerKnown).AnyTimes()
 	}
 
 	knownMessages := fixsizecache.New[common.Hash, bool](4997, 20, fixsizecache.HashKey[common.Hash])
 	bk := &Backend{
 		knownMessages: knownMessages,
-		gossiper:      NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{})),
+		gossiper:      NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{}), randutil.New()),
 	}
 	bk.SetBroadcaster(broadcaster)
 
@@ -150,14 +218,14 @@ func BenchmarkGossip(b *testing.B) {
 	for n := 0; n < 1000; n++ {
 		i := n % 1000
 		//n := time.Now()
-		bk.Gossip(validators, msgs[i])
+		bk.Gossip(context.Background(), validators, msgs[i])
 		//b.Log("time in 1 gossip", time.Since(n).Nanoseconds())
 	}
 	b.Run("cache checks", func(b *testing.B) {
 		b.ReportAllocs()
 		for n := 0; n < b.N; n++ {
 			i := n % 1000
-			bk.Gossip(validators, msgs[i])
+			bk.Gossip(context.Background(), validators, msgs[i])
 		}
 	})
 }
@@ -180,10 +248,10 @@ func TestGossip(t *testing.T) {
 		mockedPeer := consensus.NewMockPeer(ctrl)
 		// Address n3 is supposed to already have this message
 		if i == 3 {
-			address3Cache := fixsizecache.New[common.Hash, bool](11, 10, fixsizecache.HashKey[common.Hash])
-			address3Cache.Add(msg.Hash(), true)
+			peer3Known := rotatingbloom.NewDefault()
+			peer3Known.Add(msg.Hash(), msg.H())
 			mockedPeer.EXPECT().SendRaw(gomock.Any(), gomock.Any()).Times(0)
-			mockedPeer.EXPECT().Cache().Return(address3Cache)
+			mockedPeer.EXPECT().KnownMessages().Return(peer3Known)
 		} else {
 			mockedPeer.EXPECT().SendRaw(gomock.Any(), gomock.Any()).Do(func(msgCode, data interface{}) {
 				// We want to make sure the payload is correct AND that no other messages is sent.
@@ -191,8 +259,8 @@ func TestGossip(t *testing.T) {
 					atomic.AddUint64(&counter, 1)
 				}
 			}).Times(1)
-			addressCache := fixsizecache.New[common.Hash, bool](11, 10, fixsizecache.HashKey[common.Hash])
-			mockedPeer.EXPECT().Cache().Return(addressCache).AnyTimes()
+			peerKnown := rotatingbloom.NewDefault()
+			mockedPeer.EXPECT().KnownMessages().Return(peerKnown).AnyTimes()
 		}
 		peers[val.Address] = mockedPeer
 		broadcaster.EXPECT().FindPeer(val.Address).Return(peers[val.Address], true)
@@ -201,17 +269,160 @@ func TestGossip(t *testing.T) {
 	knownMessages := fixsizecache.New[common.Hash, bool](499, 10, fixsizecache.HashKey[common.Hash])
 	b := &Backend{
 		knownMessages: knownMessages,
-		gossiper:      NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{})),
+		gossiper:      NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{}), randutil.New()),
 	}
 	b.SetBroadcaster(broadcaster)
 
-	b.Gossip(validators, msg)
+	b.Gossip(context.Background(), validators, msg)
 	<-time.NewTimer(2 * time.Second).C
 	if c := atomic.LoadUint64(&counter); c != 4 {
 		t.Fatal("Gossip message transmission failure", "have", c, "want", 4)
 	}
 }
 
+func TestGossipDropsCancelledSends(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	header, blsKeys := headerAndBlsKeys(1)
+	committee := header.Committee
+	signer := makeSigner(blsKeys[0])
+	pending := message.NewPrecommit(0, 1, common.Hash{}, signer, &committee[0], 1)
+	staleVote := message.NewPrevote(0, 1, common.Hash{}, signer, &committee[0], 1)
+	proposal := message.NewPropose(0, 1, -1, types.NewBlockWithHeader(&types.Header{Number: common.Big1}), signer, &committee[0])
+
+	// the peer is slow to write the first message, the next sends are queued
+	release := make(chan struct{})
+	written := make(chan uint64, 3)
+	peer := consensus.NewMockPeer(ctrl)
+	peer.EXPECT().KnownMessages().Return(rotatingbloom.NewDefault()).AnyTimes()
+	peer.EXPECT().SendRaw(gomock.Any(), gomock.Any()).DoAndReturn(func(msgCode uint64, _ []byte) error {
+		if msgCode == PrecommitNetworkMsg {
+			<-release
+		}
+		written <- msgCode
+		return nil
+	}).AnyTimes()
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().FindPeer(committee[0].Address).Return(peer, true).AnyTimes()
+
+	knownMessages := fixsizecache.New[common.Hash, bool](499, 10, fixsizecache.HashKey[common.Hash])
+	gossiper := NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{}), randutil.New())
+	gossiper.concurrencyLimiter = make(chan struct{}, 1)
+	gossiper.SetBroadcaster(broadcaster)
+
+	heightCtx, cancelHeight := context.WithCancel(context.Background())
+	defer cancelHeight()
+	roundCtx, cancelRound := context.WithCancel(heightCtx)
+	gossiper.Gossip(heightCtx, committee, pending)
+
+	staleVoteDone := make(chan struct{})
+	go func() {
+		gossiper.Gossip(roundCtx, committee, staleVote)
+		close(staleVoteDone)
+	}()
+	proposalDone := make(chan struct{})
+	go func() {
+		gossiper.Gossip(heightCtx, committee, proposal)
+		close(proposalDone)
+	}()
+
+	// the round changes while the vote is queued
+	cancelRound()
+	<-staleVoteDone
+	close(release)
+	<-proposalDone
+
+	timeout := time.After(2 * time.Second)
+	for _, want := range []uint64{PrecommitNetworkMsg, ProposeNetworkMsg} {
+		select {
+		case code := <-written:
+			require.Equal(t, want, code)
+		case <-timeout:
+			t.Fatalf("message %d not written", want)
+		}
+	}
+	select {
+	case code := <-written:
+		t.Fatalf("stale message %d written", code)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGossipOncePerPeerPerHeight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	header, blsKeys := headerAndBlsKeys(5)
+	committee := header.Committee
+	signer := makeSigner(blsKeys[0])
+	votes := func(height uint64) []message.Msg {
+		msgs := make([]message.Msg, 10)
+		for i := range msgs {
+			msgs[i] = message.NewPrevote(int64(i), height, common.Hash{byte(i)}, signer, &committee[0], len(committee))
+		}
+		return msgs
+	}
+	firstHeight, secondHeight := votes(1), votes(2)
+
+	var mu sync.Mutex
+	sent := make(map[common.Address]map[common.Hash]int)
+	writes := make(chan struct{}, 1000)
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	for _, member := range committee {
+		address := member.Address
+		sent[address] = make(map[common.Hash]int)
+		peer := consensus.NewMockPeer(ctrl)
+		peer.EXPECT().KnownMessages().Return(rotatingbloom.NewDefault()).AnyTimes()
+		peer.EXPECT().SendRaw(gomock.Any(), gomock.Any()).DoAndReturn(func(_ uint64, payload []byte) error {
+			mu.Lock()
+			sent[address][crypto.Hash(payload)]++
+			mu.Unlock()
+			writes <- struct{}{}
+			return nil
+		}).AnyTimes()
+		broadcaster.EXPECT().FindPeer(address).Return(peer, true).AnyTimes()
+	}
+
+	knownMessages := fixsizecache.New[common.Hash, bool](499, 10, fixsizecache.HashKey[common.Hash])
+	gossiper := NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{}), randutil.New())
+	gossiper.SetBroadcaster(broadcaster)
+
+	// the messages are gossiped again on each round, and the ones of the previous height after the new height
+	for i := 0; i < 3; i++ {
+		for _, msg := range firstHeight {
+			gossiper.Gossip(context.Background(), committee, msg)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for _, msg := range append(secondHeight, firstHeight...) {
+			gossiper.Gossip(context.Background(), committee, msg)
+		}
+	}
+
+	want := len(committee) * (len(firstHeight) + len(secondHeight))
+	timeout := time.After(2 * time.Second)
+	for i := 0; i < want; i++ {
+		select {
+		case <-writes:
+		case <-timeout:
+			t.Fatalf("%d messages sent, want %d", i, want)
+		}
+	}
+	select {
+	case <-writes:
+		t.Fatal("message sent twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for address, counts := range sent {
+		for _, msg := range append(firstHeight, secondHeight...) {
+			require.Equal(t, 1, counts[msg.Hash()], "peer %v", address)
+		}
+	}
+}
+
 func TestVerifyProposal(t *testing.T) {
 	blockchain, backend := newBlockChain(1)
 	blocks := make([]*types.Block, 5)
@@ -260,6 +471,26 @@ func TestVerifyProposal(t *testing.T) {
 
 }
 
+func TestVerifyOversizedProposal(t *testing.T) {
+	blockchain, backend := newBlockChain(1)
+	block, err := makeBlockWithoutSeal(blockchain, backend, blockchain.Genesis())
+	require.NoError(t, err)
+	block, err = backend.AddSeal(block)
+	require.NoError(t, err)
+	size := uint64(block.Size())
+
+	// We need to sleep to avoid verifying a block in the future
+	time.Sleep(time.Second)
+
+	setMaxProposalSize(t, blockchain.Config(), size)
+	_, err = backend.VerifyProposal(block)
+	require.NoError(t, err)
+
+	setMaxProposalSize(t, blockchain.Config(), size-1)
+	_, err = backend.VerifyProposal(block)
+	require.ErrorIs(t, err, constants.ErrOversizedProposal)
+}
+
 func TestHasBadProposal(t *testing.T) {
 	t.Run("callback is not set, false returned", func(t *testing.T) {
 		b := &Backend{}
@@ -386,6 +617,9 @@ func TestCommit(t *testing.T) {
 			Broadcaster: broadcaster,
 			gossiper:    gossiper,
 			logger:      log.New("backend", "test", "id", 0),
+			commitRetry: newCommitRetry(log.New("backend", "test", "id", 0)),
+			liveness:    newLivenessWatchdog(log.New("backend", "test", "id", 0)),
+			skew:        newSkewDetector(log.New("backend", "test", "id", 0)),
 		}
 		b.SetBroadcaster(broadcaster)
 		b.SetEnqueuer(enqueuer)
@@ -404,7 +638,7 @@ func TestCommit(t *testing.T) {
 func TestSyncPeer(t *testing.T) {
 	t.Run("no Broadcaster set, nothing done", func(t *testing.T) {
 		b := &Backend{}
-		b.SyncPeer(common.HexToAddress("0x0123456789"))
+		b.SyncPeer(common.HexToAddress("0x0123456789"), nil)
 	})
 
 	t.Run("valid params given, messages sent", func(t *testing.T) {
@@ -414,12 +648,18 @@ func TestSyncPeer(t *testing.T) {
 		peerAddr1 := common.HexToAddress("0x0123456789")
 		messages := []message.Msg{
 			message.NewPrevote(7, 8, common.HexToHash("0x1227"), testSigner, testCommitteeMember, 1),
+			message.NewPrecommit(7, 8, common.HexToHash("0x1227"), testSigner, testCommitteeMember, 1),
+			message.NewPrevote(8, 8, common.HexToHash("0x1228"), testSigner, testCommitteeMember, 1),
 		}
 
-		payload := messages[0].Payload()
-
+		// the messages of the core are sent in its order
+		sent := make(chan struct{})
 		peer1Mock := consensus.NewMockPeer(ctrl)
-		peer1Mock.EXPECT().SendRaw(PrevoteNetworkMsg, payload)
+		gomock.InOrder(
+			peer1Mock.EXPECT().SendRaw(PrevoteNetworkMsg, messages[0].Payload()),
+			peer1Mock.EXPECT().SendRaw(PrecommitNetworkMsg, messages[1].Payload()),
+			peer1Mock.EXPECT().SendRaw(PrevoteNetworkMsg, messages[2].Payload()).Do(func(uint64, []byte) { close(sent) }),
+		)
 
 		peers := make(map[common.Address]consensus.Peer)
 		peers[peerAddr1] = peer1Mock
@@ -439,10 +679,53 @@ func TestSyncPeer(t *testing.T) {
 		}
 		b.SetBroadcaster(broadcaster)
 
-		b.SyncPeer(peerAddr1)
+		b.SyncPeer(peerAddr1, nil)
+
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("messages not sent")
+		}
+	})
+
+	t.Run("messages known to the peer skipped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		peerAddr1 := common.HexToAddress("0x0123456789")
+		messages := []message.Msg{
+			message.NewPrevote(7, 8, common.HexToHash("0x1227"), testSigner, testCommitteeMember, 1),
+			message.NewPrecommit(7, 8, common.HexToHash("0x1227"), testSigner, testCommitteeMember, 1),
+			message.NewPrevote(8, 8, common.HexToHash("0x1228"), testSigner, testCommitteeMember, 1),
+		}
+
+		sent := make(chan struct{})
+		peer1Mock := consensus.NewMockPeer(ctrl)
+		gomock.InOrder(
+			peer1Mock.EXPECT().SendRaw(PrevoteNetworkMsg, messages[0].Payload()),
+			peer1Mock.EXPECT().SendRaw(PrevoteNetworkMsg, messages[2].Payload()).Do(func(uint64, []byte) { close(sent) }),
+		)
+		broadcaster := consensus.NewMockBroadcaster(ctrl)
+		broadcaster.EXPECT().FindPeer(peerAddr1).Return(peer1Mock, true)
+		tendermintC := interfaces.NewMockCore(ctrl)
+		tendermintC.EXPECT().CurrentHeightMessages().Return(messages)
+		gossiper := interfaces.NewMockGossiper(ctrl)
+		gossiper.EXPECT().SetBroadcaster(broadcaster).Times(1)
+		b := &Backend{
+			logger:   log.New("backend", "test", "id", 0),
+			gossiper: gossiper,
+			core:     tendermintC,
+		}
+		b.SetBroadcaster(broadcaster)
+
+		// the peer holds the precommit, and a message this node does not have
+		b.SyncPeer(peerAddr1, encodeSyncRequest([]common.Hash{messages[1].Hash(), {0x01}}))
 
-		wait := time.NewTimer(time.Second)
-		<-wait.C
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("messages not sent")
+		}
 	})
 }
 
@@ -485,6 +768,13 @@ func TestBackendGetContractABI(t *testing.T) {
 // in this test, we can set n to 1, and it means we can process Istanbul and commit a
 // block by one node. Otherwise, if n is larger than 1, we have to generate
 // other fake events to process Istanbul.
+// setMaxProposalSize sets the proposal size cap of the (shared) test chain config until the end of the test.
+func setMaxProposalSize(t *testing.T, config *params.ChainConfig, size uint64) {
+	previous := config.MaxProposalSize
+	config.MaxProposalSize = size
+	t.Cleanup(func() { config.MaxProposalSize = previous })
+}
+
 func newBlockChain(n int) (*core.BlockChain, *Backend) {
 	genesis, nodeKeys, consensusKeys := getGenesisAndKeys(n)
 
@@ -499,7 +789,9 @@ func newBlockChain(n int) (*core.BlockChain, *Backend) {
 	if err != nil {
 		panic(err)
 	}
-	b.SetBlockchain(blockchain)
+	if err = b.Attach(blockchain, blockchain.ProtocolContracts()); err != nil {
+		panic(err)
+	}
 	err = b.Start(context.Background())
 	if err != nil {
 		panic(err)
@@ -0,0 +1,182 @@
+package backend
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+)
+
+const (
+	// clockStepWarningThreshold is the backwards step of the system time above which a warning is logged.
+	clockStepWarningThreshold = 5 * time.Second
+	// clockSkewThreshold is the offset of the local clock from the proposals of the other members above
+	// which the local clock is reported skewed.
+	clockSkewThreshold = 5 * time.Second
+	// clockSkewSamples is the number of the most recent proposals the offset is estimated from.
+	clockSkewSamples = 16
+	// clockSkewMinSamples is the number of proposals needed to estimate the offset.
+	clockSkewMinSamples = 3
+)
+
+var (
+	clockBackwardsStepsMeter = metrics.NewRegisteredMeter("tendermint/clock/backwards", nil)
+	clockSkewGauge           = metrics.NewRegisteredGauge("tendermint/clock/skew", nil) // estimated offset, in milliseconds
+)
+
+// consensusClock is the wall clock the block timestamps are compared to. The round timers and the durations
+// measured by the consensus are monotonic, the block timestamps cannot be. When the system time steps
+// backwards, on an NTP correction or a VM migration, the clock stalls until the system time caught up instead
+// of stepping back with it: a proposal found valid does not turn into a block from the future, and the delays
+// computed against the block timestamps are not stretched by the step. The steps are detected by comparing the
+// wall time elapsed with the monotonic one.
+type consensusClock struct {
+	wall      func() time.Time     // system time, may step
+	monotonic func() time.Duration // monotonic time elapsed since an arbitrary origin
+	logger    log.Logger
+	skew      atomic.Int64 // offset added to the system time, injected by the tests
+
+	mu       sync.Mutex
+	last     time.Time     // latest time returned
+	lastMono time.Duration // monotonic time at which last was read
+	stepping bool          // the system time is behind last, the step was reported
+	steps    uint64        // backwards steps above the warning threshold
+}
+
+func newConsensusClock(logger log.Logger) *consensusClock {
+	origin := time.Now()
+	return &consensusClock{
+		wall:      func() time.Time { return now() },
+		monotonic: func() time.Duration { return time.Since(origin) },
+		logger:    logger,
+	}
+}
+
+// Now returns the current time, which never goes backwards.
+func (c *consensusClock) Now() time.Time {
+	if c == nil {
+		return now()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wall, mono := c.wall().Add(time.Duration(c.skew.Load())), c.monotonic()
+	if c.last.IsZero() || !wall.Before(c.last) {
+		c.last, c.lastMono, c.stepping = wall, mono, false
+		return wall
+	}
+	if !c.stepping {
+		c.stepping = true
+		if step := (mono - c.lastMono) - wall.Sub(c.last); step > clockStepWarningThreshold {
+			c.steps++
+			clockBackwardsStepsMeter.Mark(1)
+			c.logger.Warn("System time stepped backwards, holding the consensus clock until it caught up",
+				"step", common.PrettyDuration(step), "held", c.last)
+		}
+	}
+	return c.last
+}
+
+// setSkew offsets the time of the clock, to simulate a skewed system time. The change applies at once, it is
+// not held as a backwards step.
+func (c *consensusClock) setSkew(skew time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skew.Store(int64(skew))
+	c.last, c.stepping = time.Time{}, false
+}
+
+// ClockSkewStatus is the offset of the local clock estimated from the timestamps of the proposals of the
+// other committee members.
+type ClockSkewStatus struct {
+	// Detected is raised while the estimated offset exceeds the threshold, either way.
+	Detected bool
+	// Offset is the median of the local time at reception minus the timestamp of the recent proposals of
+	// the other members, positive if the local clock is ahead of theirs.
+	Offset    time.Duration
+	Threshold time.Duration
+	Samples   int
+	// Injected is the skew the clock is offset by, to simulate a skewed system time in the tests.
+	Injected time.Duration `json:",omitempty"`
+}
+
+// skewDetector estimates the offset of the local clock from the timestamps of the proposals of the other
+// members. A proposer sets the timestamp of its block to its own clock, and proposes once the timestamp
+// is reached: the proposals of an honest majority reach a node whose clock is in sync within a second or
+// so of their timestamp, while a node whose clock is skewed sees them all offset by its skew.
+type skewDetector struct {
+	mu      sync.Mutex
+	offsets []time.Duration // ring of the most recent offsets
+	hashes  []common.Hash   // proposals the offsets were sampled from
+	next    int
+	logger  log.Logger
+	skewed  bool
+}
+
+func newSkewDetector(logger log.Logger) *skewDetector {
+	return &skewDetector{logger: logger}
+}
+
+// observe samples the offset of the local time from the timestamp of a proposal, once per proposal.
+func (d *skewDetector) observe(hash common.Hash, timestamp uint64, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, sampled := range d.hashes {
+		if sampled == hash {
+			return
+		}
+	}
+	offset := now.Sub(time.Unix(int64(timestamp), 0))
+	if len(d.offsets) < clockSkewSamples {
+		d.offsets, d.hashes = append(d.offsets, offset), append(d.hashes, hash)
+	} else {
+		d.offsets[d.next], d.hashes[d.next] = offset, hash
+	}
+	d.next = (d.next + 1) % clockSkewSamples
+
+	status := d.statusLocked()
+	clockSkewGauge.Update(status.Offset.Milliseconds())
+	if status.Detected != d.skewed {
+		d.skewed = status.Detected
+		if status.Detected {
+			d.logger.Warn("Local clock skewed from the other committee members, the proposals of the node are likely to be rejected",
+				"offset", common.PrettyDuration(status.Offset), "samples", status.Samples)
+		} else {
+			d.logger.Info("Local clock back in sync with the other committee members", "offset", common.PrettyDuration(status.Offset))
+		}
+	}
+}
+
+// status returns the estimated offset of the local clock.
+func (d *skewDetector) status() ClockSkewStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.statusLocked()
+}
+
+func (d *skewDetector) statusLocked() ClockSkewStatus {
+	status := ClockSkewStatus{Threshold: clockSkewThreshold, Samples: len(d.offsets)}
+	if len(d.offsets) < clockSkewMinSamples {
+		return status
+	}
+	sorted := append([]time.Duration(nil), d.offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	status.Offset = sorted[len(sorted)/2]
+	status.Detected = status.Offset > clockSkewThreshold || status.Offset < -clockSkewThreshold
+	return status
+}
+
+// futureBlock reports whether the header timestamp is ahead of the local clock by more than the allowed drift.
+func (sb *Backend) futureBlock(header *types.Header) bool {
+	return header.Time > uint64(sb.clock.Now().Unix()+allowedFutureBlockTimeSeconds)
+}
+
+// untilTimestamp returns the duration until the local clock reaches the header timestamp.
+func (sb *Backend) untilTimestamp(header *types.Header) time.Duration {
+	return time.Unix(int64(header.Time), 0).Sub(sb.clock.Now())
+}
@@ -0,0 +1,145 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+// steppingClock is a system clock whose wall time can step, the monotonic time only moving forward.
+type steppingClock struct {
+	wall time.Time
+	mono time.Duration
+}
+
+func (c *steppingClock) advance(d time.Duration) {
+	c.wall = c.wall.Add(d)
+	c.mono += d
+}
+
+func (c *steppingClock) stepBack(d time.Duration) {
+	c.wall = c.wall.Add(-d)
+}
+
+func newSteppingConsensusClock(system *steppingClock) *consensusClock {
+	return &consensusClock{
+		wall:      func() time.Time { return system.wall },
+		monotonic: func() time.Duration { return system.mono },
+		logger:    log.Root(),
+	}
+}
+
+func TestConsensusClockBackwardsStep(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	system := &steppingClock{wall: start}
+	sb := &Backend{clock: newSteppingConsensusClock(system)}
+
+	// a proposal timestamped ahead of the local time is received mid-round, and found valid
+	proposal := &types.Header{Time: uint64(start.Unix()) + 1}
+	require.False(t, sb.futureBlock(proposal))
+	require.Equal(t, time.Second, sb.untilTimestamp(proposal))
+
+	// the system time steps back by 30s
+	system.advance(500 * time.Millisecond)
+	sb.clock.Now()
+	system.stepBack(30 * time.Second)
+
+	// the proposal is still valid, and the delay until its timestamp is not stretched by the step
+	require.False(t, sb.futureBlock(proposal))
+	require.Equal(t, 500*time.Millisecond, sb.untilTimestamp(proposal))
+	require.Equal(t, uint64(1), sb.clock.steps)
+	// the blocks too far ahead are still rejected
+	require.True(t, sb.futureBlock(&types.Header{Time: uint64(start.Unix()) + 3}))
+
+	// the clock holds until the system time caught up, the step being reported once
+	held := sb.clock.Now()
+	for i := 0; i < 40; i++ {
+		system.advance(time.Second)
+		now := sb.clock.Now()
+		require.False(t, now.Before(held))
+		held = now
+	}
+	require.Equal(t, uint64(1), sb.clock.steps)
+	require.Equal(t, system.wall, sb.clock.Now())
+
+	// the small steps are tolerated silently
+	system.stepBack(time.Second)
+	require.Equal(t, held, sb.clock.Now())
+	require.Equal(t, uint64(1), sb.clock.steps)
+
+	// a later large step is reported again
+	system.advance(2 * time.Second)
+	require.Equal(t, system.wall, sb.clock.Now())
+	system.stepBack(time.Minute)
+	sb.clock.Now()
+	require.Equal(t, uint64(2), sb.clock.steps)
+}
+
+func TestConsensusClockSkew(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	system := &steppingClock{wall: start}
+	sb := &Backend{clock: newSteppingConsensusClock(system)}
+
+	// the skew offsets the block timestamps compared to the local time
+	sb.SetClockSkew(30 * time.Second)
+	require.Equal(t, start.Add(30*time.Second), sb.clock.Now())
+	require.False(t, sb.futureBlock(&types.Header{Time: uint64(start.Unix()) + 30}))
+
+	// removing the skew applies at once, it is not held as a backwards step
+	sb.SetClockSkew(0)
+	require.Equal(t, start, sb.clock.Now())
+	require.True(t, sb.futureBlock(&types.Header{Time: uint64(start.Unix()) + 30}))
+	require.Zero(t, sb.clock.steps)
+}
+
+func TestSkewDetector(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	d := newSkewDetector(log.Root())
+	observe := func(n int, timestamp uint64, now time.Time) {
+		d.observe(common.BigToHash(big.NewInt(int64(n))), timestamp, now)
+	}
+
+	// no estimate until enough proposals are sampled
+	observe(1, uint64(start.Unix()), start.Add(30*time.Second))
+	observe(2, uint64(start.Unix())+1, start.Add(31*time.Second))
+	require.Equal(t, ClockSkewStatus{Threshold: clockSkewThreshold, Samples: 2}, d.status())
+	// a proposal is sampled once, when first received
+	observe(2, uint64(start.Unix())+1, start.Add(35*time.Second))
+	require.Equal(t, 2, d.status().Samples)
+
+	// the local clock is 30s ahead of the proposals
+	observe(3, uint64(start.Unix())+2, start.Add(32*time.Second))
+	status := d.status()
+	require.True(t, status.Detected)
+	require.Equal(t, 30*time.Second, status.Offset)
+
+	// the median ignores a minority of skewed proposers, and the proposals received late
+	for i := 0; i < clockSkewSamples; i++ {
+		timestamp, now := start.Add(time.Duration(i)*time.Second), start.Add(time.Duration(i)*time.Second+200*time.Millisecond)
+		switch i % 4 {
+		case 0:
+			timestamp = timestamp.Add(30 * time.Second)
+		case 1:
+			now = now.Add(10 * time.Second)
+		}
+		observe(10+i, uint64(timestamp.Unix()), now)
+	}
+	status = d.status()
+	require.False(t, status.Detected)
+	require.Equal(t, clockSkewSamples, status.Samples)
+	require.Equal(t, 200*time.Millisecond, status.Offset)
+
+	// a clock behind the other members is detected too
+	for i := 0; i < clockSkewSamples; i++ {
+		observe(100+i, uint64(start.Unix())+uint64(i)+10, start.Add(time.Duration(i)*time.Second))
+	}
+	status = d.status()
+	require.True(t, status.Detected)
+	require.Equal(t, -10*time.Second, status.Offset)
+}
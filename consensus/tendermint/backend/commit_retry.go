@@ -0,0 +1,185 @@
+package backend
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+)
+
+const (
+	// commitImportDelay is the time given to the regular import of a committed block, by the miner or the
+	// block fetcher, before the commit retry imports it.
+	commitImportDelay = 5 * time.Second
+	// commitRetryBackoff is the delay before the first retry of a failed import, doubled at each failure.
+	commitRetryBackoff = time.Second
+	// commitRetryMaxBackoff caps the delay between the retries of a failed import.
+	commitRetryMaxBackoff = time.Minute
+	// commitRetryInterval is the period the committed blocks are checked at.
+	commitRetryInterval = time.Second
+)
+
+var (
+	commitRetryCounter = metrics.NewRegisteredCounter("tendermint/commit/retries", nil)
+	commitFailingGauge = metrics.NewRegisteredGauge("tendermint/commit/failing", nil)
+)
+
+// commitImporter is the chain the committed blocks are imported into.
+type commitImporter interface {
+	HasBlock(hash common.Hash, number uint64) bool
+	InsertChain(chain types.Blocks) (int, error)
+}
+
+// CommitImportStatus reports the committed blocks awaiting their import into the chain.
+type CommitImportStatus struct {
+	// Failing is raised once the import of a committed block failed, until it succeeds.
+	Failing   bool
+	Pending   []common.Hash // committed blocks not imported yet, ordered by number
+	Failures  int           // failed imports of the pending blocks
+	LastError string        `json:",omitempty"`
+	NextRetry time.Time     `json:",omitempty"`
+}
+
+// commitRetry re-drives the import of the blocks committed by consensus. Core starts the next height only
+// once the chain head reaches the committed block, and the import of the block is asynchronous: if it fails
+// transiently, e.g. on a full disk, the node would stay behind the network while it holds the block and its
+// quorum certificate. The committed blocks are kept until the chain has them, and imported again with an
+// exponential backoff otherwise. The blocks whose import failed are persisted, to be retried after a restart.
+type commitRetry struct {
+	mu       sync.Mutex
+	pending  []*types.Block // committed blocks not imported yet, ordered by number
+	due      time.Time      // time of the next import attempt
+	backoff  time.Duration  // delay before the next attempt after a failure
+	failures int
+	lastErr  error
+
+	db     ethdb.KeyValueStore // database the blocks whose import failed are persisted to, nil if they are not
+	logger log.Logger
+}
+
+func newCommitRetry(logger log.Logger) *commitRetry {
+	return &commitRetry{backoff: commitRetryBackoff, logger: logger}
+}
+
+// setDatabase persists the blocks whose import failed to db, and restores the ones persisted before a restart.
+func (r *commitRetry) setDatabase(db ethdb.KeyValueStore, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.db = db
+	for _, block := range rawdb.ReadCommitRetryBlocks(db) {
+		r.logger.Info("Restored committed block awaiting import", "number", block.NumberU64(), "hash", block.Hash())
+		r.add(block)
+	}
+	if len(r.pending) > 0 {
+		r.due = now
+	}
+}
+
+// track keeps the committed block until it is imported into the chain.
+func (r *commitRetry) track(block *types.Block, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		r.due = now.Add(commitImportDelay)
+	}
+	r.add(block)
+}
+
+func (r *commitRetry) add(block *types.Block) {
+	for _, pending := range r.pending {
+		if pending.Hash() == block.Hash() {
+			return
+		}
+	}
+	r.pending = append(r.pending, block)
+	sort.Slice(r.pending, func(i, j int) bool { return r.pending[i].NumberU64() < r.pending[j].NumberU64() })
+}
+
+// retry forgets the committed blocks the chain has, and imports the others if due. It returns true if the
+// blocks are all imported.
+func (r *commitRetry) retry(chain commitImporter, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.forgetImported(chain)
+	if len(r.pending) == 0 || now.Before(r.due) {
+		return len(r.pending) == 0
+	}
+
+	first := r.pending[0]
+	commitRetryCounter.Inc(1)
+	if _, err := chain.InsertChain(r.pending); err != nil {
+		r.failures++
+		r.lastErr = err
+		r.due = now.Add(r.backoff)
+		r.logger.Warn("Failed to import committed block, retrying", "number", first.NumberU64(), "hash", first.Hash(),
+			"blocks", len(r.pending), "failures", r.failures, "retry", r.backoff, "err", err)
+		r.backoff = min(2*r.backoff, commitRetryMaxBackoff)
+		commitFailingGauge.Update(1)
+		if r.db != nil {
+			for _, block := range r.pending {
+				rawdb.WriteCommitRetryBlock(r.db, block)
+			}
+		}
+		return false
+	}
+	if r.failures > 0 {
+		r.logger.Info("Imported committed block after failures", "number", first.NumberU64(), "hash", first.Hash(), "failures", r.failures)
+	}
+	r.forgetImported(chain)
+	return len(r.pending) == 0
+}
+
+// forgetImported drops the pending blocks found in the chain, and resets the failures once none is left.
+func (r *commitRetry) forgetImported(chain commitImporter) {
+	pending := r.pending[:0]
+	for _, block := range r.pending {
+		if !chain.HasBlock(block.Hash(), block.NumberU64()) {
+			pending = append(pending, block)
+			continue
+		}
+		if r.db != nil {
+			rawdb.DeleteCommitRetryBlock(r.db, block.NumberU64(), block.Hash())
+		}
+	}
+	r.pending = pending
+	if len(r.pending) == 0 && r.failures > 0 {
+		r.failures, r.lastErr, r.backoff = 0, nil, commitRetryBackoff
+		commitFailingGauge.Update(0)
+	}
+}
+
+// run checks the committed blocks periodically until stopped.
+func (r *commitRetry) run(chain commitImporter, stopped <-chan struct{}) {
+	ticker := time.NewTicker(commitRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.retry(chain, time.Now())
+		case <-stopped:
+			return
+		}
+	}
+}
+
+func (r *commitRetry) status() CommitImportStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := CommitImportStatus{Failing: r.failures > 0, Failures: r.failures, Pending: make([]common.Hash, 0, len(r.pending))}
+	for _, block := range r.pending {
+		status.Pending = append(status.Pending, block.Hash())
+	}
+	if r.lastErr != nil {
+		status.LastError = r.lastErr.Error()
+	}
+	if len(r.pending) > 0 {
+		status.NextRetry = r.due
+	}
+	return status
+}
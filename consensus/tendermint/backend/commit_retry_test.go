@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/log"
+)
+
+var errDiskFull = errors.New("disk full")
+
+// failingImporter fails the first imports into the wrapped chain.
+type failingImporter struct {
+	*core.BlockChain
+	failures int
+	attempts int
+}
+
+func (f *failingImporter) InsertChain(chain types.Blocks) (int, error) {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return 0, errDiskFull
+	}
+	return f.BlockChain.InsertChain(chain)
+}
+
+// commitBlock builds a block on top of parent and commits it with a quorum certificate, without importing it.
+func commitBlock(t *testing.T, chain *core.BlockChain, backend *Backend, parent *types.Block) *types.Block {
+	block, err := makeBlockWithoutSeal(chain, backend, parent)
+	require.NoError(t, err)
+	block, err = backend.AddSeal(block)
+	require.NoError(t, err)
+
+	committedSeal := backend.Sign(message.PrepareCommittedSeal(block.Hash(), 0, block.Number()))
+	quorumCertificate := types.AggregateSignature{
+		Signature: committedSeal.(*blst.BlsSignature),
+		Signers:   types.NewSigners(len(parent.Header().Committee)),
+	}
+	quorumCertificate.Signers.Increment(&parent.Header().Committee[0])
+	require.NoError(t, backend.Commit(block, 0, quorumCertificate))
+
+	// Commit seals the block, the committed one is the one tracked for its import
+	status := backend.CommitImportStatus()
+	require.NotEmpty(t, status.Pending)
+	for _, pending := range backend.commitRetry.pending {
+		if pending.NumberU64() == block.NumberU64() {
+			return pending
+		}
+	}
+	t.Fatal("committed block not tracked")
+	return nil
+}
+
+func TestCommitRetry(t *testing.T) {
+	chain, backend := newBlockChain(1)
+	defer backend.Close()
+	block := commitBlock(t, chain, backend, chain.Genesis())
+
+	db := rawdb.NewMemoryDatabase()
+	retry := newCommitRetry(log.Root())
+	retry.setDatabase(db, time.Now())
+	importer := &failingImporter{BlockChain: chain, failures: 2}
+
+	now := time.Now()
+	retry.track(block, now)
+	// the regular import is given some time before the block is imported again
+	require.False(t, retry.retry(importer, now))
+	require.Equal(t, 0, importer.attempts)
+	require.False(t, retry.status().Failing)
+
+	now = now.Add(commitImportDelay)
+	require.False(t, retry.retry(importer, now))
+	require.Equal(t, 1, importer.attempts)
+	status := retry.status()
+	require.True(t, status.Failing)
+	require.Equal(t, []common.Hash{block.Hash()}, status.Pending)
+	require.Equal(t, errDiskFull.Error(), status.LastError)
+	require.Equal(t, now.Add(commitRetryBackoff), status.NextRetry)
+	require.Len(t, rawdb.ReadCommitRetryBlocks(db), 1)
+
+	// the block is imported again once the backoff elapsed, doubled at each failure
+	now = now.Add(commitRetryBackoff)
+	require.False(t, retry.retry(importer, now))
+	require.Equal(t, 2, importer.attempts)
+	require.Equal(t, now.Add(2*commitRetryBackoff), retry.status().NextRetry)
+	require.False(t, retry.retry(importer, now.Add(commitRetryBackoff)))
+	require.Equal(t, 2, importer.attempts)
+
+	// a restarted node retries the import of the persisted block
+	restarted := newCommitRetry(log.Root())
+	restarted.setDatabase(db, now)
+	require.Equal(t, []common.Hash{block.Hash()}, restarted.status().Pending)
+
+	now = now.Add(2 * commitRetryBackoff)
+	require.True(t, retry.retry(importer, now))
+	require.Equal(t, 3, importer.attempts)
+	require.Equal(t, block.Hash(), chain.CurrentBlock().Hash())
+	status = retry.status()
+	require.False(t, status.Failing)
+	require.Empty(t, status.Pending)
+	require.Empty(t, rawdb.ReadCommitRetryBlocks(db))
+
+	// the persisted block imported meanwhile is forgotten without being imported again
+	require.True(t, restarted.retry(importer, now))
+	require.Equal(t, 3, importer.attempts)
+
+	// consensus goes on with the next height
+	next := commitBlock(t, chain, backend, block)
+	retry.track(next, now)
+	require.True(t, retry.retry(importer, now.Add(commitImportDelay)))
+	require.Equal(t, next.Hash(), chain.CurrentBlock().Hash())
+	require.Equal(t, 0, retry.status().Failures)
+}
+
+func TestCommitRetryImportedBlock(t *testing.T) {
+	chain, backend := newBlockChain(1)
+	defer backend.Close()
+	block := commitBlock(t, chain, backend, chain.Genesis())
+
+	retry := newCommitRetry(log.Root())
+	importer := &failingImporter{BlockChain: chain}
+	now := time.Now()
+	retry.track(block, now)
+	_, err := chain.InsertChain(types.Blocks{block})
+	require.NoError(t, err)
+
+	// the block imported by the regular path is not imported again
+	require.True(t, retry.retry(importer, now.Add(commitImportDelay)))
+	require.Equal(t, 0, importer.attempts)
+	require.Empty(t, retry.status().Pending)
+}
@@ -0,0 +1,155 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/metrics"
+)
+
+var (
+	// errProposalCommittee is returned if the committee of an epoch boundary proposal built by the node
+	// disagrees with the committee of the contract state.
+	errProposalCommittee = errors.New("proposal committee disagrees with the contract state")
+
+	committeeDivergenceGauge = metrics.NewRegisteredGauge("tendermint/committee/divergence", nil)
+	refusedProposalsMeter    = metrics.NewRegisteredMeter("tendermint/committee/refused", nil)
+)
+
+// powerMismatch is a committee member whose voting power differs between two committees.
+type powerMismatch struct {
+	address common.Address
+	ours    string
+	theirs  string
+}
+
+func (m powerMismatch) String() string {
+	return fmt.Sprintf("%v: ours %s, theirs %s", m.address, m.ours, m.theirs)
+}
+
+// committeeDiff is the difference between the committee computed by the node and another one.
+type committeeDiff struct {
+	equal      bool
+	onlyOurs   []common.Address
+	onlyTheirs []common.Address
+	power      []powerMismatch
+	keys       []common.Address // members whose consensus key differs
+	reordered  bool             // the members agree but not their order
+}
+
+// diffCommittees compares the committee computed by the node with another one. The committees are equal
+// if their RLP encodings are, that is if they have the same members with the same attributes in the same
+// order.
+func diffCommittees(ours, theirs types.Committee) *committeeDiff {
+	d := &committeeDiff{equal: len(ours) == len(theirs)}
+	for i := 0; d.equal && i < len(ours); i++ {
+		d.equal = ours[i].Address == theirs[i].Address &&
+			ours[i].VotingPower.Cmp(theirs[i].VotingPower) == 0 &&
+			bytes.Equal(ours[i].ConsensusKeyBytes, theirs[i].ConsensusKeyBytes)
+	}
+	if d.equal {
+		return d
+	}
+
+	theirMembers := make(map[common.Address]*types.CommitteeMember, len(theirs))
+	for i := range theirs {
+		theirMembers[theirs[i].Address] = &theirs[i]
+	}
+	ourMembers := make(map[common.Address]struct{}, len(ours))
+	for i := range ours {
+		ourMembers[ours[i].Address] = struct{}{}
+		their, ok := theirMembers[ours[i].Address]
+		if !ok {
+			d.onlyOurs = append(d.onlyOurs, ours[i].Address)
+			continue
+		}
+		if ours[i].VotingPower.Cmp(their.VotingPower) != 0 {
+			d.power = append(d.power, powerMismatch{
+				address: ours[i].Address,
+				ours:    ours[i].VotingPower.String(),
+				theirs:  their.VotingPower.String(),
+			})
+		}
+		if !bytes.Equal(ours[i].ConsensusKeyBytes, their.ConsensusKeyBytes) {
+			d.keys = append(d.keys, ours[i].Address)
+		}
+	}
+	for i := range theirs {
+		if _, ok := ourMembers[theirs[i].Address]; !ok {
+			d.onlyTheirs = append(d.onlyTheirs, theirs[i].Address)
+		}
+	}
+	d.reordered = len(d.onlyOurs) == 0 && len(d.onlyTheirs) == 0 && len(d.power) == 0 && len(d.keys) == 0
+	return d
+}
+
+// logCtx returns the differences as key-value pairs for the logger.
+func (d *committeeDiff) logCtx() []any {
+	power := make([]string, len(d.power))
+	for i, m := range d.power {
+		power[i] = m.String()
+	}
+	return []any{
+		"onlyOurs", d.onlyOurs,
+		"onlyTheirs", d.onlyTheirs,
+		"powerMismatches", power,
+		"keyMismatches", d.keys,
+		"reordered", d.reordered,
+	}
+}
+
+// checkProposalCommittee re-derives the committee from the contract state at the end of the proposal
+// built by the node, and refuses to propose if the proposal is at an epoch boundary and its committee
+// disagrees with it. Such a proposal could not be verified by the other committee members.
+func (sb *Backend) checkProposalCommittee(header *types.Header, statedb *state.StateDB) error {
+	contracts := sb.blockchain.ProtocolContracts()
+	lastEpochBlock, err := contracts.LastEpochBlock(header, statedb)
+	if err != nil {
+		return err
+	}
+	if lastEpochBlock.Cmp(header.Number) != 0 {
+		return nil
+	}
+	committee, err := contracts.Committee(header, statedb)
+	if err != nil {
+		return err
+	}
+	if diff := diffCommittees(committee, header.Committee); !diff.equal {
+		refusedProposalsMeter.Mark(1)
+		sb.logger.Error("CRITICAL: refusing to propose, the committee of the epoch boundary proposal disagrees with the contract state",
+			append([]any{"number", header.Number}, diff.logCtx()...)...)
+		return errProposalCommittee
+	}
+	return nil
+}
+
+// verifyProposalCommittee checks the committee of a proposal against the committee computed by the node.
+// A disagreement implies that the state of the node diverged from the proposer's one, it raises the
+// committee divergence flag.
+func (sb *Backend) verifyProposalCommittee(header *types.Header, committee types.Committee) error {
+	diff := diffCommittees(committee, header.Committee)
+	if diff.equal {
+		return nil
+	}
+	sb.committeeDivergence.Store(header.Number.Uint64())
+	committeeDivergenceGauge.Update(header.Number.Int64())
+	sb.logger.Error("Proposal committee disagrees with the computed committee, the state diverged from the proposer's one",
+		append([]any{
+			"number", header.Number,
+			"proposer", header.Coinbase,
+			"ourSize", len(committee),
+			"theirSize", len(header.Committee),
+		}, diff.logCtx()...)...)
+	return consensus.ErrInconsistentCommitteeSet
+}
+
+// CommitteeDivergence returns the last height whose proposal committee disagreed with the committee computed
+// by the node, zero if none did.
+func (sb *Backend) CommitteeDivergence() uint64 {
+	return sb.committeeDivergence.Load()
+}
@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/log"
+)
+
+func TestProposalCommitteeChecks(t *testing.T) {
+	// the blocks are built one second apart, faster than the local time goes
+	var blockchain *core.BlockChain
+	defer func(wall func() time.Time) { now = wall }(now)
+	now = func() time.Time {
+		if blockchain == nil {
+			return time.Unix(1, 0)
+		}
+		return time.Unix(int64(blockchain.CurrentHeader().Time)+1, 0)
+	}
+
+	blockchain, backend := newBlockChain(1)
+	epochPeriod := blockchain.Config().AutonityContractConfig.EpochPeriod
+
+	// build the chain up to the first epoch boundary
+	parent := blockchain.Genesis()
+	for parent.NumberU64() < epochPeriod {
+		parent = insertSealedBlock(t, blockchain, backend, parent)
+	}
+	boundary := parent
+	beforeBoundary := blockchain.GetBlockByNumber(epochPeriod - 1)
+
+	corrupt := func(block *types.Block) (*types.Header, common.Address) {
+		// the corrupted proposal is not finalized yet
+		header := types.CopyHeader(block.Header())
+		header.QuorumCertificate = types.AggregateSignature{}
+		extra := common.Address{0xaa}
+		header.Committee = append(types.Committee{}, block.Header().Committee...)
+		header.Committee[0].VotingPower = new(big.Int).Mul(header.Committee[0].VotingPower, common.Big2)
+		header.Committee = append(header.Committee, types.CommitteeMember{
+			Address:           extra,
+			VotingPower:       common.Big1,
+			ConsensusKeyBytes: header.Committee[0].ConsensusKeyBytes,
+			ConsensusKey:      header.Committee[0].ConsensusKey,
+		})
+		return header, extra
+	}
+
+	t.Run("proposer refuses an epoch boundary proposal with a corrupted committee", func(t *testing.T) {
+		statedb, err := blockchain.StateAt(boundary.Root())
+		require.NoError(t, err)
+		require.NoError(t, backend.checkProposalCommittee(boundary.Header(), statedb))
+
+		header, _ := corrupt(boundary)
+		require.ErrorIs(t, backend.checkProposalCommittee(header, statedb), errProposalCommittee)
+
+		// the committee is only re-derived at the epoch boundaries
+		statedb, err = blockchain.StateAt(beforeBoundary.Root())
+		require.NoError(t, err)
+		header, _ = corrupt(beforeBoundary)
+		require.NoError(t, backend.checkProposalCommittee(header, statedb))
+	})
+
+	t.Run("receiver logs the committee diff and raises the divergence flag", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			records []*log.Record
+		)
+		logger := log.New()
+		logger.SetHandler(log.FuncHandler(func(r *log.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, r)
+			return nil
+		}))
+		previous := backend.logger
+		backend.logger = logger
+		t.Cleanup(func() { backend.logger = previous })
+
+		require.Zero(t, backend.CommitteeDivergence())
+		header, extra := corrupt(boundary)
+		proposal, err := backend.AddSeal(types.NewBlockWithHeader(header).WithBody(boundary.Transactions(), nil))
+		require.NoError(t, err)
+		_, err = backend.VerifyProposal(proposal)
+		require.ErrorIs(t, err, consensus.ErrInconsistentCommitteeSet)
+		require.Equal(t, epochPeriod, backend.CommitteeDivergence())
+
+		mu.Lock()
+		defer mu.Unlock()
+		var ctx map[string]any
+		for _, r := range records {
+			if r.Lvl == log.LvlError && len(r.Ctx) > 0 {
+				ctx = make(map[string]any)
+				for i := 0; i+1 < len(r.Ctx); i += 2 {
+					ctx[r.Ctx[i].(string)] = r.Ctx[i+1]
+				}
+			}
+		}
+		require.NotNil(t, ctx)
+		require.Equal(t, []common.Address{extra}, ctx["onlyTheirs"])
+		require.Empty(t, ctx["onlyOurs"])
+		require.Len(t, ctx["powerMismatches"], 1)
+		require.Equal(t, false, ctx["reordered"])
+	})
+}
+
+func TestDiffCommittees(t *testing.T) {
+	member := func(address byte, power int64) types.CommitteeMember {
+		return types.CommitteeMember{Address: common.Address{address}, VotingPower: big.NewInt(power), ConsensusKeyBytes: []byte{address}}
+	}
+	ours := types.Committee{member(1, 10), member(2, 20), member(3, 30)}
+
+	require.True(t, diffCommittees(ours, types.Committee{member(1, 10), member(2, 20), member(3, 30)}).equal)
+
+	diff := diffCommittees(ours, types.Committee{member(2, 20), member(1, 10), member(3, 30)})
+	require.False(t, diff.equal)
+	require.True(t, diff.reordered)
+
+	diff = diffCommittees(ours, types.Committee{member(1, 10), member(2, 21), member(4, 40)})
+	require.False(t, diff.equal)
+	require.False(t, diff.reordered)
+	require.Equal(t, []common.Address{{3}}, diff.onlyOurs)
+	require.Equal(t, []common.Address{{4}}, diff.onlyTheirs)
+	require.Equal(t, []powerMismatch{{address: common.Address{2}, ours: "20", theirs: "21"}}, diff.power)
+
+	changedKey := member(1, 10)
+	changedKey.ConsensusKeyBytes = []byte{0xff}
+	diff = diffCommittees(ours, types.Committee{changedKey, member(2, 20), member(3, 30)})
+	require.Equal(t, []common.Address{{1}}, diff.keys)
+}
+
+// insertSealedBlock builds a block on top of parent and inserts it with a quorum certificate.
+func insertSealedBlock(t *testing.T, chain *core.BlockChain, backend *Backend, parent *types.Block) *types.Block {
+	block, err := makeBlockWithoutSeal(chain, backend, parent)
+	require.NoError(t, err)
+	block, err = backend.AddSeal(block)
+	require.NoError(t, err)
+
+	committedSeal := backend.Sign(message.PrepareCommittedSeal(block.Hash(), 0, block.Number()))
+	quorumCertificate := types.AggregateSignature{
+		Signature: committedSeal.(*blst.BlsSignature),
+		Signers:   types.NewSigners(len(parent.Header().Committee)),
+	}
+	quorumCertificate.Signers.Increment(&parent.Header().Committee[0])
+	header := block.Header()
+	require.NoError(t, types.WriteQuorumCertificate(header, quorumCertificate))
+	block = block.WithSeal(header)
+
+	_, err = chain.InsertChain(types.Blocks{block})
+	require.NoError(t, err)
+	return block
+}
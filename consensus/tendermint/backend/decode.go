@@ -0,0 +1,136 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	tendermintCore "github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/rlp"
+)
+
+// errUnknownMessageCode is returned when a payload does not start with the code of a consensus message.
+var errUnknownMessageCode = constants.NewError(constants.ClassDecode, "unknown consensus message code")
+
+// DecodedPayload is the interpretation of a consensus message payload captured outside of the node. The
+// message fields are empty if the payload could not be decoded.
+type DecodedPayload struct {
+	Type       string `json:",omitempty"` // proposal, prevote or precommit
+	Height     uint64
+	Round      int64
+	ValidRound *int64      `json:",omitempty"` // for a proposal only, -1 if nil
+	Value      common.Hash `json:",omitempty"` // block proposed or voted for
+	Hash       common.Hash `json:",omitempty"` // hash of the payload, the message identifier in the gossip
+	// Signers are the proposer, or the committee members aggregated in the vote. The BLS signatures are not
+	// recoverable: a proposal carries the address of its signer and a vote the indexes of its signers in the
+	// committee, the signature is then verified against their consensus keys.
+	Signers []common.Address `json:",omitempty"`
+	// CommitteeHeight is the height whose committee the signature was verified against.
+	CommitteeHeight uint64 `json:",omitempty"`
+	SignatureValid  bool
+	// Known is raised if the local MsgStore holds the message.
+	Known bool
+	// ErrorClass and Error are the class, as for the messages received from the peers, and the reason of the
+	// failure to decode or verify the message.
+	ErrorClass string `json:",omitempty"`
+	Error      string `json:",omitempty"`
+}
+
+// DecodeConsensusPayload decodes a consensus message payload, as sent on the wire, and verifies its
+// signature against the committee of the given height, or of the current height if zero. Nothing is
+// handed over to consensus: the message is neither processed nor gossiped, nor marked as known.
+func (sb *Backend) DecodeConsensusPayload(payload []byte, height uint64) (*DecodedPayload, error) {
+	var header *types.Header
+	if height == 0 {
+		header = sb.BlockChain().CurrentHeader()
+		height = header.Number.Uint64() + 1
+	} else {
+		// the committee of a height is the one of its parent block
+		header = sb.BlockChain().GetHeaderByNumber(height - 1)
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+
+	result := &DecodedPayload{CommitteeHeight: height}
+	msg, err := decodeConsensusPayload(payload)
+	if err != nil {
+		return result.fail(constants.WrapError(constants.ClassDecode, err)), nil
+	}
+	result.Type, result.Height, result.Round, result.Value, result.Hash = messageType(msg), msg.H(), msg.R(), msg.Value(), msg.Hash()
+	result.Known = sb.MsgStore != nil && sb.MsgStore.Has(msg)
+
+	switch m := msg.(type) {
+	case *message.Propose:
+		validRound := m.ValidRound()
+		result.ValidRound = &validRound
+		result.Signers = []common.Address{m.Signer()}
+	case message.Vote:
+		// the signers are validated against the committee size before their addresses are read
+		if err := m.Signers().Validate(len(header.Committee)); err == nil {
+			for _, index := range m.Signers().FlattenUniq() {
+				result.Signers = append(result.Signers, header.Committee[index].Address)
+			}
+		}
+	}
+
+	if err := tendermintCore.CheckMessageBounds(msg); err != nil {
+		return result.fail(err), nil
+	}
+	if err := msg.PreValidate(header); err != nil {
+		return result.fail(constants.WrapError(constants.ClassInvalid, err)), nil
+	}
+	if err := msg.Validate(); err != nil {
+		return result.fail(err), nil
+	}
+	result.SignatureValid = true
+	return result, nil
+}
+
+// fail records the failure to decode or verify the message.
+func (p *DecodedPayload) fail(err error) *DecodedPayload {
+	p.ErrorClass = constants.ClassOf(err).String()
+	p.Error = err.Error()
+	return p
+}
+
+// decodeConsensusPayload decodes the payload into the consensus message of the code it starts with.
+func decodeConsensusPayload(payload []byte) (message.Msg, error) {
+	content, _, err := rlp.SplitList(payload)
+	if err != nil {
+		return nil, err
+	}
+	code, _, err := rlp.SplitUint64(content)
+	if err != nil {
+		return nil, err
+	}
+	var msg message.Msg
+	switch code {
+	case uint64(message.ProposalCode):
+		msg = new(message.Propose)
+	case uint64(message.PrevoteCode):
+		msg = new(message.Prevote)
+	case uint64(message.PrecommitCode):
+		msg = new(message.Precommit)
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnknownMessageCode, code)
+	}
+	if err := rlp.DecodeBytes(payload, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func messageType(msg message.Msg) string {
+	switch msg.Code() {
+	case message.ProposalCode:
+		return "proposal"
+	case message.PrevoteCode:
+		return "prevote"
+	case message.PrecommitCode:
+		return "precommit"
+	}
+	return "unknown"
+}
@@ -0,0 +1,114 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	tdmcore "github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/rlp"
+)
+
+func TestDecodeConsensusPayload(t *testing.T) {
+	chain, engine := newBlockChain(4)
+	engine.MsgStore = tdmcore.NewMsgStore()
+	genesis := chain.Genesis().Header()
+	committee, self := genesis.Committee, genesis.CommitteeMember(engine.address)
+	block, err := makeBlockWithoutSeal(chain, engine, chain.Genesis())
+	require.NoError(t, err)
+
+	t.Run("valid proposal", func(t *testing.T) {
+		proposal := message.NewPropose(0, 1, -1, block, engine.Sign, self)
+		decoded, err := engine.DecodeConsensusPayload(proposal.Payload(), 0)
+		require.NoError(t, err)
+		validRound := int64(-1)
+		require.Equal(t, &DecodedPayload{
+			Type:            "proposal",
+			Height:          1,
+			ValidRound:      &validRound,
+			Value:           block.Hash(),
+			Hash:            proposal.Hash(),
+			Signers:         []common.Address{engine.address},
+			CommitteeHeight: 1,
+			SignatureValid:  true,
+		}, decoded)
+		// the payload is not marked as known to the gossip
+		require.False(t, engine.knownMessages.Contains(proposal.Hash()))
+
+		engine.MsgStore.Save(proposal)
+		decoded, err = engine.DecodeConsensusPayload(proposal.Payload(), 1)
+		require.NoError(t, err)
+		require.True(t, decoded.Known)
+	})
+
+	t.Run("valid vote", func(t *testing.T) {
+		prevote := message.NewPrevote(2, 1, block.Hash(), engine.Sign, self, len(committee))
+		decoded, err := engine.DecodeConsensusPayload(prevote.Payload(), 1)
+		require.NoError(t, err)
+		require.Equal(t, "prevote", decoded.Type)
+		require.Equal(t, int64(2), decoded.Round)
+		require.Nil(t, decoded.ValidRound)
+		require.Equal(t, []common.Address{engine.address}, decoded.Signers)
+		require.True(t, decoded.SignatureValid)
+		require.False(t, decoded.Known)
+		require.Empty(t, decoded.ErrorClass)
+	})
+
+	t.Run("tampered signature", func(t *testing.T) {
+		key, err := blst.RandKey()
+		require.NoError(t, err)
+		precommit := message.NewPrecommit(0, 1, block.Hash(), makeSigner(key), self, len(committee))
+		decoded, err := engine.DecodeConsensusPayload(precommit.Payload(), 1)
+		require.NoError(t, err)
+		require.Equal(t, "precommit", decoded.Type)
+		require.Equal(t, []common.Address{engine.address}, decoded.Signers)
+		require.False(t, decoded.SignatureValid)
+		require.Equal(t, constants.ClassBadSignature.String(), decoded.ErrorClass)
+	})
+
+	t.Run("unknown sender", func(t *testing.T) {
+		key, err := blst.RandKey()
+		require.NoError(t, err)
+		nodeKey, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		outsider := &types.CommitteeMember{Address: crypto.PubkeyToAddress(nodeKey.PublicKey), VotingPower: common.Big1, ConsensusKey: key.PublicKey()}
+		proposal := message.NewPropose(0, 1, -1, block, makeSigner(key), outsider)
+		decoded, err := engine.DecodeConsensusPayload(proposal.Payload(), 1)
+		require.NoError(t, err)
+		require.Equal(t, []common.Address{outsider.Address}, decoded.Signers)
+		require.False(t, decoded.SignatureValid)
+		require.Equal(t, constants.ClassNonCommittee.String(), decoded.ErrorClass)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		unknownCode, err := rlp.EncodeToBytes([]any{uint8(7), uint64(1)})
+		require.NoError(t, err)
+		proposal := message.NewPropose(0, 1, -1, block, engine.Sign, self)
+		for _, payload := range [][]byte{nil, {0x01, 0x02}, unknownCode, proposal.Payload()[:len(proposal.Payload())/2]} {
+			decoded, err := engine.DecodeConsensusPayload(payload, 1)
+			require.NoError(t, err)
+			require.Equal(t, &DecodedPayload{CommitteeHeight: 1, ErrorClass: constants.ClassDecode.String(), Error: decoded.Error}, decoded)
+			require.NotEmpty(t, decoded.Error)
+		}
+	})
+
+	t.Run("out of bounds", func(t *testing.T) {
+		prevote := message.NewPrevote(constants.MaxRound+1, 1, block.Hash(), engine.Sign, self, len(committee))
+		decoded, err := engine.DecodeConsensusPayload(prevote.Payload(), 1)
+		require.NoError(t, err)
+		require.False(t, decoded.SignatureValid)
+		require.Equal(t, constants.ClassInvalid.String(), decoded.ErrorClass)
+	})
+
+	t.Run("unknown height", func(t *testing.T) {
+		prevote := message.NewPrevote(0, 1, block.Hash(), engine.Sign, self, len(committee))
+		_, err := engine.DecodeConsensusPayload(prevote.Payload(), 10)
+		require.ErrorIs(t, err, errUnknownBlock)
+	})
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/misc"
-	"github.com/autonity/autonity/consensus/tendermint/bft"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/consensus/tendermint/events"
@@ -19,7 +18,6 @@ import (
 	"github.com/autonity/autonity/core/state"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto"
-	"github.com/autonity/autonity/crypto/blst"
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/metrics"
 	"github.com/autonity/autonity/params"
@@ -81,13 +79,13 @@ func (sb *Backend) VerifyHeader(chain consensus.ChainHeaderReader, header *types
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
-	return sb.verifyHeader(chain, header, parent)
+	return sb.verifyHeader(chain, header, parent, true)
 }
 
 // verifyHeader checks whether a header conforms to the consensus rules. It
 // expects the parent header to be provided unless header is the genesis
-// header.
-func (sb *Backend) verifyHeader(chain consensus.ChainHeaderReader, header, parent *types.Header) error {
+// header. The quorum certificate is verified only if certificate is set.
+func (sb *Backend) verifyHeader(chain consensus.ChainHeaderReader, header, parent *types.Header, certificate bool) error {
 	if header.Number == nil {
 		return errUnknownBlock
 	}
@@ -96,7 +94,7 @@ func (sb *Backend) verifyHeader(chain consensus.ChainHeaderReader, header, paren
 	}
 	// Don't waste time checking blocks from the future
 
-	if header.Time > uint64(now().Unix()+allowedFutureBlockTimeSeconds) {
+	if sb.futureBlock(header) {
 		return consensus.ErrFutureTimestampBlock
 	}
 
@@ -140,11 +138,12 @@ func (sb *Backend) verifyHeader(chain consensus.ChainHeaderReader, header, paren
 	if parent == nil {
 		return errUnknownBlock
 	}
-	return sb.verifyHeaderAgainstParent(header, parent)
+	return sb.verifyHeaderAgainstParent(header, parent, certificate)
 }
 
-// verifyHeaderAgainstParent verifies that the given header is valid with respect to its parent.
-func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header) error {
+// verifyHeaderAgainstParent verifies that the given header is valid with respect to its parent, and its
+// quorum certificate if certificate is set.
+func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header, certificate bool) error {
 	if parent.Number.Uint64() != header.Number.Uint64()-1 || parent.Hash() != header.ParentHash {
 		return consensus.ErrUnknownAncestor
 	}
@@ -155,27 +154,38 @@ func (sb *Backend) verifyHeaderAgainstParent(header, parent *types.Header) error
 	if err := sb.verifySigner(header, parent); err != nil {
 		return err
 	}
-
+	if !certificate {
+		return nil
+	}
 	return sb.verifyQuorumCertificate(header, parent)
 }
 
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers
 // concurrently. The method returns a quit channel to abort the operations and
 // a results channel to retrieve the async verifications (the order is that of
-// the input slice).
+// the input slice). The quorum certificates of the batch are verified at once.
 func (sb *Backend) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
 	abort := make(chan struct{}, 1)
 	results := make(chan error, len(headers))
 	go func() {
+		parents := make([]*types.Header, len(headers))
 		for i, header := range headers {
-			var parent *types.Header
 			switch {
 			case i > 0:
-				parent = headers[i-1]
+				parents[i] = headers[i-1]
 			case i == 0:
-				parent = chain.GetHeaderByHash(header.ParentHash)
+				parents[i] = chain.GetHeaderByHash(header.ParentHash)
+			}
+		}
+		certificates := sb.verifyQuorumCertificates(headers, parents)
+		for i, header := range headers {
+			err := consensus.ErrUnknownAncestor
+			if parents[i] != nil {
+				err = sb.verifyHeader(chain, header, parents[i], false)
+			}
+			if err == nil {
+				err = certificates[i]
 			}
-			err := sb.verifyHeader(chain, header, parent)
 			select {
 			case <-abort:
 				return
@@ -215,51 +225,40 @@ func (sb *Backend) verifySigner(header, parent *types.Header) error {
 // verifyQuorumCertificate validates that the quorum certificate for header come from
 // committee members and that the voting power constitute a quorum.
 func (sb *Backend) verifyQuorumCertificate(header, parent *types.Header) error {
-	// un-finalized proposals will have these fields set to nil
-	if header.QuorumCertificate.Signature == nil || header.QuorumCertificate.Signers == nil {
-		return types.ErrEmptyQuorumCertificate
-	}
-	quorumCertificate := header.QuorumCertificate.Copy() // copy so that we do not modify the header when doing Signers.Validate()
-	if err := quorumCertificate.Signers.Validate(len(parent.Committee)); err != nil {
-		return fmt.Errorf("Invalid quorum certificate signers information: %w", err)
-	}
-
-	// Calculate total voting power of committee
-	committeeVotingPower := new(big.Int)
-	for _, member := range parent.Committee {
-		committeeVotingPower.Add(committeeVotingPower, member.VotingPower)
-	}
-
-	// The data that was signed over for this block
-	headerSeal := message.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
-
-	// Total Voting power for this block
-	power := new(big.Int)
-	for _, index := range quorumCertificate.Signers.FlattenUniq() {
-		power.Add(power, parent.Committee[index].VotingPower)
-	}
-
-	// verify signature
-	var keys [][]byte //nolint
-	for _, index := range quorumCertificate.Signers.Flatten() {
-		keys = append(keys, parent.Committee[index].ConsensusKeyBytes)
-	}
-	aggregatedKey, err := blst.AggregatePublicKeys(keys)
-	if err != nil {
-		sb.logger.Crit("Failed to aggregate keys from committee members", "err", err)
-	}
-	valid := quorumCertificate.Signature.Verify(aggregatedKey, headerSeal[:])
-	if !valid {
+	_, _, err := message.VerifyQuorumCertificate(header.QuorumCertificate, header.Hash(), int64(header.Round), header.Number, parent.Committee)
+	if errors.Is(err, message.ErrQuorumCertificateSignature) {
 		sb.logger.Error("block had invalid committed seal")
-		return types.ErrInvalidQuorumCertificate
 	}
+	return err
+}
 
-	// We need at least a quorum for the block to be considered valid
-	if power.Cmp(bft.Quorum(committeeVotingPower)) < 0 {
-		return types.ErrInvalidQuorumCertificate
+// verifyQuorumCertificates verifies the quorum certificates of the headers against the committees of their
+// parents in a batch, and returns the error of each of them. Those of the genesis, or of unknown parent, are
+// not verified.
+func (sb *Backend) verifyQuorumCertificates(headers, parents []*types.Header) []error {
+	blocks := make([]message.CertifiedBlock, 0, len(headers))
+	indexes := make([]int, 0, len(headers))
+	for i, header := range headers {
+		if header.IsGenesis() || parents[i] == nil {
+			continue
+		}
+		blocks = append(blocks, message.CertifiedBlock{
+			Certificate: header.QuorumCertificate,
+			Hash:        header.Hash(),
+			Round:       int64(header.Round),
+			Height:      header.Number,
+			Committee:   parents[i].Committee,
+		})
+		indexes = append(indexes, i)
+	}
+	errs := make([]error, len(headers))
+	for i, err := range message.VerifyQuorumCertificates(blocks) {
+		if errors.Is(err, message.ErrQuorumCertificateSignature) {
+			sb.logger.Error("block had invalid committed seal", "number", headers[indexes[i]].Number)
+		}
+		errs[indexes[i]] = err
 	}
-
-	return nil
+	return errs
 }
 
 // Prepare initializes the consensus fields of a block header according to the
@@ -282,8 +281,8 @@ func (sb *Backend) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 	// set header's timestamp
 	// todo: block period from contract
 	header.Time = parent.Time + 1
-	if int64(header.Time) < time.Now().Unix() {
-		header.Time = uint64(time.Now().Unix())
+	if now := sb.clock.Now().Unix(); int64(header.Time) < now {
+		header.Time = uint64(now)
 	}
 	return nil
 }
@@ -318,6 +317,9 @@ func (sb *Backend) FinalizeAndAssemble(chain consensus.ChainReader, header *type
 
 	// add committee to extraData's committee section
 	header.Committee = committeeSet
+	if err := sb.checkProposalCommittee(header, statedb.Copy()); err != nil {
+		return nil, err
+	}
 	return types.NewBlock(header, txs, nil, *receipts, new(trie.Trie)), nil
 }
 
@@ -361,8 +363,14 @@ func (sb *Backend) Seal(chain consensus.ChainReader, block *types.Block, _ chan<
 		return err
 	}
 
+	// refuse to propose a block that the other validators would reject
+	if size, limit := uint64(block.Size()), chain.Config().ProposalSizeLimit(header.GasLimit); size > limit {
+		sb.logger.Error("Refusing to propose oversized block", "number", header.Number, "size", size, "limit", limit)
+		return constants.ErrOversizedProposal
+	}
+
 	// wait for the timestamp of header, use this to adjust the block period
-	delay := time.Unix(int64(block.Header().Time), 0).Sub(now())
+	delay := sb.untilTimestamp(block.Header())
 	if metrics.Enabled {
 		sealDelayBg.Add(delay.Nanoseconds())
 	}
@@ -428,6 +436,10 @@ func (sb *Backend) APIs(chain consensus.ChainReader) []rpc.API {
 		Version:   "1.0",
 		Service:   &API{chain: chain, tendermint: sb, getCommittee: getCommittee},
 		Public:    true,
+	}, {
+		Namespace: "debug",
+		Version:   "1.0",
+		Service:   &DebugAPI{tendermint: sb},
 	}}
 }
 
@@ -446,6 +458,11 @@ func (sb *Backend) Start(ctx context.Context) error {
 	if !sb.coreStarting.CompareAndSwap(false, true) {
 		return ErrStartedEngine
 	}
+	if sb.blockchain == nil {
+		sb.coreStarting.Store(false)
+		sb.logger.Error("Consensus engine started before being attached to the chain")
+		return consensus.ErrEngineNotAttached
+	}
 
 	sb.stopped = make(chan struct{})
 	sb.UpdateStopChannel(sb.stopped)
@@ -455,6 +472,24 @@ func (sb *Backend) Start(ctx context.Context) error {
 	sb.wg.Add(1)
 	go sb.faultyValidatorsWatcher(ctx)
 
+	sb.wg.Add(1)
+	go func() {
+		defer sb.wg.Done()
+		sb.inbound.run(sb.stopped, sb.eventMux.Post)
+	}()
+
+	sb.wg.Add(1)
+	go func() {
+		defer sb.wg.Done()
+		sb.commitRetry.run(sb.blockchain, sb.stopped)
+	}()
+
+	sb.wg.Add(1)
+	go func() {
+		defer sb.wg.Done()
+		sb.liveness.run(sb.blockchain, sb.stopped)
+	}()
+
 	// Start Tendermint
 	sb.aggregator.start(ctx)
 	sb.core.Start(ctx, sb.blockchain.ProtocolContracts())
@@ -474,20 +509,41 @@ func (sb *Backend) Close() error {
 	close(sb.stopped)
 	// Stop Tendermint
 	sb.aggregator.stop()
-	sb.core.Stop()
+	err := sb.core.Stop()
 	sb.wg.Wait()
+	sb.inbound.clear()
 	sb.coreStarting.CompareAndSwap(true, false)
-	return nil
+	return err
 }
 
 func (sb *Backend) SealHash(header *types.Header) common.Hash {
 	return types.SigHash(header)
 }
 
-func (sb *Backend) SetBlockchain(bc *core.BlockChain) {
-	sb.blockchain = bc
-	sb.currentBlock = bc.CurrentBlock
-	sb.hasBadBlock = bc.HasBadBlock
+// Attach binds the engine to the chain it seals and verifies blocks for. It must be called once,
+// after the chain is created and before the engine is started or handles any consensus message.
+func (sb *Backend) Attach(chain *core.BlockChain, contracts *autonity.ProtocolContracts) error {
+	if chain == nil || contracts == nil {
+		return consensus.ErrEngineNotAttached
+	}
+	if sb.blockchain != nil {
+		return consensus.ErrEngineAttached
+	}
+	sb.blockchain = chain
+	sb.currentBlock = chain.CurrentBlock
+	sb.hasBadBlock = chain.HasBadBlock
+	close(sb.readyCh())
+	return nil
+}
+
+// Ready returns a channel closed once the engine is attached to its chain.
+func (sb *Backend) Ready() <-chan struct{} {
+	return sb.readyCh()
+}
+
+func (sb *Backend) readyCh() chan struct{} {
+	sb.readyOnce.Do(func() { sb.ready = make(chan struct{}) })
+	return sb.ready
 }
 
 func (sb *Backend) faultyValidatorsWatcher(ctx context.Context) {
@@ -9,12 +9,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/hexutil"
 	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto/blst"
@@ -104,6 +107,35 @@ func TestSealCommitted(t *testing.T) {
 	}
 }
 
+func TestSealOversizedProposal(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	block, err := makeBlockWithoutSeal(chain, engine, chain.Genesis())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBlock, _ := engine.AddSeal(block)
+	resultCh := make(chan *types.Block)
+	engine.SetResultChan(resultCh)
+
+	// just over the limit, the proposer refuses to propose the block
+	setMaxProposalSize(t, chain.Config(), uint64(expectedBlock.Size())-1)
+	err = engine.Seal(chain, block, resultCh, nil)
+	if !errors.Is(err, constants.ErrOversizedProposal) {
+		t.Fatalf("error mismatch: have %v, want %v", err, constants.ErrOversizedProposal)
+	}
+
+	// just under the limit, the block is proposed and committed
+	setMaxProposalSize(t, chain.Config(), uint64(expectedBlock.Size()))
+	err = engine.Seal(chain, block, resultCh, nil)
+	if err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	finalBlock := <-resultCh
+	if finalBlock.Hash() != expectedBlock.Hash() {
+		t.Errorf("hash mismatch: have %v, want %v", finalBlock.Hash(), expectedBlock.Hash())
+	}
+}
+
 func TestVerifyHeader(t *testing.T) {
 	chain, engine := newBlockChain(1)
 
@@ -251,6 +283,47 @@ OUT1:
 }
 
 // The logic of this needs to change with respect of Autonity contact
+func TestVerifyHeadersQuorumCertificates(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	committee := chain.Genesis().Header().Committee
+
+	var headers []*types.Header
+	parent := chain.Genesis()
+	for i := 0; i < 4; i++ {
+		block, err := makeBlockWithoutSeal(chain, engine, parent)
+		require.NoError(t, err)
+		block, err = engine.AddSeal(block)
+		require.NoError(t, err)
+		header := block.Header()
+		seal := engine.Sign(message.PrepareCommittedSeal(header.Hash(), 0, header.Number))
+		certificate := types.AggregateSignature{Signature: seal.(*blst.BlsSignature), Signers: types.NewSigners(len(committee))}
+		certificate.Signers.Increment(&committee[0])
+		require.NoError(t, types.WriteQuorumCertificate(header, certificate))
+		headers = append(headers, header)
+		parent = block.WithSeal(header)
+	}
+	now = func() time.Time {
+		return time.Unix(int64(headers[len(headers)-1].Time), 0)
+	}
+	// the certificate of another block is a valid signature, of another seal
+	headers[2].QuorumCertificate.Signature = headers[1].QuorumCertificate.Signature
+	headers[3].QuorumCertificate.Signers = types.NewSigners(len(committee))
+
+	_, results := engine.VerifyHeaders(chain, headers, nil)
+	for i, want := range []error{nil, nil, message.ErrQuorumCertificateSignature, types.ErrEmptySigners} {
+		select {
+		case err := <-results:
+			if want == nil {
+				require.NoError(t, err, "header %d", i)
+			} else {
+				require.ErrorIs(t, err, want, "header %d", i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout verifying header %d", i)
+		}
+	}
+}
+
 func TestVerifyHeadersAbortValidation(t *testing.T) {
 	chain, engine := newBlockChain(1)
 
@@ -449,6 +522,7 @@ func TestClose(t *testing.T) {
 			core:       tendermintC,
 			aggregator: fakeAggregator(),
 			stopped:    make(chan struct{}),
+			inbound:    newInboundQueue(),
 		}
 		b.coreStarting.Store(true)
 		b.coreRunning.Store(true)
@@ -469,6 +543,7 @@ func TestClose(t *testing.T) {
 			core:       tendermintC,
 			aggregator: fakeAggregator(),
 			stopped:    make(chan struct{}),
+			inbound:    newInboundQueue(),
 		}
 		b.coreStarting.Store(true)
 		b.coreRunning.Store(true)
@@ -493,6 +568,7 @@ func TestClose(t *testing.T) {
 			core:       tendermintC,
 			aggregator: fakeAggregator(),
 			stopped:    make(chan struct{}),
+			inbound:    newInboundQueue(),
 		}
 		b.coreStarting.Store(true)
 		b.coreRunning.Store(true)
@@ -545,10 +621,14 @@ func TestStart(t *testing.T) {
 		g.EXPECT().UpdateStopChannel(gomock.Any())
 
 		b := &Backend{
-			core:       tendermintC,
-			gossiper:   g,
-			blockchain: chain,
-			eventMux:   event.NewTypeMuxSilent(nil, log.Root()),
+			core:        tendermintC,
+			gossiper:    g,
+			blockchain:  chain,
+			eventMux:    event.NewTypeMuxSilent(nil, log.Root()),
+			inbound:     newInboundQueue(),
+			commitRetry: newCommitRetry(log.Root()),
+			liveness:    newLivenessWatchdog(log.Root()),
+			skew:        newSkewDetector(log.Root()),
 		}
 		b.aggregator = &aggregator{logger: log.Root(), backend: b, core: tendermintC}
 
@@ -580,10 +660,14 @@ func TestStart(t *testing.T) {
 		g.EXPECT().UpdateStopChannel(gomock.Any())
 
 		b := &Backend{
-			core:       tendermintC,
-			gossiper:   g,
-			blockchain: chain,
-			eventMux:   event.NewTypeMuxSilent(nil, log.Root()),
+			core:        tendermintC,
+			gossiper:    g,
+			blockchain:  chain,
+			eventMux:    event.NewTypeMuxSilent(nil, log.Root()),
+			inbound:     newInboundQueue(),
+			commitRetry: newCommitRetry(log.Root()),
+			liveness:    newLivenessWatchdog(log.Root()),
+			skew:        newSkewDetector(log.Root()),
 		}
 		b.aggregator = &aggregator{logger: log.Root(), backend: b, core: tendermintC}
 		b.coreStarting.Store(false)
@@ -609,10 +693,14 @@ func TestStart(t *testing.T) {
 		g.EXPECT().UpdateStopChannel(gomock.Any())
 
 		b := &Backend{
-			core:       tendermintC,
-			gossiper:   g,
-			blockchain: chain,
-			eventMux:   event.NewTypeMuxSilent(nil, log.Root()),
+			core:        tendermintC,
+			gossiper:    g,
+			blockchain:  chain,
+			eventMux:    event.NewTypeMuxSilent(nil, log.Root()),
+			inbound:     newInboundQueue(),
+			commitRetry: newCommitRetry(log.Root()),
+			liveness:    newLivenessWatchdog(log.Root()),
+			skew:        newSkewDetector(log.Root()),
 		}
 		b.aggregator = &aggregator{logger: log.Root(), backend: b, core: tendermintC}
 		b.coreStarting.Store(false)
@@ -667,10 +755,14 @@ func TestMultipleRestart(t *testing.T) {
 	g.EXPECT().UpdateStopChannel(gomock.Any()).MaxTimes(5)
 
 	b := &Backend{
-		core:       tendermintC,
-		gossiper:   g,
-		blockchain: chain,
-		eventMux:   event.NewTypeMuxSilent(nil, log.Root()),
+		core:        tendermintC,
+		gossiper:    g,
+		blockchain:  chain,
+		eventMux:    event.NewTypeMuxSilent(nil, log.Root()),
+		inbound:     newInboundQueue(),
+		commitRetry: newCommitRetry(log.Root()),
+		liveness:    newLivenessWatchdog(log.Root()),
+		skew:        newSkewDetector(log.Root()),
 	}
 	b.aggregator = &aggregator{logger: log.Root(), backend: b, core: tendermintC}
 	b.coreStarting.Store(false)
@@ -722,3 +814,70 @@ func TestBackendSealHash(t *testing.T) {
 		t.Fatalf("expected not empty string")
 	}
 }
+
+func TestLifecycle(t *testing.T) {
+	t.Run("engine started before being attached, error returned", func(t *testing.T) {
+		b := &Backend{logger: log.Root()}
+
+		err := b.Start(context.Background())
+		assertError(t, consensus.ErrEngineNotAttached, err)
+		assertNotCoreStarted(t, b)
+		select {
+		case <-b.Ready():
+			t.Fatalf("engine ready before being attached")
+		default:
+		}
+	})
+
+	t.Run("consensus message received before being attached, error returned", func(t *testing.T) {
+		b := &Backend{logger: log.Root()}
+		b.coreStarting.Store(true)
+		b.coreRunning.Store(true)
+
+		msg := makeMsg(PrevoteNetworkMsg, []byte("data"))
+		handled, err := b.HandleMsg(common.BytesToAddress([]byte("address")), msg, make(chan error, 1))
+		if !handled {
+			t.Fatalf("consensus message not handled")
+		}
+		assertError(t, consensus.ErrEngineNotAttached, err)
+	})
+
+	t.Run("engine attached, ready and started", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		chain, _ := newBlockChain(1)
+		tendermintC := interfaces.NewMockCore(ctrl)
+		tendermintC.EXPECT().Start(gomock.Any(), chain.ProtocolContracts()).MaxTimes(1)
+		tendermintC.EXPECT().Stop().MaxTimes(1)
+		tendermintC.EXPECT().Height().Return(common.Big1).AnyTimes()
+		g := interfaces.NewMockGossiper(ctrl)
+		g.EXPECT().UpdateStopChannel(gomock.Any())
+
+		b := &Backend{
+			core:        tendermintC,
+			gossiper:    g,
+			logger:      log.Root(),
+			eventMux:    event.NewTypeMuxSilent(nil, log.Root()),
+			inbound:     newInboundQueue(),
+			commitRetry: newCommitRetry(log.Root()),
+			liveness:    newLivenessWatchdog(log.Root()),
+			skew:        newSkewDetector(log.Root()),
+		}
+		b.aggregator = &aggregator{logger: log.Root(), backend: b, core: tendermintC}
+		ready := b.Ready()
+
+		assertError(t, consensus.ErrEngineNotAttached, b.Attach(nil, nil))
+		assertNilError(t, b.Attach(chain, chain.ProtocolContracts()))
+		select {
+		case <-ready:
+		default:
+			t.Fatalf("engine not ready once attached")
+		}
+		assertError(t, consensus.ErrEngineAttached, b.Attach(chain, chain.ProtocolContracts()))
+
+		assertNilError(t, b.Start(context.Background()))
+		assertCoreStarted(t, b)
+		assertNilError(t, b.Close())
+	})
+}
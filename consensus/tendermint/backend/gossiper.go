@@ -1,18 +1,29 @@
 package backend
 
 import (
-	"math/big"
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/randutil"
 	"github.com/autonity/autonity/consensus"
-	"github.com/autonity/autonity/consensus/tendermint/bft"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
 )
 
+// syncPeers is the number of peers a sync request is sent to.
+const syncPeers = 3
+
+// GossipDroppedMeter counts the sends to a peer dropped because the message became stale before being sent
+var GossipDroppedMeter = metrics.NewRegisteredMeter("acn/gossip/dropped", nil)
+
 type Gossiper struct {
 	knownMessages      *fixsizecache.Cache[common.Hash, bool] // the cache of self messages
 	address            common.Address                         // address of the local peer
@@ -20,15 +31,21 @@ type Gossiper struct {
 	logger             log.Logger
 	stopped            chan struct{}
 	concurrencyLimiter chan struct{}
+	rand               randutil.Source // selects the peers asked to sync
+
+	syncMu    sync.Mutex
+	syncOrder []common.Address // random order the peers are asked to sync in
+	syncNext  int              // position in syncOrder of the next peer to ask
 }
 
-func NewGossiper(knownMessages *fixsizecache.Cache[common.Hash, bool], address common.Address, logger log.Logger, stopped chan struct{}) *Gossiper {
+func NewGossiper(knownMessages *fixsizecache.Cache[common.Hash, bool], address common.Address, logger log.Logger, stopped chan struct{}, rand randutil.Source) *Gossiper {
 	return &Gossiper{
 		knownMessages:      knownMessages,
 		address:            address,
 		logger:             logger,
 		stopped:            stopped,
 		concurrencyLimiter: make(chan struct{}, 64),
+		rand:               rand,
 	}
 }
 
@@ -52,7 +69,9 @@ func (g *Gossiper) UpdateStopChannel(stopCh chan struct{}) {
 	g.stopped = stopCh
 }
 
-func (g *Gossiper) Gossip(committee types.Committee, message message.Msg) {
+// Gossip sends the message to the committee members which do not have it yet. The sends waiting for a free
+// slot, or for their turn to be written to the peer, are dropped once ctx is cancelled.
+func (g *Gossiper) Gossip(ctx context.Context, committee types.Committee, message message.Msg) {
 	hash := message.Hash()
 	if !g.knownMessages.Contains(hash) {
 		g.knownMessages.Add(hash, true)
@@ -67,23 +86,33 @@ func (g *Gossiper) Gossip(committee types.Committee, message message.Msg) {
 			continue
 		}
 		if p, ok := g.broadcaster.FindPeer(val.Address); ok {
-			if p.Cache().Contains(hash) {
+			if p.KnownMessages().Contains(hash) {
 				// This peer had this event, skip it
 				continue
 			}
-			p.Cache().Add(hash, true)
-			g.concurrencyLimiter <- struct{}{}
+			select {
+			case g.concurrencyLimiter <- struct{}{}:
+			case <-ctx.Done():
+				GossipDroppedMeter.Mark(1)
+				continue
+			}
+			p.KnownMessages().Add(hash, message.H())
 			go func() {
 				defer func() {
 					<-g.concurrencyLimiter
 				}()
+				if ctx.Err() != nil {
+					GossipDroppedMeter.Mark(1)
+					return
+				}
 				p.SendRaw(code, payload) //nolint
 			}()
 		}
 	}
 }
 
-func (g *Gossiper) AskSync(header *types.Header) {
+func (g *Gossiper) AskSync(header *types.Header, known []common.Hash) {
+	request := encodeSyncRequest(known)
 
 	targets := make([]common.Address, 0, len(header.Committee))
 	for _, val := range header.Committee {
@@ -106,23 +135,48 @@ func (g *Gossiper) AskSync(header *types.Header) {
 					return
 				}
 			}
-			count := new(big.Int)
-			for addr, p := range ps {
-				//ask to a quorum nodes to sync, 1 must then be honest and updated
-				if count.Cmp(bft.Quorum(header.TotalVotingPower())) >= 0 {
-					break
-				}
+			for _, addr := range g.nextSyncTargets(ps) {
 				g.logger.Debug("Asking sync to", "addr", addr)
-				go p.Send(SyncNetworkMsg, []byte{}) //nolint
-
-				member := header.CommitteeMember(addr)
-				if member == nil {
-					g.logger.Error("could not retrieve member from address")
-					continue
-				}
-				count.Add(count, member.VotingPower)
+				go ps[addr].Send(SyncNetworkMsg, request) //nolint
 			}
 			break
 		}
 	}
 }
+
+// nextSyncTargets returns the next syncPeers connected peers to ask to sync. The peers are asked in a random
+// order drawn again once all of them were asked, so that the retries of a sync request rotate over the
+// committee rather than hitting the same peers.
+func (g *Gossiper) nextSyncTargets(peers map[common.Address]consensus.Peer) []common.Address {
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+	targets := make([]common.Address, 0, syncPeers)
+	for len(targets) < min(syncPeers, len(peers)) {
+		if g.syncNext >= len(g.syncOrder) {
+			g.syncOrder, g.syncNext = g.syncTargets(peers), 0
+		}
+		addr := g.syncOrder[g.syncNext]
+		g.syncNext++
+		// the peers disconnected since the order was drawn are skipped
+		if _, ok := peers[addr]; ok && !slices.Contains(targets, addr) {
+			targets = append(targets, addr)
+		}
+	}
+	return targets
+}
+
+// syncTargets returns the addresses of the connected peers in a random order drawn from the gossiper source.
+// The addresses are sorted first, so that the order only depends on the source and not on the map iteration.
+func (g *Gossiper) syncTargets(peers map[common.Address]consensus.Peer) []common.Address {
+	addresses := make([]common.Address, 0, len(peers))
+	for addr := range peers {
+		addresses = append(addresses, addr)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	g.rand.Shuffle(len(addresses), func(i, j int) {
+		addresses[i], addresses[j] = addresses[j], addresses[i]
+	})
+	return addresses
+}
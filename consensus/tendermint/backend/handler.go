@@ -8,13 +8,18 @@ import (
 	"time"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/logthrottle"
 	"github.com/autonity/autonity/consensus"
+	tendermintCore "github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/log"
 	"github.com/autonity/autonity/metrics"
 	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/params"
 )
 
 const (
@@ -32,7 +37,7 @@ type UnhandledMsg struct {
 
 var (
 	// errDecodeFailed is returned when decode message fails
-	errDecodeFailed = errors.New("fail to decode tendermint message")
+	errDecodeFailed = constants.NewError(constants.ClassDecode, "fail to decode tendermint message")
 	NetworkCodes    = map[uint8]uint64{
 		message.ProposalCode:  ProposeNetworkMsg,
 		message.PrevoteCode:   PrevoteNetworkMsg,
@@ -45,8 +50,81 @@ var (
 
 	TotalMessageReceivedBg = metrics.NewRegisteredMeter("acn/handler/message/received", nil)  // total message received
 	MessageProcessedBg     = metrics.NewRegisteredMeter("acn/handler/message/processed", nil) // total message processed
+
+	// the anomalies a misbehaving peer causes for every message it sends are logged once per interval
+	unknownPeerLogs  = logthrottle.New[common.Address]("acn/anomaly/unknownpeer", logthrottle.DefaultInterval)
+	decodeLogs       = logthrottle.New[common.Address]("acn/anomaly/decode", logthrottle.DefaultInterval)
+	nonCommitteeLogs = logthrottle.New[common.Address]("acn/anomaly/noncommittee", logthrottle.DefaultInterval)
 )
 
+// PeerPenalty is the penalty of a peer disconnected because of a consensus message error.
+type PeerPenalty struct {
+	Severity        p2p.BanSeverity // severity of the ban of the peer from the consensus network
+	EpochSuspension bool            // the peer is suspended for an epoch instead of the default span
+	// Strikes is the number of errors of the class after which the peer is disconnected, on the first
+	// one if unset. The errors an honest peer may relay around a committee change are tolerated a few
+	// times, the peers keep different views of the committee until they all imported the new epoch.
+	Strikes int
+}
+
+// peerStrikes is the tolerance to the consensus message errors which may be caused by a race at a
+// committee change rather than by a misbehaviour of the relaying peer.
+const peerStrikes = 3
+
+// messageErrorPolicy is the handling of the consensus message errors of a class.
+type messageErrorPolicy struct {
+	meter      metrics.Meter
+	disconnect bool // disconnect the sender, with the penalty below
+	penalty    PeerPenalty
+}
+
+// messageErrorPolicies maps the consensus message error classes to their metric and to the action
+// taken against the sender. The benign races of the gossip never disconnect the sender.
+var messageErrorPolicies = map[constants.ErrorClass]*messageErrorPolicy{
+	constants.ClassInternal:        {disconnect: false},
+	constants.ClassDecode:          {disconnect: true, penalty: PeerPenalty{Severity: p2p.BanMinor}},
+	constants.ClassInvalid:         {disconnect: true, penalty: PeerPenalty{Severity: p2p.BanMinor}},
+	constants.ClassStaleHeight:     {disconnect: false},
+	constants.ClassStaleRound:      {disconnect: false},
+	constants.ClassFuture:          {disconnect: false},
+	constants.ClassNonCommittee:    {disconnect: true, penalty: PeerPenalty{Severity: p2p.BanMinor, Strikes: peerStrikes}},
+	constants.ClassBadSignature:    {disconnect: true, penalty: PeerPenalty{Severity: p2p.BanCritical, EpochSuspension: true, Strikes: peerStrikes}},
+	constants.ClassNotFromProposer: {disconnect: true, penalty: PeerPenalty{Severity: p2p.BanMinor}},
+	constants.ClassDuplicate:       {disconnect: false},
+}
+
+func init() {
+	for _, class := range constants.ErrorClasses() {
+		messageErrorPolicies[class].meter = metrics.NewRegisteredMeter("acn/handler/message/error/"+class.String(), nil)
+	}
+}
+
+// MessageErrorPenalty returns the penalty of a peer disconnected because of err.
+func MessageErrorPenalty(err error) PeerPenalty {
+	return messageErrorPolicies[constants.ClassOf(err)].penalty
+}
+
+// ReportMessageError implements interfaces.Backend.ReportMessageError
+func (sb *Backend) ReportMessageError(err error, errCh chan<- error) {
+	if err = sb.handleMessageError(err); err != nil {
+		tryDisconnect(errCh, err)
+	}
+}
+
+// handleMessageError accounts for a consensus message handling error, it returns the error
+// only if the sender has to be disconnected.
+func (sb *Backend) handleMessageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	policy := messageErrorPolicies[constants.ClassOf(err)]
+	policy.meter.Mark(1)
+	if !policy.disconnect {
+		return nil
+	}
+	return err
+}
+
 func getProcessMetric(msgCode uint64) metrics.BufferedGauge {
 	switch msgCode {
 	case 0x11:
@@ -99,8 +177,14 @@ func (sb *Backend) HandleMsg(sender common.Address, msg p2p.Msg, errCh chan<- er
 			sb.logger.Debug("Sync message received but core not running")
 			return true, nil // we return nil as we don't want to shut down the connection if core is stopped
 		}
-		sb.logger.Debug("Received sync message", "from", sender)
-		go sb.Post(events.SyncEvent{Addr: sender})
+		// the peers not listing the messages they hold, or failing to, are sent all the messages
+		var request []byte
+		if err := msg.Decode(&request); err != nil {
+			sb.logger.Debug("Could not decode sync request", "from", sender, "err", err)
+			request = nil
+		}
+		sb.logger.Debug("Received sync message", "from", sender, "known", len(request)/syncPrefixLength)
+		go sb.Post(events.SyncEvent{Addr: sender, Request: request})
 	case AccountabilityNetworkMsg:
 		if !sb.coreRunning.Load() {
 			sb.logger.Debug("Accountability Msg received but core not running")
@@ -109,7 +193,7 @@ func (sb *Backend) HandleMsg(sender common.Address, msg p2p.Msg, errCh chan<- er
 		var data []byte
 		if err := msg.Decode(&data); err != nil {
 			// this error will freeze peer for 30 seconds by according to dev p2p protocol.
-			return true, errDecodeFailed
+			return true, sb.handleMessageError(errDecodeFailed)
 		}
 
 		// post the off chain accountability msg to the event handler, let the event handler to handle DoS attack vectors.
@@ -126,17 +210,28 @@ func handleConsensusMsg[T any, PT interface {
 	*T
 	message.Msg
 }](sb *Backend, sender common.Address, p2pMsg p2p.Msg, errCh chan<- error) (bool, error) {
+	if sb.blockchain == nil {
+		sb.logger.Error("Consensus message received before the engine is attached to the chain", "sender", sender)
+		return true, consensus.ErrEngineNotAttached
+	}
+	// discard oversized proposals before doing any work on them, they can't be valid and won't be gossiped further
+	if p2pMsg.Code == ProposeNetworkMsg {
+		if limit := sb.blockchain.Config().ProposalSizeCap() + params.ProposalMessageOverhead; uint64(p2pMsg.Size) > limit {
+			sb.logger.Debug("Discarding oversized proposal", "sender", sender, "size", p2pMsg.Size, "limit", limit)
+			return true, sb.handleMessageError(constants.ErrOversizedProposal)
+		}
+	}
 	// we type cast it to byte.Reader because that's the only reader
 	// type we expect here
 	bReader := p2pMsg.Payload.(*bytes.Reader)
 	hash, err := crypto.HashFromReader(bReader)
 	if err != nil {
 		log.Error("Failed to hash payload", "error", err)
-		return true, err
+		return true, sb.handleMessageError(constants.WrapError(constants.ClassDecode, err))
 	}
 	TotalMessageReceivedBg.Mark(1)
 	if sb.knownMessages.Contains(hash) {
-		return true, nil
+		return true, sb.handleMessageError(constants.ErrDuplicateMessage)
 	}
 	MessageProcessedBg.Mark(1)
 	bReader.Seek(0, io.SeekStart)
@@ -152,33 +247,53 @@ func handleConsensusMsg[T any, PT interface {
 		}(time.Now())
 	}
 
+	traces := msgtrace.Of(sb.tracer.Sample(hash, sender, p2pMsg.ReceivedAt))
+
 	// Mark peer's message as known.
 	peer, ok := sb.Broadcaster.FindPeer(sender)
 	if !ok {
-		sb.logger.Error("message received from unknown peer", "sender", sender)
+		if summary, ok := unknownPeerLogs.Allow(sender); ok {
+			sb.logger.Error(summary.Annotate("message received from unknown peer"), "sender", sender)
+		}
+		traces.Finish(msgtrace.Receive, "unknown peer")
 		return false, nil
 	}
-	if !peer.Cache().Contains(hash) {
-		peer.Cache().Add(hash, true)
-	}
 
 	sb.knownMessages.Add(hash, true)
 	msg := PT(new(T))
 	if err := p2pMsg.Decode(msg); err != nil {
-		sb.logger.Error("Error decoding consensus message", "err", err)
-		return true, err
+		if summary, ok := decodeLogs.Allow(sender); ok {
+			sb.logger.Error(summary.Annotate("Error decoding consensus message"), "sender", sender, "err", err)
+		}
+		traces.Finish(msgtrace.Decode, err.Error())
+		return true, sb.handleMessageError(constants.WrapError(constants.ClassDecode, err))
 	}
-	// if the message is for a future height wrt to consensus engine, buffer it
-	// it will be re-injected into the handleDecodedMsg function at the right height
-	if msg.H() > sb.core.Height().Uint64() {
+	// the height of the message is needed to rotate the peer filter
+	peer.KnownMessages().Add(hash, msg.H())
+	traces.SetMessage(msg.Code(), msg.H(), msg.R())
+	switch err := tendermintCore.AdmitMessage(msg, sb.core.Height().Uint64()); {
+	case errors.Is(err, constants.ErrFutureHeightMessage):
+		// if the message is for a future height wrt to consensus engine, buffer it
+		// it will be re-injected into the handleDecodedMsg function at the right height
 		sb.logger.Debug("Saving future height consensus message for later", "msgHeight", msg.H(), "coreHeight", sb.core.Height().Uint64())
-		sb.saveFutureMsg(msg, errCh, sender)
-		return true, nil
+		traces.Record(msgtrace.Decode, "buffered until its height")
+		sb.saveFutureMsg(msg, errCh, sender, traces)
+		return true, sb.handleMessageError(err)
+	case errors.Is(err, constants.ErrOldHeightMessage):
+		// the old height messages are still handed over to the fault detector
+	case err != nil:
+		sb.logger.Debug("Inadmissible consensus message", "err", err)
+		traces.Finish(msgtrace.Decode, err.Error())
+		return true, sb.handleMessageError(err)
+	}
+	traces.Record(msgtrace.Decode, "decoded")
+	if err := sb.handleDecodedMsg(msg, errCh, sender, traces); err != nil {
+		return true, sb.handleMessageError(err)
 	}
-	return sb.handleDecodedMsg(msg, errCh, sender)
+	return true, nil
 }
 
-func (sb *Backend) handleDecodedMsg(msg message.Msg, errCh chan<- error, sender common.Address) (bool, error) {
+func (sb *Backend) handleDecodedMsg(msg message.Msg, errCh chan<- error, sender common.Address, traces msgtrace.Traces) error {
 	header := sb.BlockChain().GetHeaderByNumber(msg.H() - 1)
 	if header == nil {
 		// since this is not a future message, we should always have the header of the parent block.
@@ -187,7 +302,13 @@ func (sb *Backend) handleDecodedMsg(msg message.Msg, errCh chan<- error, sender
 
 	// assign power and bls signer key
 	if err := msg.PreValidate(header); err != nil {
-		return true, err
+		if errors.Is(err, message.ErrNonCommitteeSender) {
+			if summary, ok := nonCommitteeLogs.Allow(sender); ok {
+				sb.logger.Warn(summary.Annotate("Consensus message from a non-committee member"), "sender", sender, "height", msg.H())
+			}
+		}
+		traces.Finish(msgtrace.Verify, err.Error())
+		return constants.WrapError(constants.ClassInvalid, err)
 	}
 
 	// if the sender is jailed, discard its messages
@@ -199,7 +320,8 @@ func (sb *Backend) handleDecodedMsg(msg message.Msg, errCh chan<- error, sender
 			// really assume that all the other committee members have the same view on the
 			// jailed validator list before gossip, that is risking then to disconnect honest nodes.
 			// This needs to verified though. Returning nil for the time being.
-			return true, nil
+			traces.Finish(msgtrace.Verify, "proposer is jailed")
+			return nil
 		}
 	case *message.Prevote, *message.Precommit:
 		vote := m.(message.Vote)
@@ -208,7 +330,8 @@ func (sb *Backend) handleDecodedMsg(msg message.Msg, errCh chan<- error, sender
 			if sb.IsJailed(signer) {
 				sb.logger.Debug("Vote message contains signature from jailed validator, ignoring message", "address", signer)
 				// same
-				return true, nil
+				traces.Finish(msgtrace.Verify, "signer is jailed")
+				return nil
 			}
 		}
 	default:
@@ -220,16 +343,18 @@ func (sb *Backend) handleDecodedMsg(msg message.Msg, errCh chan<- error, sender
 		ErrCh:   errCh,
 		Sender:  sender,
 		Posted:  time.Now(),
+		Traces:  traces,
 	})
-	return true, nil
+	return nil
 }
 
-func (sb *Backend) saveFutureMsg(msg message.Msg, errCh chan<- error, sender common.Address) {
+func (sb *Backend) saveFutureMsg(msg message.Msg, errCh chan<- error, sender common.Address, traces msgtrace.Traces) {
 	// create event that will be re-injected in handleDecodedMsg when we reach the correct height
 	e := &events.UnverifiedMessageEvent{
 		Message: msg,
 		ErrCh:   errCh,
 		Sender:  sender,
+		Traces:  traces,
 	}
 	h := msg.H()
 
@@ -256,6 +381,7 @@ func (sb *Backend) saveFutureMsg(msg message.Msg, errCh chan<- error, sender com
 			go func(evs []*events.UnverifiedMessageEvent) {
 				for _, e := range evs {
 					sb.knownMessages.Remove(e.Message.Hash())
+					e.Traces.Finish(msgtrace.Decode, "dropped from the future height buffer")
 				}
 			}(maxHeightEvs)
 			delete(sb.future, sb.futureMaxHeight)
@@ -290,7 +416,9 @@ func (sb *Backend) ProcessFutureMsgs(height uint64) {
 		if ok {
 			sb.logger.Debug("processing future height messages", "height", h, "n", len(sb.future[h]))
 			for _, e := range evs {
-				sb.handleDecodedMsg(e.Message, e.ErrCh, e.Sender)
+				if err := sb.handleDecodedMsg(e.Message, e.ErrCh, e.Sender, e.Traces); err != nil {
+					sb.ReportMessageError(err, e.ErrCh)
+				}
 				sb.futureSize--
 			}
 			delete(sb.future, h)
@@ -15,7 +15,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
-	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/rotatingbloom"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/consensus/tendermint/events"
@@ -80,8 +80,8 @@ func TestUnhandledMsgs(t *testing.T) {
 		defer ctrl.Finish()
 		mockedPeer := consensus.NewMockPeer(ctrl)
 		broadcaster := consensus.NewMockBroadcaster(ctrl)
-		addressCache := fixsizecache.New[common.Hash, bool](1997, 10, fixsizecache.HashKey[common.Hash])
-		mockedPeer.EXPECT().Cache().Return(addressCache).AnyTimes()
+		peerKnown := rotatingbloom.NewDefault()
+		mockedPeer.EXPECT().KnownMessages().Return(peerKnown).AnyTimes()
 		broadcaster.EXPECT().FindPeer(gomock.Any()).Return(mockedPeer, true).AnyTimes()
 
 		blockchain, backend := newBlockChain(1)
@@ -3,21 +3,26 @@ package backend
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
-	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/rotatingbloom"
 	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
 	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/rlp"
 )
 
@@ -34,8 +39,8 @@ func TestTendermintMessage(t *testing.T) {
 	defer ctrl.Finish()
 	mockedPeer := consensus.NewMockPeer(ctrl)
 	broadcaster := consensus.NewMockBroadcaster(ctrl)
-	addressCache := fixsizecache.New[common.Hash, bool](1997, 10, fixsizecache.HashKey[common.Hash])
-	mockedPeer.EXPECT().Cache().Return(addressCache).AnyTimes()
+	peerKnown := rotatingbloom.NewDefault()
+	mockedPeer.EXPECT().KnownMessages().Return(peerKnown).AnyTimes()
 	broadcaster.EXPECT().FindPeer(testAddress).Return(mockedPeer, true).AnyTimes()
 	backend.SetBroadcaster(broadcaster)
 
@@ -45,7 +50,7 @@ func TestTendermintMessage(t *testing.T) {
 	// 1. this message should not be in cache
 	// for peers
 	if peer, ok := backend.Broadcaster.FindPeer(testAddress); ok {
-		if peer.Cache().Contains(data.Hash()) {
+		if peer.KnownMessages().Contains(data.Hash()) {
 			t.Fatalf("the cache of messages for this peer should be empty")
 		}
 	}
@@ -63,8 +68,7 @@ func TestTendermintMessage(t *testing.T) {
 	}
 	// for peers
 	if peer, ok := backend.Broadcaster.FindPeer(testAddress); ok {
-		cache := peer.Cache()
-		if !cache.Contains(data.Hash()) {
+		if !peer.KnownMessages().Contains(data.Hash()) {
 			t.Fatalf("the cache of messages for this peer cannot be found")
 		}
 	}
@@ -115,7 +119,40 @@ func TestSynchronisationMessage(t *testing.T) {
 		select {
 		case <-timer.C:
 			t.Fatalf("sync message not posted")
-		case <-sub.Chan():
+		case ev := <-sub.Chan():
+			require.Empty(t, ev.Data.(events.SyncEvent).Request)
+		}
+	})
+
+	t.Run("engine running, known messages forwarded", func(t *testing.T) {
+		eventMux := event.NewTypeMuxSilent(nil, log.New("backend", "test", "id", 0))
+		sub := eventMux.Subscribe(events.SyncEvent{})
+		b := &Backend{
+			logger:   log.New("backend", "test", "id", 0),
+			eventMux: eventMux,
+		}
+		b.coreStarting.Store(true)
+		b.coreRunning.Store(true)
+		request := encodeSyncRequest([]common.Hash{{0x01}, {0x02}})
+		addr := common.BytesToAddress([]byte("address"))
+		cases := []struct {
+			payload any
+			want    []byte
+		}{
+			{request, request},
+			// a payload which is not a sync request falls back to a full sync
+			{[]uint64{1, 2}, nil},
+		}
+		for _, c := range cases {
+			if res, err := b.HandleMsg(addr, makeMsg(SyncNetworkMsg, c.payload), make(chan error, 1)); !res || err != nil {
+				t.Fatalf("HandleMsg unexpected return")
+			}
+			select {
+			case <-time.After(2 * time.Second):
+				t.Fatalf("sync message not posted")
+			case ev := <-sub.Chan():
+				require.Equal(t, c.want, ev.Data.(events.SyncEvent).Request)
+			}
 		}
 	})
 }
@@ -149,6 +186,10 @@ func TestNewChainHead(t *testing.T) {
 			gossiper:     g,
 			blockchain:   chain,
 			eventMux:     event.NewTypeMuxSilent(nil, log.Root()),
+			inbound:      newInboundQueue(),
+			commitRetry:  newCommitRetry(log.Root()),
+			liveness:     newLivenessWatchdog(log.Root()),
+			skew:         newSkewDetector(log.Root()),
 		}
 		b.aggregator = &aggregator{logger: log.Root(), backend: b, core: tendermintC}
 		b.Start(ctx)
@@ -159,6 +200,104 @@ func TestNewChainHead(t *testing.T) {
 		}
 	})
 }
+func TestOversizedProposalMessage(t *testing.T) {
+	chain, backend := newBlockChain(1)
+	setMaxProposalSize(t, chain.Config(), 64*1024)
+	// stop the core so that the accepted message is only buffered
+	if err := backend.Close(); err != nil {
+		t.Fatalf("can't stop the engine")
+	}
+	limit := chain.Config().ProposalSizeCap() + params.ProposalMessageOverhead
+	errCh := make(chan error, 1)
+
+	payload := make([]byte, limit+1)
+	msg := p2p.Msg{Code: ProposeNetworkMsg, Size: uint32(len(payload)), Payload: bytes.NewReader(payload)}
+	handled, err := backend.HandleMsg(testAddress, msg, errCh)
+	if !handled || !errors.Is(err, constants.ErrOversizedProposal) {
+		t.Fatalf("just over the limit: have (%v, %v), want (true, %v)", handled, err, constants.ErrOversizedProposal)
+	}
+	if backend.pendingMessages.ContentSize() != 0 {
+		t.Fatalf("oversized proposal should not be buffered")
+	}
+
+	payload = payload[:limit]
+	msg = p2p.Msg{Code: ProposeNetworkMsg, Size: uint32(len(payload)), Payload: bytes.NewReader(payload)}
+	handled, err = backend.HandleMsg(testAddress, msg, errCh)
+	if !handled || err != nil {
+		t.Fatalf("just under the limit: have (%v, %v), want (true, nil)", handled, err)
+	}
+	if backend.pendingMessages.ContentSize() != 1 {
+		t.Fatalf("proposal should be buffered")
+	}
+}
+
+func TestReportMessageError(t *testing.T) {
+	cases := []struct {
+		err        error
+		class      constants.ErrorClass
+		disconnect bool
+		penalty    PeerPenalty
+	}{
+		{consensus.ErrPrunedAncestor, constants.ClassInternal, false, PeerPenalty{}},
+		{constants.WrapError(constants.ClassDecode, rlp.EOL), constants.ClassDecode, true, PeerPenalty{Severity: p2p.BanMinor}},
+		{constants.ErrOversizedProposal, constants.ClassDecode, true, PeerPenalty{Severity: p2p.BanMinor}},
+		{message.ErrInvalidComplexAggregate, constants.ClassInvalid, true, PeerPenalty{Severity: p2p.BanMinor}},
+		{constants.WrapError(constants.ClassInvalid, consensus.ErrUnknownAncestor), constants.ClassInvalid, true, PeerPenalty{Severity: p2p.BanMinor}},
+		{constants.ErrOldHeightMessage, constants.ClassStaleHeight, false, PeerPenalty{}},
+		{constants.ErrAlreadyHaveBlock, constants.ClassStaleHeight, false, PeerPenalty{}},
+		{constants.ErrOldRoundMessage, constants.ClassStaleRound, false, PeerPenalty{}},
+		{constants.ErrMovedToNewRound, constants.ClassStaleRound, false, PeerPenalty{}},
+		{constants.ErrFutureHeightMessage, constants.ClassFuture, false, PeerPenalty{}},
+		{constants.ErrFutureRoundMessage, constants.ClassFuture, false, PeerPenalty{}},
+		// proposals with a future timestamp used to disconnect the proposer, they are only delayed
+		{constants.WrapError(constants.ClassFuture, consensus.ErrFutureTimestampBlock), constants.ClassFuture, false, PeerPenalty{}},
+		{message.ErrNonCommitteeSender, constants.ClassNonCommittee, true, PeerPenalty{Severity: p2p.BanMinor, Strikes: peerStrikes}},
+		{message.ErrBadSignature, constants.ClassBadSignature, true, PeerPenalty{Severity: p2p.BanCritical, EpochSuspension: true, Strikes: peerStrikes}},
+		{constants.ErrNotFromProposer, constants.ClassNotFromProposer, true, PeerPenalty{Severity: p2p.BanMinor}},
+		{constants.ErrAlreadyHaveProposal, constants.ClassDuplicate, false, PeerPenalty{}},
+		{constants.ErrDuplicateMessage, constants.ClassDuplicate, false, PeerPenalty{}},
+	}
+
+	// the meters are disabled in tests, swap them for working ones
+	for _, policy := range messageErrorPolicies {
+		defer func(policy *messageErrorPolicy, meter metrics.Meter) { policy.meter = meter }(policy, policy.meter)
+		policy.meter = metrics.NewMeterForced()
+	}
+	backend := &Backend{}
+	for _, tc := range cases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			require.Equal(t, tc.class, constants.ClassOf(tc.err))
+			meter := messageErrorPolicies[tc.class].meter
+			count := meter.Count()
+
+			errCh := make(chan error, 1)
+			backend.ReportMessageError(tc.err, errCh)
+			require.Equal(t, count+1, meter.Count())
+			select {
+			case err := <-errCh:
+				require.True(t, tc.disconnect, "unexpected disconnection")
+				require.Equal(t, tc.err, err)
+				require.Equal(t, tc.penalty, MessageErrorPenalty(err))
+			default:
+				require.False(t, tc.disconnect, "missing disconnection")
+			}
+
+			// the same decision is taken for the messages failing synchronously
+			err := backend.handleMessageError(tc.err)
+			require.Equal(t, count+2, meter.Count())
+			require.Equal(t, tc.disconnect, err != nil)
+		})
+	}
+
+	t.Run("every class has a policy", func(t *testing.T) {
+		for _, class := range constants.ErrorClasses() {
+			require.NotNil(t, messageErrorPolicies[class], class.String())
+		}
+		backend.ReportMessageError(message.ErrBadSignature, nil)
+		require.NoError(t, backend.handleMessageError(nil))
+	})
+}
+
 func makeMsg(msgcode uint64, data interface{}) p2p.Msg {
 	size, r, _ := rlp.EncodeToReader(data)
 	var buff bytes.Buffer
@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+)
+
+// defaultVoteFairness is the default maximum number of proposals dispatched to core in a row while
+// votes are waiting.
+const defaultVoteFairness = 8
+
+// inboundQueue dispatches the verified consensus messages to core in two tiers. The proposals gate the
+// progress of a round, so they are dispatched before the votes, which can pile up by the thousands
+// during sync replays or when many rounds are gossiped. To never starve the votes, at most `fairness`
+// proposals are dispatched in a row while votes are waiting.
+//
+// Each tier is FIFO, so the messages of the same type from a peer keep their relative order. A proposal
+// can overtake a vote received before it, and a vote can overtake a proposal when the fairness bound is
+// hit. This does not matter: core stores the messages of every round and evaluates the upon conditions
+// on the whole set, so a vote handled before the proposal it refers to is taken into account once the
+// proposal arrives. The events were already posted from separate goroutines without ordering guarantees.
+//
+// The commit and candidate block events are not queued, they are delivered on dedicated core channels and
+// never wait behind the votes.
+type inboundQueue struct {
+	mu        sync.Mutex
+	proposals []events.MessageEvent
+	votes     []events.MessageEvent
+	streak    int // proposals dispatched in a row while votes were waiting
+	fairness  int
+	notify    chan struct{}
+}
+
+func newInboundQueue() *inboundQueue {
+	return &inboundQueue{
+		fairness: defaultVoteFairness,
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// setFairness sets the maximum number of proposals dispatched in a row while votes are waiting. Zero
+// restores the default.
+func (q *inboundQueue) setFairness(n int) {
+	if n <= 0 {
+		n = defaultVoteFairness
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.fairness = n
+}
+
+func (q *inboundQueue) push(ev events.MessageEvent) {
+	q.mu.Lock()
+	if ev.Message.Code() == message.ProposalCode {
+		q.proposals = append(q.proposals, ev)
+	} else {
+		q.votes = append(q.votes, ev)
+	}
+	q.mu.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the next event to dispatch, if any.
+func (q *inboundQueue) pop() (events.MessageEvent, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var ev events.MessageEvent
+	switch {
+	case len(q.proposals) > 0 && (len(q.votes) == 0 || q.streak < q.fairness):
+		ev, q.proposals = q.proposals[0], q.proposals[1:]
+		if len(q.votes) > 0 {
+			q.streak++
+		}
+	case len(q.votes) > 0:
+		ev, q.votes = q.votes[0], q.votes[1:]
+		q.streak = 0
+	default:
+		return ev, false
+	}
+	// release the backing arrays once drained
+	if len(q.proposals) == 0 {
+		q.proposals = nil
+	}
+	if len(q.votes) == 0 {
+		q.votes = nil
+	}
+	return ev, true
+}
+
+// clear drops the queued events.
+func (q *inboundQueue) clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.proposals, q.votes, q.streak = nil, nil, 0
+}
+
+// run dispatches the queued events with post, which blocks until they are consumed, until stopped is closed.
+func (q *inboundQueue) run(stopped <-chan struct{}, post func(ev any)) {
+	for {
+		ev, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.notify:
+				continue
+			case <-stopped:
+				return
+			}
+		}
+		post(ev)
+		select {
+		case <-stopped:
+			return
+		default:
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+)
+
+func newQueuedEvent(code uint8, round uint64) events.MessageEvent {
+	return events.MessageEvent{Message: message.Fake{FakeCode: code, FakeRound: round}, Posted: time.Now()}
+}
+
+// drain pops all the queued events.
+func drain(q *inboundQueue) []events.MessageEvent {
+	var dispatched []events.MessageEvent
+	for {
+		ev, ok := q.pop()
+		if !ok {
+			return dispatched
+		}
+		dispatched = append(dispatched, ev)
+	}
+}
+
+func TestInboundQueue(t *testing.T) {
+	t.Run("proposals are dispatched before the votes", func(t *testing.T) {
+		q := newInboundQueue()
+		q.push(newQueuedEvent(message.PrevoteCode, 0))
+		q.push(newQueuedEvent(message.PrecommitCode, 0))
+		q.push(newQueuedEvent(message.ProposalCode, 0))
+
+		dispatched := drain(q)
+		require.Len(t, dispatched, 3)
+		require.Equal(t, message.ProposalCode, dispatched[0].Message.Code())
+		require.Equal(t, message.PrevoteCode, dispatched[1].Message.Code())
+		require.Equal(t, message.PrecommitCode, dispatched[2].Message.Code())
+	})
+
+	t.Run("messages of the same type keep their order", func(t *testing.T) {
+		q := newInboundQueue()
+		for r := uint64(0); r < 10; r++ {
+			q.push(newQueuedEvent(message.PrevoteCode, r))
+			q.push(newQueuedEvent(message.ProposalCode, r))
+		}
+		var proposals, votes []int64
+		for _, ev := range drain(q) {
+			if ev.Message.Code() == message.ProposalCode {
+				proposals = append(proposals, ev.Message.R())
+			} else {
+				votes = append(votes, ev.Message.R())
+			}
+		}
+		require.IsIncreasing(t, proposals)
+		require.IsIncreasing(t, votes)
+		require.Len(t, proposals, 10)
+		require.Len(t, votes, 10)
+	})
+
+	t.Run("votes are not starved beyond the fairness ratio", func(t *testing.T) {
+		q := newInboundQueue()
+		q.setFairness(3)
+		for i := 0; i < 4; i++ {
+			q.push(newQueuedEvent(message.PrevoteCode, 0))
+		}
+		for i := 0; i < 10; i++ {
+			q.push(newQueuedEvent(message.ProposalCode, 0))
+		}
+		var codes []uint8
+		for _, ev := range drain(q) {
+			codes = append(codes, ev.Message.Code())
+		}
+		p, v := message.ProposalCode, message.PrevoteCode
+		require.Equal(t, []uint8{p, p, p, v, p, p, p, v, p, p, p, v, p, v}, codes)
+	})
+
+	t.Run("zero fairness restores the default", func(t *testing.T) {
+		q := newInboundQueue()
+		q.setFairness(0)
+		require.Equal(t, defaultVoteFairness, q.fairness)
+	})
+
+	t.Run("run dispatches until stopped", func(t *testing.T) {
+		q := newInboundQueue()
+		stopped := make(chan struct{})
+		dispatched := make(chan events.MessageEvent)
+		done := make(chan struct{})
+		go func() {
+			q.run(stopped, func(ev any) { dispatched <- ev.(events.MessageEvent) })
+			close(done)
+		}()
+		q.push(newQueuedEvent(message.PrevoteCode, 1))
+		require.Equal(t, int64(1), (<-dispatched).Message.R())
+		q.push(newQueuedEvent(message.ProposalCode, 2))
+		require.Equal(t, int64(2), (<-dispatched).Message.R())
+		close(stopped)
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("dispatcher not stopped")
+		}
+	})
+}
+
+// TestInboundQueueProposalLatency posts a burst of votes followed by a proposal, and measures the time
+// the proposal waits in the queue, with the proposal queued behind the votes as in a FIFO queue and
+// with the proposals prioritised.
+func TestInboundQueueProposalLatency(t *testing.T) {
+	const votes = 50000
+	latency := func(prioritised bool) time.Duration {
+		q := newInboundQueue()
+		for i := 0; i < votes; i++ {
+			q.push(newQueuedEvent(message.PrevoteCode, 0))
+		}
+		proposal := newQueuedEvent(message.ProposalCode, 0)
+		if prioritised {
+			q.push(proposal)
+		} else {
+			q.votes = append(q.votes, proposal)
+		}
+
+		stopped := make(chan struct{})
+		result := make(chan time.Duration, 1)
+		go q.run(stopped, func(ev any) {
+			if ev.(events.MessageEvent).Message.Code() == message.ProposalCode {
+				result <- time.Since(proposal.Posted)
+			}
+		})
+		defer close(stopped)
+		select {
+		case d := <-result:
+			return d
+		case <-time.After(30 * time.Second):
+			t.Fatal("proposal not dispatched")
+			return 0
+		}
+	}
+
+	fifo := latency(false)
+	prioritised := latency(true)
+	t.Logf("proposal queue latency behind %d votes: fifo %v, prioritised %v", votes, fifo, prioritised)
+	require.Less(t, prioritised, fifo)
+}
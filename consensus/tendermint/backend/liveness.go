@@ -0,0 +1,214 @@
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+)
+
+const (
+	// livenessWindow is the number of the most recent heights the liveness is assessed on.
+	livenessWindow = 16
+	// livenessSlowInterval is the interval between two heads above which a height is decided late.
+	livenessSlowInterval = 5 * time.Second
+	// livenessStallTimeout is the time without a new head after which the chain is reported stalled.
+	livenessStallTimeout = 30 * time.Second
+	// livenessCheckInterval is the period the stall is checked at.
+	livenessCheckInterval = time.Second
+)
+
+// The liveness statuses, from the healthiest.
+const (
+	LivenessLive     = "live"     // the recent heights were decided at the first round and in time
+	LivenessDegraded = "degraded" // some of the recent heights needed round changes or were decided late
+	LivenessStalled  = "stalled"  // no height was decided for livenessStallTimeout
+)
+
+var (
+	livenessDegradedMeter = metrics.NewRegisteredMeter("tendermint/liveness/degraded", nil)
+	livenessStalledMeter  = metrics.NewRegisteredMeter("tendermint/liveness/stalled", nil)
+)
+
+// LivenessStatus reports whether the recent heights were decided in time.
+type LivenessStatus struct {
+	Status string
+	Head   uint64    // number of the latest head, zero until the first head after the start
+	HeadAt time.Time `json:",omitempty"` // local time the latest head was received at
+	// RoundChanges is the number of the recent heights decided at a round above zero.
+	RoundChanges int
+	// SlowHeights is the number of the recent heights decided more than SlowInterval after their parent.
+	SlowHeights  int
+	SlowInterval time.Duration
+	StallTimeout time.Duration
+	// Degradations and Stalls count the episodes since the start, a stall following a degradation is
+	// counted once in each.
+	Degradations uint64
+	Stalls       uint64
+}
+
+// livenessHead is a head of the window the liveness is assessed on.
+type livenessHead struct {
+	round    uint64
+	interval time.Duration // local time elapsed since the previous head, zero for the first one
+}
+
+// livenessWatchdog follows the chain head to report the degradations of the consensus liveness. With a
+// skewed clock or a lossy network, a minority of the committee delays the heights it proposes for without
+// halting the chain: the heights are then decided after round changes, or late. Once the chain stops
+// progressing altogether, the watchdog reports it stalled.
+type livenessWatchdog struct {
+	mu           sync.Mutex
+	heads        []livenessHead // ring of the most recent heads
+	next         int
+	head         uint64
+	headAt       time.Time
+	startedAt    time.Time // start of the watch, the stall timeout runs from it until the first head
+	current      string    // status of the watch
+	degradations uint64
+	stalls       uint64
+
+	logger log.Logger
+}
+
+func newLivenessWatchdog(logger log.Logger) *livenessWatchdog {
+	return &livenessWatchdog{current: LivenessLive, logger: logger}
+}
+
+// livenessChain is the chain whose heads the watchdog follows.
+type livenessChain interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// run follows the heads of chain until stopped is closed.
+func (w *livenessWatchdog) run(chain livenessChain, stopped <-chan struct{}) {
+	headCh := make(chan core.ChainHeadEvent, 16)
+	sub := chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+	ticker := time.NewTicker(livenessCheckInterval)
+	defer ticker.Stop()
+
+	w.start(time.Now())
+	for {
+		select {
+		case ev := <-headCh:
+			w.onHead(ev.Block.Header(), time.Now())
+		case <-ticker.C:
+			w.check(time.Now())
+		case <-sub.Err():
+			return
+		case <-stopped:
+			return
+		}
+	}
+}
+
+// start resets the watch, the heads received before a restart of the engine are not accounted for.
+func (w *livenessWatchdog) start(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.heads, w.next, w.head, w.headAt = nil, 0, 0, time.Time{}
+	w.startedAt = now
+	w.update(LivenessLive)
+}
+
+// onHead records the decision of a height.
+func (w *livenessWatchdog) onHead(header *types.Header, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if number := header.Number.Uint64(); number <= w.head {
+		return
+	}
+	head := livenessHead{round: header.Round}
+	if !w.headAt.IsZero() {
+		head.interval = now.Sub(w.headAt)
+	}
+	if len(w.heads) < livenessWindow {
+		w.heads = append(w.heads, head)
+	} else {
+		w.heads[w.next] = head
+	}
+	w.next = (w.next + 1) % livenessWindow
+	w.head, w.headAt = header.Number.Uint64(), now
+	w.update(w.assess(now))
+}
+
+// check reports the chain stalled once no head was received for the stall timeout.
+func (w *livenessWatchdog) check(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.update(w.assess(now))
+}
+
+func (w *livenessWatchdog) assess(now time.Time) string {
+	since := w.headAt
+	if since.IsZero() {
+		since = w.startedAt
+	}
+	if !since.IsZero() && now.Sub(since) > livenessStallTimeout {
+		return LivenessStalled
+	}
+	roundChanges, slow := w.counts()
+	if roundChanges > 0 || slow > 0 {
+		return LivenessDegraded
+	}
+	return LivenessLive
+}
+
+func (w *livenessWatchdog) counts() (roundChanges, slow int) {
+	for _, head := range w.heads {
+		if head.round > 0 {
+			roundChanges++
+		}
+		if head.interval > livenessSlowInterval {
+			slow++
+		}
+	}
+	return roundChanges, slow
+}
+
+// update moves the watch to status, counting and logging the new episodes.
+func (w *livenessWatchdog) update(status string) {
+	if status == w.current {
+		return
+	}
+	previous := w.current
+	w.current = status
+	switch status {
+	case LivenessStalled:
+		w.stalls++
+		livenessStalledMeter.Mark(1)
+		w.logger.Warn("Consensus stalled, no height decided", "head", w.head, "timeout", common.PrettyDuration(livenessStallTimeout))
+	case LivenessDegraded:
+		if previous == LivenessLive {
+			w.degradations++
+			livenessDegradedMeter.Mark(1)
+		}
+		roundChanges, slow := w.counts()
+		w.logger.Warn("Consensus liveness degraded", "head", w.head, "roundChanges", roundChanges, "slowHeights", slow, "window", livenessWindow)
+	case LivenessLive:
+		w.logger.Info("Consensus liveness restored", "head", w.head, "previous", previous)
+	}
+}
+
+func (w *livenessWatchdog) status() LivenessStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	roundChanges, slow := w.counts()
+	return LivenessStatus{
+		Status:       w.current,
+		Head:         w.head,
+		HeadAt:       w.headAt,
+		RoundChanges: roundChanges,
+		SlowHeights:  slow,
+		SlowInterval: livenessSlowInterval,
+		StallTimeout: livenessStallTimeout,
+		Degradations: w.degradations,
+		Stalls:       w.stalls,
+	}
+}
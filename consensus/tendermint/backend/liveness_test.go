@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestLivenessWatchdog(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	w := newLivenessWatchdog(log.Root())
+	w.start(start)
+	now := start
+	head := func(number, round uint64, interval time.Duration) {
+		now = now.Add(interval)
+		w.onHead(&types.Header{Number: new(big.Int).SetUint64(number), Round: round}, now)
+	}
+
+	// the heights decided at the first round and in time
+	for i := uint64(1); i <= 5; i++ {
+		head(i, 0, time.Second)
+	}
+	status := w.status()
+	require.Equal(t, LivenessLive, status.Status)
+	require.Equal(t, uint64(5), status.Head)
+
+	// a round change degrades the liveness until it leaves the window
+	head(6, 2, 3*time.Second)
+	status = w.status()
+	require.Equal(t, LivenessDegraded, status.Status)
+	require.Equal(t, 1, status.RoundChanges)
+	require.Equal(t, uint64(1), status.Degradations)
+	// a head already seen is ignored
+	head(6, 0, time.Second)
+	for i := uint64(7); i < 6+livenessWindow; i++ {
+		head(i, 0, time.Second)
+		require.Equal(t, LivenessDegraded, w.status().Status)
+	}
+	head(6+livenessWindow, 0, time.Second)
+	require.Equal(t, LivenessLive, w.status().Status)
+
+	// a height decided late degrades the liveness too
+	head(7+livenessWindow, 0, livenessSlowInterval+time.Second)
+	status = w.status()
+	require.Equal(t, LivenessDegraded, status.Status)
+	require.Equal(t, 1, status.SlowHeights)
+	require.Equal(t, uint64(2), status.Degradations)
+
+	// the chain stalls without a new head, and recovers degraded by the late height
+	w.check(now.Add(livenessStallTimeout))
+	require.Equal(t, LivenessDegraded, w.status().Status)
+	w.check(now.Add(livenessStallTimeout + time.Second))
+	status = w.status()
+	require.Equal(t, LivenessStalled, status.Status)
+	require.Equal(t, uint64(1), status.Stalls)
+	head(8+livenessWindow, 0, livenessStallTimeout+2*time.Second)
+	status = w.status()
+	require.Equal(t, LivenessDegraded, status.Status)
+	require.Equal(t, 2, status.SlowHeights)
+	require.Equal(t, uint64(2), status.Degradations)
+
+	// the stall timeout runs from the start until the first head
+	w.start(now)
+	require.Equal(t, LivenessLive, w.status().Status)
+	w.check(now.Add(livenessStallTimeout + time.Second))
+	require.Equal(t, LivenessStalled, w.status().Status)
+}
@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// syncPrefixLength is the length of the hash prefixes a sync request lists the messages known to the
+// requester with. A prefix collision only costs the requester a message it will get from the gossip.
+const syncPrefixLength = 8
+
+type syncPrefix [syncPrefixLength]byte
+
+var errSyncRequestLength = errors.New("sync request is not a list of hash prefixes")
+
+// encodeSyncRequest returns the payload of a sync request, the concatenated hash prefixes of the messages
+// the requester holds. It is empty if no message is known, as the requests of the nodes not listing them.
+func encodeSyncRequest(known []common.Hash) []byte {
+	request := make([]byte, 0, len(known)*syncPrefixLength)
+	for _, hash := range known {
+		request = append(request, hash[:syncPrefixLength]...)
+	}
+	return request
+}
+
+// decodeSyncRequest returns the hash prefixes of the messages the requester holds.
+func decodeSyncRequest(request []byte) (map[syncPrefix]struct{}, error) {
+	if len(request)%syncPrefixLength != 0 {
+		return nil, errSyncRequestLength
+	}
+	known := make(map[syncPrefix]struct{}, len(request)/syncPrefixLength)
+	for i := 0; i < len(request); i += syncPrefixLength {
+		known[syncPrefix(request[i:i+syncPrefixLength])] = struct{}{}
+	}
+	return known, nil
+}
+
+// missingMessages returns the messages whose hash prefix is not known, in their order.
+func missingMessages(messages []message.Msg, known map[syncPrefix]struct{}) []message.Msg {
+	if len(known) == 0 {
+		return messages
+	}
+	missing := make([]message.Msg, 0, len(messages))
+	for _, msg := range messages {
+		hash := msg.Hash()
+		if _, ok := known[syncPrefix(hash[:syncPrefixLength])]; !ok {
+			missing = append(missing, msg)
+		}
+	}
+	return missing
+}
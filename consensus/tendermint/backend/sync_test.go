@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/fixsizecache"
+	"github.com/autonity/autonity/common/randutil"
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestSyncRequest(t *testing.T) {
+	hashes := []common.Hash{{0x01}, {0x02}, {0xff}}
+	request := encodeSyncRequest(hashes)
+	require.Len(t, request, len(hashes)*syncPrefixLength)
+	known, err := decodeSyncRequest(request)
+	require.NoError(t, err)
+	require.Len(t, known, len(hashes))
+	for _, hash := range hashes {
+		require.Contains(t, known, syncPrefix(hash[:syncPrefixLength]))
+	}
+
+	// nothing known is the request of the nodes not listing the known messages
+	require.Equal(t, []byte{}, encodeSyncRequest(nil))
+	known, err = decodeSyncRequest([]byte{})
+	require.NoError(t, err)
+	require.Empty(t, known)
+
+	_, err = decodeSyncRequest(request[:len(request)-1])
+	require.ErrorIs(t, err, errSyncRequestLength)
+}
+
+func TestMissingMessages(t *testing.T) {
+	header, blsKeys := headerAndBlsKeys(4)
+	committee := header.Committee
+	signer := makeSigner(blsKeys[0])
+	messages := []message.Msg{
+		message.NewPropose(0, 1, -1, types.NewBlockWithHeader(&types.Header{Number: common.Big1}), signer, &committee[0]),
+		message.NewPrevote(0, 1, common.Hash{0x01}, signer, &committee[0], len(committee)),
+		message.NewPrecommit(0, 1, common.Hash{0x01}, signer, &committee[0], len(committee)),
+		message.NewPrevote(1, 1, common.Hash{0x02}, signer, &committee[0], len(committee)),
+	}
+	hashes := func(msgs ...message.Msg) []common.Hash {
+		var list []common.Hash
+		for _, msg := range msgs {
+			list = append(list, msg.Hash())
+		}
+		return list
+	}
+	decode := func(known []common.Hash) map[syncPrefix]struct{} {
+		set, err := decodeSyncRequest(encodeSyncRequest(known))
+		require.NoError(t, err)
+		return set
+	}
+
+	// an old-style request gets all the messages
+	require.Equal(t, messages, missingMessages(messages, nil))
+	require.Equal(t, messages, missingMessages(messages, decode(nil)))
+	// the known ones are skipped, in order, whatever the other messages the peer holds
+	require.Equal(t, []message.Msg{messages[0], messages[3]}, missingMessages(messages, decode(append(hashes(messages[2], messages[1]), common.Hash{0x03}))))
+	require.Empty(t, missingMessages(messages, decode(hashes(messages...))))
+	// the messages of another height the peer lists do not match
+	require.Equal(t, messages, missingMessages(messages, decode([]common.Hash{{0x04}, {0x05}})))
+}
+
+func TestAskSyncListsKnownMessages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	header, _ := headerAndBlsKeys(4) // N=4, Q=3
+	known := []common.Hash{{0x01}, {0x02}}
+	asked := make(chan []byte, len(header.Committee))
+	peers := make(map[common.Address]consensus.Peer)
+	for _, val := range header.Committee {
+		peer := consensus.NewMockPeer(ctrl)
+		peer.EXPECT().Send(SyncNetworkMsg, gomock.Any()).Do(func(_ uint64, data any) {
+			asked <- data.([]byte)
+		}).MaxTimes(1)
+		peers[val.Address] = peer
+	}
+	broadcaster := consensus.NewMockBroadcaster(ctrl)
+	broadcaster.EXPECT().FindPeers(gomock.Any()).Return(peers)
+	knownMessages := fixsizecache.New[common.Hash, bool](499, 10, fixsizecache.HashKey[common.Hash])
+	gossiper := NewGossiper(knownMessages, common.Address{}, log.New(), make(chan struct{}), randutil.New())
+	gossiper.SetBroadcaster(broadcaster)
+
+	gossiper.AskSync(header, known)
+	for i := 0; i < 3; i++ {
+		select {
+		case request := <-asked:
+			require.Equal(t, encodeSyncRequest(known), request)
+		case <-time.After(2 * time.Second):
+			t.Fatal("ask sync message transmission failure")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// AdmitMessage is the admission check of the decoded consensus messages, the decoders only enforcing
+// the wire format. On top of CheckMessageBounds, the message must be for currentHeight: the messages of
+// the past heights are rejected with ErrOldHeightMessage and those of the future heights with
+// ErrFutureHeightMessage, so that the caller can buffer them. The messages of any round of the height
+// are admitted, the old and future rounds being dealt with by the round logic.
+func AdmitMessage(msg message.Msg, currentHeight uint64) error {
+	if err := CheckMessageBounds(msg); err != nil {
+		return err
+	}
+	switch {
+	case msg.H() < currentHeight:
+		return constants.ErrOldHeightMessage
+	case msg.H() > currentHeight:
+		return constants.ErrFutureHeightMessage
+	}
+	return nil
+}
+
+// CheckMessageBounds checks the part of the admission which does not depend on the local state:
+//   - the height is not zero,
+//   - 0 <= round <= MaxRound,
+//   - the valid round of a proposal is either nil (-1) or within [0, round),
+//   - a proposal carries the hash of the proposed block.
+//
+// It is also applied to the messages of the accountability proofs.
+func CheckMessageBounds(msg message.Msg) error {
+	if msg.H() == 0 {
+		return constants.ErrInvalidHeight
+	}
+	if msg.R() < 0 || msg.R() > constants.MaxRound {
+		return fmt.Errorf("%w: %d", constants.ErrRoundOutOfBounds, msg.R())
+	}
+	switch msg.Code() {
+	case message.ProposalCode, message.LightProposalCode:
+		proposal, ok := msg.(interface{ ValidRound() int64 })
+		if !ok {
+			return constants.ErrInvalidMessage
+		}
+		if vr := proposal.ValidRound(); vr != -1 && (vr < 0 || vr >= msg.R()) {
+			return fmt.Errorf("%w: %d in round %d", constants.ErrInvalidValidRound, vr, msg.R())
+		}
+		if msg.Value() == (common.Hash{}) {
+			return constants.ErrMissingValue
+		}
+	}
+	return nil
+}
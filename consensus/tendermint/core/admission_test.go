@@ -0,0 +1,92 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+// fakeProposal is a fake message exposing a valid round, as the proposals do.
+type fakeProposal struct {
+	message.Fake
+	validRound int64
+}
+
+func (f fakeProposal) ValidRound() int64 { return f.validRound }
+
+func TestAdmitMessage(t *testing.T) {
+	value := common.HexToHash("0x1227")
+	vote := func(code uint8, h, r uint64, value common.Hash) message.Msg {
+		return message.Fake{FakeCode: code, FakeHeight: h, FakeRound: r, FakeValue: value}
+	}
+	proposal := func(code uint8, h, r uint64, vr int64, value common.Hash) message.Msg {
+		return fakeProposal{
+			Fake:       message.Fake{FakeCode: code, FakeHeight: h, FakeRound: r, FakeValue: value},
+			validRound: vr,
+		}
+	}
+	negativeRound := uint64(math.MaxUint64) // R() == -1
+
+	testCases := []struct {
+		name  string
+		msg   message.Msg
+		err   error
+		class constants.ErrorClass
+	}{
+		{"prevote", vote(message.PrevoteCode, 10, 3, value), nil, 0},
+		{"nil prevote", vote(message.PrevoteCode, 10, 0, common.Hash{}), nil, 0},
+		{"precommit at max round", vote(message.PrecommitCode, 10, constants.MaxRound, value), nil, 0},
+		{"nil precommit", vote(message.PrecommitCode, 10, 1, common.Hash{}), nil, 0},
+		{"proposal", proposal(message.ProposalCode, 10, 0, -1, value), nil, 0},
+		{"proposal with valid round", proposal(message.ProposalCode, 10, 5, 4, value), nil, 0},
+		{"light proposal", proposal(message.LightProposalCode, 10, 5, 0, value), nil, 0},
+
+		{"old height", vote(message.PrevoteCode, 9, 0, value), constants.ErrOldHeightMessage, constants.ClassStaleHeight},
+		{"future height", vote(message.PrecommitCode, 11, 0, value), constants.ErrFutureHeightMessage, constants.ClassFuture},
+
+		{"prevote at height 0", vote(message.PrevoteCode, 0, 0, value), constants.ErrInvalidHeight, constants.ClassInvalid},
+		{"precommit at height 0", vote(message.PrecommitCode, 0, 0, value), constants.ErrInvalidHeight, constants.ClassInvalid},
+		{"proposal at height 0", proposal(message.ProposalCode, 0, 0, -1, value), constants.ErrInvalidHeight, constants.ClassInvalid},
+		{"light proposal at height 0", proposal(message.LightProposalCode, 0, 0, -1, value), constants.ErrInvalidHeight, constants.ClassInvalid},
+
+		{"prevote above max round", vote(message.PrevoteCode, 10, constants.MaxRound+1, value), constants.ErrRoundOutOfBounds, constants.ClassInvalid},
+		{"precommit above max round", vote(message.PrecommitCode, 10, constants.MaxRound+1, value), constants.ErrRoundOutOfBounds, constants.ClassInvalid},
+		{"proposal above max round", proposal(message.ProposalCode, 10, constants.MaxRound+1, -1, value), constants.ErrRoundOutOfBounds, constants.ClassInvalid},
+		{"light proposal above max round", proposal(message.LightProposalCode, 10, constants.MaxRound+1, -1, value), constants.ErrRoundOutOfBounds, constants.ClassInvalid},
+		{"prevote with negative round", vote(message.PrevoteCode, 10, negativeRound, value), constants.ErrRoundOutOfBounds, constants.ClassInvalid},
+		{"proposal with negative round", proposal(message.ProposalCode, 10, negativeRound, -1, value), constants.ErrRoundOutOfBounds, constants.ClassInvalid},
+
+		{"proposal with valid round equal to round", proposal(message.ProposalCode, 10, 3, 3, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+		{"proposal with valid round above round", proposal(message.ProposalCode, 10, 3, 57, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+		{"proposal with valid round below -1", proposal(message.ProposalCode, 10, 3, -2, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+		{"proposal with valid round in round 0", proposal(message.ProposalCode, 10, 0, 0, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+		{"light proposal with valid round equal to round", proposal(message.LightProposalCode, 10, 3, 3, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+		{"light proposal with valid round above round", proposal(message.LightProposalCode, 10, 3, 4, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+		{"light proposal with valid round below -1", proposal(message.LightProposalCode, 10, 3, -5, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+
+		{"proposal without value", proposal(message.ProposalCode, 10, 0, -1, common.Hash{}), constants.ErrMissingValue, constants.ClassInvalid},
+		{"light proposal without value", proposal(message.LightProposalCode, 10, 2, 1, common.Hash{}), constants.ErrMissingValue, constants.ClassInvalid},
+		{"proposal without valid round", vote(message.ProposalCode, 10, 0, value), constants.ErrInvalidMessage, constants.ClassDecode},
+
+		// the bounds are checked before the height
+		{"old height above max round", vote(message.PrevoteCode, 9, constants.MaxRound+1, value), constants.ErrRoundOutOfBounds, constants.ClassInvalid},
+		{"future height with invalid valid round", proposal(message.ProposalCode, 11, 1, 1, value), constants.ErrInvalidValidRound, constants.ClassInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := AdmitMessage(tc.msg, 10)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.class, constants.ClassOf(err))
+		})
+	}
+}
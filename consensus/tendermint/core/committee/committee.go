@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
@@ -129,45 +130,47 @@ func getMemberIndex(members types.Committee, memberAddr common.Address) int64 {
 	return int64(index)
 }
 
+// WeightedRandomSamplingCommittee elects the proposers from the committee of the last header. The
+// last header is replaced at each height while the committee is read by other goroutines, each
+// method works on a single snapshot of it.
 type WeightedRandomSamplingCommittee struct {
-	previousHeader         *types.Header
-	bc                     *ethcore.BlockChain // Todo : remove this dependency
-	autonityContract       *autonity.ProtocolContracts
-	previousBlockStateRoot common.Hash
+	previousHeader   atomic.Pointer[types.Header]
+	bc               *ethcore.BlockChain // Todo : remove this dependency
+	autonityContract *autonity.ProtocolContracts
 }
 
 func NewWeightedRandomSamplingCommittee(previousBlock *types.Block, autonityContract *autonity.ProtocolContracts, bc *ethcore.BlockChain) *WeightedRandomSamplingCommittee {
-	return &WeightedRandomSamplingCommittee{
-		previousHeader:         previousBlock.Header(),
-		bc:                     bc,
-		autonityContract:       autonityContract,
-		previousBlockStateRoot: previousBlock.Root(),
+	w := &WeightedRandomSamplingCommittee{
+		bc:               bc,
+		autonityContract: autonityContract,
 	}
+	w.previousHeader.Store(previousBlock.Header())
+	return w
 }
 
 // Return the underlying types.Committee
 func (w *WeightedRandomSamplingCommittee) Committee() types.Committee {
-	return w.previousHeader.Committee
+	return w.previousHeader.Load().Committee
 }
 
 func (w *WeightedRandomSamplingCommittee) SetLastHeader(header *types.Header) {
-	w.previousHeader = header
-	w.previousBlockStateRoot = header.Root
+	w.previousHeader.Store(header)
 }
 
 // Get validator by index
 func (w *WeightedRandomSamplingCommittee) GetByIndex(i int) (types.CommitteeMember, error) {
-	if i < 0 || i >= len(w.previousHeader.Committee) {
+	committee := w.previousHeader.Load().Committee
+	if i < 0 || i >= len(committee) {
 		return types.CommitteeMember{}, consensus.ErrCommitteeMemberNotFound
 	}
-	return w.previousHeader.Committee[i], nil
+	return committee[i], nil
 }
 
 // Get validator by given address
 func (w *WeightedRandomSamplingCommittee) GetByAddress(addr common.Address) (int, types.CommitteeMember, error) {
 	// TODO Promote types.Committee to a struct containing a slice, this will
 	// allow for caching of other information like total power ... etc.
-	m := w.previousHeader.CommitteeMember(addr)
+	m := w.previousHeader.Load().CommitteeMember(addr)
 	if m == nil {
 		return -1, types.CommitteeMember{}, consensus.ErrCommitteeMemberNotFound
 	}
@@ -177,15 +180,16 @@ func (w *WeightedRandomSamplingCommittee) GetByAddress(addr common.Address) (int
 
 // Get the round proposer
 func (w *WeightedRandomSamplingCommittee) GetProposer(round int64) types.CommitteeMember {
+	previousHeader := w.previousHeader.Load()
 	// state.New has started taking a snapshot.Tree but it seems to be only for
 	// performance, see - https://github.com/autonity/autonity/pull/20152
-	statedb, err := state.New(w.previousBlockStateRoot, w.bc.StateCache(), nil)
+	statedb, err := state.New(previousHeader.Root, w.bc.StateCache(), nil)
 	if err != nil {
 		log.Error("cannot load state from block chain.")
 		return types.CommitteeMember{}
 	}
-	proposer := w.autonityContract.Proposer(w.previousHeader, statedb, w.previousHeader.Number.Uint64(), round)
-	member := w.previousHeader.CommitteeMember(proposer)
+	proposer := w.autonityContract.Proposer(previousHeader, statedb, previousHeader.Number.Uint64(), round)
+	member := previousHeader.CommitteeMember(proposer)
 	if member == nil {
 		//Should not happen in live network, edge case
 		log.Error("cannot find proposer")
@@ -197,11 +201,11 @@ func (w *WeightedRandomSamplingCommittee) GetProposer(round int64) types.Committ
 
 // Get the optimal quorum size
 func (w *WeightedRandomSamplingCommittee) Quorum() *big.Int {
-	return bft.Quorum(w.previousHeader.TotalVotingPower())
+	return bft.Quorum(w.previousHeader.Load().TotalVotingPower())
 }
 
 func (w *WeightedRandomSamplingCommittee) F() *big.Int {
-	return bft.F(w.previousHeader.TotalVotingPower())
+	return bft.F(w.previousHeader.Load().TotalVotingPower())
 }
 
 func copyMembers(members types.Committee) types.Committee {
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// minTimeoutBase is the lowest base of the step timeouts, below which the rounds time out before the
+// messages of the other validators can arrive.
+const minTimeoutBase = 100 * time.Millisecond
+
+// Config contains the tunable parameters of the Tendermint core. The timeout of a step at round r is its
+// base plus r times its delta, the propose timeout also includes the block period.
+type Config struct {
+	TimeoutProposeBase    time.Duration
+	TimeoutProposeDelta   time.Duration
+	TimeoutPrevoteBase    time.Duration
+	TimeoutPrevoteDelta   time.Duration
+	TimeoutPrecommitBase  time.Duration
+	TimeoutPrecommitDelta time.Duration
+}
+
+// DefaultConfig contains the default Tendermint core parameters.
+var DefaultConfig = Config{
+	TimeoutProposeBase:    InitialProposeTimeout,
+	TimeoutProposeDelta:   ProposeTimeoutDelta,
+	TimeoutPrevoteBase:    InitialPrevoteTimeout,
+	TimeoutPrevoteDelta:   PrevoteTimeoutDelta,
+	TimeoutPrecommitBase:  InitialPrecommitTimeout,
+	TimeoutPrecommitDelta: PrecommitTimeoutDelta,
+}
+
+// Validate checks that the timeout bases are at least minTimeoutBase and that the deltas are not negative.
+func (c *Config) Validate() error {
+	bases := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"TimeoutProposeBase", c.TimeoutProposeBase},
+		{"TimeoutPrevoteBase", c.TimeoutPrevoteBase},
+		{"TimeoutPrecommitBase", c.TimeoutPrecommitBase},
+	}
+	for _, base := range bases {
+		if base.value < minTimeoutBase {
+			return fmt.Errorf("tendermint %s %v is below the minimum of %v", base.name, base.value, minTimeoutBase)
+		}
+	}
+	deltas := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"TimeoutProposeDelta", c.TimeoutProposeDelta},
+		{"TimeoutPrevoteDelta", c.TimeoutPrevoteDelta},
+		{"TimeoutPrecommitDelta", c.TimeoutPrecommitDelta},
+	}
+	for _, delta := range deltas {
+		if delta.value < 0 {
+			return fmt.Errorf("tendermint %s %v is negative", delta.name, delta.value)
+		}
+	}
+	return nil
+}
+
+// Config returns the parameters of the core. They can be changed until the core starts.
+func (c *Core) Config() *Config {
+	return c.config
+}
+
+// timeouts returns the parameters of the step timeouts, the defaults for the cores not created by New.
+func (c *Core) timeouts() *Config {
+	if c.config == nil {
+		return &DefaultConfig
+	}
+	return c.config
+}
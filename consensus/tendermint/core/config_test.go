@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/log"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		valid  bool
+	}{
+		{name: "defaults", modify: func(c *Config) {}, valid: true},
+		{name: "minimum base", modify: func(c *Config) { c.TimeoutPrevoteBase = minTimeoutBase }, valid: true},
+		{name: "zero delta", modify: func(c *Config) { c.TimeoutPrecommitDelta = 0 }, valid: true},
+		{name: "propose base too low", modify: func(c *Config) { c.TimeoutProposeBase = 99 * time.Millisecond }},
+		{name: "prevote base too low", modify: func(c *Config) { c.TimeoutPrevoteBase = 0 }},
+		{name: "precommit base too low", modify: func(c *Config) { c.TimeoutPrecommitBase = time.Millisecond }},
+		{name: "negative propose delta", modify: func(c *Config) { c.TimeoutProposeDelta = -time.Millisecond }},
+		{name: "negative prevote delta", modify: func(c *Config) { c.TimeoutPrevoteDelta = -time.Second }},
+		{name: "negative precommit delta", modify: func(c *Config) { c.TimeoutPrecommitDelta = -1 }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig
+			test.modify(&config)
+			if test.valid {
+				require.NoError(t, config.Validate())
+			} else {
+				require.Error(t, config.Validate())
+			}
+		})
+	}
+}
+
+func TestConfigTimeouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("defaults", func(t *testing.T) {
+		c := New(interfaces.NewMockBackend(ctrl), nil, common.Address{}, log.Root(), false)
+		// the timeouts of the cores not created by New are the defaults too
+		literal := &Core{blockPeriod: 1}
+		for round := int64(0); round < 5; round++ {
+			propose := InitialProposeTimeout + time.Second + time.Duration(round)*ProposeTimeoutDelta
+			prevote := InitialPrevoteTimeout + time.Duration(round)*PrevoteTimeoutDelta
+			precommit := InitialPrecommitTimeout + time.Duration(round)*PrecommitTimeoutDelta
+			for _, core := range []*Core{c, literal} {
+				require.Equal(t, propose, core.timeoutPropose(round))
+				require.Equal(t, prevote, core.timeoutPrevote(round))
+				require.Equal(t, precommit, core.timeoutPrecommit(round))
+			}
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		c := New(interfaces.NewMockBackend(ctrl), nil, common.Address{}, log.Root(), false)
+		*c.Config() = Config{
+			TimeoutProposeBase:    2 * time.Second,
+			TimeoutProposeDelta:   time.Second,
+			TimeoutPrevoteBase:    1500 * time.Millisecond,
+			TimeoutPrevoteDelta:   500 * time.Millisecond,
+			TimeoutPrecommitBase:  time.Second,
+			TimeoutPrecommitDelta: 0,
+		}
+		expected := []struct{ propose, prevote, precommit time.Duration }{
+			{3 * time.Second, 1500 * time.Millisecond, time.Second},
+			{4 * time.Second, 2 * time.Second, time.Second},
+			{5 * time.Second, 2500 * time.Millisecond, time.Second},
+			{6 * time.Second, 3 * time.Second, time.Second},
+		}
+		for round, timeouts := range expected {
+			require.Equal(t, timeouts.propose, c.timeoutPropose(int64(round)), "round %d", round)
+			require.Equal(t, timeouts.prevote, c.timeoutPrevote(int64(round)), "round %d", round)
+			require.Equal(t, timeouts.precommit, c.timeoutPrecommit(int64(round)), "round %d", round)
+		}
+		// the defaults are left untouched
+		require.Equal(t, InitialProposeTimeout, DefaultConfig.TimeoutProposeBase)
+	})
+}
@@ -2,29 +2,143 @@ package constants
 
 import "errors"
 
+// ErrorClass classifies the consensus message handling errors, telling apart the benign races of the
+// gossip from the misbehaviour of the sender. The backend maps each class to a metric and to the action
+// taken against the sender.
+type ErrorClass uint8
+
+const (
+	ClassInternal        ErrorClass = iota // local failure or unclassified error, the sender is not blamed
+	ClassDecode                            // the message could not be decoded or is malformed
+	ClassInvalid                           // the message is well-formed but its content is invalid, e.g. an invalid proposed block
+	ClassStaleHeight                       // the message is for a height already concluded
+	ClassStaleRound                        // the message is for a round already left behind
+	ClassFuture                            // the message is for a future height or round, it is buffered until then
+	ClassNonCommittee                      // the sender is not a committee member
+	ClassBadSignature                      // the message signature is invalid
+	ClassNotFromProposer                   // the proposal does not come from the proposer of the round
+	ClassDuplicate                         // the message, or another proposal for its round, was already received
+	numErrorClasses
+)
+
+var errorClassNames = [numErrorClasses]string{
+	ClassInternal:        "internal",
+	ClassDecode:          "decode",
+	ClassInvalid:         "invalid",
+	ClassStaleHeight:     "staleheight",
+	ClassStaleRound:      "staleround",
+	ClassFuture:          "future",
+	ClassNonCommittee:    "noncommittee",
+	ClassBadSignature:    "badsignature",
+	ClassNotFromProposer: "notfromproposer",
+	ClassDuplicate:       "duplicate",
+}
+
+// ErrorClasses returns all the error classes.
+func ErrorClasses() []ErrorClass {
+	classes := make([]ErrorClass, numErrorClasses)
+	for i := range classes {
+		classes[i] = ErrorClass(i)
+	}
+	return classes
+}
+
+func (c ErrorClass) String() string {
+	if c >= numErrorClasses {
+		return "unknown"
+	}
+	return errorClassNames[c]
+}
+
+// ClassifiedError is a consensus message handling error of a known class.
+type ClassifiedError struct {
+	class ErrorClass
+	err   error
+}
+
+// NewError returns a new error of the given class.
+func NewError(class ErrorClass, text string) *ClassifiedError {
+	return &ClassifiedError{class: class, err: errors.New(text)}
+}
+
+// WrapError assigns the class to err, unless err already has one.
+func WrapError(class ErrorClass, err error) error {
+	var classified *ClassifiedError
+	if err == nil || errors.As(err, &classified) {
+		return err
+	}
+	return &ClassifiedError{class: class, err: err}
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.err
+}
+
+// Class returns the class of the error.
+func (e *ClassifiedError) Class() ErrorClass {
+	return e.class
+}
+
+// ClassOf returns the class of err, ClassInternal if it has none.
+func ClassOf(err error) ErrorClass {
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.class
+	}
+	return ClassInternal
+}
+
 var (
 	// ErrNotFromProposer is returned when received message is supposed to be from
 	// proposer.
-	ErrNotFromProposer = errors.New("message does not come from proposer")
+	ErrNotFromProposer = NewError(ClassNotFromProposer, "message does not come from proposer")
 	// ErrAlreadyHaveProposal is returned when we receive a proposal but we previously already processed one.
-	ErrAlreadyHaveProposal = errors.New("a proposal was already processed in the round")
+	ErrAlreadyHaveProposal = NewError(ClassDuplicate, "a proposal was already processed in the round")
+	// ErrDuplicateMessage is returned when we receive a message we already received.
+	ErrDuplicateMessage = NewError(ClassDuplicate, "message already received")
 	// ErrAlreadyHaveBlock is returned when we are processing a proposal but we already included the proposed block in our local chain.
-	ErrAlreadyHaveBlock = errors.New("proposed block is already in our local chain")
+	ErrAlreadyHaveBlock = NewError(ClassStaleHeight, "proposed block is already in our local chain")
 	// ErrHeightClosed is returned when we receive a message for current height, but we already committed a proposal for it.
-	ErrHeightClosed = errors.New("consensus instance already concluded")
+	ErrHeightClosed = NewError(ClassStaleHeight, "consensus instance already concluded")
 	// ErrOldHeightMessage is returned when the received message's view is earlier
 	// than curRoundMessages view.
-	ErrOldHeightMessage = errors.New("old height message")
+	ErrOldHeightMessage = NewError(ClassStaleHeight, "old height message")
+	// ErrFutureHeightMessage is returned when the received message is for a future height, it is buffered until then.
+	ErrFutureHeightMessage = NewError(ClassFuture, "future height message")
 	// ErrOldRoundMessage message is returned when message is of the same Height but form a smaller round
-	ErrOldRoundMessage = errors.New("same height but old round message")
+	ErrOldRoundMessage = NewError(ClassStaleRound, "same height but old round message")
 	// ErrFutureRoundMessage message is returned when message is of the same Height but form a newer round
-	ErrFutureRoundMessage = errors.New("same height but future round message")
+	ErrFutureRoundMessage = NewError(ClassFuture, "same height but future round message")
 	// ErrInvalidMessage is returned when the message is malformed.
-	ErrInvalidMessage = errors.New("invalid message")
+	ErrInvalidMessage = NewError(ClassDecode, "invalid message")
+	// ErrInvalidHeight is returned when the message is for the genesis height, which is not agreed on by consensus.
+	ErrInvalidHeight = NewError(ClassInvalid, "invalid height")
+	// ErrRoundOutOfBounds is returned when the round of the message is not within [0, MaxRound].
+	ErrRoundOutOfBounds = NewError(ClassInvalid, "round out of bounds")
+	// ErrInvalidValidRound is returned when the valid round of a proposal is neither nil nor lower than its round.
+	ErrInvalidValidRound = NewError(ClassInvalid, "invalid valid round")
+	// ErrMissingValue is returned when a proposal does not carry the hash of the proposed block.
+	ErrMissingValue = NewError(ClassInvalid, "missing proposed value")
 	// ErrNilPrevoteSent is returned when timer could not be stopped in time
-	ErrNilPrevoteSent = errors.New("timer expired and nil prevote sent")
+	ErrNilPrevoteSent = NewError(ClassStaleRound, "timer expired and nil prevote sent")
 	// ErrNilPrecommitSent is returned when timer could not be stopped in time
-	ErrNilPrecommitSent = errors.New("timer expired and nil precommit sent")
+	ErrNilPrecommitSent = NewError(ClassStaleRound, "timer expired and nil precommit sent")
 	// ErrMovedToNewRound is returned when timer could not be stopped in time
-	ErrMovedToNewRound = errors.New("timer expired and new round started")
+	ErrMovedToNewRound = NewError(ClassStaleRound, "timer expired and new round started")
+	// ErrConflictingQuorum is returned when precommit quorums for two different values exist at the same height.
+	ErrConflictingQuorum = errors.New("conflicting precommit quorums at the same height")
+	// ErrOversizedProposal is returned when a proposal exceeds the proposal size limit of the chain.
+	ErrOversizedProposal = NewError(ClassDecode, "oversized proposal")
+	// ErrAccusationSpam is returned when a peer floods us with accusations.
+	ErrAccusationSpam = errors.New("accusation spam")
+	// ErrNotProposer is returned when a proposal is retriggered while the local node is not the proposer of the round.
+	ErrNotProposer = errors.New("not the proposer of the current round")
+	// ErrProposalAlreadySent is returned when a proposal is retriggered while one was already signed for the round.
+	ErrProposalAlreadySent = errors.New("a proposal was already signed for the current round")
+	// ErrProposeStepOver is returned when a proposal is retriggered after the propose step of the round.
+	ErrProposeStepOver = errors.New("the propose step of the current round is over")
 )
@@ -1,16 +1,23 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/mclock"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/timingdump"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto/blst"
@@ -23,7 +30,9 @@ import (
 func New(backend interfaces.Backend, services *interfaces.Services, address common.Address, logger log.Logger, noGossip bool) *Core {
 	messagesMap := message.NewMap()
 	roundMessage := messagesMap.GetOrCreate(0)
+	config := DefaultConfig
 	c := &Core{
+		config:                 &config,
 		blockPeriod:            1, // todo: retrieve it from contract
 		address:                address,
 		logger:                 logger,
@@ -31,7 +40,6 @@ func New(backend interfaces.Backend, services *interfaces.Services, address comm
 		futureRound:            make(map[int64][]message.Msg),
 		futurePower:            make(map[int64]*message.AggregatedPower),
 		pendingCandidateBlocks: make(map[uint64]*types.Block),
-		stopped:                make(chan struct{}, 4),
 		committee:              nil,
 		messages:               messagesMap,
 		lockedRound:            -1,
@@ -44,6 +52,10 @@ func New(backend interfaces.Backend, services *interfaces.Services, address comm
 		newRound:               time.Now(),
 		stepChange:             time.Now(),
 		noGossip:               noGossip,
+		timingDump:             timingdump.New(logger),
+		journal:                &RoundJournal{},
+		msgQueue:               newMessageQueue(),
+		clock:                  mclock.System{},
 	}
 	c.SetDefaultHandlers()
 	if services != nil {
@@ -72,14 +84,20 @@ type Core struct {
 
 	messageSub          *event.TypeMuxSubscription
 	candidateBlockCh    chan events.NewCandidateBlockEvent
-	committedCh         chan events.CommitEvent
-	timeoutEventSub     *event.TypeMuxSubscription
+	feeds               feeds
 	syncEventSub        *event.TypeMuxSubscription
 	futureProposalTimer *time.Timer
-	stopped             chan struct{}
+	loops               eventLoops
 
 	// map[Height]UnminedBlock
 	pendingCandidateBlocks map[uint64]*types.Block
+	// hash and reception time of the last candidate block received from the miner
+	lastCandidateHash common.Hash
+	lastCandidateAt   time.Time
+
+	// height of the last committed block processed by the main thread, the commit events not
+	// bringing a newer block are ignored.
+	committedHeight uint64
 
 	//
 	// Tendermint FSM state fields
@@ -120,8 +138,36 @@ type Core struct {
 	prevoteTimeout   *Timeout
 	precommitTimeout *Timeout
 
+	// outcome of the verification of the values proposed at the current height
+	verifications proposalVerifications
+	// prevotes sent by the node at the current height, with their reason
+	prevoteRecords []interfaces.PrevoteRecord
+
 	// End of Tendermint FSM fields
 
+	// state snapshot published by the main thread for the readers outside of it, see publishSnapshot
+	published atomic.Pointer[StateSnapshot]
+
+	// tunable parameters, such as the step timeouts
+	config *Config
+
+	// import backlog above which the start of the next height is delayed, zero if disabled
+	pacingThreshold atomic.Uint64
+	// fires once the delayed start of the next height is due, nil if none is pending
+	pacing <-chan time.Time
+
+	// hooks run at the round boundaries, nil for the hooks of the core only
+	roundHooks *roundHooks
+	// write-ahead log of the round state, resumed on start
+	journal *RoundJournal
+
+	// contexts scoping the sending of the messages to the current height and round. They are cancelled
+	// on height and round changes, so that the messages of abandoned rounds stop being sent to the peers.
+	heightCtx    context.Context
+	heightCancel context.CancelFunc
+	roundCtx     context.Context
+	roundCancel  context.CancelFunc
+
 	protocolContracts *autonity.ProtocolContracts
 
 	// tendermint behaviour interfaces, can be used in customizing the behaviours
@@ -131,11 +177,24 @@ type Core struct {
 	precommiter interfaces.Precommiter
 	proposer    interfaces.Proposer
 
-	// these timestamps are used to compute metrics for tendermint
+	// proposals that reached quorum but were not committed because of a safety violation
+	rejected rejectedArchive
+
+	// these timestamps are used to compute metrics for tendermint, and reported in the state snapshot
 	newHeight          time.Time
 	newRound           time.Time
 	currBlockTimeStamp time.Time
 	noGossip           bool
+
+	// consensus timing of the current height, dumped on commit while the timing dump is active
+	timing     heightTiming
+	timingDump *timingdump.Dumper
+
+	// consensus messages waiting to be handled, the current height ones first
+	msgQueue *MessageQueue
+
+	// drives the sync requests, replaced by a simulated clock in tests
+	clock mclock.Clock
 }
 
 func (c *Core) Prevoter() interfaces.Prevoter {
@@ -161,7 +220,7 @@ func (c *Core) Step() Step {
 func (c *Core) Post(ev any) {
 	switch ev := ev.(type) {
 	case events.CommitEvent:
-		c.committedCh <- ev
+		c.feeds.commits.Send(ev)
 	case events.NewCandidateBlockEvent:
 		c.candidateBlockCh <- ev
 	}
@@ -275,11 +334,12 @@ func (c *Core) Commit(ctx context.Context, round int64, messages *message.RoundM
 		c.logger.Error("failed to commit a block", "err", err)
 		return
 	}
+	now := time.Now()
 	if metrics.Enabled {
-		now := time.Now()
 		CommitTimer.Update(now.Sub(start))
 		CommitBg.Add(now.Sub(start).Nanoseconds())
 	}
+	c.dumpTiming(proposal, round, now.Sub(start))
 }
 
 // Metric collecton of round change and height change.
@@ -311,7 +371,7 @@ func (c *Core) processFuture(previousRound int64, currentRound int64) {
 
 	for r := previousRound + 1; r <= currentRound; r++ {
 		for _, msg := range c.futureRound[r] {
-			go c.SendEvent(backlogMessageEvent{
+			go c.sendBacklog(backlogMessageEvent{
 				msg: msg,
 			})
 		}
@@ -322,21 +382,42 @@ func (c *Core) processFuture(previousRound int64, currentRound int64) {
 
 // StartRound starts a new round. if round equals to 0, it means to starts a new height
 func (c *Core) StartRound(ctx context.Context, round int64) {
+	c.startRound(ctx, round, nil)
+}
+
+// startRound starts the round, or resumes it from the journal if journaled is not nil.
+func (c *Core) startRound(ctx context.Context, round int64, journaled *journaledRound) {
 	if round > constants.MaxRound {
 		c.logger.Crit("⚠️ CONSENSUS FAILED ⚠️")
 	}
 
+	// any round start supersedes the pending start of the next height
+	c.pacing = nil
 	previousRound := c.Round()
+	from := c.heightRound()
 
-	c.measureHeightRoundMetrics(round)
 	// Set initial FSM state
+	if journaled != nil && round > 0 {
+		// the height state is set up by its first round
+		c.setInitialState(0)
+	}
 	c.setInitialState(round)
-	c.SetStep(ctx, Propose)
+	step := Propose
+	if journaled != nil {
+		step = c.restoreRound(journaled)
+	}
+	to := c.heightRound()
+	c.runRoundHooks(c.hooks().pre, from, to)
+	c.scopeSends(ctx, round)
+	c.SetStep(ctx, step)
 	c.logger.Debug("Starting new Round", "Height", c.Height(), "Round", round)
 
 	// If the node is the proposer for this round then it would propose validValue or a new block, otherwise,
 	// proposeTimeout is started, where the node waits for a proposal from the proposer of the current round.
-	if c.IsProposer() {
+	// A round resumed past its propose step waits for the votes of the other nodes.
+	if step != Propose {
+		c.logger.Debug("Resumed Round past its Propose step", "Step", step)
+	} else if c.IsProposer() {
 		// validValue and validRound represent a block they received a quorum of prevote and the round quorum was
 		// received, respectively. If the block is not committed in that round then the round is changed.
 		// The new proposer will chose the validValue, if present, which was set in one of the previous rounds otherwise
@@ -357,8 +438,12 @@ func (c *Core) StartRound(ctx context.Context, round int64) {
 		c.proposeTimeout.ScheduleTimeout(timeoutDuration, round, c.Height(), c.onTimeoutPropose)
 		c.logger.Debug("Scheduled Propose Timeout", "Timeout Duration", timeoutDuration)
 	}
+	if journaled != nil && round > 0 {
+		// the future height messages are processed at the start of a height
+		c.processFuture(previousRound, 0)
+	}
 	c.processFuture(previousRound, round)
-	c.backend.Post(events.RoundChangeEvent{Height: c.Height().Uint64(), Round: round})
+	c.runRoundHooks(c.hooks().post, from, to)
 }
 
 func (c *Core) setInitialState(r int64) {
@@ -370,32 +455,29 @@ func (c *Core) setInitialState(r int64) {
 	// Start of new height where round is 0
 	if r == 0 {
 		lastBlockMined := c.backend.HeadBlock()
-		c.setHeight(new(big.Int).Add(lastBlockMined.Number(), common.Big1))
-		lastHeader := lastBlockMined.Header()
-		c.committee.SetLastHeader(lastHeader)
-		c.setLastHeader(lastHeader)
+		c.committedHeight = lastBlockMined.NumberU64()
+		c.setHeightState(lastBlockMined.Header())
 		c.lockedRound = -1
 		c.lockedValue = nil
 		c.validRound = -1
 		c.validValue = nil
 		c.messages.Reset()
+		c.verifications = nil
+		c.prevoteRecords = nil
 		c.futureRoundLock.Lock()
 		c.futureRound = make(map[int64][]message.Msg)
 		c.futurePower = make(map[int64]*message.AggregatedPower)
 		c.futureRoundLock.Unlock()
+		c.timing = heightTiming{}
 		// update height duration timer
+		now := time.Now()
 		if metrics.Enabled {
-			now := time.Now()
 			HeightTimer.Update(now.Sub(c.newHeight))
 			HeightBg.Add(now.Sub(c.newHeight).Nanoseconds())
-			c.newHeight = now
 		}
+		c.newHeight = now
 	}
 
-	c.proposeTimeout.Reset(Propose)
-	c.prevoteTimeout.Reset(Prevote)
-	c.precommitTimeout.Reset(Precommit)
-
 	c.sentProposal = false
 	c.sentPrevote = false
 	c.sentPrecommit = false
@@ -403,12 +485,12 @@ func (c *Core) setInitialState(r int64) {
 	c.setRound(r)
 
 	// update round duration timer
+	now := time.Now()
 	if metrics.Enabled {
-		now := time.Now()
 		RoundTimer.Update(now.Sub(c.newRound))
 		RoundBg.Add(now.Sub(c.newRound).Nanoseconds())
-		c.newRound = now
 	}
+	c.newRound = now
 }
 
 func (c *Core) SetStep(ctx context.Context, step Step) {
@@ -452,12 +534,15 @@ func (c *Core) SetStep(ctx context.Context, step Step) {
 			c.logger.Warn("Unexpected tendermint state transition", "c.step", c.step, "step", step)
 		}
 	}
+	c.timeStep(now.Sub(c.stepChange))
 	c.logger.Debug("Step change", "from", c.step.String(), "to", step.String(), "round", c.Round())
 	c.step = step
 	c.stepChange = now
 
 	// stop consensus timeouts
 	c.stopAllTimeouts()
+	c.journalRound()
+	c.publishSnapshot()
 
 	// if we are moving from propose to prevote step we need to check again line 34,36 and 44
 	// NOTE: this call to stepChangeChecks can cause recursion in the SetStep function.
@@ -483,6 +568,38 @@ func (c *Core) stopAllTimeouts() {
 	}
 }
 
+// scopeSends cancels the sends of the previous round, and of the previous height if a new height starts.
+func (c *Core) scopeSends(ctx context.Context, round int64) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if round == 0 || c.heightCtx == nil {
+		if c.heightCancel != nil {
+			c.heightCancel()
+		}
+		c.heightCtx, c.heightCancel = context.WithCancel(ctx)
+	}
+	if c.roundCancel != nil {
+		c.roundCancel()
+	}
+	c.roundCtx, c.roundCancel = context.WithCancel(c.heightCtx)
+}
+
+// sendContext returns the context scoping the sending of a message. The prevotes of the current round are
+// useless once the round is over. The proposals and the precommits are kept being sent until the end of
+// the height, as a past round can still be decided upon.
+func (c *Core) sendContext(msg message.Msg) context.Context {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	switch {
+	case c.heightCtx == nil:
+		return context.Background()
+	case msg.Code() == message.PrevoteCode && msg.R() == c.round:
+		return c.roundCtx
+	default:
+		return c.heightCtx
+	}
+}
+
 func (c *Core) setRound(round int64) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
@@ -490,11 +607,33 @@ func (c *Core) setRound(round int64) {
 	c.curRoundMessages = c.messages.GetOrCreate(round)
 }
 
+// setHeight sets the consensus height, which can only move forward.
 func (c *Core) setHeight(height *big.Int) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
+	if c.height != nil && height.Cmp(c.height) < 0 {
+		// Should never happen really. Let's panic to catch bugs.
+		panic(fmt.Sprintf("consensus height moving backwards from %v to %v", c.height, height))
+	}
 	c.height = height
 }
+
+// setHeightState moves the consensus to the height following the given header. The height, the last
+// header and the committee are updated together, so that the readers on other goroutines never
+// observe the height of one block along with the last header of another.
+func (c *Core) setHeightState(lastHeader *types.Header) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	height := new(big.Int).Add(lastHeader.Number, common.Big1)
+	if c.height != nil && height.Cmp(c.height) < 0 {
+		// Should never happen really. Let's panic to catch bugs.
+		panic(fmt.Sprintf("consensus height moving backwards from %v to %v", c.height, height))
+	}
+	c.height = height
+	c.committee.SetLastHeader(lastHeader)
+	c.lastHeader = lastHeader
+}
+
 func (c *Core) setCommitteeSet(set interfaces.Committee) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
@@ -525,6 +664,9 @@ func (c *Core) CommitteeSet() interfaces.Committee {
 	return c.committee
 }
 
+// LastHeader returns the header of the block preceding the current height. The header is never
+// modified, callers processing a message should read it once and work on that snapshot rather
+// than reading it again, as the height may have changed in between.
 func (c *Core) LastHeader() *types.Header {
 	c.stateMu.RLock()
 	defer c.stateMu.RUnlock()
@@ -612,14 +754,66 @@ func (c *Core) VotesPowerFor(h uint64, r int64, code uint8, v common.Hash) *mess
 	return power
 }
 
+// CurrentHeightMessages implements interfaces.Core.CurrentHeightMessages. The messages are read under
+// the round change lock, so that a concurrent height transition cannot mix the messages of two heights.
 func (c *Core) CurrentHeightMessages() []message.Msg {
+	start := time.Now()
+	c.roundChangeMu.Lock()
+	RoundChangeMuBg.Add(time.Since(start).Nanoseconds())
+	defer c.roundChangeMu.Unlock()
+
 	c.futureRoundLock.RLock()
 	var future []message.Msg
 	for _, msgs := range c.futureRound {
 		future = append(future, msgs...)
 	}
 	c.futureRoundLock.RUnlock()
-	return append(c.messages.All(), future...)
+	return sortMessages(append(c.messages.All(), future...))
+}
+
+// sortMessages orders the messages by round, code and lowest committee index of their signers, the
+// hash breaking the ties between the aggregates of a same lowest signer.
+func sortMessages(messages []message.Msg) []message.Msg {
+	type sortKey struct {
+		msg   message.Msg
+		index int
+		hash  common.Hash
+	}
+	keys := make([]sortKey, len(messages))
+	for i, msg := range messages {
+		keys[i] = sortKey{msg: msg, index: lowestSignerIndex(msg), hash: msg.Hash()}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch {
+		case a.msg.R() != b.msg.R():
+			return a.msg.R() < b.msg.R()
+		case a.msg.Code() != b.msg.Code():
+			return a.msg.Code() < b.msg.Code()
+		case a.index != b.index:
+			return a.index < b.index
+		}
+		return bytes.Compare(a.hash[:], b.hash[:]) < 0
+	})
+	for i := range keys {
+		messages[i] = keys[i].msg
+	}
+	return messages
+}
+
+func lowestSignerIndex(msg message.Msg) int {
+	switch m := msg.(type) {
+	case *message.Propose:
+		return m.SignerIndex()
+	case message.Vote:
+		signers := m.Signers()
+		for i := 0; i < signers.CommitteeSize(); i++ {
+			if signers.Contains(i) {
+				return i
+			}
+		}
+	}
+	return math.MaxInt
 }
 
 func (c *Core) Backend() interfaces.Backend {
@@ -638,7 +832,7 @@ func (c *Core) IsProposer() bool {
 }
 
 func (c *Core) BroadcastAll(msg message.Msg) {
-	c.Backend().Broadcast(c.CommitteeSet().Committee(), msg)
+	c.Backend().Broadcast(c.sendContext(msg), c.CommitteeSet().Committee(), msg)
 }
 
 type Broadcaster struct {
@@ -2,9 +2,9 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"math/rand"
-	"reflect"
 	"testing"
 	"time"
 
@@ -12,6 +12,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/autonity/autonity/common"
+	tdmcommittee "github.com/autonity/autonity/consensus/tendermint/core/committee"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core/types"
@@ -148,6 +149,111 @@ func TestCore_Setters(t *testing.T) {
 	})
 }
 
+// TestCore_HeightStateConcurrentAccess moves the core through heights while the height state is read
+// concurrently, as the sync loop and the aggregator do. It is meant to be run with the race detector.
+func TestCore_HeightStateConcurrentAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	const heights = 200
+
+	committee, _ := GenerateCommittee(4)
+	blocks := make([]*types.Block, heights+1)
+	for h := range blocks {
+		// the committee size changes with the height, for the readers to spot a torn snapshot
+		header := &types.Header{Number: big.NewInt(int64(h)), Committee: committee[:h%len(committee)+1]}
+		blocks[h] = types.NewBlockWithHeader(header)
+	}
+	head := 0
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().HeadBlock().DoAndReturn(func() *types.Block { return blocks[head] }).AnyTimes()
+
+	c := New(backendMock, nil, common.Address{}, log.Root(), false)
+	c.setCommitteeSet(tdmcommittee.NewWeightedRandomSamplingCommittee(blocks[0], nil, nil))
+	c.setInitialState(0)
+
+	done := make(chan struct{})
+	readers := make(chan error, 3)
+	read := func(check func() error) {
+		for {
+			select {
+			case <-done:
+				readers <- nil
+				return
+			default:
+			}
+			if err := check(); err != nil {
+				readers <- err
+				return
+			}
+		}
+	}
+	// sync loop
+	go read(func() error {
+		lastHeader := c.LastHeader()
+		if len(lastHeader.Committee) != int(lastHeader.Number.Uint64())%len(committee)+1 {
+			return fmt.Errorf("torn last header at height %v", lastHeader.Number)
+		}
+		if c.Height().Cmp(lastHeader.Number) <= 0 {
+			return fmt.Errorf("height %v not above the last header %v", c.Height(), lastHeader.Number)
+		}
+		return nil
+	})
+	// aggregator
+	go read(func() error {
+		h := c.Height().Uint64()
+		c.Power(h, 0)
+		c.VotesPower(h, 0, message.PrevoteCode)
+		return nil
+	})
+	// committee readers
+	go read(func() error {
+		set := c.CommitteeSet()
+		if set.Quorum().Sign() <= 0 {
+			return fmt.Errorf("empty committee quorum")
+		}
+		_, err := set.GetByIndex(0)
+		return err
+	})
+
+	for head = 1; head <= heights; head++ {
+		c.setInitialState(0)
+		require.Equal(t, blocks[head].Header(), c.LastHeader())
+	}
+	close(done)
+	for i := 0; i < cap(readers); i++ {
+		require.NoError(t, <-readers)
+	}
+}
+
+func TestSendContexts(t *testing.T) {
+	c := &Core{messages: message.NewMap()}
+	proposal := generateBlockProposal(0, common.Big1, -1, false, defaultSigner, testCommitteeMember)
+	prevote := message.NewPrevote(0, 1, proposal.Block().Hash(), defaultSigner, testCommitteeMember, 1)
+	precommit := message.NewPrecommit(0, 1, proposal.Block().Hash(), defaultSigner, testCommitteeMember, 1)
+
+	// the sends are not scoped before the first round starts
+	require.NoError(t, c.sendContext(prevote).Err())
+
+	c.scopeSends(context.Background(), 0)
+	proposalCtx, prevoteCtx, precommitCtx := c.sendContext(proposal), c.sendContext(prevote), c.sendContext(precommit)
+
+	// the prevotes of the previous round are stale, the other messages are kept being sent until the end of the height
+	c.setRound(1)
+	c.scopeSends(context.Background(), 1)
+	require.Error(t, prevoteCtx.Err())
+	require.NoError(t, proposalCtx.Err())
+	require.NoError(t, precommitCtx.Err())
+	require.NoError(t, c.sendContext(prevote).Err())
+	require.True(t, c.sendContext(prevote) == c.heightCtx, "prevotes of a past round are scoped to the height")
+
+	// all the messages of the previous height are stale
+	c.setRound(0)
+	c.scopeSends(context.Background(), 0)
+	require.Error(t, proposalCtx.Err())
+	require.Error(t, precommitCtx.Err())
+	require.NoError(t, c.sendContext(prevote).Err())
+}
+
 // future round message processing
 func TestProcessFuture(t *testing.T) {
 	t.Run("future round msg is processed", func(t *testing.T) {
@@ -156,16 +262,7 @@ func TestProcessFuture(t *testing.T) {
 
 		msg := message.NewPropose(1, 2, 1, types.NewBlockWithHeader(&types.Header{}), defaultSigner, testCommitteeMember)
 
-		expected := backlogMessageEvent{
-			msg: msg,
-		}
-
-		evChan := make(chan any, 1)
-
 		backendMock := interfaces.NewMockBackend(ctrl)
-		backendMock.EXPECT().Post(expected).Do(func(ev any) {
-			evChan <- ev
-		})
 
 		c := &Core{
 			logger:      log.New("backend", "test", "id", 0),
@@ -179,17 +276,14 @@ func TestProcessFuture(t *testing.T) {
 		}
 
 		c.setLastHeader(&types.Header{Committee: testCommittee})
+		subscribeFeeds(t, c)
 
 		c.futureRound[msg.R()] = append(c.futureRound[msg.R()], msg)
 		c.processFuture(0, 1) // scenario: we just switched from round 0 --> 1
 
 		timeout := time.NewTimer(2 * time.Second)
 		select {
-		case ev := <-evChan:
-			e, ok := ev.(backlogMessageEvent)
-			if !ok {
-				t.Errorf("unexpected event comes: %v", reflect.TypeOf(ev))
-			}
+		case e := <-c.feeds.backlogCh:
 			if e.msg.Hash() != msg.Hash() {
 				t.Errorf("message hash mismatch: have %v, want %v", e.msg.Hash(), msg.Hash())
 			}
@@ -203,16 +297,7 @@ func TestProcessFuture(t *testing.T) {
 
 		msg := message.NewPropose(1, 2, 1, types.NewBlockWithHeader(&types.Header{}), defaultSigner, testCommitteeMember)
 
-		expected := backlogMessageEvent{
-			msg: msg,
-		}
-
-		evChan := make(chan any, 1)
-
 		backendMock := interfaces.NewMockBackend(ctrl)
-		backendMock.EXPECT().Post(expected).Do(func(ev any) {
-			evChan <- ev
-		})
 
 		c := &Core{
 			logger:      log.New("backend", "test", "id", 0),
@@ -226,17 +311,14 @@ func TestProcessFuture(t *testing.T) {
 		}
 
 		c.setLastHeader(&types.Header{Committee: testCommittee})
+		subscribeFeeds(t, c)
 
 		c.futureRound[msg.R()] = append(c.futureRound[msg.R()], msg)
 		c.processFuture(0, 3) // scenario: we just switched from round 0 --> 3
 
 		timeout := time.NewTimer(2 * time.Second)
 		select {
-		case ev := <-evChan:
-			e, ok := ev.(backlogMessageEvent)
-			if !ok {
-				t.Errorf("unexpected event comes: %v", reflect.TypeOf(ev))
-			}
+		case e := <-c.feeds.backlogCh:
 			if e.msg.Hash() != msg.Hash() {
 				t.Errorf("message hash mismatch: have %v, want %v", e.msg.Hash(), msg.Hash())
 			}
@@ -461,3 +543,91 @@ func TestProcessFuture(t *testing.T) {
 	})*/
 
 }
+
+func TestCurrentHeightMessages(t *testing.T) {
+	env := NewConsensusEnv(t, func(e *ConsensusENV) { e.curRound = 1 })
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	env.setupCore(backendMock, env.clientAddress)
+	c := env.core
+
+	committee := env.committee.Committee()
+	height := env.curHeight.Uint64()
+	// the votes are for distinct values, so that they are not aggregated
+	vote := func(code uint8, r int64, index int) message.Msg {
+		member := &committee[index]
+		signer := makeSigner(env.keys[member.Address].consensus)
+		value := common.Hash{byte(index + 1)}
+		if code == message.PrevoteCode {
+			return message.NewPrevote(r, height, value, signer, member, len(committee))
+		}
+		return message.NewPrecommit(r, height, value, signer, member, len(committee))
+	}
+	proposer := env.committee.GetProposer(1)
+	proposal := generateBlockProposal(1, env.curHeight, -1, false, makeSigner(env.keys[proposer.Address].consensus), &proposer)
+
+	prevote10, prevote11, prevote02 := vote(message.PrevoteCode, 1, 0), vote(message.PrevoteCode, 1, 1), vote(message.PrevoteCode, 0, 2)
+	precommit11, precommit00 := vote(message.PrecommitCode, 1, 1), vote(message.PrecommitCode, 0, 0)
+	future := vote(message.PrevoteCode, 2, 0)
+
+	// the messages are stored out of order, the client's own ones included
+	c.messages.GetOrCreate(1).AddPrecommit(precommit11.(*message.Precommit))
+	c.messages.GetOrCreate(1).AddPrevote(prevote11.(*message.Prevote))
+	c.futureRound[2] = append(c.futureRound[2], future)
+	c.messages.GetOrCreate(1).AddPrevote(prevote10.(*message.Prevote))
+	c.messages.GetOrCreate(0).AddPrecommit(precommit00.(*message.Precommit))
+	c.messages.GetOrCreate(1).SetProposal(proposal, true)
+	c.messages.GetOrCreate(0).AddPrevote(prevote02.(*message.Prevote))
+
+	require.Equal(t, []message.Msg{prevote02, precommit00, proposal, prevote10, prevote11, precommit11, future}, c.CurrentHeightMessages())
+}
+
+func TestCurrentHeightMessagesSnapshot(t *testing.T) {
+	env := NewConsensusEnv(t, nil)
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	env.setupCore(backendMock, env.clientAddress)
+	c := env.core
+
+	committee := env.committee.Committee()
+	member := &committee[0]
+	signer := makeSigner(env.keys[member.Address].consensus)
+	addMessages := func(height uint64) {
+		c.messages.GetOrCreate(0).AddPrevote(message.NewPrevote(0, height, common.Hash{}, signer, member, len(committee)))
+		c.futureRoundLock.Lock()
+		c.futureRound[1] = append(c.futureRound[1], message.NewPrevote(1, height, common.Hash{}, signer, member, len(committee)))
+		c.futureRoundLock.Unlock()
+	}
+	height := env.curHeight.Uint64()
+	addMessages(height)
+
+	// the height is committed, the transition to the next height is held half-way
+	entered, release := make(chan struct{}), make(chan struct{})
+	backendMock.EXPECT().HeadBlock().DoAndReturn(func() *types.Block {
+		close(entered)
+		<-release
+		return types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height), Committee: committee})
+	})
+	transitioned := make(chan struct{})
+	go func() {
+		c.setInitialState(0)
+		addMessages(height + 1)
+		close(transitioned)
+	}()
+	<-entered
+
+	// the snapshot waits for the transition, rather than mixing the messages of both heights
+	snapshot := make(chan []message.Msg)
+	go func() { snapshot <- c.CurrentHeightMessages() }()
+	select {
+	case <-snapshot:
+		t.Fatal("snapshot taken during the height transition")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	for _, msg := range <-snapshot {
+		require.Equal(t, height+1, msg.H())
+	}
+	<-transitioned
+	require.Len(t, c.CurrentHeightMessages(), 2)
+}
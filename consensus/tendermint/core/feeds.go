@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+
+	"github.com/autonity/autonity/consensus/tendermint/events"
+	"github.com/autonity/autonity/event"
+)
+
+// feedBuffer is the capacity of the channels the main loop receives the internal events on.
+const feedBuffer = 16
+
+// feeds carry the events internal to core to the main loop. Unlike the event mux of the backend, a feed
+// does not allocate per event and a send blocks until the event is delivered: no event is lost while core
+// runs, and the senders return immediately once the subscriptions are closed. The backend subscription
+// is only used for the events crossing modules, such as events.MessageEvent.
+type feeds struct {
+	timeouts event.Feed // TimeoutEvent
+	backlog  event.Feed // backlogMessageEvent
+	commits  event.Feed // events.CommitEvent
+
+	timeoutCh   chan TimeoutEvent
+	backlogCh   chan backlogMessageEvent
+	committedCh chan events.CommitEvent
+	scope       *event.SubscriptionScope
+}
+
+// subscribe subscribes the main loop channels to the feeds, until ctx is done or close is called.
+func (f *feeds) subscribe(ctx context.Context) {
+	f.timeoutCh = make(chan TimeoutEvent, feedBuffer)
+	f.backlogCh = make(chan backlogMessageEvent, feedBuffer)
+	f.committedCh = make(chan events.CommitEvent, 1)
+	f.scope = new(event.SubscriptionScope)
+	f.scope.Track(f.timeouts.Subscribe(f.timeoutCh))
+	f.scope.Track(f.backlog.Subscribe(f.backlogCh))
+	f.scope.Track(f.commits.Subscribe(f.committedCh))
+	scope := f.scope
+	context.AfterFunc(ctx, scope.Close)
+}
+
+// close unsubscribes the main loop channels, releasing the blocked senders. The events left in the
+// channels are dropped with them.
+func (f *feeds) close() {
+	if f.scope != nil {
+		f.scope.Close()
+	}
+}
+
+// sendTimeout delivers the expiry of a step timeout to the main loop.
+func (c *Core) sendTimeout(ev TimeoutEvent) {
+	c.feeds.timeouts.Send(ev)
+}
+
+// sendBacklog re-injects a buffered message into the main loop.
+func (c *Core) sendBacklog(ev backlogMessageEvent) {
+	c.feeds.backlog.Send(ev)
+}
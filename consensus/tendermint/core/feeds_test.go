@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/log"
+)
+
+// subscribeFeeds subscribes the channels of the main loop to the feeds of c, for the test to receive the
+// internal events.
+func subscribeFeeds(t *testing.T, c *Core) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	c.feeds.subscribe(ctx)
+}
+
+func TestTimeoutFeedStress(t *testing.T) {
+	const (
+		senders = 100
+		total   = 100_000
+	)
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	mux := event.NewTypeMuxSilent(nil, log.Root())
+	backendMock.EXPECT().Subscribe(gomock.Any()).Return(mux.Subscribe(events.MessageEvent{})).AnyTimes()
+
+	c := New(backendMock, nil, common.HexToAddress("0x0123456789"), log.Root(), false)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.subscribeEvents(ctx)
+
+	// the consumer stands for the main loop, it stops receiving once all the events are counted
+	var received atomic.Int64
+	counted := make(chan struct{})
+	c.loops.run("timeouts", func() {
+		for {
+			select {
+			case <-c.feeds.timeoutCh:
+				if received.Add(1) == total {
+					close(counted)
+					<-ctx.Done()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	send := func(wg *sync.WaitGroup, events int) {
+		for i := 0; i < senders; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < events; j++ {
+					c.sendTimeout(TimeoutEvent{RoundWhenCalled: int64(j), HeightWhenCalled: big.NewInt(int64(i)), Step: Step(j % 3)})
+				}
+			}(i)
+		}
+	}
+	var sent sync.WaitGroup
+	send(&sent, total/senders)
+	select {
+	case <-counted:
+	case <-time.After(30 * time.Second):
+		t.Fatalf("timeout events lost: received %d of %d", received.Load(), total)
+	}
+	sent.Wait()
+	require.Equal(t, int64(total), received.Load())
+
+	// the senders blocked on the feed once the channel is full are released by the shutdown
+	var blocked sync.WaitGroup
+	send(&blocked, 1)
+	stopped := make(chan error)
+	go func() { stopped <- c.Stop() }()
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop deadlocked")
+	}
+	released := make(chan struct{})
+	go func() {
+		blocked.Wait()
+		close(released)
+	}()
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout senders still blocked after Stop")
+	}
+
+	// the timeouts expiring after the shutdown are dropped without blocking
+	c.sendTimeout(TimeoutEvent{HeightWhenCalled: common.Big0, Step: Propose})
+}
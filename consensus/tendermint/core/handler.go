@@ -3,20 +3,25 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
-	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/tendermint/core/committee"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
 	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/metrics"
 )
 
-// todo: resolve proper tendermint state synchronization timeout from block period.
-const syncTimeOut = 30 * time.Second
+// The sync requests are retried while the view stays the same, backing off exponentially so that a network
+// wide stall does not turn into synchronized bursts of requests.
+const (
+	syncInitialBackoff = 10 * time.Second
+	syncMaxBackoff     = 2 * time.Minute
+)
 
 // Start implements core.Tendermint.Start
 func (c *Core) Start(ctx context.Context, contract *autonity.ProtocolContracts) {
@@ -26,18 +31,22 @@ func (c *Core) Start(ctx context.Context, contract *autonity.ProtocolContracts)
 		c.backend.BlockChain())
 	c.setCommitteeSet(committeeSet)
 	ctx, c.cancel = context.WithCancel(ctx)
-	c.subscribeEvents()
+	c.subscribeEvents(ctx)
 
-	// Start a new round from last height + 1
-	c.StartRound(ctx, 0)
+	// Resume the journaled round of the height in progress, or start a new round from last height + 1
+	if journaled := c.journaled(); journaled != nil {
+		c.startRound(ctx, int64(journaled.state.Round), journaled)
+	} else {
+		c.StartRound(ctx, 0)
+	}
 
 	// Tendermint Finite State Machine discrete event loop
-	go c.mainEventLoop(ctx)
-	go c.backend.HandleUnhandledMsgs(ctx)
+	c.loops.run("main", func() { c.mainEventLoop(ctx) })
+	c.loops.run("unhandled messages", func() { c.backend.HandleUnhandledMsgs(ctx) })
 }
 
 // Stop implements Core.Engine.Stop
-func (c *Core) Stop() {
+func (c *Core) Stop() error {
 	c.logger.Debug("Stopping Tendermint Core", "addr", c.address.String())
 	c.stopAllTimeouts()
 	c.cancel()
@@ -45,59 +54,33 @@ func (c *Core) Stop() {
 	c.unsubscribeEvents()
 
 	// Ensure all event handling go routines exit
-	<-c.stopped
-	<-c.stopped
+	if stuck := c.loops.wait(stopTimeout); len(stuck) > 0 {
+		c.logger.Error("Tendermint Core event loops failed to exit", "loops", stuck, "timeout", stopTimeout)
+		return ErrStopTimeout
+	}
+	return nil
 }
 
-func (c *Core) subscribeEvents() {
+func (c *Core) subscribeEvents(ctx context.Context) {
 	c.messageSub = c.backend.Subscribe(
 		events.MessageEvent{},
-		backlogMessageEvent{},
-		StateRequestEvent{})
+		StateRequestEvent{},
+		snapshotRequestEvent{},
+		supportBundleRequestEvent{},
+		pendingProposalRequestEvent{},
+		retriggerProposalEvent{})
 	c.candidateBlockCh = make(chan events.NewCandidateBlockEvent, 1)
-	c.committedCh = make(chan events.CommitEvent, 1)
-	c.timeoutEventSub = c.backend.Subscribe(TimeoutEvent{})
+	c.feeds.subscribe(ctx)
 	c.syncEventSub = c.backend.Subscribe(events.SyncEvent{})
 }
 
 // Unsubscribe all
 func (c *Core) unsubscribeEvents() {
 	c.messageSub.Unsubscribe()
-	c.timeoutEventSub.Unsubscribe()
+	c.feeds.close()
 	c.syncEventSub.Unsubscribe()
 }
 
-func shouldDisconnectSender(err error) bool {
-	switch {
-	/* //TODO(lorenzo) refinements2, double check. Also this is kinda broken due to aggregator not sending an ErrCh
-	case errors.Is(err, constants.ErrFutureHeightMessage):
-		fallthrough
-	*/
-	case errors.Is(err, constants.ErrOldHeightMessage):
-		fallthrough
-	case errors.Is(err, constants.ErrOldRoundMessage):
-		fallthrough
-	case errors.Is(err, constants.ErrFutureRoundMessage):
-		fallthrough
-	case errors.Is(err, constants.ErrNilPrevoteSent):
-		fallthrough
-	case errors.Is(err, constants.ErrNilPrecommitSent):
-		fallthrough
-	case errors.Is(err, constants.ErrMovedToNewRound):
-		fallthrough
-	case errors.Is(err, constants.ErrHeightClosed):
-		fallthrough
-	case errors.Is(err, constants.ErrAlreadyHaveBlock):
-		fallthrough
-	case errors.Is(err, consensus.ErrPrunedAncestor):
-		fallthrough
-	case errors.Is(err, constants.ErrAlreadyHaveProposal):
-		return false
-	default:
-		return true
-	}
-}
-
 func recordMessageProcessingTime(code uint8, start time.Time) {
 	if !metrics.Enabled {
 		return
@@ -136,19 +119,35 @@ func (c *Core) GossipComplexAggregate(code uint8, round int64, value common.Hash
 	case message.PrevoteCode:
 		aggregatePrevote := c.messages.GetOrCreate(round).PrevoteFor(value)
 		c.messages.GetOrCreate(round).AddPrevote(aggregatePrevote)
-		go c.backend.Gossip(c.CommitteeSet().Committee(), aggregatePrevote)
+		go c.backend.Gossip(c.sendContext(aggregatePrevote), c.CommitteeSet().Committee(), aggregatePrevote)
 	case message.PrecommitCode:
 		aggregatePrecommit := c.messages.GetOrCreate(round).PrecommitFor(value)
 		c.messages.GetOrCreate(round).AddPrecommit(aggregatePrecommit)
-		go c.backend.Gossip(c.CommitteeSet().Committee(), aggregatePrecommit)
+		go c.backend.Gossip(c.sendContext(aggregatePrecommit), c.CommitteeSet().Committee(), aggregatePrecommit)
 	}
 }
 
 func (c *Core) mainEventLoop(ctx context.Context) {
-	go c.syncLoop(ctx)
+	c.loops.run("sync", func() { c.syncLoop(ctx) })
+
+	// the state gauges are refreshed from the state snapshot
+	var stateMetrics <-chan time.Time
+	if metrics.Enabled {
+		ticker := time.NewTicker(stateMetricsInterval)
+		defer ticker.Stop()
+		stateMetrics = ticker.C
+	}
+
+	// ready to be received from while current height messages are queued
+	closed := make(chan struct{})
+	close(closed)
 
 eventLoop:
 	for {
+		var queued <-chan struct{}
+		if c.msgQueue.pending(c.Height().Uint64()) {
+			queued = closed
+		}
 		select {
 		case ev, ok := <-c.candidateBlockCh:
 			if !ok {
@@ -164,114 +163,164 @@ eventLoop:
 			if !ok {
 				break eventLoop
 			}
-			start := time.Now()
 			// An event arrived, process content
 			switch e := ev.Data.(type) {
 			case events.MessageEvent:
 				if metrics.Enabled {
 					AggregatorCoreTransitBg.Add(time.Since(e.Posted).Nanoseconds())
 				}
-				msg := e.Message
-
-				var hadQuorum bool
-				if !c.noGossip {
-					// check if we have quorum for message type for this round
-					hadQuorum = c.quorumFor(msg.Code(), msg.R(), msg.Value())
-				}
-
-				if err := c.handleMsg(ctx, msg); err != nil {
-					c.logger.Debug("MessageEvent payload failed", "err", err)
-					// filter errors which needs remote peer disconnection
-					if shouldDisconnectSender(err) {
-						tryDisconnect(e.ErrCh, err)
-					}
+				// the old height messages are only gossiped, the others wait for their height in the queue
+				if e.Message.H() < c.Height().Uint64() {
+					c.gossipOldHeightMessage(e)
 					break
 				}
-
-				if !c.noGossip {
-					if !hadQuorum {
-						// if we did not have quorum and we reached it now
-						// gossip the (complex) aggregate with quorum to everyone instead of the current message
-						hasQuorum := c.quorumFor(msg.Code(), msg.R(), msg.Value())
-						if hasQuorum {
-							c.GossipComplexAggregate(msg.Code(), msg.R(), msg.Value())
-							recordMessageProcessingTime(msg.Code(), start)
-							break // do not gossip single message, only complex aggregate
-						}
-					}
-
-					// gossip message. We should arrive here only if we did not already gossip a complex aggregate
-					go c.backend.Gossip(c.CommitteeSet().Committee(), msg)
-					recordMessageProcessingTime(msg.Code(), start)
-				}
-			case backlogMessageEvent:
-				// TODO(lorenzo) refinements, should we check for disconnection also here?
-				// I am not sure we can get the error ch though
-
-				msg := e.msg
-
-				var hadQuorum bool
-				if !c.noGossip {
-					// check if we have quorum for message type for this round
-					hadQuorum = c.quorumFor(msg.Code(), msg.R(), msg.Value())
-				}
-
-				c.logger.Debug("Handling consensus backlog event")
-				if err := c.handleMsg(ctx, msg); err != nil {
-					c.logger.Debug("BacklogEvent message handling failed", "err", err)
-					continue
-				}
-
-				if !c.noGossip {
-					if !hadQuorum {
-						// if we did not have quorum and we reached it now
-						// gossip the (complex) aggregate with quorum to everyone instead of the current message
-						hasQuorum := c.quorumFor(msg.Code(), msg.R(), msg.Value())
-						if hasQuorum {
-							c.GossipComplexAggregate(msg.Code(), msg.R(), msg.Value())
-							recordMessageProcessingTime(msg.Code(), start)
-							break // do not gossip single message, only complex aggregate
-						}
-					}
-
-					// gossip message. We should arrive here only if we did not already gossip a complex aggregate
-					go c.backend.Gossip(c.CommitteeSet().Committee(), msg)
-					recordMessageProcessingTime(msg.Code(), start)
-				}
+				c.msgQueue.push(e, c.Height().Uint64())
 			case StateRequestEvent:
 				// Process Tendermint state dump request.
 				c.handleStateDump(e)
+			case snapshotRequestEvent:
+				c.handleSnapshot(e)
+			case supportBundleRequestEvent:
+				c.handleSupportBundle(e)
+			case pendingProposalRequestEvent:
+				c.handlePendingProposalState(e)
+			case retriggerProposalEvent:
+				c.handleRetriggerProposal(ctx, e)
 			}
-		case ev, ok := <-c.timeoutEventSub.Chan():
-			if !ok {
-				break eventLoop
-			}
-			if timeoutE, ok := ev.Data.(TimeoutEvent); ok {
-				// if we already decided on this height block, ignore the timeout. It is useless by now.
-				if c.step == PrecommitDone {
-					c.logTimeoutEvent("Timer expired while at PrecommitDone step, ignoring", "", timeoutE)
-					continue
-				}
-				switch timeoutE.Step {
-				case Propose:
-					c.handleTimeoutPropose(ctx, timeoutE)
-				case Prevote:
-					c.handleTimeoutPrevote(ctx, timeoutE)
-				case Precommit:
-					c.handleTimeoutPrecommit(ctx, timeoutE)
-				}
+		case e := <-c.feeds.backlogCh:
+			c.handleBacklogEvent(ctx, e)
+		case <-queued:
+			e, _ := c.msgQueue.pop(c.Height().Uint64())
+			c.handleMessageEvent(ctx, e)
+		case timeoutE := <-c.feeds.timeoutCh:
+			// if we already decided on this height block, ignore the timeout. It is useless by now.
+			if c.step == PrecommitDone {
+				c.logTimeoutEvent("Timer expired while at PrecommitDone step, ignoring", "", timeoutE)
+				continue
 			}
-		case _, ok := <-c.committedCh:
-			if !ok {
-				break eventLoop
+			switch timeoutE.Step {
+			case Propose:
+				c.handleTimeoutPropose(ctx, timeoutE)
+			case Prevote:
+				c.handleTimeoutPrevote(ctx, timeoutE)
+			case Precommit:
+				c.handleTimeoutPrecommit(ctx, timeoutE)
 			}
+		case <-c.feeds.committedCh:
 			c.precommiter.HandleCommit(ctx)
+		case <-c.pacing:
+			c.StartRound(ctx, 0)
+		case <-stateMetrics:
+			updateStateMetrics(c.snapshot())
 		case <-ctx.Done():
 			c.logger.Debug("Tendermint core main loop stopped", "event", ctx.Err())
 			break eventLoop
 		}
 	}
-	c.stopped <- struct{}{}
+}
+
+// handleBacklogEvent applies a message re-injected from the buffers of core and gossips it, or the aggregate
+// it completed a quorum with.
+func (c *Core) handleBacklogEvent(ctx context.Context, e backlogMessageEvent) {
+	// TODO(lorenzo) refinements, should we check for disconnection also here?
+	// I am not sure we can get the error ch though
+	start := time.Now()
+	msg := e.msg
+
+	var hadQuorum bool
+	if !c.noGossip {
+		// check if we have quorum for message type for this round
+		hadQuorum = c.quorumFor(msg.Code(), msg.R(), msg.Value())
+	}
+
+	c.logger.Debug("Handling consensus backlog event")
+	if err := c.handleMsg(ctx, msg); err != nil {
+		c.logger.Debug("BacklogEvent message handling failed", "err", err)
+		c.backend.ReportMessageError(err, nil)
+		return
+	}
+
+	if !c.noGossip {
+		if !hadQuorum {
+			// if we did not have quorum and we reached it now
+			// gossip the (complex) aggregate with quorum to everyone instead of the current message
+			hasQuorum := c.quorumFor(msg.Code(), msg.R(), msg.Value())
+			if hasQuorum {
+				c.GossipComplexAggregate(msg.Code(), msg.R(), msg.Value())
+				recordMessageProcessingTime(msg.Code(), start)
+				return // do not gossip single message, only complex aggregate
+			}
+		}
+
+		// gossip message. We should arrive here only if we did not already gossip a complex aggregate
+		go c.backend.Gossip(c.sendContext(msg), c.CommitteeSet().Committee(), msg)
+		recordMessageProcessingTime(msg.Code(), start)
+	}
+}
+
+// handleMessageEvent applies a queued consensus message and gossips it, or the aggregate it completed
+// a quorum with.
+func (c *Core) handleMessageEvent(ctx context.Context, e events.MessageEvent) {
+	start := time.Now()
+	msg := e.Message
+	// the height may have advanced since the message was queued
+	if msg.H() < c.Height().Uint64() {
+		c.gossipOldHeightMessage(e)
+		return
+	}
+
+	var hadQuorum bool
+	if !c.noGossip {
+		// check if we have quorum for message type for this round
+		hadQuorum = c.quorumFor(msg.Code(), msg.R(), msg.Value())
+	}
+
+	if err := c.handleMsg(ctx, msg); err != nil {
+		c.logger.Debug("MessageEvent payload failed", "err", err)
+		e.Traces.Finish(msgtrace.Apply, err.Error())
+		// the backend decides whether the remote peer gets disconnected
+		c.backend.ReportMessageError(err, e.ErrCh)
+		return
+	}
+	if e.Traces != nil {
+		e.Traces.Record(msgtrace.Apply, fmt.Sprintf("handled, now at round %d step %s", c.Round(), c.Step()))
+	}
+
+	if c.noGossip {
+		e.Traces.Finish(msgtrace.Gossip, "gossip disabled")
+		return
+	}
+	if !hadQuorum {
+		// if we did not have quorum and we reached it now
+		// gossip the (complex) aggregate with quorum to everyone instead of the current message
+		if c.quorumFor(msg.Code(), msg.R(), msg.Value()) {
+			c.GossipComplexAggregate(msg.Code(), msg.R(), msg.Value())
+			e.Traces.Finish(msgtrace.Gossip, "quorum reached, complex aggregate gossiped")
+			recordMessageProcessingTime(msg.Code(), start)
+			return // do not gossip single message, only complex aggregate
+		}
+	}
+
+	// gossip message. We should arrive here only if we did not already gossip a complex aggregate
+	go c.backend.Gossip(c.sendContext(msg), c.CommitteeSet().Committee(), msg)
+	e.Traces.Finish(msgtrace.Gossip, "gossiped")
+	recordMessageProcessingTime(msg.Code(), start)
+}
+
+// gossipOldHeightMessage relays a message of a height already decided without applying it, the peers
+// lagging behind can still use it.
+func (c *Core) gossipOldHeightMessage(e events.MessageEvent) {
+	if c.noGossip {
+		e.Traces.Finish(msgtrace.Gossip, "old height, gossip disabled")
+		return
+	}
+	go c.backend.Gossip(context.Background(), c.CommitteeSet().Committee(), e.Message)
+	e.Traces.Finish(msgtrace.Gossip, "old height, gossiped")
+}
+
+// MessageQueue returns the queue of the consensus messages waiting to be handled.
+func (c *Core) MessageQueue() *MessageQueue {
+	return c.msgQueue
 }
 
 func (c *Core) syncLoop(ctx context.Context) {
@@ -279,7 +328,9 @@ func (c *Core) syncLoop(ctx context.Context) {
 		this method is responsible for asking the network to send us the current consensus state
 		and to process sync queries events.
 	*/
-	timer := time.NewTimer(syncTimeOut)
+	backoff := syncInitialBackoff
+	timer := c.clock.NewTimer(backoff)
+	defer timer.Stop()
 
 	round := c.Round()
 	height := c.Height()
@@ -290,19 +341,22 @@ func (c *Core) syncLoop(ctx context.Context) {
 eventLoop:
 	for {
 		select {
-		case <-timer.C:
+		case <-timer.C():
 			currentRound := c.Round()
 			currentHeight := c.Height()
 
-			// we only ask for sync if the current view stayed the same for the past 10 seconds
+			// we only ask for sync if the current view stayed the same since the last check
 			if currentHeight.Cmp(height) == 0 && currentRound == round {
 				c.logger.Warn("⚠️ Consensus liveliness lost")
-				c.logger.Warn("Broadcasting sync request..")
+				c.logger.Warn("Broadcasting sync request..", "stalled", common.PrettyDuration(backoff))
 				c.backend.AskSync(c.LastHeader())
+				backoff = min(2*backoff, syncMaxBackoff)
+			} else {
+				backoff = syncInitialBackoff
 			}
 			round = currentRound
 			height = currentHeight
-			timer = time.NewTimer(syncTimeOut)
+			timer.Reset(backoff)
 
 		case ev, ok := <-c.syncEventSub.Chan():
 			if !ok {
@@ -310,15 +364,13 @@ eventLoop:
 			}
 			event := ev.Data.(events.SyncEvent)
 			c.logger.Debug("Processing sync message", "from", event.Addr)
-			c.backend.SyncPeer(event.Addr)
+			c.backend.SyncPeer(event.Addr, event.Request)
 		case <-ctx.Done():
 			c.logger.Debug("syncLoop is stopped", "event", ctx.Err())
 			break eventLoop
 
 		}
 	}
-
-	c.stopped <- struct{}{}
 }
 
 // SendEvent sends event to mux
@@ -327,21 +379,22 @@ func (c *Core) SendEvent(ev any) {
 }
 
 func (c *Core) handleMsg(ctx context.Context, msg message.Msg) error {
-	// These checks need to be repeated here due to backlogged messages being re-injected
-	if c.Height().Uint64() > msg.H() {
+	// The admission needs to be repeated here due to backlogged messages being re-injected
+	if err := AdmitMessage(msg, c.Height().Uint64()); err != nil {
+		// the backend buffers the future height messages until their height
+		if errors.Is(err, constants.ErrFutureHeightMessage) {
+			panic("Processing future height message")
+		}
 		// TODO(lorenzo) should we gossip old height messages?
-		c.logger.Debug("ignoring stale consensus message", "msg", msg.String(), "height", c.Height().Uint64())
-		return constants.ErrOldHeightMessage
-	}
-
-	if c.Height().Uint64() < msg.H() {
-		panic("Processing future height message")
+		c.logger.Debug("ignoring inadmissible consensus message", "msg", msg.String(), "height", c.Height().Uint64(), "err", err)
+		return err
 	}
 
 	// if we already decided on this height block, discard the message. It is useless by now.
 	if c.step == PrecommitDone {
 		return constants.ErrHeightClosed
 	}
+	c.countMessage(msg)
 
 	var err error
 	switch m := msg.(type) {
@@ -389,16 +442,3 @@ func (c *Core) handleMsg(ctx context.Context, msg message.Msg) error {
 
 	return err
 }
-
-func tryDisconnect(errorCh chan<- error, err error) {
-	//TODO(lorenzo) refinements2, if aggregated vote or local message, we will have no error channel.
-	// maybe I can send back the error to the aggregator so the he can do the disconnection and the removal of messages
-	if errorCh == nil {
-		return
-	}
-
-	select {
-	case errorCh <- err:
-	default: // do nothing
-	}
-}
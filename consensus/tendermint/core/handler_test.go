@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/mclock"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
@@ -22,14 +24,14 @@ import (
 )
 
 type testCase struct {
-	id               uint64
-	round            int64
-	height           *big.Int
-	step             Step
-	message          message.Msg
-	outcome          error
-	panic            bool
-	shouldDisconnect bool
+	id      uint64
+	round   int64
+	height  *big.Int
+	step    Step
+	message message.Msg
+	outcome error
+	panic   bool
+	class   constants.ErrorClass
 }
 
 func (tc *testCase) String() string {
@@ -72,7 +74,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrevote(1, 2),
 			nil,
 			false,
-			false,
+			constants.ClassInternal,
 		},
 		{
 			1,
@@ -82,7 +84,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrevote(2, 2),
 			constants.ErrFutureRoundMessage,
 			false,
-			false,
+			constants.ClassFuture,
 		},
 		{
 			2,
@@ -91,8 +93,8 @@ func TestHandleMessage(t *testing.T) {
 			Propose,
 			createPrevote(1, 5),
 			nil,
-			true, // future height should panic
-			true, // doesn't matter
+			true,                    // future height should panic
+			constants.ClassInternal, // doesn't matter
 		},
 		{
 			3,
@@ -102,7 +104,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrevote(0, 2),
 			nil,
 			false,
-			false,
+			constants.ClassInternal,
 		},
 		{
 			4,
@@ -112,7 +114,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrecommit(0, 2),
 			nil,
 			false,
-			false,
+			constants.ClassInternal,
 		},
 		{
 			5,
@@ -122,7 +124,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrecommit(20, 2),
 			constants.ErrFutureRoundMessage,
 			false,
-			false,
+			constants.ClassFuture,
 		},
 		{
 			6,
@@ -132,7 +134,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrecommit(1, 1),
 			constants.ErrOldHeightMessage,
 			false,
-			false,
+			constants.ClassStaleHeight,
 		},
 		{
 			7,
@@ -142,7 +144,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrecommit(2, 2),
 			constants.ErrHeightClosed,
 			false,
-			false,
+			constants.ClassStaleHeight,
 		},
 		{
 			8,
@@ -152,7 +154,7 @@ func TestHandleMessage(t *testing.T) {
 			createPrecommit(1, 2),
 			constants.ErrOldRoundMessage,
 			false,
-			false,
+			constants.ClassStaleRound,
 		},
 		{
 			9,
@@ -162,7 +164,7 @@ func TestHandleMessage(t *testing.T) {
 			message.NewPropose(1, 2, -1, types.NewBlockWithHeader(&types.Header{}), makeSigner(senderKey), &sender),
 			constants.ErrNotFromProposer,
 			false,
-			true,
+			constants.ClassNotFromProposer,
 		},
 	}
 
@@ -209,11 +211,11 @@ func TestHandleMessage(t *testing.T) {
 			}
 
 			if err != nil {
-				// check if disconnection is required
-				disconnect := shouldDisconnectSender(err)
-				if tc.shouldDisconnect != disconnect {
+				// check the error class, the backend decides on the disconnection from it
+				class := constants.ClassOf(err)
+				if tc.class != class {
 					t.Log(tc.String())
-					t.Fatal("unexpected behaviour, shouldDisconnectSender returning", "disconnect=", disconnect, ", expecting=", tc.shouldDisconnect)
+					t.Fatal("unexpected behaviour, handleMsg returning", "class=", class, ", expecting=", tc.class)
 				}
 
 				if err == constants.ErrFutureRoundMessage {
@@ -292,11 +294,110 @@ func TestCoreStopDoesntPanic(t *testing.T) {
 	backendMock.EXPECT().Subscribe(gomock.Any()).Return(sub).MaxTimes(5)
 
 	c := New(backendMock, nil, common.HexToAddress("0x0123456789"), log.Root(), false)
-	_, c.cancel = context.WithCancel(context.Background())
-	c.subscribeEvents()
-	c.stopped <- struct{}{}
-	c.stopped <- struct{}{}
-	c.stopped <- struct{}{}
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+	c.subscribeEvents(ctx)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestCoreStopTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	backendMock := interfaces.NewMockBackend(ctrl)
+	eMux := event.NewTypeMuxSilent(nil, log.Root())
+	backendMock.EXPECT().Subscribe(gomock.Any()).Return(eMux.Subscribe(events.MessageEvent{})).MaxTimes(5)
+
+	defer func(timeout time.Duration) { stopTimeout = timeout }(stopTimeout)
+	stopTimeout = 100 * time.Millisecond
+
+	c := New(backendMock, nil, common.HexToAddress("0x0123456789"), log.Root(), false)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.subscribeEvents(ctx)
+
+	// one loop exits with the context, the other one is stuck
+	release := make(chan struct{})
+	defer close(release)
+	c.loops.run("exiting", func() { <-ctx.Done() })
+	c.loops.run("stuck", func() { <-release })
+
+	stopped := make(chan error)
+	go func() { stopped <- c.Stop() }()
+	select {
+	case err := <-stopped:
+		require.ErrorIs(t, err, ErrStopTimeout)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop deadlocked")
+	}
+	require.Equal(t, []string{"stuck"}, c.loops.wait(0))
+}
+
+func TestSyncLoopBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	backendMock := interfaces.NewMockBackend(ctrl)
+	clock := new(mclock.Simulated)
+	asks := make(chan mclock.AbsTime, 16)
+	backendMock.EXPECT().AskSync(gomock.Any()).Do(func(*types.Header) { asks <- clock.Now() }).AnyTimes()
+
+	c := New(backendMock, nil, common.HexToAddress("0x0123456789"), log.Root(), false)
+	c.clock = clock
+	c.setLastHeader(&types.Header{Number: common.Big0})
+	c.setHeight(common.Big1)
+	mux := event.NewTypeMuxSilent(nil, log.Root())
+	c.syncEventSub = mux.Subscribe(events.SyncEvent{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.syncLoop(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	requireAsk := func() {
+		t.Helper()
+		select {
+		case at := <-asks:
+			require.Equal(t, clock.Now(), at)
+		case <-time.After(5 * time.Second):
+			t.Fatal("sync not requested")
+		}
+	}
+	// advance moves the clock to the next sync check, which must not fire any earlier
+	advance := func(interval time.Duration) {
+		t.Helper()
+		clock.WaitForTimers(1)
+		clock.Run(interval - time.Millisecond)
+		require.Equal(t, 1, clock.ActiveTimers(), "sync check before %v", interval)
+		clock.Run(time.Millisecond)
+		// the timer is rescheduled once the check is done
+		clock.WaitForTimers(1)
+	}
+
+	// asked on start, then backing off while the view stays the same
+	requireAsk()
+	for _, interval := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 2 * time.Minute, 2 * time.Minute} {
+		advance(interval)
+		requireAsk()
+	}
+
+	// a new round resets the backoff without asking
+	c.setRound(1)
+	advance(2 * time.Minute)
+	require.Empty(t, asks)
+	advance(10 * time.Second)
+	requireAsk()
+	advance(20 * time.Second)
+	requireAsk()
 
-	c.Stop()
+	// so does a new height
+	c.setHeight(common.Big2)
+	advance(40 * time.Second)
+	require.Empty(t, asks)
+	advance(10 * time.Second)
+	requireAsk()
 }
@@ -25,8 +25,9 @@ type Backend interface {
 
 	AskSync(header *types.Header)
 
-	// Broadcast sends a message to all validators (include self)
-	Broadcast(committee types.Committee, message message.Msg)
+	// Broadcast sends a message to all validators (include self).
+	// The sends to the other validators which did not start yet are dropped once ctx is cancelled.
+	Broadcast(ctx context.Context, committee types.Committee, message message.Msg)
 
 	// Commit delivers an approved proposal to backend.
 	// The delivered proposal will be put into blockchain.
@@ -35,8 +36,9 @@ type Backend interface {
 	// GetContractABI returns the Autonity Contract ABI
 	GetContractABI() *abi.ABI
 
-	// Gossip sends a message to all validators (exclude self)
-	Gossip(committee types.Committee, message message.Msg)
+	// Gossip sends a message to all validators (exclude self).
+	// The sends which did not start yet are dropped once ctx is cancelled.
+	Gossip(ctx context.Context, committee types.Committee, message message.Msg)
 
 	KnownMsgHash() []common.Hash
 
@@ -45,6 +47,9 @@ type Backend interface {
 	// HeadBlock retrieves latest committed proposal and the address of proposer
 	HeadBlock() *types.Block
 
+	// ImportBacklog returns the number of blocks handed to the chain whose import did not complete yet.
+	ImportBacklog() int
+
 	Post(ev any)
 
 	// SetProposedBlockHash is a setter for the proposed block hash
@@ -55,7 +60,8 @@ type Backend interface {
 
 	Subscribe(types ...any) *event.TypeMuxSubscription
 
-	SyncPeer(address common.Address)
+	// SyncPeer sends the current height messages to the peer, except the ones listed in its sync request.
+	SyncPeer(address common.Address, request []byte)
 
 	// VerifyProposal verifies the proposal. If a consensus.ErrFutureBlock error is returned,
 	// the time difference of the proposal and current time is also returned.
@@ -64,9 +70,6 @@ type Backend interface {
 	// Returns the main blockchain object.
 	BlockChain() *ethcore.BlockChain
 
-	// SetBlockchain is used to set the blockchain on this object
-	SetBlockchain(bc *ethcore.BlockChain)
-
 	// Logger returns the object used for logging purposes.
 	Logger() log.Logger
 
@@ -84,18 +87,36 @@ type Backend interface {
 
 	// returns the channel used to pass messages between peer sessions and the aggregator
 	MessageCh() <-chan events.UnverifiedMessageEvent
+
+	// ReportMessageError accounts for a consensus message handling error and, depending on its class,
+	// hands it over to errCh to disconnect the sender. errCh may be nil for local or aggregated messages.
+	ReportMessageError(err error, errCh chan<- error)
 }
 
 type Core interface {
 	Start(ctx context.Context, contract *autonity.ProtocolContracts)
-	Stop()
+	Stop() error
 	CoreState() CoreState
+	Snapshot() StateSnapshot
+	// PublishedSnapshot returns the state snapshot published at the last step transition, false if none.
+	PublishedSnapshot() (StateSnapshot, bool)
+	SupportBundle() SupportBundle
+	PendingProposalState() PendingProposalState
+	RetriggerProposal() error
 	Broadcaster() Broadcaster
 	Proposer() Proposer
 	Prevoter() Prevoter
 	Precommiter() Precommiter
+	// SetImportPacing sets the import backlog, in blocks, above which the start of the next height is
+	// delayed for the import to catch up. Zero disables the pacing.
+	SetImportPacing(threshold uint64)
 	Height() *big.Int
 	Round() int64
+	// CurrentHeightMessages returns a consistent snapshot of the messages of the current height: the
+	// proposals and votes of every round, future rounds and the messages of the node included. The
+	// messages are all verified, the ones failing the checks or outside the admitted rounds never
+	// reach the core. They are ordered by round, code, then lowest committee index of their signers.
+	// It is the source of the messages sent to the peers asking for sync.
 	CurrentHeightMessages() []message.Msg
 
 	// Used by the aggregator
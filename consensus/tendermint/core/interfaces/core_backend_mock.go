@@ -106,15 +106,15 @@ func (mr *MockBackendMockRecorder) BlockChain() *gomock.Call {
 }
 
 // Broadcast mocks base method.
-func (m *MockBackend) Broadcast(committee types.Committee, message message.Msg) {
+func (m *MockBackend) Broadcast(ctx context.Context, committee types.Committee, message message.Msg) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Broadcast", committee, message)
+	m.ctrl.Call(m, "Broadcast", ctx, committee, message)
 }
 
 // Broadcast indicates an expected call of Broadcast.
-func (mr *MockBackendMockRecorder) Broadcast(committee, message any) *gomock.Call {
+func (mr *MockBackendMockRecorder) Broadcast(ctx, committee, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Broadcast", reflect.TypeOf((*MockBackend)(nil).Broadcast), committee, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Broadcast", reflect.TypeOf((*MockBackend)(nil).Broadcast), ctx, committee, message)
 }
 
 // Commit mocks base method.
@@ -160,15 +160,15 @@ func (mr *MockBackendMockRecorder) GetContractABI() *gomock.Call {
 }
 
 // Gossip mocks base method.
-func (m *MockBackend) Gossip(committee types.Committee, message message.Msg) {
+func (m *MockBackend) Gossip(ctx context.Context, committee types.Committee, message message.Msg) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Gossip", committee, message)
+	m.ctrl.Call(m, "Gossip", ctx, committee, message)
 }
 
 // Gossip indicates an expected call of Gossip.
-func (mr *MockBackendMockRecorder) Gossip(committee, message any) *gomock.Call {
+func (mr *MockBackendMockRecorder) Gossip(ctx, committee, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gossip", reflect.TypeOf((*MockBackend)(nil).Gossip), committee, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gossip", reflect.TypeOf((*MockBackend)(nil).Gossip), ctx, committee, message)
 }
 
 // Gossiper mocks base method.
@@ -211,6 +211,20 @@ func (mr *MockBackendMockRecorder) HeadBlock() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadBlock", reflect.TypeOf((*MockBackend)(nil).HeadBlock))
 }
 
+// ImportBacklog mocks base method.
+func (m *MockBackend) ImportBacklog() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportBacklog")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// ImportBacklog indicates an expected call of ImportBacklog.
+func (mr *MockBackendMockRecorder) ImportBacklog() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportBacklog", reflect.TypeOf((*MockBackend)(nil).ImportBacklog))
+}
+
 // IsJailed mocks base method.
 func (m *MockBackend) IsJailed(address common.Address) bool {
 	m.ctrl.T.Helper()
@@ -291,16 +305,16 @@ func (mr *MockBackendMockRecorder) ProcessFutureMsgs(height any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessFutureMsgs", reflect.TypeOf((*MockBackend)(nil).ProcessFutureMsgs), height)
 }
 
-// SetBlockchain mocks base method.
-func (m *MockBackend) SetBlockchain(bc *core.BlockChain) {
+// ReportMessageError mocks base method.
+func (m *MockBackend) ReportMessageError(err error, errCh chan<- error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SetBlockchain", bc)
+	m.ctrl.Call(m, "ReportMessageError", err, errCh)
 }
 
-// SetBlockchain indicates an expected call of SetBlockchain.
-func (mr *MockBackendMockRecorder) SetBlockchain(bc any) *gomock.Call {
+// ReportMessageError indicates an expected call of ReportMessageError.
+func (mr *MockBackendMockRecorder) ReportMessageError(err, errCh any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlockchain", reflect.TypeOf((*MockBackend)(nil).SetBlockchain), bc)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportMessageError", reflect.TypeOf((*MockBackend)(nil).ReportMessageError), err, errCh)
 }
 
 // SetProposedBlockHash mocks base method.
@@ -348,15 +362,15 @@ func (mr *MockBackendMockRecorder) Subscribe(types ...any) *gomock.Call {
 }
 
 // SyncPeer mocks base method.
-func (m *MockBackend) SyncPeer(address common.Address) {
+func (m *MockBackend) SyncPeer(address common.Address, request []byte) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SyncPeer", address)
+	m.ctrl.Call(m, "SyncPeer", address, request)
 }
 
 // SyncPeer indicates an expected call of SyncPeer.
-func (mr *MockBackendMockRecorder) SyncPeer(address any) *gomock.Call {
+func (mr *MockBackendMockRecorder) SyncPeer(address, request any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPeer", reflect.TypeOf((*MockBackend)(nil).SyncPeer), address)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPeer", reflect.TypeOf((*MockBackend)(nil).SyncPeer), address, request)
 }
 
 // VerifyProposal mocks base method.
@@ -453,6 +467,20 @@ func (mr *MockCoreMockRecorder) Height() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Height", reflect.TypeOf((*MockCore)(nil).Height))
 }
 
+// PendingProposalState mocks base method.
+func (m *MockCore) PendingProposalState() PendingProposalState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingProposalState")
+	ret0, _ := ret[0].(PendingProposalState)
+	return ret0
+}
+
+// PendingProposalState indicates an expected call of PendingProposalState.
+func (mr *MockCoreMockRecorder) PendingProposalState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingProposalState", reflect.TypeOf((*MockCore)(nil).PendingProposalState))
+}
+
 // Power mocks base method.
 func (m *MockCore) Power(h uint64, r int64) *message.AggregatedPower {
 	m.ctrl.T.Helper()
@@ -509,6 +537,35 @@ func (mr *MockCoreMockRecorder) Proposer() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Proposer", reflect.TypeOf((*MockCore)(nil).Proposer))
 }
 
+// PublishedSnapshot mocks base method.
+func (m *MockCore) PublishedSnapshot() (StateSnapshot, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishedSnapshot")
+	ret0, _ := ret[0].(StateSnapshot)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// PublishedSnapshot indicates an expected call of PublishedSnapshot.
+func (mr *MockCoreMockRecorder) PublishedSnapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishedSnapshot", reflect.TypeOf((*MockCore)(nil).PublishedSnapshot))
+}
+
+// RetriggerProposal mocks base method.
+func (m *MockCore) RetriggerProposal() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetriggerProposal")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetriggerProposal indicates an expected call of RetriggerProposal.
+func (mr *MockCoreMockRecorder) RetriggerProposal() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetriggerProposal", reflect.TypeOf((*MockCore)(nil).RetriggerProposal))
+}
+
 // Round mocks base method.
 func (m *MockCore) Round() int64 {
 	m.ctrl.T.Helper()
@@ -523,6 +580,32 @@ func (mr *MockCoreMockRecorder) Round() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Round", reflect.TypeOf((*MockCore)(nil).Round))
 }
 
+// SetImportPacing mocks base method.
+func (m *MockCore) SetImportPacing(threshold uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetImportPacing", threshold)
+}
+
+// SetImportPacing indicates an expected call of SetImportPacing.
+func (mr *MockCoreMockRecorder) SetImportPacing(threshold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImportPacing", reflect.TypeOf((*MockCore)(nil).SetImportPacing), threshold)
+}
+
+// Snapshot mocks base method.
+func (m *MockCore) Snapshot() StateSnapshot {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(StateSnapshot)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockCoreMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockCore)(nil).Snapshot))
+}
+
 // Start mocks base method.
 func (m *MockCore) Start(ctx context.Context, contract *autonity.ProtocolContracts) {
 	m.ctrl.T.Helper()
@@ -536,9 +619,11 @@ func (mr *MockCoreMockRecorder) Start(ctx, contract any) *gomock.Call {
 }
 
 // Stop mocks base method.
-func (m *MockCore) Stop() {
+func (m *MockCore) Stop() error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Stop")
+	ret := m.ctrl.Call(m, "Stop")
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Stop indicates an expected call of Stop.
@@ -547,6 +632,20 @@ func (mr *MockCoreMockRecorder) Stop() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockCore)(nil).Stop))
 }
 
+// SupportBundle mocks base method.
+func (m *MockCore) SupportBundle() SupportBundle {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SupportBundle")
+	ret0, _ := ret[0].(SupportBundle)
+	return ret0
+}
+
+// SupportBundle indicates an expected call of SupportBundle.
+func (mr *MockCoreMockRecorder) SupportBundle() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupportBundle", reflect.TypeOf((*MockCore)(nil).SupportBundle))
+}
+
 // VotesPower mocks base method.
 func (m *MockCore) VotesPower(h uint64, r int64, code uint8) *message.AggregatedPower {
 	m.ctrl.T.Helper()
@@ -1,6 +1,8 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/fixsizecache"
 	"github.com/autonity/autonity/consensus"
@@ -9,8 +11,10 @@ import (
 )
 
 type Gossiper interface {
-	Gossip(committee types.Committee, message message.Msg)
-	AskSync(header *types.Header)
+	Gossip(ctx context.Context, committee types.Committee, message message.Msg)
+	// AskSync asks a few committee members for the current height messages, except the known ones. The
+	// members asked rotate with each request.
+	AskSync(header *types.Header, known []common.Hash)
 	SetBroadcaster(broadcaster consensus.Broadcaster)
 	Broadcaster() consensus.Broadcaster
 	KnownMessages() *fixsizecache.Cache[common.Hash, bool]
@@ -9,6 +9,7 @@
 package interfaces
 
 import (
+	context "context"
 	reflect "reflect"
 
 	common "github.com/autonity/autonity/common"
@@ -57,15 +58,15 @@ func (mr *MockGossiperMockRecorder) Address() *gomock.Call {
 }
 
 // AskSync mocks base method.
-func (m *MockGossiper) AskSync(header *types.Header) {
+func (m *MockGossiper) AskSync(header *types.Header, known []common.Hash) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AskSync", header)
+	m.ctrl.Call(m, "AskSync", header, known)
 }
 
 // AskSync indicates an expected call of AskSync.
-func (mr *MockGossiperMockRecorder) AskSync(header any) *gomock.Call {
+func (mr *MockGossiperMockRecorder) AskSync(header, known any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AskSync", reflect.TypeOf((*MockGossiper)(nil).AskSync), header)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AskSync", reflect.TypeOf((*MockGossiper)(nil).AskSync), header, known)
 }
 
 // Broadcaster mocks base method.
@@ -83,15 +84,15 @@ func (mr *MockGossiperMockRecorder) Broadcaster() *gomock.Call {
 }
 
 // Gossip mocks base method.
-func (m *MockGossiper) Gossip(committee types.Committee, message message.Msg) {
+func (m *MockGossiper) Gossip(ctx context.Context, committee types.Committee, message message.Msg) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Gossip", committee, message)
+	m.ctrl.Call(m, "Gossip", ctx, committee, message)
 }
 
 // Gossip indicates an expected call of Gossip.
-func (mr *MockGossiperMockRecorder) Gossip(committee, message any) *gomock.Call {
+func (mr *MockGossiperMockRecorder) Gossip(ctx, committee, message any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gossip", reflect.TypeOf((*MockGossiper)(nil).Gossip), committee, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gossip", reflect.TypeOf((*MockGossiper)(nil).Gossip), ctx, committee, message)
 }
 
 // KnownMessages mocks base method.
@@ -2,9 +2,11 @@ package interfaces
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
 	"github.com/autonity/autonity/core/types"
 )
 
@@ -32,11 +34,93 @@ type MsgForDump struct {
 	Round  int64
 }
 
+// StateSnapshot is a consistent view of the consensus state. It carries hashes only, never blocks or
+// messages, so that it is cheap to produce and safe to serialize to JSON.
+type StateSnapshot struct {
+	Height      uint64
+	Round       int64
+	Step        uint64
+	LockedRound int64
+	LockedValue *common.Hash
+	ValidRound  int64
+	ValidValue  *common.Hash
+
+	Proposer        common.Address
+	IsProposer      bool
+	QuorumVotePower *big.Int
+	// vote power tallies of the rounds of the current height
+	RoundStates []RoundState
+
+	// prevotes sent by the node in the rounds of the current height, with their reason
+	Prevotes []PrevoteRecord
+
+	// number of messages of the current height, and of the future rounds
+	CurHeightMessages   int
+	FutureRoundMessages int
+
+	// time of the last transitions
+	HeightStart time.Time
+	RoundStart  time.Time
+	StepStart   time.Time
+}
+
+// PrevoteReason is the reason category of a prevote sent by the node: either it prevoted the proposal, or
+// why it prevoted nil.
+type PrevoteReason uint8
+
+const (
+	PrevoteValue      PrevoteReason = iota // the node prevoted the proposal
+	PrevoteNoProposal                      // no proposal was received before the propose timeout
+	PrevoteTimeout                         // a proposal was received, but could not be prevoted before the propose timeout
+	PrevoteInvalid                         // the proposal failed its verification
+	PrevoteLocked                          // the node is locked on another value
+	NumPrevoteReasons
+)
+
+var prevoteReasonNames = [NumPrevoteReasons]string{
+	PrevoteValue:      "value",
+	PrevoteNoProposal: "noproposal",
+	PrevoteTimeout:    "timeout",
+	PrevoteInvalid:    "invalid",
+	PrevoteLocked:     "locked",
+}
+
+func (r PrevoteReason) String() string {
+	if r >= NumPrevoteReasons {
+		return "unknown"
+	}
+	return prevoteReasonNames[r]
+}
+
+// MarshalText encodes the reason as its name.
+func (r PrevoteReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// PrevoteRecord is a prevote sent by the node in a round, with its reason. ErrorClass is the class of the
+// verification error of an invalid proposal, empty otherwise.
+type PrevoteRecord struct {
+	Round      int64
+	Reason     PrevoteReason
+	ErrorClass string `json:",omitempty"`
+}
+
+// SupportBundle gathers the consensus diagnostics to attach to a support request.
+type SupportBundle struct {
+	Snapshot        StateSnapshot
+	PendingProposal PendingProposalState
+	// most recent consensus message traces, if tracing is enabled.
+	Traces []*msgtrace.Trace `json:",omitempty"`
+}
+
 // TendermintState save an instant status for the tendermint consensus engine.
 type CoreState struct {
 	// validator address
 	Client common.Address
 
+	// Snapshot is the consensus state the fields below are derived from.
+	Snapshot StateSnapshot
+
 	// Core state of tendermint
 	Height      *big.Int
 	Round       int64
@@ -77,3 +161,20 @@ type CoreState struct {
 	// Known msg of gossip.
 	KnownMsgHash []common.Hash
 }
+
+// PendingProposalState reports the candidate block pipeline feeding the proposals of the local node.
+type PendingProposalState struct {
+	Height       *big.Int
+	Round        int64
+	Step         uint64
+	IsProposer   bool
+	SentProposal bool
+
+	// candidate block received from the miner for the current height, if any.
+	HasCandidate    bool
+	CandidateNumber *big.Int     `json:",omitempty"`
+	CandidateParent *common.Hash `json:",omitempty"`
+	CandidateTxs    int
+	// CandidateAge is the time in seconds since the candidate block was received from the miner.
+	CandidateAge float64
+}
@@ -1,9 +1,20 @@
 package interfaces
 
+import (
+	"time"
+
+	"github.com/autonity/autonity/common/randutil"
+)
+
 type Services struct {
 	Broadcaster func(c Core) Broadcaster
 	Prevoter    func(c Core) Prevoter
 	Proposer    func(c Core) Proposer
 	Precommiter func(c Core) Precommiter
 	Gossiper    func(b Backend) Gossiper
+	// Rand is the source of randomness of the node, a crypto-seeded one is used if nil.
+	Rand randutil.Source
+	// ClockSkew offsets the time the consensus compares the block timestamps to, to simulate a skewed
+	// system time.
+	ClockSkew time.Duration
 }
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrStopTimeout is returned by Stop when some event loops did not exit in time.
+var ErrStopTimeout = errors.New("timed out waiting for the core event loops to exit")
+
+// stopTimeout is how long Stop waits for the event loops to exit.
+var stopTimeout = 10 * time.Second
+
+// eventLoops tracks the event loops of the core, so that Stop can wait for them to exit. The zero
+// value is ready to use.
+type eventLoops struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// run starts the loop in its own goroutine.
+func (l *eventLoops) run(name string, loop func()) {
+	l.mu.Lock()
+	if l.running == nil {
+		l.running = make(map[string]int)
+	}
+	l.running[name]++
+	l.mu.Unlock()
+
+	l.wg.Add(1)
+	go func() {
+		defer func() {
+			l.mu.Lock()
+			if l.running[name]--; l.running[name] == 0 {
+				delete(l.running, name)
+			}
+			l.mu.Unlock()
+			l.wg.Done()
+		}()
+		loop()
+	}()
+}
+
+// wait waits for the loops to exit. It returns the names of the loops still running once the
+// timeout expires, if any.
+func (l *eventLoops) wait(timeout time.Duration) []string {
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.running))
+	for name := range l.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -31,6 +31,7 @@ func (b *base) PreVerified() bool {
 	return b.preverified
 }
 
+// H returns the height of the message, checked to be non-zero at decoding.
 func (b *base) H() uint64 {
 	return b.height
 }
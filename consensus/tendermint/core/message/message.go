@@ -17,8 +17,8 @@
 package message
 
 import (
-	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"sort"
 
@@ -32,9 +32,12 @@ import (
 )
 
 var (
-	ErrBadSignature            = errors.New("bad signature")
-	ErrUnauthorizedAddress     = errors.New("unauthorized address")
-	ErrInvalidComplexAggregate = errors.New("complex aggregate does not carry quorum")
+	ErrBadSignature            = constants.NewError(constants.ClassBadSignature, "bad signature")
+	ErrNonCommitteeSender      = constants.NewError(constants.ClassNonCommittee, "sender is not a committee member")
+	ErrInvalidComplexAggregate = constants.NewError(constants.ClassInvalid, "complex aggregate does not carry quorum")
+
+	ErrQuorumCertificateSignature = fmt.Errorf("%w: aggregate signature mismatch", types.ErrInvalidQuorumCertificate)
+	ErrQuorumCertificatePower     = fmt.Errorf("%w: signers voting power below quorum", types.ErrInvalidQuorumCertificate)
 )
 
 const (
@@ -170,9 +173,11 @@ func (p *Propose) DecodeRLP(s *rlp.Stream) error {
 	if ext.Signature == nil {
 		return constants.ErrInvalidMessage
 	}
-	if ext.Round > constants.MaxRound || ext.ValidRound > constants.MaxRound {
+	// the rounds bounds are checked on admission, the decoding only makes sure they fit
+	if ext.Round > math.MaxInt64 || ext.ValidRound > math.MaxInt64 {
 		return constants.ErrInvalidMessage
 	}
+	// the genesis block is not agreed on, no message has height 0
 	if ext.Height == 0 {
 		return constants.ErrInvalidMessage
 	}
@@ -185,9 +190,6 @@ func (p *Propose) DecodeRLP(s *rlp.Stream) error {
 		}
 		p.validRound = -1
 	} else {
-		if ext.ValidRound >= ext.Round {
-			return constants.ErrInvalidMessage
-		}
 		p.validRound = int64(ext.ValidRound)
 	}
 
@@ -224,7 +226,7 @@ func (p *Propose) PreValidate(header *types.Header) error {
 
 	validator := header.CommitteeMember(p.signer)
 	if validator == nil {
-		return ErrUnauthorizedAddress
+		return ErrNonCommitteeSender
 	}
 
 	p.signerKey = validator.ConsensusKey
@@ -330,15 +332,14 @@ func (p *LightProposal) DecodeRLP(s *rlp.Stream) error {
 	if ext.Code != LightProposalCode {
 		return constants.ErrInvalidMessage
 	}
-	if ext.ProposalBlock == (common.Hash{}) {
-		return constants.ErrInvalidMessage
-	}
 	if ext.Signature == nil {
 		return constants.ErrInvalidMessage
 	}
-	if ext.Round > constants.MaxRound || ext.ValidRound > constants.MaxRound {
+	// the rounds bounds are checked on admission, the decoding only makes sure they fit
+	if ext.Round > math.MaxInt64 || ext.ValidRound > math.MaxInt64 {
 		return constants.ErrInvalidMessage
 	}
+	// the genesis block is not agreed on, no message has height 0
 	if ext.Height == 0 {
 		return constants.ErrInvalidMessage
 	}
@@ -348,9 +349,6 @@ func (p *LightProposal) DecodeRLP(s *rlp.Stream) error {
 		}
 		p.validRound = -1
 	} else {
-		if ext.ValidRound >= ext.Round {
-			return constants.ErrInvalidMessage
-		}
 		p.validRound = int64(ext.ValidRound)
 	}
 	p.round = int64(ext.Round)
@@ -383,7 +381,7 @@ func (p *LightProposal) PreValidate(header *types.Header) error {
 
 	validator := header.CommitteeMember(p.signer)
 	if validator == nil {
-		return ErrUnauthorizedAddress
+		return ErrNonCommitteeSender
 	}
 
 	p.signerKey = validator.ConsensusKey
@@ -426,7 +424,7 @@ func (v *vote) PreValidate(header *types.Header) error {
 	}
 
 	if err := v.signers.Validate(len(header.Committee)); err != nil {
-		return fmt.Errorf("Invalid signers information: %w", err)
+		return constants.WrapError(constants.ClassInvalid, fmt.Errorf("Invalid signers information: %w", err))
 	}
 
 	// compute aggregated key and auxiliary data structures
@@ -779,10 +777,11 @@ func (p *Prevote) DecodeRLP(s *rlp.Stream) error {
 	if encoded.Signature == nil {
 		return constants.ErrInvalidMessage
 	}
-	if encoded.Height == 0 {
+	// the round bounds are checked on admission, the decoding only makes sure it fits
+	if encoded.Round > math.MaxInt64 {
 		return constants.ErrInvalidMessage
 	}
-	if encoded.Round > constants.MaxRound {
+	if encoded.Height == 0 {
 		return constants.ErrInvalidMessage
 	}
 	if encoded.Signers == nil || encoded.Signers.Bits == nil || len(encoded.Signers.Bits) == 0 || encoded.Signers.Coefficients == nil {
@@ -818,10 +817,11 @@ func (p *Precommit) DecodeRLP(s *rlp.Stream) error {
 	if encoded.Signature == nil {
 		return constants.ErrInvalidMessage
 	}
-	if encoded.Height == 0 {
+	// the round bounds are checked on admission, the decoding only makes sure it fits
+	if encoded.Round > math.MaxInt64 {
 		return constants.ErrInvalidMessage
 	}
-	if encoded.Round > constants.MaxRound {
+	if encoded.Height == 0 {
 		return constants.ErrInvalidMessage
 	}
 	if encoded.Signers == nil || encoded.Signers.Bits == nil || len(encoded.Signers.Bits) == 0 {
@@ -849,6 +849,126 @@ func PrepareCommittedSeal(hash common.Hash, round int64, height *big.Int) common
 	return crypto.Hash(buf)
 }
 
+// VerifyQuorumCertificate verifies that the quorum certificate aggregates the precommits for the given block
+// of committee members holding a quorum of the committee voting power. It returns the voting power of the
+// distinct signers and the quorum of the committee, the signed power being nil if the signers information
+// cannot be decoded against the committee.
+func VerifyQuorumCertificate(certificate types.AggregateSignature, hash common.Hash, round int64, height *big.Int, committee types.Committee) (*big.Int, *big.Int, error) {
+	qc := prepareQuorumCertificate(CertifiedBlock{Certificate: certificate, Hash: hash, Round: round, Height: height, Committee: committee})
+	if qc.err != nil {
+		return qc.power, qc.quorum, qc.err
+	}
+	if !certificate.Signature.Verify(qc.key, qc.seal[:]) {
+		return qc.power, qc.quorum, ErrQuorumCertificateSignature
+	}
+	return qc.power, qc.quorum, qc.checkPower()
+}
+
+// CertifiedBlock is a block hash along with its quorum certificate and the committee it is verified against.
+type CertifiedBlock struct {
+	Certificate types.AggregateSignature
+	Hash        common.Hash
+	Round       int64
+	Height      *big.Int
+	Committee   types.Committee
+}
+
+// VerifyQuorumCertificates verifies the quorum certificates of a batch of blocks as VerifyQuorumCertificate
+// does, and returns the error of each of them. The aggregate signatures are verified at once, in a single
+// multi-pairing, and one by one only if the batch holds an invalid one, to find it.
+func VerifyQuorumCertificates(blocks []CertifiedBlock) []error {
+	errs := make([]error, len(blocks))
+	qcs := make([]*preparedQuorumCertificate, 0, len(blocks))
+	for i, block := range blocks {
+		qc := prepareQuorumCertificate(block)
+		if qc.err != nil {
+			errs[i] = qc.err
+			continue
+		}
+		qc.index = i
+		qcs = append(qcs, qc)
+	}
+	if len(qcs) == 0 {
+		return errs
+	}
+
+	signatures := make([][]byte, len(qcs))
+	seals := make([][32]byte, len(qcs))
+	keys := make([]blst.PublicKey, len(qcs))
+	for i, qc := range qcs {
+		signatures[i] = qc.signature.Marshal()
+		seals[i] = qc.seal
+		keys[i] = qc.key
+	}
+	valid, err := blst.VerifyMultipleSignatures(signatures, seals, keys)
+	for _, qc := range qcs {
+		if (err != nil || !valid) && !qc.signature.Verify(qc.key, qc.seal[:]) {
+			errs[qc.index] = ErrQuorumCertificateSignature
+			continue
+		}
+		errs[qc.index] = qc.checkPower()
+	}
+	return errs
+}
+
+// preparedQuorumCertificate is a quorum certificate whose signers information is decoded, ready for its
+// signature to be verified.
+type preparedQuorumCertificate struct {
+	index     int
+	signature *blst.BlsSignature
+	key       blst.PublicKey // aggregated key of the signers
+	seal      common.Hash
+	power     *big.Int // voting power of the distinct signers
+	quorum    *big.Int
+	err       error
+}
+
+func prepareQuorumCertificate(block CertifiedBlock) *preparedQuorumCertificate {
+	committeeVotingPower := new(big.Int)
+	for _, member := range block.Committee {
+		committeeVotingPower.Add(committeeVotingPower, member.VotingPower)
+	}
+	qc := &preparedQuorumCertificate{quorum: bft.Quorum(committeeVotingPower)}
+
+	// un-finalized proposals will have these fields set to nil
+	certificate := block.Certificate
+	if certificate.Signature == nil || certificate.Signers == nil {
+		qc.err = types.ErrEmptyQuorumCertificate
+		return qc
+	}
+	signers := certificate.Signers.Copy() // copy so that we do not modify the certificate when doing Signers.Validate()
+	if err := signers.Validate(len(block.Committee)); err != nil {
+		qc.err = fmt.Errorf("Invalid quorum certificate signers information: %w", err)
+		return qc
+	}
+
+	qc.power = new(big.Int)
+	for _, index := range signers.FlattenUniq() {
+		qc.power.Add(qc.power, block.Committee[index].VotingPower)
+	}
+
+	keys := make([][]byte, 0, len(block.Committee))
+	for _, index := range signers.Flatten() {
+		keys = append(keys, block.Committee[index].ConsensusKeyBytes)
+	}
+	aggregatedKey, err := blst.AggregatePublicKeys(keys)
+	if err != nil {
+		qc.err = fmt.Errorf("cannot aggregate the committee keys: %w", err)
+		return qc
+	}
+	qc.signature = certificate.Signature
+	qc.key = aggregatedKey
+	qc.seal = PrepareCommittedSeal(block.Hash, block.Round, block.Height)
+	return qc
+}
+
+func (qc *preparedQuorumCertificate) checkPower() error {
+	if qc.power.Cmp(qc.quorum) < 0 {
+		return ErrQuorumCertificatePower
+	}
+	return nil
+}
+
 // computes the power of a set of messages. Every sender's power is counted only once
 func Power(messages []Msg) *big.Int {
 	power := NewAggregatedPower()
@@ -129,16 +129,15 @@ func TestMessageDecode(t *testing.T) {
 		require.Equal(t, proposal.Signer(), decoded.Signer())
 		require.Equal(t, proposal.Signature(), decoded.Signature())
 	})
-	t.Run("invalid propose with vr > r", func(t *testing.T) {
+	t.Run("propose with vr > r", func(t *testing.T) {
+		// the valid round is checked on admission, the decoding only checks the wire format
 		header := &types.Header{Number: common.Big2}
 		block := types.NewBlockWithHeader(header)
 		proposal := NewPropose(1, 2, 57, block, defaultSigner, testCommitteeMember)
 		decoded := &Propose{}
 		reader := bytes.NewReader(proposal.Payload())
-		err := rlp.Decode(reader, decoded)
-		if !errors.Is(err, constants.ErrInvalidMessage) {
-			t.Error("Decoding should have failed")
-		}
+		require.NoError(t, rlp.Decode(reader, decoded))
+		require.Equal(t, int64(57), decoded.ValidRound())
 	})
 	t.Run("invalid propose with proposal height != block number", func(t *testing.T) {
 		header := &types.Header{Number: common.Big2}
@@ -151,6 +150,21 @@ func TestMessageDecode(t *testing.T) {
 			t.Error("Decoding should have failed")
 		}
 	})
+	t.Run("invalid messages with height 0", func(t *testing.T) {
+		// rejected at decoding, rather than filed under height 0 by the msg store
+		block := types.NewBlockWithHeader(&types.Header{Number: common.Big0})
+		messages := []Msg{
+			NewPropose(1, 0, -1, block, defaultSigner, testCommitteeMember),
+			NewLightProposal(NewPropose(1, 0, -1, block, defaultSigner, testCommitteeMember)),
+			newVote[Prevote](1, 0, common.HexToHash("0x1227"), defaultSigner, testCommitteeMember, 1),
+			newVote[Precommit](1, 0, common.HexToHash("0x1227"), defaultSigner, testCommitteeMember, 1),
+		}
+		for _, m := range messages {
+			decoded := reflect.New(reflect.TypeOf(m).Elem()).Interface().(Msg)
+			err := rlp.Decode(bytes.NewReader(m.Payload()), decoded)
+			require.ErrorIs(t, err, constants.ErrInvalidMessage, "code %d", m.Code())
+		}
+	})
 }
 
 func TestValidate(t *testing.T) {
@@ -245,7 +259,7 @@ func TestPreValidate(t *testing.T) {
 
 		for _, message := range messages {
 			err := message.PreValidate(header)
-			require.ErrorIs(t, err, ErrUnauthorizedAddress)
+			require.ErrorIs(t, err, ErrNonCommitteeSender)
 		}
 	})
 	t.Run("proposals from a committee member, no error", func(t *testing.T) {
@@ -768,3 +782,121 @@ func BenchmarkDecodeVote(b *testing.B) {
 		b.StartTimer()
 	}
 }
+
+// certifiedBlocks creates count blocks of the given height, certified by all the members of a committee of
+// the given size, along with the precommits aggregated in their quorum certificates.
+func certifiedBlocks(t testing.TB, size int, count int) ([]CertifiedBlock, [][]blst.Signature) {
+	keys := make([]blst.SecretKey, size)
+	committee := make(types.Committee, size)
+	for i := range committee {
+		key, err := blst.RandKey()
+		require.NoError(t, err)
+		keys[i] = key
+		committee[i] = *makeCommitteeMemberWithKey(key, uint64(i))
+	}
+	blocks := make([]CertifiedBlock, count)
+	precommits := make([][]blst.Signature, count)
+	for i := range blocks {
+		block := CertifiedBlock{Hash: common.Hash{byte(i), 0xaa}, Round: 1, Height: big.NewInt(100), Committee: committee}
+		seal := PrepareCommittedSeal(block.Hash, block.Round, block.Height)
+		signers := types.NewSigners(size)
+		for j, key := range keys {
+			precommits[i] = append(precommits[i], key.Sign(seal[:]))
+			signers.Increment(&committee[j])
+		}
+		block.Certificate = types.AggregateSignature{
+			Signature: blst.AggregateSignatures(precommits[i]).(*blst.BlsSignature),
+			Signers:   signers,
+		}
+		blocks[i] = block
+	}
+	return blocks, precommits
+}
+
+func TestVerifyQuorumCertificates(t *testing.T) {
+	t.Run("valid certificates", func(t *testing.T) {
+		blocks, _ := certifiedBlocks(t, 7, 4)
+		for _, err := range VerifyQuorumCertificates(blocks) {
+			require.NoError(t, err)
+		}
+		for _, block := range blocks {
+			_, _, err := VerifyQuorumCertificate(block.Certificate, block.Hash, block.Round, block.Height, block.Committee)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("invalid signature is found", func(t *testing.T) {
+		blocks, _ := certifiedBlocks(t, 7, 4)
+		// the certificate of another block is a valid signature, of a different seal
+		blocks[2].Certificate.Signature = blocks[1].Certificate.Signature
+		errs := VerifyQuorumCertificates(blocks)
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		require.ErrorIs(t, errs[2], ErrQuorumCertificateSignature)
+		require.NoError(t, errs[3])
+	})
+
+	t.Run("signers below quorum", func(t *testing.T) {
+		blocks, precommits := certifiedBlocks(t, 7, 2)
+		signers := types.NewSigners(7)
+		signers.Increment(&blocks[1].Committee[0])
+		blocks[1].Certificate = types.AggregateSignature{Signature: precommits[1][0].(*blst.BlsSignature), Signers: signers}
+		errs := VerifyQuorumCertificates(blocks)
+		require.NoError(t, errs[0])
+		require.ErrorIs(t, errs[1], ErrQuorumCertificatePower)
+	})
+
+	t.Run("malformed signers", func(t *testing.T) {
+		blocks, _ := certifiedBlocks(t, 7, 4)
+		blocks[0].Certificate.Signers = types.NewSigners(7)
+		blocks[1].Certificate.Signers = blocks[1].Certificate.Signers.Copy()
+		blocks[1].Certificate.Signers.Bits[1] |= 0x03 // the signer of index 7, beyond the committee
+		blocks[2].Certificate.Signers = types.NewSigners(9)
+		blocks[2].Certificate.Signers.Increment(&types.CommitteeMember{Index: 8, VotingPower: common.Big1})
+		blocks[3].Certificate.Signature = nil
+		errs := VerifyQuorumCertificates(blocks)
+		require.ErrorIs(t, errs[0], types.ErrEmptySigners)
+		require.ErrorIs(t, errs[1], types.ErrOutOfCommittee)
+		require.ErrorIs(t, errs[2], types.ErrWrongSizeSigners)
+		require.ErrorIs(t, errs[3], types.ErrEmptyQuorumCertificate)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		require.Empty(t, VerifyQuorumCertificates(nil))
+	})
+}
+
+// BenchmarkVerifyQuorumCertificate compares the verification of a block of a 100 members committee by each of
+// its precommits, by its quorum certificate, and by the quorum certificates of a batch of blocks.
+func BenchmarkVerifyQuorumCertificate(b *testing.B) {
+	const size, batch = 100, 32
+	blocks, precommits := certifiedBlocks(b, size, batch)
+	block := blocks[0]
+	seal := PrepareCommittedSeal(block.Hash, block.Round, block.Height)
+
+	b.Run("precommits", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j, precommit := range precommits[0] {
+				if !precommit.Verify(block.Committee[j].ConsensusKey, seal[:]) {
+					b.Fatal("invalid precommit")
+				}
+			}
+		}
+	})
+	b.Run("certificate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := VerifyQuorumCertificate(block.Certificate, block.Hash, block.Round, block.Height, block.Committee); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i += batch {
+			for _, err := range VerifyQuorumCertificates(blocks) {
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
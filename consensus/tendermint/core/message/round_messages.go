@@ -139,6 +139,11 @@ func (s *RoundMessages) PrecommitsTotalAggregatedPower() *AggregatedPower {
 	return s.precommits.TotalPower()
 }
 
+// PrecommitValues returns the values for which precommits were received in the round.
+func (s *RoundMessages) PrecommitValues() []common.Hash {
+	return s.precommits.Values()
+}
+
 func (s *RoundMessages) AddPrevote(prevote *Prevote) {
 	s.Lock()
 	defer s.Unlock()
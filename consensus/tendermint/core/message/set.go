@@ -103,6 +103,18 @@ func (s *Set) TotalPower() *AggregatedPower {
 	return s.totalPower.Copy() // return copy to avoid data race
 }
 
+// Values returns the values for which at least one vote was received.
+func (s *Set) Values() []common.Hash {
+	s.RLock()
+	defer s.RUnlock()
+
+	values := make([]common.Hash, 0, len(s.votes))
+	for value := range s.votes {
+		values = append(values, value)
+	}
+	return values
+}
+
 func (s *Set) VotesFor(blockHash common.Hash) []Vote {
 	s.RLock()
 	defer s.RUnlock()
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"github.com/autonity/autonity/consensus/tendermint/events"
+)
+
+// DefaultMessageQueueSize is the default maximum number of consensus messages queued by core.
+const DefaultMessageQueueSize = 4096
+
+// MessageQueue orders the consensus messages received by core by height. The messages of the current
+// height can complete it, so they are handled before the messages buffered for the future heights,
+// which can pile up by the thousands while catching up. The future height messages are released
+// once core reaches their height.
+//
+// The queue is bounded: when full, the oldest future height message is dropped to make room. The
+// current height messages are only dropped if no future height message is left, the oldest first.
+// It is accessed by the core main loop only, its size is set before the engine starts.
+type MessageQueue struct {
+	height  uint64
+	current []events.MessageEvent // messages of height, or older once the height advanced
+	future  []events.MessageEvent // messages above height, oldest first
+	limit   int
+}
+
+func newMessageQueue() *MessageQueue {
+	return &MessageQueue{limit: DefaultMessageQueueSize}
+}
+
+// SetLimit sets the maximum number of queued messages. Zero restores the default.
+func (q *MessageQueue) SetLimit(n int) {
+	if n <= 0 {
+		n = DefaultMessageQueueSize
+	}
+	q.limit = n
+}
+
+// Len returns the number of queued messages.
+func (q *MessageQueue) Len() int {
+	return len(q.current) + len(q.future)
+}
+
+// push queues the message event ev received at height. The old height messages are not queued.
+func (q *MessageQueue) push(ev events.MessageEvent, height uint64) {
+	q.advance(height)
+	if ev.Message.H() < height {
+		return
+	}
+	if q.Len() >= q.limit {
+		switch {
+		case len(q.future) > 0:
+			q.future = q.future[1:]
+		case ev.Message.H() > height:
+			MessageQueueDropMeter.Mark(1)
+			return
+		default:
+			q.current = q.current[1:]
+		}
+		MessageQueueDropMeter.Mark(1)
+	}
+	if ev.Message.H() == height {
+		q.current = append(q.current, ev)
+	} else {
+		q.future = append(q.future, ev)
+	}
+}
+
+// pending reports whether a message of the current height, or of an older one, is waiting.
+func (q *MessageQueue) pending(height uint64) bool {
+	q.advance(height)
+	return len(q.current) > 0
+}
+
+// pop returns the next message event to handle at height, the future height messages are kept.
+func (q *MessageQueue) pop(height uint64) (events.MessageEvent, bool) {
+	q.advance(height)
+	if len(q.current) == 0 {
+		return events.MessageEvent{}, false
+	}
+	ev := q.current[0]
+	q.current[0] = events.MessageEvent{}
+	q.current = q.current[1:]
+	if len(q.current) == 0 {
+		q.current = nil // release the backing array once drained
+	}
+	return ev, true
+}
+
+// advance releases the future messages reached by height, in their arrival order.
+func (q *MessageQueue) advance(height uint64) {
+	if height <= q.height {
+		return
+	}
+	q.height = height
+	kept := q.future[:0]
+	for _, ev := range q.future {
+		if ev.Message.H() <= height {
+			q.current = append(q.current, ev)
+		} else {
+			kept = append(kept, ev)
+		}
+	}
+	for i := len(kept); i < len(q.future); i++ {
+		q.future[i] = events.MessageEvent{}
+	}
+	q.future = kept
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/log"
+)
+
+func queuedEvent(height uint64, round int64) events.MessageEvent {
+	return events.MessageEvent{Message: message.NewFakePrevote(message.Fake{FakeHeight: height, FakeRound: uint64(round)})}
+}
+
+func popAll(q *MessageQueue, height uint64) (popped []uint64) {
+	for {
+		ev, ok := q.pop(height)
+		if !ok {
+			return popped
+		}
+		popped = append(popped, ev.Message.H()*100+uint64(ev.Message.R()))
+	}
+}
+
+func TestMessageQueue(t *testing.T) {
+	t.Run("current height messages before future height ones", func(t *testing.T) {
+		q := newMessageQueue()
+		q.push(queuedEvent(11, 0), 10)
+		q.push(queuedEvent(12, 0), 10)
+		q.push(queuedEvent(10, 0), 10)
+		q.push(queuedEvent(11, 1), 10)
+		q.push(queuedEvent(10, 1), 10)
+		q.push(queuedEvent(9, 0), 10)
+
+		require.True(t, q.pending(10))
+		require.Equal(t, []uint64{1000, 1001}, popAll(q, 10))
+		require.False(t, q.pending(10))
+		require.Equal(t, 3, q.Len())
+
+		// the future messages are released in their arrival order once their height is reached
+		require.Equal(t, []uint64{1100, 1101}, popAll(q, 11))
+		require.Equal(t, []uint64{1200}, popAll(q, 12))
+		require.Zero(t, q.Len())
+	})
+
+	t.Run("queued messages overtaken by the height are still popped", func(t *testing.T) {
+		q := newMessageQueue()
+		q.push(queuedEvent(10, 0), 10)
+		q.push(queuedEvent(12, 0), 10)
+		require.Equal(t, []uint64{1000, 1200}, popAll(q, 13))
+	})
+
+	t.Run("oldest future height messages dropped first", func(t *testing.T) {
+		q := newMessageQueue()
+		q.SetLimit(3)
+		q.push(queuedEvent(11, 0), 10)
+		q.push(queuedEvent(10, 0), 10)
+		q.push(queuedEvent(11, 1), 10)
+		q.push(queuedEvent(10, 1), 10)
+		require.Equal(t, 3, q.Len())
+		require.Equal(t, []uint64{1000, 1001, 1101}, popAll(q, 11))
+
+		// without future height messages left, the incoming future ones are dropped
+		q.push(queuedEvent(11, 0), 11)
+		q.push(queuedEvent(11, 1), 11)
+		q.push(queuedEvent(11, 2), 11)
+		q.push(queuedEvent(12, 0), 11)
+		require.Equal(t, 3, q.Len())
+
+		// and the oldest current height ones make room for the current height ones
+		q.push(queuedEvent(11, 3), 11)
+		require.Equal(t, []uint64{1101, 1102, 1103}, popAll(q, 11))
+	})
+
+	t.Run("zero limit restores the default", func(t *testing.T) {
+		q := newMessageQueue()
+		q.SetLimit(0)
+		require.Equal(t, DefaultMessageQueueSize, q.limit)
+	})
+}
+
+// The messages buffered for the next height while catching up must not delay the quorum of the current
+// height: they are queued without being applied, and the current height votes overtake them.
+func TestMessageQueueBacklog(t *testing.T) {
+	const backlog = 1000
+
+	e := NewConsensusEnv(t, func(e *ConsensusENV) {
+		e.step = Precommit
+	})
+	proposal := generateBlockProposal(e.curRound, e.curHeight, e.curRound, false, signer(e, e.curRound), member(e, e.curRound))
+	future := message.NewPrecommit(0, e.curHeight.Uint64()+1, common.Hash{0x1}, signer(e, 1), member(e, 1), e.committeeSize)
+	setCommitteeAndSealOnBlock(t, proposal.Block(), e.committee, e.keys, 1)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	backendMock := interfaces.NewMockBackend(ctrl)
+	e.setupCore(backendMock, e.clientAddress)
+	e.core.noGossip = true
+	e.core.curRoundMessages.SetProposal(proposal, true)
+	// a quorum of precommits, aggregated
+	precommit := message.NewFakePrecommit(message.Fake{
+		FakeCode:      message.PrecommitCode,
+		FakeRound:     uint64(e.curRound),
+		FakeHeight:    e.curHeight.Uint64(),
+		FakeValue:     proposal.Block().Hash(),
+		FakeSigners:   signersWithPower(1, e.committeeSize, e.core.CommitteeSet().Quorum()),
+		FakeSignerKey: testConsensusKey.PublicKey(),
+		FakeSignature: defaultSigner(proposal.Block().Hash()),
+	})
+
+	committed := make(chan struct{})
+	backendMock.EXPECT().Commit(proposal.Block(), e.curRound, gomock.Any()).Do(
+		func(*types.Block, int64, types.AggregateSignature) { close(committed) })
+	backendMock.EXPECT().AskSync(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().Post(gomock.Any()).AnyTimes()
+
+	mux := event.NewTypeMuxSilent(nil, log.Root())
+	e.core.messageSub = mux.Subscribe(events.MessageEvent{})
+	e.core.syncEventSub = mux.Subscribe(events.SyncEvent{})
+	e.core.candidateBlockCh = make(chan events.NewCandidateBlockEvent, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	e.core.cancel = cancel
+	e.core.feeds.subscribe(ctx)
+	e.core.loops.run("main", func() { e.core.mainEventLoop(ctx) })
+
+	for i := 0; i < backlog; i++ {
+		mux.Post(events.MessageEvent{Message: future})
+	}
+	start := time.Now()
+	mux.Post(events.MessageEvent{Message: precommit})
+	select {
+	case <-committed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("quorum of precommits not committed")
+	}
+	latency := time.Since(start)
+
+	cancel()
+	e.core.unsubscribeEvents()
+	require.Empty(t, e.core.loops.wait(5*time.Second))
+
+	// the backlog is still waiting for the next height, it did not go through the state machine
+	require.Equal(t, backlog, e.core.msgQueue.Len())
+	require.Equal(t, PrecommitDone, e.core.step)
+	require.Less(t, latency, time.Second, "commit delayed by the future height backlog")
+}
@@ -17,9 +17,14 @@
 package core
 
 import (
+	"time"
+
 	"github.com/autonity/autonity/metrics"
 )
 
+// stateMetricsInterval is the refresh interval of the consensus state gauges.
+const stateMetricsInterval = 3 * time.Second
+
 var (
 	HeightChangeMeter = metrics.NewRegisteredMeter("tendermint/height/change", nil)
 	RoundChangeMeter  = metrics.NewRegisteredMeter("tendermint/round/change", nil)
@@ -75,8 +80,39 @@ var (
 	MsgPrevotePackets   = metrics.NewRegisteredMeter("core/handler/msg/prevote/packets", nil)   //nolint:goconst
 	MsgPrecommitPackets = metrics.NewRegisteredMeter("core/handler/msg/precommit/packets", nil) //nolint:goconst
 
+	MessageQueueDropMeter = metrics.NewRegisteredMeter("core/handler/msg/queue/drop", nil) // messages dropped from the full message queue
+
+	msgStoreMessagesGauge        = metrics.NewRegisteredGauge("tendermint/msgstore/messages", nil)         // messages, or digests, buffered by the msg store
+	msgStoreBytesGauge           = metrics.NewRegisteredGauge("tendermint/msgstore/bytes", nil)            // size of the messages, or digests, buffered
+	msgStoreEvictedHeightsMeter  = metrics.NewRegisteredMeter("tendermint/msgstore/evicted/heights", nil)  // heights evicted over the capacity
+	msgStoreEvictedMessagesMeter = metrics.NewRegisteredMeter("tendermint/msgstore/evicted/messages", nil) // messages, or digests, evicted over the capacity
+	msgStoreDroppedMeter         = metrics.NewRegisteredMeter("tendermint/msgstore/dropped", nil)          // messages dropped over the sender quota
+	msgStoreRestoredMeter        = metrics.NewRegisteredMeter("tendermint/msgstore/restored", nil)         // messages restored from the database
+
 	AggregatorCoreTransitBg = metrics.NewRegisteredBufferedGauge("core/aggregator/transit", nil, metrics.GetIntPointer(100)) // measures time for message passing from backend to aggregator
 
 	// temporary metrics to evaluate whether core.roundChangeMu is causing lock contention issues
 	RoundChangeMuBg = metrics.NewRegisteredBufferedGauge("tendermint/roundchangemu.bg", nil, nil)
+
+	// consensus state, refreshed from the state snapshot
+	StateHeightGauge         = metrics.NewRegisteredGauge("tendermint/state/height", nil)
+	StateRoundGauge          = metrics.NewRegisteredGauge("tendermint/state/round", nil)
+	StateStepGauge           = metrics.NewRegisteredGauge("tendermint/state/step", nil)
+	StateLockedRoundGauge    = metrics.NewRegisteredGauge("tendermint/state/lockedround", nil)
+	StateValidRoundGauge     = metrics.NewRegisteredGauge("tendermint/state/validround", nil)
+	StateMessagesGauge       = metrics.NewRegisteredGauge("tendermint/state/messages", nil)
+	StateFutureMessagesGauge = metrics.NewRegisteredGauge("tendermint/state/messages/future", nil)
+	StateStepDurationGauge   = metrics.NewRegisteredGauge("tendermint/state/step/duration", nil)
 )
+
+// updateStateMetrics refreshes the consensus state gauges from the snapshot.
+func updateStateMetrics(s StateSnapshot) {
+	StateHeightGauge.Update(int64(s.Height))
+	StateRoundGauge.Update(s.Round)
+	StateStepGauge.Update(int64(s.Step))
+	StateLockedRoundGauge.Update(s.LockedRound)
+	StateValidRoundGauge.Update(s.ValidRound)
+	StateMessagesGauge.Update(int64(s.CurHeightMessages))
+	StateFutureMessagesGauge.Update(int64(s.FutureRoundMessages))
+	StateStepDurationGauge.Update(time.Since(s.StepStart).Milliseconds())
+}
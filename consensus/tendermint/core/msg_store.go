@@ -1,201 +1,673 @@
 package core
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/rlp"
 )
 
 var NilValue = common.Hash{}
 
+// ErrDigestMode is returned to the callers requiring the full messages while the MsgStore keeps their
+// digests only.
+var ErrDigestMode = errors.New("msg store in digest mode, full messages unavailable")
+
+var (
+	errUnknownPersistedMessage  = errors.New("unknown persisted message code")
+	errMismatchPersistedMessage = errors.New("persisted message does not match its metadata")
+)
+
+// DefaultMsgStoreCapacity is the default number of messages, or digests, buffered by the MsgStore. It is
+// about a thousand per height over the accountability window.
+const DefaultMsgStoreCapacity = 1 << 18
+
+// MsgStoreSenderQuota is the number of messages of a kind kept per signer and round in full mode. An honest
+// validator signs a single proposal, prevote and precommit per round, the second message kept being the
+// equivocation evidence. Beyond it, the messages bringing no signer within its quota are dropped.
+const MsgStoreSenderQuota = 2
+
+// digestSize is the memory held by a signer digest in digest mode.
+const digestSize = int(unsafe.Sizeof(digestKey{}) + unsafe.Sizeof(digest{}))
+
+// MsgStoreMode is the way the MsgStore keeps the consensus messages.
+type MsgStoreMode uint8
+
+const (
+	// FullMode keeps the full messages, for the fault detector to build proofs out of them.
+	FullMode MsgStoreMode = iota
+	// DigestMode keeps a digest of the messages by signer only, enough for statistics and omission
+	// observation. It is meant for the nodes which do not take part in consensus.
+	DigestMode
+)
+
+func (m MsgStoreMode) String() string {
+	switch m {
+	case FullMode:
+		return "full"
+	case DigestMode:
+		return "digest"
+	default:
+		return "unknown"
+	}
+}
+
+// MsgStore buffers the consensus messages for the fault detector. It is sharded by height, so that the
+// consensus hot path saving messages for the current height does not contend with the fault detector
+// scanning older heights. Beyond its capacity, the oldest heights are evicted.
+//
+// A persistent store also writes the full messages to the database. The messages of a height saved before
+// a restart are restored on the first access to the height, so that the node can still raise or defend
+// against accusations about it. The rules are not applied to the restored heights, which are older than the
+// first height buffered, since the messages of the heights the node was down for are missing.
 type MsgStore struct {
-	sync.RWMutex
-	// the first height that msg are buffered from after node is start.
+	// mu protects the height index, firstHeight, mode and capacity, the messages are protected by their height lock.
+	mu sync.RWMutex
+	// the first height that msg are buffered from after node is start, moved past the evicted heights.
 	firstHeight uint64
-	proposals   map[uint64][]*message.Propose
-	prevotes    map[uint64][]*message.Prevote
-	precommits  map[uint64][]*message.Precommit
+	heights     map[uint64]*heightMsgStore
+	mode        MsgStoreMode
+	capacity    int    // maximum number of messages, or digests, buffered. 0 for no limit.
+	retainFrom  uint64 // lowest height accepted, the late messages of the evicted heights below are discarded
+
+	count   atomic.Int64  // messages, or digests, buffered
+	size    atomic.Int64  // bytes buffered
+	evicted atomic.Uint64 // heights evicted over the capacity
+	dropped atomic.Uint64 // messages dropped over the sender quota
+
+	db ethdb.Database // database the full messages are persisted to, nil if they are not
+}
+
+// heightMsgStore holds the messages of a single height.
+type heightMsgStore struct {
+	sync.RWMutex
+	proposals  []*message.Propose
+	prevotes   []*message.Prevote
+	precommits []*message.Precommit
 
 	// in the fault detector we only do power computation on prevotes, therefore cache only prevote power
-	prevotesPower map[uint64]map[int64]map[common.Hash]*message.AggregatedPower
+	prevotesPower map[int64]map[common.Hash]*message.AggregatedPower
+
+	// digests of the messages by signer in digest mode, the first message of a signer being kept.
+	digests map[digestKey]digest
+	// messages kept by signer in full mode, bounded by MsgStoreSenderQuota.
+	quotas map[digestKey]int
+
+	count   int  // messages, or digests, of the height
+	size    int  // bytes of the messages, or digests, of the height
+	dropped bool // the shard was removed from the store, the messages saved into it are discarded
 }
 
+type digestKey struct {
+	round  int64
+	code   uint8
+	signer int
+}
+
+type digest struct {
+	value     common.Hash
+	signature common.Hash
+}
+
+// MsgDigest is the trace kept of a consensus message for each of its signers in digest mode.
+type MsgDigest struct {
+	Round     int64
+	Code      uint8
+	Signer    int         // committee index of the signer
+	Value     common.Hash // value proposed or voted for
+	Signature common.Hash // hash of the message signature, aggregated for the votes
+}
+
+// MsgStoreStats summarises the content of the MsgStore.
+type MsgStoreStats struct {
+	Mode        MsgStoreMode
+	FirstHeight uint64 // first height buffered since the node started, or the mode was set
+	Heights     int    // number of heights buffered
+	Messages    int    // number of full messages buffered, in full mode
+	Digests     int    // number of signer digests buffered, in digest mode
+	Bytes       int    // size of the messages, or digests, buffered
+	Evicted     uint64 // number of heights evicted over the capacity
+	Dropped     uint64 // number of messages dropped over the sender quota
+}
+
+// persistedMsg is a consensus message as persisted, along with the metadata it is checked against once decoded.
+type persistedMsg struct {
+	Code    uint8
+	Round   uint64
+	Value   common.Hash
+	Payload []byte
+}
+
+// NewMsgStore returns a msg store in full mode.
 func NewMsgStore() *MsgStore {
 	return &MsgStore{
-		RWMutex:       sync.RWMutex{},
-		firstHeight:   uint64(0),
-		proposals:     make(map[uint64][]*message.Propose),
-		prevotes:      make(map[uint64][]*message.Prevote),
-		precommits:    make(map[uint64][]*message.Precommit),
-		prevotesPower: make(map[uint64]map[int64]map[common.Hash]*message.AggregatedPower),
+		firstHeight: uint64(0),
+		heights:     make(map[uint64]*heightMsgStore),
+		capacity:    DefaultMsgStoreCapacity,
 	}
 }
 
-// Save store msg into msg store, it assumes the msg signature was verified, and there is no duplicated msg in the store.
-func (ms *MsgStore) Save(m message.Msg) {
-	ms.Lock()
-	defer ms.Unlock()
+// NewPersistentMsgStore returns a msg store in full mode persisting the full messages to the database. The
+// restored messages are validated against the committee of the canonical headers of the database.
+func NewPersistentMsgStore(db ethdb.Database) *MsgStore {
+	ms := NewMsgStore()
+	ms.db = db
+	return ms
+}
+
+// SetMode sets the way the messages are kept. The messages buffered, and persisted, so far are dropped on a
+// change, so that the heights are never partially buffered in either mode.
+func (ms *MsgStore) SetMode(mode MsgStoreMode) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.mode == mode {
+		return
+	}
+	ms.mode = mode
+	ms.firstHeight = 0
+	ms.retainFrom = 0
+	for h := range ms.heights {
+		ms.dropHeight(h)
+	}
+	if ms.db != nil {
+		rawdb.DeleteMsgStoreMessagesBelow(ms.db, math.MaxUint64)
+	}
+	ms.updateGauges()
+}
 
-	height := m.H()
+// SetCapacity sets the maximum number of messages, or digests in digest mode, buffered. 0 for no limit.
+// Beyond it, the oldest heights are evicted, the height being saved into never being evicted.
+func (ms *MsgStore) SetCapacity(capacity int) {
+	ms.mu.Lock()
+	ms.capacity = capacity
+	ms.mu.Unlock()
+}
+
+// Mode returns the way the messages are kept.
+func (ms *MsgStore) Mode() MsgStoreMode {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.mode
+}
 
-	if ms.firstHeight == uint64(0) {
+// RequireMessages returns ErrDigestMode if the full messages are not kept. The queries of full
+// messages, and the quorum search, return no message in digest mode: the callers building proofs
+// out of them check this first.
+func (ms *MsgStore) RequireMessages() error {
+	if ms.Mode() == DigestMode {
+		return ErrDigestMode
+	}
+	return nil
+}
+
+// height returns the shard of the given height, nil if there is none.
+func (ms *MsgStore) height(height uint64) *heightMsgStore {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.heights[height]
+}
+
+// load returns the shard of the given height, restoring it from the database if it is missing and the
+// messages are persisted. It returns nil if there is none.
+func (ms *MsgStore) load(height uint64) *heightMsgStore {
+	return ms.shard(height, false)
+}
+
+// getOrCreateHeight returns the shard of the given height, creating it if needed. It returns nil if the
+// height was evicted.
+func (ms *MsgStore) getOrCreateHeight(height uint64) *heightMsgStore {
+	return ms.shard(height, true)
+}
+
+// shard returns the shard of the given height. A missing shard is restored from the database if the messages
+// are persisted, and created otherwise if create is true. It returns nil if there is none, or if the height
+// was evicted.
+func (ms *MsgStore) shard(height uint64, create bool) *heightMsgStore {
+	if hs := ms.height(height); hs != nil {
+		return hs
+	}
+	// the persisted messages are decoded before taking the lock, not to block the other heights
+	restored := ms.restore(height)
+	if !create && len(restored) == 0 {
+		return nil
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if height < ms.retainFrom {
+		return nil
+	}
+	// the restored heights do not count as buffered, only the heights saved into since the start do
+	if create && ms.firstHeight == uint64(0) {
 		ms.firstHeight = height
 	}
+	hs, ok := ms.heights[height]
+	if !ok {
+		hs = &heightMsgStore{prevotesPower: make(map[int64]map[common.Hash]*message.AggregatedPower)}
+		if ms.mode == DigestMode {
+			hs.digests = make(map[digestKey]digest)
+		} else {
+			hs.quotas = make(map[digestKey]int)
+			for _, m := range restored {
+				hs.add(m)
+			}
+			ms.count.Add(int64(hs.count))
+			ms.size.Add(int64(hs.size))
+			ms.updateGauges()
+		}
+		ms.heights[height] = hs
+	}
+	return hs
+}
+
+// restore decodes the messages of the height persisted to the database, nil if the messages are not persisted.
+func (ms *MsgStore) restore(height uint64) []message.Msg {
+	if ms.db == nil || height == 0 || ms.Mode() != FullMode {
+		return nil
+	}
+	data := rawdb.ReadMsgStoreMessages(ms.db, height)
+	if len(data) == 0 {
+		return nil
+	}
+	// the messages of a height are signed by the committee of its parent
+	parent := rawdb.ReadHeader(ms.db, rawdb.ReadCanonicalHash(ms.db, height-1), height-1)
+	if parent == nil {
+		log.Warn("Missing the committee of the persisted consensus messages", "height", height)
+		return nil
+	}
+	msgs := make([]message.Msg, 0, len(data))
+	for _, d := range data {
+		msg, err := decodePersisted(d, parent)
+		if err != nil {
+			log.Warn("Discarding an invalid persisted consensus message", "height", height, "err", err)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	msgStoreRestoredMeter.Mark(int64(len(msgs)))
+	return msgs
+}
+
+// decodePersisted decodes a persisted message and pre-validates it against the committee of the parent header.
+// Its signature is not verified again: the messages are verified before they are saved.
+func decodePersisted(data []byte, parent *types.Header) (message.Msg, error) {
+	var persisted persistedMsg
+	if err := rlp.DecodeBytes(data, &persisted); err != nil {
+		return nil, err
+	}
+	var msg message.Msg
+	switch persisted.Code {
+	case message.ProposalCode:
+		msg = new(message.Propose)
+	case message.PrevoteCode:
+		msg = new(message.Prevote)
+	case message.PrecommitCode:
+		msg = new(message.Precommit)
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnknownPersistedMessage, persisted.Code)
+	}
+	if err := rlp.DecodeBytes(persisted.Payload, msg); err != nil {
+		return nil, err
+	}
+	if msg.H() != parent.Number.Uint64()+1 || msg.R() != int64(persisted.Round) || msg.Value() != persisted.Value {
+		return nil, errMismatchPersistedMessage
+	}
+	if err := msg.PreValidate(parent); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
 
+// persist writes the message to the database, for it to be restored after a restart.
+func (ms *MsgStore) persist(m message.Msg) {
+	data, err := rlp.EncodeToBytes(&persistedMsg{Code: m.Code(), Round: uint64(m.R()), Value: m.Value(), Payload: m.Payload()})
+	if err != nil {
+		log.Error("Failed to encode the consensus message", "err", err)
+		return
+	}
+	rawdb.WriteMsgStoreMessage(ms.db, m.H(), msgSigner(m), m.Code(), m.Hash(), data)
+}
+
+// msgSigner returns the committee index of the proposer, or of the first signer of a vote.
+func msgSigner(m message.Msg) uint32 {
 	switch msg := m.(type) {
 	case *message.Propose:
-		_, ok := ms.proposals[height]
-		if !ok {
-			ms.proposals[height] = make([]*message.Propose, 0)
+		return uint32(msg.SignerIndex())
+	case message.Vote:
+		if signers := msg.Signers().FlattenUniq(); len(signers) > 0 {
+			return uint32(signers[0])
 		}
-		ms.proposals[height] = append(ms.proposals[height], msg)
-	case *message.Prevote:
-		_, ok := ms.prevotes[height]
-		if !ok {
-			ms.prevotes[height] = make([]*message.Prevote, 0)
-		}
-		ms.prevotes[height] = append(ms.prevotes[height], msg)
+	}
+	return 0
+}
+
+// Save store msg into msg store, it assumes the msg signature was verified, and there is no duplicated msg in the store.
+// In full mode, the messages of signers over their quota are dropped.
+func (ms *MsgStore) Save(m message.Msg) {
+	if ms.save(m) > 0 {
+		ms.evict(m.H())
+	}
+}
+
+// save buffers the message, and returns the number of messages, or digests, buffered by the store beyond its capacity.
+func (ms *MsgStore) save(m message.Msg) int64 {
+	hs := ms.getOrCreateHeight(m.H())
+	if hs == nil {
+		return 0
+	}
+	hs.Lock()
+	defer hs.Unlock()
 
-		// update prevotes power cache
-		round := msg.R()
-		value := msg.Value()
-		_, ok = ms.prevotesPower[height]
-		if !ok {
-			ms.prevotesPower[height] = make(map[int64]map[common.Hash]*message.AggregatedPower)
+	// the height was evicted since it was looked up
+	if hs.dropped {
+		return 0
+	}
+	count, size := hs.count, hs.size
+	// the shard of a height is created in the mode of the store
+	if hs.digests != nil {
+		hs.saveDigest(m)
+	} else {
+		if !hs.add(m) {
+			ms.dropped.Add(1)
+			msgStoreDroppedMeter.Mark(1)
+			return 0
 		}
-		_, ok = ms.prevotesPower[height][round]
-		if !ok {
-			ms.prevotesPower[height][round] = make(map[common.Hash]*message.AggregatedPower)
+		if ms.db != nil {
+			ms.persist(m)
 		}
-		_, ok = ms.prevotesPower[height][round][value]
-		if !ok {
-			ms.prevotesPower[height][round][value] = message.NewAggregatedPower()
+	}
+	total := ms.count.Add(int64(hs.count - count))
+	ms.size.Add(int64(hs.size - size))
+
+	ms.mu.RLock()
+	capacity := int64(ms.capacity)
+	ms.mu.RUnlock()
+	if capacity == 0 || total <= capacity {
+		msgStoreMessagesGauge.Update(total)
+		msgStoreBytesGauge.Update(ms.size.Load())
+		return 0
+	}
+	return total - capacity
+}
+
+// evict drops the oldest heights below keep until the store is back within its capacity, and moves the
+// first height buffered past them: the rules are not applied to the heights partially buffered.
+func (ms *MsgStore) evict(keep uint64) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	olds := make([]uint64, 0, len(ms.heights))
+	for h := range ms.heights {
+		if h < keep {
+			olds = append(olds, h)
 		}
-		for index, power := range msg.Signers().Powers() {
-			ms.prevotesPower[height][round][value].Set(index, power)
+	}
+	sort.Slice(olds, func(i, j int) bool { return olds[i] < olds[j] })
+	for _, h := range olds {
+		if ms.capacity == 0 || ms.count.Load() <= int64(ms.capacity) {
+			break
 		}
+		evicted := ms.dropHeight(h)
+		ms.evicted.Add(1)
+		msgStoreEvictedHeightsMeter.Mark(1)
+		msgStoreEvictedMessagesMeter.Mark(int64(evicted))
+		ms.retainFrom = max(ms.retainFrom, h+1)
+		ms.firstHeight = max(ms.firstHeight, h)
+	}
+	ms.updateGauges()
+}
+
+// dropHeight removes the shard of the height from the store, and returns the number of messages, or digests,
+// it held. The caller must hold the write lock.
+func (ms *MsgStore) dropHeight(height uint64) int {
+	hs := ms.heights[height]
+	delete(ms.heights, height)
+	hs.Lock()
+	defer hs.Unlock()
+	hs.dropped = true
+	ms.count.Add(-int64(hs.count))
+	ms.size.Add(-int64(hs.size))
+	return hs.count
+}
+
+func (ms *MsgStore) updateGauges() {
+	msgStoreMessagesGauge.Update(ms.count.Load())
+	msgStoreBytesGauge.Update(ms.size.Load())
+}
+
+// add buffers the message in full mode, and returns false if it was dropped over the sender quota.
+func (hs *heightMsgStore) add(m message.Msg) bool {
+	if !hs.consumeQuota(m) {
+		return false
+	}
+	switch msg := m.(type) {
+	case *message.Propose:
+		hs.proposals = append(hs.proposals, msg)
+	case *message.Prevote:
+		hs.prevotes = append(hs.prevotes, msg)
+		hs.addPrevotePower(msg)
+	case *message.Precommit:
+		hs.precommits = append(hs.precommits, msg)
+	}
+	hs.count++
+	hs.size += len(m.Payload())
+	return true
+}
+
+// saveDigest records the digest of the message for each of its signers, and the prevotes power.
+func (hs *heightMsgStore) saveDigest(m message.Msg) {
+	d := digest{value: m.Value(), signature: crypto.Keccak256Hash(m.Signature().Marshal())}
+	switch msg := m.(type) {
+	case *message.Propose:
+		hs.addDigest(digestKey{round: msg.R(), code: msg.Code(), signer: msg.SignerIndex()}, d)
+	case *message.Prevote:
+		for _, signer := range msg.Signers().FlattenUniq() {
+			hs.addDigest(digestKey{round: msg.R(), code: msg.Code(), signer: signer}, d)
+		}
+		hs.addPrevotePower(msg)
 	case *message.Precommit:
-		_, ok := ms.precommits[height]
-		if !ok {
-			ms.precommits[height] = make([]*message.Precommit, 0)
+		for _, signer := range msg.Signers().FlattenUniq() {
+			hs.addDigest(digestKey{round: msg.R(), code: msg.Code(), signer: signer}, d)
+		}
+	}
+}
+
+// quotaKeys returns the quota keys of the signers of the message.
+func quotaKeys(m message.Msg) []digestKey {
+	switch msg := m.(type) {
+	case *message.Propose:
+		return []digestKey{{round: msg.R(), code: msg.Code(), signer: msg.SignerIndex()}}
+	case message.Vote:
+		signers := msg.Signers().FlattenUniq()
+		keys := make([]digestKey, len(signers))
+		for i, signer := range signers {
+			keys[i] = digestKey{round: msg.R(), code: msg.Code(), signer: signer}
+		}
+		return keys
+	}
+	return nil
+}
+
+// consumeQuota returns false if every signer of the message is over its quota. Otherwise the message is
+// accounted in the quota of each of its signers.
+func (hs *heightMsgStore) consumeQuota(m message.Msg) bool {
+	keys := quotaKeys(m)
+	within := false
+	for _, key := range keys {
+		if hs.quotas[key] < MsgStoreSenderQuota {
+			within = true
+			break
+		}
+	}
+	if !within {
+		return false
+	}
+	for _, key := range keys {
+		hs.quotas[key]++
+	}
+	return true
+}
+
+// releaseQuota gives the quota consumed by the message back to its signers.
+func (hs *heightMsgStore) releaseQuota(m message.Msg) {
+	for _, key := range quotaKeys(m) {
+		if hs.quotas[key]--; hs.quotas[key] <= 0 {
+			delete(hs.quotas, key)
 		}
-		ms.precommits[height] = append(ms.precommits[height], msg)
+	}
+}
+
+func (hs *heightMsgStore) addDigest(key digestKey, d digest) {
+	if _, ok := hs.digests[key]; !ok {
+		hs.digests[key] = d
+		hs.count++
+		hs.size += digestSize
+	}
+}
+
+// addPrevotePower updates the prevotes power cache.
+func (hs *heightMsgStore) addPrevotePower(msg *message.Prevote) {
+	round := msg.R()
+	value := msg.Value()
+	_, ok := hs.prevotesPower[round]
+	if !ok {
+		hs.prevotesPower[round] = make(map[common.Hash]*message.AggregatedPower)
+	}
+	_, ok = hs.prevotesPower[round][value]
+	if !ok {
+		hs.prevotesPower[round][value] = message.NewAggregatedPower()
+	}
+	for index, power := range msg.Signers().Powers() {
+		hs.prevotesPower[round][value].Set(index, power)
 	}
 }
 
 func (ms *MsgStore) FirstHeightBuffered() uint64 {
-	ms.Lock()
-	defer ms.Unlock()
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
 	return ms.firstHeight
 }
 
+// DeleteOlds drops the messages of the heights up to the given one included, from the database as well if
+// they are persisted.
 func (ms *MsgStore) DeleteOlds(height uint64) {
-	ms.Lock()
-	defer ms.Unlock()
-	for h := range ms.proposals {
-		if h <= height {
-			delete(ms.proposals, h)
-		}
+	if ms.db != nil {
+		// deferred first, the database range is deleted once the shards are dropped and the lock released, so
+		// that no message is persisted into it meanwhile
+		defer rawdb.DeleteMsgStoreMessagesBelow(ms.db, height+1)
 	}
-	for h := range ms.prevotes {
+	// collect the candidate heights under the read lock, so that the concurrent Save calls are not blocked by the scan
+	ms.mu.RLock()
+	var olds []uint64
+	for h := range ms.heights {
 		if h <= height {
-			delete(ms.prevotes, h)
+			olds = append(olds, h)
 		}
 	}
-	for h := range ms.precommits {
-		if h <= height {
-			delete(ms.precommits, h)
-		}
+	ms.mu.RUnlock()
+	if len(olds) == 0 {
+		return
 	}
-	for h := range ms.prevotesPower {
-		if h <= height {
-			delete(ms.prevotesPower, h)
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for _, h := range olds {
+		if _, ok := ms.heights[h]; ok {
+			ms.dropHeight(h)
 		}
 	}
+	ms.updateGauges()
 }
 
 // RemoveMsg only used for integration tests.
 func (ms *MsgStore) RemoveMsg(height uint64, code uint8, hash common.Hash) {
-	ms.Lock()
-	defer ms.Unlock()
+	hs := ms.height(height)
+	if hs == nil {
+		if code != message.ProposalCode && code != message.PrevoteCode && code != message.PrecommitCode {
+			panic("non-existent code")
+		}
+		return
+	}
+	hs.Lock()
+	defer hs.Unlock()
 
 	switch code {
 	case message.ProposalCode:
-		_, ok := ms.proposals[height]
-		if !ok {
-			return
-		}
 		var filteredProposals []*message.Propose
-		for _, proposal := range ms.proposals[height] {
+		for _, proposal := range hs.proposals {
 			if proposal.Hash() != hash {
 				filteredProposals = append(filteredProposals, proposal)
+			} else {
+				ms.forget(hs, proposal)
 			}
 		}
-		ms.proposals[height] = filteredProposals
+		hs.proposals = filteredProposals
 	case message.PrevoteCode:
-		_, ok := ms.prevotes[height]
-		if !ok {
-			return
-		}
 		var filteredPrevotes []*message.Prevote
-		for _, prevote := range ms.prevotes[height] {
+		for _, prevote := range hs.prevotes {
 			if prevote.Hash() != hash {
 				filteredPrevotes = append(filteredPrevotes, prevote)
+			} else {
+				ms.forget(hs, prevote)
 			}
 		}
-		ms.prevotes[height] = filteredPrevotes
+		hs.prevotes = filteredPrevotes
 
 		// update power cache
-		ms.prevotesPower = make(map[uint64]map[int64]map[common.Hash]*message.AggregatedPower)
-		for _, msg := range ms.prevotes[height] {
-			round := msg.R()
-			value := msg.Value()
-			_, ok = ms.prevotesPower[height]
-			if !ok {
-				ms.prevotesPower[height] = make(map[int64]map[common.Hash]*message.AggregatedPower)
-			}
-			_, ok = ms.prevotesPower[height][round]
-			if !ok {
-				ms.prevotesPower[height][round] = make(map[common.Hash]*message.AggregatedPower)
-			}
-			_, ok = ms.prevotesPower[height][round][value]
-			if !ok {
-				ms.prevotesPower[height][round][value] = message.NewAggregatedPower()
-			}
-			for index, power := range msg.Signers().Powers() {
-				ms.prevotesPower[height][round][value].Set(index, power)
-			}
+		hs.prevotesPower = make(map[int64]map[common.Hash]*message.AggregatedPower)
+		for _, msg := range hs.prevotes {
+			hs.addPrevotePower(msg)
 		}
 	case message.PrecommitCode:
-		_, ok := ms.precommits[height]
-		if !ok {
-			return
-		}
 		var filteredPrecommits []*message.Precommit
-		for _, precommit := range ms.precommits[height] {
+		for _, precommit := range hs.precommits {
 			if precommit.Hash() != hash {
 				filteredPrecommits = append(filteredPrecommits, precommit)
+			} else {
+				ms.forget(hs, precommit)
 			}
 		}
-		ms.precommits[height] = filteredPrecommits
+		hs.precommits = filteredPrecommits
 	default:
 		panic("non-existent code")
 	}
 }
 
-func (ms *MsgStore) GetProposals(height uint64, query func(*message.Propose) bool) []*message.Propose {
-	ms.RLock()
-	defer ms.RUnlock()
+// forget updates the counters of the store for a message removed from the shard of its height.
+func (ms *MsgStore) forget(hs *heightMsgStore, m message.Msg) {
+	if ms.db != nil {
+		rawdb.DeleteMsgStoreMessage(ms.db, m.H(), msgSigner(m), m.Code(), m.Hash())
+	}
+	hs.releaseQuota(m)
+	hs.count--
+	hs.size -= len(m.Payload())
+	ms.count.Add(-1)
+	ms.size.Add(-int64(len(m.Payload())))
+}
 
+func (ms *MsgStore) GetProposals(height uint64, query func(*message.Propose) bool) []*message.Propose {
 	var result []*message.Propose
-	_, ok := ms.proposals[height]
-	if !ok {
+	hs := ms.load(height)
+	if hs == nil {
 		return result
 	}
+	hs.RLock()
+	defer hs.RUnlock()
 
-	for _, proposal := range ms.proposals[height] {
+	for _, proposal := range hs.proposals {
 		if query(proposal) {
 			result = append(result, proposal)
 		}
@@ -204,16 +676,15 @@ func (ms *MsgStore) GetProposals(height uint64, query func(*message.Propose) boo
 }
 
 func (ms *MsgStore) GetPrevotes(height uint64, query func(*message.Prevote) bool) []*message.Prevote {
-	ms.RLock()
-	defer ms.RUnlock()
-
 	var result []*message.Prevote
-	_, ok := ms.prevotes[height]
-	if !ok {
+	hs := ms.load(height)
+	if hs == nil {
 		return result
 	}
+	hs.RLock()
+	defer hs.RUnlock()
 
-	for _, prevote := range ms.prevotes[height] {
+	for _, prevote := range hs.prevotes {
 		if query(prevote) {
 			result = append(result, prevote)
 		}
@@ -222,16 +693,15 @@ func (ms *MsgStore) GetPrevotes(height uint64, query func(*message.Prevote) bool
 }
 
 func (ms *MsgStore) GetPrecommits(height uint64, query func(*message.Precommit) bool) []*message.Precommit {
-	ms.RLock()
-	defer ms.RUnlock()
-
 	var result []*message.Precommit
-	_, ok := ms.precommits[height]
-	if !ok {
+	hs := ms.load(height)
+	if hs == nil {
 		return result
 	}
+	hs.RLock()
+	defer hs.RUnlock()
 
-	for _, precommit := range ms.precommits[height] {
+	for _, precommit := range hs.precommits {
 		if query(precommit) {
 			result = append(result, precommit)
 		}
@@ -239,53 +709,97 @@ func (ms *MsgStore) GetPrecommits(height uint64, query func(*message.Precommit)
 	return result
 }
 
-func (ms *MsgStore) PrevotesPowerFor(height uint64, round int64, value common.Hash) *big.Int {
-	ms.RLock()
-	defer ms.RUnlock()
+// GetRange returns the messages of the heights from `from` to `to` included matching the query, ordered by
+// height, then proposals, prevotes and precommits. It is empty in digest mode.
+func (ms *MsgStore) GetRange(from, to uint64, query func(message.Msg) bool) []message.Msg {
+	type shard struct {
+		height uint64
+		hs     *heightMsgStore
+	}
+	if ms.db != nil {
+		for _, h := range rawdb.ReadMsgStoreHeights(ms.db, from, to) {
+			ms.load(h)
+		}
+	}
+	// a single pass over the index rather than a lookup per height of the window
+	ms.mu.RLock()
+	shards := make([]shard, 0, len(ms.heights))
+	for h, hs := range ms.heights {
+		if h >= from && h <= to {
+			shards = append(shards, shard{height: h, hs: hs})
+		}
+	}
+	ms.mu.RUnlock()
+	sort.Slice(shards, func(i, j int) bool { return shards[i].height < shards[j].height })
 
-	_, ok := ms.prevotesPower[height]
-	if !ok {
+	var result []message.Msg
+	for _, s := range shards {
+		s.hs.RLock()
+		for _, proposal := range s.hs.proposals {
+			if query(proposal) {
+				result = append(result, proposal)
+			}
+		}
+		for _, prevote := range s.hs.prevotes {
+			if query(prevote) {
+				result = append(result, prevote)
+			}
+		}
+		for _, precommit := range s.hs.precommits {
+			if query(precommit) {
+				result = append(result, precommit)
+			}
+		}
+		s.hs.RUnlock()
+	}
+	return result
+}
+
+func (ms *MsgStore) PrevotesPowerFor(height uint64, round int64, value common.Hash) *big.Int {
+	hs := ms.load(height)
+	if hs == nil {
 		return new(big.Int)
 	}
-	_, ok = ms.prevotesPower[height][round]
+	hs.RLock()
+	defer hs.RUnlock()
+
+	_, ok := hs.prevotesPower[round]
 	if !ok {
 		return new(big.Int)
 	}
-	_, ok = ms.prevotesPower[height][round][value]
+	_, ok = hs.prevotesPower[round][value]
 	if !ok {
 		return new(big.Int)
 	}
-	return new(big.Int).Set(ms.prevotesPower[height][round][value].Power()) // return a copy to avoid data races
+	return new(big.Int).Set(hs.prevotesPower[round][value].Power()) // return a copy to avoid data races
 }
 
 // this function checks if we have a quorum for a value in (h,r). It excludes the `excludedValue` from the search.
 // it is used by the fault detector to verify if we have quorums of prevotes for values != `excludedValue`.
 // returns the slice of messages constituting the quorum
 func (ms *MsgStore) SearchQuorum(height uint64, round int64, excludedValue common.Hash, quorum *big.Int) []message.Msg {
-	ms.Lock()
-	defer ms.Unlock()
-
 	var result []message.Msg
-
-	_, ok := ms.prevotesPower[height]
-	if !ok {
+	hs := ms.load(height)
+	if hs == nil {
 		return result
 	}
-	_, ok = ms.prevotesPower[height][round]
-	if !ok {
+	hs.RLock()
+	defer hs.RUnlock()
+
+	_, ok := hs.prevotesPower[round]
+	if !ok || hs.digests != nil {
 		return result
 	}
 
-	for value, aggregatedPower := range ms.prevotesPower[height][round] {
+	for value, aggregatedPower := range hs.prevotesPower[round] {
 		if value == excludedValue {
 			continue
 		}
 		if aggregatedPower.Power().Cmp(quorum) >= 0 {
-			_, ok := ms.prevotes[height]
-			if !ok {
+			if len(hs.prevotes) == 0 {
 				panic("Have quorum in power cache, but cannot find related messages in msgStore")
 			}
-			for _, prevote := range ms.prevotes[height] {
+			for _, prevote := range hs.prevotes {
 				if prevote.R() == round && prevote.Value() == value {
 					result = append(result, prevote)
 				}
@@ -296,3 +810,88 @@ func (ms *MsgStore) SearchQuorum(height uint64, round int64, excludedValue commo
 
 	return result
 }
+
+// Has reports whether the store holds the message, or its digest in digest mode. The message does not need
+// to be verified.
+func (ms *MsgStore) Has(m message.Msg) bool {
+	hs := ms.load(m.H())
+	if hs == nil {
+		return false
+	}
+	hs.RLock()
+	defer hs.RUnlock()
+
+	if hs.digests != nil {
+		d := digest{value: m.Value(), signature: crypto.Keccak256Hash(m.Signature().Marshal())}
+		for key, held := range hs.digests {
+			if key.round == m.R() && key.code == m.Code() && held == d {
+				return true
+			}
+		}
+		return false
+	}
+	switch m.Code() {
+	case message.ProposalCode:
+		return containsHash(hs.proposals, m.Hash())
+	case message.PrevoteCode:
+		return containsHash(hs.prevotes, m.Hash())
+	case message.PrecommitCode:
+		return containsHash(hs.precommits, m.Hash())
+	}
+	return false
+}
+
+func containsHash[M message.Msg](msgs []M, hash common.Hash) bool {
+	for _, m := range msgs {
+		if m.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Digests returns the digests of the messages of the given height by round, code and signer. It is
+// empty in full mode.
+func (ms *MsgStore) Digests(height uint64) []MsgDigest {
+	hs := ms.height(height)
+	if hs == nil {
+		return nil
+	}
+	hs.RLock()
+	defer hs.RUnlock()
+
+	digests := make([]MsgDigest, 0, len(hs.digests))
+	for key, d := range hs.digests {
+		digests = append(digests, MsgDigest{Round: key.round, Code: key.code, Signer: key.signer, Value: d.value, Signature: d.signature})
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		if digests[i].Round != digests[j].Round {
+			return digests[i].Round < digests[j].Round
+		}
+		if digests[i].Code != digests[j].Code {
+			return digests[i].Code < digests[j].Code
+		}
+		return digests[i].Signer < digests[j].Signer
+	})
+	return digests
+}
+
+// Stats returns a summary of the content of the store.
+func (ms *MsgStore) Stats() MsgStoreStats {
+	ms.mu.RLock()
+	stats := MsgStoreStats{Mode: ms.mode, FirstHeight: ms.firstHeight, Heights: len(ms.heights), Evicted: ms.evicted.Load(), Dropped: ms.dropped.Load()}
+	shards := make([]*heightMsgStore, 0, len(ms.heights))
+	for _, hs := range ms.heights {
+		shards = append(shards, hs)
+	}
+	ms.mu.RUnlock()
+
+	for _, hs := range shards {
+		hs.RLock()
+		stats.Messages += len(hs.proposals) + len(hs.prevotes) + len(hs.precommits)
+		stats.Digests += len(hs.digests)
+		stats.Bytes += hs.size
+		hs.RUnlock()
+	}
+	return stats
+}
@@ -1,13 +1,25 @@
 package core
 
 import (
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/rlp"
+	"github.com/autonity/autonity/trie"
 )
 
 //TODO(lorenzo) need to add tests on the prevotes power caching
@@ -160,4 +172,581 @@ func TestMsgStore(t *testing.T) {
 		assert.Equal(t, 1, votes[0].Signers().Len())
 		assert.Equal(t, 1, votes[1].Signers().Len())
 	})
+
+	t.Run("concurrent access across heights", func(t *testing.T) {
+		ms := NewMsgStore()
+		const heights = 20
+		var wg sync.WaitGroup
+		for _, member := range committee {
+			m := member
+			key := keys[member.Address].consensus
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for h := uint64(1); h <= heights; h++ {
+					ms.Save(message.NewPrevote(round, h, NilValue, makeSigner(key), &m, cSize))
+					ms.Save(message.NewPrecommit(round, h, NilValue, makeSigner(key), &m, cSize))
+				}
+			}()
+		}
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for h := uint64(1); h <= heights; h++ {
+					ms.GetPrevotes(h, func(m *message.Prevote) bool { return m.Value() == NilValue })
+					ms.SearchQuorum(h, round, notNilValue, common.Big1)
+					ms.PrevotesPowerFor(h, round, NilValue)
+				}
+			}()
+		}
+		wg.Wait()
+
+		for h := uint64(1); h <= heights; h++ {
+			require.Len(t, ms.GetPrevotes(h, func(*message.Prevote) bool { return true }), cSize)
+			require.Len(t, ms.GetPrecommits(h, func(*message.Precommit) bool { return true }), cSize)
+			require.Equal(t, uint64(cSize), ms.PrevotesPowerFor(h, round, NilValue).Uint64())
+		}
+		ms.DeleteOlds(heights / 2)
+		require.Empty(t, ms.GetPrevotes(heights/2, func(*message.Prevote) bool { return true }))
+		require.Len(t, ms.GetPrevotes(heights/2+1, func(*message.Prevote) bool { return true }), cSize)
+		require.Equal(t, uint64(1), ms.FirstHeightBuffered())
+	})
+}
+
+// BenchmarkMsgStoreMixedWorkload saves a storm of prevotes at the latest height while the fault
+// detector concurrently scans 20 heights.
+func BenchmarkMsgStoreMixedWorkload(b *testing.B) {
+	const heights = 20
+	cSize := 100
+	committee, keys := GenerateCommittee(cSize)
+	prevotes := make([]*message.Prevote, cSize)
+	for i, member := range committee {
+		m := member
+		prevotes[i] = message.NewPrevote(0, heights+1, NilValue, makeSigner(keys[member.Address].consensus), &m, cSize)
+	}
+	ms := NewMsgStore()
+	for h := uint64(1); h <= heights; h++ {
+		for i, member := range committee {
+			m := member
+			if i%10 == 0 {
+				ms.Save(message.NewPrevote(0, h, NilValue, makeSigner(keys[member.Address].consensus), &m, cSize))
+			}
+		}
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%2 == 0 {
+				ms.Save(prevotes[i%cSize])
+			} else {
+				h := uint64(i%heights) + 1
+				ms.GetPrevotes(h, func(m *message.Prevote) bool { return m.R() == 0 && m.Value() == NilValue })
+				ms.SearchQuorum(h, 0, NilValue, common.Big1)
+			}
+			i++
+		}
+	})
+}
+
+func TestMsgStoreDigestMode(t *testing.T) {
+	height := uint64(100)
+	round := int64(0)
+	cSize := 4
+	committee, keys := GenerateCommittee(cSize)
+	signer := func(i int) message.Signer { return makeSigner(keys[committee[i].Address].consensus) }
+	value := common.Hash{0x1}
+
+	ms := NewMsgStore()
+	ms.SetMode(DigestMode)
+	require.Equal(t, DigestMode, ms.Mode())
+	require.ErrorIs(t, ms.RequireMessages(), ErrDigestMode)
+
+	proposal := message.NewPropose(round, height, -1, generateBlock(new(big.Int).SetUint64(height)), signer(0), &committee[0])
+	ms.Save(proposal)
+	for i := range committee {
+		ms.Save(message.NewPrevote(round, height, value, signer(i), &committee[i], cSize))
+	}
+	// the equivocated prevote of the first signer is not kept: the first digest is the evidence
+	equivocated := message.NewPrevote(round, height, NilValue, signer(0), &committee[0], cSize)
+	ms.Save(equivocated)
+	ms.Save(message.NewPrecommit(round, height, value, signer(1), &committee[1], cSize))
+	require.True(t, ms.Has(proposal))
+	require.True(t, ms.Has(message.NewPrevote(round, height, value, signer(2), &committee[2], cSize)))
+	require.False(t, ms.Has(equivocated))
+	require.False(t, ms.Has(message.NewPrecommit(round+1, height, value, signer(1), &committee[1], cSize)))
+
+	require.Empty(t, ms.GetProposals(height, func(*message.Propose) bool { return true }))
+	require.Empty(t, ms.GetPrevotes(height, func(*message.Prevote) bool { return true }))
+	require.Empty(t, ms.GetPrecommits(height, func(*message.Precommit) bool { return true }))
+	require.Empty(t, ms.SearchQuorum(height, round, NilValue, common.Big1))
+	require.Equal(t, 0, ms.PrevotesPowerFor(height, round, value).Cmp(big.NewInt(int64(cSize))))
+
+	digests := ms.Digests(height)
+	require.Len(t, digests, 1+cSize+1)
+	require.Equal(t, MsgDigest{Round: round, Code: message.ProposalCode, Signer: 0, Value: proposal.Value(),
+		Signature: crypto.Keccak256Hash(proposal.Signature().Marshal())}, digests[0])
+	for i := 0; i < cSize; i++ {
+		require.Equal(t, message.PrevoteCode, digests[1+i].Code)
+		require.Equal(t, i, digests[1+i].Signer)
+		require.Equal(t, value, digests[1+i].Value)
+	}
+	require.Equal(t, message.PrecommitCode, digests[1+cSize].Code)
+	require.Equal(t, MsgStoreStats{Mode: DigestMode, FirstHeight: height, Heights: 1, Digests: 1 + cSize + 1,
+		Bytes: (1 + cSize + 1) * digestSize}, ms.Stats())
+
+	// switching back to full mode drops the digests
+	ms.SetMode(FullMode)
+	require.NoError(t, ms.RequireMessages())
+	require.Empty(t, ms.Digests(height))
+	require.Equal(t, MsgStoreStats{Mode: FullMode}, ms.Stats())
+}
+
+func TestMsgStoreHas(t *testing.T) {
+	height := uint64(100)
+	cSize := 4
+	committee, keys := GenerateCommittee(cSize)
+	signer := func(i int) message.Signer { return makeSigner(keys[committee[i].Address].consensus) }
+	value := common.Hash{0x1}
+
+	ms := NewMsgStore()
+	proposal := message.NewPropose(0, height, -1, generateBlock(new(big.Int).SetUint64(height)), signer(0), &committee[0])
+	prevote := message.NewPrevote(0, height, value, signer(1), &committee[1], cSize)
+	precommit := message.NewPrecommit(0, height, value, signer(2), &committee[2], cSize)
+	for _, m := range []message.Msg{proposal, prevote, precommit} {
+		require.False(t, ms.Has(m))
+		ms.Save(m)
+		require.True(t, ms.Has(m))
+	}
+	// the messages are told apart by their hash, not by their content
+	require.False(t, ms.Has(message.NewPrevote(0, height, value, signer(2), &committee[2], cSize)))
+	require.False(t, ms.Has(message.NewPrecommit(0, height+1, value, signer(2), &committee[2], cSize)))
+}
+
+func TestMsgStoreSenderQuota(t *testing.T) {
+	height := uint64(100)
+	cSize := 4
+	committee, keys := GenerateCommittee(cSize)
+	signer := func(i int) message.Signer { return makeSigner(keys[committee[i].Address].consensus) }
+	value := common.Hash{0x1}
+	ms := NewMsgStore()
+
+	// a byzantine member spams prevotes for distinct values over every round. The store does not verify the
+	// signatures: a single one is reused to keep the test fast.
+	spam := 10_000
+	rounds := constants.MaxRound + 1
+	signature := signer(0)(common.Hash{})
+	spammer := func(common.Hash) blst.Signature { return signature }
+	for i := 0; i < spam; i++ {
+		ms.Save(message.NewPrevote(int64(i%rounds), height, common.BigToHash(big.NewInt(int64(i+2))), spammer, &committee[0], cSize))
+	}
+	stats := ms.Stats()
+	require.Equal(t, MsgStoreSenderQuota*rounds, stats.Messages)
+	require.Equal(t, uint64(spam-MsgStoreSenderQuota*rounds), stats.Dropped)
+
+	// the first two conflicting prevotes of each round are kept as equivocation evidence
+	for r := 0; r < rounds; r++ {
+		prevotes := ms.GetPrevotes(height, func(m *message.Prevote) bool { return m.R() == int64(r) })
+		require.Len(t, prevotes, MsgStoreSenderQuota)
+		require.Equal(t, common.BigToHash(big.NewInt(int64(r+2))), prevotes[0].Value())
+		require.Equal(t, common.BigToHash(big.NewInt(int64(r+rounds+2))), prevotes[1].Value())
+	}
+
+	// the messages of the other members are unaffected
+	ms.Save(message.NewPropose(0, height, -1, generateBlock(new(big.Int).SetUint64(height)), signer(1), &committee[1]))
+	for i := 1; i < cSize; i++ {
+		ms.Save(message.NewPrevote(0, height, value, signer(i), &committee[i], cSize))
+		ms.Save(message.NewPrecommit(0, height, value, signer(i), &committee[i], cSize))
+	}
+	require.Len(t, ms.GetProposals(height, func(*message.Propose) bool { return true }), 1)
+	require.Len(t, ms.GetPrevotes(height, func(m *message.Prevote) bool { return m.Value() == value }), cSize-1)
+	require.Len(t, ms.GetPrecommits(height, func(*message.Precommit) bool { return true }), cSize-1)
+	require.Equal(t, 0, ms.PrevotesPowerFor(height, 0, value).Cmp(big.NewInt(int64(cSize-1))))
+
+	// an aggregate is kept as long as one of its signers is within its quota
+	aggregate := func(value common.Hash, signers ...int) *message.Prevote {
+		votes := make([]message.Vote, len(signers))
+		for i, s := range signers {
+			votes[i] = message.NewPrevote(1, height, value, signer(s), &committee[s], cSize)
+		}
+		return message.AggregatePrevotes(votes)
+	}
+	ms.Save(aggregate(value, 0, 1))
+	require.Len(t, ms.GetPrevotes(height, func(m *message.Prevote) bool { return m.R() == 1 && m.Value() == value }), 1)
+	ms.Save(aggregate(common.Hash{0x2}, 0, 1))
+	ms.Save(aggregate(common.Hash{0x3}, 0, 1))
+	require.Len(t, ms.GetPrevotes(height, func(m *message.Prevote) bool { return m.R() == 1 && m.Signers().Contains(1) }), MsgStoreSenderQuota)
+	require.Equal(t, stats.Dropped+1, ms.Stats().Dropped)
+
+	// the quota is per height
+	ms.Save(message.NewPrevote(0, height+1, value, signer(0), &committee[0], cSize))
+	require.Len(t, ms.GetPrevotes(height+1, func(*message.Prevote) bool { return true }), 1)
+}
+
+func TestMsgStoreEviction(t *testing.T) {
+	cSize := 4
+	committee, keys := GenerateCommittee(cSize)
+	signer := func(i int) message.Signer { return makeSigner(keys[committee[i].Address].consensus) }
+	value := common.Hash{0x1}
+
+	// saves a prevote and a precommit of each member at each height
+	fill := func(ms *MsgStore, from, to uint64) {
+		for h := from; h <= to; h++ {
+			for i := range committee {
+				ms.Save(message.NewPrevote(0, h, value, signer(i), &committee[i], cSize))
+				ms.Save(message.NewPrecommit(0, h, value, signer(i), &committee[i], cSize))
+			}
+		}
+	}
+	perHeight := 2 * cSize
+
+	t.Run("oldest heights are evicted first", func(t *testing.T) {
+		ms := NewMsgStore()
+		ms.SetCapacity(3 * perHeight)
+		fill(ms, 10, 12)
+		stats := ms.Stats()
+		require.Equal(t, 3*perHeight, stats.Messages)
+		require.Zero(t, stats.Evicted)
+		require.Equal(t, uint64(10), ms.FirstHeightBuffered())
+
+		fill(ms, 13, 14)
+		require.Empty(t, ms.GetPrevotes(10, func(*message.Prevote) bool { return true }))
+		require.Empty(t, ms.GetPrevotes(11, func(*message.Prevote) bool { return true }))
+		for h := uint64(12); h <= 14; h++ {
+			require.Len(t, ms.GetPrevotes(h, func(*message.Prevote) bool { return true }), cSize)
+			require.Equal(t, uint64(cSize), ms.PrevotesPowerFor(h, 0, value).Uint64())
+		}
+		stats = ms.Stats()
+		require.Equal(t, 3, stats.Heights)
+		require.Equal(t, 3*perHeight, stats.Messages)
+		require.Equal(t, uint64(2), stats.Evicted)
+		require.Positive(t, stats.Bytes)
+	})
+
+	t.Run("first height buffered moves past the evicted heights", func(t *testing.T) {
+		ms := NewMsgStore()
+		ms.SetCapacity(2 * perHeight)
+		fill(ms, 5, 7)
+		require.Equal(t, uint64(5), ms.FirstHeightBuffered())
+		require.Equal(t, uint64(5), ms.Stats().FirstHeight)
+
+		// the late messages of the evicted heights are discarded, the height is not buffered partially
+		ms.Save(message.NewPrevote(1, 5, value, signer(0), &committee[0], cSize))
+		require.Empty(t, ms.GetPrevotes(5, func(*message.Prevote) bool { return true }))
+		require.Equal(t, uint64(5), ms.FirstHeightBuffered())
+
+		// the first height buffered never moves backwards on an eviction
+		ms.DeleteOlds(6)
+		fill(ms, 8, 10)
+		require.Equal(t, uint64(8), ms.FirstHeightBuffered())
+		require.Equal(t, 2*perHeight, ms.Stats().Messages)
+	})
+
+	t.Run("the height being saved is never evicted", func(t *testing.T) {
+		ms := NewMsgStore()
+		ms.SetCapacity(cSize)
+		fill(ms, 1, 1)
+		require.Len(t, ms.GetRange(1, 1, func(message.Msg) bool { return true }), perHeight)
+		require.Zero(t, ms.Stats().Evicted)
+
+		fill(ms, 2, 2)
+		require.Empty(t, ms.GetRange(1, 1, func(message.Msg) bool { return true }))
+		require.Len(t, ms.GetRange(2, 2, func(message.Msg) bool { return true }), perHeight)
+		require.Equal(t, uint64(1), ms.FirstHeightBuffered())
+	})
+
+	t.Run("counters are reset with the mode", func(t *testing.T) {
+		ms := NewMsgStore()
+		ms.SetCapacity(perHeight)
+		fill(ms, 1, 2)
+		require.Equal(t, uint64(1), ms.Stats().Evicted)
+		ms.SetMode(DigestMode)
+		require.Equal(t, MsgStoreStats{Mode: DigestMode, Evicted: 1}, ms.Stats())
+
+		// the evicted heights are buffered again in the new mode
+		fill(ms, 1, 1)
+		require.Equal(t, perHeight, ms.Stats().Digests)
+		require.Equal(t, uint64(1), ms.FirstHeightBuffered())
+	})
+}
+
+func TestMsgStoreGetRange(t *testing.T) {
+	cSize := 4
+	committee, keys := GenerateCommittee(cSize)
+	signer := func(i int) message.Signer { return makeSigner(keys[committee[i].Address].consensus) }
+	value := common.Hash{0x1}
+
+	ms := NewMsgStore()
+	for h := uint64(10); h >= 1; h-- {
+		ms.Save(message.NewPrecommit(0, h, value, signer(1), &committee[1], cSize))
+		ms.Save(message.NewPrevote(0, h, value, signer(0), &committee[0], cSize))
+		ms.Save(message.NewPropose(0, h, -1, generateBlock(new(big.Int).SetUint64(h)), signer(2), &committee[2]))
+	}
+
+	msgs := ms.GetRange(3, 6, func(message.Msg) bool { return true })
+	require.Len(t, msgs, 4*3)
+	for i, m := range msgs {
+		require.Equal(t, uint64(3+i/3), m.H())
+		require.Equal(t, []uint8{message.ProposalCode, message.PrevoteCode, message.PrecommitCode}[i%3], m.Code())
+	}
+
+	prevotes := ms.GetRange(0, 100, func(m message.Msg) bool { return m.Code() == message.PrevoteCode })
+	require.Len(t, prevotes, 10)
+	require.Empty(t, ms.GetRange(11, 20, func(message.Msg) bool { return true }))
+	require.Empty(t, ms.GetRange(6, 3, func(message.Msg) bool { return true }))
+}
+
+// TestMsgStoreDigestModeFootprint compares the heap retained by the msg store in both modes over an
+// accountability window of heights with transaction-carrying proposals.
+func TestMsgStoreDigestModeFootprint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping footprint comparison in short mode")
+	}
+	const (
+		heights = 50
+		txs     = 50
+		cSize   = 10
+	)
+	committee, keys := GenerateCommittee(cSize)
+	txKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	txSigner := types.NewEIP155Signer(common.Big1)
+
+	fill := func(ms *MsgStore) {
+		for h := uint64(1); h <= heights; h++ {
+			transactions := make([]*types.Transaction, txs)
+			for i := range transactions {
+				tx := types.NewTransaction(uint64(i), common.Address{0x1}, common.Big1, 21000, common.Big1, make([]byte, 100))
+				transactions[i], err = types.SignTx(tx, txSigner, txKey)
+				require.NoError(t, err)
+			}
+			block := types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(h)}, transactions, nil, nil, new(trie.Trie))
+			proposer := &committee[int(h)%cSize]
+			ms.Save(message.NewPropose(0, h, -1, block, makeSigner(keys[proposer.Address].consensus), proposer))
+			for i := range committee {
+				signer := makeSigner(keys[committee[i].Address].consensus)
+				ms.Save(message.NewPrevote(0, h, block.Hash(), signer, &committee[i], cSize))
+				ms.Save(message.NewPrecommit(0, h, block.Hash(), signer, &committee[i], cSize))
+			}
+		}
+	}
+	retained := func(mode MsgStoreMode) uint64 {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		ms := NewMsgStore()
+		ms.SetMode(mode)
+		fill(ms)
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(ms)
+		if after.HeapAlloc < before.HeapAlloc {
+			return 0
+		}
+		return after.HeapAlloc - before.HeapAlloc
+	}
+
+	full, digest := retained(FullMode), retained(DigestMode)
+	t.Logf("heap retained over %d heights: full mode %d bytes, digest mode %d bytes", heights, full, digest)
+	require.Greater(t, full, 10*digest)
+}
+
+// persistentMsgStoreChain writes the canonical header whose committee signs the messages of the given height.
+func persistentMsgStoreChain(db ethdb.Database, height uint64, committee types.Committee) {
+	parent := &types.Header{Number: new(big.Int).SetUint64(height - 1), Committee: committee, MixDigest: types.BFTDigest}
+	rawdb.WriteHeader(db, parent)
+	rawdb.WriteCanonicalHash(db, parent.Hash(), height-1)
+}
+
+// heightMsgs returns the signed proposal, prevotes and precommits of every member of the committee at the height.
+func heightMsgs(height uint64, committee types.Committee, keys AddressKeyMap) []message.Msg {
+	cSize := len(committee)
+	proposer := committee[0]
+	proposal := generateBlockProposal(0, new(big.Int).SetUint64(height), -1, false, makeSigner(keys[proposer.Address].consensus), &proposer)
+	msgs := []message.Msg{proposal}
+	for _, member := range committee {
+		m := member
+		msgs = append(msgs, message.NewPrevote(0, height, proposal.Value(), makeSigner(keys[m.Address].consensus), &m, cSize))
+	}
+	for _, member := range committee {
+		m := member
+		msgs = append(msgs, message.NewPrecommit(0, height, proposal.Value(), makeSigner(keys[m.Address].consensus), &m, cSize))
+	}
+	return msgs
+}
+
+func TestMsgStorePersistence(t *testing.T) {
+	const height = uint64(10)
+	committee, keys := GenerateCommittee(4)
+	msgs := heightMsgs(height, committee, keys)
+	proposal := msgs[0].(*message.Propose)
+	all := func(message.Msg) bool { return true }
+
+	t.Run("messages are restored after a restart", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		persistentMsgStoreChain(db, height, committee)
+		ms := NewPersistentMsgStore(db)
+		for _, m := range msgs {
+			ms.Save(m)
+		}
+		require.Len(t, rawdb.ReadMsgStoreMessages(db, height), len(msgs))
+
+		restarted := NewPersistentMsgStore(db)
+		require.Equal(t, 0, restarted.Stats().Heights)
+		proposals := restarted.GetProposals(height, func(*message.Propose) bool { return true })
+		require.Len(t, proposals, 1)
+		require.Equal(t, proposal.Hash(), proposals[0].Hash())
+		require.Equal(t, proposal.SignerIndex(), proposals[0].SignerIndex())
+		require.Len(t, restarted.GetPrevotes(height, func(m *message.Prevote) bool { return m.Value() == proposal.Value() }), len(committee))
+		require.Len(t, restarted.GetPrecommits(height, func(*message.Precommit) bool { return true }), len(committee))
+		require.Equal(t, big.NewInt(int64(len(committee))), restarted.PrevotesPowerFor(height, 0, proposal.Value()))
+		require.Len(t, restarted.SearchQuorum(height, 0, NilValue, big.NewInt(3)), len(committee))
+
+		stats := restarted.Stats()
+		require.Equal(t, len(msgs), stats.Messages)
+		require.Equal(t, 1, stats.Heights)
+		// the restored heights are not buffered since the start, the rules are not applied to them
+		require.Zero(t, restarted.FirstHeightBuffered())
+	})
+
+	t.Run("messages of a height are merged with the restored ones", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		persistentMsgStoreChain(db, height, committee)
+		ms := NewPersistentMsgStore(db)
+		for _, m := range msgs[:len(msgs)-1] {
+			ms.Save(m)
+		}
+
+		restarted := NewPersistentMsgStore(db)
+		restarted.Save(msgs[len(msgs)-1])
+		require.Equal(t, height, restarted.FirstHeightBuffered())
+		require.Len(t, restarted.GetRange(height, height, all), len(msgs))
+		require.Len(t, rawdb.ReadMsgStoreMessages(db, height), len(msgs))
+	})
+
+	t.Run("range queries restore the heights", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		ms := NewPersistentMsgStore(db)
+		for h := height; h < height+3; h++ {
+			persistentMsgStoreChain(db, h, committee)
+			for _, m := range heightMsgs(h, committee, keys) {
+				ms.Save(m)
+			}
+		}
+
+		restarted := NewPersistentMsgStore(db)
+		result := restarted.GetRange(height+1, height+5, all)
+		require.Len(t, result, 2*len(msgs))
+		require.Equal(t, height+1, result[0].H())
+		require.Equal(t, height+2, result[len(result)-1].H())
+		require.Equal(t, 2, restarted.Stats().Heights)
+	})
+
+	t.Run("deleted heights are removed from the database", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		ms := NewPersistentMsgStore(db)
+		for h := height; h < height+3; h++ {
+			persistentMsgStoreChain(db, h, committee)
+			for _, m := range heightMsgs(h, committee, keys) {
+				ms.Save(m)
+			}
+		}
+		ms.DeleteOlds(height + 1)
+		require.Equal(t, []uint64{height + 2}, rawdb.ReadMsgStoreHeights(db, 0, math.MaxUint64))
+
+		// a restarted store deletes the heights it did not restore as well
+		restarted := NewPersistentMsgStore(db)
+		restarted.DeleteOlds(height + 2)
+		require.Empty(t, rawdb.ReadMsgStoreHeights(db, 0, math.MaxUint64))
+		require.Empty(t, restarted.GetRange(0, math.MaxUint64, all))
+	})
+
+	t.Run("a mode change drops the persisted messages", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		persistentMsgStoreChain(db, height, committee)
+		ms := NewPersistentMsgStore(db)
+		for _, m := range msgs {
+			ms.Save(m)
+		}
+		ms.SetMode(DigestMode)
+		require.Empty(t, rawdb.ReadMsgStoreMessages(db, height))
+	})
+
+	t.Run("messages without a committee are not restored", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		ms := NewPersistentMsgStore(db)
+		for _, m := range msgs {
+			ms.Save(m)
+		}
+		require.Empty(t, NewPersistentMsgStore(db).GetRange(height, height, all))
+	})
+
+	t.Run("corrupted messages are discarded", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		persistentMsgStoreChain(db, height, committee)
+		ms := NewPersistentMsgStore(db)
+		for _, m := range msgs {
+			ms.Save(m)
+		}
+		// a prevote persisted with the metadata of another round
+		prevote := msgs[1]
+		data, err := rlp.EncodeToBytes(&persistedMsg{Code: prevote.Code(), Round: 1, Value: prevote.Value(), Payload: prevote.Payload()})
+		require.NoError(t, err)
+		rawdb.WriteMsgStoreMessage(db, height, msgSigner(prevote), prevote.Code(), prevote.Hash(), data)
+
+		restored := NewPersistentMsgStore(db).GetRange(height, height, all)
+		require.Len(t, restored, len(msgs)-1)
+		for _, m := range restored {
+			require.NotEqual(t, prevote.Hash(), m.Hash())
+		}
+	})
+}
+
+// countingDatabase counts the writes to the database.
+type countingDatabase struct {
+	ethdb.Database
+	writes, bytes int
+}
+
+func (db *countingDatabase) Put(key []byte, value []byte) error {
+	db.writes++
+	db.bytes += len(key) + len(value)
+	return db.Database.Put(key, value)
+}
+
+// BenchmarkMsgStorePersistence saves the proposal, prevotes and precommits of a 30 validators committee for a
+// height, and reports the bytes written to the database per block against the size of the messages.
+func BenchmarkMsgStorePersistence(b *testing.B) {
+	const height = uint64(10)
+	committee, keys := GenerateCommittee(30)
+	msgs := heightMsgs(height, committee, keys)
+	payload := 0
+	for _, m := range msgs {
+		payload += len(m.Payload())
+	}
+
+	b.Run("memory", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ms := NewMsgStore()
+			for _, m := range msgs {
+				ms.Save(m)
+			}
+		}
+	})
+	b.Run("persistent", func(b *testing.B) {
+		db := &countingDatabase{Database: rawdb.NewMemoryDatabase()}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ms := NewPersistentMsgStore(db)
+			for _, m := range msgs {
+				ms.Save(m)
+			}
+		}
+		b.ReportMetric(float64(db.writes)/float64(b.N), "writes/block")
+		b.ReportMetric(float64(db.bytes)/float64(b.N), "dbbytes/block")
+		b.ReportMetric(float64(payload), "msgbytes/block")
+		b.ReportMetric(float64(db.bytes)/float64(b.N)/float64(payload), "amplification")
+	})
 }
@@ -0,0 +1,221 @@
+// Package msgtrace samples the lifecycle of consensus messages. A sampled message carries a trace through
+// the processing pipeline which records the time and the outcome of each stage, from its reception to its
+// gossip. The completed traces are kept in a bounded ring buffer for debugging purposes.
+package msgtrace
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/autonity/autonity/common"
+)
+
+// DefaultCapacity is the number of completed traces kept by a tracer.
+const DefaultCapacity = 1024
+
+// Stage is a processing stage of a consensus message.
+type Stage uint8
+
+const (
+	Receive Stage = iota // the message is received from a peer
+	Decode               // the message is decoded, or buffered until its height
+	Verify               // the message is pre-validated and its signature verified by the aggregator
+	Apply                // the message is handled by the upon rules of core
+	Gossip               // the message is gossiped to the committee
+)
+
+var stageToString = [...]string{
+	Receive: "receive",
+	Decode:  "decode",
+	Verify:  "verify",
+	Apply:   "apply",
+	Gossip:  "gossip",
+}
+
+func (s Stage) String() string {
+	if int(s) >= len(stageToString) {
+		return fmt.Sprintf("unknown stage %d", s)
+	}
+	return stageToString[s]
+}
+
+func (s Stage) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// StageRecord is the outcome of a stage of a traced message.
+type StageRecord struct {
+	Stage   Stage     `json:"stage"`
+	Time    time.Time `json:"time"`
+	Outcome string    `json:"outcome"`
+}
+
+// Trace is the lifecycle of a sampled consensus message.
+type Trace struct {
+	Hash     common.Hash    `json:"hash"`
+	Sender   common.Address `json:"sender"`
+	Code     uint8          `json:"code"`
+	Height   uint64         `json:"height"`
+	Round    int64          `json:"round"`
+	Stages   []StageRecord  `json:"stages"`
+	Duration time.Duration  `json:"duration"` // from the reception of the message to the last stage
+
+	mu     sync.Mutex
+	tracer *Tracer
+	done   bool
+}
+
+// SetMessage records the fields of the decoded message.
+func (t *Trace) SetMessage(code uint8, height uint64, round int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Code, t.Height, t.Round = code, height, round
+}
+
+// Record records the outcome of a stage.
+func (t *Trace) Record(stage Stage, outcome string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(stage, outcome)
+}
+
+// Finish records the outcome of the last stage the message goes through and completes the trace.
+// A trace is completed once, the stages recorded afterwards are ignored.
+func (t *Trace) Finish(stage Stage, outcome string) {
+	t.mu.Lock()
+	if t.done {
+		t.mu.Unlock()
+		return
+	}
+	t.record(stage, outcome)
+	t.done = true
+	t.Duration = t.Stages[len(t.Stages)-1].Time.Sub(t.Stages[0].Time)
+	t.mu.Unlock()
+	t.tracer.add(t)
+}
+
+func (t *Trace) record(stage Stage, outcome string) {
+	if t.done {
+		return
+	}
+	t.Stages = append(t.Stages, StageRecord{Stage: stage, Time: time.Now(), Outcome: outcome})
+}
+
+func (t *Trace) copy() *Trace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &Trace{
+		Hash:     t.Hash,
+		Sender:   t.Sender,
+		Code:     t.Code,
+		Height:   t.Height,
+		Round:    t.Round,
+		Stages:   append([]StageRecord(nil), t.Stages...),
+		Duration: t.Duration,
+	}
+}
+
+// Traces are the traces carried by a consensus message. A message has no trace unless it is sampled, or
+// several traces if it aggregates sampled votes. The methods are no-ops on the traces of unsampled messages.
+type Traces []*Trace
+
+// Of returns the traces of a message, or nil if the message is not sampled.
+func Of(t *Trace) Traces {
+	if t == nil {
+		return nil
+	}
+	return Traces{t}
+}
+
+// SetMessage records the fields of the decoded message in all the traces.
+func (ts Traces) SetMessage(code uint8, height uint64, round int64) {
+	for _, t := range ts {
+		t.SetMessage(code, height, round)
+	}
+}
+
+// Record records the outcome of a stage in all the traces.
+func (ts Traces) Record(stage Stage, outcome string) {
+	for _, t := range ts {
+		t.Record(stage, outcome)
+	}
+}
+
+// Finish completes all the traces.
+func (ts Traces) Finish(stage Stage, outcome string) {
+	for _, t := range ts {
+		t.Finish(stage, outcome)
+	}
+}
+
+// Tracer samples the consensus messages whose hash falls in the configured sample, and keeps their
+// completed traces in a ring buffer.
+type Tracer struct {
+	threshold atomic.Uint64 // messages whose hash prefix is below the threshold are sampled
+
+	mu    sync.Mutex
+	ring  []*Trace
+	next  int // index of the next trace in the ring
+	count int // number of traces in the ring
+}
+
+// New returns a tracer keeping the given number of completed traces, with sampling disabled.
+func New(capacity int) *Tracer {
+	return &Tracer{ring: make([]*Trace, capacity)}
+}
+
+// SetSampling sets the sampling rate to one in n messages. Sampling is disabled if n is zero.
+func (t *Tracer) SetSampling(n uint64) {
+	switch n {
+	case 0:
+		t.threshold.Store(0)
+	case 1:
+		t.threshold.Store(math.MaxUint64)
+	default:
+		t.threshold.Store(math.MaxUint64 / n)
+	}
+}
+
+// Sample returns the trace of the message received at the given time, or nil if the message is not sampled.
+// Sampling an unsampled message costs a single comparison of its hash.
+func (t *Tracer) Sample(hash common.Hash, sender common.Address, received time.Time) *Trace {
+	if binary.BigEndian.Uint64(hash[:8]) >= t.threshold.Load() {
+		return nil
+	}
+	if received.IsZero() {
+		received = time.Now()
+	}
+	return &Trace{
+		Hash:   hash,
+		Sender: sender,
+		Stages: []StageRecord{{Stage: Receive, Time: received, Outcome: "received"}},
+		tracer: t,
+	}
+}
+
+func (t *Tracer) add(trace *Trace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.ring) == 0 {
+		return
+	}
+	t.ring[t.next] = trace
+	t.next = (t.next + 1) % len(t.ring)
+	t.count = min(t.count+1, len(t.ring))
+}
+
+// Traces returns up to count of the most recently completed traces, the most recent first.
+func (t *Tracer) Traces(count int) []*Trace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	count = min(max(count, 0), t.count)
+	traces := make([]*Trace, 0, count)
+	for i := 1; i <= count; i++ {
+		traces = append(traces, t.ring[(t.next-i+len(t.ring))%len(t.ring)].copy())
+	}
+	return traces
+}
@@ -0,0 +1,150 @@
+package msgtrace
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+)
+
+func randomHash(r *rand.Rand) common.Hash {
+	var hash common.Hash
+	r.Read(hash[:])
+	return hash
+}
+
+func TestSampling(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tracer := New(DefaultCapacity)
+
+	t.Run("sampling is disabled by default", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			require.Nil(t, tracer.Sample(randomHash(r), common.Address{}, time.Time{}))
+		}
+	})
+
+	t.Run("one in n messages is sampled", func(t *testing.T) {
+		tracer.SetSampling(100)
+		sampled := 0
+		for i := 0; i < 100000; i++ {
+			if tracer.Sample(randomHash(r), common.Address{}, time.Time{}) != nil {
+				sampled++
+			}
+		}
+		require.InDelta(t, 1000, sampled, 150)
+	})
+
+	t.Run("the sample depends on the message hash only", func(t *testing.T) {
+		tracer.SetSampling(2)
+		for i := 0; i < 1000; i++ {
+			hash := randomHash(r)
+			sampled := tracer.Sample(hash, common.Address{}, time.Time{}) != nil
+			require.Equal(t, sampled, tracer.Sample(hash, common.Address{1}, time.Now()) != nil)
+		}
+	})
+
+	t.Run("all messages are sampled", func(t *testing.T) {
+		tracer.SetSampling(1)
+		for i := 0; i < 1000; i++ {
+			require.NotNil(t, tracer.Sample(randomHash(r), common.Address{}, time.Time{}))
+		}
+		tracer.SetSampling(0)
+		require.Nil(t, tracer.Sample(randomHash(r), common.Address{}, time.Time{}))
+	})
+}
+
+func TestTrace(t *testing.T) {
+	tracer := New(DefaultCapacity)
+	tracer.SetSampling(1)
+
+	t.Run("the stages are recorded until the trace is completed", func(t *testing.T) {
+		received := time.Now().Add(-time.Second)
+		trace := tracer.Sample(common.Hash{1}, common.Address{2}, received)
+		traces := Of(trace)
+		traces.SetMessage(1, 10, 2)
+		traces.Record(Decode, "decoded")
+		traces.Record(Verify, "proposal verified")
+		traces.Record(Apply, "handled")
+		traces.Finish(Gossip, "gossiped")
+		traces.Record(Apply, "handled")
+		traces.Finish(Gossip, "gossiped")
+
+		completed := tracer.Traces(10)
+		require.Len(t, completed, 1)
+		require.Equal(t, common.Hash{1}, completed[0].Hash)
+		require.Equal(t, common.Address{2}, completed[0].Sender)
+		require.Equal(t, uint64(10), completed[0].Height)
+		require.Equal(t, int64(2), completed[0].Round)
+		stages := completed[0].Stages
+		require.Len(t, stages, 5)
+		for i, stage := range []Stage{Receive, Decode, Verify, Apply, Gossip} {
+			require.Equal(t, stage, stages[i].Stage)
+		}
+		require.Equal(t, received, stages[0].Time)
+		require.Equal(t, stages[4].Time.Sub(received), completed[0].Duration)
+		require.GreaterOrEqual(t, completed[0].Duration, time.Second)
+	})
+
+	t.Run("unsampled messages have no trace", func(t *testing.T) {
+		traces := Of(nil)
+		require.Nil(t, traces)
+		traces.SetMessage(1, 10, 2)
+		traces.Record(Decode, "decoded")
+		traces.Finish(Gossip, "gossiped")
+	})
+}
+
+func TestTraces(t *testing.T) {
+	tracer := New(3)
+	tracer.SetSampling(1)
+	require.Empty(t, tracer.Traces(10))
+
+	for i := byte(1); i <= 5; i++ {
+		tracer.Sample(common.Hash{i}, common.Address{}, time.Time{}).Finish(Decode, "decoded")
+	}
+	completed := tracer.Traces(10)
+	require.Len(t, completed, 3)
+	for i, trace := range completed {
+		require.Equal(t, common.Hash{byte(5 - i)}, trace.Hash)
+	}
+	require.Len(t, tracer.Traces(2), 2)
+	require.Empty(t, tracer.Traces(-1))
+
+	// the returned traces are copies
+	completed[0].Stages[0].Outcome = "modified"
+	require.Equal(t, "received", tracer.Traces(1)[0].Stages[0].Outcome)
+}
+
+// BenchmarkTracer measures the tracing overhead of the processing of a consensus message.
+func BenchmarkTracer(b *testing.B) {
+	for _, bc := range []struct {
+		name     string
+		sampling uint64
+	}{
+		{"disabled", 0},
+		{"1%", 100},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			r := rand.New(rand.NewSource(1))
+			hashes := make([]common.Hash, 1024)
+			for i := range hashes {
+				hashes[i] = randomHash(r)
+			}
+			tracer := New(DefaultCapacity)
+			tracer.SetSampling(bc.sampling)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				traces := Of(tracer.Sample(hashes[i%len(hashes)], common.Address{}, time.Time{}))
+				traces.SetMessage(1, 10, 0)
+				traces.Record(Decode, "decoded")
+				traces.Record(Verify, "signature verified, aggregated")
+				traces.Record(Apply, "handled")
+				traces.Finish(Gossip, "gossiped")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/autonity/autonity/metrics"
+)
+
+// pacingDelayStep is the delay of the start of the next height per block of import backlog above the
+// pacing threshold.
+const pacingDelayStep = 100 * time.Millisecond
+
+var (
+	PacingBacklogGauge = metrics.NewRegisteredGauge("tendermint/pacing/backlog", nil) // import backlog when a height is committed
+	PacingDelayTimer   = metrics.NewRegisteredTimer("tendermint/pacing/delay", nil)   // delays of the start of the next height
+)
+
+// SetImportPacing sets the import backlog, in blocks, above which the start of the next height is delayed
+// for the import to catch up. Zero disables the pacing.
+func (c *Core) SetImportPacing(threshold uint64) {
+	c.pacingThreshold.Store(threshold)
+}
+
+// startNextHeight starts the first round of the height following the committed block. While the import
+// of the blocks handed to the chain lags behind the commits, the round starts after a delay growing with
+// the backlog, so that the node does not run further ahead of its own chain.
+func (c *Core) startNextHeight(ctx context.Context) {
+	if c.pacing != nil {
+		// the round starts once the pending delay elapses
+		return
+	}
+	if threshold := c.pacingThreshold.Load(); threshold > 0 {
+		backlog := c.backend.ImportBacklog()
+		PacingBacklogGauge.Update(int64(backlog))
+		if delay := pacingDelay(uint64(backlog), threshold, c.timeoutPropose(0)); delay > 0 {
+			c.logger.Debug("Delaying the next height for the import to catch up", "backlog", backlog, "delay", delay)
+			PacingDelayTimer.Update(delay)
+			c.pacing = time.After(delay)
+			return
+		}
+	}
+	c.StartRound(ctx, 0)
+}
+
+// pacingDelay returns the delay of the start of the next height for the import backlog, pacingDelayStep
+// per block above the threshold. It is capped at half the propose timeout of the first round, for the
+// proposal and votes of the node to still reach the other validators before they time out.
+func pacingDelay(backlog, threshold uint64, proposeTimeout time.Duration) time.Duration {
+	if threshold == 0 || backlog <= threshold {
+		return 0
+	}
+	limit := proposeTimeout / 2
+	if excess := backlog - threshold; excess < uint64(limit/pacingDelayStep) {
+		return time.Duration(excess) * pacingDelayStep
+	}
+	return limit
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/committee"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func TestPacingDelay(t *testing.T) {
+	proposeTimeout := 3 * time.Second
+	tests := []struct {
+		name      string
+		backlog   uint64
+		threshold uint64
+		expected  time.Duration
+	}{
+		{name: "disabled", backlog: 100, threshold: 0, expected: 0},
+		{name: "no backlog", backlog: 0, threshold: 4, expected: 0},
+		{name: "at the threshold", backlog: 4, threshold: 4, expected: 0},
+		{name: "above the threshold", backlog: 5, threshold: 4, expected: pacingDelayStep},
+		{name: "growing with the backlog", backlog: 10, threshold: 4, expected: 6 * pacingDelayStep},
+		{name: "capped", backlog: 1000, threshold: 4, expected: proposeTimeout / 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, pacingDelay(test.backlog, test.threshold, proposeTimeout))
+		})
+	}
+}
+
+// simulateImportBacklog commits heights at the pace of consensus against an importer throttled to a slower
+// pace, and returns the largest import backlog found at a commit.
+func simulateImportBacklog(threshold uint64, heights int) uint64 {
+	const (
+		heightTime     = time.Second             // duration of a height without pacing
+		importTime     = 1500 * time.Millisecond // throttled import time of a block
+		proposeTimeout = 3 * time.Second
+	)
+	var (
+		now       time.Duration
+		imported  []time.Duration // completion times of the imports, in order
+		importEnd time.Duration
+		largest   uint64
+	)
+	for h := 0; h < heights; h++ {
+		now += heightTime
+		// the committed block is imported once the previous ones are
+		importEnd = max(importEnd, now) + importTime
+		imported = append(imported, importEnd)
+		for len(imported) > 0 && imported[0] <= now {
+			imported = imported[1:]
+		}
+		backlog := uint64(len(imported))
+		largest = max(largest, backlog)
+		now += pacingDelay(backlog, threshold, proposeTimeout)
+	}
+	return largest
+}
+
+func TestPacingBoundsImportBacklog(t *testing.T) {
+	const heights = 1000
+	// without pacing the backlog grows with the heights committed
+	require.Greater(t, simulateImportBacklog(0, heights), uint64(heights/4))
+	// with pacing it settles once the delay covers the import lag
+	threshold := uint64(4)
+	paced := simulateImportBacklog(threshold, heights)
+	require.LessOrEqual(t, paced, threshold+uint64(time.Second/pacingDelayStep))
+	require.Equal(t, paced, simulateImportBacklog(threshold, 10*heights))
+}
+
+func TestHandleCommitPacing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer waitForExpects(ctrl)
+
+	logger := log.New("backend", "test", "id", 0)
+	testCommittee, _ := GenerateCommittee(3)
+	committeeSet, err := committee.NewRoundRobinSet(testCommittee, testCommittee[0].Address)
+	require.NoError(t, err)
+
+	backlog := 10
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().HeadBlock().AnyTimes().Return(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3)}))
+	backendMock.EXPECT().ImportBacklog().AnyTimes().DoAndReturn(func() int { return backlog })
+	backendMock.EXPECT().Post(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().ProcessFutureMsgs(uint64(4)).MaxTimes(1)
+
+	c := &Core{
+		address:          testCommittee[0].Address,
+		backend:          backendMock,
+		round:            2,
+		height:           big.NewInt(3),
+		committedHeight:  2,
+		messages:         message.NewMap(),
+		logger:           logger,
+		proposeTimeout:   NewTimeout(Propose, logger),
+		prevoteTimeout:   NewTimeout(Prevote, logger),
+		precommitTimeout: NewTimeout(Precommit, logger),
+		committee:        committeeSet,
+	}
+	c.SetDefaultHandlers()
+	c.SetImportPacing(4)
+	defer func() {
+		_ = c.proposeTimeout.StopTimer()
+	}()
+
+	// the backlog is above the threshold, the next height starts after a delay
+	c.precommiter.HandleCommit(context.Background())
+	require.Equal(t, big.NewInt(3), c.Height())
+	require.NotNil(t, c.pacing)
+	// the duplicated commit events do not start it either
+	pending := c.pacing
+	c.precommiter.HandleCommit(context.Background())
+	require.Equal(t, pending, c.pacing)
+
+	select {
+	case <-c.pacing:
+		c.StartRound(context.Background(), 0)
+	case <-time.After(time.Second + c.timeoutPropose(0)/2):
+		t.Fatal("the delayed start of the next height did not fire")
+	}
+	require.Equal(t, big.NewInt(4), c.Height())
+	require.Equal(t, int64(0), c.Round())
+	require.Nil(t, c.pacing)
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+)
+
+type pendingProposalRequestEvent struct {
+	stateChan chan interfaces.PendingProposalState
+}
+
+type retriggerProposalEvent struct {
+	errChan chan error
+}
+
+// PendingProposalState reports the candidate block pipeline feeding the proposals of the local node.
+func (c *Core) PendingProposalState() interfaces.PendingProposalState {
+	var e = pendingProposalRequestEvent{
+		stateChan: make(chan interfaces.PendingProposalState),
+	}
+	go c.SendEvent(e)
+	return <-e.stateChan
+}
+
+// RetriggerProposal checks that the local node can still propose in the current round, and proposes
+// the valid value if it has one. Otherwise, the proposal is sent when the next candidate block for
+// the current height is received from the miner. It is refused once a proposal was signed for the
+// round, so that it can never be used to equivocate.
+func (c *Core) RetriggerProposal() error {
+	var e = retriggerProposalEvent{
+		errChan: make(chan error),
+	}
+	go c.SendEvent(e)
+	return <-e.errChan
+}
+
+// The pending proposal requests are handled in the main loop, as the state dump.
+func (c *Core) handlePendingProposalState(e pendingProposalRequestEvent) {
+	e.stateChan <- c.pendingProposalState()
+}
+
+func (c *Core) pendingProposalState() interfaces.PendingProposalState {
+	state := interfaces.PendingProposalState{
+		Height:       c.Height(),
+		Round:        c.Round(),
+		Step:         uint64(c.step),
+		IsProposer:   c.IsProposer(),
+		SentProposal: c.sentProposal,
+	}
+	if candidate, ok := c.pendingCandidateBlocks[c.Height().Uint64()]; ok {
+		parent := candidate.ParentHash()
+		state.HasCandidate = true
+		state.CandidateNumber = candidate.Number()
+		state.CandidateParent = &parent
+		state.CandidateTxs = len(candidate.Transactions())
+		if candidate.Hash() == c.lastCandidateHash {
+			state.CandidateAge = time.Since(c.lastCandidateAt).Seconds()
+		}
+	}
+	return state
+}
+
+func (c *Core) handleRetriggerProposal(ctx context.Context, e retriggerProposalEvent) {
+	e.errChan <- c.retriggerProposal(ctx)
+}
+
+func (c *Core) retriggerProposal(ctx context.Context) error {
+	if !c.IsProposer() {
+		return constants.ErrNotProposer
+	}
+	if c.sentProposal {
+		return constants.ErrProposalAlreadySent
+	}
+	if proposal := c.curRoundMessages.Proposal(); proposal != nil && proposal.Signer() == c.address {
+		return constants.ErrProposalAlreadySent
+	}
+	if c.step != Propose {
+		return constants.ErrProposeStepOver
+	}
+	c.logger.Info("Retriggering the proposal", "height", c.Height(), "round", c.Round())
+	// a valid value must be proposed again rather than a new block
+	if c.validValue != nil {
+		c.proposer.SendProposal(ctx, c.validValue)
+	}
+	return nil
+}
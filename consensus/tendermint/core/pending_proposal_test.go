@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+)
+
+func newPendingProposalCore(t *testing.T, backend interfaces.Backend) (*Core, types.CommitteeMember, AddressKeyMap) {
+	committeeSet, keys := NewTestCommitteeSetWithKeys(1)
+	proposer, _ := committeeSet.GetByIndex(0)
+	messages := message.NewMap()
+	c := &Core{
+		pendingCandidateBlocks: make(map[uint64]*types.Block),
+		address:                proposer.Address,
+		backend:                backend,
+		messages:               messages,
+		curRoundMessages:       messages.GetOrCreate(0),
+		logger:                 log.New("backend", "test", "id", 0),
+		height:                 big.NewInt(1),
+		step:                   Propose,
+		validRound:             -1,
+		committee:              committeeSet,
+		lastHeader:             &types.Header{Committee: committeeSet.Committee()},
+	}
+	c.SetDefaultHandlers()
+	return c, proposer, keys
+}
+
+func TestPendingProposalState(t *testing.T) {
+	t.Run("no candidate block for the current height", func(t *testing.T) {
+		c, _, _ := newPendingProposalCore(t, nil)
+		c.pendingCandidateBlocks[0] = generateBlock(big.NewInt(0))
+
+		e := pendingProposalRequestEvent{stateChan: make(chan interfaces.PendingProposalState, 1)}
+		c.handlePendingProposalState(e)
+		state := <-e.stateChan
+
+		require.Equal(t, big.NewInt(1), state.Height)
+		require.Equal(t, int64(0), state.Round)
+		require.Equal(t, uint64(Propose), state.Step)
+		require.True(t, state.IsProposer)
+		require.False(t, state.SentProposal)
+		require.False(t, state.HasCandidate)
+		require.Nil(t, state.CandidateNumber)
+		require.Nil(t, state.CandidateParent)
+	})
+
+	t.Run("candidate block received from the miner is reported", func(t *testing.T) {
+		c, _, _ := newPendingProposalCore(t, nil)
+		c.address = common.HexToAddress("0x01")
+		c.sentProposal = true
+		candidate := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: common.HexToHash("0xaa")})
+		c.pendingCandidateBlocks[1] = candidate
+		c.lastCandidateHash = candidate.Hash()
+		c.lastCandidateAt = time.Now().Add(-2 * time.Second)
+
+		e := pendingProposalRequestEvent{stateChan: make(chan interfaces.PendingProposalState, 1)}
+		c.handlePendingProposalState(e)
+		state := <-e.stateChan
+
+		require.False(t, state.IsProposer)
+		require.True(t, state.SentProposal)
+		require.True(t, state.HasCandidate)
+		require.Equal(t, big.NewInt(1), state.CandidateNumber)
+		require.Equal(t, common.HexToHash("0xaa"), *state.CandidateParent)
+		require.Equal(t, 0, state.CandidateTxs)
+		require.GreaterOrEqual(t, state.CandidateAge, float64(2))
+	})
+}
+
+func TestRetriggerProposal(t *testing.T) {
+	t.Run("not the proposer of the round", func(t *testing.T) {
+		c, _, _ := newPendingProposalCore(t, nil)
+		c.address = common.HexToAddress("0x01")
+		require.ErrorIs(t, c.retriggerProposal(context.Background()), constants.ErrNotProposer)
+	})
+
+	t.Run("proposal already sent for the round", func(t *testing.T) {
+		c, _, _ := newPendingProposalCore(t, nil)
+		c.sentProposal = true
+		require.ErrorIs(t, c.retriggerProposal(context.Background()), constants.ErrProposalAlreadySent)
+	})
+
+	t.Run("own proposal already received for the round", func(t *testing.T) {
+		c, proposer, keys := newPendingProposalCore(t, nil)
+		proposal := generateBlockProposal(0, big.NewInt(1), -1, false, makeSigner(keys[proposer.Address].consensus), &proposer)
+		c.curRoundMessages.SetProposal(proposal, true)
+		require.ErrorIs(t, c.retriggerProposal(context.Background()), constants.ErrProposalAlreadySent)
+	})
+
+	t.Run("propose step is over", func(t *testing.T) {
+		c, _, _ := newPendingProposalCore(t, nil)
+		c.step = Prevote
+		require.ErrorIs(t, c.retriggerProposal(context.Background()), constants.ErrProposeStepOver)
+	})
+
+	t.Run("no valid value, the next candidate block is awaited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		c, _, _ := newPendingProposalCore(t, interfaces.NewMockBackend(ctrl))
+		require.NoError(t, c.retriggerProposal(context.Background()))
+		require.False(t, c.sentProposal)
+	})
+
+	t.Run("valid value is proposed again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		backendMock := interfaces.NewMockBackend(ctrl)
+		c, proposer, keys := newPendingProposalCore(t, backendMock)
+		c.round = 1
+		c.curRoundMessages = c.messages.GetOrCreate(1)
+		c.validValue = generateBlock(big.NewInt(1))
+		c.validRound = 0
+
+		proposal := message.NewPropose(1, 1, 0, c.validValue, makeSigner(keys[proposer.Address].consensus), &proposer)
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[proposer.Address].consensus))
+		backendMock.EXPECT().SetProposedBlockHash(c.validValue.Hash())
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), proposal)
+
+		require.NoError(t, c.retriggerProposal(context.Background()))
+		require.True(t, c.sentProposal)
+	})
+}
@@ -32,10 +32,12 @@ func (c *Precommiter) SendPrecommit(ctx context.Context, isNil bool) {
 	} else {
 		c.logger.Info("Precommiting on nil", "round", c.Round(), "height", c.Height().Uint64())
 	}
-	self := c.LastHeader().CommitteeMember(c.address)
-	precommit := message.NewPrecommit(c.Round(), c.Height().Uint64(), value, c.backend.Sign, self, len(c.CommitteeSet().Committee()))
+	lastHeader := c.LastHeader()
+	self := lastHeader.CommitteeMember(c.address)
+	precommit := message.NewPrecommit(c.Round(), c.Height().Uint64(), value, c.backend.Sign, self, len(lastHeader.Committee))
 	c.LogPrecommitMessageEvent("Precommit sent", precommit)
 	c.sentPrecommit = true
+	c.journalSent(precommit)
 	c.Broadcaster().Broadcast(precommit)
 	if metrics.Enabled {
 		PrecommitSentBlockTSDeltaBg.Add(time.Since(c.currBlockTimeStamp).Nanoseconds())
@@ -80,14 +82,19 @@ func (c *Precommiter) HandlePrecommit(ctx context.Context, precommit *message.Pr
 
 func (c *Precommiter) HandleCommit(ctx context.Context) {
 	c.logger.Debug("Received a final committed proposal", "step", c.step)
-	lastBlock := c.backend.HeadBlock()
-	height := new(big.Int).Add(lastBlock.Number(), common.Big1)
-	if height.Cmp(c.Height()) == 0 {
-		c.logger.Debug("Discarding event as Core is at the same height", "height", c.Height())
-	} else {
-		c.logger.Debug("New chain head ahead of consensus Core height", "height", c.Height(), "block_height", height)
-		c.StartRound(ctx, 0)
+	// the commit events do not carry the committed block, duplicated or delayed events are
+	// told apart by the chain head they find.
+	committed := c.backend.HeadBlock().NumberU64()
+	expected := c.committedHeight + 1
+	if committed < expected {
+		c.logger.Debug("Discarding commit event of an already processed height", "expected", expected, "committed", committed)
+		return
+	}
+	if committed > expected {
+		// the chain moved by more than one block, e.g. blocks were imported by the sync
+		c.logger.Debug("New chain head ahead of consensus Core height", "expected", expected, "committed", committed)
 	}
+	c.startNextHeight(ctx)
 }
 
 func (c *Precommiter) LogPrecommitMessageEvent(message string, precommit *message.Precommit) {
@@ -15,6 +15,7 @@ import (
 	"github.com/autonity/autonity/consensus/tendermint/core/committee"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/log"
 )
@@ -25,7 +26,7 @@ func TestSendPrecommit(t *testing.T) {
 		defer ctrl.Finish()
 
 		backendMock := interfaces.NewMockBackend(ctrl)
-		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(0)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
 		messages := message.NewMap()
 		c := &Core{
@@ -65,7 +66,7 @@ func TestSendPrecommit(t *testing.T) {
 
 		preCommit := message.NewPrecommit(1, 2, curRoundMessages.ProposalHash(), makeSigner(keys[addr].consensus), &val, 7)
 		backendMock := interfaces.NewMockBackend(ctrl)
-		backendMock.EXPECT().Broadcast(gomock.Any(), preCommit)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), preCommit)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[addr].consensus))
 
 		c := &Core{
@@ -106,7 +107,7 @@ func TestSendPrecommit(t *testing.T) {
 
 		preCommit := message.NewPrecommit(1, 2, common.Hash{}, makeSigner(keys[addr].consensus), &val, 7)
 		backendMock := interfaces.NewMockBackend(ctrl)
-		backendMock.EXPECT().Broadcast(gomock.Any(), preCommit)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), preCommit)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[addr].consensus))
 
 		c := &Core{
@@ -247,7 +248,8 @@ func TestHandlePrecommit(t *testing.T) {
 			precommitTimeout: NewTimeout(Precommit, logger),
 		}
 		c.SetDefaultHandlers()
-		backendMock.EXPECT().Post(gomock.Any()).Times(6)
+		subscribeFeeds(t, c)
+		backendMock.EXPECT().Post(gomock.Any()).Times(5)
 
 		for _, member := range committeeSet.Committee()[1:5] {
 			m := member
@@ -263,7 +265,9 @@ func TestHandlePrecommit(t *testing.T) {
 		}
 
 		<-time.NewTimer(5 * time.Second).C
-
+		// the precommit timeout expired
+		timeout := <-c.feeds.timeoutCh
+		require.Equal(t, Precommit, timeout.Step)
 	})
 }
 
@@ -308,3 +312,81 @@ func TestHandleCommit(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestHandleCommitReplay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer waitForExpects(ctrl)
+
+	logger := log.New("backend", "test", "id", 0)
+	testCommittee, _ := GenerateCommittee(3)
+	committeeSet, err := committee.NewRoundRobinSet(testCommittee, testCommittee[0].Address)
+	require.NoError(t, err)
+
+	var (
+		head         *types.Block
+		roundChanges []events.RoundChangeEvent
+		futureMsgs   = make(chan uint64, 2)
+	)
+	headAt := func(height int64) {
+		head = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(height)})
+	}
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().HeadBlock().AnyTimes().DoAndReturn(func() *types.Block { return head })
+	backendMock.EXPECT().ProcessFutureMsgs(gomock.Any()).Times(2).Do(func(height uint64) { futureMsgs <- height })
+	backendMock.EXPECT().Post(gomock.Any()).AnyTimes().Do(func(ev any) {
+		roundChanges = append(roundChanges, ev.(events.RoundChangeEvent))
+	})
+
+	// the local node is not a committee member, it schedules the propose timeout at each height
+	c := &Core{
+		backend:          backendMock,
+		round:            2,
+		height:           big.NewInt(4),
+		committedHeight:  3,
+		messages:         message.NewMap(),
+		logger:           logger,
+		proposeTimeout:   NewTimeout(Propose, logger),
+		prevoteTimeout:   NewTimeout(Prevote, logger),
+		precommitTimeout: NewTimeout(Precommit, logger),
+		committee:        committeeSet,
+	}
+	c.SetDefaultHandlers()
+	defer func() {
+		_ = c.proposeTimeout.StopTimer()
+	}()
+
+	// the block of the current height is committed, then the commit event is duplicated
+	headAt(4)
+	c.precommiter.HandleCommit(context.Background())
+	require.Equal(t, big.NewInt(5), c.Height())
+	timerStart := c.proposeTimeout.Start
+	c.precommiter.HandleCommit(context.Background())
+
+	// a delayed commit event finding an older chain head
+	headAt(3)
+	c.precommiter.HandleCommit(context.Background())
+	require.Equal(t, big.NewInt(5), c.Height())
+	require.Equal(t, int64(0), c.Round())
+	require.Equal(t, timerStart, c.proposeTimeout.Start)
+
+	// the next height is committed, followed by its duplicate
+	headAt(5)
+	c.precommiter.HandleCommit(context.Background())
+	c.precommiter.HandleCommit(context.Background())
+	require.Equal(t, big.NewInt(6), c.Height())
+	require.Equal(t, uint64(5), c.committedHeight)
+
+	// exactly one height transition per committed height
+	require.Equal(t, []events.RoundChangeEvent{{Height: 5, Round: 0}, {Height: 6, Round: 0}}, roundChanges)
+	// the future height messages are processed in the background
+	require.ElementsMatch(t, []uint64{5, 6}, []uint64{<-futureMsgs, <-futureMsgs})
+}
+
+func TestSetHeightMovesForward(t *testing.T) {
+	c := &Core{}
+	c.setHeight(big.NewInt(4))
+	c.setHeight(big.NewInt(4))
+	c.setHeight(big.NewInt(5))
+	require.Panics(t, func() { c.setHeight(big.NewInt(3)) })
+	require.Equal(t, big.NewInt(5), c.Height())
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+)
+
+// recordPrevote records the reason of the prevote about to be sent in the current round. The records of
+// the height are journaled along with the round state, reported in the state snapshot and aggregated in
+// the timing dump.
+func (c *Core) recordPrevote(reason interfaces.PrevoteReason, err error) {
+	record := interfaces.PrevoteRecord{Round: c.Round(), Reason: reason}
+	if reason == interfaces.PrevoteInvalid {
+		record.ErrorClass = constants.ClassOf(err).String()
+	}
+	c.prevoteRecords = append(c.prevoteRecords, record)
+}
+
+// lockedPrevoteReason returns the reason of the prevote for the value proposed, nil if the node is locked on
+// another value.
+func lockedPrevoteReason(isNil bool) interfaces.PrevoteReason {
+	if isNil {
+		return interfaces.PrevoteLocked
+	}
+	return interfaces.PrevoteValue
+}
+
+// timeoutPrevoteReason returns the reason of the prevote nil sent on the propose timeout: a proposal of the
+// round could be received but not prevoted, e.g. an old proposal whose valid round lacks a prevote quorum.
+func (c *Core) timeoutPrevoteReason() interfaces.PrevoteReason {
+	if c.curRoundMessages.Proposal() != nil {
+		return interfaces.PrevoteTimeout
+	}
+	return interfaces.PrevoteNoProposal
+}
+
+// prevoteReasonCounts returns the number of prevotes of each reason sent at the current height, and the error
+// class of the last invalid proposal, if any.
+func (c *Core) prevoteReasonCounts() ([interfaces.NumPrevoteReasons]uint64, string) {
+	var counts [interfaces.NumPrevoteReasons]uint64
+	var class string
+	for _, record := range c.prevoteRecords {
+		if record.Reason < interfaces.NumPrevoteReasons {
+			counts[record.Reason]++
+		}
+		if record.Reason == interfaces.PrevoteInvalid {
+			class = record.ErrorClass
+		}
+	}
+	return counts, class
+}
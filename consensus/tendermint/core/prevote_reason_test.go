@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/timingdump"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/ethdb"
+)
+
+// newPrevoteReasonCore sets up the core of the environment, journaled in db if not nil. The proposals
+// fail their verification with verifyErr.
+func newPrevoteReasonCore(t *testing.T, env *ConsensusENV, db ethdb.KeyValueStore, verifyErr error) *Core {
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().HeadBlock().Return(env.previousValue).AnyTimes()
+	backendMock.EXPECT().Post(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().ProcessFutureMsgs(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(env.clientSigner).AnyTimes()
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(time.Duration(1), verifyErr).AnyTimes()
+	env.setupCore(backendMock, env.clientAddress)
+	if db != nil {
+		env.core.Journal().SetDatabase(db)
+	}
+	t.Cleanup(env.core.stopAllTimeouts)
+	return env.core
+}
+
+func TestPrevoteReasons(t *testing.T) {
+	errInvalid := errors.New("invalid proposal")
+	timeoutPropose := func(ctx context.Context, e *ConsensusENV) {
+		e.core.handleTimeoutPropose(ctx, TimeoutEvent{RoundWhenCalled: e.curRound, HeightWhenCalled: e.curHeight, Step: Propose})
+	}
+
+	tests := []struct {
+		name      string
+		customize func(e *ConsensusENV)
+		verifyErr error
+		drive     func(ctx context.Context, e *ConsensusENV)
+		expected  interfaces.PrevoteRecord
+	}{
+		{
+			name: "proposal prevoted",
+			drive: func(ctx context.Context, e *ConsensusENV) {
+				proposal := generateBlockProposal(e.curRound, e.curHeight, -1, false, signer(e, e.curRound), member(e, e.curRound))
+				require.NoError(t, e.core.handleMsg(ctx, proposal))
+			},
+			expected: interfaces.PrevoteRecord{Reason: interfaces.PrevoteValue},
+		},
+		{
+			name: "no proposal before the timeout",
+			customize: func(e *ConsensusENV) {
+				e.curRound = 1
+			},
+			drive:    timeoutPropose,
+			expected: interfaces.PrevoteRecord{Round: 1, Reason: interfaces.PrevoteNoProposal},
+		},
+		{
+			name: "old proposal without a prevote quorum before the timeout",
+			customize: func(e *ConsensusENV) {
+				e.curRound = 1
+			},
+			drive: func(ctx context.Context, e *ConsensusENV) {
+				proposal := generateBlockProposal(e.curRound, e.curHeight, 0, false, signer(e, e.curRound), member(e, e.curRound))
+				require.NoError(t, e.core.handleMsg(ctx, proposal))
+				require.False(t, e.core.sentPrevote)
+				timeoutPropose(ctx, e)
+			},
+			expected: interfaces.PrevoteRecord{Round: 1, Reason: interfaces.PrevoteTimeout},
+		},
+		{
+			name:      "invalid proposal",
+			verifyErr: errInvalid,
+			drive: func(ctx context.Context, e *ConsensusENV) {
+				proposal := generateBlockProposal(e.curRound, e.curHeight, -1, true, signer(e, e.curRound), member(e, e.curRound))
+				require.ErrorIs(t, e.core.handleMsg(ctx, proposal), errInvalid)
+			},
+			expected: interfaces.PrevoteRecord{Reason: interfaces.PrevoteInvalid, ErrorClass: "invalid"},
+		},
+		{
+			name:      "proposal of a pruned ancestor",
+			verifyErr: consensus.ErrPrunedAncestor,
+			drive: func(ctx context.Context, e *ConsensusENV) {
+				proposal := generateBlockProposal(e.curRound, e.curHeight, -1, false, signer(e, e.curRound), member(e, e.curRound))
+				require.ErrorIs(t, e.core.handleMsg(ctx, proposal), consensus.ErrPrunedAncestor)
+			},
+			expected: interfaces.PrevoteRecord{Reason: interfaces.PrevoteInvalid, ErrorClass: "internal"},
+		},
+		{
+			name: "locked on another value",
+			customize: func(e *ConsensusENV) {
+				e.lockedRound = 0
+				e.lockedValue = generateBlock(e.curHeight)
+				e.validRound = 0
+				e.validValue = e.lockedValue
+			},
+			drive: func(ctx context.Context, e *ConsensusENV) {
+				proposal := generateBlockProposal(e.curRound, e.curHeight, -1, false, signer(e, e.curRound), member(e, e.curRound))
+				require.NoError(t, e.core.handleMsg(ctx, proposal))
+			},
+			expected: interfaces.PrevoteRecord{Reason: interfaces.PrevoteLocked},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewConsensusEnv(t, func(e *ConsensusENV) {
+				e.step = Propose
+				if tt.customize != nil {
+					tt.customize(e)
+				}
+			})
+			c := newPrevoteReasonCore(t, e, nil, tt.verifyErr)
+			tt.drive(context.Background(), e)
+			require.True(t, c.sentPrevote)
+			require.Equal(t, []interfaces.PrevoteRecord{tt.expected}, c.prevoteRecords)
+			require.Equal(t, c.prevoteRecords, c.snapshot().Prevotes)
+		})
+	}
+}
+
+func TestPrevoteReasonsJournal(t *testing.T) {
+	env := NewConsensusEnv(t, func(e *ConsensusENV) {
+		e.step = Propose
+	})
+	db := rawdb.NewMemoryDatabase()
+	c := newPrevoteReasonCore(t, env, db, errors.New("invalid proposal"))
+	ctx := context.Background()
+
+	// no proposal is received in round 0, the proposal of round 1 is invalid
+	c.handleTimeoutPropose(ctx, TimeoutEvent{RoundWhenCalled: 0, HeightWhenCalled: env.curHeight, Step: Propose})
+	c.setInitialState(1)
+	c.SetStep(ctx, Propose)
+	proposal := generateBlockProposal(1, env.curHeight, -1, true, signer(env, 1), member(env, 1))
+	require.Error(t, c.handleMsg(ctx, proposal))
+	expected := []interfaces.PrevoteRecord{
+		{Round: 0, Reason: interfaces.PrevoteNoProposal},
+		{Round: 1, Reason: interfaces.PrevoteInvalid, ErrorClass: "invalid"},
+	}
+	require.Equal(t, expected, c.prevoteRecords)
+
+	// the records are journaled along with the round state, and restored on restart
+	restarted := newPrevoteReasonCore(t, env, db, nil)
+	journaled := restarted.journaled()
+	require.NotNil(t, journaled)
+	restarted.startRound(ctx, int64(journaled.state.Round), journaled)
+	require.Equal(t, expected, restarted.prevoteRecords)
+
+	// the committed height dumps the count of each reason
+	path := filepath.Join(t.TempDir(), "timing.csv")
+	require.NoError(t, restarted.timingDump.Start(path, time.Hour, 0))
+	restarted.dumpTiming(proposal, 2, 0)
+	require.NoError(t, restarted.timingDump.Stop())
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	row := make(map[string]string)
+	for i, column := range timingdump.Header {
+		row[column] = records[1][i]
+	}
+	require.Equal(t, "0", row["prevote_value"])
+	require.Equal(t, "1", row["prevote_noproposal"])
+	require.Equal(t, "0", row["prevote_timeout"])
+	require.Equal(t, "1", row["prevote_invalid"])
+	require.Equal(t, "0", row["prevote_locked"])
+	require.Equal(t, "invalid", row["invalid_class"])
+
+	// the records are reset on a new height
+	restarted.setInitialState(0)
+	require.Empty(t, restarted.prevoteRecords)
+}
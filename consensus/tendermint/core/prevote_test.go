@@ -27,7 +27,7 @@ func TestSendPrevote(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		committeeSet := NewTestCommitteeSet(4)
-		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(1)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 		// return random signature just to allow prevote encoding
 		backendMock.EXPECT().Sign(gomock.Any()).Times(1).Return(testSignature)
 
@@ -72,7 +72,7 @@ func TestSendPrevote(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signer)
-		backendMock.EXPECT().Broadcast(gomock.Any(), expectedMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), expectedMsg)
 
 		c := &Core{
 			backend:          backendMock,
@@ -169,7 +169,7 @@ func TestHandlePrevote(t *testing.T) {
 
 		precommit := message.NewPrecommit(2, 3, curRoundMessage.ProposalHash(), signer, &member, csize)
 
-		backendMock.EXPECT().Broadcast(gomock.Any(), precommit)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), precommit)
 		backendMock.EXPECT().Post(gomock.Any()).MaxTimes(3)
 
 		c := &Core{
@@ -222,7 +222,7 @@ func TestHandlePrevote(t *testing.T) {
 
 		precommit := message.NewPrecommit(2, 3, common.Hash{}, makeSigner(keys[member2.Address].consensus), &member2, csize)
 
-		backendMock.EXPECT().Broadcast(gomock.Any(), precommit)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), precommit)
 
 		logger := log.New("backend", "test", "id", 0)
 
@@ -8,6 +8,7 @@ import (
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/types"
@@ -36,6 +37,7 @@ func (c *Proposer) SendProposal(_ context.Context, block *types.Block) {
 	c.sentProposal = true
 	c.backend.SetProposedBlockHash(block.Hash())
 	c.LogProposalMessageEvent("MessageEvent(Proposal): Sent", proposal)
+	c.journalSent(proposal)
 	c.Broadcaster().Broadcast(proposal)
 	if metrics.Enabled {
 		now := time.Now()
@@ -98,7 +100,7 @@ func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose
 
 	// Verify the proposal we received
 	start := time.Now()
-	duration, err := c.backend.VerifyProposal(proposal.Block()) // youssef: can we skip the verification for our own proposal?
+	duration, err := c.verifyProposal(proposal.Block()) // youssef: can we skip the verification for our own proposal?
 
 	if metrics.Enabled {
 		now := time.Now()
@@ -113,11 +115,11 @@ func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose
 			c.logger.Debug("delaying processing of proposal due to future timestamp", "delay", duration)
 			c.StopFutureProposalTimer()
 			c.futureProposalTimer = time.AfterFunc(duration, func() {
-				c.SendEvent(backlogMessageEvent{
+				c.sendBacklog(backlogMessageEvent{
 					msg: proposal,
 				})
 			})
-			return err
+			return constants.WrapError(constants.ClassFuture, err)
 		}
 		// if the proposal block is already in the chain, no need to prevote for nil
 		if errors.Is(err, core.ErrKnownBlock) || errors.Is(err, constants.ErrAlreadyHaveBlock) {
@@ -125,10 +127,15 @@ func (c *Proposer) HandleProposal(ctx context.Context, proposal *message.Propose
 			c.SetStep(ctx, PrecommitDone) // we do not need to process any more consensus messages for this height
 			return constants.ErrAlreadyHaveBlock
 		}
+		// the state of the parent block may be missing locally, in which case the proposer is not at fault
+		if !errors.Is(err, consensus.ErrPrunedAncestor) {
+			err = constants.WrapError(constants.ClassInvalid, err)
+		}
 		// Proposal is invalid here, we need to prevote nil.
 		// However, we may have already sent a prevote nil in the past without having processed the proposal
 		// because of a timeout, so we need to check if we are still in the Propose step.
 		if c.step == Propose {
+			c.recordPrevote(interfaces.PrevoteInvalid, err)
 			c.prevoter.SendPrevote(ctx, true)
 			// do not to accept another proposal in current round
 			c.SetStep(ctx, Prevote)
@@ -159,6 +166,7 @@ func (c *Proposer) HandleNewCandidateBlockMsg(ctx context.Context, candidateBloc
 	}
 
 	c.pendingCandidateBlocks[candidateBlock.NumberU64()] = candidateBlock
+	c.lastCandidateHash, c.lastCandidateAt = candidateBlock.Hash(), time.Now()
 
 	// if current node is the proposer of current height and current round at step PROPOSE without available candidate
 	// block sent before, if the incoming candidate block is the one it missed, send it now.
@@ -60,7 +60,7 @@ func TestSendPropose(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
 		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(makeSigner(proposerConsensusKey))
-		backendMock.EXPECT().Broadcast(gomock.Any(), proposal)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), proposal)
 
 		c := &Core{
 			address:          proposer,
@@ -215,10 +215,6 @@ func TestHandleProposal(t *testing.T) {
 		proposal := message.NewPropose(round, height, 1, block, signer, signerMember)
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().VerifyProposal(gomock.Any()).Return(eventPostingDelay, consensus.ErrFutureTimestampBlock)
-		event := backlogMessageEvent{
-			msg: proposal,
-		}
-		backendMock.EXPECT().Post(event).Times(1)
 		c := &Core{
 			address:          addr,
 			backend:          backendMock,
@@ -232,12 +228,17 @@ func TestHandleProposal(t *testing.T) {
 		}
 
 		c.SetDefaultHandlers()
+		subscribeFeeds(t, c)
 		err := c.proposer.HandleProposal(context.Background(), proposal)
 		require.Error(t, err)
-		// We wait here for at least the delay "eventPostingDelay" returned by VerifyProposal :
-		// We expect above that a backlog event containing the future proposal message will be posted
-		// after this amount of time. This being done asynchrounously it is necessary to pause the main thread.
-		<-time.NewTimer(2 * eventPostingDelay).C
+		// A backlog event containing the future proposal message is posted after the delay
+		// "eventPostingDelay" returned by VerifyProposal, asynchronously.
+		select {
+		case event := <-c.feeds.backlogCh:
+			require.Equal(t, backlogMessageEvent{msg: proposal}, event)
+		case <-time.After(2 * eventPostingDelay):
+			t.Fatal("backlog event not posted")
+		}
 	})
 
 	t.Run("valid proposal given, no error returned", func(t *testing.T) {
@@ -338,7 +339,7 @@ func TestHandleProposal(t *testing.T) {
 		prevote := message.NewPrevote(round, height, block.Hash(), signer, signerMember, csize)
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().VerifyProposal(proposal.Block())
-		backendMock.EXPECT().Broadcast(gomock.Any(), prevote)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), prevote)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signer)
 		c := &Core{
 			address:          addr,
@@ -384,7 +385,7 @@ func TestHandleProposal(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().VerifyProposal(proposal.Block())
-		backendMock.EXPECT().Broadcast(gomock.Any(), message.NewPrevote(round, height, proposal.Block().Hash(), signer, signerMember, csize))
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), message.NewPrevote(round, height, proposal.Block().Hash(), signer, signerMember, csize))
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signer)
 
 		c := &Core{
@@ -463,7 +464,7 @@ func TestHandleNewCandidateBlockMsg(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
-		backendMock.EXPECT().Broadcast(gomock.Any(), proposal)
+		backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), proposal)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(proposerKey))
 
 		c := &Core{
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/constants"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+)
+
+// maxRejectedProposals is the number of rejected proposals kept in the archive.
+const maxRejectedProposals = 32
+
+// RejectedProposal is a proposal that reached a quorum of precommits but was not committed,
+// together with the evidence that led to its rejection.
+type RejectedProposal struct {
+	Height   uint64
+	Round    int64
+	Proposal *message.Propose
+	Reason   error
+	Evidence []message.Msg
+}
+
+// rejectedArchive keeps the most recent rejected proposals in memory.
+// It is written by the core routine and read by the APIs, hence the lock.
+type rejectedArchive struct {
+	entries []*RejectedProposal
+	sync.RWMutex
+}
+
+func (a *rejectedArchive) add(entry *RejectedProposal) {
+	a.Lock()
+	defer a.Unlock()
+	if len(a.entries) == maxRejectedProposals {
+		a.entries = a.entries[1:]
+	}
+	a.entries = append(a.entries, entry)
+}
+
+func (a *rejectedArchive) all() []*RejectedProposal {
+	a.RLock()
+	defer a.RUnlock()
+	entries := make([]*RejectedProposal, len(a.entries))
+	copy(entries, a.entries)
+	return entries
+}
+
+// RejectedProposals returns the archived proposals which were not committed because of a safety violation.
+func (c *Core) RejectedProposals() []*RejectedProposal {
+	return c.rejected.all()
+}
+
+// conflictingPrecommitQuorum looks for a precommit quorum at the current height for a value different from `value`.
+// With the BFT assumptions only one value can reach quorum at a given height, finding one means that
+// more than 1/3 of the voting power is byzantine or that there is a bug.
+func (c *Core) conflictingPrecommitQuorum(value common.Hash) (int64, common.Hash, bool) {
+	quorum := c.CommitteeSet().Quorum()
+	for _, round := range c.messages.GetRounds() {
+		rm := c.messages.GetOrCreate(round)
+		for _, v := range rm.PrecommitValues() {
+			if v == value || v == NilValue {
+				continue
+			}
+			if rm.PrecommitsPower(v).Cmp(quorum) >= 0 {
+				return round, v, true
+			}
+		}
+	}
+	return 0, common.Hash{}, false
+}
+
+// haltHeight closes the current height without committing the proposal, archives the evidence of both
+// precommit quorums and raises a ConflictingQuorumEvent.
+func (c *Core) haltHeight(ctx context.Context, proposal *message.Propose, conflictingRound int64, conflictingValue common.Hash) {
+	height := c.Height().Uint64()
+	value := proposal.Block().Hash()
+	c.logger.Error("⚠️ CONFLICTING PRECOMMIT QUORUMS, HALTING HEIGHT ⚠️", "height", height,
+		"round", proposal.R(), "value", value, "conflictingRound", conflictingRound, "conflictingValue", conflictingValue)
+
+	// no more messages will be processed for this height and the timeouts are stopped.
+	// The height can still be concluded by importing the block through the p2p block propagation.
+	c.SetStep(ctx, PrecommitDone)
+
+	// both values have a quorum, therefore the precommits are there
+	evidence := []message.Msg{
+		c.messages.GetOrCreate(proposal.R()).PrecommitFor(value),
+		c.messages.GetOrCreate(conflictingRound).PrecommitFor(conflictingValue),
+	}
+	c.rejected.add(&RejectedProposal{
+		Height:   height,
+		Round:    proposal.R(),
+		Proposal: proposal,
+		Reason:   constants.ErrConflictingQuorum,
+		Evidence: evidence,
+	})
+	c.backend.Post(events.ConflictingQuorumEvent{
+		Height:           height,
+		Round:            proposal.R(),
+		Value:            value,
+		ConflictingRound: conflictingRound,
+		ConflictingValue: conflictingValue,
+	})
+}
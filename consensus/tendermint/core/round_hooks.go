@@ -0,0 +1,128 @@
+package core
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+	"github.com/autonity/autonity/metrics"
+)
+
+var RoundHookPanicMeter = metrics.NewRegisteredMeter("tendermint/round/hookpanic", nil)
+
+// heightRound identifies a round of a height.
+type heightRound struct {
+	height uint64
+	round  int64
+}
+
+// roundSnapshot is the view of the consensus state handed to the round hooks. It is a copy, the
+// hooks cannot alter the state through it.
+type roundSnapshot struct {
+	Step        Step
+	LockedRound int64
+	LockedValue *common.Hash
+	ValidRound  int64
+	ValidValue  *common.Hash
+}
+
+// roundHook runs at a round boundary, with the round left and the round entered.
+type roundHook struct {
+	name string
+	run  func(c *Core, from, to heightRound, state roundSnapshot)
+}
+
+// roundHooks holds the hooks run by StartRound, in their registration order. The pre-round hooks run
+// once the state is moved to the new round, before the round starts. The post-round hooks run once
+// the round started: the proposal is sent or the propose timeout scheduled, and the future messages
+// of the round are replayed.
+type roundHooks struct {
+	pre  []roundHook
+	post []roundHook
+}
+
+// coreRoundHooks are the hooks of the core itself, run by the cores not registering any other.
+var coreRoundHooks = roundHooks{
+	pre: []roundHook{
+		{name: "metrics", run: func(c *Core, _, to heightRound, _ roundSnapshot) {
+			c.measureHeightRoundMetrics(to.round)
+		}},
+		{name: "timeouts", run: func(c *Core, _, _ heightRound, _ roundSnapshot) {
+			c.proposeTimeout.Reset(Propose)
+			c.prevoteTimeout.Reset(Prevote)
+			c.precommitTimeout.Reset(Precommit)
+		}},
+	},
+	post: []roundHook{
+		{name: "round change event", run: func(c *Core, _, to heightRound, _ roundSnapshot) {
+			c.backend.Post(events.RoundChangeEvent{Height: to.height, Round: to.round})
+		}},
+	},
+}
+
+// clone returns a copy of the hooks, to register more of them.
+func (h *roundHooks) clone() *roundHooks {
+	return &roundHooks{
+		pre:  append([]roundHook(nil), h.pre...),
+		post: append([]roundHook(nil), h.post...),
+	}
+}
+
+// addPreRound registers a hook run after the ones already registered, before the rounds start.
+func (h *roundHooks) addPreRound(name string, run func(c *Core, from, to heightRound, state roundSnapshot)) {
+	h.pre = append(h.pre, roundHook{name: name, run: run})
+}
+
+// addPostRound registers a hook run after the ones already registered, once the rounds started.
+func (h *roundHooks) addPostRound(name string, run func(c *Core, from, to heightRound, state roundSnapshot)) {
+	h.post = append(h.post, roundHook{name: name, run: run})
+}
+
+// hooks returns the round hooks of the core.
+func (c *Core) hooks() *roundHooks {
+	if c.roundHooks == nil {
+		return &coreRoundHooks
+	}
+	return c.roundHooks
+}
+
+// heightRound returns the current height and round.
+func (c *Core) heightRound() heightRound {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+	var height uint64
+	if c.height != nil {
+		height = c.height.Uint64()
+	}
+	return heightRound{height: height, round: c.round}
+}
+
+func (c *Core) roundSnapshot() roundSnapshot {
+	return roundSnapshot{
+		Step:        c.step,
+		LockedRound: c.lockedRound,
+		LockedValue: getHash(c.lockedValue),
+		ValidRound:  c.validRound,
+		ValidValue:  getHash(c.validValue),
+	}
+}
+
+// runRoundHooks runs the hooks in order. A hook panicking is logged and skipped, the following hooks
+// and the round still run.
+func (c *Core) runRoundHooks(hooks []roundHook, from, to heightRound) {
+	if len(hooks) == 0 {
+		return
+	}
+	state := c.roundSnapshot()
+	for _, hook := range hooks {
+		c.runRoundHook(hook, from, to, state)
+	}
+}
+
+func (c *Core) runRoundHook(hook roundHook, from, to heightRound, state roundSnapshot) {
+	defer func() {
+		if r := recover(); r != nil {
+			RoundHookPanicMeter.Mark(1)
+			c.logger.Error("Round hook panicked", "hook", hook.name, "height", to.height, "round", to.round, "err", r)
+		}
+	}()
+	hook.run(c, from, to, state)
+}
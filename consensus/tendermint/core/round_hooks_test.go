@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/events"
+)
+
+func newRoundHooksCore(t *testing.T, env *ConsensusENV, posts int) *Core {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(func() { waitForExpects(ctrl) })
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().HeadBlock().Return(env.previousValue).AnyTimes()
+	backendMock.EXPECT().Post(gomock.Any()).Times(posts)
+	backendMock.EXPECT().ProcessFutureMsgs(env.previousHeight.Uint64() + 1).Times(1)
+	env.setupCore(backendMock, env.clientAddress)
+	t.Cleanup(func() { _ = env.core.proposeTimeout.StopTimer() })
+	return env.core
+}
+
+func TestRoundHooksOrder(t *testing.T) {
+	env := NewConsensusEnv(t, nil)
+	c := newRoundHooksCore(t, env, 2)
+
+	type call struct {
+		hook     string
+		from, to heightRound
+		step     Step
+	}
+	var calls []call
+	record := func(name string) func(*Core, heightRound, heightRound, roundSnapshot) {
+		return func(_ *Core, from, to heightRound, state roundSnapshot) {
+			calls = append(calls, call{hook: name, from: from, to: to, step: state.Step})
+		}
+	}
+	c.roundHooks = coreRoundHooks.clone()
+	c.roundHooks.addPreRound("first", record("first"))
+	c.roundHooks.addPreRound("second", record("second"))
+	c.roundHooks.addPostRound("third", record("third"))
+	// the core hooks are kept
+	require.Len(t, coreRoundHooks.pre, 2)
+	require.Len(t, c.roundHooks.pre, 4)
+
+	height := env.curHeight.Uint64()
+	c.StartRound(context.Background(), 0)
+	c.StartRound(context.Background(), 1)
+	require.Equal(t, []call{
+		{hook: "first", from: heightRound{height, 0}, to: heightRound{height, 0}, step: PrecommitDone},
+		{hook: "second", from: heightRound{height, 0}, to: heightRound{height, 0}, step: PrecommitDone},
+		{hook: "third", from: heightRound{height, 0}, to: heightRound{height, 0}, step: Propose},
+		{hook: "first", from: heightRound{height, 0}, to: heightRound{height, 1}, step: Propose},
+		{hook: "second", from: heightRound{height, 0}, to: heightRound{height, 1}, step: Propose},
+		{hook: "third", from: heightRound{height, 0}, to: heightRound{height, 1}, step: Propose},
+	}, calls)
+}
+
+func TestRoundHooksPanic(t *testing.T) {
+	env := NewConsensusEnv(t, nil)
+	c := newRoundHooksCore(t, env, 1)
+
+	var ran []string
+	c.roundHooks = coreRoundHooks.clone()
+	c.roundHooks.addPreRound("buggy", func(*Core, heightRound, heightRound, roundSnapshot) {
+		panic("buggy extension")
+	})
+	c.roundHooks.addPreRound("after", func(*Core, heightRound, heightRound, roundSnapshot) {
+		ran = append(ran, "pre")
+	})
+	c.roundHooks.addPostRound("buggy", func(*Core, heightRound, heightRound, roundSnapshot) {
+		panic("buggy extension")
+	})
+	c.roundHooks.addPostRound("after", func(*Core, heightRound, heightRound, roundSnapshot) {
+		ran = append(ran, "post")
+	})
+
+	require.NotPanics(t, func() { c.StartRound(context.Background(), 0) })
+	require.Equal(t, []string{"pre", "post"}, ran)
+	// the core hooks ran around the failing ones
+	env.checkState(t, env.curHeight, 0, Propose, nil, -1, nil, -1)
+}
+
+func TestRoundHooksDisabled(t *testing.T) {
+	env := NewConsensusEnv(t, nil)
+	type outcome struct {
+		state          roundSnapshot
+		round          int64
+		proposeStarted bool
+		events         []any
+	}
+	run := func(extend func(h *roundHooks)) outcome {
+		ctrl := gomock.NewController(t)
+		defer waitForExpects(ctrl)
+		var posted []any
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().HeadBlock().Return(env.previousValue).AnyTimes()
+		backendMock.EXPECT().Post(gomock.Any()).Do(func(ev any) { posted = append(posted, ev) }).Times(2)
+		backendMock.EXPECT().ProcessFutureMsgs(env.previousHeight.Uint64() + 1).Times(1)
+		env.setupCore(backendMock, env.clientAddress)
+		c := env.core
+		defer func() { _ = c.proposeTimeout.StopTimer() }()
+		if extend != nil {
+			c.roundHooks = coreRoundHooks.clone()
+			extend(c.roundHooks)
+		}
+		c.StartRound(context.Background(), 0)
+		c.SetLockedValue(env.curBlock)
+		c.SetLockedRound(0)
+		c.StartRound(context.Background(), 1)
+		return outcome{
+			state:          c.roundSnapshot(),
+			round:          c.Round(),
+			proposeStarted: c.proposeTimeout.TimerStarted(),
+			events:         posted,
+		}
+	}
+
+	// the extension hooks, even failing ones, leave the consensus behaviour unchanged
+	disabled := run(nil)
+	enabled := run(func(h *roundHooks) {
+		h.addPreRound("noop", func(*Core, heightRound, heightRound, roundSnapshot) {})
+		h.addPreRound("buggy", func(*Core, heightRound, heightRound, roundSnapshot) { panic("buggy extension") })
+		h.addPostRound("buggy", func(*Core, heightRound, heightRound, roundSnapshot) { panic("buggy extension") })
+	})
+	require.Equal(t, disabled, enabled)
+	require.Equal(t, []any{
+		events.RoundChangeEvent{Height: env.curHeight.Uint64(), Round: 0},
+		events.RoundChangeEvent{Height: env.curHeight.Uint64(), Round: 1},
+	}, disabled.events)
+}
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/rlp"
+)
+
+var errUnknownJournaledMessage = errors.New("unknown journaled message code")
+
+// RoundJournal is the write-ahead log of the consensus round state. The state of the height in
+// progress is persisted along with the messages it depends on, the messages sent by the node and the
+// proposals of its locked and valid values, before any message is sent. A restarted node resumes the
+// height from it rather than from round 0, so that it does not break the locking rules its votes
+// committed it to. The journal is disabled until a database is set.
+type RoundJournal struct {
+	db     ethdb.KeyValueStore
+	height uint64               // height of the messages journaled
+	values map[common.Hash]bool // values whose proposal is journaled at the height
+}
+
+// SetDatabase enables the journal, persisted to the database. It must be called before the core starts.
+func (j *RoundJournal) SetDatabase(db ethdb.KeyValueStore) {
+	j.db = db
+}
+
+// journaledRoundState is the consensus round state as journaled. The locked and valid rounds are
+// shifted by one, 0 standing for none.
+type journaledRoundState struct {
+	Height      uint64
+	Round       uint64
+	Step        uint64
+	LockedRound uint64
+	LockedValue common.Hash
+	ValidRound  uint64
+	ValidValue  common.Hash
+	Prevotes    []journaledPrevote `rlp:"optional"`
+}
+
+// journaledPrevote is the reason of a prevote sent by the node at the height, as journaled.
+type journaledPrevote struct {
+	Round      uint64
+	Reason     uint8
+	ErrorClass string
+}
+
+// journaledMessage is a consensus message as journaled.
+type journaledMessage struct {
+	Code    uint8
+	Payload []byte
+}
+
+// journaledRound is the height in progress as read back from the journal.
+type journaledRound struct {
+	state    journaledRoundState
+	messages []journaledMessage
+}
+
+// Journal returns the write-ahead log of the round state.
+func (c *Core) Journal() *RoundJournal {
+	return c.journal
+}
+
+func (c *Core) journalEnabled() bool {
+	return c.journal != nil && c.journal.db != nil
+}
+
+// journalRound persists the round state. The proposals of the locked and valid values are journaled
+// along, the first time they are.
+func (c *Core) journalRound() {
+	if !c.journalEnabled() {
+		return
+	}
+	j := c.journal
+	height := c.Height().Uint64()
+	if height != j.height {
+		// the messages of the heights left are no longer needed
+		rawdb.DeleteConsensusWALMessagesBelow(j.db, height)
+		j.height, j.values = height, make(map[common.Hash]bool)
+	}
+	for _, value := range []*types.Block{c.lockedValue, c.validValue} {
+		if value == nil || j.values[value.Hash()] {
+			continue
+		}
+		if proposal := c.proposalFor(value.Hash()); proposal != nil {
+			c.journalMessage(proposal)
+		}
+	}
+	state := journaledRoundState{
+		Height:      height,
+		Round:       uint64(c.Round()),
+		Step:        uint64(c.step),
+		LockedRound: uint64(c.lockedRound + 1),
+		LockedValue: hashOrZero(c.lockedValue),
+		ValidRound:  uint64(c.validRound + 1),
+		ValidValue:  hashOrZero(c.validValue),
+	}
+	for _, record := range c.prevoteRecords {
+		state.Prevotes = append(state.Prevotes, journaledPrevote{
+			Round:      uint64(record.Round),
+			Reason:     uint8(record.Reason),
+			ErrorClass: record.ErrorClass,
+		})
+	}
+	data, err := rlp.EncodeToBytes(&state)
+	if err != nil {
+		c.logger.Error("Failed to encode the consensus round state", "err", err)
+		return
+	}
+	rawdb.WriteConsensusRoundState(j.db, data)
+}
+
+// journalSent persists the message about to be sent by the node, along with the round state.
+func (c *Core) journalSent(msg message.Msg) {
+	if !c.journalEnabled() {
+		return
+	}
+	c.journalRound()
+	c.journalMessage(msg)
+}
+
+func (c *Core) journalMessage(msg message.Msg) {
+	data, err := rlp.EncodeToBytes(&journaledMessage{Code: msg.Code(), Payload: msg.Payload()})
+	if err != nil {
+		c.logger.Error("Failed to encode the consensus message", "err", err)
+		return
+	}
+	rawdb.WriteConsensusWALMessage(c.journal.db, msg.H(), msg.Hash(), data)
+	if proposal, ok := msg.(*message.Propose); ok {
+		c.journal.values[proposal.Block().Hash()] = true
+	}
+}
+
+// proposalFor returns the proposal of the value received at the current height, if any.
+func (c *Core) proposalFor(value common.Hash) *message.Propose {
+	for _, round := range c.messages.GetRounds() {
+		if proposal := c.messages.GetOrCreate(round).Proposal(); proposal != nil && proposal.Block().Hash() == value {
+			return proposal
+		}
+	}
+	return nil
+}
+
+func hashOrZero(b *types.Block) common.Hash {
+	if b == nil {
+		return common.Hash{}
+	}
+	return b.Hash()
+}
+
+// journaled returns the round journaled for the height following the head block, if any.
+func (c *Core) journaled() *journaledRound {
+	if !c.journalEnabled() {
+		return nil
+	}
+	data := rawdb.ReadConsensusRoundState(c.journal.db)
+	if data == nil {
+		return nil
+	}
+	var journaled journaledRound
+	if err := rlp.DecodeBytes(data, &journaled.state); err != nil {
+		c.logger.Error("Discarding the corrupted consensus round state", "err", err)
+		return nil
+	}
+	if journaled.state.Height != c.backend.HeadBlock().NumberU64()+1 {
+		// the height journaled is committed already
+		return nil
+	}
+	for _, data := range rawdb.ReadConsensusWALMessages(c.journal.db, journaled.state.Height) {
+		var msg journaledMessage
+		if err := rlp.DecodeBytes(data, &msg); err != nil {
+			c.logger.Error("Discarding a corrupted journaled consensus message", "err", err)
+			continue
+		}
+		journaled.messages = append(journaled.messages, msg)
+	}
+	return &journaled
+}
+
+// decodeJournaled decodes and verifies a journaled message of the current height.
+func (c *Core) decodeJournaled(journaled journaledMessage) (message.Msg, error) {
+	var msg message.Msg
+	switch journaled.Code {
+	case message.ProposalCode:
+		msg = new(message.Propose)
+	case message.PrevoteCode:
+		msg = new(message.Prevote)
+	case message.PrecommitCode:
+		msg = new(message.Precommit)
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnknownJournaledMessage, journaled.Code)
+	}
+	if err := rlp.DecodeBytes(journaled.Payload, msg); err != nil {
+		return nil, err
+	}
+	if err := msg.PreValidate(c.LastHeader()); err != nil {
+		return nil, err
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// restoreRound replays the journaled messages and restores the locked and valid values, once the
+// core is set up for the journaled round. It returns the step to resume the round at, at least the
+// one following the messages sent by the node in the round.
+func (c *Core) restoreRound(journaled *journaledRound) Step {
+	round := c.Round()
+	values := make(map[common.Hash]*types.Block)
+	for _, journaledMsg := range journaled.messages {
+		msg, err := c.decodeJournaled(journaledMsg)
+		if err != nil {
+			c.logger.Error("Discarding an invalid journaled consensus message", "err", err)
+			continue
+		}
+		roundMessages := c.messages.GetOrCreate(msg.R())
+		switch m := msg.(type) {
+		case *message.Propose:
+			roundMessages.SetProposal(m, true)
+			values[m.Block().Hash()] = m.Block()
+			if m.R() == round && m.Signer() == c.address {
+				c.sentProposal = true
+			}
+		case *message.Prevote:
+			roundMessages.AddPrevote(m)
+			if m.R() == round {
+				c.sentPrevote = true
+			}
+		case *message.Precommit:
+			roundMessages.AddPrecommit(m)
+			if m.R() == round {
+				c.sentPrecommit = true
+			}
+		}
+	}
+
+	state := journaled.state
+	if state.LockedRound > 0 {
+		if value, ok := values[state.LockedValue]; ok {
+			c.lockedRound, c.lockedValue = int64(state.LockedRound)-1, value
+		} else {
+			c.logger.Error("Missing journaled locked value", "value", state.LockedValue)
+		}
+	}
+	if state.ValidRound > 0 {
+		if value, ok := values[state.ValidValue]; ok {
+			c.validRound, c.validValue = int64(state.ValidRound)-1, value
+		} else {
+			c.logger.Error("Missing journaled valid value", "value", state.ValidValue)
+		}
+	}
+
+	c.prevoteRecords = nil
+	for _, prevote := range state.Prevotes {
+		c.prevoteRecords = append(c.prevoteRecords, interfaces.PrevoteRecord{
+			Round:      int64(prevote.Round),
+			Reason:     interfaces.PrevoteReason(prevote.Reason),
+			ErrorClass: prevote.ErrorClass,
+		})
+	}
+
+	step := Propose
+	if uint64(round) == state.Round {
+		// a commit which did not reach the chain resumes at the precommit step
+		step = min(Step(state.Step), Precommit)
+	}
+	if c.sentPrevote {
+		step = max(step, Prevote)
+	}
+	if c.sentPrecommit {
+		step = max(step, Precommit)
+	}
+	c.logger.Info("Resuming the journaled consensus round", "height", state.Height, "round", round, "step", step,
+		"lockedRound", c.lockedRound, "validRound", c.validRound, "messages", len(journaled.messages))
+	return step
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/ethdb"
+)
+
+// newJournaledCore sets up the core of the environment with its round journal in db. The core
+// broadcasts up to broadcasts messages.
+func newJournaledCore(t *testing.T, env *ConsensusENV, db ethdb.KeyValueStore, broadcasts int) *Core {
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().HeadBlock().Return(env.previousValue).AnyTimes()
+	backendMock.EXPECT().Post(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().ProcessFutureMsgs(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(env.clientSigner).AnyTimes()
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(broadcasts)
+	env.setupCore(backendMock, env.clientAddress)
+	env.core.Journal().SetDatabase(db)
+	t.Cleanup(env.core.stopAllTimeouts)
+	return env.core
+}
+
+func TestRoundJournalResume(t *testing.T) {
+	env := NewConsensusEnv(t, nil)
+	db := rawdb.NewMemoryDatabase()
+	c := newJournaledCore(t, env, db, 2)
+	ctx := context.Background()
+
+	// nothing is journaled yet
+	require.Nil(t, c.journaled())
+
+	// the node prevotes and locks on the proposal of round 0
+	c.StartRound(ctx, 0)
+	proposer := c.CommitteeSet().GetProposer(0)
+	proposal := generateBlockProposal(0, env.curHeight, -1, false, makeSigner(env.keys[proposer.Address].consensus), &proposer)
+	c.curRoundMessages.SetProposal(proposal, true)
+	c.prevoter.SendPrevote(ctx, false)
+	c.SetStep(ctx, Prevote)
+	c.lockedValue, c.lockedRound = proposal.Block(), 0
+	c.precommiter.SendPrecommit(ctx, false)
+	c.SetStep(ctx, Precommit)
+	c.validValue, c.validRound = proposal.Block(), 0
+	c.journalRound()
+	require.Len(t, rawdb.ReadConsensusWALMessages(db, env.curHeight.Uint64()), 3)
+
+	// the restarted node resumes the round at its precommit step, with its lock
+	restarted := newJournaledCore(t, env, db, 0)
+	journaled := restarted.journaled()
+	require.NotNil(t, journaled)
+	restarted.startRound(ctx, int64(journaled.state.Round), journaled)
+	require.Equal(t, int64(0), restarted.Round())
+	require.Equal(t, Precommit, restarted.step)
+	require.True(t, restarted.sentPrevote)
+	require.True(t, restarted.sentPrecommit)
+	require.Equal(t, proposal.Block().Hash(), restarted.lockedValue.Hash())
+	require.Equal(t, int64(0), restarted.lockedRound)
+	require.Equal(t, proposal.Block().Hash(), restarted.validValue.Hash())
+	require.Equal(t, proposal.Block().Hash(), restarted.curRoundMessages.ProposalHash())
+	// the votes of the node are replayed
+	require.Equal(t, env.clientMember.VotingPower, restarted.curRoundMessages.PrevotesPower(proposal.Block().Hash()))
+	require.Equal(t, env.clientMember.VotingPower, restarted.curRoundMessages.PrecommitsPower(proposal.Block().Hash()))
+
+	// a stale timeout does not make the node vote again in the round
+	restarted.handleTimeoutPropose(ctx, TimeoutEvent{RoundWhenCalled: 0, HeightWhenCalled: env.curHeight, Step: Propose})
+	restarted.handleTimeoutPrevote(ctx, TimeoutEvent{RoundWhenCalled: 0, HeightWhenCalled: env.curHeight, Step: Prevote})
+	require.Equal(t, Precommit, restarted.step)
+}
+
+func TestRoundJournalCommittedHeight(t *testing.T) {
+	env := NewConsensusEnv(t, nil)
+	db := rawdb.NewMemoryDatabase()
+	c := newJournaledCore(t, env, db, 0)
+	c.StartRound(context.Background(), 0)
+
+	// the height journaled is no longer resumed once the head reaches it
+	env.previousValue = generateBlock(env.curHeight)
+	restarted := newJournaledCore(t, env, db, 0)
+	require.Nil(t, restarted.journaled())
+
+	// the journaled messages are deleted once a later height is journaled
+	rawdb.WriteConsensusWALMessage(db, env.curHeight.Uint64(), common.Hash{1}, []byte{0xc0})
+	restarted.setHeight(new(big.Int).Add(env.curHeight, common.Big1))
+	restarted.journalRound()
+	require.Empty(t, rawdb.ReadConsensusWALMessages(db, env.curHeight.Uint64()))
+}
+
+func TestRoundJournalDisabled(t *testing.T) {
+	env := NewConsensusEnv(t, nil)
+	c := newJournaledCore(t, env, nil, 0)
+	c.StartRound(context.Background(), 0)
+	require.False(t, c.journalEnabled())
+	require.Nil(t, c.journaled())
+}
@@ -27,7 +27,9 @@ func (c *Core) newProposalCheck(ctx context.Context, proposal *message.Propose)
 	// When lockedRound is set to any value other than -1 lockedValue is also
 	// set to a non nil value. So we can be sure that we will only try to access
 	// lockedValue when it is non nil.
-	c.prevoter.SendPrevote(ctx, !(c.lockedRound == -1 || proposal.Block().Hash() == c.lockedValue.Hash()))
+	isNil := !(c.lockedRound == -1 || proposal.Block().Hash() == c.lockedValue.Hash())
+	c.recordPrevote(lockedPrevoteReason(isNil), nil)
+	c.prevoter.SendPrevote(ctx, isNil)
 	c.SetStep(ctx, Prevote)
 }
 
@@ -47,7 +49,9 @@ func (c *Core) oldProposalCheck(ctx context.Context, proposal *message.Propose)
 	hash := proposal.Block().Hash()
 	rm := c.messages.GetOrCreate(vr)
 	if rm.PrevotesPower(hash).Cmp(c.CommitteeSet().Quorum()) >= 0 {
-		c.prevoter.SendPrevote(ctx, !(c.lockedRound <= vr || hash == c.lockedValue.Hash()))
+		isNil := !(c.lockedRound <= vr || hash == c.lockedValue.Hash())
+		c.recordPrevote(lockedPrevoteReason(isNil), nil)
+		c.prevoter.SendPrevote(ctx, isNil)
 		c.SetStep(ctx, Prevote)
 	}
 }
@@ -89,6 +93,8 @@ func (c *Core) quorumPrevotesCheck(ctx context.Context, proposal *message.Propos
 		c.validValue = proposal.Block()
 		c.validRound = c.Round()
 		c.setValidRoundAndValue = true
+		c.journalRound()
+		c.publishSnapshot()
 	}
 }
 
@@ -134,9 +140,16 @@ func (c *Core) quorumPrecommitsCheck(ctx context.Context, proposal *message.Prop
 		PrecommitQuorumBlockTSDeltaBg.Add(time.Since(c.currBlockTimeStamp).Nanoseconds())
 	}
 
+	// defensive check: only one value can reach a precommit quorum at a given height.
+	// If it is not the case, do not commit and halt the height.
+	if conflictingRound, conflictingValue, ok := c.conflictingPrecommitQuorum(hash); ok {
+		c.haltHeight(ctx, proposal, conflictingRound, conflictingValue)
+		return true
+	}
+
 	// if there is a quorum, verify the proposal if needed
 	if !verified {
-		if _, err := c.backend.VerifyProposal(proposal.Block()); err != nil {
+		if _, err := c.verifyProposal(proposal.Block()); err != nil {
 			// This can happen if while we are processing the proposal,
 			// we actually receive the finalized proposed block from p2p block propagation (other peers already reached quorum on it)
 			// In this case we can just consider the proposal as committed.
@@ -157,7 +170,9 @@ func (c *Core) quorumPrecommitsCheck(ctx context.Context, proposal *message.Prop
 }
 
 // Line 55 in Algorithm 1 of The latest gossip on BFT consensus
-// check if we need to skip to a new round
+// check if we need to skip to a new round. The buffered messages of the new round are not handled
+// from here: StartRound queues them back to the event loop, so the round skip does not recurse into
+// the message handling.
 func (c *Core) roundSkipCheck(ctx context.Context, r int64) {
 	futurePower := new(big.Int)
 
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+)
+
+// StateSnapshot is the consistent view of the consensus state shared by the state dump, the metrics
+// and the support bundle.
+type StateSnapshot = interfaces.StateSnapshot
+
+type snapshotRequestEvent struct {
+	snapshotChan chan StateSnapshot
+}
+
+type supportBundleRequestEvent struct {
+	bundleChan chan interfaces.SupportBundle
+}
+
+// Snapshot returns a consistent view of the consensus state. It is served by the main loop, which is
+// the only writer of the consensus state.
+func (c *Core) Snapshot() StateSnapshot {
+	var e = snapshotRequestEvent{
+		snapshotChan: make(chan StateSnapshot),
+	}
+	go c.SendEvent(e)
+	return <-e.snapshotChan
+}
+
+// SupportBundle returns the consensus state snapshot along with the candidate block pipeline state.
+func (c *Core) SupportBundle() interfaces.SupportBundle {
+	var e = supportBundleRequestEvent{
+		bundleChan: make(chan interfaces.SupportBundle),
+	}
+	go c.SendEvent(e)
+	return <-e.bundleChan
+}
+
+// snapshot must only be called by the main loop. The fields guarded by the state lock are read under a
+// single acquisition, the others are owned by the main loop.
+func (c *Core) snapshot() StateSnapshot {
+	c.stateMu.RLock()
+	height, round, committee := c.height.Uint64(), c.round, c.committee
+	c.stateMu.RUnlock()
+
+	c.futureRoundLock.RLock()
+	futureRoundMessages := 0
+	for _, msgs := range c.futureRound {
+		futureRoundMessages += len(msgs)
+	}
+	c.futureRoundLock.RUnlock()
+
+	proposer := committee.GetProposer(round).Address
+	return StateSnapshot{
+		Height:              height,
+		Round:               round,
+		Step:                uint64(c.step),
+		LockedRound:         c.lockedRound,
+		LockedValue:         getHash(c.lockedValue),
+		ValidRound:          c.validRound,
+		ValidValue:          getHash(c.validValue),
+		Proposer:            proposer,
+		IsProposer:          proposer == c.address,
+		QuorumVotePower:     committee.Quorum(),
+		RoundStates:         getRoundState(c),
+		Prevotes:            append([]interfaces.PrevoteRecord(nil), c.prevoteRecords...),
+		CurHeightMessages:   len(c.messages.All()),
+		FutureRoundMessages: futureRoundMessages,
+		HeightStart:         c.newHeight,
+		RoundStart:          c.newRound,
+		StepStart:           c.stepChange,
+	}
+}
+
+// publishSnapshot publishes the consensus state for the readers outside of the main loop. It must only be
+// called by the main loop, at the step transitions and on the update of the valid value: the vote power
+// tallies are the ones of the last publication.
+func (c *Core) publishSnapshot() {
+	c.stateMu.RLock()
+	ready := c.height != nil && c.committee != nil
+	c.stateMu.RUnlock()
+	if !ready || c.messages == nil {
+		return
+	}
+	snapshot := c.snapshot()
+	c.published.Store(&snapshot)
+}
+
+// PublishedSnapshot returns the consensus state as last published by the main loop. It does not go through
+// the main loop, so that it is served even if the main loop is busy or stuck. It returns false if no state
+// was published yet.
+func (c *Core) PublishedSnapshot() (StateSnapshot, bool) {
+	snapshot := c.published.Load()
+	if snapshot == nil {
+		return StateSnapshot{}, false
+	}
+	return *snapshot, true
+}
+
+func (c *Core) handleSnapshot(e snapshotRequestEvent) {
+	e.snapshotChan <- c.snapshot()
+}
+
+func (c *Core) handleSupportBundle(e supportBundleRequestEvent) {
+	e.bundleChan <- interfaces.SupportBundle{
+		Snapshot:        c.snapshot(),
+		PendingProposal: c.pendingProposalState(),
+	}
+}
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/metrics"
+)
+
+// useStateGauges replaces the consensus state gauges, which are no-ops when the metrics are disabled, for
+// the duration of the test.
+func useStateGauges(t *testing.T) {
+	gauges := []*metrics.Gauge{
+		&StateHeightGauge, &StateRoundGauge, &StateStepGauge, &StateLockedRoundGauge, &StateValidRoundGauge,
+		&StateMessagesGauge, &StateFutureMessagesGauge, &StateStepDurationGauge,
+	}
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	previous := make([]metrics.Gauge, len(gauges))
+	for i, g := range gauges {
+		previous[i] = *g
+		*g = metrics.NewGauge()
+	}
+	metrics.Enabled = enabled
+	t.Cleanup(func() {
+		for i, g := range gauges {
+			*g = previous[i]
+		}
+	})
+}
+
+// observe returns the snapshots observed by the state dump and the support bundle, and checks that the state
+// gauges were refreshed with the same values.
+func observe(t *testing.T, c *Core) (StateSnapshot, StateSnapshot) {
+	stateEvent := StateRequestEvent{StateChan: make(chan interfaces.CoreState)}
+	go c.handleStateDump(stateEvent)
+	state := <-stateEvent.StateChan
+	require.Equal(t, state.Snapshot.Height, state.Height.Uint64())
+	require.Equal(t, state.Snapshot.Round, state.Round)
+	require.Equal(t, state.Snapshot.Step, state.Step)
+	require.Len(t, state.CurHeightMessages, state.Snapshot.CurHeightMessages)
+	require.Len(t, state.BacklogMessages, state.Snapshot.FutureRoundMessages)
+
+	bundleEvent := supportBundleRequestEvent{bundleChan: make(chan interfaces.SupportBundle)}
+	go c.handleSupportBundle(bundleEvent)
+	bundle := <-bundleEvent.bundleChan
+	_, err := json.Marshal(bundle)
+	require.NoError(t, err)
+
+	updateStateMetrics(c.snapshot())
+	s := state.Snapshot
+	require.Equal(t, int64(s.Height), StateHeightGauge.Value())
+	require.Equal(t, s.Round, StateRoundGauge.Value())
+	require.Equal(t, int64(s.Step), StateStepGauge.Value())
+	require.Equal(t, s.LockedRound, StateLockedRoundGauge.Value())
+	require.Equal(t, s.ValidRound, StateValidRoundGauge.Value())
+	require.Equal(t, int64(s.CurHeightMessages), StateMessagesGauge.Value())
+	require.Equal(t, int64(s.FutureRoundMessages), StateFutureMessagesGauge.Value())
+
+	return state.Snapshot, bundle.Snapshot
+}
+
+func TestStateSnapshot(t *testing.T) {
+	useStateGauges(t)
+	e := NewConsensusEnv(t, func(e *ConsensusENV) {
+		e.step = Propose
+	})
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().KnownMsgHash().AnyTimes()
+	backendMock.EXPECT().FutureMsgs().AnyTimes()
+	e.setupCore(backendMock, e.clientAddress)
+	c := e.core
+	ctx := context.Background()
+
+	height := e.curHeight.Uint64()
+	proposal := generateBlockProposal(e.curRound, e.curHeight, -1, false, signer(e, e.curRound), member(e, e.curRound))
+	value := proposal.Block().Hash()
+
+	steps := []struct {
+		name   string
+		script func()
+		check  func(t *testing.T, s StateSnapshot)
+	}{
+		{
+			name:   "new height",
+			script: func() {},
+			check: func(t *testing.T, s StateSnapshot) {
+				require.Equal(t, height, s.Height)
+				require.Equal(t, uint64(Propose), s.Step)
+				require.Equal(t, 0, s.CurHeightMessages)
+				require.Nil(t, s.LockedValue)
+			},
+		},
+		{
+			name: "proposal received",
+			script: func() {
+				c.curRoundMessages.SetProposal(proposal, true)
+				c.SetStep(ctx, Prevote)
+			},
+			check: func(t *testing.T, s StateSnapshot) {
+				require.Equal(t, uint64(Prevote), s.Step)
+				require.Equal(t, 1, s.CurHeightMessages)
+				require.Len(t, s.RoundStates, 1)
+				require.Equal(t, value, s.RoundStates[0].Proposal)
+			},
+		},
+		{
+			name: "prevote quorum, locked",
+			script: func() {
+				for i := 0; i < e.committeeSize; i++ {
+					c.curRoundMessages.AddPrevote(message.NewPrevote(e.curRound, height, value, signer(e, int64(i)), member(e, int64(i)), e.committeeSize))
+				}
+				c.SetLockedRound(e.curRound)
+				c.SetLockedValue(proposal.Block())
+				c.SetValidRound(e.curRound)
+				c.SetValidValue(proposal.Block())
+				c.SetStep(ctx, Precommit)
+			},
+			check: func(t *testing.T, s StateSnapshot) {
+				require.Equal(t, uint64(Precommit), s.Step)
+				require.Equal(t, e.curRound, s.LockedRound)
+				require.Equal(t, value, *s.LockedValue)
+				require.Equal(t, value, *s.ValidValue)
+				// the prevotes for the same value are aggregated
+				require.Equal(t, 2, s.CurHeightMessages)
+			},
+		},
+		{
+			name: "future round messages",
+			script: func() {
+				future := message.NewPrevote(e.curRound+1, height, value, signer(e, 1), member(e, 1), e.committeeSize)
+				c.futureRound[e.curRound+1] = append(c.futureRound[e.curRound+1], future)
+			},
+			check: func(t *testing.T, s StateSnapshot) {
+				require.Equal(t, 1, s.FutureRoundMessages)
+			},
+		},
+		{
+			name: "precommit quorum",
+			script: func() {
+				for i := 0; i < e.committeeSize; i++ {
+					c.curRoundMessages.AddPrecommit(message.NewPrecommit(e.curRound, height, value, signer(e, int64(i)), member(e, int64(i)), e.committeeSize))
+				}
+				c.SetStep(ctx, PrecommitDone)
+			},
+			check: func(t *testing.T, s StateSnapshot) {
+				require.Equal(t, uint64(PrecommitDone), s.Step)
+				require.Equal(t, 3, s.CurHeightMessages)
+				require.Len(t, s.RoundStates[0].PrecommitState, 1)
+				require.Equal(t, value, s.RoundStates[0].PrecommitState[0].Value)
+			},
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.script()
+			dumped, bundled := observe(t, c)
+			require.Equal(t, dumped, bundled)
+			require.Equal(t, dumped, c.snapshot())
+			step.check(t, dumped)
+		})
+	}
+}
+
+func TestPublishedSnapshot(t *testing.T) {
+	e := NewConsensusEnv(t, func(e *ConsensusENV) {
+		e.step = Precommit
+	})
+	nextHeight := e.curHeight.Uint64() + 1
+	proposal := generateBlockProposal(e.curRound, e.curHeight, e.curRound, false, signer(e, e.curRound), member(e, e.curRound))
+	value := proposal.Block().Hash()
+	setCommitteeAndSealOnBlock(t, proposal.Block(), e.committee, e.keys, 1)
+
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().Commit(proposal.Block(), e.curRound, gomock.Any())
+	backendMock.EXPECT().HeadBlock().Return(proposal.Block()).AnyTimes()
+	backendMock.EXPECT().ProcessFutureMsgs(nextHeight).AnyTimes()
+	backendMock.EXPECT().Post(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(signer(e, 0)).AnyTimes()
+	backendMock.EXPECT().SetProposedBlockHash(gomock.Any()).AnyTimes()
+	backendMock.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	e.setupCore(backendMock, e.clientAddress)
+	t.Cleanup(e.core.stopAllTimeouts)
+	c := e.core
+	ctx := context.Background()
+
+	published, ok := c.PublishedSnapshot()
+	require.True(t, ok)
+	require.Equal(t, uint64(Precommit), published.Step)
+
+	// the quorum of precommits commits the proposal, the state is published without going through the main loop
+	c.curRoundMessages.SetProposal(proposal, true)
+	for i := 0; i < e.committeeSize-1; i++ {
+		c.curRoundMessages.AddPrecommit(message.NewPrecommit(e.curRound, e.curHeight.Uint64(), value, signer(e, int64(i)), member(e, int64(i)), e.committeeSize))
+	}
+	last := int64(e.committeeSize - 1)
+	require.NoError(t, c.handleMsg(ctx, message.NewPrecommit(e.curRound, e.curHeight.Uint64(), value, signer(e, last), member(e, last), e.committeeSize)))
+	published, ok = c.PublishedSnapshot()
+	require.True(t, ok)
+	require.Equal(t, e.curHeight.Uint64(), published.Height)
+	require.Equal(t, uint64(PrecommitDone), published.Step)
+	require.Equal(t, c.CommitteeSet().GetProposer(e.curRound).Address, published.Proposer)
+	require.Len(t, published.RoundStates, 1)
+	require.Len(t, published.RoundStates[0].PrecommitState, 1)
+	require.Equal(t, value, published.RoundStates[0].PrecommitState[0].Value)
+	require.True(t, published.RoundStates[0].PrecommitState[0].VotePower.Cmp(c.CommitteeSet().Quorum()) >= 0)
+
+	// the commit event starts the next height
+	c.precommiter.HandleCommit(ctx)
+	published, ok = c.PublishedSnapshot()
+	require.True(t, ok)
+	require.Equal(t, nextHeight, published.Height)
+	require.Equal(t, int64(0), published.Round)
+	require.Equal(t, uint64(Propose), published.Step)
+	require.Equal(t, c.CommitteeSet().GetProposer(0).Address, published.Proposer)
+	require.Equal(t, int64(-1), published.LockedRound)
+	require.Nil(t, published.LockedValue)
+	require.Equal(t, int64(-1), published.ValidRound)
+	require.Nil(t, published.ValidValue)
+	for _, round := range published.RoundStates {
+		require.Empty(t, round.PrevoteState)
+		require.Empty(t, round.PrecommitState)
+	}
+	require.Equal(t, c.snapshot(), published)
+}
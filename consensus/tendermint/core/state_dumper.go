@@ -24,28 +24,30 @@ func (c *Core) CoreState() interfaces.CoreState {
 
 // State Dump is handled in the main loop triggered by an event rather than using RLOCK mutex.
 func (c *Core) handleStateDump(e StateRequestEvent) {
+	snapshot := c.snapshot()
 	state := interfaces.CoreState{
 		Client:            c.address,
+		Snapshot:          snapshot,
 		BlockPeriod:       c.blockPeriod,
 		CurHeightMessages: msgForDump(c.messages.All()),
 		BacklogMessages:   getBacklogMsgs(c),                  // TODO(lorenzo) rename, it is not called backlog anymore
 		FutureMsgs:        msgForDump(c.backend.FutureMsgs()), //TODO(lorenzo) refinements, still needed?
 		// tendermint Core state:
-		Height:      c.Height(),
-		Round:       c.Round(),
-		Step:        uint64(c.step),
-		Proposal:    getProposal(c, c.Round()),
-		LockedValue: getHash(c.lockedValue),
-		LockedRound: c.lockedRound,
-		ValidValue:  getHash(c.validValue),
-		ValidRound:  c.validRound,
+		Height:      new(big.Int).SetUint64(snapshot.Height),
+		Round:       snapshot.Round,
+		Step:        snapshot.Step,
+		Proposal:    getProposal(c, snapshot.Round),
+		LockedValue: snapshot.LockedValue,
+		LockedRound: snapshot.LockedRound,
+		ValidValue:  snapshot.ValidValue,
+		ValidRound:  snapshot.ValidRound,
 
 		// committee state
 		Committee:       c.CommitteeSet().Committee(),
-		Proposer:        c.CommitteeSet().GetProposer(c.Round()).Address,
-		IsProposer:      c.IsProposer(),
-		QuorumVotePower: c.CommitteeSet().Quorum(),
-		RoundStates:     getRoundState(c),
+		Proposer:        snapshot.Proposer,
+		IsProposer:      snapshot.IsProposer,
+		QuorumVotePower: snapshot.QuorumVotePower,
+		RoundStates:     snapshot.RoundStates,
 		// extra state
 		SentProposal:          c.sentProposal,
 		SentPrevote:           c.sentPrevote,
@@ -144,7 +144,7 @@ func (c *Core) onTimeoutPropose(r int64, h *big.Int) {
 	if metrics.Enabled {
 		c.measureMetricsOnTimeOut(msg.Step, r)
 	}
-	c.SendEvent(msg)
+	c.sendTimeout(msg)
 }
 
 func (c *Core) onTimeoutPrevote(r int64, h *big.Int) {
@@ -157,7 +157,7 @@ func (c *Core) onTimeoutPrevote(r int64, h *big.Int) {
 	if metrics.Enabled {
 		c.measureMetricsOnTimeOut(msg.Step, r)
 	}
-	c.SendEvent(msg)
+	c.sendTimeout(msg)
 }
 
 func (c *Core) onTimeoutPrecommit(r int64, h *big.Int) {
@@ -170,13 +170,14 @@ func (c *Core) onTimeoutPrecommit(r int64, h *big.Int) {
 	if metrics.Enabled {
 		c.measureMetricsOnTimeOut(msg.Step, r)
 	}
-	c.SendEvent(msg)
+	c.sendTimeout(msg)
 }
 
 // ///////////// Handle Timeout Functions ///////////////
 func (c *Core) handleTimeoutPropose(ctx context.Context, msg TimeoutEvent) {
 	if msg.HeightWhenCalled.Cmp(c.Height()) == 0 && msg.RoundWhenCalled == c.Round() && c.step == Propose {
 		c.logTimeoutEvent("TimeoutEvent(Propose): Received", "Propose", msg)
+		c.recordPrevote(c.timeoutPrevoteReason(), nil)
 		c.prevoter.SendPrevote(ctx, true)
 		c.SetStep(ctx, Prevote)
 	}
@@ -200,15 +201,18 @@ func (c *Core) handleTimeoutPrecommit(ctx context.Context, msg TimeoutEvent) {
 // ///////////// Calculate Timeout Duration Functions ///////////////
 // The Timeout may need to be changed depending on the Step
 func (c *Core) timeoutPropose(round int64) time.Duration {
-	return InitialProposeTimeout + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*ProposeTimeoutDelta
+	config := c.timeouts()
+	return config.TimeoutProposeBase + time.Duration(c.blockPeriod)*time.Second + time.Duration(round)*config.TimeoutProposeDelta
 }
 
 func (c *Core) timeoutPrevote(round int64) time.Duration {
-	return InitialPrevoteTimeout + time.Duration(round)*PrevoteTimeoutDelta
+	config := c.timeouts()
+	return config.TimeoutPrevoteBase + time.Duration(round)*config.TimeoutPrevoteDelta
 }
 
 func (c *Core) timeoutPrecommit(round int64) time.Duration {
-	return InitialPrecommitTimeout + time.Duration(round)*PrecommitTimeoutDelta
+	config := c.timeouts()
+	return config.TimeoutPrecommitBase + time.Duration(round)*config.TimeoutPrecommitDelta
 }
 
 func (c *Core) logTimeoutEvent(message string, msgType string, timeout TimeoutEvent) {
@@ -96,8 +96,8 @@ func TestHandleTimeoutPrevote(t *testing.T) {
 		}
 		// should send precommit nil
 		mockBackend.EXPECT().Sign(gomock.Any()).DoAndReturn(makeSigner(keys[currentValidator.Address].consensus))
-		mockBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any()).Times(1).Do(
-			func(c types.Committee, msg message.Msg) {
+		mockBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Do(
+			func(_ context.Context, c types.Committee, msg message.Msg) {
 				if msg.Code() != message.PrecommitCode {
 					t.Fatalf("unexpected message code, should be precommit")
 				}
@@ -181,19 +181,15 @@ func TestOnTimeoutPrevote(t *testing.T) {
 		step:             Prevote,
 	}
 	engine.SetDefaultHandlers()
-	mockBackend.EXPECT().Post(gomock.Any()).Times(1).Do(func(ev interface{}) {
-		timeoutEvent, ok := ev.(TimeoutEvent)
-		if !ok {
-			t.Fatalf("could not cast to timeoutevent")
-		}
-		if timeoutEvent.RoundWhenCalled != 2 || timeoutEvent.HeightWhenCalled.Uint64() != 4 {
-			t.Fatalf("bad view")
-		}
-		if timeoutEvent.Step != Prevote {
-			t.Fatalf("bad step")
-		}
-	})
+	subscribeFeeds(t, &engine)
 	engine.onTimeoutPrevote(2, big.NewInt(4))
+	timeoutEvent := <-engine.feeds.timeoutCh
+	if timeoutEvent.RoundWhenCalled != 2 || timeoutEvent.HeightWhenCalled.Uint64() != 4 {
+		t.Fatalf("bad view")
+	}
+	if timeoutEvent.Step != Prevote {
+		t.Fatalf("bad step")
+	}
 }
 
 func TestOnTimeoutPrecommit(t *testing.T) {
@@ -213,17 +209,13 @@ func TestOnTimeoutPrecommit(t *testing.T) {
 		messages:         messages,
 	}
 	engine.SetDefaultHandlers()
-	mockBackend.EXPECT().Post(gomock.Any()).Times(1).Do(func(ev interface{}) {
-		timeoutEvent, ok := ev.(TimeoutEvent)
-		if !ok {
-			t.Fatalf("could not cast to timeoutevent")
-		}
-		if timeoutEvent.RoundWhenCalled != 2 || timeoutEvent.HeightWhenCalled.Uint64() != 4 {
-			t.Fatalf("bad view")
-		}
-		if timeoutEvent.Step != Precommit {
-			t.Fatalf("bad step")
-		}
-	})
+	subscribeFeeds(t, &engine)
 	engine.onTimeoutPrecommit(2, big.NewInt(4))
+	timeoutEvent := <-engine.feeds.timeoutCh
+	if timeoutEvent.RoundWhenCalled != 2 || timeoutEvent.HeightWhenCalled.Uint64() != 4 {
+		t.Fatalf("bad view")
+	}
+	if timeoutEvent.Step != Precommit {
+		t.Fatalf("bad step")
+	}
 }
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"time"
+
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/timingdump"
+)
+
+// heightTiming accumulates the consensus timing of the current height for the timing dump.
+type heightTiming struct {
+	propose    time.Duration
+	prevote    time.Duration
+	precommit  time.Duration
+	proposals  uint64
+	prevotes   uint64
+	precommits uint64
+}
+
+// TimingDump returns the dumper of the consensus timing of the committed heights.
+func (c *Core) TimingDump() *timingdump.Dumper {
+	return c.timingDump
+}
+
+// timeStep accounts for the time spent in the step being left.
+func (c *Core) timeStep(elapsed time.Duration) {
+	switch c.step {
+	case Propose:
+		c.timing.propose += elapsed
+	case Prevote:
+		c.timing.prevote += elapsed
+	case Precommit:
+		c.timing.precommit += elapsed
+	}
+}
+
+// countMessage accounts for an admitted message of the current height.
+func (c *Core) countMessage(msg message.Msg) {
+	switch msg.(type) {
+	case *message.Propose:
+		c.timing.proposals++
+	case *message.Prevote:
+		c.timing.prevotes++
+	case *message.Precommit:
+		c.timing.precommits++
+	}
+}
+
+// dumpTiming hands the timing of the height committed in round over to the timing dump.
+func (c *Core) dumpTiming(proposal *message.Propose, round int64, commit time.Duration) {
+	if c.timingDump == nil || !c.timingDump.Active() {
+		return
+	}
+	reasons, invalidClass := c.prevoteReasonCounts()
+	c.timingDump.Add(&timingdump.Row{
+		Height:            proposal.H(),
+		Round:             round,
+		Proposer:          proposal.Signer() == c.address,
+		Propose:           c.timing.propose,
+		Prevote:           c.timing.prevote,
+		Precommit:         c.timing.precommit,
+		Commit:            commit,
+		Proposals:         c.timing.proposals,
+		Prevotes:          c.timing.prevotes,
+		Precommits:        c.timing.precommits,
+		PrevoteValue:      reasons[interfaces.PrevoteValue],
+		PrevoteNoProposal: reasons[interfaces.PrevoteNoProposal],
+		PrevoteTimeout:    reasons[interfaces.PrevoteTimeout],
+		PrevoteInvalid:    reasons[interfaces.PrevoteInvalid],
+		PrevoteLocked:     reasons[interfaces.PrevoteLocked],
+		InvalidClass:      invalidClass,
+	})
+}
@@ -0,0 +1,326 @@
+// Package timingdump writes the consensus timing of each committed height to a CSV file, for the offline
+// analysis of the consensus performance where no metrics collection is available. The rows are buffered
+// and written by a background routine, the file is rotated once it reaches a size cap.
+package timingdump
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/log"
+)
+
+const (
+	// DefaultFlushInterval is the flush interval of a dump started without one.
+	DefaultFlushInterval = 5 * time.Second
+	// DefaultMaxSize is the size cap of the dump file, in bytes, after which it is rotated.
+	DefaultMaxSize = 64 * 1024 * 1024
+	// MaxBackups is the number of rotated files kept, named after the dump file suffixed with .1, .2, ...
+	// from the most recent one.
+	MaxBackups = 5
+	// maxBuffered is the number of rows buffered between two flushes, the rows beyond it are dropped.
+	maxBuffered = 4096
+)
+
+var (
+	ErrDumpActive   = errors.New("consensus timing dump already active")
+	ErrDumpInactive = errors.New("consensus timing dump not active")
+)
+
+// Header is the header of the CSV dump.
+var Header = []string{
+	"height",
+	"round",
+	"proposer",
+	"propose_ms",
+	"prevote_ms",
+	"precommit_ms",
+	"commit_ms",
+	"proposals",
+	"prevotes",
+	"precommits",
+	"prevote_value",
+	"prevote_noproposal",
+	"prevote_timeout",
+	"prevote_invalid",
+	"prevote_locked",
+	"invalid_class",
+}
+
+// Row is the consensus timing of a committed height. The step durations add up the time spent in each
+// step over all the rounds of the height.
+type Row struct {
+	Height     uint64
+	Round      int64 // round of the committed proposal
+	Proposer   bool  // whether the local node proposed the committed block
+	Propose    time.Duration
+	Prevote    time.Duration
+	Precommit  time.Duration
+	Commit     time.Duration // time taken to commit the block to the chain
+	Proposals  uint64
+	Prevotes   uint64
+	Precommits uint64
+	// number of prevotes sent by the local node for the proposal, and nil for each reason, over the rounds
+	PrevoteValue      uint64
+	PrevoteNoProposal uint64
+	PrevoteTimeout    uint64
+	PrevoteInvalid    uint64
+	PrevoteLocked     uint64
+	InvalidClass      string // error class of the last proposal prevoted nil as invalid, empty if none
+}
+
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// record returns the CSV record of the row, matching Header.
+func (r *Row) record() []string {
+	return []string{
+		strconv.FormatUint(r.Height, 10),
+		strconv.FormatInt(r.Round, 10),
+		strconv.FormatBool(r.Proposer),
+		milliseconds(r.Propose),
+		milliseconds(r.Prevote),
+		milliseconds(r.Precommit),
+		milliseconds(r.Commit),
+		strconv.FormatUint(r.Proposals, 10),
+		strconv.FormatUint(r.Prevotes, 10),
+		strconv.FormatUint(r.Precommits, 10),
+		strconv.FormatUint(r.PrevoteValue, 10),
+		strconv.FormatUint(r.PrevoteNoProposal, 10),
+		strconv.FormatUint(r.PrevoteTimeout, 10),
+		strconv.FormatUint(r.PrevoteInvalid, 10),
+		strconv.FormatUint(r.PrevoteLocked, 10),
+		r.InvalidClass,
+	}
+}
+
+// Dumper dumps the rows to a CSV file while it is active. It is safe for concurrent use.
+type Dumper struct {
+	logger log.Logger
+
+	mu      sync.Mutex
+	active  bool
+	buffer  []*Row
+	dropped uint64
+	file    *file
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+// New creates an inactive dumper.
+func New(logger log.Logger) *Dumper {
+	return &Dumper{logger: logger}
+}
+
+// Start starts dumping the rows to the CSV file at path, appended to it if it exists. The rows are written
+// every flushInterval, DefaultFlushInterval if zero, and the file is rotated once it exceeds maxSize bytes.
+func (d *Dumper) Start(path string, flushInterval time.Duration, maxSize int64) error {
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.active {
+		return ErrDumpActive
+	}
+	f, err := openFile(path, maxSize)
+	if err != nil {
+		return err
+	}
+	d.active = true
+	d.file = f
+	d.buffer = nil
+	d.dropped = 0
+	d.quit = make(chan struct{})
+	d.done = make(chan struct{})
+	go d.loop(flushInterval, d.quit, d.done)
+	d.logger.Info("Consensus timing dump started", "path", path, "flushInterval", flushInterval, "maxSize", maxSize)
+	return nil
+}
+
+// Stop flushes the buffered rows and stops the dump.
+func (d *Dumper) Stop() error {
+	d.mu.Lock()
+	if !d.active {
+		d.mu.Unlock()
+		return ErrDumpInactive
+	}
+	d.active = false
+	quit, done := d.quit, d.done
+	d.mu.Unlock()
+
+	close(quit)
+	<-done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.flush()
+	if closeErr := d.file.close(); err == nil {
+		err = closeErr
+	}
+	d.logger.Info("Consensus timing dump stopped", "path", d.file.path, "dropped", d.dropped)
+	d.file = nil
+	return err
+}
+
+// Active returns whether the rows are dumped.
+func (d *Dumper) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active
+}
+
+// Add buffers a row until the next flush. It is a no-op if the dump is not active.
+func (d *Dumper) Add(row *Row) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active {
+		return
+	}
+	if len(d.buffer) >= maxBuffered {
+		d.dropped++
+		return
+	}
+	d.buffer = append(d.buffer, row)
+}
+
+func (d *Dumper) loop(flushInterval time.Duration, quit <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.mu.Lock()
+			if err := d.flush(); err != nil {
+				d.logger.Warn("Failed to flush the consensus timing dump", "path", d.file.path, "err", err)
+			}
+			d.mu.Unlock()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// flush writes the buffered rows, d.mu must be held.
+func (d *Dumper) flush() error {
+	rows := d.buffer
+	d.buffer = nil
+	for _, row := range rows {
+		if err := d.file.write(row); err != nil {
+			return err
+		}
+	}
+	return d.file.flush()
+}
+
+// file is a CSV dump file rotated at a size cap.
+type file struct {
+	path    string
+	maxSize int64
+	size    int64
+	f       *os.File
+	w       *bufio.Writer
+}
+
+func openFile(path string, maxSize int64) (*file, error) {
+	f := &file{path: path, maxSize: maxSize}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the dump file, and writes the header if it is empty.
+func (f *file) open() error {
+	fd, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	f.f, f.w, f.size = fd, bufio.NewWriter(fd), info.Size()
+	if f.size == 0 {
+		if err := f.writeRecord(Header); err != nil {
+			return err
+		}
+		return f.flush()
+	}
+	return nil
+}
+
+func (f *file) write(row *Row) error {
+	record := encode(row.record())
+	// a file holds at least one row past the header, however small the cap
+	if f.size+int64(len(record)) > f.maxSize && f.size > int64(len(encode(Header))) {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.w.WriteString(record)
+	f.size += int64(n)
+	return err
+}
+
+func (f *file) writeRecord(record []string) error {
+	n, err := f.w.WriteString(encode(record))
+	f.size += int64(n)
+	return err
+}
+
+// rotate closes the dump file, shifts the rotated files and opens a new dump file.
+func (f *file) rotate() error {
+	if err := f.close(); err != nil {
+		return err
+	}
+	for i := MaxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backupPath(f.path, i), backupPath(f.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, backupPath(f.path, 1)); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *file) flush() error {
+	return f.w.Flush()
+}
+
+func (f *file) close() error {
+	if err := f.w.Flush(); err != nil {
+		f.f.Close()
+		return err
+	}
+	return f.f.Close()
+}
+
+// backupPath returns the path of the i-th most recent rotated file.
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// encode encodes a CSV record. The fields are numbers and booleans, they never need to be quoted.
+func encode(record []string) string {
+	var b []byte
+	for i, field := range record {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, field...)
+	}
+	return string(append(b, '\n'))
+}
@@ -0,0 +1,98 @@
+package timingdump
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/log"
+)
+
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestDumper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timing.csv")
+	d := New(log.Root())
+	require.ErrorIs(t, d.Stop(), ErrDumpInactive)
+
+	// the rows added while inactive are not dumped
+	d.Add(&Row{Height: 1})
+	require.NoError(t, d.Start(path, time.Hour, 0))
+	require.ErrorIs(t, d.Start(path, time.Hour, 0), ErrDumpActive)
+
+	d.Add(&Row{
+		Height:     2,
+		Round:      1,
+		Proposer:   true,
+		Propose:    1500 * time.Microsecond,
+		Prevote:    2 * time.Millisecond,
+		Precommit:  3 * time.Millisecond,
+		Commit:     4 * time.Millisecond,
+		Proposals:  2,
+		Prevotes:   8,
+		Precommits: 7,
+
+		PrevoteValue:      1,
+		PrevoteNoProposal: 2,
+		PrevoteInvalid:    1,
+		InvalidClass:      "invalid",
+	})
+	d.Add(&Row{Height: 3})
+	// the rows are buffered until the next flush
+	require.Equal(t, [][]string{Header}, readCSV(t, path))
+	require.NoError(t, d.Stop())
+
+	require.Equal(t, [][]string{
+		Header,
+		{"2", "1", "true", "1.500", "2.000", "3.000", "4.000", "2", "8", "7", "1", "2", "0", "1", "0", "invalid"},
+		{"3", "0", "false", "0.000", "0.000", "0.000", "0.000", "0", "0", "0", "0", "0", "0", "0", "0", ""},
+	}, readCSV(t, path))
+
+	// a restarted dump appends to the file, and flushes on the interval
+	require.NoError(t, d.Start(path, 10*time.Millisecond, 0))
+	d.Add(&Row{Height: 4})
+	require.Eventually(t, func() bool {
+		return len(readCSV(t, path)) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, d.Stop())
+	require.Equal(t, "4", readCSV(t, path)[3][0])
+}
+
+func TestDumperRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timing.csv")
+	d := New(log.Root())
+	// a tiny cap holds a single row per file
+	require.NoError(t, d.Start(path, time.Hour, 1))
+	for h := uint64(1); h <= MaxBackups+3; h++ {
+		d.Add(&Row{Height: h})
+	}
+	require.NoError(t, d.Stop())
+
+	// the most recent rows are kept, the oldest rotated files are dropped
+	height := uint64(MaxBackups + 3)
+	for i := 0; i <= MaxBackups; i++ {
+		p := path
+		if i > 0 {
+			p = backupPath(path, i)
+		}
+		records := readCSV(t, p)
+		require.Len(t, records, 2, p)
+		require.Equal(t, Header, records[0])
+		require.Equal(t, strconv.FormatUint(height, 10), records[1][0])
+		height--
+	}
+	_, err := os.Stat(backupPath(path, MaxBackups+1))
+	require.True(t, os.IsNotExist(err))
+}
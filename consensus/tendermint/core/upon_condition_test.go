@@ -17,6 +17,7 @@ import (
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/events"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/crypto/blst"
@@ -117,7 +118,7 @@ func TestStartRound(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), proposal)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), proposal)
 		backendMock.EXPECT().HeadBlock().Return(e.previousValue)
 		backendMock.EXPECT().Post(gomock.Any()).Times(1)
 		backendMock.EXPECT().ProcessFutureMsgs(e.previousHeight.Uint64() + 1).Times(1)
@@ -147,7 +148,7 @@ func TestStartRound(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(e.clientSigner)
 		backendMock.EXPECT().SetProposedBlockHash(proposal.Block().Hash())
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), proposal)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), proposal)
 		backendMock.EXPECT().Post(gomock.Any()).Times(1)
 
 		e.setupCore(backendMock, e.clientAddress)
@@ -203,12 +204,13 @@ func TestStartRound(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(e.clientSigner)
-		backendMock.EXPECT().Post(TimeoutEvent{RoundWhenCalled: e.curRound, HeightWhenCalled: e.curHeight, Step: Propose})
 		e.setupCore(backendMock, e.clientAddress)
+		subscribeFeeds(t, e.core)
 		assert.False(t, e.core.proposeTimeout.TimerStarted())
 		e.core.prevoteTimeout.ScheduleTimeout(timeoutDuration, e.core.Round(), e.core.Height(), e.core.onTimeoutPropose)
 		assert.True(t, e.core.prevoteTimeout.TimerStarted())
 		time.Sleep(sleepDuration)
+		assert.Equal(t, TimeoutEvent{RoundWhenCalled: e.curRound, HeightWhenCalled: e.curHeight, Step: Propose}, <-e.core.feeds.timeoutCh)
 		e.checkState(t, e.curHeight, e.curRound, PrecommitDone, nil, int64(-1), nil, int64(-1))
 	})
 	t.Run("at reception of proposal Timeout event prevote nil is sent", func(t *testing.T) {
@@ -224,7 +226,7 @@ func TestStartRound(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(e.clientSigner)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 
 		e.setupCore(backendMock, e.clientAddress)
 		e.core.handleTimeoutPropose(context.Background(), timeoutE)
@@ -256,7 +258,7 @@ func TestNewProposal(t *testing.T) {
 
 		backendMock.EXPECT().VerifyProposal(invalidProposal.Block()).Return(time.Duration(1), errors.New("invalid proposal"))
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 
 		err := e.core.handleMsg(context.Background(), invalidProposal)
 		assert.Error(t, err, "expected an error for invalid proposal")
@@ -277,7 +279,7 @@ func TestNewProposal(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
 		backendMock.EXPECT().VerifyProposal(proposal.Block()).Return(time.Duration(1), nil)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 
 		e.setupCore(backendMock, e.clientAddress)
 		err := e.core.handleMsg(context.Background(), proposal)
@@ -303,7 +305,7 @@ func TestNewProposal(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
 		backendMock.EXPECT().VerifyProposal(e.curProposal.Block()).Return(time.Duration(1), nil)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 
 		e.setupCore(backendMock, e.clientAddress)
 		err := e.core.handleMsg(context.Background(), e.curProposal)
@@ -333,7 +335,7 @@ func TestNewProposal(t *testing.T) {
 		e.setupCore(backendMock, e.clientAddress)
 
 		backendMock.EXPECT().VerifyProposal(e.curProposal.Block()).Return(time.Duration(1), nil)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 
 		err := e.core.handleMsg(context.Background(), e.curProposal)
 		assert.NoError(t, err)
@@ -371,7 +373,7 @@ func TestOldProposal(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).AnyTimes().DoAndReturn(e.clientSigner)
 		backendMock.EXPECT().VerifyProposal(e.curProposal.Block()).Return(time.Duration(1), nil)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 
 		e.setupCore(backendMock, e.clientAddress)
 		e.core.curRoundMessages = e.core.messages.GetOrCreate(e.curRound)
@@ -414,7 +416,7 @@ func TestOldProposal(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
 		backendMock.EXPECT().VerifyProposal(e.curProposal.Block()).Return(time.Duration(1), nil)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 		e.setupCore(backendMock, e.clientAddress)
 		fakePrevote := message.Fake{
 			FakeValue:   e.curProposal.Block().Hash(),
@@ -458,7 +460,7 @@ func TestOldProposal(t *testing.T) {
 		e.core.messages.GetOrCreate(e.curProposal.ValidRound()).AddPrevote(fakePrevote)
 
 		backendMock.EXPECT().VerifyProposal(e.curProposal.Block()).Return(time.Duration(0), nil)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
 
 		err := e.core.handleMsg(context.Background(), e.curProposal)
 		assert.NoError(t, err)
@@ -585,7 +587,7 @@ func TestOldProposal(t *testing.T) {
 		e.core.proposeTimeout.ScheduleTimeout(1*time.Second, e.core.Round(), e.core.Height(), e.core.onTimeoutPropose)
 
 		backendMock.EXPECT().VerifyProposal(e.curProposal.Block()).Return(time.Duration(1), nil)
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), prevoteMsgToBroadcast)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsgToBroadcast)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
 
 		// now we handle new round's proposal with round_p > vr on value v.
@@ -614,11 +616,12 @@ func TestProposeTimeout(t *testing.T) {
 	t.Run("propose Timeout is not stopped if the proposal does not cause a step change", func(t *testing.T) {
 		customizer := func(e *ConsensusENV) {
 			e.step = Propose
+			e.curRound = 1
 		}
 		e := NewConsensusEnv(t, customizer)
 
-		// proposal with vr > r
-		proposal := generateBlockProposal(e.curRound, e.curHeight, e.curRound+1, false, signer(e, e.curRound), member(e, e.curRound))
+		// proposal with vr < r, without the quorum of prevotes for vr
+		proposal := generateBlockProposal(e.curRound, e.curHeight, e.curRound-1, false, signer(e, e.curRound), member(e, e.curRound))
 
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -733,13 +736,14 @@ func TestPrevoteTimeout(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		e.setupCore(backendMock, e.clientAddress)
+		subscribeFeeds(t, e.core)
 
 		assert.False(t, e.core.prevoteTimeout.TimerStarted())
-		backendMock.EXPECT().Post(TimeoutEvent{RoundWhenCalled: e.curRound, HeightWhenCalled: e.curHeight, Step: Prevote})
 		e.core.prevoteTimeout.ScheduleTimeout(timeoutDuration, e.core.Round(), e.core.Height(), e.core.onTimeoutPrevote)
 		assert.True(t, e.core.prevoteTimeout.TimerStarted())
 		e.checkState(t, e.curHeight, e.curRound, Prevote, e.lockedValue, e.lockedRound, e.validValue, e.validRound)
 		time.Sleep(sleepDuration)
+		assert.Equal(t, TimeoutEvent{RoundWhenCalled: e.curRound, HeightWhenCalled: e.curHeight, Step: Prevote}, <-e.core.feeds.timeoutCh)
 	})
 	t.Run("at reception of prevote Timeout event precommit nil is sent", func(t *testing.T) {
 		customizer := func(e *ConsensusENV) {
@@ -755,7 +759,7 @@ func TestPrevoteTimeout(t *testing.T) {
 		backendMock := interfaces.NewMockBackend(ctrl)
 		e.setupCore(backendMock, e.clientAddress)
 
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), precommitMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), precommitMsg)
 		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
 
 		e.core.handleTimeoutPrevote(context.Background(), timeoutE)
@@ -797,7 +801,7 @@ func TestQuorumPrevote(t *testing.T) {
 		e.core.curRoundMessages.AddPrevote(message.NewFakePrevote(fakePrevote))
 
 		if e.step == Prevote {
-			backendMock.EXPECT().Broadcast(e.committee.Committee(), precommitMsg)
+			backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), precommitMsg)
 			err := e.core.handleMsg(context.Background(), prevoteMsg)
 			assert.NoError(t, err)
 			assert.Equal(t, e.curProposal.Block(), e.core.lockedValue)
@@ -843,7 +847,7 @@ func TestQuorumPrevote(t *testing.T) {
 
 		// receive first prevote to increase the total to quorum
 		if e.step == Prevote {
-			backendMock.EXPECT().Broadcast(e.committee.Committee(), precommitMsg)
+			backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), precommitMsg)
 			err := e.core.handleMsg(context.Background(), prevoteMsg1)
 			assert.NoError(t, err)
 			assert.Equal(t, e.curProposal.Block(), e.core.lockedValue)
@@ -896,7 +900,7 @@ func TestQuorumPrevoteNil(t *testing.T) {
 		FakeSignature: testSignature,                // whatever signature is fine
 	}
 	e.core.curRoundMessages.AddPrevote(message.NewFakePrevote(fakePrevote))
-	backendMock.EXPECT().Broadcast(e.committee.Committee(), precommitMsg)
+	backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), precommitMsg)
 
 	err := e.core.handleMsg(context.Background(), prevoteMsg)
 	assert.NoError(t, err)
@@ -1049,13 +1053,14 @@ func TestPrecommitTimeout(t *testing.T) {
 
 		backendMock := interfaces.NewMockBackend(ctrl)
 		e.setupCore(backendMock, e.clientAddress)
+		subscribeFeeds(t, e.core)
 		assert.False(t, e.core.precommitTimeout.TimerStarted())
-		backendMock.EXPECT().Post(TimeoutEvent{RoundWhenCalled: e.curRound, HeightWhenCalled: e.curHeight, Step: Precommit})
 		e.core.precommitTimeout.ScheduleTimeout(timeoutDuration, e.core.Round(), e.core.Height(), e.core.onTimeoutPrecommit)
 		assert.True(t, e.core.precommitTimeout.TimerStarted())
 		e.checkState(t, e.curHeight, e.curRound, e.step, e.lockedValue, e.lockedRound, e.validValue, e.validRound)
 
 		time.Sleep(sleepDuration)
+		assert.Equal(t, TimeoutEvent{RoundWhenCalled: e.curRound, HeightWhenCalled: e.curHeight, Step: Precommit}, <-e.core.feeds.timeoutCh)
 	})
 	t.Run("at reception of precommit Timeout event next round will be started", func(t *testing.T) {
 		customizer := func(e *ConsensusENV) {
@@ -1144,7 +1149,7 @@ func TestQuorumPrecommit(t *testing.T) {
 		t.Log("is proposer")
 		e.core.pendingCandidateBlocks[nextHeight] = nextProposalMsg.Block()
 		backendMock.EXPECT().SetProposedBlockHash(nextProposalMsg.Block().Hash())
-		backendMock.EXPECT().Broadcast(e.committee.Committee(), nextProposalMsg)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), nextProposalMsg)
 	}
 
 	// It is hard to control tendermint's state machine if we construct the full backend since it overwrites the
@@ -1154,6 +1159,74 @@ func TestQuorumPrecommit(t *testing.T) {
 	e.checkState(t, big.NewInt(int64(nextHeight)), int64(0), Propose, e.lockedValue, e.lockedRound, e.validValue, e.validRound)
 }
 
+// A precommit quorum for a different value at the same height can only happen with more than 1/3 of byzantine
+// voting power. In this case the proposal must not be committed and the height must be halted.
+func TestConflictingQuorumPrecommit(t *testing.T) {
+	customizer := func(e *ConsensusENV) {
+		e.curRound = 1
+		e.step = Precommit
+	}
+	e := NewConsensusEnv(t, customizer)
+
+	proposal := generateBlockProposal(e.curRound, e.curHeight, e.curRound, false, signer(e, e.curRound), member(e, e.curRound))
+	precommit := message.NewPrecommit(e.curRound, e.curHeight.Uint64(), proposal.Block().Hash(), signer(e, 1), member(e, 1), e.committeeSize)
+	setCommitteeAndSealOnBlock(t, proposal.Block(), e.committee, e.keys, 1)
+
+	ctrl := gomock.NewController(t)
+	defer waitForExpects(ctrl)
+
+	backendMock := interfaces.NewMockBackend(ctrl)
+	e.setupCore(backendMock, e.clientAddress)
+	e.core.curRoundMessages.SetProposal(proposal, true)
+
+	// quorum for the proposal at the current round, once precommit is received
+	quorumPrecommitMsg := message.NewFakePrecommit(message.Fake{
+		FakeValue:     proposal.Block().Hash(),
+		FakeSigners:   signersWithPower(2, e.committeeSize, new(big.Int).Sub(e.core.CommitteeSet().Quorum(), common.Big1)),
+		FakeSignerKey: testConsensusKey.PublicKey(), // whatever key is fine
+		FakeSignature: testSignature,                // whatever signature is fine
+	})
+	e.core.curRoundMessages.AddPrecommit(quorumPrecommitMsg)
+
+	// (artificial) quorum for a different value at a previous round
+	conflictingValue := common.HexToHash("0xc0ffee")
+	conflictingPrecommitMsg := message.NewFakePrecommit(message.Fake{
+		FakeValue:     conflictingValue,
+		FakeSigners:   signersWithPower(0, e.committeeSize, e.core.CommitteeSet().Quorum()),
+		FakeSignerKey: testConsensusKey.PublicKey(),
+		FakeSignature: testSignature,
+	})
+	e.core.messages.GetOrCreate(0).AddPrecommit(conflictingPrecommitMsg)
+
+	backendMock.EXPECT().Commit(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	backendMock.EXPECT().Post(gomock.AssignableToTypeOf(events.PowerChangeEvent{})).AnyTimes()
+	backendMock.EXPECT().Post(events.ConflictingQuorumEvent{
+		Height:           e.curHeight.Uint64(),
+		Round:            e.curRound,
+		Value:            proposal.Block().Hash(),
+		ConflictingRound: 0,
+		ConflictingValue: conflictingValue,
+	}).Times(1)
+
+	err := e.core.handleMsg(context.Background(), precommit)
+	require.NoError(t, err)
+	e.checkState(t, e.curHeight, e.curRound, PrecommitDone, e.lockedValue, e.lockedRound, e.validValue, e.validRound)
+
+	rejected := e.core.RejectedProposals()
+	require.Len(t, rejected, 1)
+	require.Equal(t, e.curHeight.Uint64(), rejected[0].Height)
+	require.Equal(t, e.curRound, rejected[0].Round)
+	require.Equal(t, proposal, rejected[0].Proposal)
+	require.ErrorIs(t, rejected[0].Reason, constants.ErrConflictingQuorum)
+	require.Len(t, rejected[0].Evidence, 2)
+	require.Equal(t, proposal.Block().Hash(), rejected[0].Evidence[0].Value())
+	require.Equal(t, conflictingValue, rejected[0].Evidence[1].Value())
+
+	// the height is closed, further messages are discarded
+	err = e.core.handleMsg(context.Background(), precommit)
+	require.ErrorIs(t, err, constants.ErrHeightClosed)
+}
+
 // The following tests aim to test lines 49 - 54 of Tendermint Algorithm described on page 6 of
 // https://arxiv.org/pdf/1807.04938.pdf.
 func TestFutureRoundChange(t *testing.T) {
@@ -1228,6 +1301,56 @@ func TestFutureRoundChange(t *testing.T) {
 		e.checkState(t, e.curHeight, e.curRound, e.step, e.lockedValue, e.lockedRound, e.validValue, e.validRound)
 		assert.Equal(t, 2, len(e.core.futureRound[futureRound]))
 	})
+
+	t.Run("skip several rounds ahead and prevote for the buffered proposal of the new round", func(t *testing.T) {
+		customizer := func(e *ConsensusENV) {
+			e.step = Propose
+		}
+		e := NewConsensusEnv(t, customizer)
+		futureRound := int64(5)
+		proposer := e.committee.GetProposer(futureRound)
+		proposal := generateBlockProposal(futureRound, e.curHeight, -1, false, makeSigner(e.keys[proposer.Address].consensus), &proposer)
+		prevoteMsg := message.NewPrevote(futureRound, e.curHeight.Uint64(), proposal.Block().Hash(), e.clientSigner, e.clientMember, e.committeeSize)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		backendMock := interfaces.NewMockBackend(ctrl)
+		backendMock.EXPECT().Post(gomock.Any()).AnyTimes()
+		backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner)
+		backendMock.EXPECT().VerifyProposal(proposal.Block()).Return(time.Duration(1), nil)
+		backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevoteMsg)
+		e.setupCore(backendMock, e.clientAddress)
+		subscribeFeeds(t, e.core)
+		defer e.core.stopAllTimeouts()
+
+		// the proposal of round 5 and the nil prevotes of the other members are buffered until they amount to more than F
+		buffered := []message.Msg{proposal}
+		require.ErrorIs(t, e.core.handleMsg(context.Background(), proposal), constants.ErrFutureRoundMessage)
+		power := new(big.Int).Set(proposer.VotingPower)
+		for i := 1; power.Cmp(e.committee.F()) <= 0; i++ {
+			if member(e, int64(i)).Address == proposer.Address {
+				continue
+			}
+			require.Equal(t, e.curRound, e.core.Round())
+			prevote := message.NewPrevote(futureRound, e.curHeight.Uint64(), common.Hash{}, signer(e, int64(i)), member(e, int64(i)), e.committeeSize)
+			require.ErrorIs(t, e.core.handleMsg(context.Background(), prevote), constants.ErrFutureRoundMessage)
+			buffered = append(buffered, prevote)
+			power.Add(power, member(e, int64(i)).VotingPower)
+		}
+		e.checkState(t, e.curHeight, futureRound, Propose, nil, -1, nil, -1)
+
+		// the buffered messages are queued back to the core, which prevotes for the proposal
+		for range buffered {
+			select {
+			case backlogEvent := <-e.core.feeds.backlogCh:
+				require.NoError(t, e.core.handleMsg(context.Background(), backlogEvent.msg))
+			case <-time.After(time.Second):
+				t.Fatal("buffered message not queued back")
+			}
+		}
+		e.checkState(t, e.curHeight, futureRound, Prevote, nil, -1, nil, -1)
+		require.True(t, e.core.sentPrevote)
+	})
 }
 
 func setCommitteeAndSealOnBlock(t *testing.T, b *types.Block, c interfaces.Committee, keys AddressKeyMap, signerIndex int) {
@@ -1310,6 +1433,7 @@ func (e *ConsensusENV) setupCore(backend interfaces.Backend, address common.Addr
 	e.core = New(backend, nil, address, log.Root(), false)
 	e.core.setCommitteeSet(e.committee)
 	e.core.setHeight(e.curHeight)
+	e.core.committedHeight = e.curHeight.Uint64() - 1
 	e.core.setLastHeader(&types.Header{Committee: e.committee.Committee(), Number: new(big.Int).SetUint64(e.curHeight.Uint64() - 1)})
 	e.core.setRound(e.curRound)
 	e.core.SetValidRound(e.validRound)
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/core/types"
+)
+
+// verificationStatus is the outcome of the verification of a proposed value.
+type verificationStatus uint8
+
+const (
+	verificationUnknown verificationStatus = iota // never verified, or verification to retry
+	verificationValid
+	verificationInvalid
+)
+
+// proposalVerification is the outcome of the verification of a proposed value, with the first error
+// failing it.
+type proposalVerification struct {
+	status verificationStatus
+	err    error
+}
+
+// proposalVerifications caches the outcome of the verification of the values proposed at the current
+// height, keyed by value hash. A value proposed again in a later round, or checked again for a quorum
+// of precommits, is verified once: the block execution and the clock-dependent checks are not repeated.
+// It is reset on height change and accessed by the main thread only.
+type proposalVerifications map[common.Hash]proposalVerification
+
+// retryableVerificationError reports whether a proposal failing the verification with err may pass it
+// later, so that its value can't be deemed invalid yet.
+func retryableVerificationError(err error) bool {
+	return errors.Is(err, consensus.ErrFutureTimestampBlock) ||
+		errors.Is(err, consensus.ErrPrunedAncestor) ||
+		errors.Is(err, consensus.ErrUnknownAncestor)
+}
+
+// verifyProposal verifies the proposed block with the backend, unless its value was already verified
+// at this height. The returned duration is the one of a proposal from the future, it is zero for the
+// cached outcomes.
+func (c *Core) verifyProposal(block *types.Block) (time.Duration, error) {
+	hash := block.Hash()
+	switch cached := c.verifications[hash]; cached.status {
+	case verificationValid:
+		return 0, nil
+	case verificationInvalid:
+		return 0, cached.err
+	}
+
+	duration, err := c.backend.VerifyProposal(block)
+	if err != nil && retryableVerificationError(err) {
+		return duration, err
+	}
+	if c.verifications == nil {
+		c.verifications = make(proposalVerifications)
+	}
+	if err != nil {
+		c.verifications[hash] = proposalVerification{status: verificationInvalid, err: err}
+	} else {
+		c.verifications[hash] = proposalVerification{status: verificationValid}
+	}
+	return duration, err
+}
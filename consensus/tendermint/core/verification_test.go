@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
+	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+)
+
+func newVerificationCore(t *testing.T) (*ConsensusENV, *interfaces.MockBackend) {
+	e := NewConsensusEnv(t, func(e *ConsensusENV) {
+		e.step = Propose
+	})
+	ctrl := gomock.NewController(t)
+	backendMock := interfaces.NewMockBackend(ctrl)
+	backendMock.EXPECT().Sign(gomock.Any()).DoAndReturn(e.clientSigner).AnyTimes()
+	e.setupCore(backendMock, e.clientAddress)
+	return e, backendMock
+}
+
+// expectPrevote expects the client to prevote for value in round.
+func expectPrevote(e *ConsensusENV, backendMock *interfaces.MockBackend, round int64, value common.Hash) {
+	prevote := message.NewPrevote(round, e.curHeight.Uint64(), value, e.clientSigner, e.clientMember, e.committeeSize)
+	backendMock.EXPECT().Broadcast(gomock.Any(), e.committee.Committee(), prevote)
+}
+
+// nextRound moves the core to the propose step of the next round of the height.
+func nextRound(e *ConsensusENV) {
+	e.core.setInitialState(e.core.Round() + 1)
+	e.core.SetStep(context.Background(), Propose)
+}
+
+func TestProposalVerificationCache(t *testing.T) {
+	t.Run("value proposed again in a later round is verified once", func(t *testing.T) {
+		e, backendMock := newVerificationCore(t)
+		block := generateBlock(e.curHeight)
+
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil).Times(1)
+		expectPrevote(e, backendMock, 0, block.Hash())
+		expectPrevote(e, backendMock, 1, block.Hash())
+
+		require.NoError(t, e.core.handleMsg(context.Background(), message.NewPropose(0, e.curHeight.Uint64(), -1, block, signer(e, 0), member(e, 0))))
+		nextRound(e)
+		require.NoError(t, e.core.handleMsg(context.Background(), message.NewPropose(1, e.curHeight.Uint64(), -1, block, signer(e, 1), member(e, 1))))
+		require.Equal(t, Prevote, e.core.step)
+	})
+
+	t.Run("invalid value proposed again is rejected without verification", func(t *testing.T) {
+		e, backendMock := newVerificationCore(t)
+		block := generateBlock(e.curHeight)
+		errInvalid := errors.New("invalid proposal")
+
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), errInvalid).Times(1)
+		expectPrevote(e, backendMock, 0, common.Hash{})
+		expectPrevote(e, backendMock, 1, common.Hash{})
+
+		err := e.core.handleMsg(context.Background(), message.NewPropose(0, e.curHeight.Uint64(), -1, block, signer(e, 0), member(e, 0)))
+		require.ErrorIs(t, err, errInvalid)
+		nextRound(e)
+		err = e.core.handleMsg(context.Background(), message.NewPropose(1, e.curHeight.Uint64(), -1, block, signer(e, 1), member(e, 1)))
+		require.ErrorIs(t, err, errInvalid)
+		require.Equal(t, Prevote, e.core.step)
+	})
+
+	t.Run("value failing with a retryable error is verified again", func(t *testing.T) {
+		e, backendMock := newVerificationCore(t)
+		block := generateBlock(e.curHeight)
+		proposal := message.NewPropose(0, e.curHeight.Uint64(), -1, block, signer(e, 0), member(e, 0))
+
+		gomock.InOrder(
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Hour, consensus.ErrFutureTimestampBlock),
+			backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil),
+		)
+		expectPrevote(e, backendMock, 0, block.Hash())
+		expectPrevote(e, backendMock, 1, block.Hash())
+
+		err := e.core.handleMsg(context.Background(), proposal)
+		require.ErrorIs(t, err, consensus.ErrFutureTimestampBlock)
+		(&Proposer{e.core}).StopFutureProposalTimer()
+		require.Equal(t, verificationUnknown, e.core.verifications[block.Hash()].status)
+
+		// the proposal is handled again once its timestamp is reached
+		require.NoError(t, e.core.handleMsg(context.Background(), proposal))
+		nextRound(e)
+		require.NoError(t, e.core.handleMsg(context.Background(), message.NewPropose(1, e.curHeight.Uint64(), -1, block, signer(e, 1), member(e, 1))))
+	})
+
+	t.Run("verification outcomes are reset on height change", func(t *testing.T) {
+		e, backendMock := newVerificationCore(t)
+		block := generateBlock(e.curHeight)
+		setCommitteeAndSealOnBlock(t, block, e.committee, e.keys, 0)
+
+		backendMock.EXPECT().VerifyProposal(block).Return(time.Duration(0), nil).Times(1)
+		backendMock.EXPECT().HeadBlock().Return(block)
+		expectPrevote(e, backendMock, 0, block.Hash())
+
+		require.NoError(t, e.core.handleMsg(context.Background(), message.NewPropose(0, e.curHeight.Uint64(), -1, block, signer(e, 0), member(e, 0))))
+		require.Equal(t, verificationValid, e.core.verifications[block.Hash()].status)
+
+		e.core.setInitialState(0)
+		require.Empty(t, e.core.verifications)
+	})
+}
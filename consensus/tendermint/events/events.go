@@ -21,6 +21,7 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
 	"github.com/autonity/autonity/core/types"
 )
 
@@ -36,6 +37,7 @@ type UnverifiedMessageEvent struct {
 	ErrCh   chan<- error
 	Sender  common.Address
 	Posted  time.Time
+	Traces  msgtrace.Traces // set if the message is sampled for tracing
 }
 
 // MessageEvent is posted from the aggregator to core and the fault detector
@@ -43,6 +45,7 @@ type MessageEvent struct {
 	Message message.Msg
 	ErrCh   chan<- error
 	Posted  time.Time
+	Traces  msgtrace.Traces // set if the message is sampled for tracing, or aggregates sampled votes
 }
 
 // old messages are posted only to the fault detector
@@ -79,6 +82,18 @@ type FuturePowerChangeEvent struct {
 
 type SyncEvent struct {
 	Addr common.Address
+	// Request lists the messages the peer already holds, it is empty for a full sync.
+	Request []byte
+}
+
+// ConflictingQuorumEvent is posted when precommit quorums for two different values are
+// detected at the same height. Consensus is halted for the height and nothing is committed.
+type ConflictingQuorumEvent struct {
+	Height           uint64
+	Round            int64
+	Value            common.Hash
+	ConflictingRound int64
+	ConflictingValue common.Hash
 }
 
 type AccountabilityEvent struct {
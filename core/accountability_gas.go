@@ -0,0 +1,53 @@
+package core
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/params"
+)
+
+// accountabilityGasStats returns the runs of the accountability precompiled contracts recorded in the
+// state during the execution of a block.
+func accountabilityGasStats(statedb *state.StateDB) *types.AccountabilityGasStats {
+	stats := new(types.AccountabilityGasStats)
+	for addr, runs := range statedb.PrecompileRuns() {
+		switch addr {
+		case params.CheckAccusationAddress:
+			stats.Accusation.Merge(*runs)
+		case params.CheckInnocenceAddress:
+			stats.Innocence.Merge(*runs)
+		case params.CheckMisbehaviourAddress:
+			stats.Misbehaviour.Merge(*runs)
+		}
+	}
+	return stats
+}
+
+// writeAccountabilityGasStats indexes the accountability gas statistics of a block executed by the node.
+// The index is built going forward only, from the first block executed.
+func (bc *BlockChain) writeAccountabilityGasStats(batch ethdb.KeyValueWriter, block *types.Block, statedb *state.StateDB) {
+	if stats := accountabilityGasStats(statedb); !stats.Empty() {
+		rawdb.WriteAccountabilityGasStats(batch, block.Hash(), block.NumberU64(), stats)
+	}
+	if bc.accountabilityGasStart.Load() == 0 {
+		rawdb.WriteAccountabilityGasStart(batch, block.NumberU64())
+	}
+}
+
+// AccountabilityGasStart returns the first block indexed by the accountability gas statistics, zero
+// if no block was indexed yet.
+func (bc *BlockChain) AccountabilityGasStart() uint64 {
+	return bc.accountabilityGasStart.Load()
+}
+
+// AccountabilityGasStats returns the accountability gas statistics of a block indexed. The statistics
+// are empty for the blocks without any run of the accountability precompiled contracts.
+func (bc *BlockChain) AccountabilityGasStats(hash common.Hash, number uint64) *types.AccountabilityGasStats {
+	if stats := rawdb.ReadAccountabilityGasStats(bc.db, hash, number); stats != nil {
+		return stats
+	}
+	return new(types.AccountabilityGasStats)
+}
@@ -97,7 +97,7 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
     // Validate the state root against the received state root and throw
     // an error if they don't match.
     if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number)); header.Root != root {
-        return fmt.Errorf("invalid merkle root (remote: %x local: %x)", header.Root, root)
+        return fmt.Errorf("%w (remote: %x local: %x)", ErrStateRootMismatch, header.Root, root)
     }
     return nil
 }
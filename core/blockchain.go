@@ -54,6 +54,7 @@ var (
 	headBlockGauge     = metrics.NewRegisteredGauge("chain/head/block", nil)
 	headHeaderGauge    = metrics.NewRegisteredGauge("chain/head/header", nil)
 	headFastBlockGauge = metrics.NewRegisteredGauge("chain/head/receipt", nil)
+	importBacklogGauge = metrics.NewRegisteredGauge("chain/import/backlog", nil)
 
 	accountReadTimer   = metrics.NewRegisteredTimer("chain/account/reads", nil)
 	accountHashTimer   = metrics.NewRegisteredTimer("chain/account/hashes", nil)
@@ -140,6 +141,9 @@ type CacheConfig struct {
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 
+	CommitJournalEntries uint64        // Maximum number of entries of the commit journal, 0 to disable it
+	CommitJournalAge     time.Duration // Maximum age of the commit journal entries relative to the head block, 0 for no limit
+
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
 
@@ -183,15 +187,16 @@ type BlockChain struct {
 	//  * nil: disable tx reindexer/deleter, but still index new blocks
 	txLookupLimit uint64
 
-	hc            *HeaderChain
-	rmLogsFeed    event.Feed
-	chainFeed     event.Feed
-	chainSideFeed event.Feed
-	chainHeadFeed event.Feed
-	logsFeed      event.Feed
-	blockProcFeed event.Feed
-	scope         event.SubscriptionScope
-	genesisBlock  *types.Block
+	hc                *HeaderChain
+	rmLogsFeed        event.Feed
+	chainFeed         event.Feed
+	chainSideFeed     event.Feed
+	chainHeadFeed     event.Feed
+	logsFeed          event.Feed
+	blockProcFeed     event.Feed
+	stateMismatchFeed event.Feed
+	scope             event.SubscriptionScope
+	genesisBlock      *types.Block
 
 	// This mutex synchronizes chain write operations.
 	// Readers don't need to take it, they can just read the database.
@@ -212,6 +217,8 @@ type BlockChain struct {
 	quit          chan struct{}  // shutdown signal, closed in Stop.
 	running       int32          // 0 if chain is running, 1 when stopped
 	procInterrupt int32          // interrupt signaler for block processing
+	importPaused  atomic.Bool    // the free disk space is critically low, see PauseWrites
+	importBacklog atomic.Int64   // blocks handed to the chain whose import did not return yet
 
 	engine     consensus.Engine
 	validator  Validator // Block and state validator interface
@@ -221,6 +228,13 @@ type BlockChain struct {
 	vmConfig   vm.Config
 
 	protocolContracts *autonity.ProtocolContracts
+	commitJournal     *CommitJournal // nil if disabled
+
+	// accountabilityGasStart is the first block indexed by the accountability gas statistics, zero if none
+	accountabilityGasStart atomic.Uint64
+
+	// internalCallTracer traces the protocol contract calls made by the node itself, when enabled
+	internalCallTracer *InternalCallTracer
 
 	// senderCacher is a concurrent transaction sender recoverer and cacher
 	senderCacher *TxSenderCacher
@@ -271,8 +285,11 @@ func NewBlockChain(db ethdb.Database,
 		engine:        engine,
 		vmConfig:      vmConfig,
 		senderCacher:  senderCacher,
+		commitJournal: NewCommitJournal(db, cacheConfig.CommitJournalEntries, cacheConfig.CommitJournalAge),
 		log:           log,
 	}
+	bc.accountabilityGasStart.Store(rawdb.ReadAccountabilityGasStart(db))
+	bc.internalCallTracer = NewInternalCallTracer(DefaultInternalCallTraces, bc.protocolContractMethod)
 	bc.forker = NewForkChoice(bc, shouldPreserve)
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
@@ -780,6 +797,9 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
+	// Journal the block in the same batch, so that a committed block is never missing from the journal
+	bc.commitJournal.append(batch, block)
+
 	// Flush the whole batch into the disk, exit the node if failed
 	if err := batch.Write(); err != nil {
 		bc.log.Crit("Failed to update chain indexes and markers", "err", err)
@@ -905,6 +925,9 @@ const (
 // InsertReceiptChain attempts to complete an already existing header chain with
 // transaction and receipt data.
 func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain []types.Receipts, ancientLimit uint64) (int, error) {
+	if bc.importPaused.Load() {
+		return 0, ErrDiskSpaceCritical
+	}
 	// We don't require the chainMu here since we want to maximize the
 	// concurrency of header insertion and receipt insertion.
 	bc.wg.Add(1)
@@ -1223,9 +1246,11 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	bc.writeAccountabilityGasStats(blockBatch, block, state)
 	if err := blockBatch.Write(); err != nil {
 		bc.log.Crit("Failed to write block into disk", "err", err)
 	}
+	bc.accountabilityGasStart.CompareAndSwap(0, block.NumberU64())
 	// Commit all cached state changes into underlying memory database.
 	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number()))
 	if err != nil {
@@ -1288,6 +1313,10 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 
 // WriteBlockWithState writes the block and all associated state to the database.
 func (bc *BlockChain) WriteBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
+	if bc.importPaused.Load() {
+		return NonStatTy, ErrDiskSpaceCritical
+	}
+	defer bc.trackImport(1)()
 	if !bc.chainmu.TryLock() {
 		return NonStatTy, errChainStopped
 	}
@@ -1372,6 +1401,9 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	if len(chain) == 0 {
 		return 0, nil
 	}
+	if bc.importPaused.Load() {
+		return 0, ErrDiskSpaceCritical
+	}
 	bc.blockProcFeed.Send(true)
 	defer bc.blockProcFeed.Send(false)
 
@@ -1391,6 +1423,7 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 		}
 	}
 	// Pre-checks passed, start the full block imports
+	defer bc.trackImport(len(chain))()
 	if !bc.chainmu.TryLock() {
 		return 0, errChainStopped
 	}
@@ -1398,6 +1431,21 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	return bc.insertChain(chain, true, true)
 }
 
+// ImportBacklog returns the number of blocks handed to the chain for import, by InsertChain or
+// WriteBlockAndSetHead, whose import did not complete yet, including the ones waiting for the imports
+// in progress.
+func (bc *BlockChain) ImportBacklog() int {
+	return int(bc.importBacklog.Load())
+}
+
+// trackImport counts n blocks in the import backlog, until the returned function is called.
+func (bc *BlockChain) trackImport(n int) func() {
+	importBacklogGauge.Update(bc.importBacklog.Add(int64(n)))
+	return func() {
+		importBacklogGauge.Update(bc.importBacklog.Add(-int64(n)))
+	}
+}
+
 // insertChain is the internal implementation of InsertChain, which assumes that
 // 1) chains are contiguous, and 2) The chain mutex is held.
 //
@@ -1650,6 +1698,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 
 		if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
 			bc.reportBlock(block, receipts, err)
+			if errors.Is(err, ErrStateRootMismatch) {
+				bc.stateMismatchFeed.Send(StateMismatchEvent{Block: block, Err: err})
+			}
 			atomic.StoreUint32(&followupInterrupt, 1)
 			return it.index, err
 		}
@@ -2108,6 +2159,9 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 // updating. It relies on the additional SetChainHead call to finalize the entire
 // procedure.
 func (bc *BlockChain) InsertBlockWithoutSetHead(block *types.Block) error {
+	if bc.importPaused.Load() {
+		return ErrDiskSpaceCritical
+	}
 	if !bc.chainmu.TryLock() {
 		return errChainStopped
 	}
@@ -2328,6 +2382,9 @@ Error: %v
 // of the header retrieval mechanisms already need to verify nonces, as well as
 // because nonces can be verified sparsely, not needing to check each.
 func (bc *BlockChain) InsertHeaderChain(chain []*types.Header, checkFreq int) (int, error) {
+	if bc.importPaused.Load() {
+		return 0, ErrDiskSpaceCritical
+	}
 	start := time.Now()
 	if i, err := bc.hc.ValidateHeaderChain(chain, checkFreq); err != nil {
 		return i, err
@@ -2354,3 +2411,26 @@ func (bc *BlockChain) HasBadBlock(hash common.Hash) bool {
 func (bc *BlockChain) ProtocolContracts() *autonity.ProtocolContracts {
 	return bc.protocolContracts
 }
+
+// CommitJournal returns the journal of the committed blocks, nil if disabled.
+func (bc *BlockChain) CommitJournal() *CommitJournal {
+	return bc.commitJournal
+}
+
+// InternalCallTracer returns the tracer of the protocol contract calls made by the node itself.
+func (bc *BlockChain) InternalCallTracer() *InternalCallTracer {
+	return bc.internalCallTracer
+}
+
+// protocolContractMethod resolves the methods of the autonity contract, whose ABI can be upgraded.
+// The calls to the other contracts are traced by selector.
+func (bc *BlockChain) protocolContractMethod(contract common.Address, selector []byte) (string, bool) {
+	if contract != params.AutonityContractAddress || bc.protocolContracts == nil {
+		return "", false
+	}
+	method, err := bc.protocolContracts.ABI().MethodById(selector)
+	if err != nil {
+		return "", false
+	}
+	return method.RawName, true
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+    "context"
     "math/big"
 
     "github.com/autonity/autonity/common"
@@ -79,6 +80,13 @@ func (bc *BlockChain) GetHeadersFrom(number, count uint64) []rlp.RawValue {
     return bc.hc.GetHeadersFrom(number, count)
 }
 
+// HeaderRangeIterator returns an iterator over the canonical headers in [from, to],
+// going backwards if from is greater than to, which reads from both the freezer
+// and the key-value store without loading the block bodies.
+func (bc *BlockChain) HeaderRangeIterator(ctx context.Context, from, to uint64) *rawdb.HeaderIterator {
+    return bc.hc.HeaderRangeIterator(ctx, from, to)
+}
+
 // GetBody retrieves a block body (transactions and uncles) from the database by
 // hash, caching it if found.
 func (bc *BlockChain) GetBody(hash common.Hash) *types.Body {
@@ -376,6 +384,11 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
     return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeStateMismatchEvent registers a subscription of StateMismatchEvent.
+func (bc *BlockChain) SubscribeStateMismatchEvent(ch chan<- StateMismatchEvent) event.Subscription {
+    return bc.scope.Track(bc.stateMismatchFeed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Subscription {
     return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
@@ -18,6 +18,8 @@ package core
 
 import (
     "context"
+    "encoding/binary"
+    "fmt"
     "time"
 
     "github.com/autonity/autonity/common"
@@ -26,6 +28,7 @@ import (
     "github.com/autonity/autonity/core/rawdb"
     "github.com/autonity/autonity/core/types"
     "github.com/autonity/autonity/ethdb"
+    "github.com/autonity/autonity/params"
 )
 
 const (
@@ -56,6 +59,74 @@ func NewBloomIndexer(db ethdb.Database, size, confirms uint64) *ChainIndexer {
     return NewChainIndexer(db, table, backend, size, confirms, bloomThrottling, "bloombits")
 }
 
+// OpenBloomIndexer returns a chain indexer that generates bloom bits data for the
+// canonical chain with the given section size, along with the generations of the
+// index. If the index was generated with another section size, its sections stay
+// readable as a past generation and the new one starts at the first boundary of
+// the given size they don't cover.
+func OpenBloomIndexer(db ethdb.Database, size, confirms uint64) (*ChainIndexer, []rawdb.BloomGeneration) {
+    generations := bloomGenerations(db, size)
+    rawdb.WriteBloomGenerations(db, generations)
+
+    current := generations[len(generations)-1]
+    backend := &BloomIndexer{
+        db:   db,
+        size: size,
+    }
+    indexer := NewChainIndexer(db, bloomIndexTable(db, current), backend, size, confirms, bloomThrottling, "bloombits")
+    if current.Start > 0 {
+        // The blocks before the generation are never processed again, the past
+        // generations serve them.
+        indexer.AddCheckpoint(current.Start/size-1, rawdb.ReadCanonicalHash(db, current.Start-1))
+    }
+    return indexer, generations
+}
+
+// bloomGenerations returns the generations of the bloombits index, ending with
+// the one of the given section size.
+func bloomGenerations(db ethdb.Database, size uint64) []rawdb.BloomGeneration {
+    generations := rawdb.ReadBloomGenerations(db)
+    if legacy := (rawdb.BloomGeneration{SectionSize: params.BloomBitsBlocks}); generations == nil && bloomIndexedSections(db, legacy) > 0 {
+        // Index generated before the generations were tracked
+        generations = []rawdb.BloomGeneration{legacy}
+    }
+    for len(generations) > 0 {
+        last := generations[len(generations)-1]
+        if last.SectionSize == size {
+            return generations
+        }
+        // Start the new generation at the last boundary of its section size the
+        // previous one indexed, dropping the previous one if it has nothing left.
+        indexed := bloomIndexedSections(db, last) * last.SectionSize
+        if start := indexed / size * size; start > last.Start {
+            return append(generations, rawdb.BloomGeneration{Start: start, SectionSize: size})
+        }
+        generations = generations[:len(generations)-1]
+    }
+    return []rawdb.BloomGeneration{{SectionSize: size}}
+}
+
+// bloomIndexTable returns the table tracking the progress of the chain indexer of
+// a bloombits generation. The index generated before the generations were tracked
+// keeps its table.
+func bloomIndexTable(db ethdb.Database, generation rawdb.BloomGeneration) ethdb.Database {
+    prefix := string(rawdb.BloomBitsIndexPrefix)
+    if generation.Start != 0 || generation.SectionSize != params.BloomBitsBlocks {
+        prefix += fmt.Sprintf("-%d-%d-", generation.Start, generation.SectionSize)
+    }
+    return rawdb.NewTable(db, prefix)
+}
+
+// bloomIndexedSections returns the number of sections a bloombits generation
+// stored, counted from the genesis.
+func bloomIndexedSections(db ethdb.Database, generation rawdb.BloomGeneration) uint64 {
+    data, _ := bloomIndexTable(db, generation).Get([]byte("count"))
+    if len(data) != 8 {
+        return 0
+    }
+    return binary.BigEndian.Uint64(data)
+}
+
 // Reset implements core.ChainIndexerBackend, starting a new bloombits index
 // section.
 func (b *BloomIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/params"
+)
+
+// setBloomIndexedSections overrides the number of sections a bloombits generation stored.
+func setBloomIndexedSections(db ethdb.Database, generation rawdb.BloomGeneration, sections uint64) {
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], sections)
+	bloomIndexTable(db, generation).Put([]byte("count"), data[:])
+}
+
+func TestBloomGenerations(t *testing.T) {
+	var (
+		legacy  = rawdb.BloomGeneration{SectionSize: params.BloomBitsBlocks}
+		second  = rawdb.BloomGeneration{Start: 65536, SectionSize: 32768}
+		third   = rawdb.BloomGeneration{Start: 98304, SectionSize: 4096}
+		current = func(db ethdb.Database, size uint64) []rawdb.BloomGeneration {
+			generations := bloomGenerations(db, size)
+			rawdb.WriteBloomGenerations(db, generations)
+			return generations
+		}
+	)
+	t.Run("fresh database", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		if got, want := current(db, 32768), []rawdb.BloomGeneration{{SectionSize: 32768}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("generations %v, want %v", got, want)
+		}
+	})
+	t.Run("legacy index too short to keep", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		setBloomIndexedSections(db, legacy, 3)
+		if got, want := current(db, 32768), []rawdb.BloomGeneration{{SectionSize: 32768}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("generations %v, want %v", got, want)
+		}
+	})
+	t.Run("section size changes", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		setBloomIndexedSections(db, legacy, 20)
+		if got, want := current(db, params.BloomBitsBlocks), []rawdb.BloomGeneration{legacy}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("generations %v, want %v", got, want)
+		}
+		// The new generation starts at the last boundary of its size the legacy index covers
+		if got, want := current(db, 32768), []rawdb.BloomGeneration{legacy, second}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("generations %v, want %v", got, want)
+		}
+		if got, want := current(db, 32768), []rawdb.BloomGeneration{legacy, second}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("reopened generations %v, want %v", got, want)
+		}
+		// Switching back before the new generation indexed anything drops it
+		setBloomIndexedSections(db, second, 2)
+		if got, want := current(db, params.BloomBitsBlocks), []rawdb.BloomGeneration{legacy}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("generations %v, want %v", got, want)
+		}
+		if got, want := current(db, 32768), []rawdb.BloomGeneration{legacy, second}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("generations %v, want %v", got, want)
+		}
+		// Switching back after it indexed some sections keeps it
+		setBloomIndexedSections(db, second, 3)
+		if got, want := current(db, params.BloomBitsBlocks), []rawdb.BloomGeneration{legacy, second, third}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("generations %v, want %v", got, want)
+		}
+	})
+}
+
+func TestOpenBloomIndexerNewGeneration(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	setBloomIndexedSections(db, rawdb.BloomGeneration{SectionSize: params.BloomBitsBlocks}, 20)
+
+	head := common.Hash{1}
+	rawdb.WriteCanonicalHash(db, head, 65535)
+
+	indexer, generations := OpenBloomIndexer(db, 32768, params.BloomConfirms)
+	defer indexer.Close()
+
+	if want := []rawdb.BloomGeneration{{SectionSize: params.BloomBitsBlocks}, {Start: 65536, SectionSize: 32768}}; !reflect.DeepEqual(generations, want) {
+		t.Fatalf("generations %v, want %v", generations, want)
+	}
+	if stored := rawdb.ReadBloomGenerations(db); !reflect.DeepEqual(stored, generations) {
+		t.Fatalf("stored generations %v, want %v", stored, generations)
+	}
+	// The blocks before the generation are never indexed with the new section size
+	sections, last, lastHead := indexer.Sections()
+	if sections != 2 || last != 65535 || lastHead != head {
+		t.Fatalf("sections %d, last block %d %x, want 2, 65535 %x", sections, last, lastHead, head)
+	}
+}
+
+// BenchmarkBloomIndexer1sBlocks measures the bloombits indexing overhead of a
+// chain producing a block per second, with the default section size tuned for 13
+// seconds blocks and with the one derived from the block period.
+func BenchmarkBloomIndexer1sBlocks(b *testing.B) {
+	headers := make([]*types.Header, 2*params.MaxBloomBitsBlocks)
+	for i := range headers {
+		var bloom types.Bloom
+		for j := 0; j < 4; j++ {
+			bloom.Add(common.BigToHash(big.NewInt(rand.Int63())).Bytes())
+		}
+		headers[i] = &types.Header{Number: big.NewInt(int64(i)), Bloom: bloom}
+	}
+	for _, size := range []uint64{params.BloomBitsBlocks, 32768} {
+		size := size
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			var written, sections int
+			for i := 0; i < b.N; i++ {
+				db := rawdb.NewMemoryDatabase()
+				backend := &BloomIndexer{db: db, size: size}
+				for section := uint64(0); section < uint64(len(headers))/size; section++ {
+					if err := backend.Reset(context.Background(), section, common.Hash{}); err != nil {
+						b.Fatal(err)
+					}
+					for _, header := range headers[section*size : (section+1)*size] {
+						backend.Process(context.Background(), header)
+					}
+					if err := backend.Commit(); err != nil {
+						b.Fatal(err)
+					}
+					sections++
+				}
+				it := db.NewIterator(nil, nil)
+				for it.Next() {
+					written += len(it.Key()) + len(it.Value())
+				}
+				it.Release()
+				db.Close()
+			}
+			b.ReportMetric(float64(sections*types.BloomBitLength)/float64(b.N), "writes/op")
+			b.ReportMetric(float64(written)/float64(b.N), "bytes/op")
+		})
+	}
+}
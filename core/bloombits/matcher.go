@@ -61,9 +61,10 @@ type partialMatches struct {
 // The contest and error fields are used by the light client to terminate matching
 // early if an error is encountered on some path of the pipeline.
 type Retrieval struct {
-	Bit      uint
-	Sections []uint64
-	Bitsets  [][]byte
+	Bit         uint
+	Sections    []uint64
+	SectionSize uint64 // Number of blocks per section of the requesting matcher
+	Bitsets     [][]byte
 
 	Context context.Context
 	Error   error
@@ -627,7 +628,7 @@ func (s *MatcherSession) Multiplex(batch int, wait time.Duration, mux chan chan
 
 		case mux <- request:
 			// Retrieval accepted, something must arrive before we're aborting
-			request <- &Retrieval{Bit: bit, Sections: sections, Context: s.ctx}
+			request <- &Retrieval{Bit: bit, Sections: sections, SectionSize: s.matcher.sectionSize, Context: s.ctx}
 
 			result := <-request
 			if result.Error != nil {
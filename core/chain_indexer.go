@@ -92,6 +92,7 @@ type ChainIndexer struct {
     checkpointHead     common.Hash // Section head belonging to the checkpoint
 
     throttling time.Duration // Disk throttling to prevent a heavy upgrade from hogging resources
+    paused     atomic.Bool   // Flag whether the section processing is paused, see PauseWrites
 
     log  log.Logger
     lock sync.Mutex
@@ -315,7 +316,11 @@ func (c *ChainIndexer) updateLoop() {
             return
 
         case <-c.update:
-            // Section headers completed (or rolled back), update the index
+            // Section headers completed (or rolled back), update the index, unless
+            // paused: the pending sections are processed on resume
+            if c.paused.Load() {
+                continue
+            }
             c.lock.Lock()
             if c.knownSections > c.storedSections {
                 // Periodically print an upgrade log message to the user
@@ -381,6 +386,21 @@ func (c *ChainIndexer) updateLoop() {
     }
 }
 
+// PauseWrites pauses the processing of the new sections, until ResumeWrites is called.
+func (c *ChainIndexer) PauseWrites() {
+    c.paused.Store(true)
+}
+
+// ResumeWrites resumes the processing of the sections, starting with the ones
+// completed while paused.
+func (c *ChainIndexer) ResumeWrites() {
+    c.paused.Store(false)
+    select {
+    case c.update <- struct{}{}:
+    default:
+    }
+}
+
 // processSection processes an entire section by calling backend functions while
 // ensuring the continuity of the passed headers. Since the chain mutex is not
 // held while processing, the continuity can be broken by a long reorg, in which
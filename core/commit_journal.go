@@ -0,0 +1,151 @@
+package core
+
+import (
+	"time"
+
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+)
+
+// commitJournalPruneLimit bounds the number of entries pruned along with a single head update,
+// so that lowering the cap of a large journal does not stall the chain.
+const commitJournalPruneLimit = 1024
+
+// CommitJournal is a persistent log of the blocks committed to the canonical chain, meant for the
+// downstream services which need a gap-free stream of blocks across restarts. Each entry is given a
+// sequence number, incremented by one from an entry to the next, after which the consumers resume.
+//
+// The entries are written in the same batch as the canonical head marker, so that a committed block
+// is never missing from the journal, even after a crash. The oldest entries are pruned once the count
+// or the age cap is reached.
+type CommitJournal struct {
+	db         ethdb.Database
+	maxEntries uint64
+	maxAge     uint64 // seconds, relative to the time of the head block, no age cap if zero
+	log        log.Logger
+}
+
+// NewCommitJournal creates a commit journal keeping up to maxEntries entries no older than maxAge
+// in db. It returns nil, which journals nothing, if maxEntries is zero.
+func NewCommitJournal(db ethdb.Database, maxEntries uint64, maxAge time.Duration) *CommitJournal {
+	if maxEntries == 0 {
+		return nil
+	}
+	return &CommitJournal{
+		db:         db,
+		maxEntries: maxEntries,
+		maxAge:     uint64(maxAge / time.Second),
+		log:        log.New("module", "commitjournal"),
+	}
+}
+
+// Range returns the sequence numbers of the oldest entry kept and of the next entry to be appended.
+// The sequence numbers start at one.
+func (j *CommitJournal) Range() (first, next uint64) {
+	first, next = rawdb.ReadCommitJournalRange(j.db)
+	if next == 0 {
+		return 1, 1
+	}
+	return first, next
+}
+
+// Entries returns up to limit entries following afterSeq, in order. A first entry with a sequence
+// number above afterSeq+1 means that the entries in between were pruned.
+func (j *CommitJournal) Entries(afterSeq uint64, limit int) []*rawdb.CommitJournalEntry {
+	first, next := j.Range()
+	if afterSeq+1 > first {
+		first = afterSeq + 1
+	}
+	var entries []*rawdb.CommitJournalEntry
+	for seq := first; seq < next && len(entries) < limit; seq++ {
+		entry := rawdb.ReadCommitJournalEntry(j.db, seq)
+		if entry == nil {
+			// pruned after the range was read, the pruning proceeds from the oldest entries
+			if len(entries) == 0 {
+				continue
+			}
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// append journals the block, which is being made the canonical head, into batch. The blocks between
+// the last journaled block and this one are journaled first, from the canonical chain, up to the cap.
+// A block which is not above the last journaled block, as after a rewind of the head, is skipped.
+func (j *CommitJournal) append(batch ethdb.KeyValueWriter, block *types.Block) {
+	if j == nil {
+		return
+	}
+	tail, head := rawdb.ReadCommitJournalRange(j.db)
+	if head == 0 {
+		tail, head = 1, 1
+	}
+	number, from := block.NumberU64(), block.NumberU64()
+	if head > tail {
+		if last := rawdb.ReadCommitJournalEntry(j.db, head-1); last != nil {
+			if number <= last.Number {
+				return
+			}
+			from = last.Number + 1
+		}
+	}
+	if number-from >= j.maxEntries {
+		from = number - j.maxEntries + 1
+	}
+
+	var appended []*rawdb.CommitJournalEntry
+	for n := from; n < number; n++ {
+		header := rawdb.ReadHeader(j.db, rawdb.ReadCanonicalHash(j.db, n), n)
+		if header == nil {
+			j.log.Warn("Missing canonical header, not journaled", "number", n)
+			continue
+		}
+		appended = append(appended, &rawdb.CommitJournalEntry{
+			Seq:        head + uint64(len(appended)),
+			Number:     n,
+			Hash:       header.Hash(),
+			ParentHash: header.ParentHash,
+			Time:       header.Time,
+		})
+	}
+	appended = append(appended, &rawdb.CommitJournalEntry{
+		Seq:        head + uint64(len(appended)),
+		Number:     number,
+		Hash:       block.Hash(),
+		ParentHash: block.ParentHash(),
+		Time:       block.Time(),
+	})
+	for _, entry := range appended {
+		rawdb.WriteCommitJournalEntry(batch, entry)
+	}
+	first := head
+	head += uint64(len(appended))
+
+	// the entries appended above are not readable from the database until the batch is written
+	entry := func(seq uint64) *rawdb.CommitJournalEntry {
+		if seq >= first {
+			return appended[seq-first]
+		}
+		return rawdb.ReadCommitJournalEntry(j.db, seq)
+	}
+	for pruned := 0; tail < head-1 && pruned < commitJournalPruneLimit; pruned++ {
+		if head-tail <= j.maxEntries && !j.expired(entry(tail), block.Time()) {
+			break
+		}
+		rawdb.DeleteCommitJournalEntry(batch, tail)
+		tail++
+	}
+	rawdb.WriteCommitJournalRange(batch, tail, head)
+}
+
+// expired reports whether the entry is older than the age cap at time now. A missing entry is expired.
+func (j *CommitJournal) expired(entry *rawdb.CommitJournalEntry, now uint64) bool {
+	if entry == nil {
+		return true
+	}
+	return j.maxAge > 0 && entry.Time+j.maxAge < now
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+)
+
+// newCommitJournalTestChain generates n blocks on top of the genesis committed to db.
+func newCommitJournalTestChain(db ethdb.Database, n int) []*types.Block {
+	gspec := &Genesis{BaseFee: big.NewInt(params.InitialBaseFee), Config: params.TestChainConfig}
+	gspec.MustCommit(db)
+	genDb := rawdb.NewMemoryDatabase()
+	blocks, _ := GenerateChain(params.TestChainConfig, gspec.MustCommit(genDb), ethash.NewFaker(), genDb, n, nil)
+	return blocks
+}
+
+func newCommitJournalBlockChain(t *testing.T, db ethdb.Database, maxEntries uint64, maxAge time.Duration) *BlockChain {
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.TrieDirtyDisabled = true
+	cacheConfig.CommitJournalEntries = maxEntries
+	cacheConfig.CommitJournalAge = maxAge
+	chain, err := NewBlockChain(db, &cacheConfig, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, NewTxSenderCacher(), nil, FakeContractBackendProvider(t), log.Root())
+	require.NoError(t, err)
+	return chain
+}
+
+// requireJournaled checks that the entries journal the given blocks in order, from the given sequence number.
+func requireJournaled(t *testing.T, entries []*rawdb.CommitJournalEntry, firstSeq uint64, blocks []*types.Block) {
+	t.Helper()
+	require.Len(t, entries, len(blocks))
+	for i, entry := range entries {
+		require.Equal(t, &rawdb.CommitJournalEntry{
+			Seq:        firstSeq + uint64(i),
+			Number:     blocks[i].NumberU64(),
+			Hash:       blocks[i].Hash(),
+			ParentHash: blocks[i].ParentHash(),
+			Time:       blocks[i].Time(),
+		}, entry)
+	}
+}
+
+func TestCommitJournalResume(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	blocks := newCommitJournalTestChain(db, 15)
+
+	chain := newCommitJournalBlockChain(t, db, 100, 0)
+	_, err := chain.InsertChain(blocks[:10])
+	require.NoError(t, err)
+	requireJournaled(t, chain.CommitJournal().Entries(0, 100), 1, blocks[:10])
+	requireJournaled(t, chain.CommitJournal().Entries(0, 3), 1, blocks[:3])
+	chain.Stop()
+
+	// the consumer resumes after the last entry it processed, across the restart of the node
+	chain = newCommitJournalBlockChain(t, db, 100, 0)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks[10:])
+	require.NoError(t, err)
+	requireJournaled(t, chain.CommitJournal().Entries(10, 100), 11, blocks[10:])
+	first, next := chain.CommitJournal().Range()
+	require.Equal(t, uint64(1), first)
+	require.Equal(t, uint64(16), next)
+
+	// the blocks committed again after a rewind of the head are not journaled twice
+	require.NoError(t, chain.SetHead(5))
+	_, err = chain.InsertChain(blocks[5:])
+	require.NoError(t, err)
+	requireJournaled(t, chain.CommitJournal().Entries(0, 100), 1, blocks)
+}
+
+func TestCommitJournalPruning(t *testing.T) {
+	t.Run("count", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		blocks := newCommitJournalTestChain(db, 13)
+		chain := newCommitJournalBlockChain(t, db, 5, 0)
+		_, err := chain.InsertChain(blocks[:12])
+		require.NoError(t, err)
+
+		first, next := chain.CommitJournal().Range()
+		require.Equal(t, uint64(8), first)
+		require.Equal(t, uint64(13), next)
+		// the consumer detects the pruned entries from the first sequence number returned
+		requireJournaled(t, chain.CommitJournal().Entries(2, 100), 8, blocks[7:12])
+		require.Nil(t, rawdb.ReadCommitJournalEntry(db, 7))
+		chain.Stop()
+
+		// a lower cap applies from the next head update
+		chain = newCommitJournalBlockChain(t, db, 2, 0)
+		defer chain.Stop()
+		_, err = chain.InsertChain(blocks[12:])
+		require.NoError(t, err)
+		requireJournaled(t, chain.CommitJournal().Entries(0, 100), 12, blocks[11:])
+	})
+
+	t.Run("age", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		blocks := newCommitJournalTestChain(db, 12)
+		// the generated blocks are 10 seconds apart
+		chain := newCommitJournalBlockChain(t, db, 100, 35*time.Second)
+		defer chain.Stop()
+		_, err := chain.InsertChain(blocks)
+		require.NoError(t, err)
+		requireJournaled(t, chain.CommitJournal().Entries(0, 100), 9, blocks[8:])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		blocks := newCommitJournalTestChain(db, 3)
+		chain := newCommitJournalBlockChain(t, db, 0, 0)
+		defer chain.Stop()
+		_, err := chain.InsertChain(blocks)
+		require.NoError(t, err)
+		require.Nil(t, chain.CommitJournal())
+		tail, head := rawdb.ReadCommitJournalRange(db)
+		require.Zero(t, tail)
+		require.Zero(t, head)
+	})
+}
+
+func TestCommitJournalFillsHeadJumps(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	blocks := newCommitJournalTestChain(db, 10)
+	chain := newCommitJournalBlockChain(t, db, 5, 0)
+	defer chain.Stop()
+	_, err := chain.InsertChain(blocks[:2])
+	require.NoError(t, err)
+
+	// the blocks made canonical without moving the head, as during a snap sync, are journaled
+	// along with the next head update, up to the cap
+	for _, block := range blocks[2:9] {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	batch := db.NewBatch()
+	chain.CommitJournal().append(batch, blocks[9])
+	require.NoError(t, batch.Write())
+	requireJournaled(t, chain.CommitJournal().Entries(0, 100), 3, blocks[5:])
+}
+
+var (
+	testHeadBlockKey          = []byte("LastBlock")
+	testCommitJournalRangeKey = []byte("CommitJournalRange")
+)
+
+// crashDB simulates a crash of the node while the crashAt-th batch moving the head block is
+// written: this batch and all the later writes are dropped.
+type crashDB struct {
+	ethdb.Database
+	crashAt int
+
+	mu       sync.Mutex
+	heads    int
+	crashed  bool
+	unpaired int // batches moving the head block without updating the commit journal
+}
+
+func (db *crashDB) Put(key []byte, value []byte) error {
+	if db.isCrashed() {
+		return nil
+	}
+	return db.Database.Put(key, value)
+}
+
+func (db *crashDB) Delete(key []byte) error {
+	if db.isCrashed() {
+		return nil
+	}
+	return db.Database.Delete(key)
+}
+
+func (db *crashDB) NewBatch() ethdb.Batch {
+	return &crashBatch{Batch: db.Database.NewBatch(), db: db}
+}
+
+func (db *crashDB) isCrashed() bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.crashed
+}
+
+type crashBatch struct {
+	ethdb.Batch
+	db            *crashDB
+	head, journal bool
+}
+
+func (b *crashBatch) Put(key []byte, value []byte) error {
+	b.head = b.head || bytes.Equal(key, testHeadBlockKey)
+	b.journal = b.journal || bytes.Equal(key, testCommitJournalRangeKey)
+	return b.Batch.Put(key, value)
+}
+
+func (b *crashBatch) Write() error {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+	if b.db.crashed {
+		return nil
+	}
+	if b.head {
+		if !b.journal {
+			b.db.unpaired++
+		}
+		if b.db.heads++; b.db.heads == b.db.crashAt {
+			b.db.crashed = true
+			return nil
+		}
+	}
+	return b.Batch.Write()
+}
+
+func (b *crashBatch) Reset() {
+	b.head, b.journal = false, false
+	b.Batch.Reset()
+}
+
+func TestCommitJournalCrash(t *testing.T) {
+	for _, crashAt := range []int{1, 2, 5, 9} {
+		db := rawdb.NewMemoryDatabase()
+		blocks := newCommitJournalTestChain(db, 10)
+
+		crashing := &crashDB{Database: db, crashAt: crashAt}
+		chain := newCommitJournalBlockChain(t, crashing, 100, 0)
+		// the import fails once the writes are dropped
+		_, _ = chain.InsertChain(blocks)
+		chain.Stop()
+		require.True(t, crashing.crashed)
+		require.Zero(t, crashing.unpaired, "head moved without journal update")
+
+		// after the restart, the journal ends with the head block
+		chain = newCommitJournalBlockChain(t, db, 100, 0)
+		head := chain.CurrentBlock().NumberU64()
+		require.Equal(t, uint64(crashAt-1), head, "crash at %d", crashAt)
+		requireJournaled(t, chain.CommitJournal().Entries(0, 100), 1, blocks[:head])
+
+		// and the journal resumes without gap
+		_, err := chain.InsertChain(blocks[head:])
+		require.NoError(t, err)
+		requireJournaled(t, chain.CommitJournal().Entries(0, 100), 1, blocks)
+		chain.Stop()
+	}
+}
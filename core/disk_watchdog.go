@@ -0,0 +1,277 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+	"github.com/autonity/autonity/trie"
+)
+
+// DefaultDiskWatchdogInterval is the interval between two checks of the free disk space.
+const DefaultDiskWatchdogInterval = 10 * time.Second
+
+// ErrDiskSpaceCritical is returned for the blocks submitted for import while the free disk space is
+// below the hard threshold.
+var ErrDiskSpaceCritical = errors.New("free disk space below the hard threshold, block import suspended")
+
+var (
+	diskHealthGauge = metrics.NewRegisteredGauge("chain/disk/health", nil)
+	diskFreeGauge   = metrics.NewRegisteredGauge("chain/disk/free", nil)
+)
+
+// DiskHealth is the health of the file systems holding the chain database.
+type DiskHealth uint32
+
+const (
+	DiskHealthy  DiskHealth = iota
+	DiskDegraded            // below the soft threshold, the non-essential writes are paused
+	DiskCritical            // below the hard threshold, the blocks are no longer imported
+)
+
+func (h DiskHealth) String() string {
+	switch h {
+	case DiskHealthy:
+		return "healthy"
+	case DiskDegraded:
+		return "degraded"
+	case DiskCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// DiskSpaceFunc returns the space available to the node on the file system holding path.
+type DiskSpaceFunc func(path string) (uint64, error)
+
+// PausableWriter is a disk writer paused by the DiskWatchdog while the disk is nearly full. The
+// methods are called from the watchdog routine and must not block.
+type PausableWriter interface {
+	PauseWrites()
+	ResumeWrites()
+}
+
+// DiskWatchdogConfig configures the DiskWatchdog.
+type DiskWatchdogConfig struct {
+	Paths         []string      // chain database and freezer paths
+	SoftThreshold uint64        // free space in bytes below which the non-essential writes are paused, 0 to disable
+	HardThreshold uint64        // free space in bytes below which the blocks are no longer imported, 0 to disable
+	Interval      time.Duration // interval between two checks
+}
+
+// DiskStatus is the state of the DiskWatchdog as of its last check.
+type DiskStatus struct {
+	Health        DiskHealth
+	Available     uint64 // lowest free space of the watched paths
+	SoftThreshold uint64
+	HardThreshold uint64
+	Paused        []string // names of the paused writers
+}
+
+// DiskWatchdog watches the free space of the file systems holding the chain database, so that the
+// node does not run out of space in the middle of a database write. Below the soft threshold, the
+// non-essential writers are paused and the disk health is degraded. Below the hard threshold, the
+// essential writers are paused as well, which stops the block import, and the disk health is
+// critical. The writers are resumed once enough space is freed.
+type DiskWatchdog struct {
+	config DiskWatchdogConfig
+	space  DiskSpaceFunc
+	log    log.Logger
+	health atomic.Uint32
+
+	mu        sync.Mutex
+	writers   []*watchedWriter
+	available uint64
+	failing   bool // the last check could not read the free space
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+type watchedWriter struct {
+	name   string
+	level  DiskHealth // the writer is paused from this health on
+	writer PausableWriter
+	paused bool
+}
+
+// NewDiskWatchdog returns a healthy watchdog, reading the free space with space.
+func NewDiskWatchdog(config DiskWatchdogConfig, space DiskSpaceFunc, logger log.Logger) *DiskWatchdog {
+	if config.Interval <= 0 {
+		config.Interval = DefaultDiskWatchdogInterval
+	}
+	if config.SoftThreshold != 0 && config.SoftThreshold < config.HardThreshold {
+		logger.Warn("Sanitizing disk soft threshold below the hard threshold", "provided", common.StorageSize(config.SoftThreshold), "updated", common.StorageSize(config.HardThreshold))
+		config.SoftThreshold = config.HardThreshold
+	}
+	return &DiskWatchdog{
+		config: config,
+		space:  space,
+		log:    logger,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Register registers a non-essential writer, paused below the soft threshold.
+func (w *DiskWatchdog) Register(name string, writer PausableWriter) {
+	w.register(name, DiskDegraded, writer)
+}
+
+// RegisterEssential registers an essential writer, paused below the hard threshold only.
+func (w *DiskWatchdog) RegisterEssential(name string, writer PausableWriter) {
+	w.register(name, DiskCritical, writer)
+}
+
+func (w *DiskWatchdog) register(name string, level DiskHealth, writer PausableWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ww := &watchedWriter{name: name, level: level, writer: writer}
+	w.writers = append(w.writers, ww)
+	w.apply(ww, w.Health())
+}
+
+// Start checks the free space periodically until Stop is called.
+func (w *DiskWatchdog) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+		for {
+			w.Check()
+			select {
+			case <-ticker.C:
+			case <-w.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic checks.
+func (w *DiskWatchdog) Stop() {
+	close(w.quit)
+	w.wg.Wait()
+}
+
+// Check reads the free space of the watched paths and pauses or resumes the writers accordingly.
+// The health is left unchanged if the free space cannot be read.
+func (w *DiskWatchdog) Check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var available uint64
+	for i, path := range w.config.Paths {
+		free, err := w.space(path)
+		if err != nil {
+			if !w.failing {
+				w.log.Warn("Failed to read the free disk space", "path", path, "err", err)
+			}
+			w.failing = true
+			return
+		}
+		if i == 0 || free < available {
+			available = free
+		}
+	}
+	w.failing = false
+	w.available = available
+	diskFreeGauge.Update(int64(available))
+
+	health := DiskHealthy
+	if w.config.HardThreshold != 0 && available < w.config.HardThreshold {
+		health = DiskCritical
+	} else if w.config.SoftThreshold != 0 && available < w.config.SoftThreshold {
+		health = DiskDegraded
+	}
+	previous := DiskHealth(w.health.Swap(uint32(health)))
+	diskHealthGauge.Update(int64(health))
+	if health != previous {
+		switch health {
+		case DiskCritical:
+			w.log.Error("Free disk space critically low, block import suspended until space is freed",
+				"available", common.StorageSize(available), "threshold", common.StorageSize(w.config.HardThreshold))
+		case DiskDegraded:
+			if previous == DiskCritical {
+				w.log.Warn("Free disk space above the hard threshold, block import resumed", "available", common.StorageSize(available))
+			}
+			w.log.Warn("Free disk space low, non-essential writes paused",
+				"available", common.StorageSize(available), "threshold", common.StorageSize(w.config.SoftThreshold))
+		default:
+			w.log.Info("Free disk space recovered, writes resumed", "available", common.StorageSize(available))
+		}
+	}
+	for _, ww := range w.writers {
+		w.apply(ww, health)
+	}
+}
+
+// apply pauses or resumes the writer for the given health. It must be called with the lock held.
+func (w *DiskWatchdog) apply(ww *watchedWriter, health DiskHealth) {
+	pause := health >= ww.level
+	if pause == ww.paused {
+		return
+	}
+	ww.paused = pause
+	if pause {
+		w.log.Debug("Pausing disk writer", "writer", ww.name, "health", health)
+		ww.writer.PauseWrites()
+	} else {
+		w.log.Debug("Resuming disk writer", "writer", ww.name, "health", health)
+		ww.writer.ResumeWrites()
+	}
+}
+
+// Health returns the disk health as of the last check.
+func (w *DiskWatchdog) Health() DiskHealth {
+	return DiskHealth(w.health.Load())
+}
+
+// Status returns the state of the watchdog as of the last check.
+func (w *DiskWatchdog) Status() DiskStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := DiskStatus{
+		Health:        w.Health(),
+		Available:     w.available,
+		SoftThreshold: w.config.SoftThreshold,
+		HardThreshold: w.config.HardThreshold,
+		Paused:        []string{},
+	}
+	for _, ww := range w.writers {
+		if ww.paused {
+			status.Paused = append(status.Paused, ww.name)
+		}
+	}
+	return status
+}
+
+// PauseWrites stops the import and the commit of blocks, which fail with ErrDiskSpaceCritical until
+// ResumeWrites is called. The blockchain is an essential writer of the DiskWatchdog.
+func (bc *BlockChain) PauseWrites() {
+	bc.importPaused.Store(true)
+}
+
+// ResumeWrites resumes the import and the commit of blocks.
+func (bc *BlockChain) ResumeWrites() {
+	bc.importPaused.Store(false)
+}
+
+// cleanCacheJournal is the writer of the clean trie cache journal.
+type cleanCacheJournal struct {
+	triedb *trie.Database
+}
+
+func (j cleanCacheJournal) PauseWrites()  { j.triedb.PauseJournal() }
+func (j cleanCacheJournal) ResumeWrites() { j.triedb.ResumeJournal() }
+
+// CleanCacheJournal returns the writer of the clean trie cache journal, to be paused by the
+// DiskWatchdog along with the other non-essential writers.
+func (bc *BlockChain) CleanCacheJournal() PausableWriter {
+	return cleanCacheJournal{triedb: bc.stateCache.TrieDB()}
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/trie"
+)
+
+// fakeDiskSpace is a statfs provider serving the configured free space by path.
+type fakeDiskSpace struct {
+	free map[string]uint64
+	err  error
+}
+
+func (f *fakeDiskSpace) space(path string) (uint64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.free[path], nil
+}
+
+// countingWriter is a pausable writer counting the calls it receives.
+type countingWriter struct {
+	paused          bool
+	pauses, resumes int
+}
+
+func (w *countingWriter) PauseWrites() {
+	w.paused = true
+	w.pauses++
+}
+
+func (w *countingWriter) ResumeWrites() {
+	w.paused = false
+	w.resumes++
+}
+
+func TestDiskWatchdog(t *testing.T) {
+	disk := &fakeDiskSpace{free: map[string]uint64{"chaindata": 1000, "ancient": 1000}}
+	watchdog := NewDiskWatchdog(DiskWatchdogConfig{
+		Paths:         []string{"chaindata", "ancient"},
+		SoftThreshold: 100,
+		HardThreshold: 50,
+	}, disk.space, log.Root())
+	bloom, journal, chain := new(countingWriter), new(countingWriter), new(countingWriter)
+	watchdog.Register("bloom", bloom)
+	watchdog.Register("journal", journal)
+	watchdog.RegisterEssential("chain", chain)
+
+	check := func(health DiskHealth, paused ...string) {
+		t.Helper()
+		watchdog.Check()
+		require.Equal(t, health, watchdog.Health())
+		require.Equal(t, bloom.paused, health >= DiskDegraded)
+		require.Equal(t, journal.paused, health >= DiskDegraded)
+		require.Equal(t, chain.paused, health == DiskCritical)
+		require.Equal(t, append([]string{}, paused...), watchdog.Status().Paused)
+	}
+
+	check(DiskHealthy)
+	// the lowest free space of the paths is considered
+	disk.free["ancient"] = 80
+	check(DiskDegraded, "bloom", "journal")
+	check(DiskDegraded, "bloom", "journal")
+	require.Equal(t, 1, bloom.pauses)
+
+	disk.free["chaindata"] = 40
+	check(DiskCritical, "bloom", "journal", "chain")
+	require.Equal(t, uint64(40), watchdog.Status().Available)
+
+	// the health is left unchanged while the free space cannot be read
+	disk.err = errors.New("statfs failure")
+	check(DiskCritical, "bloom", "journal", "chain")
+	disk.err = nil
+
+	// the writers are resumed as space is freed
+	disk.free["chaindata"] = 90
+	check(DiskDegraded, "bloom", "journal")
+	disk.free["chaindata"], disk.free["ancient"] = 500, 500
+	check(DiskHealthy)
+	require.Equal(t, []int{1, 1, 1}, []int{bloom.pauses, journal.pauses, chain.pauses})
+	require.Equal(t, []int{1, 1, 1}, []int{bloom.resumes, journal.resumes, chain.resumes})
+
+	// a writer registered while degraded is paused right away
+	disk.free["ancient"] = 60
+	check(DiskDegraded, "bloom", "journal")
+	late := new(countingWriter)
+	watchdog.Register("late", late)
+	require.True(t, late.paused)
+}
+
+func TestDiskWatchdogBlockImport(t *testing.T) {
+	db, blockchain, err := newCanonical(t, ethash.NewFaker(), 0, true)
+	require.NoError(t, err)
+	defer blockchain.Stop()
+	blocks := makeBlockChain(blockchain.CurrentBlock(), 2, ethash.NewFaker(), db, canonicalSeed)
+
+	blockchain.PauseWrites()
+	_, err = blockchain.InsertChain(blocks[:1])
+	require.ErrorIs(t, err, ErrDiskSpaceCritical)
+	_, err = blockchain.InsertHeaderChain([]*types.Header{blocks[0].Header()}, 1)
+	require.ErrorIs(t, err, ErrDiskSpaceCritical)
+	require.Equal(t, uint64(0), blockchain.CurrentBlock().NumberU64())
+
+	blockchain.ResumeWrites()
+	_, err = blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), blockchain.CurrentBlock().NumberU64())
+}
+
+func TestDiskWatchdogCleanCacheJournal(t *testing.T) {
+	triedb := trie.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Cache: 16})
+	journal := cleanCacheJournal{triedb: triedb}
+	dir := filepath.Join(t.TempDir(), "triecache")
+
+	journal.PauseWrites()
+	require.NoError(t, triedb.SaveCache(dir))
+	_, err := os.Stat(dir)
+	require.True(t, os.IsNotExist(err))
+
+	journal.ResumeWrites()
+	require.NoError(t, triedb.SaveCache(dir))
+	_, err = os.Stat(dir)
+	require.NoError(t, err)
+}
+
+// nopIndexBackend indexes the sections without producing anything.
+type nopIndexBackend struct{}
+
+func (nopIndexBackend) Reset(context.Context, uint64, common.Hash) error { return nil }
+func (nopIndexBackend) Process(context.Context, *types.Header) error     { return nil }
+func (nopIndexBackend) Commit() error                                    { return nil }
+func (nopIndexBackend) Prune(uint64) error                               { return nil }
+
+func TestDiskWatchdogChainIndexer(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	indexer := NewChainIndexer(db, rawdb.NewTable(db, "i"), nopIndexBackend{}, 4, 0, 0, "test")
+	defer indexer.Close()
+	for i := uint64(0); i < 8; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i)}
+		if i > 0 {
+			header.ParentHash = rawdb.ReadCanonicalHash(db, i-1)
+		}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), i)
+	}
+	sections := func() uint64 {
+		sections, _, _ := indexer.Sections()
+		return sections
+	}
+
+	indexer.PauseWrites()
+	indexer.newHead(7, false)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, uint64(0), sections())
+
+	// the sections completed while paused are processed on resume
+	indexer.ResumeWrites()
+	require.Eventually(t, func() bool { return sections() == 2 }, 3*time.Second, 10*time.Millisecond)
+}
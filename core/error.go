@@ -32,6 +32,10 @@ var (
     // ErrNoGenesis is returned when there is no Genesis Block.
     ErrNoGenesis = errors.New("genesis not found in chain")
 
+    // ErrStateRootMismatch is returned when the state root computed locally for a
+    // block differs from the one of its header.
+    ErrStateRootMismatch = errors.New("invalid merkle root")
+
     errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
@@ -41,3 +41,10 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// StateMismatchEvent is posted when the import of a block fails because the state
+// root computed locally differs from the one of its header.
+type StateMismatchEvent struct {
+    Block *types.Block
+    Err   error
+}
@@ -86,21 +86,24 @@ func GetDefaultEVM(chain *BlockChain) func(header *types.Header, origin common.A
 			Origin:   origin,
 			GasPrice: new(big.Int).SetUint64(0x0),
 		}
-		evm := vm.NewEVM(evmContext, txContext, statedb, chain.chainConfig,
-			vm.Config{
-				//// Uncomment this to get EVM debugging logs
-				//Debug: true,
-				//Tracer: logger.NewMarkdownLogger(&logger.Config{
-				//	EnableMemory:     true,
-				//	DisableStack:     false,
-				//	DisableStorage:   false,
-				//	EnableReturnData: true,
-				//	Debug:            true,
-				//	Limit:            0,
-				//	Overrides:        nil,
-				//}, os.Stdout),
-			},
-		)
+		vmConfig := vm.Config{
+			//// Uncomment this to get EVM debugging logs
+			//Debug: true,
+			//Tracer: logger.NewMarkdownLogger(&logger.Config{
+			//	EnableMemory:     true,
+			//	DisableStack:     false,
+			//	DisableStorage:   false,
+			//	EnableReturnData: true,
+			//	Debug:            true,
+			//	Limit:            0,
+			//	Overrides:        nil,
+			//}, os.Stdout),
+		}
+		// the logger is only allocated if the internal calls are traced
+		if tracer := chain.internalCallTracer.logger(header); tracer != nil {
+			vmConfig.Debug, vmConfig.Tracer = true, tracer
+		}
+		evm := vm.NewEVM(evmContext, txContext, statedb, chain.chainConfig, vmConfig)
 		return evm
 	}
 }
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	crand "crypto/rand"
 	"errors"
 	"fmt"
@@ -543,6 +544,14 @@ func (hc *HeaderChain) GetHeadersFrom(number, count uint64) []rlp.RawValue {
 	return headers
 }
 
+// HeaderRangeIterator returns an iterator over the canonical headers in [from, to],
+// going backwards if from is greater than to. The headers are read from the
+// database in batches, from the freezer for the ancient ones and from the
+// key-value store for the recent ones. The caller must bound the range.
+func (hc *HeaderChain) HeaderRangeIterator(ctx context.Context, from, to uint64) *rawdb.HeaderIterator {
+	return rawdb.NewHeaderIterator(ctx, hc.chainDb, from, to)
+}
+
 func (hc *HeaderChain) GetCanonicalHash(number uint64) common.Hash {
 	return rawdb.ReadCanonicalHash(hc.chainDb, number)
 }
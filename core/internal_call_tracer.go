@@ -0,0 +1,152 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/autonity/autonity/accounts/abi"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+)
+
+// DefaultInternalCallTraces is the number of internal call traces kept by a tracer.
+const DefaultInternalCallTraces = 256
+
+// InternalCallTrace is the trace of a protocol contract call made by the node itself.
+type InternalCallTrace struct {
+	Time         time.Time      `json:"time"`
+	Block        uint64         `json:"block"` // number of the block the call is made on top of
+	From         common.Address `json:"from"`
+	To           common.Address `json:"to"`
+	Method       string         `json:"method"` // method name, or the hex selector if the contract ABI is unknown
+	Input        hexutil.Bytes  `json:"input"`
+	Output       hexutil.Bytes  `json:"output"`
+	GasUsed      uint64         `json:"gasUsed"`
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Calls        int            `json:"calls"`    // number of calls in the call tree, the top call included
+	MaxDepth     int            `json:"maxDepth"` // depth of the call tree, 1 without nested call
+}
+
+// MethodResolver returns the name of the method of the contract with the given selector.
+type MethodResolver func(contract common.Address, selector []byte) (string, bool)
+
+// InternalCallTracer traces the protocol contract calls made by the node itself when enabled, and
+// keeps the traces in a ring buffer. No EVM logger is allocated while it is disabled.
+type InternalCallTracer struct {
+	enabled atomic.Bool
+	methods MethodResolver
+
+	mu    sync.Mutex
+	ring  []*InternalCallTrace
+	next  int // index of the next trace in the ring
+	count int // number of traces in the ring
+}
+
+// NewInternalCallTracer returns a disabled tracer keeping the given number of traces.
+func NewInternalCallTracer(capacity int, methods MethodResolver) *InternalCallTracer {
+	return &InternalCallTracer{ring: make([]*InternalCallTrace, capacity), methods: methods}
+}
+
+// SetEnabled enables or disables the tracing of the internal calls.
+func (t *InternalCallTracer) SetEnabled(enabled bool) {
+	t.enabled.Store(enabled)
+}
+
+// Enabled reports whether the internal calls are traced.
+func (t *InternalCallTracer) Enabled() bool {
+	return t.enabled.Load()
+}
+
+// Traces returns the traces kept, the most recent first.
+func (t *InternalCallTracer) Traces() []*InternalCallTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	traces := make([]*InternalCallTrace, 0, t.count)
+	for i := 1; i <= t.count; i++ {
+		traces = append(traces, t.ring[(t.next-i+len(t.ring))%len(t.ring)])
+	}
+	return traces
+}
+
+// logger returns the EVM logger tracing a call on top of header, or nil if tracing is disabled.
+func (t *InternalCallTracer) logger(header *types.Header) vm.EVMLogger {
+	if t == nil || !t.enabled.Load() {
+		return nil
+	}
+	l := &internalCallLogger{tracer: t}
+	if header != nil && header.Number != nil {
+		l.trace.Block = header.Number.Uint64()
+	}
+	return l
+}
+
+func (t *InternalCallTracer) add(trace *InternalCallTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.ring) == 0 {
+		return
+	}
+	t.ring[t.next] = trace
+	t.next = (t.next + 1) % len(t.ring)
+	t.count = min(t.count+1, len(t.ring))
+}
+
+// internalCallLogger summarises a single internal call, nested calls included.
+type internalCallLogger struct {
+	tracer *InternalCallTracer
+	trace  InternalCallTrace
+	depth  int
+}
+
+func (l *internalCallLogger) CaptureStart(_ *vm.EVM, from common.Address, to common.Address, _ bool, input []byte, _ uint64, _ *big.Int) {
+	l.trace.Time = time.Now()
+	l.trace.From, l.trace.To = from, to
+	l.trace.Input = common.CopyBytes(input)
+	l.trace.Calls, l.trace.MaxDepth, l.depth = 1, 1, 1
+	if len(input) >= 4 {
+		l.trace.Method = hexutil.Encode(input[:4])
+		if name, ok := l.tracer.methods(to, input[:4]); ok {
+			l.trace.Method = name
+		}
+	}
+}
+
+func (l *internalCallLogger) CaptureEnter(vm.OpCode, common.Address, common.Address, []byte, uint64, *big.Int) {
+	l.trace.Calls++
+	l.depth++
+	l.trace.MaxDepth = max(l.trace.MaxDepth, l.depth)
+}
+
+func (l *internalCallLogger) CaptureExit([]byte, uint64, error) {
+	l.depth--
+}
+
+func (l *internalCallLogger) CaptureState(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, []byte, int, error) {
+}
+
+func (l *internalCallLogger) CaptureFault(uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, int, error) {
+}
+
+func (l *internalCallLogger) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	l.trace.Output = common.CopyBytes(output)
+	l.trace.GasUsed = gasUsed
+	if err != nil {
+		l.trace.Error = err.Error()
+		if errors.Is(err, vm.ErrExecutionReverted) {
+			if reason, unpackErr := abi.UnpackRevert(output); unpackErr == nil {
+				l.trace.RevertReason = reason
+			} else if len(output) > 0 {
+				l.trace.RevertReason = fmt.Sprintf("undecodable revert data %s", hexutil.Encode(output))
+			}
+		}
+	}
+	trace := l.trace
+	l.tracer.add(&trace)
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/params"
+)
+
+func TestInternalCallTracer(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	newCommitJournalTestChain(db, 0)
+	chain := newCommitJournalBlockChain(t, db, 0, 0)
+	defer chain.Stop()
+	head := chain.CurrentBlock()
+	state, err := chain.StateAt(head.Root())
+	require.NoError(t, err)
+	contracts := chain.ProtocolContracts()
+
+	// nothing is traced while disabled
+	require.Nil(t, chain.InternalCallTracer().logger(head.Header()))
+	_, err = contracts.CommitteeEnodes(context.Background(), head, state, false)
+	require.NoError(t, err)
+	require.Empty(t, chain.InternalCallTracer().Traces())
+
+	chain.InternalCallTracer().SetEnabled(true)
+	enodes, err := contracts.CommitteeEnodes(context.Background(), head, state, false)
+	require.NoError(t, err)
+	// only the operator can set the minimum base fee
+	err = contracts.AutonityContractCall(state, head.Header(), "setMinimumBaseFee", nil, big.NewInt(1))
+	require.ErrorIs(t, err, vm.ErrExecutionReverted)
+
+	traces := chain.InternalCallTracer().Traces()
+	require.Len(t, traces, 2)
+	reverted, read := traces[0], traces[1]
+
+	require.Equal(t, "getCommitteeEnodes", read.Method)
+	require.Equal(t, params.DeployerAddress, read.From)
+	require.Equal(t, params.AutonityContractAddress, read.To)
+	require.Equal(t, head.NumberU64(), read.Block)
+	require.NotEmpty(t, read.Output)
+	require.NotZero(t, read.GasUsed)
+	require.Empty(t, read.Error)
+	require.Empty(t, read.RevertReason)
+	require.Equal(t, 1, read.Calls)
+	require.Equal(t, 1, read.MaxDepth)
+	require.NotEmpty(t, enodes.List)
+
+	require.Equal(t, "setMinimumBaseFee", reverted.Method)
+	require.Equal(t, vm.ErrExecutionReverted.Error(), reverted.Error)
+	require.Equal(t, "caller is not the operator", reverted.RevertReason)
+
+	chain.InternalCallTracer().SetEnabled(false)
+	_, err = contracts.CommitteeEnodes(context.Background(), head, state, false)
+	require.NoError(t, err)
+	require.Len(t, chain.InternalCallTracer().Traces(), 2)
+}
+
+func TestInternalCallTracerRing(t *testing.T) {
+	tracer := NewInternalCallTracer(3, nil)
+	for i := uint64(1); i <= 5; i++ {
+		tracer.add(&InternalCallTrace{Block: i})
+	}
+	traces := tracer.Traces()
+	require.Len(t, traces, 3)
+	for i, trace := range traces {
+		require.Equal(t, uint64(5-i), trace.Block)
+	}
+}
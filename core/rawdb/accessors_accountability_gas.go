@@ -0,0 +1,54 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/rlp"
+)
+
+// ReadAccountabilityGasStart retrieves the first block indexed by the accountability gas
+// statistics, zero if the index was never written.
+func ReadAccountabilityGasStart(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(accountabilityGasStartKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteAccountabilityGasStart stores the first block indexed by the accountability gas statistics.
+func WriteAccountabilityGasStart(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(accountabilityGasStartKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the accountability gas statistics start", "err", err)
+	}
+}
+
+// ReadAccountabilityGasStats retrieves the accountability gas statistics of a block. The blocks
+// indexed without any run of the accountability precompiled contracts have no entry.
+func ReadAccountabilityGasStats(db ethdb.KeyValueReader, hash common.Hash, number uint64) *types.AccountabilityGasStats {
+	data, _ := db.Get(accountabilityGasKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	stats := new(types.AccountabilityGasStats)
+	if err := rlp.DecodeBytes(data, stats); err != nil {
+		log.Error("Invalid accountability gas statistics RLP", "hash", hash, "number", number, "err", err)
+		return nil
+	}
+	return stats
+}
+
+// WriteAccountabilityGasStats stores the accountability gas statistics of a block.
+func WriteAccountabilityGasStats(db ethdb.KeyValueWriter, hash common.Hash, number uint64, stats *types.AccountabilityGasStats) {
+	data, err := rlp.EncodeToBytes(stats)
+	if err != nil {
+		log.Crit("Failed to RLP encode accountability gas statistics", "err", err)
+	}
+	if err := db.Put(accountabilityGasKey(number, hash), data); err != nil {
+		log.Crit("Failed to store accountability gas statistics", "err", err)
+	}
+}
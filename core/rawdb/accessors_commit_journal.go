@@ -0,0 +1,72 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/rlp"
+)
+
+// CommitJournalEntry is a block committed to the canonical chain, as recorded by the commit journal.
+type CommitJournalEntry struct {
+	Seq        uint64
+	Number     uint64
+	Hash       common.Hash
+	ParentHash common.Hash
+	Time       uint64
+}
+
+// ReadCommitJournalRange retrieves the sequence numbers of the oldest commit journal entry
+// and of the next one to be written. Both are zero if the journal was never written.
+func ReadCommitJournalRange(db ethdb.KeyValueReader) (tail, head uint64) {
+	data, _ := db.Get(commitJournalRangeKey)
+	if len(data) != 16 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:])
+}
+
+// WriteCommitJournalRange stores the sequence numbers of the oldest commit journal entry
+// and of the next one to be written.
+func WriteCommitJournalRange(db ethdb.KeyValueWriter, tail, head uint64) {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data[:8], tail)
+	binary.BigEndian.PutUint64(data[8:], head)
+	if err := db.Put(commitJournalRangeKey, data); err != nil {
+		log.Crit("Failed to store the commit journal range", "err", err)
+	}
+}
+
+// ReadCommitJournalEntry retrieves the commit journal entry of the given sequence number.
+func ReadCommitJournalEntry(db ethdb.KeyValueReader, seq uint64) *CommitJournalEntry {
+	data, _ := db.Get(commitJournalKey(seq))
+	if len(data) == 0 {
+		return nil
+	}
+	entry := new(CommitJournalEntry)
+	if err := rlp.DecodeBytes(data, entry); err != nil {
+		log.Error("Invalid commit journal entry RLP", "seq", seq, "err", err)
+		return nil
+	}
+	return entry
+}
+
+// WriteCommitJournalEntry stores a commit journal entry.
+func WriteCommitJournalEntry(db ethdb.KeyValueWriter, entry *CommitJournalEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to RLP encode commit journal entry", "err", err)
+	}
+	if err := db.Put(commitJournalKey(entry.Seq), data); err != nil {
+		log.Crit("Failed to store commit journal entry", "err", err)
+	}
+}
+
+// DeleteCommitJournalEntry removes a commit journal entry.
+func DeleteCommitJournalEntry(db ethdb.KeyValueWriter, seq uint64) {
+	if err := db.Delete(commitJournalKey(seq)); err != nil {
+		log.Crit("Failed to delete commit journal entry", "err", err)
+	}
+}
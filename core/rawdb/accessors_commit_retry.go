@@ -0,0 +1,47 @@
+package rawdb
+
+import (
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/rlp"
+)
+
+// ReadCommitRetryBlocks retrieves the committed blocks whose import failed, ordered by number.
+func ReadCommitRetryBlocks(db ethdb.Iteratee) []*types.Block {
+	it := db.NewIterator(commitRetryPrefix, nil)
+	defer it.Release()
+
+	var blocks []*types.Block
+	for it.Next() {
+		if len(it.Key()) != len(commitRetryPrefix)+8+common.HashLength {
+			continue
+		}
+		block := new(types.Block)
+		if err := rlp.DecodeBytes(it.Value(), block); err != nil {
+			log.Error("Invalid committed block RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// WriteCommitRetryBlock stores a committed block whose import failed, along with its quorum certificate.
+func WriteCommitRetryBlock(db ethdb.KeyValueWriter, block *types.Block) {
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		log.Crit("Failed to RLP encode committed block", "err", err)
+	}
+	if err := db.Put(commitRetryKey(block.NumberU64(), block.Hash()), data); err != nil {
+		log.Crit("Failed to store committed block", "err", err)
+	}
+}
+
+// DeleteCommitRetryBlock removes a committed block once imported.
+func DeleteCommitRetryBlock(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	if err := db.Delete(commitRetryKey(number, hash)); err != nil {
+		log.Crit("Failed to delete committed block", "err", err)
+	}
+}
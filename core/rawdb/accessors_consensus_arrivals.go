@@ -0,0 +1,67 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+)
+
+// ReadConsensusArrivals retrieves the encoded first arrival times of the consensus messages of the
+// given height.
+func ReadConsensusArrivals(db ethdb.KeyValueReader, number uint64) []byte {
+	data, _ := db.Get(consensusArrivalsKey(number))
+	return data
+}
+
+// WriteConsensusArrivals stores the encoded first arrival times of the consensus messages of the
+// given height.
+func WriteConsensusArrivals(db ethdb.KeyValueWriter, number uint64, data []byte) {
+	if err := db.Put(consensusArrivalsKey(number), data); err != nil {
+		log.Crit("Failed to store consensus arrivals", "err", err)
+	}
+}
+
+// DeleteConsensusArrivals removes the first arrival times of the consensus messages of the given
+// height.
+func DeleteConsensusArrivals(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(consensusArrivalsKey(number)); err != nil {
+		log.Crit("Failed to delete consensus arrivals", "err", err)
+	}
+}
+
+// ReadConsensusArrivalsTail retrieves the lowest height the first arrival times are stored for.
+func ReadConsensusArrivalsTail(db ethdb.Iteratee) (uint64, bool) {
+	it := db.NewIterator(consensusArrivalsPrefix, nil)
+	defer it.Release()
+	for it.Next() {
+		if key := it.Key(); len(key) == len(consensusArrivalsPrefix)+8 {
+			return binary.BigEndian.Uint64(key[len(consensusArrivalsPrefix):]), true
+		}
+	}
+	return 0, false
+}
+
+// DeleteConsensusArrivalsBelow removes the first arrival times of the consensus messages of the
+// heights below the given one.
+func DeleteConsensusArrivalsBelow(db ethdb.KeyValueStore, number uint64) {
+	it := db.NewIterator(consensusArrivalsPrefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(consensusArrivalsPrefix)+8 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(consensusArrivalsPrefix):]) >= number {
+			break
+		}
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete consensus arrivals", "err", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete consensus arrivals", "err", err)
+	}
+}
@@ -0,0 +1,76 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+)
+
+// ReadConsensusRoundState retrieves the encoded consensus round state of the height in progress.
+func ReadConsensusRoundState(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(consensusRoundStateKey)
+	return data
+}
+
+// WriteConsensusRoundState stores the encoded consensus round state of the height in progress.
+func WriteConsensusRoundState(db ethdb.KeyValueWriter, data []byte) {
+	if err := db.Put(consensusRoundStateKey, data); err != nil {
+		log.Crit("Failed to store consensus round state", "err", err)
+	}
+}
+
+// DeleteConsensusRoundState removes the consensus round state.
+func DeleteConsensusRoundState(db ethdb.KeyValueWriter) {
+	if err := db.Delete(consensusRoundStateKey); err != nil {
+		log.Crit("Failed to delete consensus round state", "err", err)
+	}
+}
+
+// ReadConsensusWALMessages retrieves the encoded consensus messages journaled at the given height.
+func ReadConsensusWALMessages(db ethdb.Iteratee, number uint64) [][]byte {
+	prefix := append(consensusWALPrefix, encodeBlockNumber(number)...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var messages [][]byte
+	for it.Next() {
+		if len(it.Key()) == len(prefix)+common.HashLength {
+			messages = append(messages, common.CopyBytes(it.Value()))
+		}
+	}
+	return messages
+}
+
+// WriteConsensusWALMessage journals the encoded consensus message of the given hash at the given
+// height.
+func WriteConsensusWALMessage(db ethdb.KeyValueWriter, number uint64, hash common.Hash, data []byte) {
+	if err := db.Put(consensusWALKey(number, hash), data); err != nil {
+		log.Crit("Failed to store consensus journal message", "err", err)
+	}
+}
+
+// DeleteConsensusWALMessagesBelow removes the consensus messages journaled at the heights below the
+// given one.
+func DeleteConsensusWALMessagesBelow(db ethdb.KeyValueStore, number uint64) {
+	it := db.NewIterator(consensusWALPrefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(consensusWALPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(consensusWALPrefix):]) >= number {
+			break
+		}
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete consensus journal message", "err", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete consensus journal messages", "err", err)
+	}
+}
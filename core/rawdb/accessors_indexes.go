@@ -175,3 +175,38 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// BloomGeneration is a span of the bloombits index generated with the same
+// section size. It covers the blocks from its start up to the start of the next
+// generation, or up to the indexing progress if it is the last one.
+type BloomGeneration struct {
+	Start       uint64 // First block of the generation, a multiple of the section size
+	SectionSize uint64 // Number of blocks per bloombits section
+}
+
+// ReadBloomGenerations retrieves the generations of the bloombits index, ordered
+// by start block. Nil is returned for databases indexed before the generations
+// were tracked.
+func ReadBloomGenerations(db ethdb.KeyValueReader) []BloomGeneration {
+	data, _ := db.Get(bloomBitsGenerationsKey)
+	if len(data) == 0 {
+		return nil
+	}
+	var generations []BloomGeneration
+	if err := rlp.DecodeBytes(data, &generations); err != nil {
+		log.Error("Invalid bloombits generations", "err", err)
+		return nil
+	}
+	return generations
+}
+
+// WriteBloomGenerations stores the generations of the bloombits index.
+func WriteBloomGenerations(db ethdb.KeyValueWriter, generations []BloomGeneration) {
+	data, err := rlp.EncodeToBytes(generations)
+	if err != nil {
+		log.Crit("Failed to encode bloombits generations", "err", err)
+	}
+	if err := db.Put(bloomBitsGenerationsKey, data); err != nil {
+		log.Crit("Failed to store bloombits generations", "err", err)
+	}
+}
@@ -0,0 +1,49 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+)
+
+// ReadMeshHistoryRange retrieves the sequence numbers of the oldest consensus mesh history
+// entry and of the next one to be written. Both are zero if the history is empty.
+func ReadMeshHistoryRange(db ethdb.KeyValueReader) (tail, head uint64) {
+	data, _ := db.Get(meshHistoryRangeKey)
+	if len(data) != 16 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:])
+}
+
+// WriteMeshHistoryRange stores the sequence numbers of the oldest consensus mesh history
+// entry and of the next one to be written.
+func WriteMeshHistoryRange(db ethdb.KeyValueWriter, tail, head uint64) {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data[:8], tail)
+	binary.BigEndian.PutUint64(data[8:], head)
+	if err := db.Put(meshHistoryRangeKey, data); err != nil {
+		log.Crit("Failed to store the mesh history range", "err", err)
+	}
+}
+
+// ReadMeshHistoryEntry retrieves the consensus mesh history entry of the given sequence number.
+func ReadMeshHistoryEntry(db ethdb.KeyValueReader, seq uint64) []byte {
+	data, _ := db.Get(meshHistoryKey(seq))
+	return data
+}
+
+// WriteMeshHistoryEntry stores a consensus mesh history entry.
+func WriteMeshHistoryEntry(db ethdb.KeyValueWriter, seq uint64, entry []byte) {
+	if err := db.Put(meshHistoryKey(seq), entry); err != nil {
+		log.Crit("Failed to store mesh history entry", "err", err)
+	}
+}
+
+// DeleteMeshHistoryEntry removes a consensus mesh history entry.
+func DeleteMeshHistoryEntry(db ethdb.KeyValueWriter, seq uint64) {
+	if err := db.Delete(meshHistoryKey(seq)); err != nil {
+		log.Crit("Failed to delete mesh history entry", "err", err)
+	}
+}
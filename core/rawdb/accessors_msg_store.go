@@ -0,0 +1,94 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+)
+
+// ReadMsgStoreMessages retrieves the encoded consensus messages of the fault detector at the given height,
+// ordered by signer, code and hash.
+func ReadMsgStoreMessages(db ethdb.Iteratee, number uint64) [][]byte {
+	prefix := append(msgStorePrefix, encodeBlockNumber(number)...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var messages [][]byte
+	for it.Next() {
+		if len(it.Key()) == msgStoreKeyLength {
+			messages = append(messages, common.CopyBytes(it.Value()))
+		}
+	}
+	return messages
+}
+
+// WriteMsgStoreMessage stores the encoded consensus message of the fault detector of the given height,
+// signer, code and hash. The signer is the committee index of the proposer, or of the first signer of a vote.
+func WriteMsgStoreMessage(db ethdb.KeyValueWriter, number uint64, signer uint32, code uint8, hash common.Hash, data []byte) {
+	if err := db.Put(msgStoreKey(number, signer, code, hash), data); err != nil {
+		log.Crit("Failed to store fault detector message", "err", err)
+	}
+}
+
+// DeleteMsgStoreMessage removes the consensus message of the fault detector of the given height, signer,
+// code and hash.
+func DeleteMsgStoreMessage(db ethdb.KeyValueWriter, number uint64, signer uint32, code uint8, hash common.Hash) {
+	if err := db.Delete(msgStoreKey(number, signer, code, hash)); err != nil {
+		log.Crit("Failed to delete fault detector message", "err", err)
+	}
+}
+
+// ReadMsgStoreHeights returns the heights from `from` to `to` included at which consensus messages of the fault
+// detector are stored, in ascending order.
+func ReadMsgStoreHeights(db ethdb.Iteratee, from, to uint64) []uint64 {
+	it := db.NewIterator(msgStorePrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var heights []uint64
+	for it.Next() {
+		key := it.Key()
+		if len(key) != msgStoreKeyLength {
+			continue
+		}
+		height := binary.BigEndian.Uint64(key[len(msgStorePrefix):])
+		if height > to {
+			break
+		}
+		if len(heights) == 0 || heights[len(heights)-1] != height {
+			heights = append(heights, height)
+		}
+	}
+	return heights
+}
+
+// DeleteMsgStoreMessagesBelow removes the consensus messages of the fault detector at the heights below
+// the given one.
+func DeleteMsgStoreMessagesBelow(db ethdb.KeyValueStore, number uint64) {
+	it := db.NewIterator(msgStorePrefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != msgStoreKeyLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(msgStorePrefix):]) >= number {
+			break
+		}
+		if err := batch.Delete(key); err != nil {
+			log.Crit("Failed to delete fault detector message", "err", err)
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete fault detector messages", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete fault detector messages", "err", err)
+	}
+}
@@ -352,3 +352,23 @@ func UnindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 func unindexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
 	unindexTransactions(db, from, to, interrupt, hook)
 }
+
+// FindTransaction looks the transaction up in the canonical block bodies of the specified block
+// range, regardless of the txlookup indices, and returns the number of the block including it.
+// The frozen blocks are searched as well. The from is included while to is excluded.
+func FindTransaction(db ethdb.Database, hash common.Hash, from uint64, to uint64) (uint64, bool) {
+	// short circuit for invalid range
+	if from >= to {
+		return 0, false
+	}
+	interrupt := make(chan struct{})
+	defer close(interrupt)
+	for delivery := range iterateTransactions(db, from, to, true, interrupt) {
+		for _, txHash := range delivery.hashes {
+			if txHash == hash {
+				return delivery.number, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -206,3 +206,53 @@ func TestIndexTransactions(t *testing.T) {
 	verify(8, 11, true, 8)
 	verify(0, 8, false, 8)
 }
+
+func TestFindTransaction(t *testing.T) {
+	// Construct test chain db, without any txlookup index
+	chainDb := NewMemoryDatabase()
+
+	var txs []*types.Transaction
+	to := common.BytesToAddress([]byte{0x11})
+	block := types.NewBlock(&types.Header{Number: big.NewInt(int64(0))}, nil, nil, nil, newHasher()) // Empty genesis block
+	WriteBlock(chainDb, block)
+	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	for i := uint64(1); i <= 10; i++ {
+		tx := types.NewTx(&types.LegacyTx{
+			Nonce:    i,
+			GasPrice: big.NewInt(11111),
+			Gas:      1111,
+			To:       &to,
+			Value:    big.NewInt(111),
+			Data:     []byte{0x11, 0x11, 0x11},
+		})
+		txs = append(txs, tx)
+		block = types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, []*types.Transaction{tx}, nil, nil, newHasher())
+		WriteBlock(chainDb, block)
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	}
+
+	var cases = []struct {
+		tx       int
+		from, to uint64
+		found    bool
+	}{
+		{0, 0, 11, true},
+		{4, 5, 6, true},
+		{9, 1, 11, true},
+		{4, 0, 5, false},
+		{4, 6, 11, false},
+		{4, 5, 5, false},
+	}
+	for i, c := range cases {
+		number, found := FindTransaction(chainDb, txs[c.tx].Hash(), c.from, c.to)
+		if found != c.found {
+			t.Fatalf("Case %d failed, found mismatch, want %t, got %t", i, c.found, found)
+		}
+		if found && number != uint64(c.tx+1) {
+			t.Fatalf("Case %d failed, block number mismatch, want %d, got %d", i, c.tx+1, number)
+		}
+	}
+	if _, found := FindTransaction(chainDb, common.Hash{0x01}, 0, 11); found {
+		t.Fatal("Unknown transaction found")
+	}
+}
@@ -321,6 +321,13 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		storageSnaps    stat
 		preimages       stat
 		bloomBits       stat
+		meshHistory     stat
+		commitJournal   stat
+		afdGas          stat
+		arrivals        stat
+		consensusWAL    stat
+		msgStore        stat
+		commitRetry     stat
 
 		// Ancient store statistics
 		ancientHeadersSize  common.StorageSize
@@ -376,6 +383,20 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			metadata.Add(size)
 		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
 			bloomBits.Add(size)
+		case bytes.HasPrefix(key, meshHistoryPrefix) && len(key) == len(meshHistoryPrefix)+8:
+			meshHistory.Add(size)
+		case bytes.HasPrefix(key, commitJournalPrefix) && len(key) == len(commitJournalPrefix)+8:
+			commitJournal.Add(size)
+		case bytes.HasPrefix(key, accountabilityGasPrefix) && len(key) == len(accountabilityGasPrefix)+8+common.HashLength:
+			afdGas.Add(size)
+		case bytes.HasPrefix(key, consensusArrivalsPrefix) && len(key) == len(consensusArrivalsPrefix)+8:
+			arrivals.Add(size)
+		case bytes.HasPrefix(key, consensusWALPrefix) && len(key) == len(consensusWALPrefix)+8+common.HashLength:
+			consensusWAL.Add(size)
+		case bytes.HasPrefix(key, msgStorePrefix) && len(key) == msgStoreKeyLength:
+			msgStore.Add(size)
+		case bytes.HasPrefix(key, commitRetryPrefix) && len(key) == len(commitRetryPrefix)+8+common.HashLength:
+			commitRetry.Add(size)
 		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, []byte("cht-")) ||
@@ -392,7 +413,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, lastPivotKey,
 				fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
-				uncleanShutdownKey, badBlockKey, transitionStatusKey,
+				uncleanShutdownKey, badBlockKey, transitionStatusKey, meshHistoryRangeKey,
+				commitJournalRangeKey, accountabilityGasStartKey, consensusRoundStateKey,
+				bloomBitsGenerationsKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -438,6 +461,13 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
 		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
+		{"Key-Value store", "Consensus mesh history", meshHistory.Size(), meshHistory.Count()},
+		{"Key-Value store", "Commit journal", commitJournal.Size(), commitJournal.Count()},
+		{"Key-Value store", "Accountability gas statistics", afdGas.Size(), afdGas.Count()},
+		{"Key-Value store", "Consensus message arrivals", arrivals.Size(), arrivals.Count()},
+		{"Key-Value store", "Consensus round journal", consensusWAL.Size(), consensusWAL.Count()},
+		{"Key-Value store", "Fault detector messages", msgStore.Size(), msgStore.Count()},
+		{"Key-Value store", "Committed blocks to import", commitRetry.Size(), commitRetry.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Ancient store", "Headers", ancientHeadersSize.String(), ancients.String()},
 		{"Ancient store", "Bodies", ancientBodiesSize.String(), ancients.String()},
@@ -0,0 +1,188 @@
+package rawdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/rlp"
+)
+
+const (
+	// headerIteratorFreezerBatch is the number of ancient headers prefetched at once. The freezer
+	// serves contiguous ranges with a single read, so the batches are large.
+	headerIteratorFreezerBatch = 1024
+	// headerIteratorFreezerBytes is the soft limit of the size of a single freezer read.
+	headerIteratorFreezerBytes = 2 * 1024 * 1024
+	// headerIteratorKVBatch is the number of recent headers prefetched at once. The key-value
+	// store serves each header with a point lookup, the batches only bound the prefetched data.
+	headerIteratorKVBatch = 128
+)
+
+// HeaderIterator iterates over the canonical headers of a block range, in ascending or descending
+// order. Ancient headers are read from the freezer and recent ones from the key-value store,
+// including when the range straddles the freezer boundary or when the boundary moves during the
+// iteration. Only headers are read, never the block bodies.
+//
+// The iteration stops at the first missing header or when the context is cancelled, after which
+// Error reports the cause.
+type HeaderIterator struct {
+	ctx  context.Context
+	db   ethdb.Reader
+	next uint64 // number of the next header to fetch
+	last uint64 // number of the last header of the range
+	desc bool
+	done bool // all the headers of the range are fetched
+
+	batch  []*types.Header // prefetched headers, in iteration order
+	header *types.Header
+	err    error
+}
+
+// NewHeaderIterator returns an iterator over the canonical headers in [from, to], or in [to, from]
+// going towards genesis if from is greater than to. The caller is expected to bound the range.
+func NewHeaderIterator(ctx context.Context, db ethdb.Reader, from, to uint64) *HeaderIterator {
+	return &HeaderIterator{ctx: ctx, db: db, next: from, last: to, desc: from > to}
+}
+
+// Next moves the iterator to the next header. It returns false once the range is exhausted,
+// or if the iteration failed.
+func (it *HeaderIterator) Next() bool {
+	it.header = nil
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	if len(it.batch) == 0 {
+		if it.done {
+			return false
+		}
+		if it.err = it.fetch(); it.err != nil {
+			return false
+		}
+	}
+	it.header, it.batch = it.batch[0], it.batch[1:]
+	return true
+}
+
+// Header returns the current header.
+func (it *HeaderIterator) Header() *types.Header {
+	return it.header
+}
+
+// Error returns the error which stopped the iteration, if any.
+func (it *HeaderIterator) Error() error {
+	return it.err
+}
+
+// fetch prefetches the next batch of headers from the source holding the next header.
+func (it *HeaderIterator) fetch() error {
+	frozen, err := it.db.Ancients()
+	if err != nil {
+		// no freezer, all the headers are in the key-value store
+		frozen = 0
+	}
+	var headers []*types.Header
+	if it.next < frozen {
+		headers, err = it.fetchAncients(frozen)
+	} else {
+		headers, err = it.fetchRecent(frozen)
+	}
+	if err != nil {
+		return err
+	}
+	it.batch = headers
+	last := headers[len(headers)-1].Number.Uint64()
+	switch {
+	case last == it.last:
+		it.done = true
+	case it.desc:
+		it.next = last - 1
+	default:
+		it.next = last + 1
+	}
+	return nil
+}
+
+// fetchAncients reads the next batch of headers from the freezer, which holds the headers below frozen.
+func (it *HeaderIterator) fetchAncients(frozen uint64) ([]*types.Header, error) {
+	// the freezer reads ascending ranges, compute the range of the batch
+	start, count := it.next, uint64(headerIteratorFreezerBatch)
+	if it.desc {
+		if it.next-it.last+1 < count {
+			count = it.next - it.last + 1
+		}
+		start = it.next + 1 - count
+	} else {
+		count = min(count, it.last-it.next+1, frozen-it.next)
+	}
+	headers := make([]*types.Header, 0, count)
+	for uint64(len(headers)) < count {
+		number := start + uint64(len(headers))
+		// the reads stop short of the count once over the size limit
+		data, err := it.db.AncientRange(freezerHeaderTable, number, count-uint64(len(headers)), headerIteratorFreezerBytes)
+		if err != nil || len(data) == 0 {
+			return nil, fmt.Errorf("missing header #%d: %v", number, err)
+		}
+		for _, blob := range data {
+			header, err := decodeIteratedHeader(blob, number)
+			if err != nil {
+				return nil, err
+			}
+			headers = append(headers, header)
+			number++
+		}
+	}
+	if it.desc {
+		for i, j := 0, len(headers)-1; i < j; i, j = i+1, j-1 {
+			headers[i], headers[j] = headers[j], headers[i]
+		}
+	}
+	return headers, nil
+}
+
+// fetchRecent reads the next batch of headers from the key-value store, which holds the headers from frozen.
+func (it *HeaderIterator) fetchRecent(frozen uint64) ([]*types.Header, error) {
+	headers := make([]*types.Header, 0, headerIteratorKVBatch)
+	for number := it.next; len(headers) < headerIteratorKVBatch; {
+		data, _ := it.db.Get(headerKey(number, ReadCanonicalHash(it.db, number)))
+		if len(data) == 0 {
+			if len(headers) > 0 {
+				// the rest of the batch may have moved to the freezer, let the next fetch find out
+				break
+			}
+			if current, err := it.db.Ancients(); err == nil && current > frozen {
+				// the header got moved to the freezer in the meantime
+				return it.fetchAncients(current)
+			}
+			return nil, fmt.Errorf("missing header #%d", number)
+		}
+		header, err := decodeIteratedHeader(data, number)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+		if number == it.last || (it.desc && number == frozen) {
+			break
+		}
+		if it.desc {
+			number--
+		} else {
+			number++
+		}
+	}
+	return headers, nil
+}
+
+// decodeIteratedHeader decodes the header of the given number, returning an error instead of logging it.
+func decodeIteratedHeader(data []byte, number uint64) (*types.Header, error) {
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(data, header); err != nil {
+		return nil, fmt.Errorf("invalid header #%d: %w", number, err)
+	}
+	return header, nil
+}
@@ -0,0 +1,167 @@
+package rawdb
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+)
+
+// newHeaderIteratorTestDB creates a chain of n headers whose first `frozen` headers are in the freezer.
+func newHeaderIteratorTestDB(t *testing.T, n, frozen uint64) (ethdb.Database, []*types.Header) {
+	db, err := NewDatabaseWithFreezer(NewMemoryDatabase(), t.TempDir(), "", false)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	headers := make([]*types.Header, n)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(1), Time: uint64(i)}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+	}
+	freezeHeaders(t, db, headers[:frozen])
+	for _, header := range headers[frozen:] {
+		WriteHeader(db, header)
+		WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+	return db, headers
+}
+
+// freezeHeaders moves the headers to the freezer, the way the chain freezer does.
+func freezeHeaders(t *testing.T, db ethdb.Database, headers []*types.Header) {
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+	)
+	for _, header := range headers {
+		blocks = append(blocks, types.NewBlockWithHeader(header))
+		receipts = append(receipts, nil)
+	}
+	_, err := WriteAncientBlocks(db, blocks, receipts, big.NewInt(1))
+	require.NoError(t, err)
+	for _, header := range headers {
+		DeleteHeader(db, header.Hash(), header.Number.Uint64())
+		DeleteCanonicalHash(db, header.Number.Uint64())
+	}
+}
+
+// iterateHeaders returns the numbers of the headers of the range, checking them against the chain.
+func iterateHeaders(t *testing.T, it *HeaderIterator, headers []*types.Header) []uint64 {
+	var numbers []uint64
+	for it.Next() {
+		number := it.Header().Number.Uint64()
+		require.Equal(t, headers[number].Hash(), it.Header().Hash())
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+func headerNumbers(from, to uint64) []uint64 {
+	var numbers []uint64
+	for n := from; ; {
+		numbers = append(numbers, n)
+		switch {
+		case n == to:
+			return numbers
+		case from > to:
+			n--
+		default:
+			n++
+		}
+	}
+}
+
+func TestHeaderIterator(t *testing.T) {
+	const n, frozen = 3000, 2000
+	db, headers := newHeaderIteratorTestDB(t, n, frozen)
+
+	for _, tc := range []struct {
+		name     string
+		from, to uint64
+	}{
+		{"entirely in the freezer", 10, 1500},
+		{"entirely in the freezer, descending", 1999, 0},
+		{"entirely recent", 2000, 2999},
+		{"entirely recent, descending", 2999, 2100},
+		{"straddling the freezer boundary", 0, 2999},
+		{"straddling the freezer boundary, descending", 2999, 0},
+		{"last frozen and first recent headers", 1999, 2000},
+		{"single header", 2000, 2000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			it := NewHeaderIterator(context.Background(), db, tc.from, tc.to)
+			require.Equal(t, headerNumbers(tc.from, tc.to), iterateHeaders(t, it, headers))
+			require.NoError(t, it.Error())
+			require.False(t, it.Next())
+			require.Nil(t, it.Header())
+		})
+	}
+
+	t.Run("missing headers stop the iteration", func(t *testing.T) {
+		it := NewHeaderIterator(context.Background(), db, 1990, n)
+		require.Equal(t, headerNumbers(1990, n-1), iterateHeaders(t, it, headers))
+		require.EqualError(t, it.Error(), "missing header #3000")
+	})
+}
+
+func TestHeaderIteratorWithoutFreezer(t *testing.T) {
+	db := NewMemoryDatabase()
+	var headers []*types.Header
+	for i := 0; i < 300; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: big.NewInt(1)}
+		WriteHeader(db, header)
+		WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+		headers = append(headers, header)
+	}
+	it := NewHeaderIterator(context.Background(), db, 299, 0)
+	require.Equal(t, headerNumbers(299, 0), iterateHeaders(t, it, headers))
+	require.NoError(t, it.Error())
+}
+
+func TestHeaderIteratorFreezerMove(t *testing.T) {
+	db, headers := newHeaderIteratorTestDB(t, 1000, 100)
+
+	it := NewHeaderIterator(context.Background(), db, 0, 999)
+	var numbers []uint64
+	for i := 0; i < 150 && it.Next(); i++ {
+		numbers = append(numbers, it.Header().Number.Uint64())
+	}
+	// the recent headers of the next batches are moved to the freezer during the iteration
+	freezeHeaders(t, db, headers[100:600])
+	numbers = append(numbers, iterateHeaders(t, it, headers)...)
+	require.NoError(t, it.Error())
+	require.Equal(t, headerNumbers(0, 999), numbers)
+}
+
+func TestHeaderIteratorCancellation(t *testing.T) {
+	db, headers := newHeaderIteratorTestDB(t, 3000, 2000)
+
+	for _, tc := range []struct {
+		name     string
+		from, to uint64
+	}{
+		{"ascending", 0, 2999},
+		{"descending", 2999, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			it := NewHeaderIterator(ctx, db, tc.from, tc.to)
+			count := 0
+			for it.Next() {
+				require.Equal(t, headers[it.Header().Number.Uint64()].Hash(), it.Header().Hash())
+				if count++; count == 1500 {
+					cancel()
+				}
+			}
+			require.Equal(t, 1500, count)
+			require.ErrorIs(t, it.Error(), context.Canceled)
+			require.False(t, it.Next())
+		})
+	}
+}
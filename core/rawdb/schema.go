@@ -78,6 +78,21 @@ var (
 	// transitionStatusKey tracks the eth2 transition status.
 	transitionStatusKey = []byte("eth2-transition")
 
+	// meshHistoryRangeKey tracks the sequence numbers of the oldest and of the next consensus mesh history entries.
+	meshHistoryRangeKey = []byte("MeshHistoryRange")
+
+	// commitJournalRangeKey tracks the sequence numbers of the oldest and of the next commit journal entries.
+	commitJournalRangeKey = []byte("CommitJournalRange")
+
+	// accountabilityGasStartKey tracks the first block indexed by the accountability gas statistics.
+	accountabilityGasStartKey = []byte("AccountabilityGasStart")
+
+	// consensusRoundStateKey tracks the consensus round state of the height in progress.
+	consensusRoundStateKey = []byte("ConsensusRoundState")
+
+	// bloomBitsGenerationsKey tracks the section sizes the bloombits index was generated with.
+	bloomBitsGenerationsKey = []byte("BloomBitsGenerations")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -92,6 +107,14 @@ var (
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
+	meshHistoryPrefix     = []byte("M") // meshHistoryPrefix + seq (uint64 big endian) -> consensus mesh history entry
+	commitJournalPrefix   = []byte("J") // commitJournalPrefix + seq (uint64 big endian) -> commit journal entry
+
+	accountabilityGasPrefix = []byte("G") // accountabilityGasPrefix + num (uint64 big endian) + hash -> accountability gas statistics
+	consensusArrivalsPrefix = []byte("A") // consensusArrivalsPrefix + num (uint64 big endian) -> first arrival times of the consensus messages
+	consensusWALPrefix      = []byte("W") // consensusWALPrefix + num (uint64 big endian) + hash -> consensus message journaled at the height
+	msgStorePrefix          = []byte("F") // msgStorePrefix + num (uint64 big endian) + signer (uint32 big endian) + code + hash -> consensus message of the fault detector
+	commitRetryPrefix       = []byte("Q") // commitRetryPrefix + num (uint64 big endian) + hash -> committed block whose import failed
 
 	PreimagePrefix = []byte("secure-key-")      // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
@@ -233,3 +256,44 @@ func IsCodeKey(key []byte) (bool, []byte) {
 func configKey(hash common.Hash) []byte {
 	return append(configPrefix, hash.Bytes()...)
 }
+
+// meshHistoryKey = meshHistoryPrefix + seq (uint64 big endian)
+func meshHistoryKey(seq uint64) []byte {
+	return append(meshHistoryPrefix, encodeBlockNumber(seq)...)
+}
+
+// commitJournalKey = commitJournalPrefix + seq (uint64 big endian)
+func commitJournalKey(seq uint64) []byte {
+	return append(commitJournalPrefix, encodeBlockNumber(seq)...)
+}
+
+// accountabilityGasKey = accountabilityGasPrefix + num (uint64 big endian) + hash
+func accountabilityGasKey(number uint64, hash common.Hash) []byte {
+	return append(append(accountabilityGasPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// consensusArrivalsKey = consensusArrivalsPrefix + num (uint64 big endian)
+func consensusArrivalsKey(number uint64) []byte {
+	return append(consensusArrivalsPrefix, encodeBlockNumber(number)...)
+}
+
+// consensusWALKey = consensusWALPrefix + num (uint64 big endian) + hash
+func consensusWALKey(number uint64, hash common.Hash) []byte {
+	return append(append(consensusWALPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// msgStoreKeyLength is the length of the keys of the consensus messages of the fault detector.
+const msgStoreKeyLength = 1 + 8 + 4 + 1 + common.HashLength
+
+// msgStoreKey = msgStorePrefix + num (uint64 big endian) + signer (uint32 big endian) + code + hash
+func msgStoreKey(number uint64, signer uint32, code uint8, hash common.Hash) []byte {
+	key := make([]byte, 0, msgStoreKeyLength)
+	key = append(append(key, msgStorePrefix...), encodeBlockNumber(number)...)
+	key = binary.BigEndian.AppendUint32(key, signer)
+	return append(append(key, code), hash.Bytes()...)
+}
+
+// commitRetryKey = commitRetryPrefix + num (uint64 big endian) + hash
+func commitRetryKey(number uint64, hash common.Hash) []byte {
+	return append(append(commitRetryPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/state/snapshot"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/trie"
+)
+
+var (
+	// ErrPartialState is returned when reading an account out of a partial state.
+	ErrPartialState = errors.New("state not available, the node is syncing")
+
+	errPartialStateReadOnly = errors.New("partial state is read-only")
+)
+
+// NewPartialDatabase creates a read-only state database holding the given accounts only, along with their
+// code and storage. The accounts are read from the flat snapshot entries persisted by snap sync, the state
+// trie being incomplete until the sync is done, and reading any other account fails with ErrPartialState.
+// It is meant to serve the state of the protocol contracts while the rest of the state is synced.
+func NewPartialDatabase(db ethdb.Database, accounts []common.Address) Database {
+	hashes := make(map[common.Hash]bool, len(accounts))
+	for _, account := range accounts {
+		hashes[crypto.Keccak256Hash(account.Bytes())] = true
+	}
+	return &partialDB{Database: NewDatabase(db), disk: db, accounts: hashes}
+}
+
+type partialDB struct {
+	Database // contract code, which is complete once the accounts are synced
+	disk     ethdb.KeyValueReader
+	accounts map[common.Hash]bool // hashes of the accounts held
+}
+
+// OpenTrie opens the account trie at the given root, which must be the root the accounts were synced at.
+func (db *partialDB) OpenTrie(root common.Hash) (Trie, error) {
+	return &partialTrie{db: db, root: root}, nil
+}
+
+// OpenStorageTrie opens the storage trie of an account held by the database.
+func (db *partialDB) OpenStorageTrie(addrHash, root common.Hash) (Trie, error) {
+	if !db.accounts[addrHash] {
+		return nil, ErrPartialState
+	}
+	return &partialTrie{db: db, root: root, account: addrHash, storage: true}, nil
+}
+
+// CopyTrie returns a copy of the given trie, which is read-only and can be shared.
+func (db *partialDB) CopyTrie(t Trie) Trie {
+	return t
+}
+
+// partialTrie reads the accounts or the storage of an account of a partial database.
+type partialTrie struct {
+	db      *partialDB
+	root    common.Hash
+	account common.Hash // account of the storage trie
+	storage bool
+}
+
+func (t *partialTrie) GetKey([]byte) []byte {
+	return nil
+}
+
+func (t *partialTrie) TryGet(key []byte) ([]byte, error) {
+	hash := crypto.Keccak256Hash(key)
+	if t.storage {
+		return rawdb.ReadStorageSnapshot(t.db.disk, t.account, hash), nil
+	}
+	if !t.db.accounts[hash] {
+		return nil, ErrPartialState
+	}
+	data := rawdb.ReadAccountSnapshot(t.db.disk, hash)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return snapshot.FullAccountRLP(data)
+}
+
+func (t *partialTrie) TryUpdateAccount([]byte, *types.StateAccount) error {
+	return errPartialStateReadOnly
+}
+
+func (t *partialTrie) TryUpdate(_, _ []byte) error {
+	return errPartialStateReadOnly
+}
+
+func (t *partialTrie) TryDelete([]byte) error {
+	return errPartialStateReadOnly
+}
+
+func (t *partialTrie) Hash() common.Hash {
+	return t.root
+}
+
+func (t *partialTrie) Commit(trie.LeafCallback) (common.Hash, int, error) {
+	return common.Hash{}, 0, errPartialStateReadOnly
+}
+
+// NodeIterator iterates no node, the partial state having no trie.
+func (t *partialTrie) NodeIterator(start []byte) trie.NodeIterator {
+	empty, _ := trie.NewSecure(common.Hash{}, t.db.TrieDB())
+	return empty.NodeIterator(start)
+}
+
+func (t *partialTrie) Prove([]byte, uint, ethdb.KeyValueWriter) error {
+	return ErrPartialState
+}
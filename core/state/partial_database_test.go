@@ -0,0 +1,66 @@
+package state
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/state/snapshot"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/rlp"
+)
+
+func TestPartialDatabase(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		held    = common.HexToAddress("0x01")
+		other   = common.HexToAddress("0x02")
+		absent  = common.HexToAddress("0x03")
+		code    = []byte{0x60, 0x00}
+		key     = common.HexToHash("0x0a")
+		value   = common.HexToHash("0x0b")
+		root    = common.HexToHash("0xff")
+		balance = big.NewInt(100)
+	)
+	// the accounts synced by snap sync, as flat snapshot entries only
+	for _, account := range []common.Address{held, other} {
+		hash := crypto.Keccak256Hash(account.Bytes())
+		rawdb.WriteAccountSnapshot(db, hash, snapshot.SlimAccountRLP(1, balance, common.HexToHash("0x1234"), crypto.Keccak256(code)))
+		enc, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(value[:]))
+		rawdb.WriteStorageSnapshot(db, hash, crypto.Keccak256Hash(key[:]), enc)
+	}
+	rawdb.WriteCode(db, crypto.Keccak256Hash(code), code)
+
+	statedb, err := New(root, NewPartialDatabase(db, []common.Address{held, absent}), nil)
+	if err != nil {
+		t.Fatalf("failed to open partial state: %v", err)
+	}
+	if have := statedb.GetBalance(held); have.Cmp(balance) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", have, balance)
+	}
+	if have := statedb.GetNonce(held); have != 1 {
+		t.Errorf("nonce mismatch: have %d, want 1", have)
+	}
+	if have := statedb.GetCode(held); string(have) != string(code) {
+		t.Errorf("code mismatch: have %x, want %x", have, code)
+	}
+	if have := statedb.GetState(held, key); have != value {
+		t.Errorf("storage mismatch: have %x, want %x", have, value)
+	}
+	if statedb.Exist(absent) {
+		t.Error("absent account exists")
+	}
+	if err := statedb.Error(); err != nil {
+		t.Fatalf("partial state failed: %v", err)
+	}
+
+	// the accounts out of the partial state are not available, even if synced already
+	if have := statedb.GetBalance(other); have.Sign() != 0 {
+		t.Errorf("balance of unavailable account: %v", have)
+	}
+	if err := statedb.Error(); err == nil || !strings.Contains(err.Error(), ErrPartialState.Error()) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrPartialState)
+	}
+}
@@ -96,6 +96,9 @@ type StateDB struct {
 
 	preimages map[common.Hash][]byte
 
+	// runs of the accounted precompiled contracts
+	precompileRuns map[common.Address]*types.PrecompileStats
+
 	// Per-transaction access list
 	accessList *accessList
 
@@ -140,6 +143,7 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error)
 		stateObjectsDirty:   make(map[common.Address]struct{}),
 		logs:                make(map[common.Hash][]*types.Log),
 		preimages:           make(map[common.Hash][]byte),
+		precompileRuns:      make(map[common.Address]*types.PrecompileStats),
 		journal:             newJournal(),
 		accessList:          newAccessList(),
 		hasher:              crypto.NewKeccakState(),
@@ -228,6 +232,22 @@ func (s *StateDB) Preimages() map[common.Hash][]byte {
 	return s.preimages
 }
 
+// AddPrecompileRun records the run of an accounted precompiled contract. The runs are not
+// reverted along with the state, as the gas they used is consumed.
+func (s *StateDB) AddPrecompileRun(addr common.Address, gasUsed uint64, success bool) {
+	stats, ok := s.precompileRuns[addr]
+	if !ok {
+		stats = new(types.PrecompileStats)
+		s.precompileRuns[addr] = stats
+	}
+	stats.Add(gasUsed, success)
+}
+
+// PrecompileRuns returns the runs of the accounted precompiled contracts, by address.
+func (s *StateDB) PrecompileRuns() map[common.Address]*types.PrecompileStats {
+	return s.precompileRuns
+}
+
 // AddRefund adds gas to the refund counter
 func (s *StateDB) AddRefund(gas uint64) {
 	s.journal.append(refundChange{prev: s.refund})
@@ -655,6 +675,7 @@ func (s *StateDB) Copy() *StateDB {
 		logs:                make(map[common.Hash][]*types.Log, len(s.logs)),
 		logSize:             s.logSize,
 		preimages:           make(map[common.Hash][]byte, len(s.preimages)),
+		precompileRuns:      make(map[common.Address]*types.PrecompileStats, len(s.precompileRuns)),
 		journal:             newJournal(),
 		hasher:              crypto.NewKeccakState(),
 	}
@@ -700,6 +721,10 @@ func (s *StateDB) Copy() *StateDB {
 	for hash, preimage := range s.preimages {
 		state.preimages[hash] = preimage
 	}
+	for addr, stats := range s.precompileRuns {
+		cpy := *stats
+		state.precompileRuns[addr] = &cpy
+	}
 	// Do we need to copy the access list? In practice: No. At the start of a
 	// transaction, the access list is empty. In practice, we only ever copy state
 	// _between_ transactions/blocks, never in the middle of a transaction.
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/trie"
+)
+
+// DefaultStateGuardFailures is the number of consecutive failures to read the state at a root after
+// which a StateGuard stops reading it.
+const DefaultStateGuardFailures = 3
+
+// ErrStateUnavailable is returned by a StateGuard for a root it stopped reading.
+var ErrStateUnavailable = errors.New("state unavailable")
+
+// StateGuard reads the state for the components which read it at every block or message and treat
+// a failure as transient, such as the committee watchers and the fault detector. After maxFailures
+// consecutive failures for the same root, the root is given up: the failure is logged once at error
+// level along with its likely cause, the guard reports itself unhealthy and the root is not read
+// again. The state is read again once asked for a different root, as the head moves.
+type StateGuard struct {
+	name        string
+	stateAt     func(root common.Hash) (*state.StateDB, error)
+	maxFailures int
+	log         log.Logger
+	healthy     atomic.Bool
+
+	mu       sync.Mutex
+	root     common.Hash // root of the last failed read
+	failures int         // number of consecutive failures for root
+}
+
+// NewStateGuard returns a healthy guard of the given state reader.
+func NewStateGuard(name string, stateAt func(root common.Hash) (*state.StateDB, error), maxFailures int, logger log.Logger) *StateGuard {
+	g := &StateGuard{
+		name:        name,
+		stateAt:     stateAt,
+		maxFailures: max(maxFailures, 1),
+		log:         logger,
+	}
+	g.healthy.Store(true)
+	return g
+}
+
+// StateAt returns the state at root, or ErrStateUnavailable without reading it if root was given up.
+func (g *StateGuard) StateAt(root common.Hash) (*state.StateDB, error) {
+	g.mu.Lock()
+	if g.failures >= g.maxFailures && root == g.root {
+		g.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s at root %s", ErrStateUnavailable, g.name, root)
+	}
+	g.mu.Unlock()
+
+	statedb, err := g.stateAt(root)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err == nil {
+		if !g.healthy.Load() {
+			g.log.Info("State available again", "reader", g.name, "root", root)
+		}
+		g.root, g.failures = common.Hash{}, 0
+		g.healthy.Store(true)
+		return statedb, nil
+	}
+	if root != g.root {
+		g.root, g.failures = root, 0
+	}
+	g.failures++
+	if g.failures < g.maxFailures {
+		g.log.Debug("Could not retrieve state", "reader", g.name, "root", root, "failures", g.failures, "err", err)
+		return nil, err
+	}
+	g.log.Error("Could not retrieve state, giving up until the head moves", "reader", g.name, "root", root,
+		"cause", StateFailureCause(root, err), "failures", g.failures, "err", err)
+	g.healthy.Store(false)
+	return nil, err
+}
+
+// Healthy reports whether the last root read was not given up.
+func (g *StateGuard) Healthy() bool {
+	return g.healthy.Load()
+}
+
+// StateFailureCause returns the likely cause of the failure to open the state at root:
+//   - "pruned" if the root node is missing, as for the states garbage collected from memory or never persisted,
+//   - "missing" if an inner node is missing, as for a state left incomplete by an interrupted sync,
+//   - "corrupted" for any other failure.
+func StateFailureCause(root common.Hash, err error) string {
+	var missing *trie.MissingNodeError
+	if !errors.As(err, &missing) {
+		return "corrupted"
+	}
+	if missing.NodeHash == root {
+		return "pruned"
+	}
+	return "missing"
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/trie"
+)
+
+// stateGuardTest is a state reader failing as configured, whose reads and error logs are counted.
+type stateGuardTest struct {
+	failing map[common.Hash]int // number of failures left by root, -1 to fail forever
+	err     error
+	reads   int
+	errors  []*log.Record
+}
+
+func (s *stateGuardTest) stateAt(root common.Hash) (*state.StateDB, error) {
+	s.reads++
+	if left := s.failing[root]; left != 0 {
+		s.failing[root] = left - 1
+		return nil, s.err
+	}
+	return state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+}
+
+func newStateGuardTest() (*stateGuardTest, *StateGuard) {
+	s := &stateGuardTest{failing: make(map[common.Hash]int), err: errors.New("failure")}
+	logger := log.New()
+	logger.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		if r.Lvl == log.LvlError {
+			s.errors = append(s.errors, r)
+		}
+		return nil
+	}))
+	return s, NewStateGuard("test", s.stateAt, 3, logger)
+}
+
+func TestStateGuardTransientFailures(t *testing.T) {
+	s, guard := newStateGuardTest()
+	root := common.HexToHash("0x01")
+	s.failing[root] = 2
+
+	for i := 0; i < 2; i++ {
+		_, err := guard.StateAt(root)
+		require.ErrorIs(t, err, s.err)
+		require.True(t, guard.Healthy())
+	}
+	statedb, err := guard.StateAt(root)
+	require.NoError(t, err)
+	require.NotNil(t, statedb)
+	require.True(t, guard.Healthy())
+	require.Empty(t, s.errors)
+	require.Equal(t, 3, s.reads)
+
+	// the failures are counted again from zero after a success
+	s.failing[root] = 2
+	for i := 0; i < 2; i++ {
+		_, err = guard.StateAt(root)
+		require.ErrorIs(t, err, s.err)
+	}
+	require.True(t, guard.Healthy())
+	require.Empty(t, s.errors)
+}
+
+func TestStateGuardPersistentFailures(t *testing.T) {
+	s, guard := newStateGuardTest()
+	s.err = &trie.MissingNodeError{NodeHash: common.HexToHash("0x01")}
+	pruned, next := common.HexToHash("0x01"), common.HexToHash("0x02")
+	s.failing[pruned] = -1
+
+	for i := 0; i < 3; i++ {
+		_, err := guard.StateAt(pruned)
+		require.ErrorIs(t, err, s.err)
+	}
+	require.False(t, guard.Healthy())
+	require.Len(t, s.errors, 1)
+	require.Contains(t, s.errors[0].Ctx, "pruned")
+
+	// the root given up is not read again, nor logged
+	for i := 0; i < 10; i++ {
+		_, err := guard.StateAt(pruned)
+		require.ErrorIs(t, err, ErrStateUnavailable)
+	}
+	require.Equal(t, 3, s.reads)
+	require.Len(t, s.errors, 1)
+	require.False(t, guard.Healthy())
+
+	// the state is read again once the head root changes
+	_, err := guard.StateAt(next)
+	require.NoError(t, err)
+	require.True(t, guard.Healthy())
+	require.Equal(t, 4, s.reads)
+
+	// and the former root is read again too
+	_, err = guard.StateAt(pruned)
+	require.ErrorIs(t, err, s.err)
+	require.Equal(t, 5, s.reads)
+}
+
+func TestStateGuardFailuresAcrossRoots(t *testing.T) {
+	s, guard := newStateGuardTest()
+	first, second := common.HexToHash("0x01"), common.HexToHash("0x02")
+	s.failing[first], s.failing[second] = -1, -1
+
+	// the consecutive failures are counted by root
+	for i := 0; i < 2; i++ {
+		_, err := guard.StateAt(first)
+		require.ErrorIs(t, err, s.err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := guard.StateAt(second)
+		require.ErrorIs(t, err, s.err)
+	}
+	require.True(t, guard.Healthy())
+	require.Empty(t, s.errors)
+
+	_, err := guard.StateAt(second)
+	require.ErrorIs(t, err, s.err)
+	require.False(t, guard.Healthy())
+	require.Len(t, s.errors, 1)
+	require.Contains(t, s.errors[0].Ctx, "corrupted")
+}
+
+func TestStateFailureCause(t *testing.T) {
+	root := common.HexToHash("0x01")
+	require.Equal(t, "pruned", StateFailureCause(root, &trie.MissingNodeError{NodeHash: root}))
+	require.Equal(t, "missing", StateFailureCause(root, &trie.MissingNodeError{NodeHash: common.HexToHash("0x02"), Path: []byte{1}}))
+	require.Equal(t, "corrupted", StateFailureCause(root, errors.New("rlp: invalid node")))
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/rlp"
+	"github.com/autonity/autonity/trie"
+)
+
+// ErrCorruptedState is returned by VerifyState for a state holding a node or a code whose content
+// does not match its hash.
+var ErrCorruptedState = errors.New("corrupted state")
+
+// VerifyState walks the account and storage tries of the given state root and checks that every
+// node and code read from the database hashes to the key it is stored under. It reads the whole
+// state and should only be used to find a state to recover from.
+func (bc *BlockChain) VerifyState(root common.Hash) error {
+	triedb := bc.stateCache.TrieDB()
+	accounts, err := trie.NewSecure(root, triedb)
+	if err != nil {
+		return err
+	}
+	it := accounts.NodeIterator(nil)
+	for it.Next(true) {
+		if err := verifyTrieNode(triedb, it.Hash()); err != nil {
+			return err
+		}
+		if !it.Leaf() {
+			continue
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(it.LeafBlob(), &account); err != nil {
+			return fmt.Errorf("%w: invalid account %x: %v", ErrCorruptedState, it.LeafKey(), err)
+		}
+		if account.Root != types.EmptyRootHash {
+			storage, err := trie.NewSecure(account.Root, triedb)
+			if err != nil {
+				return err
+			}
+			storageIt := storage.NodeIterator(nil)
+			for storageIt.Next(true) {
+				if err := verifyTrieNode(triedb, storageIt.Hash()); err != nil {
+					return err
+				}
+			}
+			if err := storageIt.Error(); err != nil {
+				return err
+			}
+		}
+		if !bytes.Equal(account.CodeHash, emptyCodeHash[:]) {
+			hash := common.BytesToHash(account.CodeHash)
+			if code, err := bc.stateCache.ContractCode(common.Hash{}, hash); err != nil {
+				return err
+			} else if crypto.Keccak256Hash(code) != hash {
+				return fmt.Errorf("%w: code %x", ErrCorruptedState, hash)
+			}
+		}
+	}
+	return it.Error()
+}
+
+// verifyTrieNode checks the content of the node stored under hash, the embedded nodes having no
+// hash of their own are checked along with their parent.
+func verifyTrieNode(triedb *trie.Database, hash common.Hash) error {
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	blob, err := triedb.Node(hash)
+	if err != nil {
+		return err
+	}
+	if crypto.Keccak256Hash(blob) != hash {
+		return fmt.Errorf("%w: trie node %x", ErrCorruptedState, hash)
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"sort"
@@ -85,6 +86,10 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrFeeCapBelowMinBaseFee is returned in strict mode if a transaction's fee cap
+	// is below the protocol minimum base fee, as it could never be included.
+	ErrFeeCapBelowMinBaseFee = errors.New("fee cap less than Autonity minimum base fee")
 )
 
 var (
@@ -166,6 +171,8 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	StrictMinBaseFee bool // Whether to reject transactions whose fee cap is below the protocol minimum base fee
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -183,6 +190,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	StrictMinBaseFee: true,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -248,6 +257,7 @@ type TxPool struct {
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
+	minBaseFee    *big.Int       // Protocol minimum base fee at the current head
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
@@ -319,9 +329,26 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 
-		if err := pool.journal.load(pool.AddLocals); err != nil {
+		// The journaled transactions are validated against the current minimum base fee,
+		// which might have been raised since they were submitted.
+		stale := 0
+		err := pool.journal.load(func(txs []*types.Transaction) []error {
+			errs := pool.AddLocals(txs)
+			for _, err := range errs {
+				if errors.Is(err, ErrFeeCapBelowMinBaseFee) {
+					stale++
+				}
+			}
+			return errs
+		})
+		if err != nil {
 			log.Warn("Failed to load transaction journal", "err", err)
 		}
+		if stale > 0 {
+			pool.mu.RLock()
+			log.Warn("Dropped journaled transactions below the minimum base fee", "count", stale, "minBaseFee", pool.minBaseFee)
+			pool.mu.RUnlock()
+		}
 		if err := pool.journal.rotate(pool.local()); err != nil {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
@@ -642,8 +669,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return err
 	}
 
-	if pool.chain.MinBaseFee().Cmp(tx.GasPrice()) > 0 {
-		return errors.New("fee cap less than Autonity minimum base fee")
+	// Reject the transactions which cannot be included unless the minimum base fee decreases
+	if pool.config.StrictMinBaseFee && tx.GasFeeCapIntCmp(pool.minBaseFee) < 0 {
+		return fmt.Errorf("%w: fee cap %v, minimum base fee %v", ErrFeeCapBelowMinBaseFee, tx.GasFeeCap(), pool.minBaseFee)
 	}
 
 	if tx.Gas() < intrGas {
@@ -1302,6 +1330,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.minBaseFee = pool.chain.MinBaseFee()
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
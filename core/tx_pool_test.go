@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -2355,6 +2356,125 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// minBaseFeeTestChain is a test chain whose protocol minimum base fee can be updated.
+type minBaseFeeTestChain struct {
+	*testBlockChain
+	minBaseFee atomic.Pointer[big.Int]
+}
+
+func newMinBaseFeeTestChain(statedb *state.StateDB, minBaseFee int64) *minBaseFeeTestChain {
+	bc := &minBaseFeeTestChain{testBlockChain: &testBlockChain{1000000, statedb, new(event.Feed)}}
+	bc.minBaseFee.Store(big.NewInt(minBaseFee))
+	return bc
+}
+
+func (bc *minBaseFeeTestChain) MinBaseFee() *big.Int {
+	return bc.minBaseFee.Load()
+}
+
+// Tests that in strict mode, transactions whose fee cap is below the protocol
+// minimum base fee at the current head are rejected at submission.
+func TestTransactionStrictMinBaseFee(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newMinBaseFeeTestChain(statedb, 100)
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain, NewTxSenderCacher())
+	defer pool.Stop()
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Transactions below the floor are rejected, with the current minimum in the error
+	err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(99), local))
+	if !errors.Is(err, ErrFeeCapBelowMinBaseFee) {
+		t.Fatalf("local transaction below the minimum base fee error mismatch: have %v, want %v", err, ErrFeeCapBelowMinBaseFee)
+	}
+	if want := "fee cap less than Autonity minimum base fee: fee cap 99, minimum base fee 100"; err.Error() != want {
+		t.Fatalf("error message mismatch: have %q, want %q", err, want)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(0, 100000, big.NewInt(99), big.NewInt(1), remote)); !errors.Is(err, ErrFeeCapBelowMinBaseFee) {
+		t.Fatalf("remote transaction below the minimum base fee error mismatch: have %v, want %v", err, ErrFeeCapBelowMinBaseFee)
+	}
+	// Transactions at or above the floor are accepted
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(100), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(0, 100000, big.NewInt(200), big.NewInt(1), remote)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	// The floor is cached per head, it is only applied once the pool is reset to a new head
+	blockchain.minBaseFee.Store(big.NewInt(150))
+	if err := pool.AddLocal(pricedTransaction(1, 100000, big.NewInt(120), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	<-pool.requestReset(nil, nil)
+	if err := pool.AddLocal(pricedTransaction(2, 100000, big.NewInt(120), local)); !errors.Is(err, ErrFeeCapBelowMinBaseFee) {
+		t.Fatalf("transaction below the raised minimum base fee error mismatch: have %v, want %v", err, ErrFeeCapBelowMinBaseFee)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+
+	// Without strict mode, the transactions below the floor are accepted
+	config := testTxPoolConfig
+	config.StrictMinBaseFee = false
+	lenient := NewTxPool(config, params.TestChainConfig, blockchain, NewTxSenderCacher())
+	defer lenient.Stop()
+
+	if err := lenient.AddLocal(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction in lenient mode: %v", err)
+	}
+}
+
+// Tests that the journaled transactions are validated against the current
+// protocol minimum base fee, dropping those which cannot be included anymore.
+func TestTransactionJournalingMinBaseFee(t *testing.T) {
+	t.Parallel()
+
+	journal := filepath.Join(t.TempDir(), "transactions.rlp")
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	config := testTxPoolConfig
+	config.Journal = journal
+	config.Rejournal = time.Second
+
+	pool := NewTxPool(config, params.TestChainConfig, newMinBaseFeeTestChain(statedb, 10), NewTxSenderCacher())
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	for i, feeCap := range []int64{10, 49, 50} {
+		if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(feeCap), keys[i])); err != nil {
+			t.Fatalf("failed to add local transaction: %v", err)
+		}
+	}
+	pool.Stop()
+
+	// Restart the pool after the floor got raised
+	pool = NewTxPool(config, params.TestChainConfig, newMinBaseFeeTestChain(statedb, 50), NewTxSenderCacher())
+	defer pool.Stop()
+
+	pending, queued := pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	if txs := pool.Pending(false)[crypto.PubkeyToAddress(keys[2].PublicKey)]; len(txs) != 1 {
+		t.Fatalf("transaction above the raised minimum base fee not restored")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
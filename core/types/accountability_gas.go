@@ -0,0 +1,44 @@
+package types
+
+// PrecompileStats accounts for the runs of a precompiled contract.
+type PrecompileStats struct {
+	Calls    uint64 // runs of the contract, including the failed ones
+	Failures uint64 // runs which failed, or whose output is not a success
+	GasUsed  uint64
+}
+
+// Add records a run of the contract.
+func (s *PrecompileStats) Add(gasUsed uint64, success bool) {
+	s.Calls++
+	if !success {
+		s.Failures++
+	}
+	s.GasUsed += gasUsed
+}
+
+// Merge adds the runs accounted by other.
+func (s *PrecompileStats) Merge(other PrecompileStats) {
+	s.Calls += other.Calls
+	s.Failures += other.Failures
+	s.GasUsed += other.GasUsed
+}
+
+// AccountabilityGasStats accounts for the runs of the precompiled contracts verifying the proofs of the
+// accountability events, over a block or a range of blocks.
+type AccountabilityGasStats struct {
+	Accusation   PrecompileStats
+	Innocence    PrecompileStats
+	Misbehaviour PrecompileStats
+}
+
+// Merge adds the runs accounted by other.
+func (s *AccountabilityGasStats) Merge(other *AccountabilityGasStats) {
+	s.Accusation.Merge(other.Accusation)
+	s.Innocence.Merge(other.Innocence)
+	s.Misbehaviour.Merge(other.Misbehaviour)
+}
+
+// Empty returns whether no run is accounted.
+func (s *AccountabilityGasStats) Empty() bool {
+	return s.Accusation.Calls == 0 && s.Innocence.Calls == 0 && s.Misbehaviour.Calls == 0
+}
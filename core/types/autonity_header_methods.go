@@ -11,16 +11,29 @@ func (h *Header) IsGenesis() bool {
 }
 
 // CommitteeMember returns the committee member having the given address or
-// nil if there is none.
+// nil if there is none. The lookup is constant time, the committee being
+// indexed by address on the first lookup.
 func (h *Header) CommitteeMember(address common.Address) *CommitteeMember {
+	if i := h.MemberIndex(address); i >= 0 {
+		return &h.Committee[i]
+	}
+	return nil
+}
+
+// MemberIndex returns the index in the committee of the member having the
+// given address, or -1 if there is none.
+func (h *Header) MemberIndex(address common.Address) int {
 	h.once.Do(func() {
-		h.committeeMap = make(map[common.Address]*CommitteeMember)
+		h.committeeIndex = make(map[common.Address]int, len(h.Committee))
 		for i := range h.Committee {
-			member := h.Committee[i]
-			h.committeeMap[member.Address] = &member
+			h.committeeIndex[h.Committee[i].Address] = i
 		}
 	})
-	return h.committeeMap[address]
+	// the committee may have been replaced since it was indexed
+	if i, ok := h.committeeIndex[address]; ok && i < len(h.Committee) && h.Committee[i].Address == address {
+		return i
+	}
+	return -1
 }
 
 // TotalVotingPower returns the total voting power contained in the committee
@@ -37,3 +50,10 @@ func (c Committee) TotalVotingPower() *big.Int {
 	}
 	return total
 }
+
+// Hash returns the keccak256 hash of the RLP encoding of the committee. The fields derived locally from
+// the encoded ones, the parsed consensus keys and the indexes, are not part of it, so the hash of a
+// committee is stable across encoding and decoding.
+func (c Committee) Hash() common.Hash {
+	return rlpHash(c)
+}
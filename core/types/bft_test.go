@@ -7,8 +7,56 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/rlp"
 )
 
+func TestCommitteeHash(t *testing.T) {
+	testKey, _ := blst.SecretKeyFromHex("667e85b8b64622c4b8deadf59964e4c6ae38768a54dbbbc8bbd926777b896584")
+	committee := Committee{
+		{
+			Address:           common.HexToAddress("0x1234566"),
+			VotingPower:       new(big.Int).SetUint64(12),
+			ConsensusKeyBytes: testKey.PublicKey().Marshal(),
+		},
+		{
+			Address:           common.HexToAddress("0x13371337"),
+			VotingPower:       new(big.Int).SetUint64(1337),
+			ConsensusKeyBytes: testKey.PublicKey().Marshal(),
+		},
+	}
+	hash := committee.Hash()
+
+	// the locally derived fields are not hashed
+	if err := committee.Enrich(); err != nil {
+		t.Fatal(err)
+	}
+	if committee.Hash() != hash {
+		t.Fatal("committee hash changed by the derived fields")
+	}
+
+	// the hash is stable across encoding and decoding
+	encoded, err := rlp.EncodeToBytes(committee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Committee
+	if err := rlp.DecodeBytes(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != hash {
+		t.Fatalf("decoded committee hash %v, want %v", decoded.Hash(), hash)
+	}
+
+	// but it covers the members, their attributes and their order
+	decoded[1].VotingPower = new(big.Int).SetUint64(1338)
+	if decoded.Hash() == hash {
+		t.Fatal("committee hash unchanged by the voting power")
+	}
+	if (Committee{committee[1], committee[0]}).Hash() == hash {
+		t.Fatal("committee hash unchanged by the members order")
+	}
+}
+
 func TestHeaderHash(t *testing.T) {
 	originalHeader := Header{
 		ParentHash:  common.HexToHash("0000H45H"),
@@ -52,7 +100,7 @@ func TestHeaderHash(t *testing.T) {
 			originalHeaderHash,
 		},
 		{
-			setExtra(originalHeader, headerExtra{}),
+			setExtra(originalHeader, HeaderExtra{}),
 			originalHeaderHash,
 		},
 
@@ -62,13 +110,13 @@ func TestHeaderHash(t *testing.T) {
 			posHeaderHash,
 		},
 		{
-			setExtra(PosHeader, headerExtra{
+			setExtra(PosHeader, HeaderExtra{
 				QuorumCertificate: quorumCertificate,
 			}),
 			posHeaderHash,
 		},
 		{
-			setExtra(PosHeader, headerExtra{
+			setExtra(PosHeader, HeaderExtra{
 				Committee: Committee{
 					{
 						Address:           common.HexToAddress("0x1234566"),
@@ -87,25 +135,25 @@ func TestHeaderHash(t *testing.T) {
 			common.HexToHash("0xe81df587da3150a831fa0f13f9386ef3abd962f880251e65963547dbba84a703"),
 		},
 		{
-			setExtra(PosHeader, headerExtra{
+			setExtra(PosHeader, HeaderExtra{
 				ProposerSeal: common.Hex2Bytes("0xbebedead"),
 			}),
 			common.HexToHash("0xebec6824a0f6a3870d987f61c23909c0e0248b4fbc46ef64457a7011fd761a61"),
 		},
 		{
-			setExtra(PosHeader, headerExtra{
+			setExtra(PosHeader, HeaderExtra{
 				Round: 1997,
 			}),
 			posHeaderHash,
 		},
 		{
-			setExtra(PosHeader, headerExtra{
+			setExtra(PosHeader, HeaderExtra{
 				Round: 3,
 			}),
 			posHeaderHash,
 		},
 		{
-			setExtra(PosHeader, headerExtra{
+			setExtra(PosHeader, HeaderExtra{
 				Round: 0,
 			}),
 			posHeaderHash,
@@ -118,10 +166,75 @@ func TestHeaderHash(t *testing.T) {
 	}
 }
 
-func setExtra(h Header, hExtra headerExtra) Header {
+func setExtra(h Header, hExtra HeaderExtra) Header {
 	h.Committee = hExtra.Committee
 	h.ProposerSeal = hExtra.ProposerSeal
 	h.Round = hExtra.Round
 	h.QuorumCertificate = hExtra.QuorumCertificate
 	return h
 }
+
+// largeCommittee returns a committee of size members, with distinct addresses.
+func largeCommittee(size int) Committee {
+	committee := make(Committee, size)
+	for i := range committee {
+		committee[i] = CommitteeMember{
+			Address:     common.BigToAddress(big.NewInt(int64(i + 1))),
+			VotingPower: big.NewInt(int64(i + 1)),
+			Index:       uint64(i),
+		}
+	}
+	return committee
+}
+
+func TestHeaderCommitteeMember(t *testing.T) {
+	header := &Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), Committee: largeCommittee(1000)}
+	hash := header.Hash()
+
+	for i, member := range header.Committee {
+		if index := header.MemberIndex(member.Address); index != i {
+			t.Fatalf("member %d index mismatch: have %d", i, index)
+		}
+		found := header.CommitteeMember(member.Address)
+		if found == nil || found.Address != member.Address || found.Index != uint64(i) || found.VotingPower.Cmp(member.VotingPower) != 0 {
+			t.Fatalf("member %d mismatch: have %v", i, found)
+		}
+	}
+	unknown := common.HexToAddress("0xdeadbeef")
+	if header.CommitteeMember(unknown) != nil || header.MemberIndex(unknown) != -1 {
+		t.Fatal("unknown address found in the committee")
+	}
+	// the index is not part of the header
+	if header.Hash() != hash {
+		t.Fatal("header hash changed by the committee lookups")
+	}
+
+	// a committee replaced after the lookups is not looked up out of the stale index
+	header.Committee = header.Committee[:10]
+	if header.CommitteeMember(common.BigToAddress(big.NewInt(500))) != nil {
+		t.Fatal("member of the replaced committee found")
+	}
+}
+
+func BenchmarkHeaderCommitteeMember(b *testing.B) {
+	committee := largeCommittee(1000)
+	last := committee[len(committee)-1].Address
+
+	// the linear scan over the committee the lookups used to be
+	b.Run("scan", func(b *testing.B) {
+		header := &Header{Committee: committee}
+		for i := 0; i < b.N; i++ {
+			for j := range header.Committee {
+				if header.Committee[j].Address == last {
+					break
+				}
+			}
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		header := &Header{Committee: committee}
+		for i := 0; i < b.N; i++ {
+			header.CommitteeMember(last)
+		}
+	})
+}
@@ -93,9 +93,10 @@ type Header struct {
 		for computing the sigHash.
 	*/
 	Committee Committee `json:"committee"           gencodec:"required"`
-	// used for committee member lookup, lazily initialised.
-	committeeMap map[common.Address]*CommitteeMember
-	// Used to ensure the committeeMap is created only once.
+	// used for committee member lookup, lazily initialised: address -> index in the committee. It is not
+	// serialized, so it does not affect the hash of the header.
+	committeeIndex map[common.Address]int
+	// Used to ensure the committeeIndex is created only once.
 	once              sync.Once
 	ProposerSeal      []byte             `json:"proposerSeal"        gencodec:"required"`
 	Round             uint64             `json:"round"               gencodec:"required"`
@@ -178,13 +179,34 @@ type originalHeader struct {
 	*/
 }
 
-type headerExtra struct {
+// HeaderExtra holds the BFT fields of a header, which are carried RLP encoded in the extra-data field of
+// the Ethereum header layout. The layout is consensus critical: it is pinned by the test vectors under
+// testdata/header_extra.
+type HeaderExtra struct {
 	Committee         Committee          `json:"committee"           gencodec:"required"`
 	ProposerSeal      []byte             `json:"proposerSeal"        gencodec:"required"`
 	Round             uint64             `json:"round"               gencodec:"required"`
 	QuorumCertificate AggregateSignature `json:"quorumCertificate"      gencodec:"required"`
 }
 
+// EncodeHeaderExtra returns the extra-data field of a BFT header carrying the given fields.
+func EncodeHeaderExtra(extra *HeaderExtra) ([]byte, error) {
+	return rlp.EncodeToBytes(extra)
+}
+
+// DecodeHeaderExtra decodes the extra-data field of a BFT header. The consensus keys of the committee
+// are deserialized, so that an extra holding an invalid key fails to decode.
+func DecodeHeaderExtra(data []byte) (*HeaderExtra, error) {
+	extra := new(HeaderExtra)
+	if err := rlp.DecodeBytes(data, extra); err != nil {
+		return nil, err
+	}
+	if err := extra.Committee.Enrich(); err != nil {
+		return nil, fmt.Errorf("Error while deserializing consensus keys: %w", err)
+	}
+	return extra, nil
+}
+
 // headerMarshaling is used by gencodec (which can be invoked by running go
 // generate in this package) and defines marshalling types for fields that
 // would not marshal correctly to hex of their own accord. When modifying the
@@ -263,8 +285,7 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 	}
 
 	if origin.MixDigest == BFTDigest {
-		hExtra := &headerExtra{}
-		err := rlp.DecodeBytes(origin.Extra, hExtra)
+		hExtra, err := DecodeHeaderExtra(origin.Extra)
 		if err != nil {
 			return err
 		}
@@ -272,10 +293,6 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 		h.Committee = hExtra.Committee
 		h.ProposerSeal = hExtra.ProposerSeal
 		h.Round = hExtra.Round
-
-		if err := h.Committee.Enrich(); err != nil {
-			return fmt.Errorf("Error while deserializing consensus keys: %w", err)
-		}
 	} else {
 		h.Extra = origin.Extra
 	}
@@ -310,7 +327,7 @@ func (h *Header) DecodeRLP(s *rlp.Stream) error {
 // fields. When we decode we repopulate our additional header fields from the
 // extra data.
 func (h *Header) EncodeRLP(w io.Writer) error {
-	hExtra := headerExtra{
+	hExtra := &HeaderExtra{
 		Committee:         h.Committee,
 		ProposerSeal:      h.ProposerSeal,
 		Round:             h.Round,
@@ -319,7 +336,7 @@ func (h *Header) EncodeRLP(w io.Writer) error {
 
 	original := h.original()
 	if h.MixDigest == BFTDigest {
-		extra, err := rlp.EncodeToBytes(hExtra)
+		extra, err := EncodeHeaderExtra(hExtra)
 		if err != nil {
 			return err
 		}
@@ -130,14 +130,14 @@ func TestHeaderEncodeDecodeJson(t *testing.T) {
 	sig := blst.AggregateSignatures([]blst.Signature{seal1, seal2})
 	header.QuorumCertificate.Signature = sig.(*blst.BlsSignature)
 
-	hExtra := headerExtra{
+	hExtra := HeaderExtra{
 		Committee:         header.Committee,
 		ProposerSeal:      header.ProposerSeal,
 		Round:             header.Round,
 		QuorumCertificate: header.QuorumCertificate,
 	}
 
-	extra, err := rlp.EncodeToBytes(hExtra)
+	extra, err := EncodeHeaderExtra(&hExtra)
 	require.NoError(t, err)
 	header.Extra = extra
 
@@ -0,0 +1,335 @@
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/rlp"
+)
+
+var updateVectors = flag.Bool("update", false, "regenerate the header extra test vectors")
+
+// headerExtraVersion is the version of the header extra layout the test vectors are generated for.
+// The vectors of every version are kept under testdata/header_extra/v<version> and checked against the
+// current code, so that the headers of the past versions keep decoding and hashing the same. A change
+// of the layout bumps the version and regenerates the vectors with
+//
+//	go test ./core/types -run TestHeaderExtraVectors -update
+//
+// leaving the directories of the previous versions untouched.
+const headerExtraVersion = 1
+
+// vectorMaxCommittee is the largest committee size configured for the networks in params.
+const vectorMaxCommittee = 50
+
+// headerExtraVector is a test vector: the fields of the extra, its encoding and the hashes of the
+// reference header carrying it.
+type headerExtraVector struct {
+	Extra      vectorExtra   `json:"extra"`
+	Encoded    hexutil.Bytes `json:"encoded"`
+	HeaderRLP  hexutil.Bytes `json:"headerRlp"`
+	HeaderHash common.Hash   `json:"headerHash"`
+	SigHash    common.Hash   `json:"sigHash"`
+}
+
+type vectorExtra struct {
+	Committee         Committee          `json:"committee"`
+	ProposerSeal      hexutil.Bytes      `json:"proposerSeal"`
+	Round             hexutil.Uint64     `json:"round"`
+	QuorumCertificate *vectorCertificate `json:"quorumCertificate"` // null without a certificate
+}
+
+type vectorCertificate struct {
+	Signature    hexutil.Bytes `json:"signature"`
+	Bits         hexutil.Bytes `json:"bits"`
+	Coefficients []uint16      `json:"coefficients"`
+}
+
+func newVectorExtra(extra *HeaderExtra) vectorExtra {
+	committee := make(Committee, len(extra.Committee))
+	for i, member := range extra.Committee {
+		committee[i] = CommitteeMember{Address: member.Address, VotingPower: member.VotingPower, ConsensusKeyBytes: member.ConsensusKeyBytes}
+	}
+	v := vectorExtra{Committee: committee, ProposerSeal: extra.ProposerSeal, Round: hexutil.Uint64(extra.Round)}
+	if certificate := extra.QuorumCertificate; certificate.Signature != nil {
+		v.QuorumCertificate = &vectorCertificate{
+			Signature:    certificate.Signature.Marshal(),
+			Bits:         hexutil.Bytes(certificate.Signers.Bits),
+			Coefficients: certificate.Signers.Coefficients,
+		}
+	}
+	return v
+}
+
+func (v vectorExtra) headerExtra(t *testing.T) *HeaderExtra {
+	extra := &HeaderExtra{Committee: v.Committee, ProposerSeal: v.ProposerSeal, Round: uint64(v.Round)}
+	require.NoError(t, extra.Committee.Enrich())
+	if v.QuorumCertificate != nil {
+		signature, err := blst.SignatureFromBytes(v.QuorumCertificate.Signature)
+		require.NoError(t, err)
+		extra.QuorumCertificate = AggregateSignature{
+			Signature: signature.(*blst.BlsSignature),
+			Signers:   &Signers{Bits: validatorBitmap(v.QuorumCertificate.Bits), Coefficients: v.QuorumCertificate.Coefficients},
+		}
+	}
+	return extra
+}
+
+// vectorHeader returns the reference header of the vectors, carrying the extra.
+func vectorHeader(extra *HeaderExtra) *Header {
+	return &Header{
+		ParentHash:        common.HexToHash("0x1e5a5c1fa3cf1d8fd6a1c0a3f2ee4e40a1e5ec2b1b3dc0b8e6e2c1dd0cf1a1b2"),
+		UncleHash:         EmptyUncleHash,
+		Coinbase:          common.HexToAddress("0x850a7e3e4b6d1c0b8b5b1e7c5ea8f1dd1ca5f6d2"),
+		Root:              common.HexToHash("0x5a0a11d6b1a48b1bd5cfc1cd6a54b9e7aa0b39d7e7a5ac9a1c7bb0c5ccf6e775"),
+		TxHash:            EmptyRootHash,
+		ReceiptHash:       EmptyRootHash,
+		Difficulty:        big.NewInt(1),
+		Number:            big.NewInt(1210),
+		GasLimit:          30_000_000,
+		GasUsed:           21_000,
+		Time:              1709287200,
+		MixDigest:         BFTDigest,
+		BaseFee:           big.NewInt(500_000_000),
+		Committee:         extra.Committee,
+		ProposerSeal:      extra.ProposerSeal,
+		Round:             extra.Round,
+		QuorumCertificate: extra.QuorumCertificate,
+	}
+}
+
+func newHeaderExtraVector(t *testing.T, extra *HeaderExtra) *headerExtraVector {
+	encoded, err := EncodeHeaderExtra(extra)
+	require.NoError(t, err)
+	header := vectorHeader(extra)
+	headerRLP, err := rlp.EncodeToBytes(header)
+	require.NoError(t, err)
+	return &headerExtraVector{
+		Extra:      newVectorExtra(extra),
+		Encoded:    encoded,
+		HeaderRLP:  headerRLP,
+		HeaderHash: header.Hash(),
+		SigHash:    SigHash(header),
+	}
+}
+
+func vectorCommittee(size int) Committee {
+	committee := make(Committee, size)
+	for i := range committee {
+		key, err := blst.SecretKeyFromBytes(common.BigToHash(big.NewInt(int64(i + 1))).Bytes())
+		if err != nil {
+			panic(err)
+		}
+		committee[i] = CommitteeMember{
+			Address:           crypto.PubkeyToAddress(vectorSealKey(i).PublicKey),
+			VotingPower:       new(big.Int).Mul(big.NewInt(int64(i+1)), big.NewInt(1e18)),
+			ConsensusKeyBytes: key.PublicKey().Marshal(),
+		}
+	}
+	if err := committee.Enrich(); err != nil {
+		panic(err)
+	}
+	return committee
+}
+
+func vectorSealKey(i int) *ecdsa.PrivateKey {
+	key, err := crypto.ToECDSA(common.BigToHash(big.NewInt(int64(i + 1))).Bytes())
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// vectorCertificateOf returns a certificate signed by the committee members of the bitmap, the members
+// marked with multiple signatures having the coefficients given in order.
+func vectorCertificateOf(size int, counts map[int]uint16) AggregateSignature {
+	signers := NewSigners(size)
+	var signatures []blst.Signature
+	indices := make([]int, 0, len(counts))
+	for index := range counts {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	for _, index := range indices {
+		key, err := blst.SecretKeyFromBytes(common.BigToHash(big.NewInt(int64(index + 1))).Bytes())
+		if err != nil {
+			panic(err)
+		}
+		for n := uint16(0); n < counts[index]; n++ {
+			signers.increment(index)
+			signatures = append(signatures, key.Sign([]byte("header extra test vector")))
+		}
+	}
+	return NewAggregateSignature(blst.AggregateSignatures(signatures).(*blst.BlsSignature), signers)
+}
+
+// headerExtraVectorCases are the extras of the test vectors of the current version, by name.
+func headerExtraVectorCases() map[string]*HeaderExtra {
+	seal := func(extra *HeaderExtra) *HeaderExtra {
+		signature, err := crypto.Sign(SigHash(vectorHeader(extra)).Bytes(), vectorSealKey(0))
+		if err != nil {
+			panic(err)
+		}
+		extra.ProposerSeal = signature
+		return extra
+	}
+	all := func(size int) map[int]uint16 {
+		counts := make(map[int]uint16, size)
+		for i := 0; i < size; i++ {
+			counts[i] = 1
+		}
+		return counts
+	}
+	return map[string]*HeaderExtra{
+		"empty":                            {},
+		"empty_committee_sealed":           seal(&HeaderExtra{Committee: Committee{}}),
+		"single_member_committee":          {Committee: vectorCommittee(1)},
+		"max_committee":                    {Committee: vectorCommittee(vectorMaxCommittee)},
+		"proposer_seal":                    seal(&HeaderExtra{}),
+		"round_zero":                       {Round: 0, ProposerSeal: []byte{0x01}},
+		"round_one":                        {Round: 1},
+		"round_max":                        {Round: math.MaxUint64},
+		"certificate_single_signer":        {QuorumCertificate: vectorCertificateOf(4, map[int]uint16{2: 1})},
+		"certificate_all_signers":          {QuorumCertificate: vectorCertificateOf(4, all(4))},
+		"certificate_two_signatures":       {QuorumCertificate: vectorCertificateOf(4, map[int]uint16{0: 2, 3: 1})},
+		"certificate_coefficients":         {QuorumCertificate: vectorCertificateOf(8, map[int]uint16{1: 3, 2: 1, 4: 7})},
+		"certificate_unaligned_bitmap":     {QuorumCertificate: vectorCertificateOf(7, map[int]uint16{6: 1})},
+		"certificate_max_committee":        {QuorumCertificate: vectorCertificateOf(vectorMaxCommittee, all(vectorMaxCommittee))},
+		"certificate_max_committee_sparse": {QuorumCertificate: vectorCertificateOf(vectorMaxCommittee, map[int]uint16{0: 1, 10: 2, 25: 4, 49: 1})},
+		"full": seal(&HeaderExtra{
+			Committee:         vectorCommittee(4),
+			Round:             3,
+			QuorumCertificate: vectorCertificateOf(4, map[int]uint16{0: 1, 1: 1, 3: 4}),
+		}),
+	}
+}
+
+func readHeaderExtraVector(t *testing.T, file string) *headerExtraVector {
+	encoded, err := os.ReadFile(file)
+	require.NoError(t, err)
+	vector := new(headerExtraVector)
+	require.NoError(t, json.Unmarshal(encoded, vector))
+	return vector
+}
+
+func marshalVector(t *testing.T, vector interface{}) []byte {
+	encoded, err := json.MarshalIndent(vector, "", "  ")
+	require.NoError(t, err)
+	return append(encoded, '\n')
+}
+
+// TestHeaderExtraVectors pins the encoding, decoding and hashing of the header extra to the test vectors of
+// every version.
+func TestHeaderExtraVectors(t *testing.T) {
+	current := filepath.Join("testdata", "header_extra", fmt.Sprintf("v%d", headerExtraVersion))
+	cases := headerExtraVectorCases()
+	if *updateVectors {
+		require.NoError(t, os.RemoveAll(current))
+		require.NoError(t, os.MkdirAll(current, 0755))
+		for name, extra := range cases {
+			encoded := marshalVector(t, newHeaderExtraVector(t, extra))
+			require.NoError(t, os.WriteFile(filepath.Join(current, name+".json"), encoded, 0644))
+		}
+		return
+	}
+
+	// the vectors of the current version are the ones generated out of the cases
+	t.Run(fmt.Sprintf("v%d generated", headerExtraVersion), func(t *testing.T) {
+		files, err := filepath.Glob(filepath.Join(current, "*.json"))
+		require.NoError(t, err)
+		require.Len(t, files, len(cases), "vectors out of date, regenerate with -update")
+		for name, extra := range cases {
+			golden, err := os.ReadFile(filepath.Join(current, name+".json"))
+			require.NoError(t, err, "missing vector, regenerate with -update")
+			if encoded := marshalVector(t, newHeaderExtraVector(t, extra)); !bytes.Equal(golden, encoded) {
+				t.Fatalf("the header extra vector %s changed, the layout is consensus critical\nwant:\n%s\ngot:\n%s", name, golden, encoded)
+			}
+		}
+	})
+
+	versions, err := filepath.Glob(filepath.Join("testdata", "header_extra", "v*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, versions)
+	for _, version := range versions {
+		files, err := filepath.Glob(filepath.Join(version, "*.json"))
+		require.NoError(t, err)
+		for _, file := range files {
+			name := filepath.Base(version) + "/" + strings.TrimSuffix(filepath.Base(file), ".json")
+			t.Run(name, func(t *testing.T) {
+				checkHeaderExtraVector(t, readHeaderExtraVector(t, file))
+			})
+		}
+	}
+}
+
+func checkHeaderExtraVector(t *testing.T, vector *headerExtraVector) {
+	extra := vector.Extra.headerExtra(t)
+
+	encoded, err := EncodeHeaderExtra(extra)
+	require.NoError(t, err)
+	if !bytes.Equal(encoded, vector.Encoded) {
+		t.Fatalf("encoding changed\nwant: %x\ngot:  %x", []byte(vector.Encoded), encoded)
+	}
+
+	decoded, err := DecodeHeaderExtra(vector.Encoded)
+	require.NoError(t, err)
+	want, got := marshalVector(t, vector.Extra), marshalVector(t, newVectorExtra(decoded))
+	if !bytes.Equal(want, got) {
+		t.Fatalf("decoding changed\nwant:\n%s\ngot:\n%s", want, got)
+	}
+	for i, member := range decoded.Committee {
+		require.Equal(t, uint64(i), member.Index)
+		require.Equal(t, member.ConsensusKeyBytes, member.ConsensusKey.Marshal())
+	}
+
+	header := vectorHeader(extra)
+	headerRLP, err := rlp.EncodeToBytes(header)
+	require.NoError(t, err)
+	if !bytes.Equal(headerRLP, vector.HeaderRLP) {
+		t.Fatalf("header encoding changed\nwant: %x\ngot:  %x", []byte(vector.HeaderRLP), headerRLP)
+	}
+	require.Equal(t, vector.HeaderHash, header.Hash(), "header hash changed")
+	require.Equal(t, vector.SigHash, SigHash(header), "signature hash changed")
+
+	// the header decoded out of the vector, and its JSON as served over RPC, hash the same
+	decodedHeader := new(Header)
+	require.NoError(t, rlp.DecodeBytes(vector.HeaderRLP, decodedHeader))
+	require.Equal(t, vector.HeaderHash, decodedHeader.Hash())
+	encodedJSON, err := decodedHeader.MarshalJSON()
+	require.NoError(t, err)
+	jsonHeader := new(Header)
+	require.NoError(t, jsonHeader.UnmarshalJSON(encodedJSON))
+	require.Equal(t, vector.HeaderHash, jsonHeader.Hash())
+}
+
+func TestDecodeHeaderExtraInvalid(t *testing.T) {
+	_, err := DecodeHeaderExtra(nil)
+	require.Error(t, err)
+	_, err = DecodeHeaderExtra([]byte{0xc0})
+	require.Error(t, err)
+
+	// a committee member with a consensus key out of the curve
+	extra := &HeaderExtra{Committee: vectorCommittee(1)}
+	extra.Committee[0].ConsensusKeyBytes = bytes.Repeat([]byte{0xff}, len(extra.Committee[0].ConsensusKeyBytes))
+	encoded, err := EncodeHeaderExtra(extra)
+	require.NoError(t, err)
+	_, err = DecodeHeaderExtra(encoded)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "consensus keys")
+}
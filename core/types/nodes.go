@@ -1,85 +1,133 @@
 package types
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"sync"
+	"time"
 
 	"github.com/autonity/autonity/log"
 	"github.com/autonity/autonity/p2p/enode"
 )
 
+const (
+	// nodesParseWorkers is the number of enodes parsed concurrently.
+	nodesParseWorkers = 16
+	// nodeResolveTimeout bounds the host resolution of each enode.
+	nodeResolveTimeout = 5 * time.Second
+)
+
+// Nodes is the list of the parsed committee enodes, along with the enodes skipped as invalid.
 type Nodes struct {
 	List    []*enode.Node
 	StrList []string
+	Errors  []*NodeError
 }
 
-func NewNodes(strList []string, asACN bool) *Nodes {
-	wg := sync.WaitGroup{}
-	errCh := make(chan error, len(strList))
-	var parser func(string) (*enode.Node, error)
-	if asACN {
-		parser = enode.ParseACNV4
-	} else {
-		parser = enode.ParseV4
-	}
-
-	n := &Nodes{
-		make([]*enode.Node, len(strList)),
-		make([]string, len(strList)),
-	}
+// NodeError is the error of an enode which could not be parsed or resolved.
+type NodeError struct {
+	Enode string
+	Err   error
+}
 
-	for i, enodeStr := range strList {
-		idx := i
-		wg.Add(1)
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("invalid enode %q: %v", e.Enode, e.Err)
+}
 
-		go func(enodeStr string) {
-			log.Debug("node retrieved", "node", enodeStr)
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
 
-			newEnode, err := parser(enodeStr)
-			if err != nil {
-				errCh <- err
-			}
+// NewNodes parses the enodes concurrently. The invalid and unresolvable enodes are skipped and
+// reported in Errors, the order of the others is preserved. An error is returned only if ctx is
+// done before the end of the parsing.
+func NewNodes(ctx context.Context, strList []string, asACN bool) (*Nodes, error) {
+	p := &nodesParser{
+		resolve: enode.V4ResolveFunc,
+		workers: nodesParseWorkers,
+		timeout: nodeResolveTimeout,
+	}
+	return p.parse(ctx, strList, asACN)
+}
 
-			n.List[idx] = newEnode
-			n.StrList[idx] = enodeStr
+type nodesParser struct {
+	resolve func(host string) ([]net.IP, error)
+	workers int
+	timeout time.Duration
+}
 
-			wg.Done()
-		}(enodeStr)
+func (p *nodesParser) parse(ctx context.Context, strList []string, asACN bool) (*Nodes, error) {
+	var (
+		nodes = make([]*enode.Node, len(strList))
+		errs  = make([]error, len(strList))
+		jobs  = make(chan int)
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < p.workers && i < len(strList); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				nodes[idx], errs[idx] = p.parseNode(ctx, strList[idx], asACN)
+			}
+		}()
 	}
-
+loop:
+	for idx := range strList {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
 	wg.Wait()
-	close(errCh)
-
-	var errs []error
-	for err := range errCh {
-		errs = append(errs, err)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	if len(errs) != 0 {
-		var msg string
-		for _, err := range errs {
-			if err != nil {
-				msg += fmt.Sprintf("%v\n", err)
-			}
+	n := &Nodes{
+		List:    make([]*enode.Node, 0, len(strList)),
+		StrList: make([]string, 0, len(strList)),
+	}
+	for i, enodeStr := range strList {
+		if errs[i] != nil {
+			n.Errors = append(n.Errors, &NodeError{Enode: enodeStr, Err: errs[i]})
+			continue
 		}
-		log.Error("enodes parse errors", "errs", msg)
+		n.List = append(n.List, nodes[i])
+		n.StrList = append(n.StrList, enodeStr)
 	}
-
-	return filterNodes(n)
+	return n, nil
 }
 
-func filterNodes(n *Nodes) *Nodes {
-	filtered := &Nodes{
-		make([]*enode.Node, 0, len(n.List)),
-		make([]string, 0, len(n.StrList)),
-	}
+func (p *nodesParser) parseNode(ctx context.Context, enodeStr string, asACN bool) (*enode.Node, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
 
-	for i, node := range n.List {
-		if node != nil {
-			filtered.List = append(filtered.List, node)
-			filtered.StrList = append(filtered.StrList, n.StrList[i])
+	resolve := func(host string) ([]net.IP, error) {
+		type result struct {
+			ips []net.IP
+			err error
+		}
+		// the resolution is left to finish in the background if ctx is done first
+		resCh := make(chan result, 1)
+		go func() {
+			ips, err := p.resolve(host)
+			resCh <- result{ips, err}
+		}()
+		select {
+		case res := <-resCh:
+			return res.ips, res.err
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", enode.ErrHostResolution, ctx.Err())
 		}
 	}
 
-	return filtered
+	log.Debug("node retrieved", "node", enodeStr)
+	if asACN {
+		return enode.ParseACNV4WithResolver(enodeStr, resolve)
+	}
+	return enode.ParseV4WithResolver(enodeStr, resolve)
 }
@@ -0,0 +1,145 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+func newTestEnodeURL(t *testing.T, host string) string {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return fmt.Sprintf("enode://%x@%s:30303", crypto.FromECDSAPub(&key.PublicKey)[1:], host)
+}
+
+func newTestNodesParser(resolve func(host string) ([]net.IP, error)) *nodesParser {
+	return &nodesParser{resolve: resolve, workers: 4, timeout: time.Second}
+}
+
+func fakeResolve(host string) ([]net.IP, error) {
+	if host == "unknown.example" {
+		return nil, enode.ErrHostResolution
+	}
+	return []net.IP{net.IPv4(10, 0, 0, 1)}, nil
+}
+
+func TestNewNodes(t *testing.T) {
+	var (
+		valid      = newTestEnodeURL(t, "127.0.0.1")
+		resolvable = newTestEnodeURL(t, "node.example")
+		unknown    = newTestEnodeURL(t, "unknown.example")
+		malformed  = "enode://zz@127.0.0.1:30303"
+		noScheme   = "127.0.0.1:30303"
+	)
+	parser := newTestNodesParser(fakeResolve)
+
+	for _, asACN := range []bool{false, true} {
+		nodes, err := parser.parse(context.Background(), []string{malformed, valid, unknown, resolvable, noScheme}, asACN)
+		require.NoError(t, err)
+
+		// the invalid enodes are skipped, the order of the others is preserved
+		require.Equal(t, []string{valid, resolvable}, nodes.StrList)
+		require.Len(t, nodes.List, 2)
+		require.Equal(t, net.IPv4(127, 0, 0, 1).To4(), nodes.List[0].IP())
+		require.Equal(t, net.IPv4(10, 0, 0, 1).To4(), nodes.List[1].IP())
+
+		require.Len(t, nodes.Errors, 3)
+		require.Equal(t, malformed, nodes.Errors[0].Enode)
+		require.Equal(t, unknown, nodes.Errors[1].Enode)
+		require.ErrorIs(t, nodes.Errors[1], enode.ErrHostResolution)
+		require.Equal(t, noScheme, nodes.Errors[2].Enode)
+	}
+}
+
+func TestNewNodesResolveTimeout(t *testing.T) {
+	var (
+		slow    = newTestEnodeURL(t, "slow.example")
+		valid   = newTestEnodeURL(t, "127.0.0.1")
+		release = make(chan struct{})
+	)
+	defer close(release)
+	parser := newTestNodesParser(func(host string) ([]net.IP, error) {
+		<-release
+		return nil, errors.New("released")
+	})
+	parser.timeout = 10 * time.Millisecond
+
+	nodes, err := parser.parse(context.Background(), []string{slow, valid}, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{valid}, nodes.StrList)
+	require.Len(t, nodes.Errors, 1)
+	require.ErrorIs(t, nodes.Errors[0], enode.ErrHostResolution)
+	require.ErrorIs(t, nodes.Errors[0], context.DeadlineExceeded)
+}
+
+func TestNewNodesBoundedWorkers(t *testing.T) {
+	var (
+		enodes        = make([]string, 20)
+		running, peak atomic.Int32
+	)
+	for i := range enodes {
+		enodes[i] = newTestEnodeURL(t, fmt.Sprintf("node%d.example", i))
+	}
+	parser := newTestNodesParser(func(host string) ([]net.IP, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return fakeResolve(host)
+	})
+
+	nodes, err := parser.parse(context.Background(), enodes, false)
+	require.NoError(t, err)
+	require.Equal(t, enodes, nodes.StrList)
+	require.LessOrEqual(t, peak.Load(), int32(parser.workers))
+}
+
+func TestNewNodesCancellation(t *testing.T) {
+	var (
+		enodes  = make([]string, 20)
+		started = make(chan struct{}, len(enodes))
+		release = make(chan struct{})
+	)
+	defer close(release)
+	for i := range enodes {
+		enodes[i] = newTestEnodeURL(t, fmt.Sprintf("node%d.example", i))
+	}
+	parser := newTestNodesParser(func(host string) ([]net.IP, error) {
+		started <- struct{}{}
+		<-release
+		return fakeResolve(host)
+	})
+	parser.timeout = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := parser.parse(ctx, enodes, false)
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the parsing was not interrupted")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"math/bits"
 )
 
 var (
@@ -28,6 +29,7 @@ var (
 	ErrWrongCoefficientLen = errors.New("coefficient array has incorrect length")
 	ErrInvalidSingleSig    = errors.New("individual signature has coefficient != 1")
 	ErrInvalidCoefficient  = errors.New("coefficient exceeds maximum boundary (committee size)")
+	ErrOutOfCommittee      = errors.New("validator bitmap has signers beyond the committee size")
 
 	ErrNotValidated  = errors.New("Using un-validated signers information")
 	ErrDifferentSize = errors.New("Comparing signers information with different committee size")
@@ -132,6 +134,12 @@ func (s *Signers) Validate(committeeSize int) error {
 	if !s.Bits.Valid(committeeSize) || len(s.Coefficients) > committeeSize {
 		return ErrWrongSizeSigners
 	}
+	// the padding of the last byte cannot hold signers
+	for i := committeeSize; i < len(s.Bits)*validatorsPerByte; i++ {
+		if s.Bits.Get(i) != noSignature {
+			return ErrOutOfCommittee
+		}
+	}
 
 	// gather data about signers bits
 	countNonZero := 0
@@ -176,6 +184,7 @@ func (s *Signers) Validate(committeeSize int) error {
 	return nil
 }
 
+// Contains returns whether the committee member at index is a signer.
 func (s *Signers) Contains(index int) bool {
 	if !s.validated {
 		panic("Trying to use not validated signer information")
@@ -257,6 +266,81 @@ func (s *Signers) CanMergeSimple(other *Signers) bool {
 	return true
 }
 
+// Union returns the signers of either s or other, each contributing a single signature. It fails if the
+// signers are not validated or were validated against different committee sizes.
+func (s *Signers) Union(other *Signers) (*Signers, error) {
+	if err := safetyCheck(s, other); err != nil {
+		return nil, err
+	}
+	return s.combine(other, func(inFirst, inSecond bool) bool { return inFirst || inSecond }), nil
+}
+
+// Intersect returns the signers of both s and other, each contributing a single signature. It fails if the
+// signers are not validated or were validated against different committee sizes.
+func (s *Signers) Intersect(other *Signers) (*Signers, error) {
+	if err := safetyCheck(s, other); err != nil {
+		return nil, err
+	}
+	return s.combine(other, func(inFirst, inSecond bool) bool { return inFirst && inSecond }), nil
+}
+
+// combine returns the signers selected by member out of s and other. The powers are carried over if they
+// are assigned to both.
+func (s *Signers) combine(other *Signers, member func(inFirst, inSecond bool) bool) *Signers {
+	result := NewSigners(s.committeeSize)
+	result.powerAssigned = s.powerAssigned && other.powerAssigned
+	for i := 0; i < s.committeeSize; i++ {
+		inFirst, inSecond := s.Bits.Get(i) != noSignature, other.Bits.Get(i) != noSignature
+		if !member(inFirst, inSecond) {
+			continue
+		}
+		result.increment(i)
+		if !result.powerAssigned {
+			continue
+		}
+		power, ok := s.powers[i]
+		if !ok {
+			power, ok = other.powers[i]
+		}
+		if ok {
+			result.powers[i] = power
+			result.power.Add(result.power, power)
+		}
+	}
+	return result
+}
+
+// Count returns the number of distinct signers, counted out of the bitmap.
+func (s *Signers) Count() int {
+	if !s.validated {
+		panic("Using un-validated signers information")
+	}
+	count := 0
+	for _, b := range s.Bits {
+		// the low bit of each pair is set if any of its bits is, the padding is empty once validated
+		count += bits.OnesCount8((b | b>>1) & 0x55)
+	}
+	return count
+}
+
+// PowerIn returns the voting power of the signers in committee, regardless of the power assigned to the
+// signers. The committee must be the one the signers were validated against.
+func (s *Signers) PowerIn(committee Committee) *big.Int {
+	if !s.validated {
+		panic("Using un-validated signers information")
+	}
+	if len(committee) != s.committeeSize {
+		panic(ErrDifferentSize.Error())
+	}
+	power := new(big.Int)
+	for i := range committee {
+		if s.Bits.Get(i) != noSignature {
+			power.Add(power, committee[i].VotingPower)
+		}
+	}
+	return power
+}
+
 func (s *Signers) increment(index int) {
 	if !s.validated {
 		panic("Using un-validated signers information")
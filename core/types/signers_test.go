@@ -576,6 +576,12 @@ func TestValidation(t *testing.T) {
 	s.Coefficients = []uint16{uint16(csize + 10)}
 	require.True(t, errors.Is(s.Validate(csize), ErrInvalidCoefficient))
 
+	// the padding of the bitmap holds no signer
+	s = NewSigners(csize)
+	s.increment(0)
+	s.Bits.Set(csize+1, oneSignature)
+	require.True(t, errors.Is(s.Validate(csize), ErrOutOfCommittee))
+
 	s = NewSigners(csize)
 	s.increment(0)
 	s.Validate(csize)
@@ -583,3 +589,154 @@ func TestValidation(t *testing.T) {
 	require.Equal(t, csize, s.CommitteeSize())
 	require.Equal(t, 1, s.Len())
 }
+
+// signersOf returns the signers of a committee of committeeSize members, incrementing each index once per
+// occurrence.
+func signersOf(committeeSize int, indexes ...int) *Signers {
+	s := NewSigners(committeeSize)
+	for _, index := range indexes {
+		s.increment(index)
+	}
+	return s
+}
+
+func TestSignersSetAlgebra(t *testing.T) {
+	err := committee.Enrich()
+	require.NoError(t, err)
+	size := len(committee)
+
+	tests := []struct {
+		name      string
+		first     []int
+		second    []int
+		union     []int
+		intersect []int
+	}{
+		{name: "disjoint", first: []int{0, 1}, second: []int{3, 4}, union: []int{0, 1, 3, 4}},
+		{name: "overlapping", first: []int{0, 1, 2}, second: []int{2, 3}, union: []int{0, 1, 2, 3}, intersect: []int{2}},
+		{name: "identical", first: []int{1, 4}, second: []int{1, 4}, union: []int{1, 4}, intersect: []int{1, 4}},
+		{name: "one empty", first: []int{2}, union: []int{2}},
+		{name: "multiple signatures", first: []int{0, 0, 0, 0, 3}, second: []int{0, 1, 1}, union: []int{0, 1, 3}, intersect: []int{0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			first, second := signersOf(size, test.first...), signersOf(size, test.second...)
+
+			union, err := first.Union(second)
+			require.NoError(t, err)
+			require.Equal(t, test.union, union.FlattenUniq())
+			require.Equal(t, test.union, union.Flatten(), "each signer contributes a single signature")
+			require.Equal(t, len(test.union), union.Count())
+			require.Equal(t, union.Len(), union.Count())
+
+			intersect, err := first.Intersect(second)
+			require.NoError(t, err)
+			require.Equal(t, test.intersect, intersect.FlattenUniq())
+			require.Equal(t, len(test.intersect), intersect.Count())
+
+			for i := 0; i < size; i++ {
+				require.Equal(t, first.Contains(i) || second.Contains(i), union.Contains(i))
+				require.Equal(t, first.Contains(i) && second.Contains(i), intersect.Contains(i))
+			}
+		})
+	}
+
+	t.Run("different committee sizes are rejected", func(t *testing.T) {
+		_, err := signersOf(size, 0).Union(signersOf(size+4, 0))
+		require.ErrorIs(t, err, ErrDifferentSize)
+		_, err = signersOf(size, 0).Intersect(signersOf(size-1, 0))
+		require.ErrorIs(t, err, ErrDifferentSize)
+	})
+
+	t.Run("un-validated signers are rejected", func(t *testing.T) {
+		_, err := (&Signers{Bits: NewValidatorBitmap(size)}).Union(signersOf(size, 0))
+		require.ErrorIs(t, err, ErrNotValidated)
+	})
+
+	t.Run("powers", func(t *testing.T) {
+		first, second := NewSigners(size), NewSigners(size)
+		first.Increment(&committee[0])
+		first.Increment(&committee[1])
+		second.Increment(&committee[1])
+		second.Increment(&committee[4])
+
+		union, err := first.Union(second)
+		require.NoError(t, err)
+		expected := new(big.Int).Add(committee[0].VotingPower, committee[1].VotingPower)
+		expected.Add(expected, committee[4].VotingPower)
+		require.Equal(t, expected, union.Power())
+		require.Equal(t, expected, union.PowerIn(committee))
+
+		intersect, err := first.Intersect(second)
+		require.NoError(t, err)
+		require.Equal(t, committee[1].VotingPower, intersect.Power())
+		require.Equal(t, committee[1].VotingPower, intersect.PowerIn(committee))
+
+		require.Equal(t, new(big.Int), NewSigners(size).PowerIn(committee))
+		defer expectPanic(t)
+		union.PowerIn(committee[:size-1])
+	})
+}
+
+func FuzzSignersRLP(f *testing.F) {
+	f.Add(uint8(5), []byte{0, 0, 0, 0, 1, 3})
+	f.Add(uint8(1), []byte{0})
+	f.Add(uint8(33), []byte{32, 7, 7, 7, 0})
+	f.Fuzz(func(t *testing.T, size uint8, indexes []byte) {
+		if size == 0 {
+			return
+		}
+		s := NewSigners(int(size))
+		distinct := make(map[int]struct{})
+		for _, index := range indexes {
+			i := int(index) % int(size)
+			// the coefficients are bounded by the committee size
+			if countOf(s.Flatten(), i) >= int(size) {
+				continue
+			}
+			s.increment(i)
+			distinct[i] = struct{}{}
+		}
+		require.Equal(t, len(distinct), s.Count())
+
+		payload, err := rlp.EncodeToBytes(s)
+		require.NoError(t, err)
+		decoded := &Signers{}
+		require.NoError(t, rlp.DecodeBytes(payload, decoded))
+		require.Equal(t, s.Bits, decoded.Bits)
+		require.Equal(t, s.Coefficients, decoded.Coefficients)
+		err = decoded.Validate(int(size))
+		switch {
+		case len(distinct) == 0:
+			require.ErrorIs(t, err, ErrEmptySigners)
+			return
+		case len(distinct) == 1 && len(s.Flatten()) > 1:
+			// a single signer contributes a single signature on the wire
+			require.ErrorIs(t, err, ErrInvalidSingleSig)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, s.Count(), decoded.Count())
+		require.Equal(t, s.Len(), decoded.Count())
+		require.Equal(t, s.Flatten(), decoded.Flatten())
+
+		// the set operations with itself are the identity on the signers
+		union, err := decoded.Union(s)
+		require.NoError(t, err)
+		require.Equal(t, s.FlattenUniq(), union.FlattenUniq())
+		intersect, err := decoded.Intersect(s)
+		require.NoError(t, err)
+		require.Equal(t, s.FlattenUniq(), intersect.FlattenUniq())
+	})
+}
+
+// countOf returns the number of occurrences of index in indexes.
+func countOf(indexes []int, index int) int {
+	count := 0
+	for _, i := range indexes {
+		if i == index {
+			count++
+		}
+	}
+	return count
+}
@@ -61,6 +61,14 @@ type PrecompiledContract interface {
 	Run(input []byte, blockNumber uint64, evm *EVM, caller common.Address) ([]byte, error) // Run runs the precompiled contract
 }
 
+// AccountedPrecompile is implemented by the precompiled contracts whose runs are recorded in the state
+// during the execution of a block, for their usage to be accounted per block.
+type AccountedPrecompile interface {
+	PrecompiledContract
+	Address() common.Address      // address the contract is registered at
+	Succeeded(output []byte) bool // whether the output of a run is a success
+}
+
 // PrecompiledContractsHomestead contains the default set of pre-compiled Ethereum
 // contracts used in the Frontier and Homestead releases.
 var PrecompiledContractsHomestead = map[common.Address]PrecompiledContract{
@@ -201,13 +209,29 @@ func RunPrecompiledContract(
 ) (ret []byte, remainingGas uint64, err error) {
 	gasCost := p.RequiredGas(input)
 	if suppliedGas < gasCost {
+		recordPrecompileRun(p, evm, suppliedGas, nil, ErrOutOfGas)
 		return nil, 0, ErrOutOfGas
 	}
 	suppliedGas -= gasCost
 	output, err := p.Run(input, blockNumber, evm, caller)
+	if err != nil && err != ErrExecutionReverted {
+		recordPrecompileRun(p, evm, gasCost+suppliedGas, output, err)
+	} else {
+		recordPrecompileRun(p, evm, gasCost, output, err)
+	}
 	return output, suppliedGas, err
 }
 
+// recordPrecompileRun records the run of an accounted precompiled contract in the state. The gas used
+// of a run failing with an error other than a revert is the gas it was supplied, as the EVM consumes it.
+func recordPrecompileRun(p PrecompiledContract, evm *EVM, gasUsed uint64, output []byte, err error) {
+	accounted, ok := p.(AccountedPrecompile)
+	if !ok || evm == nil || evm.StateDB == nil {
+		return
+	}
+	evm.StateDB.AddPrecompileRun(accounted.Address(), gasUsed, err == nil && accounted.Succeeded(output))
+}
+
 const (
 	KB = 1024
 	// offset constants of Validator struct from Autonity.sol
@@ -299,8 +323,6 @@ func (a *CommitteeSelector) Run(input []byte, _ uint64, evm *EVM, caller common.
 	}
 	validatorListSize := int(validatorListSizeBig.Uint64())
 	addressOffset := crypto.Keccak256Hash(inputs.validatorListSlot).Big()
-	stakeThreshold := big.NewInt(StakeThreshold)
-	activeState := big.NewInt(ActiveState)
 
 	// get validators from DB
 	// scope to improve: read validators from DB concurrently
@@ -309,7 +331,7 @@ func (a *CommitteeSelector) Run(input []byte, _ uint64, evm *EVM, caller common.
 	mapKey := make([]byte, DataLen*2)
 	copy(mapKey[DataLen:], inputs.validatorsSlot)
 	for i := 0; i < validatorListSize; i++ {
-		user := a.getValidatorInfo(mapKey, addressOffset.Bytes(), stakeThreshold, activeState, caller, stateDB)
+		user := a.getValidatorInfo(mapKey, addressOffset.Bytes(), caller, stateDB)
 		if user != nil {
 			validators = append(validators, user)
 		}
@@ -321,15 +343,27 @@ func (a *CommitteeSelector) Run(input []byte, _ uint64, evm *EVM, caller common.
 		return nil, errNoActiveValidator
 	}
 
-	committeeSize := min(len(validators), int(inputs.maxCommitteeSize))
-	// sort validators according to their voting power in descending order
+	committee := SelectCommittee(validators, inputs.maxCommitteeSize)
+	err := a.updateCommittee(inputs, committee, len(committee), caller, stateDB)
+	return nil, err
+}
+
+// IsCommitteeCandidate reports whether a validator with the given bonded stake and state
+// takes part in the committee selection.
+func IsCommitteeCandidate(bondedStake *big.Int, state *big.Int) bool {
+	// take validator if the stake is greater than threshold, for now threshold = 0
+	return bondedStake.Cmp(big.NewInt(StakeThreshold)) == 1 && state.Cmp(big.NewInt(ActiveState)) == 0
+}
+
+// SelectCommittee returns the committee elected among the candidates, which are expected in the
+// order of the validator list. The candidates are sorted in place by descending voting power and
+// the first maxCommitteeSize of them are elected. Ties are broken by the validator list order.
+func SelectCommittee(candidates []*types.CommitteeMember, maxCommitteeSize uint64) []*types.CommitteeMember {
 	// stable sort keeps the original order of equal elements
-	slices.SortStableFunc(validators, func(a, b *types.CommitteeMember) int {
+	slices.SortStableFunc(candidates, func(a, b *types.CommitteeMember) int {
 		return b.VotingPower.Cmp(a.VotingPower)
 	})
-
-	err := a.updateCommittee(inputs, validators, committeeSize, caller, stateDB)
-	return nil, err
+	return candidates[:min(uint64(len(candidates)), maxCommitteeSize)]
 }
 
 func (a *CommitteeSelector) updateCommittee(
@@ -430,7 +464,7 @@ func (a *CommitteeSelector) updateCommittee(
 
 // solidity storage layout: https://docs.soliditylang.org/en/latest/internals/layout_in_storage.html#storage-inplace-encoding
 func (a *CommitteeSelector) getValidatorInfo(
-	mapKey []byte, addressSlot []byte, stakeThreshold *big.Int, expectedState *big.Int, caller common.Address, stateDB StateDB,
+	mapKey []byte, addressSlot []byte, caller common.Address, stateDB StateDB,
 ) *types.CommitteeMember {
 
 	nodeAddress := common.BytesToAddress(stateDB.GetState(caller, common.BytesToHash(addressSlot)).Bytes())
@@ -441,8 +475,7 @@ func (a *CommitteeSelector) getValidatorInfo(
 	bondedStake := stateDB.GetState(caller, common.BytesToHash(stakeSlot)).Big()
 	stateSlot := new(big.Int).Add(mapItemSlot, big.NewInt(StateOffset)).Bytes()
 	state := stateDB.GetState(caller, common.BytesToHash(stateSlot)).Big()
-	// take validator if the stake is greater than threshold, for now threshold = 0
-	if bondedStake.Cmp(stakeThreshold) == 1 && state.Cmp(expectedState) == 0 {
+	if IsCommitteeCandidate(bondedStake, state) {
 		return &types.CommitteeMember{
 			Address:     nodeAddress,
 			VotingPower: bondedStake,
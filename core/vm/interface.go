@@ -72,6 +72,8 @@ type StateDB interface {
 
 	AddLog(*types.Log)
 	AddPreimage(common.Hash, []byte)
+	// AddPrecompileRun records the run of an accounted precompiled contract.
+	AddPrecompileRun(addr common.Address, gasUsed uint64, success bool)
 
 	ForEachStorage(common.Address, func(common.Hash, common.Hash) bool) error
 
@@ -4,18 +4,22 @@ import (
 	"encoding/hex"
 	"math/rand"
 	"testing"
+	"time"
 
 	fuzz "github.com/google/gofuzz"
 	"github.com/stretchr/testify/require"
 
 	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/tendermint/accountability"
 	bk "github.com/autonity/autonity/consensus/tendermint/backend"
 	"github.com/autonity/autonity/consensus/tendermint/core"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto/blst"
 	e2e "github.com/autonity/autonity/e2e_test"
+	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/rlp"
 )
 
@@ -118,21 +122,25 @@ func (s *C1OffChainAccusation) Broadcast(msg message.Msg) {
 	s.Logger().Info("MsgStore manipulated to cause accusation of C1 rule to be raised later on", "accusationHeight", height)
 }
 
-func newOffChainAccusationFuzzer(c interfaces.Core) interfaces.Broadcaster {
-	return &OffChainAccusationFuzzer{c.(*core.Core)}
+func newOffChainAccusationFuzzer(seed *e2e.Seed) func(c interfaces.Core) interfaces.Broadcaster {
+	return func(c interfaces.Core) interfaces.Broadcaster {
+		return &OffChainAccusationFuzzer{c.(*core.Core), rand.New(rand.NewSource(seed.Int63())), seed.Fuzzer()} //nolint:gosec
+	}
 }
 
 // send accusation with garbage accusation msg, the sender of the msg should get disconnected from receiver end.
 type OffChainAccusationFuzzer struct {
 	*core.Core
+	rand   *rand.Rand
+	fuzzer *fuzz.Fuzzer
 }
 
 // TODO(lorenzo) not sure this manages to fuzz also the `Signers` object in message.Vote
-func fuzzedMessages() []message.Msg {
-	num := rand.Intn(10) + 1
+func (s *OffChainAccusationFuzzer) fuzzedMessages() []message.Msg {
+	num := s.rand.Intn(10) + 1
 	var msgs []message.Msg
 	//TODO(lorenzo) properly define custom fuzzing functions for signature and signerkey
-	f := fuzz.New().Funcs(
+	f := s.fuzzer.Funcs(
 		func(sig *blst.Signature, c fuzz.Continue) {
 			sigHex := "0x98759b81f6595ac857dbf0a51df26c6b9bb05ada93be66a4dfff5cb7aa5b0a43cd5cf37eb7f5cdfa67df9080a4e406921484edc9596f71eb55323ec79c62a73128524db2ad3eac9d2bb2db74676a21c1b280613e574bbfd54cbd220c552b518d" //nolint
 			b, _ := hex.DecodeString(sigHex[2:])
@@ -177,13 +185,13 @@ func (s *OffChainAccusationFuzzer) Broadcast(msg message.Msg) {
 		}
 		peer, ok := backEnd.Broadcaster.FindPeer(c.Address)
 
-		evidences := fuzzedMessages()
+		evidences := s.fuzzedMessages()
 		accusation := &accountability.Proof{
-			Type:          autonity.AccountabilityEventType(rand.Int()),
-			Rule:          autonity.Rule(rand.Int()),
+			Type:          autonity.AccountabilityEventType(s.rand.Int()),
+			Rule:          autonity.Rule(s.rand.Int()),
 			Evidences:     evidences,
 			Message:       evidences[0],
-			OffenderIndex: rand.Int(),
+			OffenderIndex: s.rand.Int(),
 		}
 		proof, err := rlp.EncodeToBytes(accusation)
 		if err != nil {
@@ -308,7 +316,82 @@ func (s *OverRatedOffChainAccusation) Broadcast(msg message.Msg) {
 	}
 }
 
-// TODO(lorenzo): add test to check the maximum accusations per height
+func newEquivocationStorm(c interfaces.Core) interfaces.Broadcaster {
+	return &EquivocationStorm{Core: c.(*core.Core)}
+}
+
+// EquivocationStorm equivocates its prevotes over dozens of rounds of a single height, for the fault
+// detectors of the honest nodes to produce dozens of proofs for that height.
+type EquivocationStorm struct {
+	*core.Core
+	done bool
+}
+
+func (s *EquivocationStorm) Broadcast(msg message.Msg) {
+	s.BroadcastAll(msg)
+	if _, isPrevote := msg.(*message.Prevote); !isPrevote || s.done || msg.H() < 20 {
+		return
+	}
+	self, csize := selfAndCsize(s.Core, msg.H())
+	for r := msg.R(); r < msg.R()+40; r++ {
+		s.BroadcastAll(message.NewPrevote(r, msg.H(), e2e.NonNilValue, s.Backend().Sign, self, csize))
+		s.BroadcastAll(message.NewPrevote(r, msg.H(), common.Hash{0xca, 0xfe, byte(r)}, s.Backend().Sign, self, csize))
+	}
+	s.Logger().Info("Equivocation storm simulation", "height", msg.H())
+	s.done = true
+}
+
+// TestAccountabilitySubmissionCap checks that the honest nodes do not submit more accountability
+// events than their submission cap when a byzantine validator gets dozens of proofs raised against it
+// for a single height.
+func TestAccountabilitySubmissionCap(t *testing.T) {
+	const (
+		perHeight = 1
+		perEpoch  = 2
+	)
+	validators, err := e2e.Validators(t, 4, "10e36,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	faultyNode := 0
+	validators[faultyNode].TendermintServices = &interfaces.Services{Broadcaster: newEquivocationStorm}
+	network, err := e2e.NewNetworkFromValidators(t, validators, true)
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	for _, n := range network {
+		n.Eth.FD().SetSubmissionCap(perHeight, perEpoch)
+	}
+
+	err = network.WaitToMineNBlocks(160, 500, false)
+	require.NoError(t, err)
+	require.True(t, e2e.AccountabilityEventDetected(t, network[faultyNode].Address, autonity.Misbehaviour, autonity.Equivocation, network))
+
+	// count the accountability transactions of each node by block
+	chain := network[1].Eth.BlockChain()
+	signer := types.LatestSigner(chain.Config())
+	epochPeriod := network[1].EthConfig.Genesis.Config.AutonityContractConfig.EpochPeriod
+	head := chain.CurrentHeader().Number.Uint64()
+	submitted := make(map[common.Address]int)
+	for number := uint64(1); number <= head; number++ {
+		inBlock := make(map[common.Address]int)
+		for _, tx := range chain.GetBlockByNumber(number).Transactions() {
+			if tx.To() == nil || *tx.To() != params.AccountabilityContractAddress {
+				continue
+			}
+			sender, err := types.Sender(signer, tx)
+			require.NoError(t, err)
+			inBlock[sender]++
+			submitted[sender]++
+		}
+		for sender, count := range inBlock {
+			require.LessOrEqual(t, count, perHeight, "block %d, sender %v", number, sender)
+		}
+	}
+	// the events released in an epoch can get included in the following one
+	epochs := head/epochPeriod + 1
+	for sender, count := range submitted {
+		require.LessOrEqual(t, uint64(count), perEpoch*epochs, "sender %v", sender)
+	}
+}
+
 func TestOffChainAccusation(t *testing.T) {
 	// TODO(lorenzo) we should add a check that an offchain accountability message is actually sent
 	// if something prevents this from happening, these tests will keep passing because no proof is ever raised
@@ -327,7 +410,7 @@ func TestOffChainAccusation(t *testing.T) {
 	})
 
 	t.Run("Test off chain accusation with fuzzed msg", func(t *testing.T) {
-		handler := &interfaces.Services{Broadcaster: newOffChainAccusationFuzzer}
+		handler := &interfaces.Services{Broadcaster: newOffChainAccusationFuzzer(e2e.SeedOf(t))}
 		runDropPeerConnectionTest(t, handler, 30, 60)
 	})
 
@@ -362,9 +445,37 @@ func runDropPeerConnectionTest(t *testing.T, handler *interfaces.Services, testP
 	err = network.WaitToMineNBlocks(testPeriod, numSec, false)
 	require.NoError(t, err)
 
+	// every honest peer drops the challenger, and refuses it for the ban cooldown of a committee member.
+	for i, peer := range network {
+		if i == spammer {
+			continue
+		}
+		require.Eventually(t, func() bool {
+			return accusationDisconnects(t, peer, n.Address) > 0
+		}, time.Duration(numSec)*time.Second, 100*time.Millisecond, "challenger not dropped by peer %d", i)
+	}
+
 	// the challenger should get no peer connection left.
-	count = n.ConsensusServer().PeerCount()
-	require.Equal(t, 0, count)
+	require.Eventually(t, func() bool {
+		return n.ConsensusServer().PeerCount() == 0
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+// accusationDisconnects returns the number of times the node disconnected the peer for the off-chain accountability
+// protocol, as reported by the debug API.
+func accusationDisconnects(t *testing.T, n *e2e.Node, peer common.Address) uint64 {
+	client, err := n.Attach()
+	require.NoError(t, err)
+	defer client.Close()
+
+	var rates []accountability.PeerAccusationRate
+	require.NoError(t, client.Call(&rates, "debug_accusationRates"))
+	for _, rate := range rates {
+		if rate.Peer == peer {
+			return rate.Disconnects
+		}
+	}
+	return 0
 }
 
 func runOffChainAccountabilityEventTest(t *testing.T, handler *interfaces.Services, tp autonity.AccountabilityEventType,
@@ -45,7 +45,7 @@ func (s *randomBytesBroadcaster) Broadcast(_ message.Msg) {
 		var hash common.Hash
 		copy(hash[:], payload)
 		msg := message.Fake{FakeCode: 1, FakePayload: payload, FakeHash: hash}
-		s.Backend().Gossip(s.CommitteeSet().Committee(), msg)
+		s.Backend().Gossip(context.Background(), s.CommitteeSet().Committee(), msg)
 	}
 }
 
@@ -73,19 +73,22 @@ func TestRandomBytesBroadcaster(t *testing.T) {
 	require.NoError(t, err, "Network should be mining new blocks now, but it's not")
 }
 
-func newGarbageMessageBroadcaster(c interfaces.Core) interfaces.Broadcaster {
-	return &garbageMessageBroadcaster{c.(*core.Core)}
+func newGarbageMessageBroadcaster(seed *e2e.Seed) func(c interfaces.Core) interfaces.Broadcaster {
+	return func(c interfaces.Core) interfaces.Broadcaster {
+		return &garbageMessageBroadcaster{c.(*core.Core), seed.Fuzzer()}
+	}
 }
 
 type garbageMessageBroadcaster struct {
 	*core.Core
+	fuzzer *fuzz.Fuzzer
 }
 
 func (s *garbageMessageBroadcaster) Broadcast(_ message.Msg) {
 	logger := s.Logger().New("step", s.Step())
 	var fMsg message.Fake
 	//TODO(lorenzo) properly define custom fuzzing functions for signature and signerkey
-	f := fuzz.New().Funcs(
+	f := s.fuzzer.Funcs(
 		func(sig *blst.Signature, c fuzz.Continue) {
 			sigHex := "0x98759b81f6595ac857dbf0a51df26c6b9bb05ada93be66a4dfff5cb7aa5b0a43cd5cf37eb7f5cdfa67df9080a4e406921484edc9596f71eb55323ec79c62a73128524db2ad3eac9d2bb2db74676a21c1b280613e574bbfd54cbd220c552b518d"
 			b, _ := hex.DecodeString(sigHex[2:])
@@ -99,7 +102,7 @@ func (s *garbageMessageBroadcaster) Broadcast(_ message.Msg) {
 	)
 	f.Fuzz(&fMsg)
 	logger.Info("Broadcasting random bytes")
-	s.Backend().Gossip(s.CommitteeSet().Committee(), &fMsg)
+	s.Backend().Gossip(context.Background(), s.CommitteeSet().Committee(), &fMsg)
 }
 
 // TestGarbageMessageBroadcaster broadcasts a garbage Messages in the network,
@@ -113,7 +116,7 @@ func TestGarbageMessageBroadcaster(t *testing.T) {
 	f := bft.F(new(big.Int).SetUint64(uint64(numOfNodes)))
 	for i := uint64(0); i < f.Uint64(); i++ {
 		//set Malicious users
-		users[i].TendermintServices = &interfaces.Services{Broadcaster: newGarbageMessageBroadcaster}
+		users[i].TendermintServices = &interfaces.Services{Broadcaster: newGarbageMessageBroadcaster(e2e.SeedOf(t))}
 	}
 
 	// creates a network of 6 users and starts all the nodes in it
@@ -147,7 +150,7 @@ func (c *fuzzPrecommitSender) SendPrecommit(_ context.Context, isNil bool) {
 		precommit = message.NewPrecommit(r, h, randHash(), c.Backend().Sign, self, csize)
 	}
 	c.SetSentPrecommit(true)
-	c.Backend().Gossip(c.CommitteeSet().Committee(), precommit)
+	c.Backend().Gossip(context.Background(), c.CommitteeSet().Committee(), precommit)
 }
 
 // TestFuzzPrecommitter broadcasts a garbage precommit message in the network,
@@ -194,7 +197,7 @@ func (c *fuzzPrevoter) SendPrevote(_ context.Context, isNil bool) {
 		prevote = message.NewPrevote(r, h, randHash(), c.Backend().Sign, self, csize)
 	}
 	c.SetSentPrevote(true)
-	c.Backend().Gossip(c.CommitteeSet().Committee(), prevote)
+	c.Backend().Gossip(context.Background(), c.CommitteeSet().Committee(), prevote)
 }
 
 // TestFuzzPrevoter broadcasts a garbage prevote message in the network,
@@ -220,13 +223,16 @@ func TestFuzzPrevoter(t *testing.T) {
 	require.NoError(t, err, "Network should be mining new blocks now, but it's not")
 }
 
-func newFuzzProposer(c interfaces.Core) interfaces.Proposer {
-	return &fuzzProposer{c.(*core.Core), c.Proposer()}
+func newFuzzProposer(seed *e2e.Seed) func(c interfaces.Core) interfaces.Proposer {
+	return func(c interfaces.Core) interfaces.Proposer {
+		return &fuzzProposer{c.(*core.Core), c.Proposer(), seed.Fuzzer()}
+	}
 }
 
 type fuzzProposer struct {
 	*core.Core
 	interfaces.Proposer
+	fuzzer *fuzz.Fuzzer
 }
 
 /*
@@ -260,10 +266,9 @@ func generateFieldMap(v interface{}) map[string]reflect.Value {
 */
 // duplicated with TestInvalidBlockProposal in proposal_test.go
 func (c *fuzzProposer) SendProposal(_ context.Context, p *types.Block) {
-	f := fuzz.New()
 	var num big.Int
-	f.Fuzz(&num)
-	e2e.FuzBlock(p, &num)
+	c.fuzzer.Fuzz(&num)
+	e2e.FuzBlockWith(c.fuzzer, p, &num)
 	self, _ := selfAndCsize(c.Core, c.Height().Uint64())
 	proposal := message.NewPropose(c.Round(), c.Height().Uint64(), c.ValidRound(), p, c.Backend().Sign, self)
 	c.SetSentProposal(true)
@@ -282,7 +287,7 @@ func TestFuzzProposer(t *testing.T) {
 	f := bft.F(new(big.Int).SetUint64(uint64(numOfNodes)))
 	for i := uint64(0); i < f.Uint64(); i++ {
 		//set Malicious users
-		users[i].TendermintServices = &interfaces.Services{Proposer: newFuzzProposer}
+		users[i].TendermintServices = &interfaces.Services{Proposer: newFuzzProposer(e2e.SeedOf(t))}
 	}
 
 	// creates a network of 6 users and starts all the nodes in it
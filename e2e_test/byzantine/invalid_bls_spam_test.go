@@ -4,10 +4,12 @@ import (
 	"context"
 	"crypto/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/autonity/autonity/common"
+	tendermint "github.com/autonity/autonity/consensus/tendermint/backend"
 	"github.com/autonity/autonity/consensus/tendermint/core"
 	ccore "github.com/autonity/autonity/core"
 
@@ -21,38 +23,69 @@ import (
 
 // TODO(lorenzo) Add test for malicious peer sending invalid sig and disconnecting right away. Does disconnection still work as intended?
 
+// invalidSigner signs a random hash instead of the given one, producing a well-formed but invalid signature.
+func invalidSigner(c *core.Core) message.Signer {
+	return func(common.Hash) blst.Signature {
+		var h common.Hash
+		rand.Read(h[:])
+		return c.Backend().Sign(h)
+	}
+}
+
 func newInvalidSignatureBroadcaster(c interfaces.Core) interfaces.Prevoter {
-	return &invalidSignatureBroadcaster{c.(*core.Core), c.Prevoter(), false}
+	return &invalidSignatureBroadcaster{c.(*core.Core), c.Prevoter(), 0}
 }
 
 type invalidSignatureBroadcaster struct {
 	*core.Core
 	interfaces.Prevoter
-	sent bool
+	sent int
 }
 
-// when sending a prevote, do the standard behaviour + send an invalid signature prevote
+// when sending a prevote, do the standard behaviour + send an invalid signature prevote, until the
+// peers ran out of tolerance
 func (c *invalidSignatureBroadcaster) SendPrevote(ctx context.Context, isNil bool) {
 	// send invalid sig
-	if !c.sent {
-		invalidSigner := func(hash common.Hash) blst.Signature {
-			var h common.Hash
-			rand.Read(h[:])
-			return c.Backend().Sign(h)
-		}
+	if c.sent < tendermint.MessageErrorPenalty(message.ErrBadSignature).Strikes {
 		hash := c.CurRoundMessages().ProposalHash()
 		self, csize := selfAndCsize(c.Core, c.Height().Uint64())
-		prevote := message.NewPrevote(c.Round(), c.Height().Uint64(), hash, invalidSigner, self, csize)
-		c.Backend().Gossip(c.CommitteeSet().Committee(), prevote)
-		c.sent = true
+		prevote := message.NewPrevote(c.Round(), c.Height().Uint64(), hash, invalidSigner(c.Core), self, csize)
+		c.Backend().Gossip(context.Background(), c.CommitteeSet().Committee(), prevote)
+		c.sent++
 	}
 
 	// standard behaviour
 	c.Prevoter.SendPrevote(ctx, isNil)
 }
 
+func newGarbageVoteBroadcaster(c interfaces.Core) interfaces.Broadcaster {
+	return &garbageVoteBroadcaster{c.(*core.Core)}
+}
+
+// garbageVoteBroadcaster follows the protocol, but relays a copy of each of its votes carrying an
+// invalid signature along with it.
+type garbageVoteBroadcaster struct {
+	*core.Core
+}
+
+func (c *garbageVoteBroadcaster) Broadcast(msg message.Msg) {
+	c.BroadcastAll(msg)
+
+	self, csize := selfAndCsize(c.Core, msg.H())
+	var garbage message.Msg
+	switch msg.(type) {
+	case *message.Prevote:
+		garbage = message.NewPrevote(msg.R(), msg.H(), msg.Value(), invalidSigner(c.Core), self, csize)
+	case *message.Precommit:
+		garbage = message.NewPrecommit(msg.R(), msg.H(), msg.Value(), invalidSigner(c.Core), self, csize)
+	default:
+		return
+	}
+	c.Backend().Gossip(context.Background(), c.CommitteeSet().Committee(), garbage)
+}
+
 func TestInvalidBlsSignatureDisconnection(t *testing.T) {
-	t.Run("Malicious peer sending an invalid BLS signature should be disconnect for at least 1 epoch", func(t *testing.T) {
+	t.Run("Malicious peer sending invalid BLS signatures should be disconnect for at least 1 epoch", func(t *testing.T) {
 		n := 4
 		validators, err := e2e.Validators(t, n, "10e36,v,100,0.0.0.0:%s,%s,%s,%s")
 		require.NoError(t, err)
@@ -95,3 +128,31 @@ func TestInvalidBlsSignatureDisconnection(t *testing.T) {
 		}
 	})
 }
+
+func TestGarbageSignedVotesDisconnection(t *testing.T) {
+	n := 4
+	validators, err := e2e.Validators(t, n, "10e36,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+
+	// the malicious validator relays garbage signed copies of its votes
+	malicious := 0
+	validators[malicious].TendermintServices = &interfaces.Services{Broadcaster: newGarbageVoteBroadcaster}
+	network, err := e2e.NewNetworkFromValidators(t, validators, true, func(genesis *ccore.Genesis) { genesis.Config.AutonityContractConfig.EpochPeriod = 100 })
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+
+	// the malicious peer is dropped by the honest ones once it ran out of strikes
+	require.Eventually(t, func() bool {
+		return len(network[malicious].ConsensusServer().PeersInfo()) == 0
+	}, 60*time.Second, 100*time.Millisecond, "malicious peer still connected")
+
+	// the honest peers keep mining without it
+	err = network.WaitToMineNBlocks(10, 60, false)
+	require.NoError(t, err)
+	for i, node := range network {
+		require.Equal(t, n-1, len(node.ExecutionServer().PeersInfo()))
+		if i != malicious {
+			require.Equal(t, n-2, len(node.ConsensusServer().PeersInfo()))
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package byzantine
 
 import (
+	"context"
 	"math/big"
 	"math/rand"
 	"testing"
@@ -35,7 +36,7 @@ type rawMSGFuzzer struct {
 }
 
 // Faulty node keeps broadcasting fuzz raw message to committee. Every input message of this interface will be fuzzed.
-func (fg *rawMSGFuzzer) Gossip(committee types.Committee, msg message.Msg) {
+func (fg *rawMSGFuzzer) Gossip(_ context.Context, committee types.Committee, msg message.Msg) {
 	targets := make([]common.Address, 0)
 	i := 0
 	for _, val := range committee {
@@ -66,7 +67,7 @@ func (fg *rawMSGFuzzer) Gossip(committee types.Committee, msg message.Msg) {
 	}
 }
 
-func (fg *rawMSGFuzzer) AskSync(_ *types.Header) {
+func (fg *rawMSGFuzzer) AskSync(_ *types.Header, _ []common.Hash) {
 }
 
 func TestRawMessageFuzzer(t *testing.T) {
@@ -357,6 +357,19 @@ func (c *Caller) GetCommittee() ([]autonity.AutonityCommitteeMember, error) {
 	return committee, err
 }
 
+func (c *Caller) EpochID() (uint64, error) {
+	var epochID uint64
+	err := c.execute(func(instance *autonity.Autonity, opts *bind.CallOpts) error {
+		id, err := instance.EpochID(opts)
+		if err != nil {
+			return err
+		}
+		epochID = id.Uint64()
+		return nil
+	})
+	return epochID, err
+}
+
 func (c *Caller) GetValidators() ([]common.Address, error) {
 	var validators []common.Address
 	err := c.execute(func(instance *autonity.Autonity, opts *bind.CallOpts) error {
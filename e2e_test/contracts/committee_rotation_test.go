@@ -0,0 +1,94 @@
+package contracts
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	e2e "github.com/autonity/autonity/e2e_test"
+)
+
+// TestCommitteeRotation registers a new validator mid-run and checks that it enters the committee and
+// starts voting at the next epoch, while an existing validator unbonding its stake drops out of it.
+func TestCommitteeRotation(t *testing.T) {
+	validators, err := e2e.Validators(t, 5, "10e18,v,1000,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	network, err := e2e.NewNetworkFromValidators(t, validators[:4], true)
+	require.NoError(t, err)
+	defer func() { network.Shutdown(t) }()
+
+	// wait for the consensus engine to work.
+	network.WaitToMineNBlocks(2, 10, false)
+
+	operatorNode := network[0]
+	operatorKey := operatorNode.Key
+	stake := new(big.Int).SetUint64(validators[0].Stake)
+
+	// the new validator node joins the network without stake.
+	newNode, err := e2e.NewNode(validators[4], operatorNode.EthConfig.Genesis, 4)
+	require.NoError(t, err)
+	require.NoError(t, newNode.Start())
+	network = append(network, newNode)
+	newNode.ExecutionServer().AddPeer(operatorNode.ExecutionServer().Self())
+
+	err = fundingAccounts(operatorNode, []*ecdsa.PrivateKey{newNode.Key})
+	require.NoError(t, err)
+	require.NoError(t, e2e.RegisterValidator(operatorNode, validators[4]))
+	require.NoError(t, operatorNode.AwaitMintNTN(operatorKey, newNode.Address, stake, 5*time.Second))
+	require.NoError(t, e2e.BondStake(operatorNode, newNode.Key, newNode.Address, stake))
+
+	// the new validator enters the committee at the next epoch and starts voting.
+	epoch, err := operatorNode.Interactor.Call(nil).EpochID()
+	require.NoError(t, err)
+	require.NoError(t, network.WaitForEpoch(epoch+1, 120))
+	committee, err := operatorNode.Interactor.Call(nil).GetCommittee()
+	require.NoError(t, err)
+	require.Len(t, committee, 5)
+	require.True(t, inCommittee(committee, newNode.Address))
+	requireVoting(t, network, newNode.Address)
+
+	// an existing validator unbonds all its stake and drops out of the committee at the next epoch.
+	leaving := network[3]
+	require.NoError(t, e2e.UnbondStake(operatorNode, validators[3].TreasuryKey, leaving.Address, stake))
+	epoch, err = operatorNode.Interactor.Call(nil).EpochID()
+	require.NoError(t, err)
+	require.NoError(t, network.WaitForEpoch(epoch+1, 120))
+	committee, err = operatorNode.Interactor.Call(nil).GetCommittee()
+	require.NoError(t, err)
+	require.Len(t, committee, 4)
+	require.False(t, inCommittee(committee, leaving.Address))
+	require.True(t, inCommittee(committee, newNode.Address))
+
+	// the network keeps on finalising blocks without the leaving validator.
+	require.NoError(t, network.WaitToMineNBlocks(5, 30, false))
+	requireVoting(t, network, newNode.Address)
+}
+
+func inCommittee(committee []autonity.AutonityCommitteeMember, address common.Address) bool {
+	return slices.ContainsFunc(committee, func(member autonity.AutonityCommitteeMember) bool {
+		return member.Addr == address
+	})
+}
+
+// requireVoting checks that the validator precommits are aggregated in a quorum certificate of the
+// next blocks finalised by the network.
+func requireVoting(t *testing.T, network e2e.Network, validator common.Address) {
+	const blocks = 10
+	require.NoError(t, network.WaitToMineNBlocks(blocks, 60, false))
+	observer := network[0]
+	head := observer.GetChainHeight()
+	for height := head - blocks + 1; height <= head; height++ {
+		signers, err := e2e.QuorumCertificateSigners(observer, height)
+		require.NoError(t, err)
+		if slices.Contains(signers, validator) {
+			return
+		}
+	}
+	require.Failf(t, "validator not voting", "%v precommits not in the last %d quorum certificates", validator, blocks)
+}
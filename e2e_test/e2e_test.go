@@ -2,11 +2,13 @@ package e2e
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"math/big"
-	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
@@ -15,16 +17,19 @@ import (
 	"text/tabwriter"
 	"time"
 
-	"github.com/hashicorp/consul/sdk/freeport"
 	"github.com/stretchr/testify/require"
 
 	"github.com/autonity/autonity/accounts/abi/bind"
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/acn/protocol"
+	"github.com/autonity/autonity/consensus/tendermint/backend"
 	"github.com/autonity/autonity/consensus/tendermint/core"
 	"github.com/autonity/autonity/consensus/tendermint/core/constants"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/consensus/tendermint/core/msgtrace"
+	"github.com/autonity/autonity/consensus/tendermint/core/timingdump"
 	ccore "github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto/blst"
@@ -392,6 +397,90 @@ func TestWaitForChainSyncAfterStop(t *testing.T) {
 
 }
 
+// voteRecorder records the votes a node sends across its restarts.
+type voteRecorder struct {
+	sync.Mutex
+	votes map[string]common.Hash
+}
+
+func (r *voteRecorder) broadcaster() func(c interfaces.Core) interfaces.Broadcaster {
+	return func(c interfaces.Core) interfaces.Broadcaster {
+		return &recordingBroadcaster{c.(*core.Core), r}
+	}
+}
+
+type recordingBroadcaster struct {
+	*core.Core
+	recorder *voteRecorder
+}
+
+func (s *recordingBroadcaster) Broadcast(msg message.Msg) {
+	switch msg.(type) {
+	case *message.Prevote, *message.Precommit:
+		key := fmt.Sprintf("%d/%d/%d", msg.Code(), msg.H(), msg.R())
+		s.recorder.Lock()
+		if sent, ok := s.recorder.votes[key]; ok && sent != msg.Value() {
+			s.recorder.Unlock()
+			panic("restarted node sent a conflicting vote: " + msg.String())
+		}
+		s.recorder.votes[key] = msg.Value()
+		s.recorder.Unlock()
+	}
+	s.BroadcastAll(msg)
+}
+
+// Tests that a node restarted in the middle of a height resumes its journaled round, without sending
+// votes conflicting with the ones sent before it stopped.
+func TestResumeJournaledRound(t *testing.T) {
+	network, err := NewNetwork(t, 4, "10e18,v,1,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+
+	recorder := &voteRecorder{votes: make(map[string]common.Hash)}
+	network[0].CustHandler = &interfaces.Services{Broadcaster: recorder.broadcaster()}
+	require.NoError(t, network[0].Close(false))
+	network[0].Wait()
+	require.NoError(t, network[0].Start())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, network.WaitToMineNBlocks(5, 60, false))
+		// node 0 is needed for liveness once another node is stopped, it resumes the height in progress
+		require.NoError(t, network[1].Close(false))
+		network[1].Wait()
+		require.NoError(t, network[0].Close(false))
+		network[0].Wait()
+		require.NoError(t, network[0].Start())
+		require.NoError(t, network[1].Start())
+	}
+	require.NoError(t, network.WaitToMineNBlocks(10, 60, false))
+}
+
+// Tests that the nodes restarted while the network is stalled recover the consensus state of the height in
+// progress, the sync requests of the ones resuming a journaled round listing the messages they hold.
+func TestSyncStalledHeight(t *testing.T) {
+	network, err := NewNetwork(t, 4, "10e18,v,1,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	require.NoError(t, network.WaitToMineNBlocks(3, 60, false))
+
+	// with two nodes down, the height in progress stalls while the others keep exchanging its messages
+	for _, n := range network[2:] {
+		require.NoError(t, n.Close(false))
+		n.Wait()
+	}
+	err = network.WaitToMineNBlocks(1, 5, false)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// node 1 lags behind node 0 once restarted, with the messages of its journal only
+	require.NoError(t, network[1].Close(false))
+	network[1].Wait()
+	require.NoError(t, network[1].Start())
+	for _, n := range network[2:] {
+		require.NoError(t, n.Start())
+	}
+	require.NoError(t, network.WaitToMineNBlocks(5, 60, false))
+}
+
 // Test details
 // a.setup 7 validators with 100 voting power on each, and keep 7 committee seats as well.
 // b.start the network with 1st 3 nodes only, the network should be on-hold since the online voting power is less than 2/3 of 7
@@ -565,6 +654,267 @@ func TestTendermintQuorum4(t *testing.T) {
 	require.NoError(t, err, "Network should be mining new blocks now, but it's not")
 }
 
+// TestConsensusMessageTraces checks that the sampled consensus messages are traced through the whole
+// processing pipeline, from their reception to their gossip.
+func TestConsensusMessageTraces(t *testing.T) {
+	network, err := NewNetwork(t, 4, "10e18,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	for _, n := range network {
+		n.Eth.Engine().(*backend.Backend).SetTraceSampling(1)
+	}
+	require.NoError(t, network.WaitToMineNBlocks(10, 60, false))
+
+	for _, n := range network {
+		traces := n.Eth.Engine().(*backend.Backend).ConsensusTraces(msgtrace.DefaultCapacity)
+		require.NotEmpty(t, traces)
+		complete := 0
+		for _, trace := range traces {
+			require.Equal(t, msgtrace.Receive, trace.Stages[0].Stage)
+			for i := 1; i < len(trace.Stages); i++ {
+				require.GreaterOrEqual(t, trace.Stages[i].Stage, trace.Stages[i-1].Stage, "stages out of order: %v", trace.Stages)
+				require.False(t, trace.Stages[i].Time.Before(trace.Stages[i-1].Time), "time going backwards: %v", trace.Stages)
+			}
+			seen := make(map[msgtrace.Stage]bool)
+			for _, stage := range trace.Stages {
+				seen[stage.Stage] = true
+			}
+			if len(seen) == 5 {
+				complete++
+			}
+		}
+		require.NotZero(t, complete, "no message traced through all the stages")
+	}
+}
+
+// TestConsensusTimingDump checks that each node dumps a well-formed CSV row per committed height while
+// the consensus timing dump is active, and that the dump file is rotated at its size cap.
+func TestConsensusTimingDump(t *testing.T) {
+	network, err := NewNetwork(t, 4, "10e18,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	require.NoError(t, network.WaitToMineNBlocks(2, 60, false))
+
+	dir := t.TempDir()
+	paths := make([]string, len(network))
+	for i, n := range network {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("timing-%d.csv", i))
+		// a cap of a few rows to go through a rotation
+		maxSize := int64(len(strings.Join(timingdump.Header, ",")) + 1 + 3*64)
+		require.NoError(t, n.Eth.Engine().(*backend.Backend).StartConsensusTimingDump(paths[i], 100*time.Millisecond, maxSize))
+		require.ErrorIs(t, n.Eth.Engine().(*backend.Backend).StartConsensusTimingDump(paths[i], time.Second, maxSize), timingdump.ErrDumpActive)
+	}
+	require.NoError(t, network.WaitToMineNBlocks(10, 60, false))
+
+	// the committed block of a height is proposed by a single node
+	proposers := make(map[uint64]int)
+	for i, n := range network {
+		require.NoError(t, n.Eth.Engine().(*backend.Backend).StopConsensusTimingDump())
+		// the rotated files hold the oldest rows, the most recent rotated file is suffixed with .1
+		var rows [][]string
+		for k := timingdump.MaxBackups; k >= 0; k-- {
+			path := paths[i]
+			if k > 0 {
+				path = fmt.Sprintf("%s.%d", paths[i], k)
+			}
+			f, err := os.Open(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			require.NoError(t, err)
+			records, err := csv.NewReader(f).ReadAll()
+			f.Close()
+			require.NoError(t, err)
+			require.Equal(t, timingdump.Header, records[0])
+			rows = append(rows, records[1:]...)
+		}
+		_, err := os.Stat(paths[i] + ".1")
+		require.NoError(t, err, "dump file not rotated")
+		require.GreaterOrEqual(t, len(rows), 10)
+
+		for j, row := range rows {
+			height, err := strconv.ParseUint(row[0], 10, 64)
+			require.NoError(t, err)
+			if j > 0 {
+				previous, _ := strconv.ParseUint(rows[j-1][0], 10, 64)
+				require.Equal(t, previous+1, height, "missing height")
+			}
+			round, err := strconv.ParseInt(row[1], 10, 64)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, round, int64(0))
+			proposer, err := strconv.ParseBool(row[2])
+			require.NoError(t, err)
+			if proposer {
+				proposers[height]++
+			}
+			for _, field := range row[3:7] {
+				duration, err := strconv.ParseFloat(field, 64)
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, duration, 0.0)
+			}
+			counts := make([]uint64, 3)
+			for k, field := range row[7:] {
+				counts[k], err = strconv.ParseUint(field, 10, 64)
+				require.NoError(t, err)
+			}
+			require.NotZero(t, counts[0], "no proposal at height %d", height)
+			require.NotZero(t, counts[2], "no precommit at height %d", height)
+		}
+	}
+	for height, count := range proposers {
+		require.LessOrEqual(t, count, 1, "height %d", height)
+	}
+}
+
+// accountabilityEvents returns the accusations and the fault proofs raised against offender.
+func accountabilityEvents(t *testing.T, n *Node, offender common.Address) int {
+	contract, err := autonity.NewAccountability(params.AccountabilityContractAddress, n.WsClient)
+	require.NoError(t, err)
+	faults, err := contract.GetValidatorFaults(nil, offender)
+	require.NoError(t, err)
+	iter, err := contract.FilterNewAccusation(nil, []common.Address{offender})
+	require.NoError(t, err)
+	defer iter.Close()
+	accusations := 0
+	for iter.Next() {
+		accusations++
+	}
+	require.NoError(t, iter.Error())
+	return len(faults) + accusations
+}
+
+// TestClockSkewedMember checks that a committee member whose clock is 30s ahead does not hold the network:
+// its proposals are rejected as blocks from the future while the three honest clocks commit the others. The
+// skewed member detects its skew from the proposals it receives, and the honest members are not held
+// accountable for rejecting its proposals.
+func TestClockSkewedMember(t *testing.T) {
+	users, err := Validators(t, 4, "10e18,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	skewed := 0
+	users[skewed].TendermintServices = &interfaces.Services{ClockSkew: 30 * time.Second}
+	network, err := NewNetworkFromValidators(t, users, true)
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	require.NoError(t, network.WaitToMineNBlocks(20, 120, false))
+
+	for i, n := range network {
+		status := n.Eth.Engine().(*backend.Backend).ClockSkew()
+		if i == skewed {
+			require.True(t, status.Detected, "skew not detected: %+v", status)
+			require.InDelta(t, 30*time.Second, status.Offset, float64(2*time.Second))
+			require.Equal(t, 30*time.Second, status.Injected)
+			continue
+		}
+		require.False(t, status.Detected, "node %d: skew detected: %+v", i, status)
+		require.Zero(t, status.Injected)
+	}
+	for i, n := range network {
+		if i != skewed {
+			require.Zero(t, accountabilityEvents(t, network[1], n.Address), "node %d held accountable", i)
+		}
+	}
+}
+
+// TestClockSkewedMembersLiveness checks that with two members of four 30s ahead, the heights they propose
+// for are decided after round changes: the liveness watchdog reports it degraded, without the chain
+// stalling, and back live once the clocks are fixed.
+func TestClockSkewedMembersLiveness(t *testing.T) {
+	users, err := Validators(t, 4, "10e18,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	for _, skewed := range []int{0, 1} {
+		users[skewed].TendermintServices = &interfaces.Services{ClockSkew: 30 * time.Second}
+	}
+	network, err := NewNetworkFromValidators(t, users, true)
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	require.NoError(t, network.WaitToMineNBlocks(10, 120, false))
+
+	honest := network[3].Eth.Engine().(*backend.Backend)
+	require.Eventually(t, func() bool {
+		return honest.Liveness().Status == backend.LivenessDegraded
+	}, 60*time.Second, 100*time.Millisecond, "liveness not degraded: %+v", honest.Liveness())
+	require.NotZero(t, honest.Liveness().RoundChanges)
+
+	for _, skewed := range []int{0, 1} {
+		network[skewed].Eth.Engine().(*backend.Backend).SetClockSkew(0)
+	}
+	require.NoError(t, network.WaitToMineNBlocks(10, 60, false))
+	for i, n := range network {
+		engine := n.Eth.Engine().(*backend.Backend)
+		require.Eventually(t, func() bool {
+			return engine.Liveness().Status == backend.LivenessLive
+		}, 90*time.Second, 100*time.Millisecond, "node %d: liveness not restored: %+v", i, engine.Liveness())
+		require.Zero(t, engine.Liveness().Stalls, "node %d stalled", i)
+	}
+}
+
+// TestConsensusProtocolUpgrade checks that a network keeps committing blocks while half of the
+// validators advertise the old consensus network protocol version only, and that each node reports
+// the version mix of its committee peers.
+func TestConsensusProtocolUpgrade(t *testing.T) {
+	users, err := Validators(t, 4, "10e18,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	network, err := NewNetworkFromValidators(t, users, false)
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	for i, n := range network {
+		if i < 2 {
+			n.Config.ConsensusProtocolVersions = []uint{protocol.ACNv1}
+		}
+		require.NoError(t, n.Start())
+	}
+	require.NoError(t, network.WaitToMineNBlocks(10, 60, false))
+
+	for i, n := range network {
+		// the old-only nodes speak the old version to everybody, the upgraded nodes the new
+		// version between themselves
+		want := map[uint]int{protocol.ACNv1: 3}
+		if i >= 2 {
+			want = map[uint]int{protocol.ACNv3: 1, protocol.ACNv2: 0, protocol.ACNv1: 2}
+		}
+		require.Eventually(t, func() bool {
+			return reflect.DeepEqual(want, n.ACN.CommitteeVersionMix())
+		}, 10*time.Second, 100*time.Millisecond, "node %d: have %v, want %v", i, n.ACN.CommitteeVersionMix(), want)
+	}
+}
+
+// TestConsensusIdentityBinding checks that a node holding the node key of a validator, but not its
+// consensus key, is rejected from the consensus network while it stays connected on the execution
+// network.
+func TestConsensusIdentityBinding(t *testing.T) {
+	users, err := Validators(t, 4, "10e18,v,100,0.0.0.0:%s,%s,%s,%s")
+	require.NoError(t, err)
+	network, err := NewNetworkFromValidators(t, users, true)
+	require.NoError(t, err)
+	defer network.Shutdown(t)
+	require.NoError(t, network.WaitToMineNBlocks(5, 60, false))
+
+	// the impostor takes the enode of the last validator, with another consensus key
+	impostor := network[3]
+	require.NoError(t, impostor.Close(false))
+	wrongKey, err := blst.RandKey()
+	require.NoError(t, err)
+	impostor.Config.ConsensusKey = wrongKey
+	impostor.ConsensusKey = wrongKey
+	require.NoError(t, impostor.Start())
+
+	// the remaining validators hold a quorum and keep committing blocks
+	require.NoError(t, network.WaitToMineNBlocks(5, 60, false))
+	impostorID := impostor.ExecutionServer().Self().ID()
+	for i, n := range network[:3] {
+		_, ok := n.ACN.FindPeer(impostor.Address)
+		require.False(t, ok, "node %d: impostor in the consensus network", i)
+		require.Eventually(t, func() bool {
+			for _, peer := range n.ExecutionServer().Peers() {
+				if peer.ID() == impostorID {
+					return true
+				}
+			}
+			return false
+		}, 30*time.Second, 100*time.Millisecond, "node %d: impostor not in the execution network", i)
+	}
+}
+
 // setup up a network of 12 nodes
 // ensure the newtwork is running and blocks are getting mined
 // start/stop nodes in parallel
@@ -595,7 +945,7 @@ func TestStartStopAllNodesInParallel(t *testing.T) {
 
 	// randomly start stop nodes
 	for j := 0; j < 40; j++ {
-		i := rand.Intn(nodeCount) //nolint:gosec
+		i := SeedOf(t).Intn(nodeCount)
 		switch i % 4 {
 		case 0, 1, 2:
 			wg.Add(1)
@@ -679,7 +1029,7 @@ func TestValidatorMigration(t *testing.T) {
 	oldEnode, err := enode.ParseV4(validator.Enode)
 	require.NoError(t, err)
 	// use new port in the newer enode
-	newPort := freeport.GetOne(t)
+	newPort := SeedOf(t).FreePort(t)
 	newEnode := enode.AppendConsensusEndpoint(oldEnode.Host(), strconv.Itoa(newPort), oldEnode.String())
 
 	_, err = autonityContract.PauseValidator(transactor, n.Address)
@@ -844,7 +1194,7 @@ func TestLargeNetwork(t *testing.T) {
 			fmt.Println("-------------", tickCount, "---------------")
 
 			if tickCount%10 == 4 {
-				newMaxCommittee := rand.Intn(50) + 1
+				newMaxCommittee := SeedOf(t).Intn(50) + 1
 				autonityContract.SetCommitteeSize(transactOpts, big.NewInt(int64(newMaxCommittee)))
 				fmt.Println("new committee size:", newMaxCommittee)
 			}
@@ -104,8 +104,12 @@ func AccountabilityEventDetected(t *testing.T, faultyValidator common.Address, e
 }
 
 func FuzBlock(p *types.Block, height *big.Int) {
+	FuzBlockWith(fuzz.New(), p, height)
+}
+
+// FuzBlockWith fuzzes the block with the given fuzzer, see Seed.Fuzzer.
+func FuzBlockWith(f *fuzz.Fuzzer, p *types.Block, height *big.Int) {
 	fakeTransactions := make([]*types.Transaction, 0)
-	f := fuzz.New()
 	for i := 0; i < 5; i++ {
 		var fakeTransaction types.Transaction
 		f.Fuzz(&fakeTransaction)
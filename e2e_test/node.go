@@ -12,13 +12,13 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hashicorp/consul/sdk/freeport"
 	"go.uber.org/goleak"
 
 	ethereum "github.com/autonity/autonity"
 	"github.com/autonity/autonity/cmd/gengen/gengen"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/graph"
+	"github.com/autonity/autonity/common/randutil"
 	"github.com/autonity/autonity/consensus/acn"
 	"github.com/autonity/autonity/consensus/tendermint/core/interfaces"
 	"github.com/autonity/autonity/core"
@@ -83,6 +83,7 @@ type Node struct {
 	isRunning bool
 	Config    *node.Config
 	Eth       *eth.Ethereum
+	ACN       *acn.ACN
 	EthConfig *ethconfig.Config
 	WsClient  *ethclient.Client
 
@@ -201,7 +202,9 @@ func (n *Node) Start() error {
 		return fmt.Errorf("cannot create new eth: %w", err)
 	}
 
-	acn.New(n.Node, n.Eth, ethconfig.Defaults.NetworkID)
+	if n.ACN, err = acn.New(n.Node, n.Eth, ethconfig.Defaults.NetworkID); err != nil {
+		return fmt.Errorf("cannot create new acn: %w", err)
+	}
 	if err = n.Node.Start(); err != nil {
 		return fmt.Errorf("failed to start a node: %w", err)
 	}
@@ -518,6 +521,7 @@ func (nw Network) WaitForHeight(height uint64, numSec int) error {
 // an error it will be returned immediately, meaning that some nodes may be
 // running and others not.
 func NewNetworkFromValidators(t *testing.T, validators []*gengen.Validator, start bool, options ...gengen.GenesisOption) (Network, error) {
+	seedUnseededValidators(t, validators)
 	g, err := Genesis(validators, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed the genesis: %w", err)
@@ -614,6 +618,7 @@ func (p *pipeDialer) Dial(_ context.Context, dest *enode.Node) (conn net.Conn, e
 }
 
 func NewInMemoryNetwork(t *testing.T, validators []*gengen.Validator, start bool, options ...gengen.GenesisOption) (Network, error) {
+	seedUnseededValidators(t, validators)
 	g, err := Genesis(validators, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed the genesis: %w", err)
@@ -630,7 +635,7 @@ func NewInMemoryNetwork(t *testing.T, validators []*gengen.Validator, start bool
 		go func(id int, val *gengen.Validator) {
 			n, _ := NewNode(val, g, id)
 			if id == 0 {
-				n.Config.WSPort = freeport.GetOne(t)
+				n.Config.WSPort = SeedOf(t).FreePort(t)
 			}
 			nodeID := enode.PubkeyToIDV4(&val.NodeKey.PublicKey)
 			n.Config.ConsensusP2P.Dialer = consensusManager.createPipeDialer(n)
@@ -697,11 +702,7 @@ func (nw Network) Shutdown(t *testing.T) {
 
 func checkGoRoutineLeak(t *testing.T) {
 	time.Sleep(1 * time.Second)
-	goleak.VerifyNone(t,
-		// this routine from 3rd party SDK is used only by E2E test framework for port query, it does not have a shutdown
-		// triggered from inside of it, and we cannot shut down it from e2e test framework side as well.
-		goleak.IgnoreTopFunction("github.com/hashicorp/consul/sdk/freeport.checkFreedPorts"),
-	)
+	goleak.VerifyNone(t)
 }
 
 type TopologyManager struct {
@@ -810,23 +811,55 @@ func ValueTransferTransaction(client *ethclient.Client,
 // package see the variable 'userDescription' in the gengen package for a
 // detailed description of the meaning of the format string.
 // E.G. for a validator '10e18,v,1,0.0.0.0:%s,%s,%s,%s'.
+// The ports and the keys derive from the seed of the test, see SeedOf.
 func Validators(t *testing.T, count int, formatString string) ([]*gengen.Validator, error) {
+	seed := SeedOf(t)
 	var validators []*gengen.Validator
 	for i := 0; i < count; i++ {
-		portString := strconv.Itoa(freeport.GetOne(t))
+		portString := strconv.Itoa(seed.FreePort(t))
 		u, err := gengen.ParseValidator(fmt.Sprintf(formatString, portString, "key"+portString))
 		if err != nil {
 			return nil, err
 		}
 		//add port ip for consensus channel
 		u.AcnIP = u.NodeIP
-		u.AcnPort = freeport.GetOne(t)
-		u.TreasuryKey, _ = crypto.GenerateKey()
+		u.AcnPort = seed.FreePort(t)
+		// the keys generated by gengen are replaced with the ones derived from the seed
+		u.NodeKey = seed.Key()
+		u.ConsensusKey = seed.ConsensusKey()
+		u.OracleKey = seed.Key()
+		u.TreasuryKey = seed.Key()
 		validators = append(validators, u)
 	}
 	return validators, nil
 }
 
+// SeedValidators gives each validator a deterministic source of randomness derived from seed,
+// so that the consensus layer random choices of a failing scenario can be replayed.
+// It must be called after the custom tendermint services, if any, are set.
+func SeedValidators(validators []*gengen.Validator, seed int64) {
+	for i, validator := range validators {
+		if validator.TendermintServices == nil {
+			validator.TendermintServices = &interfaces.Services{}
+		}
+		validator.TendermintServices.Rand = randutil.NewSeeded(seed + int64(i))
+	}
+}
+
+// seedUnseededValidators gives the validators not seeded with SeedValidators a source of randomness
+// derived from the seed of the test.
+func seedUnseededValidators(t *testing.T, validators []*gengen.Validator) {
+	seed := SeedOf(t)
+	for _, validator := range validators {
+		if validator.TendermintServices == nil {
+			validator.TendermintServices = &interfaces.Services{}
+		}
+		if validator.TendermintServices.Rand == nil {
+			validator.TendermintServices.Rand = randutil.NewSeeded(seed.Int63())
+		}
+	}
+}
+
 // This is used by the monitor tool to retrieve a useful websocket port
 func communicatePort(port int) {
 	conn, err := net.Dial("tcp", "localhost:55000")
@@ -0,0 +1,191 @@
+package e2e
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fuzz "github.com/google/gofuzz"
+
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/crypto/blst"
+)
+
+// SeedEnv is the environment variable holding the seed to replay the framework inputs of a run with.
+const SeedEnv = "E2E_SEED"
+
+// The free port search starts at a port derived from the seed, within this range.
+const (
+	minPort   = 10000
+	portRange = 20000
+)
+
+var (
+	seedsMu sync.Mutex
+	seeds   = make(map[string]*Seed) // seed of each top-level test running
+
+	portsMu sync.Mutex
+	ports   = make(map[int]struct{}) // ports handed out by the free port search
+)
+
+// Seed is the source of the inputs the framework generates for a test: the validator keys, the
+// account keys, the ports, the fuzzers and its random choices. Two runs with the same seed get the
+// same inputs, apart from the ports found busy by the free port search. The OS scheduling remains
+// non-deterministic.
+type Seed struct {
+	seed int64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	port int // next port probed by the free port search
+}
+
+func newSeed(seed int64) *Seed {
+	r := rand.New(rand.NewSource(seed)) //nolint:gosec
+	return &Seed{seed: seed, rand: r, port: minPort + r.Intn(portRange)}
+}
+
+// WithSeed sets the seed of the framework inputs for the test and its subtests, to replay a failing
+// run. It must be called before the validators are generated.
+func WithSeed(t *testing.T, seed int64) *Seed {
+	s := newSeed(seed)
+	name := topLevelName(t)
+	seedsMu.Lock()
+	seeds[name] = s
+	seedsMu.Unlock()
+	t.Cleanup(func() {
+		seedsMu.Lock()
+		defer seedsMu.Unlock()
+		if seeds[name] == s {
+			delete(seeds, name)
+		}
+	})
+	s.print(t)
+	return s
+}
+
+// SeedOf returns the seed of the framework inputs for the test. Unless set with WithSeed, it is read
+// from the SeedEnv environment variable, or chosen at random.
+func SeedOf(t *testing.T) *Seed {
+	seedsMu.Lock()
+	s, ok := seeds[topLevelName(t)]
+	seedsMu.Unlock()
+	if ok {
+		return s
+	}
+	seed := time.Now().UnixNano()
+	if env := os.Getenv(SeedEnv); env != "" {
+		var err error
+		if seed, err = strconv.ParseInt(env, 10, 64); err != nil {
+			t.Fatalf("invalid %s %q: %v", SeedEnv, env, err)
+		}
+	}
+	return WithSeed(t, seed)
+}
+
+func topLevelName(t *testing.T) string {
+	name, _, _ := strings.Cut(t.Name(), "/")
+	return name
+}
+
+func (s *Seed) print(t *testing.T) {
+	fmt.Printf("e2e seed of %s: %d, replay with %s=%d\n", t.Name(), s.seed, SeedEnv, s.seed)
+	t.Logf("e2e seed: %d", s.seed)
+}
+
+// Seed returns the value of the seed.
+func (s *Seed) Seed() int64 {
+	return s.seed
+}
+
+// Int63 returns a non-negative pseudo-random number.
+func (s *Seed) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Int63()
+}
+
+// Intn returns a pseudo-random number in [0, n).
+func (s *Seed) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Intn(n)
+}
+
+// Read fills b with pseudo-random bytes.
+func (s *Seed) Read(b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rand.Read(b) //nolint:gosec
+}
+
+// Key returns a new secp256k1 key.
+func (s *Seed) Key() *ecdsa.PrivateKey {
+	b := make([]byte, 32)
+	for {
+		s.Read(b)
+		if key, err := crypto.ToECDSA(b); err == nil {
+			return key
+		}
+	}
+}
+
+// ConsensusKey returns a new BLS key.
+func (s *Seed) ConsensusKey() blst.SecretKey {
+	b := make([]byte, blst.BLSSecretKeyLength)
+	for {
+		s.Read(b)
+		// below the order of the curve
+		b[0] &= 0x3f
+		if key, err := blst.SecretKeyFromBytes(b); err == nil {
+			return key
+		}
+	}
+}
+
+// Fuzzer returns a new fuzzer. The fuzzers are not safe for concurrent use, each goroutine needs its own.
+func (s *Seed) Fuzzer() *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(s.Int63())
+}
+
+// FreePort returns a port free on localhost for both TCP and UDP. The ports are probed in sequence
+// from a port derived from the seed, skipping the ones handed out already.
+func (s *Seed) FreePort(t *testing.T) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	portsMu.Lock()
+	defer portsMu.Unlock()
+	for i := 0; i < portRange; i++ {
+		port := s.port
+		s.port = minPort + (s.port-minPort+1)%portRange
+		if _, ok := ports[port]; ok || !isPortFree(port) {
+			continue
+		}
+		ports[port] = struct{}{}
+		return port
+	}
+	t.Fatal("no free port found")
+	return 0
+}
+
+func isPortFree(port int) bool {
+	address := net.JoinHostPort(localhost, strconv.Itoa(port))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return false
+	}
+	defer listener.Close()
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,35 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/crypto"
+)
+
+func TestSeedReplaysValidatorKeys(t *testing.T) {
+	validatorKeys := func(t *testing.T, seed int64) [][]byte {
+		WithSeed(t, seed)
+		validators, err := Validators(t, 4, "10e18,v,100,0.0.0.0:%s,%s,%s,%s")
+		require.NoError(t, err)
+		var keys [][]byte
+		for _, v := range validators {
+			keys = append(keys,
+				crypto.FromECDSA(v.NodeKey),
+				v.ConsensusKey.Marshal(),
+				crypto.FromECDSA(v.OracleKey),
+				crypto.FromECDSA(v.TreasuryKey),
+			)
+		}
+		return keys
+	}
+
+	var first, replay, other [][]byte
+	t.Run("first run", func(t *testing.T) { first = validatorKeys(t, 42) })
+	t.Run("replay", func(t *testing.T) { replay = validatorKeys(t, 42) })
+	t.Run("another seed", func(t *testing.T) { other = validatorKeys(t, 43) })
+	require.Len(t, first, 16)
+	require.Equal(t, first, replay)
+	require.NotEqual(t, first, other)
+}
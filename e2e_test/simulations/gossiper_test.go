@@ -1,6 +1,7 @@
 package simulations
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"testing"
@@ -35,7 +36,7 @@ type customGossiper struct {
 
 // this is a test custom gossip function, just to illustrate how to build one
 // it gossips only to a random set of ceil(sqrt(N)). It is not optimized.
-func (cg *customGossiper) Gossip(committee types.Committee, msg message.Msg) {
+func (cg *customGossiper) Gossip(_ context.Context, committee types.Committee, msg message.Msg) {
 	hash := msg.Hash()
 	cg.knownMessages.Add(hash, true)
 
@@ -66,16 +67,16 @@ func (cg *customGossiper) Gossip(committee types.Committee, msg message.Msg) {
 
 	ps := cg.Broadcaster().FindPeers(targets)
 	for _, p := range ps {
-		if p.Cache().Contains(hash) {
+		if p.KnownMessages().Contains(hash) {
 			// This peer had this event, skip it
 			continue
 		}
-		p.Cache().Add(hash, true)
+		p.KnownMessages().Add(hash, msg.H())
 		go p.SendRaw(backend.NetworkCodes[msg.Code()], msg.Payload()) //nolint
 	}
 }
 
-func (cg *customGossiper) AskSync(_ *types.Header) {
+func (cg *customGossiper) AskSync(_ *types.Header, _ []common.Hash) {
 	// I disable the ask sync recovery mechanism, so that I can see if the gossip only is enough to keep the network live
 	log.Info("liveness lost, supposed to ask sync (but will not)")
 }
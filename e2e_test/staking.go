@@ -0,0 +1,106 @@
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/autonity/autonity/cmd/gengen/gengen"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// stakingTimeout is the time given to a staking transaction to be included.
+const stakingTimeout = 10 * time.Second
+
+// RegisterValidator registers the validator on the protocol contract through the given node. The
+// registration is sent from the validator node account, which becomes its treasury, so it must be
+// funded beforehand. The validator does not enter the committee until stake is bonded to it.
+func RegisterValidator(n *Node, validator *gengen.Validator) error {
+	e := enode.NewV4(&validator.NodeKey.PublicKey, validator.NodeIP, validator.NodePort, validator.NodePort)
+	enodeURL := enode.AppendConsensusEndpoint(validator.AcnIP.String(), strconv.Itoa(validator.AcnPort), e.String())
+	treasuryHex := crypto.PubkeyToAddress(validator.NodeKey.PublicKey).Hex()
+	pop, err := crypto.AutonityPOPProof(validator.NodeKey, validator.OracleKey, treasuryHex, validator.ConsensusKey)
+	if err != nil {
+		return fmt.Errorf("cannot generate the proof of possession: %w", err)
+	}
+	oracle := crypto.PubkeyToAddress(validator.OracleKey.PublicKey)
+	return n.AwaitRegisterValidator(validator.NodeKey, enodeURL, oracle, validator.ConsensusKey.PublicKey().Marshal(), pop, stakingTimeout)
+}
+
+// BondStake bonds the amount of NTN held by the delegator to the validator through the given node.
+// The stake is applied at the end of the current epoch.
+func BondStake(n *Node, delegator *ecdsa.PrivateKey, validator common.Address, amount *big.Int) error {
+	return n.AwaitBondStake(delegator, validator, amount, stakingTimeout)
+}
+
+// UnbondStake unbonds the amount of stake the delegator bonded to the validator through the given
+// node. The stake is removed from the validator at the end of the current epoch.
+func UnbondStake(n *Node, delegator *ecdsa.PrivateKey, validator common.Address, amount *big.Int) error {
+	return n.AwaitUnbondStake(delegator, validator, amount, stakingTimeout)
+}
+
+// QuorumCertificateSigners returns the committee members whose precommits are aggregated in the
+// quorum certificate of the block at the given height, as seen by the node.
+func QuorumCertificateSigners(n *Node, height uint64) ([]common.Address, error) {
+	chain := n.Eth.BlockChain()
+	header := chain.GetHeaderByNumber(height)
+	if header == nil || height == 0 {
+		return nil, fmt.Errorf("no certified block at height %d", height)
+	}
+	parent := chain.GetHeaderByNumber(height - 1)
+	if parent == nil {
+		return nil, fmt.Errorf("no parent block at height %d", height-1)
+	}
+	if header.QuorumCertificate.Signers == nil {
+		return nil, fmt.Errorf("no quorum certificate at height %d", height)
+	}
+	var signers []common.Address
+	for _, index := range header.QuorumCertificate.Signers.FlattenUniq() {
+		if index >= len(parent.Committee) {
+			return nil, fmt.Errorf("quorum certificate signer %d out of the committee at height %d", index, height)
+		}
+		signers = append(signers, parent.Committee[index].Address)
+	}
+	return signers, nil
+}
+
+// WaitForEpoch waits for all the running nodes in the network to reach at least the given epoch, as
+// reported by the protocol contract.
+func (nw Network) WaitForEpoch(epoch uint64, numSec int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(numSec)*time.Second)
+	defer cancel()
+	syncTicker := time.NewTicker(1 * time.Second)
+	defer syncTicker.Stop()
+	for {
+		select {
+		case <-syncTicker.C:
+			totalRunning := 0
+			syncedNodes := 0
+			for _, n := range nw {
+				// skipping nodes which are not running
+				if !n.isRunning {
+					continue
+				}
+				totalRunning++
+				epochID, err := n.Interactor.Call(nil).EpochID()
+				if err != nil {
+					continue
+				}
+				if epochID >= epoch {
+					syncedNodes++
+				}
+			}
+			// all the running nodes should reach the required epoch
+			if syncedNodes == totalRunning {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
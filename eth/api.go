@@ -29,16 +29,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/autonity/autonity/eth/autapi"
 	"github.com/autonity/autonity/log"
 
 	"github.com/autonity/autonity/autonity"
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/state"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/internal/ethapi"
+	"github.com/autonity/autonity/p2p"
 	"github.com/autonity/autonity/rlp"
 	"github.com/autonity/autonity/rpc"
 	"github.com/autonity/autonity/trie"
@@ -124,6 +127,15 @@ func (api *PrivateMinerAPI) SetExtra(extra string) (bool, error) {
 	return true, nil
 }
 
+// SetExtraData sets the metadata, e.g. a pool name, appended to the client version prefix in the extra
+// data of the blocks proposed from now on. It fails if the extra data would exceed its maximum size.
+func (api *PrivateMinerAPI) SetExtraData(metadata hexutil.Bytes) (bool, error) {
+	if err := api.e.SetExtraData(metadata); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SetGasPrice sets the minimum accepted gas price for the miner.
 func (api *PrivateMinerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
 	api.e.lock.Lock()
@@ -550,7 +562,7 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 // of the next block.
 // The (from, to) parameters are the sequence of blocks to search, which can go
 // either forwards or backwards
-func (api *PrivateDebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error) {
+func (api *PrivateDebugAPI) GetAccessibleState(ctx context.Context, from, to rpc.BlockNumber) (uint64, error) {
 	db := api.eth.ChainDb()
 	var pivot uint64
 	if p := rawdb.ReadLastPivotNumber(db); p != nil {
@@ -571,7 +583,6 @@ func (api *PrivateDebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64
 	var (
 		start   uint64
 		end     uint64
-		delta   = int64(1)
 		lastLog time.Time
 		err     error
 	)
@@ -584,28 +595,75 @@ func (api *PrivateDebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64
 	if start == end {
 		return 0, fmt.Errorf("from and to needs to be different")
 	}
+	// The headers below the pivot are skipped, there is no state for them
+	first, last := max(start, pivot), end-1
 	if start > end {
-		delta = -1
+		first, last = start, max(end+1, pivot)
+		if first < last {
+			return 0, fmt.Errorf("No state found")
+		}
+	} else if first > last {
+		return 0, fmt.Errorf("No state found")
 	}
-	for i := int64(start); i != int64(end); i += delta {
+	it := api.eth.BlockChain().HeaderRangeIterator(ctx, first, last)
+	for it.Next() {
+		h := it.Header()
 		if time.Since(lastLog) > 8*time.Second {
-			log.Info("Finding roots", "from", start, "to", end, "at", i)
+			log.Info("Finding roots", "from", start, "to", end, "at", h.Number)
 			lastLog = time.Now()
 		}
-		if i < int64(pivot) {
-			continue
-		}
-		h := api.eth.BlockChain().GetHeaderByNumber(uint64(i))
-		if h == nil {
-			return 0, fmt.Errorf("missing header %d", i)
-		}
 		if ok, _ := api.eth.ChainDb().Has(h.Root[:]); ok {
-			return uint64(i), nil
+			return h.Number.Uint64(), nil
 		}
 	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
 	return 0, fmt.Errorf("No state found")
 }
 
+// MeshHistory returns the consensus mesh memberships of the node applied in the given
+// block range, preceded for each network by the membership in force at fromBlock.
+func (api *PrivateDebugAPI) MeshHistory(fromBlock, toBlock rpc.BlockNumber) ([]*p2p.MeshMembership, error) {
+	history := api.eth.MeshHistory()
+	if history == nil {
+		return nil, errors.New("mesh history is disabled")
+	}
+	head := api.eth.BlockChain().CurrentHeader().Number.Uint64()
+	from, to := resolveBlockNumber(fromBlock, head), resolveBlockNumber(toBlock, head)
+	if from > to {
+		return nil, errInvalidBlockRange
+	}
+	return history.Entries(from, to), nil
+}
+
+// SetInternalCallTracing enables or disables the tracing of the protocol contract calls made by
+// the node itself.
+func (api *PrivateDebugAPI) SetInternalCallTracing(enabled bool) {
+	api.eth.BlockChain().InternalCallTracer().SetEnabled(enabled)
+}
+
+// InternalCallTraces returns the traces of the last protocol contract calls made by the node
+// itself, the most recent first. The calls are traced only if internal call tracing is enabled.
+func (api *PrivateDebugAPI) InternalCallTraces() []*core.InternalCallTrace {
+	return api.eth.BlockChain().InternalCallTracer().Traces()
+}
+
+// TrieCleanJournal returns the outcome of the load of the trie clean cache journal at startup, with
+// the records recovered and discarded if it was corrupted.
+func (api *PrivateDebugAPI) TrieCleanJournal() (*trie.CleanJournalStatus, error) {
+	status := api.eth.BlockChain().StateCache().TrieDB().CleanJournalStatus()
+	if status.Path == "" {
+		return nil, errors.New("trie clean cache journal is disabled")
+	}
+	return &status, nil
+}
+
+// AccusationRates returns the rate limiting state of the off-chain accountability messages of each peer.
+func (api *PrivateDebugAPI) AccusationRates() []accountability.PeerAccusationRate {
+	return api.eth.FD().AccusationRates()
+}
+
 // AutonityContractAPI implements rpc.Methods to expose view functions of the
 // autonity contract through the rpc api. Note, although it looks like this
 // struct would be better defined in the rpc package or in the autonity
@@ -623,6 +681,12 @@ type AutonityContractAPI struct {
 // able to fit into the current approach taken for registering rpc services.
 // See rpc.Server.RegisterName().
 func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.ProtocolContracts) *AutonityContractAPI {
+	return newAutonityContractAPI(&protocolState{chain: bc}, ac)
+}
+
+// newAutonityContractAPI builds the API calling the protocol contract against the given state, which may be
+// partial while the node syncs.
+func newAutonityContractAPI(protocolState *protocolState, ac *autonity.ProtocolContracts) *AutonityContractAPI {
 	var contractABI = ac.ABI()
 	var contractViewMethods = make(map[string]reflect.Value)
 
@@ -644,7 +708,7 @@ func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.ProtocolContracts)
 					makereturn := func(res interface{}, err error) []reflect.Value {
 						return []reflect.Value{reflect.ValueOf(&res).Elem(), reflect.ValueOf(&err).Elem()}
 					}
-					stateDB, err := bc.State()
+					stateDB, header, err := protocolState.Head()
 					if err != nil {
 						return makereturn(nil, err)
 					}
@@ -666,17 +730,21 @@ func NewAutonityContractAPI(bc *core.BlockChain, ac *autonity.ProtocolContracts)
 					if err != nil {
 						return makereturn(nil, err)
 					}
-					packedResult, err := ac.CallContractFunc(stateDB, bc.CurrentHeader(), packedArgs)
+					packedResult, err := ac.CallContractFunc(stateDB, header, packedArgs)
 					if err != nil {
 						return makereturn(nil, err)
 					}
+					// the call may have read accounts out of a partial state
+					if err := stateDB.Error(); err != nil {
+						return makereturn(nil, err)
+					}
 					result, err := contractABI.Unpack(functionName, packedResult)
 
 					// If the result slice contains only one element then just return the element.
 					if len(result) == 1 {
-						return makereturn(result[0], err)
+						return makereturn(autapi.ContractValue(result[0]), err)
 					}
-					return makereturn(result, err)
+					return makereturn(autapi.ContractValue(result), err)
 				})
 		}
 	}
@@ -0,0 +1,565 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/autonity/autonity/accounts/abi/bind"
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
+	tendermintcore "github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/eth/filters"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/params/generated"
+	"github.com/autonity/autonity/rpc"
+)
+
+// AccountabilityHistoryVersion is the version of the accountability history export schema.
+const AccountabilityHistoryVersion = 1
+
+// Resolutions of an exported accountability event.
+const (
+	ResolutionPending  = "pending"  // accusation still within its innocence proof window, or not promoted
+	ResolutionInnocent = "innocent" // accusation dismissed by an innocence proof
+	ResolutionGuilty   = "guilty"   // fault proven, either directly or by promotion of an accusation
+)
+
+// maxAccountabilityEvents is the maximum number of entries returned by a single aut_getAccountabilityEvents call.
+const maxAccountabilityEvents = 1024
+
+var errNoAccountabilityContract = errors.New("accountability contract not available")
+
+// accountabilityTopics are the accountability contract events relevant to the history.
+var accountabilityTopics = []common.Hash{
+	generated.AccountabilityAbi.Events["NewAccusation"].ID,
+	generated.AccountabilityAbi.Events["NewFaultProof"].ID,
+	generated.AccountabilityAbi.Events["InnocenceProven"].ID,
+	generated.AccountabilityAbi.Events["SlashingEvent"].ID,
+}
+
+// headerReader reads the canonical headers, to resolve the committee of the offence heights.
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+}
+
+// accountabilityEventReader reads the accountability events stored by the contract.
+type accountabilityEventReader interface {
+	Event(ctx context.Context, id *big.Int, head *types.Header) (*autonity.AccountabilityEvent, error)
+}
+
+// contractEventReader reads the accountability events through the contract binding.
+type contractEventReader struct {
+	contract *autonity.Accountability
+}
+
+func (r *contractEventReader) Event(ctx context.Context, id *big.Int, head *types.Header) (*autonity.AccountabilityEvent, error) {
+	event, err := r.contract.Events(&bind.CallOpts{Context: ctx, BlockNumber: head.Number}, id)
+	if err != nil {
+		return nil, err
+	}
+	result := autonity.AccountabilityEvent(event)
+	return &result, nil
+}
+
+// AccountabilityRecord is the exported history of a single accountability event.
+type AccountabilityRecord struct {
+	ID             hexutil.Uint64 `json:"id"`             // event id in the accountability contract
+	Type           string         `json:"type"`           // event type as submitted: "Fault Proof" or "Accusation"
+	Rule           string         `json:"rule"`           // accountability rule which was broken
+	Offender       common.Address `json:"offender"`       // node address of the offending validator
+	Reporter       common.Address `json:"reporter"`       // node address of the reporting validator
+	Block          hexutil.Uint64 `json:"block"`          // height at which the offence happened
+	ReportingBlock hexutil.Uint64 `json:"reportingBlock"` // block in which the event was submitted
+
+	Resolution      string          `json:"resolution"`                // one of pending, innocent or guilty
+	ResolutionBlock *hexutil.Uint64 `json:"resolutionBlock,omitempty"` // block in which the event was resolved
+
+	// Slashing outcome, only set once the offender has been slashed for this event.
+	SlashingBlock    *hexutil.Uint64 `json:"slashingBlock,omitempty"`
+	SlashedAmount    *hexutil.Big    `json:"slashedAmount,omitempty"`
+	Jailbound        bool            `json:"jailbound,omitempty"`        // the validator is jailed permanently
+	JailReleaseBlock *hexutil.Uint64 `json:"jailReleaseBlock,omitempty"` // unset when jailbound
+	JailPeriod       *hexutil.Uint64 `json:"jailPeriod,omitempty"`       // number of blocks spent in jail, unset when jailbound
+
+	// Inputs of the verdict, unset when the committee of the offence height is not available locally.
+	Audit *AccountabilityAudit `json:"audit,omitempty"`
+}
+
+// AccountabilityAudit is the committee and the parameters an accountability proof is validated with.
+// The committee is referenced by hash, it is resolved with aut_getCommitteeByHash.
+type AccountabilityAudit struct {
+	OffenceHeight    hexutil.Uint64 `json:"offenceHeight"`
+	CommitteeHash    common.Hash    `json:"committeeHash"`
+	TotalVotingPower *hexutil.Big   `json:"totalVotingPower"`
+	QuorumThreshold  *hexutil.Big   `json:"quorumThreshold"`
+	DeltaBlocks      hexutil.Uint64 `json:"deltaBlocks"`
+	RulesVersion     hexutil.Uint64 `json:"rulesVersion"`
+}
+
+func newAccountabilityAudit(snapshot accountability.AuditSnapshot) *AccountabilityAudit {
+	return &AccountabilityAudit{
+		OffenceHeight:    hexutil.Uint64(snapshot.OffenceHeight),
+		CommitteeHash:    snapshot.CommitteeHash,
+		TotalVotingPower: (*hexutil.Big)(snapshot.TotalVotingPower),
+		QuorumThreshold:  (*hexutil.Big)(snapshot.QuorumThreshold),
+		DeltaBlocks:      hexutil.Uint64(snapshot.DeltaBlocks),
+		RulesVersion:     hexutil.Uint64(snapshot.RulesVersion),
+	}
+}
+
+// AccountabilityHistoryManifest describes an accountability history export.
+type AccountabilityHistoryManifest struct {
+	Version    int            `json:"version"`
+	FromBlock  hexutil.Uint64 `json:"fromBlock"`
+	ToBlock    hexutil.Uint64 `json:"toBlock"`
+	HeadNumber hexutil.Uint64 `json:"headNumber"` // local head at export time, used to read the contract state
+	HeadHash   common.Hash    `json:"headHash"`
+	Records    int            `json:"records"`
+}
+
+// AccountabilityHistory is the content of an accountability history export file.
+type AccountabilityHistory struct {
+	Manifest *AccountabilityHistoryManifest `json:"manifest"`
+	Records  []*AccountabilityRecord        `json:"records"`
+}
+
+// ExportAccountabilityHistory writes to file the accountability events raised or resolved in
+// [fromBlock, toBlock], with their resolution and slashing outcome. The contract logs are
+// retrieved with the bloombits index where available and by bloom scanning otherwise.
+// Innocence proofs for accusations raised before fromBlock cannot be matched and are left out.
+func (api *PrivateDebugAPI) ExportAccountabilityHistory(ctx context.Context, fromBlock, toBlock rpc.BlockNumber, file string) (*AccountabilityHistoryManifest, error) {
+	contracts := api.eth.blockchain.ProtocolContracts()
+	if contracts == nil || contracts.Accountability == nil {
+		return nil, errNoAccountabilityContract
+	}
+	return exportAccountabilityHistory(ctx, api.eth.APIBackend, &contractEventReader{contracts.Accountability}, fromBlock, toBlock, file)
+}
+
+func exportAccountabilityHistory(ctx context.Context, backend filters.Backend, events accountabilityEventReader, fromBlock, toBlock rpc.BlockNumber, file string) (*AccountabilityHistoryManifest, error) {
+	if _, err := os.Stat(file); err == nil {
+		// same as for the chain export, do not allow to overwrite arbitrary files
+		return nil, errors.New("location would overwrite an existing file")
+	}
+	head, err := backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	from, to := resolveBlockNumber(fromBlock, head.Number.Uint64()), resolveBlockNumber(toBlock, head.Number.Uint64())
+	if from > to {
+		return nil, errInvalidBlockRange
+	}
+	if to > head.Number.Uint64() {
+		return nil, fmt.Errorf("block #%d not found", to)
+	}
+
+	filter := filters.NewRangeFilter(backend, int64(from), int64(to), []common.Address{params.AccountabilityContractAddress}, [][]common.Hash{accountabilityTopics})
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := newAccountabilityHistoryBuilder(events, backend, head).build(ctx, logs)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &AccountabilityHistory{
+		Manifest: &AccountabilityHistoryManifest{
+			Version:    AccountabilityHistoryVersion,
+			FromBlock:  hexutil.Uint64(from),
+			ToBlock:    hexutil.Uint64(to),
+			HeadNumber: hexutil.Uint64(head.Number.Uint64()),
+			HeadHash:   head.Hash(),
+			Records:    len(records),
+		},
+		Records: records,
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(history); err != nil {
+		return nil, err
+	}
+	return history.Manifest, nil
+}
+
+// accountabilityHistoryBuilder replays the accountability contract logs in chain order.
+type accountabilityHistoryBuilder struct {
+	events   accountabilityEventReader
+	headers  headerReader
+	head     *types.Header
+	filterer *autonity.AccountabilityFilterer
+
+	records map[uint64]*AccountabilityRecord
+	// pending accusation of each offender, the contract allows only one at a time
+	accusations map[common.Address]uint64
+}
+
+func newAccountabilityHistoryBuilder(events accountabilityEventReader, headers headerReader, head *types.Header) *accountabilityHistoryBuilder {
+	// the filterer is only used to decode logs, it does not need a backend
+	filterer, _ := autonity.NewAccountabilityFilterer(params.AccountabilityContractAddress, nil)
+	return &accountabilityHistoryBuilder{
+		events:      events,
+		headers:     headers,
+		head:        head,
+		filterer:    filterer,
+		records:     make(map[uint64]*AccountabilityRecord),
+		accusations: make(map[common.Address]uint64),
+	}
+}
+
+func (b *accountabilityHistoryBuilder) build(ctx context.Context, logs []*types.Log) ([]*AccountabilityRecord, error) {
+	for _, log := range logs {
+		if err := b.apply(ctx, log); err != nil {
+			return nil, fmt.Errorf("block #%d log %d: %w", log.BlockNumber, log.Index, err)
+		}
+	}
+	records := make([]*AccountabilityRecord, 0, len(b.records))
+	for _, record := range b.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ID < records[j].ID
+	})
+	return records, nil
+}
+
+func (b *accountabilityHistoryBuilder) apply(ctx context.Context, log *types.Log) error {
+	if log.Removed || len(log.Topics) == 0 {
+		return nil
+	}
+	block := hexutil.Uint64(log.BlockNumber)
+	switch log.Topics[0] {
+	case accountabilityTopics[0]:
+		ev, err := b.filterer.ParseNewAccusation(*log)
+		if err != nil {
+			return err
+		}
+		if _, err := b.record(ctx, ev.Id); err != nil {
+			return err
+		}
+		b.accusations[ev.Offender] = ev.Id.Uint64()
+	case accountabilityTopics[1]:
+		ev, err := b.filterer.ParseNewFaultProof(*log)
+		if err != nil {
+			return err
+		}
+		record, err := b.record(ctx, ev.Id)
+		if err != nil {
+			return err
+		}
+		record.Resolution, record.ResolutionBlock = ResolutionGuilty, &block
+		if id, ok := b.accusations[ev.Offender]; ok && id == ev.Id.Uint64() {
+			delete(b.accusations, ev.Offender)
+		}
+	case accountabilityTopics[2]:
+		// the contract does not emit the accusation id, it is the pending accusation of the offender
+		ev, err := b.filterer.ParseInnocenceProven(*log)
+		if err != nil {
+			return err
+		}
+		id, ok := b.accusations[ev.Offender]
+		if !ok {
+			return nil
+		}
+		delete(b.accusations, ev.Offender)
+		record := b.records[id]
+		record.Resolution, record.ResolutionBlock = ResolutionInnocent, &block
+	case accountabilityTopics[3]:
+		ev, err := b.filterer.ParseSlashingEvent(*log)
+		if err != nil {
+			return err
+		}
+		record, err := b.record(ctx, ev.EventId)
+		if err != nil {
+			return err
+		}
+		if record.Resolution == ResolutionPending {
+			// only proven faults are slashed, the proof was submitted before the exported range
+			record.Resolution = ResolutionGuilty
+		}
+		record.SlashingBlock = &block
+		record.SlashedAmount = (*hexutil.Big)(ev.Amount)
+		record.Jailbound = ev.IsJailbound
+		if !ev.IsJailbound {
+			release := hexutil.Uint64(ev.ReleaseBlock.Uint64())
+			period := release - block
+			record.JailReleaseBlock, record.JailPeriod = &release, &period
+		}
+	}
+	return nil
+}
+
+// record returns the record of the event with the given id, reading it from the contract state on first use.
+func (b *accountabilityHistoryBuilder) record(ctx context.Context, id *big.Int) (*AccountabilityRecord, error) {
+	if record, ok := b.records[id.Uint64()]; ok {
+		return record, nil
+	}
+	event, err := b.events.Event(ctx, id, b.head)
+	if err != nil {
+		return nil, fmt.Errorf("event %d: %w", id, err)
+	}
+	record := &AccountabilityRecord{
+		ID:             hexutil.Uint64(id.Uint64()),
+		Type:           autonity.AccountabilityEventType(event.EventType).String(),
+		Rule:           autonity.Rule(event.Rule).String(),
+		Offender:       event.Offender,
+		Reporter:       event.Reporter,
+		Block:          hexutil.Uint64(event.Block.Uint64()),
+		ReportingBlock: hexutil.Uint64(event.ReportingBlock.Uint64()),
+		Resolution:     ResolutionPending,
+	}
+	if height := event.Block.Uint64(); height > 0 {
+		// the committee of the offence height is the one of its parent header
+		parent, err := b.headers.HeaderByNumber(ctx, rpc.BlockNumber(height-1))
+		if err != nil {
+			return nil, err
+		}
+		if parent != nil && len(parent.Committee) > 0 {
+			record.Audit = newAccountabilityAudit(accountability.NewAuditSnapshot(height, parent.Committee))
+		}
+	}
+	b.records[id.Uint64()] = record
+	return record, nil
+}
+
+// AccountabilityJournalEntry is a proof handled by the local fault detector.
+type AccountabilityJournalEntry struct {
+	Seq       hexutil.Uint64       `json:"seq"`
+	Timestamp autapi.Timestamp     `json:"timestamp"`
+	Action    string               `json:"action"`
+	Type      string               `json:"type"`
+	Rule      string               `json:"rule"`
+	Offender  autapi.Address       `json:"offender"`
+	ProofHash common.Hash          `json:"proofHash"`
+	Audit     *AccountabilityAudit `json:"audit"`
+}
+
+// AccountabilitySubmission is an accountability event sent by the local node and pending inclusion.
+type AccountabilitySubmission struct {
+	Type      string               `json:"type"`
+	Rule      string               `json:"rule"`
+	Offender  autapi.Address       `json:"offender"`
+	Chunk     hexutil.Uint64       `json:"chunk"`
+	Chunks    hexutil.Uint64       `json:"chunks"`
+	Deadline  hexutil.Uint64       `json:"deadline"`
+	TxHash    common.Hash          `json:"txHash"` // transaction currently pending
+	ProofHash common.Hash          `json:"proofHash"`
+	Audit     *AccountabilityAudit `json:"audit"` // null for the submissions recorded by older versions
+}
+
+// AccountabilityEventsPage is the result of aut_getAccountabilityEvents.
+type AccountabilityEventsPage struct {
+	Entries []*AccountabilityJournalEntry `json:"entries"`
+	// FirstSeq is the sequence number of the oldest entry kept, the entries before it were pruned.
+	FirstSeq hexutil.Uint64 `json:"firstSeq"`
+	// NextSeq is the sequence number of the next entry to be journaled.
+	NextSeq hexutil.Uint64 `json:"nextSeq"`
+	// Pending are the accountability events sent by the local node and not yet included.
+	Pending []*AccountabilitySubmission `json:"pending"`
+}
+
+// FaultDetectorStatus is the result of aut_getFaultDetectorStatus.
+type FaultDetectorStatus struct {
+	// MsgStoreMode is "full", or "digest" if only the digests of the consensus messages are kept, in
+	// which case the fault detector builds no proof.
+	MsgStoreMode string         `json:"msgStoreMode"`
+	FirstHeight  hexutil.Uint64 `json:"firstHeight"` // first height buffered since the node started
+	Heights      hexutil.Uint64 `json:"heights"`     // number of heights buffered
+	Messages     hexutil.Uint64 `json:"messages"`    // number of full messages buffered
+	Digests      hexutil.Uint64 `json:"digests"`     // number of message digests by signer buffered
+	Pending      hexutil.Uint64 `json:"pending"`     // number of accountability events pending inclusion
+	// IncrementalRescans is the number of re-evaluations of a height for the signer of late messages.
+	IncrementalRescans hexutil.Uint64 `json:"incrementalRescans"`
+	DroppedStale       hexutil.Uint64 `json:"droppedStale"`  // late messages dropped outside the window
+	CappedRescans      hexutil.Uint64 `json:"cappedRescans"` // re-evaluations skipped by the cap per height
+	// DroppedOverQuota is the number of consensus messages dropped over the quota of their signers.
+	DroppedOverQuota hexutil.Uint64 `json:"droppedOverQuota"`
+}
+
+// MessageArrivals is the result of aut_getMessageArrivals: the first arrival of the proposal, prevotes
+// and precommits of a height per committee member, as offsets in milliseconds from the start of the
+// height, nil if none arrived.
+type MessageArrivals struct {
+	Start     autapi.Timestamp  `json:"start"` // first arrival of the height
+	Proposal  []*hexutil.Uint64 `json:"proposal"`
+	Prevote   []*hexutil.Uint64 `json:"prevote"`
+	Precommit []*hexutil.Uint64 `json:"precommit"`
+}
+
+// accountabilityJournal is the view of the fault detector served by the accountability API.
+type accountabilityJournal interface {
+	AccountabilityEvents(afterSeq uint64, limit int) (entries []*accountability.JournalEntry, first, next uint64)
+	PendingSubmissions() []accountability.PendingSubmission
+	CommitteeByHash(hash common.Hash) (types.Committee, error)
+	MsgStoreStats() tendermintcore.MsgStoreStats
+	LateMessageStats() accountability.LateMessageStats
+	Arrivals(height uint64) (accountability.HeightArrivals, bool)
+}
+
+// PublicAccountabilityAPI serves the accountability proofs handled by the local fault detector under
+// the aut namespace. The journal is kept in memory, it starts empty on restart.
+type PublicAccountabilityAPI struct {
+	journal accountabilityJournal
+}
+
+// NewPublicAccountabilityAPI creates a new accountability API instance.
+func NewPublicAccountabilityAPI(journal accountabilityJournal) *PublicAccountabilityAPI {
+	return &PublicAccountabilityAPI{journal: journal}
+}
+
+// GetAccountabilityEvents returns up to limit journal entries following afterSeq, 1024 at most, along
+// with the accountability events pending inclusion. Each entry records the committee and the
+// parameters the proof was handled with.
+func (api *PublicAccountabilityAPI) GetAccountabilityEvents(afterSeq hexutil.Uint64, limit *hexutil.Uint64) *AccountabilityEventsPage {
+	n := maxAccountabilityEvents
+	if limit != nil && uint64(*limit) < maxAccountabilityEvents {
+		n = int(*limit)
+	}
+	entries, first, next := api.journal.AccountabilityEvents(uint64(afterSeq), n)
+	page := &AccountabilityEventsPage{
+		Entries:  make([]*AccountabilityJournalEntry, 0, len(entries)),
+		FirstSeq: hexutil.Uint64(first),
+		NextSeq:  hexutil.Uint64(next),
+		Pending:  []*AccountabilitySubmission{},
+	}
+	for _, entry := range entries {
+		page.Entries = append(page.Entries, &AccountabilityJournalEntry{
+			Seq:       hexutil.Uint64(entry.Seq),
+			Timestamp: autapi.Timestamp(entry.Time),
+			Action:    string(entry.Action),
+			Type:      entry.Type.String(),
+			Rule:      entry.Rule.String(),
+			Offender:  autapi.Address(entry.Offender),
+			ProofHash: entry.ProofHash,
+			Audit:     newAccountabilityAudit(entry.Audit),
+		})
+	}
+	for _, s := range api.journal.PendingSubmissions() {
+		submission := &AccountabilitySubmission{
+			Type:      autonity.AccountabilityEventType(s.Event.EventType).String(),
+			Rule:      autonity.Rule(s.Event.Rule).String(),
+			Offender:  autapi.Address(s.Event.Offender),
+			Chunk:     hexutil.Uint64(s.Event.ChunkId),
+			Chunks:    hexutil.Uint64(s.Event.Chunks),
+			Deadline:  hexutil.Uint64(s.Deadline),
+			ProofHash: s.ProofHash,
+		}
+		if len(s.Hashes) > 0 {
+			submission.TxHash = s.Hashes[len(s.Hashes)-1]
+		}
+		if s.Audit != nil {
+			submission.Audit = newAccountabilityAudit(*s.Audit)
+		}
+		page.Pending = append(page.Pending, submission)
+	}
+	return page
+}
+
+// GetCommitteeByHash returns the committee referenced by the audit of the accountability events.
+func (api *PublicAccountabilityAPI) GetCommitteeByHash(hash common.Hash) ([]*autapi.CommitteeMember, error) {
+	committee, err := api.journal.CommitteeByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return autapi.NewCommittee(committee), nil
+}
+
+// GetFaultDetectorStatus returns the state of the local fault detector.
+func (api *PublicAccountabilityAPI) GetFaultDetectorStatus() *FaultDetectorStatus {
+	stats := api.journal.MsgStoreStats()
+	late := api.journal.LateMessageStats()
+	return &FaultDetectorStatus{
+		MsgStoreMode:       stats.Mode.String(),
+		FirstHeight:        hexutil.Uint64(stats.FirstHeight),
+		Heights:            hexutil.Uint64(stats.Heights),
+		Messages:           hexutil.Uint64(stats.Messages),
+		Digests:            hexutil.Uint64(stats.Digests),
+		Pending:            hexutil.Uint64(len(api.journal.PendingSubmissions())),
+		IncrementalRescans: hexutil.Uint64(late.IncrementalRescans),
+		DroppedStale:       hexutil.Uint64(late.DroppedStale),
+		CappedRescans:      hexutil.Uint64(late.CappedRescans),
+		DroppedOverQuota:   hexutil.Uint64(stats.Dropped),
+	}
+}
+
+// GetMessageArrivals returns the first arrival of the consensus messages of the height per committee
+// member, or nil if the height is unknown or outside the accountability window.
+func (api *PublicAccountabilityAPI) GetMessageArrivals(height hexutil.Uint64) *MessageArrivals {
+	arrivals, ok := api.journal.Arrivals(uint64(height))
+	if !ok {
+		return nil
+	}
+	first := func(code uint8) []*hexutil.Uint64 {
+		offsets := make([]*hexutil.Uint64, arrivals.CommitteeSize())
+		for i := range offsets {
+			if offset, ok := arrivals.First(i, code); ok {
+				ms := hexutil.Uint64(offset.Milliseconds())
+				offsets[i] = &ms
+			}
+		}
+		return offsets
+	}
+	return &MessageArrivals{
+		Start:     autapi.Timestamp(arrivals.Start()),
+		Proposal:  first(message.ProposalCode),
+		Prevote:   first(message.PrevoteCode),
+		Precommit: first(message.PrecommitCode),
+	}
+}
+
+// AccountabilitySubmissionCap is the number of accountability events the local node submits per
+// height and per epoch, 0 for no cap.
+type AccountabilitySubmissionCap struct {
+	PerHeight hexutil.Uint64 `json:"perHeight"`
+	PerEpoch  hexutil.Uint64 `json:"perEpoch"`
+	Queued    hexutil.Uint64 `json:"queued"` // number of events queued beyond the cap
+}
+
+// accountabilitySubmissions is the submission cap of the fault detector.
+type accountabilitySubmissions interface {
+	SetSubmissionCap(perHeight, perEpoch uint64)
+	SubmissionCap() (perHeight, perEpoch uint64, queued int)
+}
+
+// PrivateAccountabilityAPI controls the accountability event submissions of the local fault detector
+// under the aut namespace.
+type PrivateAccountabilityAPI struct {
+	submissions accountabilitySubmissions
+}
+
+// NewPrivateAccountabilityAPI creates a new private accountability API instance.
+func NewPrivateAccountabilityAPI(submissions accountabilitySubmissions) *PrivateAccountabilityAPI {
+	return &PrivateAccountabilityAPI{submissions: submissions}
+}
+
+// AccountabilitySubmissionCap returns the submission cap of the accountability events.
+func (api *PrivateAccountabilityAPI) AccountabilitySubmissionCap() *AccountabilitySubmissionCap {
+	perHeight, perEpoch, queued := api.submissions.SubmissionCap()
+	return &AccountabilitySubmissionCap{
+		PerHeight: hexutil.Uint64(perHeight),
+		PerEpoch:  hexutil.Uint64(perEpoch),
+		Queued:    hexutil.Uint64(queued),
+	}
+}
+
+// SetAccountabilitySubmissionCap overrides the submission cap of the accountability events until the
+// node restarts, e.g. to raise it during an incident. The events queued beyond the previous cap are
+// released from the next block on.
+func (api *PrivateAccountabilityAPI) SetAccountabilitySubmissionCap(perHeight, perEpoch hexutil.Uint64) *AccountabilitySubmissionCap {
+	api.submissions.SetSubmissionCap(uint64(perHeight), uint64(perEpoch))
+	return api.AccountabilitySubmissionCap()
+}
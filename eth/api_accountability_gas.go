@@ -0,0 +1,125 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/rpc"
+)
+
+// maxAccountabilityGasRange is the maximum number of blocks covered by a single aut_accountabilityGasStats call.
+const maxAccountabilityGasRange = 1024
+
+var (
+	errAccountabilityGasNotIndexed = errors.New("accountability gas statistics not indexed")
+	errAccountabilityGasRange      = fmt.Errorf("block range too large, maximum is %d blocks", maxAccountabilityGasRange)
+)
+
+// accountabilityGasIndex reads the accountability gas statistics indexed by the chain.
+type accountabilityGasIndex interface {
+	CurrentHeader() *types.Header
+	GetCanonicalHash(number uint64) common.Hash
+	AccountabilityGasStart() uint64
+	AccountabilityGasStats(hash common.Hash, number uint64) *types.AccountabilityGasStats
+}
+
+// PrecompileGasStats accounts for the runs of an accountability precompiled contract.
+type PrecompileGasStats struct {
+	Calls    hexutil.Uint64 `json:"calls"`
+	Failures hexutil.Uint64 `json:"failures"` // runs which failed, or whose proof is not valid
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+}
+
+// AccountabilityGasStats accounts for the runs of the accountability precompiled contracts.
+type AccountabilityGasStats struct {
+	Accusation   PrecompileGasStats `json:"accusation"`
+	Innocence    PrecompileGasStats `json:"innocence"`
+	Misbehaviour PrecompileGasStats `json:"misbehaviour"`
+}
+
+// AccountabilityGasBlock is the accountability gas statistics of a block.
+type AccountabilityGasBlock struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	AccountabilityGasStats
+}
+
+// AccountabilityGasRange is the result of aut_accountabilityGasStats.
+type AccountabilityGasRange struct {
+	// IndexedFrom is the first block indexed, the statistics are not available for the blocks before it.
+	IndexedFrom hexutil.Uint64 `json:"indexedFrom"`
+	// Blocks are the blocks of the range with at least one run, in ascending order.
+	Blocks []*AccountabilityGasBlock `json:"blocks"`
+	Total  AccountabilityGasStats    `json:"total"`
+}
+
+func newPrecompileGasStats(stats types.PrecompileStats) PrecompileGasStats {
+	return PrecompileGasStats{
+		Calls:    hexutil.Uint64(stats.Calls),
+		Failures: hexutil.Uint64(stats.Failures),
+		GasUsed:  hexutil.Uint64(stats.GasUsed),
+	}
+}
+
+func newAccountabilityGasStats(stats *types.AccountabilityGasStats) AccountabilityGasStats {
+	return AccountabilityGasStats{
+		Accusation:   newPrecompileGasStats(stats.Accusation),
+		Innocence:    newPrecompileGasStats(stats.Innocence),
+		Misbehaviour: newPrecompileGasStats(stats.Misbehaviour),
+	}
+}
+
+// PublicAccountabilityGasAPI serves the usage of the accountability precompiled contracts under the aut namespace.
+type PublicAccountabilityGasAPI struct {
+	index accountabilityGasIndex
+}
+
+// NewPublicAccountabilityGasAPI creates a new accountability gas API instance.
+func NewPublicAccountabilityGasAPI(index accountabilityGasIndex) *PublicAccountabilityGasAPI {
+	return &PublicAccountabilityGasAPI{index: index}
+}
+
+// AccountabilityGasStats returns the gas used, the calls and the failures of the accountability precompiled
+// contracts for each canonical block of [fromBlock, toBlock], 1024 blocks at most, along with their total. The
+// statistics are indexed as the blocks are executed, from the first block executed by the node.
+func (api *PublicAccountabilityGasAPI) AccountabilityGasStats(fromBlock, toBlock rpc.BlockNumber) (*AccountabilityGasRange, error) {
+	head := api.index.CurrentHeader().Number.Uint64()
+	from, to := resolveBlockNumber(fromBlock, head), resolveBlockNumber(toBlock, head)
+	if from > to {
+		return nil, errInvalidBlockRange
+	}
+	if to > head {
+		return nil, fmt.Errorf("block #%d not found", to)
+	}
+	if to-from+1 > maxAccountabilityGasRange {
+		return nil, errAccountabilityGasRange
+	}
+	start := api.index.AccountabilityGasStart()
+	if start == 0 {
+		return nil, errAccountabilityGasNotIndexed
+	}
+	if from < start {
+		return nil, fmt.Errorf("%w before block %d", errAccountabilityGasNotIndexed, start)
+	}
+
+	result := &AccountabilityGasRange{IndexedFrom: hexutil.Uint64(start), Blocks: []*AccountabilityGasBlock{}}
+	total := new(types.AccountabilityGasStats)
+	for number := from; number <= to; number++ {
+		hash := api.index.GetCanonicalHash(number)
+		stats := api.index.AccountabilityGasStats(hash, number)
+		if stats.Empty() {
+			continue
+		}
+		total.Merge(stats)
+		result.Blocks = append(result.Blocks, &AccountabilityGasBlock{
+			Number:                 hexutil.Uint64(number),
+			Hash:                   hash,
+			AccountabilityGasStats: newAccountabilityGasStats(stats),
+		})
+	}
+	result.Total = newAccountabilityGasStats(total)
+	return result, nil
+}
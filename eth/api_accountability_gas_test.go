@@ -0,0 +1,151 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/accounts/abi/bind/backends"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+)
+
+// fakeProofVerifier stands for an accountability precompiled contract, the proofs starting with 1 are valid.
+type fakeProofVerifier struct {
+	address common.Address
+}
+
+func (v *fakeProofVerifier) RequiredGas(input []byte) uint64 {
+	return 1000 * uint64(len(input))
+}
+
+func (v *fakeProofVerifier) Run(input []byte, _ uint64, _ *vm.EVM, _ common.Address) ([]byte, error) {
+	if len(input) > 0 && input[0] == 1 {
+		return common.LeftPadBytes([]byte{1}, 32), nil
+	}
+	return make([]byte, 32), nil
+}
+
+func (v *fakeProofVerifier) Address() common.Address {
+	return v.address
+}
+
+func (v *fakeProofVerifier) Succeeded(output []byte) bool {
+	return len(output) == 32 && output[31] == 1
+}
+
+func TestAccountabilityGasStatsAPI(t *testing.T) {
+	vm.PrecompiledContractRWMutex.Lock()
+	for _, addr := range []common.Address{params.CheckAccusationAddress, params.CheckInnocenceAddress, params.CheckMisbehaviourAddress} {
+		addr := addr
+		previous, ok := vm.PrecompiledContractsBerlin[addr]
+		vm.PrecompiledContractsBerlin[addr] = &fakeProofVerifier{address: addr}
+		t.Cleanup(func() {
+			vm.PrecompiledContractRWMutex.Lock()
+			defer vm.PrecompiledContractRWMutex.Unlock()
+			if ok {
+				vm.PrecompiledContractsBerlin[addr] = previous
+			} else {
+				delete(vm.PrecompiledContractsBerlin, addr)
+			}
+		})
+	}
+	vm.PrecompiledContractRWMutex.Unlock()
+
+	key, _ := crypto.GenerateKey()
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(params.Ether)}},
+	}
+	signer := types.LatestSigner(gspec.Config)
+	nonce := uint64(0)
+	proofTx := func(b *core.BlockGen, to common.Address, proof []byte, gas uint64) {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, To: &to, Gas: gas, GasPrice: b.BaseFee(), Data: proof})
+		require.NoError(t, err)
+		b.AddTx(tx)
+		nonce++
+	}
+	intrinsicGas := func(proof []byte) uint64 {
+		gas, err := core.IntrinsicGas(proof, nil, false, true, true)
+		require.NoError(t, err)
+		return gas
+	}
+	validProof, invalidProof := []byte{1, 2}, []byte{0, 2, 3}
+
+	// block 1 verifies an accusation and an innocence proof, and rejects an accusation. Block 2 verifies
+	// nothing. Block 3 runs out of gas verifying a misbehaviour proof, and verifies a misbehaviour proof.
+	genDB := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(genDB)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), genDB, 3, func(i int, b *core.BlockGen) {
+		switch i {
+		case 0:
+			proofTx(b, params.CheckAccusationAddress, validProof, 100_000)
+			proofTx(b, params.CheckAccusationAddress, invalidProof, 100_000)
+			proofTx(b, params.CheckInnocenceAddress, validProof, 100_000)
+		case 2:
+			proofTx(b, params.CheckMisbehaviourAddress, validProof, intrinsicGas(validProof)+500)
+			proofTx(b, params.CheckMisbehaviourAddress, validProof, 100_000)
+		}
+	})
+
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, &core.TxSenderCacher{}, nil, backends.NewInternalBackend(nil), log.Root())
+	require.NoError(t, err)
+	defer chain.Stop()
+	api := NewPublicAccountabilityGasAPI(chain)
+
+	_, err = api.AccountabilityGasStats(0, rpc.LatestBlockNumber)
+	require.ErrorIs(t, err, errAccountabilityGasNotIndexed)
+
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), chain.AccountabilityGasStart())
+
+	// per block statistics
+	require.Equal(t, &types.AccountabilityGasStats{
+		Accusation: types.PrecompileStats{Calls: 2, Failures: 1, GasUsed: 5000},
+		Innocence:  types.PrecompileStats{Calls: 1, GasUsed: 2000},
+	}, chain.AccountabilityGasStats(blocks[0].Hash(), 1))
+	require.True(t, chain.AccountabilityGasStats(blocks[1].Hash(), 2).Empty())
+	require.Equal(t, &types.AccountabilityGasStats{
+		Misbehaviour: types.PrecompileStats{Calls: 2, Failures: 1, GasUsed: 2500},
+	}, chain.AccountabilityGasStats(blocks[2].Hash(), 3))
+
+	// range aggregation
+	result, err := api.AccountabilityGasStats(1, rpc.LatestBlockNumber)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(1), result.IndexedFrom)
+	require.Len(t, result.Blocks, 2)
+	require.Equal(t, hexutil.Uint64(1), result.Blocks[0].Number)
+	require.Equal(t, blocks[0].Hash(), result.Blocks[0].Hash)
+	require.Equal(t, hexutil.Uint64(3), result.Blocks[1].Number)
+	require.Equal(t, AccountabilityGasStats{
+		Accusation:   PrecompileGasStats{Calls: 2, Failures: 1, GasUsed: 5000},
+		Innocence:    PrecompileGasStats{Calls: 1, GasUsed: 2000},
+		Misbehaviour: PrecompileGasStats{Calls: 2, Failures: 1, GasUsed: 2500},
+	}, result.Total)
+
+	result, err = api.AccountabilityGasStats(2, 2)
+	require.NoError(t, err)
+	require.Empty(t, result.Blocks)
+	require.Equal(t, AccountabilityGasStats{}, result.Total)
+
+	// range bounds
+	_, err = api.AccountabilityGasStats(0, 3)
+	require.ErrorIs(t, err, errAccountabilityGasNotIndexed)
+	_, err = api.AccountabilityGasStats(3, 1)
+	require.ErrorIs(t, err, errInvalidBlockRange)
+	_, err = api.AccountabilityGasStats(1, 4)
+	require.Error(t, err)
+}
@@ -0,0 +1,435 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
+	"github.com/autonity/autonity/consensus/tendermint/bft"
+	tendermintcore "github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/bloombits"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/params/generated"
+	"github.com/autonity/autonity/rpc"
+)
+
+// logChainBackend serves an in-memory chain of headers and logs to the log filters.
+// No bloombits section is indexed, the filters fall back to bloom scanning.
+type logChainBackend struct {
+	db       ethdb.Database
+	headers  []*types.Header
+	head     int // number of the latest header
+	logs     map[common.Hash][]*types.Log
+	logsFeed event.Feed
+}
+
+func (b *logChainBackend) ChainDb() ethdb.Database { return b.db }
+
+func (b *logChainBackend) HeaderByNumber(_ context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.headers[b.head], nil
+	}
+	if int(number) >= len(b.headers) {
+		return nil, nil
+	}
+	return b.headers[number], nil
+}
+
+func (b *logChainBackend) HeaderByHash(_ context.Context, hash common.Hash) (*types.Header, error) {
+	for _, header := range b.headers {
+		if header.Hash() == hash {
+			return header, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *logChainBackend) GetReceipts(_ context.Context, hash common.Hash) (types.Receipts, error) {
+	return types.Receipts{{Logs: b.logs[hash]}}, nil
+}
+
+func (b *logChainBackend) GetLogs(_ context.Context, hash common.Hash) ([][]*types.Log, error) {
+	return [][]*types.Log{b.logs[hash]}, nil
+}
+
+func (b *logChainBackend) SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription {
+	return nil
+}
+
+func (b *logChainBackend) SubscribeChainEvent(chan<- core.ChainEvent) event.Subscription {
+	return nil
+}
+
+func (b *logChainBackend) SubscribeRemovedLogsEvent(chan<- core.RemovedLogsEvent) event.Subscription {
+	return nil
+}
+
+func (b *logChainBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return b.logsFeed.Subscribe(ch)
+}
+
+func (b *logChainBackend) SubscribePendingLogsEvent(chan<- []*types.Log) event.Subscription {
+	return nil
+}
+
+func (b *logChainBackend) BloomStatus() (uint64, uint64) {
+	return params.BloomBitsBlocks, 0
+}
+
+func (b *logChainBackend) ServiceFilter(context.Context, *bloombits.MatcherSession) {}
+
+// newLogChainBackend creates a chain of n+1 headers whose blooms cover the given logs.
+func newLogChainBackend(n uint64, logs map[uint64][]*types.Log) *logChainBackend {
+	backend := &logChainBackend{db: rawdb.NewMemoryDatabase(), head: int(n), logs: make(map[common.Hash][]*types.Log)}
+	for i := uint64(0); i <= n; i++ {
+		header := &types.Header{
+			Number:     new(big.Int).SetUint64(i),
+			Difficulty: big.NewInt(1),
+			Bloom:      types.CreateBloom(types.Receipts{{Logs: logs[i]}}),
+		}
+		if i > 0 {
+			header.ParentHash = backend.headers[i-1].Hash()
+		}
+		for j, log := range logs[i] {
+			log.BlockNumber, log.BlockHash, log.Index = i, header.Hash(), uint(j)
+		}
+		backend.headers = append(backend.headers, header)
+		backend.logs[header.Hash()] = logs[i]
+	}
+	return backend
+}
+
+// accountabilityLog packs an accountability contract event the same way the EVM does.
+func accountabilityLog(t *testing.T, name string, offender *common.Address, args ...interface{}) *types.Log {
+	event := generated.AccountabilityAbi.Events[name]
+	data, err := event.Inputs.NonIndexed().Pack(args...)
+	require.NoError(t, err)
+	topics := []common.Hash{event.ID}
+	if offender != nil {
+		topics = append(topics, common.BytesToHash(offender.Bytes()))
+	}
+	return &types.Log{Address: params.AccountabilityContractAddress, Topics: topics, Data: data}
+}
+
+// contractEvents is the accountability events storage of the contract.
+type contractEvents map[uint64]*autonity.AccountabilityEvent
+
+func (e contractEvents) Event(_ context.Context, id *big.Int, _ *types.Header) (*autonity.AccountabilityEvent, error) {
+	event, ok := e[id.Uint64()]
+	if !ok {
+		return nil, errors.New("unknown event")
+	}
+	return event, nil
+}
+
+func readAccountabilityHistory(t *testing.T, file string) *AccountabilityHistory {
+	raw, err := os.ReadFile(file)
+	require.NoError(t, err)
+	history := new(AccountabilityHistory)
+	require.NoError(t, json.Unmarshal(raw, history))
+	return history
+}
+
+func TestExportAccountabilityHistory(t *testing.T) {
+	var (
+		reporter  = common.HexToAddress("0x01")
+		innocent  = common.HexToAddress("0xa1")
+		faulty    = common.HexToAddress("0xa2")
+		convicted = common.HexToAddress("0xa3")
+		severity  = big.NewInt(2)
+	)
+	events := contractEvents{
+		0: {EventType: uint8(autonity.Accusation), Rule: uint8(autonity.PVN), Reporter: reporter, Offender: innocent, Block: big.NewInt(1), ReportingBlock: big.NewInt(2)},
+		1: {EventType: uint8(autonity.Misbehaviour), Rule: uint8(autonity.Equivocation), Reporter: reporter, Offender: faulty, Block: big.NewInt(2), ReportingBlock: big.NewInt(3)},
+		2: {EventType: uint8(autonity.Accusation), Rule: uint8(autonity.C1), Reporter: reporter, Offender: convicted, Block: big.NewInt(4), ReportingBlock: big.NewInt(6)},
+		3: {EventType: uint8(autonity.Accusation), Rule: uint8(autonity.PO), Reporter: reporter, Offender: innocent, Block: big.NewInt(10), ReportingBlock: big.NewInt(11)},
+	}
+	// same topic emitted by another contract, it must be filtered out
+	foreign := accountabilityLog(t, "NewAccusation", &faulty, severity, big.NewInt(7))
+	foreign.Address = common.HexToAddress("0xdead")
+
+	backend := newLogChainBackend(12, map[uint64][]*types.Log{
+		2: {accountabilityLog(t, "NewAccusation", &innocent, severity, big.NewInt(0))},
+		3: {accountabilityLog(t, "NewFaultProof", &faulty, severity, big.NewInt(1))},
+		4: {foreign},
+		5: {accountabilityLog(t, "InnocenceProven", &innocent, big.NewInt(0))},
+		6: {accountabilityLog(t, "NewAccusation", &convicted, severity, big.NewInt(2))},
+		// the accusation is promoted after the innocence proof window
+		8: {accountabilityLog(t, "NewFaultProof", &convicted, severity, big.NewInt(2))},
+		// slashing at epoch end
+		10: {
+			accountabilityLog(t, "SlashingEvent", nil, faulty, big.NewInt(1000), big.NewInt(40), false, big.NewInt(1)),
+			accountabilityLog(t, "SlashingEvent", nil, convicted, big.NewInt(5000), big.NewInt(0), true, big.NewInt(2)),
+		},
+		11: {accountabilityLog(t, "NewAccusation", &innocent, severity, big.NewInt(3))},
+	})
+	head := backend.headers[12]
+	u64 := func(n uint64) *hexutil.Uint64 { return (*hexutil.Uint64)(&n) }
+
+	t.Run("full history", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "history.json")
+		manifest, err := exportAccountabilityHistory(context.Background(), backend, events, 0, rpc.LatestBlockNumber, file)
+		require.NoError(t, err)
+		require.Equal(t, &AccountabilityHistoryManifest{
+			Version:    AccountabilityHistoryVersion,
+			FromBlock:  0,
+			ToBlock:    12,
+			HeadNumber: 12,
+			HeadHash:   head.Hash(),
+			Records:    4,
+		}, manifest)
+
+		history := readAccountabilityHistory(t, file)
+		require.Equal(t, manifest, history.Manifest)
+		require.Equal(t, []*AccountabilityRecord{
+			{
+				ID: 0, Type: "Accusation", Rule: "PVN", Offender: innocent, Reporter: reporter, Block: 1, ReportingBlock: 2,
+				Resolution: ResolutionInnocent, ResolutionBlock: u64(5),
+			},
+			{
+				ID: 1, Type: "Fault Proof", Rule: "Equivocation", Offender: faulty, Reporter: reporter, Block: 2, ReportingBlock: 3,
+				Resolution: ResolutionGuilty, ResolutionBlock: u64(3),
+				SlashingBlock: u64(10), SlashedAmount: (*hexutil.Big)(big.NewInt(1000)), JailReleaseBlock: u64(40), JailPeriod: u64(30),
+			},
+			{
+				ID: 2, Type: "Accusation", Rule: "C1", Offender: convicted, Reporter: reporter, Block: 4, ReportingBlock: 6,
+				Resolution: ResolutionGuilty, ResolutionBlock: u64(8),
+				SlashingBlock: u64(10), SlashedAmount: (*hexutil.Big)(big.NewInt(5000)), Jailbound: true,
+			},
+			{
+				ID: 3, Type: "Accusation", Rule: "PO", Offender: innocent, Reporter: reporter, Block: 10, ReportingBlock: 11,
+				Resolution: ResolutionPending,
+			},
+		}, history.Records)
+	})
+
+	t.Run("partial range", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "history.json")
+		_, err := exportAccountabilityHistory(context.Background(), backend, events, 4, 10, file)
+		require.NoError(t, err)
+
+		// the innocence proof of accusation 0 cannot be matched, the fault proof of event 1 is outside the range
+		history := readAccountabilityHistory(t, file)
+		require.Len(t, history.Records, 2)
+		require.Equal(t, hexutil.Uint64(1), history.Records[0].ID)
+		require.Equal(t, ResolutionGuilty, history.Records[0].Resolution)
+		require.Nil(t, history.Records[0].ResolutionBlock)
+		require.Equal(t, hexutil.Uint64(2), history.Records[1].ID)
+		require.Equal(t, u64(8), history.Records[1].ResolutionBlock)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "history.json")
+		_, err := exportAccountabilityHistory(context.Background(), backend, events, 10, 5, file)
+		require.ErrorIs(t, err, errInvalidBlockRange)
+		_, err = exportAccountabilityHistory(context.Background(), backend, events, 0, 13, file)
+		require.Error(t, err)
+
+		require.NoError(t, os.WriteFile(file, nil, 0600))
+		_, err = exportAccountabilityHistory(context.Background(), backend, events, 0, 12, file)
+		require.Error(t, err)
+	})
+}
+
+func TestExportAccountabilityAudit(t *testing.T) {
+	var (
+		reporter = common.HexToAddress("0x01")
+		offender = common.HexToAddress("0xa1")
+		// committees of epoch 0 and 1, with an epoch period of 4 blocks
+		committeeA = types.Committee{{Address: offender, VotingPower: big.NewInt(10)}, {Address: reporter, VotingPower: big.NewInt(20)}}
+		committeeB = types.Committee{{Address: reporter, VotingPower: big.NewInt(20)}}
+	)
+	// the offence happens at the last height of epoch 0, it is reported in epoch 1
+	events := contractEvents{
+		0: {EventType: uint8(autonity.Accusation), Rule: uint8(autonity.PVN), Reporter: reporter, Offender: offender, Block: big.NewInt(3), ReportingBlock: big.NewInt(5)},
+	}
+	backend := newLogChainBackend(6, map[uint64][]*types.Log{
+		5: {accountabilityLog(t, "NewAccusation", &offender, big.NewInt(2), big.NewInt(0))},
+	})
+	// the headers without logs can be altered, the committee of a height is the one of its parent header
+	for i := 0; i < 4; i++ {
+		backend.headers[i].Committee = committeeA
+	}
+	backend.headers[4].Committee = committeeB
+
+	file := filepath.Join(t.TempDir(), "history.json")
+	_, err := exportAccountabilityHistory(context.Background(), backend, events, 0, rpc.LatestBlockNumber, file)
+	require.NoError(t, err)
+	history := readAccountabilityHistory(t, file)
+	require.Len(t, history.Records, 1)
+	require.Equal(t, &AccountabilityAudit{
+		OffenceHeight:    3,
+		CommitteeHash:    committeeA.Hash(),
+		TotalVotingPower: (*hexutil.Big)(big.NewInt(30)),
+		QuorumThreshold:  (*hexutil.Big)(bft.Quorum(big.NewInt(30))),
+		DeltaBlocks:      accountability.DeltaBlocks,
+		RulesVersion:     hexutil.Uint64(accountability.RulesVersion),
+	}, history.Records[0].Audit)
+}
+
+// testAccountabilityJournal serves a fixed journal.
+type testAccountabilityJournal struct {
+	entries    []*accountability.JournalEntry
+	pending    []accountability.PendingSubmission
+	committees map[common.Hash]types.Committee
+	stats      tendermintcore.MsgStoreStats
+	late       accountability.LateMessageStats
+	arrivals   *accountability.ArrivalIndex
+}
+
+func (j *testAccountabilityJournal) AccountabilityEvents(afterSeq uint64, limit int) ([]*accountability.JournalEntry, uint64, uint64) {
+	var entries []*accountability.JournalEntry
+	for _, entry := range j.entries {
+		if entry.Seq > afterSeq && len(entries) < limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, 1, uint64(len(j.entries)) + 1
+}
+
+func (j *testAccountabilityJournal) PendingSubmissions() []accountability.PendingSubmission {
+	return j.pending
+}
+
+func (j *testAccountabilityJournal) CommitteeByHash(hash common.Hash) (types.Committee, error) {
+	committee, ok := j.committees[hash]
+	if !ok {
+		return nil, errors.New("unknown committee")
+	}
+	return committee, nil
+}
+
+func (j *testAccountabilityJournal) MsgStoreStats() tendermintcore.MsgStoreStats {
+	return j.stats
+}
+
+func (j *testAccountabilityJournal) LateMessageStats() accountability.LateMessageStats {
+	return j.late
+}
+
+func (j *testAccountabilityJournal) Arrivals(height uint64) (accountability.HeightArrivals, bool) {
+	if j.arrivals == nil {
+		return accountability.HeightArrivals{}, false
+	}
+	return j.arrivals.Arrivals(height)
+}
+
+func TestAccountabilityEventsAPI(t *testing.T) {
+	offender := common.HexToAddress("0xa1")
+	committee := types.Committee{{Address: offender, VotingPower: big.NewInt(10)}}
+	audit := accountability.NewAuditSnapshot(7, committee)
+	journal := &testAccountabilityJournal{
+		entries: []*accountability.JournalEntry{
+			{Seq: 1, Time: time.Unix(100, 0), Action: accountability.ActionAccused, Type: autonity.Accusation, Rule: autonity.PO, Offender: offender, Audit: audit},
+			{Seq: 2, Time: time.Unix(200, 0), Action: accountability.ActionEscalated, Type: autonity.Accusation, Rule: autonity.PO, Offender: offender, Audit: audit},
+		},
+		pending: []accountability.PendingSubmission{
+			{Event: autonity.AccountabilityEvent{EventType: uint8(autonity.Accusation), Rule: uint8(autonity.PO), Offender: offender, Chunks: 1}, Deadline: 300, Hashes: []common.Hash{{1}, {2}}, Audit: &audit},
+			{Event: autonity.AccountabilityEvent{EventType: uint8(autonity.Misbehaviour), Rule: uint8(autonity.PN), Offender: offender, Chunks: 1}, Deadline: 300, Hashes: []common.Hash{{3}}},
+		},
+		committees: map[common.Hash]types.Committee{audit.CommitteeHash: committee},
+	}
+	api := NewPublicAccountabilityAPI(journal)
+
+	limit := hexutil.Uint64(1)
+	page := api.GetAccountabilityEvents(1, &limit)
+	require.Equal(t, hexutil.Uint64(1), page.FirstSeq)
+	require.Equal(t, hexutil.Uint64(3), page.NextSeq)
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, &AccountabilityJournalEntry{
+		Seq:       2,
+		Timestamp: autapi.UnixTimestamp(200),
+		Action:    "escalated",
+		Type:      "Accusation",
+		Rule:      "PO",
+		Offender:  autapi.Address(offender),
+		Audit:     newAccountabilityAudit(audit),
+	}, page.Entries[0])
+
+	require.Len(t, page.Pending, 2)
+	require.Equal(t, common.Hash{2}, page.Pending[0].TxHash)
+	require.Equal(t, newAccountabilityAudit(audit), page.Pending[0].Audit)
+	require.Nil(t, page.Pending[1].Audit)
+
+	resolved, err := api.GetCommitteeByHash(page.Entries[0].Audit.CommitteeHash)
+	require.NoError(t, err)
+	require.Equal(t, autapi.NewCommittee(committee), resolved)
+}
+
+func TestFaultDetectorStatusAPI(t *testing.T) {
+	journal := &testAccountabilityJournal{
+		pending: []accountability.PendingSubmission{{Deadline: 300}},
+		stats:   tendermintcore.MsgStoreStats{Mode: tendermintcore.DigestMode, FirstHeight: 40, Heights: 60, Digests: 2500, Dropped: 12},
+		late:    accountability.LateMessageStats{IncrementalRescans: 3, DroppedStale: 7, CappedRescans: 1},
+	}
+	require.Equal(t, &FaultDetectorStatus{
+		MsgStoreMode:       "digest",
+		FirstHeight:        40,
+		Heights:            60,
+		Digests:            2500,
+		Pending:            1,
+		IncrementalRescans: 3,
+		DroppedStale:       7,
+		CappedRescans:      1,
+		DroppedOverQuota:   12,
+	}, NewPublicAccountabilityAPI(journal).GetFaultDetectorStatus())
+}
+
+func TestMessageArrivalsAPI(t *testing.T) {
+	arrivals := accountability.NewArrivalIndex(rawdb.NewMemoryDatabase(), 1, 10)
+	start := time.UnixMilli(1_000_000)
+	arrivals.Record(5, 3, 1, message.ProposalCode, start)
+	arrivals.Record(5, 3, 0, message.PrevoteCode, start.Add(40*time.Millisecond))
+	arrivals.Record(5, 3, 2, message.PrecommitCode, start.Add(90*time.Millisecond))
+	api := NewPublicAccountabilityAPI(&testAccountabilityJournal{arrivals: arrivals})
+
+	ms := func(offset uint64) *hexutil.Uint64 {
+		return (*hexutil.Uint64)(&offset)
+	}
+	require.Equal(t, &MessageArrivals{
+		Start:     autapi.Timestamp(start),
+		Proposal:  []*hexutil.Uint64{nil, ms(0), nil},
+		Prevote:   []*hexutil.Uint64{ms(40), nil, nil},
+		Precommit: []*hexutil.Uint64{nil, nil, ms(90)},
+	}, api.GetMessageArrivals(5))
+	require.Nil(t, api.GetMessageArrivals(6))
+}
+
+// testSubmissionCap is a fault detector submission cap.
+type testSubmissionCap struct {
+	perHeight, perEpoch uint64
+	queued              int
+}
+
+func (c *testSubmissionCap) SetSubmissionCap(perHeight, perEpoch uint64) {
+	c.perHeight, c.perEpoch = perHeight, perEpoch
+}
+
+func (c *testSubmissionCap) SubmissionCap() (uint64, uint64, int) {
+	return c.perHeight, c.perEpoch, c.queued
+}
+
+func TestAccountabilitySubmissionCapAPI(t *testing.T) {
+	submissions := &testSubmissionCap{perHeight: 2, perEpoch: 32, queued: 40}
+	api := NewPrivateAccountabilityAPI(submissions)
+	require.Equal(t, &AccountabilitySubmissionCap{PerHeight: 2, PerEpoch: 32, Queued: 40}, api.AccountabilitySubmissionCap())
+	require.Equal(t, &AccountabilitySubmissionCap{PerHeight: 10, PerEpoch: 0, Queued: 40}, api.SetAccountabilitySubmissionCap(10, 0))
+}
@@ -0,0 +1,295 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/autonity/autonity/accounts/abi/bind"
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/eth/filters"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+)
+
+// maxWatchedAddresses is the maximum number of validators watched by a single accountability subscription.
+const maxWatchedAddresses = 256
+
+var (
+	errNoWatchedAddress        = errors.New("no validator address to watch")
+	errTooManyWatchedAddresses = fmt.Errorf("too many validator addresses to watch, maximum is %d", maxWatchedAddresses)
+)
+
+// AccountabilityWatchCriteria selects the accountability events of an aut_subscribe("accountability")
+// subscription.
+type AccountabilityWatchCriteria struct {
+	// Addresses are the node addresses of the watched validators.
+	Addresses []common.Address `json:"addresses"`
+	// FromBlock, if set, is the first block whose events are delivered from the log index before the
+	// live ones, for a reconnecting subscriber to catch up with the events it missed.
+	FromBlock *rpc.BlockNumber `json:"fromBlock"`
+}
+
+// AccountabilityNotification is an accountability contract event affecting a watched validator.
+type AccountabilityNotification struct {
+	Event         string         `json:"event"`         // NewAccusation, NewFaultProof, InnocenceProven or SlashingEvent
+	ID            hexutil.Uint64 `json:"id"`            // event id in the accountability contract
+	Offender      autapi.Address `json:"offender"`      // node address of the validator
+	Type          string         `json:"type"`          // event type as submitted: "Fault Proof" or "Accusation"
+	Rule          string         `json:"rule"`          // accountability rule which was broken
+	OffenceHeight hexutil.Uint64 `json:"offenceHeight"` // height at which the offence happened
+	Severity      *hexutil.Big   `json:"severity,omitempty"`
+
+	// Slashing outcome, only set for the slashing events.
+	SlashedAmount    *hexutil.Big    `json:"slashedAmount,omitempty"`
+	Jailbound        bool            `json:"jailbound,omitempty"`        // the validator is jailed permanently
+	JailReleaseBlock *hexutil.Uint64 `json:"jailReleaseBlock,omitempty"` // unset when jailbound
+	JailPeriod       *hexutil.Uint64 `json:"jailPeriod,omitempty"`       // number of blocks spent in jail, unset when jailbound
+
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	LogIndex    hexutil.Uint   `json:"logIndex"`
+}
+
+// accountabilityWatchReader reads the accountability events stored by the contract, including the
+// accusation pending against a validator, which is the one dismissed by an innocence proof.
+type accountabilityWatchReader interface {
+	accountabilityEventReader
+	PendingAccusation(ctx context.Context, offender common.Address, head *types.Header) (*autonity.AccountabilityEvent, error)
+}
+
+func (r *contractEventReader) PendingAccusation(ctx context.Context, offender common.Address, head *types.Header) (*autonity.AccountabilityEvent, error) {
+	event, err := r.contract.GetValidatorAccusation(&bind.CallOpts{Context: ctx, BlockNumber: head.Number}, offender)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// PublicAccountabilityWatchAPI serves the accountability events affecting a set of validators as they
+// are included, under the aut namespace.
+type PublicAccountabilityWatchAPI struct {
+	backend filters.Backend
+	events  func() accountabilityWatchReader // nil if the accountability contract is not available
+}
+
+// NewPublicAccountabilityWatchAPI creates a new accountability watch API instance.
+func NewPublicAccountabilityWatchAPI(backend filters.Backend, events func() accountabilityWatchReader) *PublicAccountabilityWatchAPI {
+	return &PublicAccountabilityWatchAPI{backend: backend, events: events}
+}
+
+// Accountability creates a subscription that fires for each accusation, fault proof, innocence proof
+// and slashing of the watched validators, decoded from the accountability contract events. If
+// fromBlock is set, the events included since are delivered first, from the log index.
+func (api *PublicAccountabilityWatchAPI) Accountability(ctx context.Context, criteria AccountabilityWatchCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if len(criteria.Addresses) == 0 {
+		return nil, errNoWatchedAddress
+	}
+	if len(criteria.Addresses) > maxWatchedAddresses {
+		return nil, errTooManyWatchedAddresses
+	}
+	events := api.events()
+	if events == nil {
+		return nil, errNoAccountabilityContract
+	}
+
+	// subscribed before reading the head, so that no event is missed between the catch-up and the live ones
+	logs := make(chan []*types.Log)
+	logsSub := api.backend.SubscribeLogsEvent(logs)
+	head, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		logsSub.Unsubscribe()
+		return nil, err
+	}
+	watch := &accountabilityWatch{
+		backend: api.backend,
+		events:  events,
+		watched: make(map[common.Address]struct{}, len(criteria.Addresses)),
+		last:    head.Number.Uint64(),
+	}
+	for _, address := range criteria.Addresses {
+		watch.watched[address] = struct{}{}
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		defer logsSub.Unsubscribe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// the live events are queued while catching up, the feed must not be held up
+		var (
+			catchUp chan []*types.Log
+			queued  []*types.Log
+		)
+		if criteria.FromBlock != nil {
+			if from := resolveBlockNumber(*criteria.FromBlock, watch.last); from <= watch.last {
+				catchUp = make(chan []*types.Log, 1)
+				go func() {
+					filter := filters.NewRangeFilter(api.backend, int64(from), int64(watch.last),
+						[]common.Address{params.AccountabilityContractAddress}, [][]common.Hash{accountabilityTopics})
+					missed, err := filter.Logs(ctx)
+					if err != nil {
+						log.Warn("Failed to retrieve the missed accountability events", "from", from, "to", watch.last, "err", err)
+					}
+					catchUp <- missed
+				}()
+			}
+		}
+		notify := func(logs []*types.Log, live bool) {
+			for _, l := range logs {
+				// the live events up to the head at subscription time are delivered by the catch-up
+				if live && l.BlockNumber <= watch.last {
+					continue
+				}
+				if n := watch.notification(ctx, l); n != nil {
+					notifier.Notify(rpcSub.ID, n)
+				}
+			}
+		}
+		for {
+			select {
+			case missed := <-catchUp:
+				notify(missed, false)
+				notify(queued, true)
+				catchUp, queued = nil, nil
+			case live := <-logs:
+				if catchUp != nil {
+					queued = append(queued, live...)
+					continue
+				}
+				notify(live, true)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// accountabilityWatch decodes the accountability contract events affecting the watched validators.
+type accountabilityWatch struct {
+	backend  filters.Backend
+	events   accountabilityWatchReader
+	filterer *autonity.AccountabilityFilterer
+	watched  map[common.Address]struct{}
+	last     uint64 // head at subscription time, the first live events are the ones after it
+}
+
+// notification returns the notification of an accountability contract log, or nil if it does not affect
+// a watched validator or was already delivered.
+func (w *accountabilityWatch) notification(ctx context.Context, l *types.Log) *AccountabilityNotification {
+	if l.Removed || l.Address != params.AccountabilityContractAddress || len(l.Topics) == 0 {
+		return nil
+	}
+	if w.filterer == nil {
+		// the filterer is only used to decode logs, it does not need a backend
+		w.filterer, _ = autonity.NewAccountabilityFilterer(params.AccountabilityContractAddress, nil)
+	}
+	n, err := w.decode(ctx, l)
+	if err != nil {
+		log.Warn("Failed to decode accountability event", "block", l.BlockNumber, "index", l.Index, "err", err)
+		return nil
+	}
+	return n
+}
+
+func (w *accountabilityWatch) decode(ctx context.Context, l *types.Log) (*AccountabilityNotification, error) {
+	var (
+		n = &AccountabilityNotification{
+			BlockNumber: hexutil.Uint64(l.BlockNumber),
+			BlockHash:   l.BlockHash,
+			TxHash:      l.TxHash,
+			LogIndex:    hexutil.Uint(l.Index),
+		}
+		id *big.Int
+	)
+	switch l.Topics[0] {
+	case accountabilityTopics[0]:
+		ev, err := w.filterer.ParseNewAccusation(*l)
+		if err != nil {
+			return nil, err
+		}
+		n.Event, n.Offender, n.Severity, id = "NewAccusation", autapi.Address(ev.Offender), (*hexutil.Big)(ev.Severity), ev.Id
+	case accountabilityTopics[1]:
+		ev, err := w.filterer.ParseNewFaultProof(*l)
+		if err != nil {
+			return nil, err
+		}
+		n.Event, n.Offender, n.Severity, id = "NewFaultProof", autapi.Address(ev.Offender), (*hexutil.Big)(ev.Severity), ev.Id
+	case accountabilityTopics[2]:
+		ev, err := w.filterer.ParseInnocenceProven(*l)
+		if err != nil {
+			return nil, err
+		}
+		n.Event, n.Offender = "InnocenceProven", autapi.Address(ev.Offender)
+	case accountabilityTopics[3]:
+		ev, err := w.filterer.ParseSlashingEvent(*l)
+		if err != nil {
+			return nil, err
+		}
+		n.Event, n.Offender, id = "SlashingEvent", autapi.Address(ev.Validator), ev.EventId
+		n.SlashedAmount, n.Jailbound = (*hexutil.Big)(ev.Amount), ev.IsJailbound
+		if !ev.IsJailbound {
+			release := hexutil.Uint64(ev.ReleaseBlock.Uint64())
+			period := release - n.BlockNumber
+			n.JailReleaseBlock, n.JailPeriod = &release, &period
+		}
+	default:
+		return nil, nil
+	}
+	if _, ok := w.watched[common.Address(n.Offender)]; !ok {
+		return nil, nil
+	}
+
+	var (
+		event *autonity.AccountabilityEvent
+		err   error
+	)
+	if id != nil {
+		header, herr := w.backend.HeaderByNumber(ctx, rpc.BlockNumber(l.BlockNumber))
+		if herr != nil || header == nil {
+			return nil, fmt.Errorf("header #%d not found: %v", l.BlockNumber, herr)
+		}
+		event, err = w.events.Event(ctx, id, header)
+	} else {
+		// the contract does not emit the id of the dismissed accusation, it is the one pending before
+		parent, herr := w.backend.HeaderByNumber(ctx, rpc.BlockNumber(l.BlockNumber-1))
+		if herr != nil || parent == nil {
+			return nil, fmt.Errorf("header #%d not found: %v", l.BlockNumber-1, herr)
+		}
+		event, err = w.events.PendingAccusation(ctx, common.Address(n.Offender), parent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	n.ID = hexutil.Uint64(event.Id.Uint64())
+	n.Type = autonity.AccountabilityEventType(event.EventType).String()
+	n.Rule = autonity.Rule(event.Rule).String()
+	n.OffenceHeight = hexutil.Uint64(event.Block.Uint64())
+	return n, nil
+}
+
+// accountabilityEvents returns the reader of the accountability contract events, or nil if the
+// contract is not deployed yet.
+func (s *Ethereum) accountabilityEvents() accountabilityWatchReader {
+	contracts := s.blockchain.ProtocolContracts()
+	if contracts == nil || contracts.Accountability == nil {
+		return nil
+	}
+	return &contractEventReader{contracts.Accountability}
+}
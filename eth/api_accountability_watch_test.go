@@ -0,0 +1,164 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/node"
+	"github.com/autonity/autonity/rpc"
+)
+
+// PendingAccusation returns the last accusation reported against the offender up to head.
+func (e contractEvents) PendingAccusation(_ context.Context, offender common.Address, head *types.Header) (*autonity.AccountabilityEvent, error) {
+	var pending *autonity.AccountabilityEvent
+	for _, event := range e {
+		if event.EventType != uint8(autonity.Accusation) || event.Offender != offender || event.ReportingBlock.Cmp(head.Number) > 0 {
+			continue
+		}
+		if pending == nil || event.Id.Cmp(pending.Id) > 0 {
+			pending = event
+		}
+	}
+	if pending == nil {
+		return nil, errors.New("no pending accusation")
+	}
+	return pending, nil
+}
+
+func TestAccountabilitySubscription(t *testing.T) {
+	var (
+		reporter = common.HexToAddress("0x01")
+		innocent = common.HexToAddress("0xa1")
+		faulty   = common.HexToAddress("0xa2")
+		other    = common.HexToAddress("0xa3")
+		severity = big.NewInt(2)
+	)
+	events := contractEvents{
+		0: {Id: big.NewInt(0), EventType: uint8(autonity.Accusation), Rule: uint8(autonity.PVN), Reporter: reporter, Offender: innocent, Block: big.NewInt(1), ReportingBlock: big.NewInt(2)},
+		1: {Id: big.NewInt(1), EventType: uint8(autonity.Misbehaviour), Rule: uint8(autonity.Equivocation), Reporter: reporter, Offender: faulty, Block: big.NewInt(2), ReportingBlock: big.NewInt(3)},
+		2: {Id: big.NewInt(2), EventType: uint8(autonity.Accusation), Rule: uint8(autonity.C1), Reporter: reporter, Offender: other, Block: big.NewInt(5), ReportingBlock: big.NewInt(6)},
+		3: {Id: big.NewInt(3), EventType: uint8(autonity.Accusation), Rule: uint8(autonity.PO), Reporter: reporter, Offender: innocent, Block: big.NewInt(8), ReportingBlock: big.NewInt(9)},
+		4: {Id: big.NewInt(4), EventType: uint8(autonity.Misbehaviour), Rule: uint8(autonity.PO), Reporter: reporter, Offender: innocent, Block: big.NewInt(8), ReportingBlock: big.NewInt(10)},
+	}
+	logs := map[uint64][]*types.Log{
+		2:  {accountabilityLog(t, "NewAccusation", &innocent, severity, big.NewInt(0))},
+		3:  {accountabilityLog(t, "NewFaultProof", &faulty, severity, big.NewInt(1))},
+		5:  {accountabilityLog(t, "InnocenceProven", &innocent, big.NewInt(0))},
+		6:  {accountabilityLog(t, "NewAccusation", &other, severity, big.NewInt(2))},
+		7:  {accountabilityLog(t, "SlashingEvent", nil, faulty, big.NewInt(1000), big.NewInt(40), false, big.NewInt(1))},
+		9:  {accountabilityLog(t, "NewAccusation", &innocent, severity, big.NewInt(3))},
+		10: {accountabilityLog(t, "NewFaultProof", &innocent, severity, big.NewInt(4))},
+	}
+	backend := newLogChainBackend(10, logs)
+	backend.head = 4
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	apis := []rpc.API{{
+		Namespace: "aut",
+		Service:   NewPublicAccountabilityWatchAPI(backend, func() accountabilityWatchReader { return events }),
+		Public:    true,
+	}}
+	require.NoError(t, node.RegisterApis(apis, []string{"aut"}, server, false))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	subscribe := func(criteria AccountabilityWatchCriteria) (*rpc.Client, chan AccountabilityNotification, *rpc.ClientSubscription) {
+		client := rpc.DialInProc(server)
+		notifications := make(chan AccountabilityNotification)
+		sub, err := client.Subscribe(ctx, "aut", notifications, "accountability", criteria)
+		require.NoError(t, err)
+		return client, notifications, sub
+	}
+	next := func(notifications chan AccountabilityNotification, sub *rpc.ClientSubscription) AccountabilityNotification {
+		select {
+		case n := <-notifications:
+			return n
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("no accountability event notified")
+		}
+		return AccountabilityNotification{}
+	}
+	u64 := func(n uint64) *hexutil.Uint64 { return (*hexutil.Uint64)(&n) }
+	watched := []common.Address{innocent, faulty}
+
+	t.Run("watched address limit", func(t *testing.T) {
+		client := rpc.DialInProc(server)
+		defer client.Close()
+		_, err := client.Subscribe(ctx, "aut", make(chan AccountabilityNotification), "accountability", AccountabilityWatchCriteria{})
+		require.EqualError(t, err, errNoWatchedAddress.Error())
+		criteria := AccountabilityWatchCriteria{Addresses: make([]common.Address, maxWatchedAddresses+1)}
+		_, err = client.Subscribe(ctx, "aut", make(chan AccountabilityNotification), "accountability", criteria)
+		require.EqualError(t, err, errTooManyWatchedAddresses.Error())
+	})
+
+	t.Run("filtered delivery and catch-up", func(t *testing.T) {
+		client, notifications, sub := subscribe(AccountabilityWatchCriteria{Addresses: watched})
+		// the events up to the head at subscription time are not delivered again, nor the unwatched ones
+		backend.logsFeed.Send(logs[3])
+		backend.logsFeed.Send(logs[5])
+		backend.logsFeed.Send(logs[6])
+		require.Equal(t, AccountabilityNotification{
+			Event:         "InnocenceProven",
+			ID:            0,
+			Offender:      autapi.Address(innocent),
+			Type:          "Accusation",
+			Rule:          "PVN",
+			OffenceHeight: 1,
+			BlockNumber:   5,
+			BlockHash:     backend.headers[5].Hash(),
+		}, next(notifications, sub))
+		backend.logsFeed.Send(logs[7])
+		require.Equal(t, AccountabilityNotification{
+			Event:            "SlashingEvent",
+			ID:               1,
+			Offender:         autapi.Address(faulty),
+			Type:             "Fault Proof",
+			Rule:             "Equivocation",
+			OffenceHeight:    2,
+			SlashedAmount:    (*hexutil.Big)(big.NewInt(1000)),
+			JailReleaseBlock: u64(40),
+			JailPeriod:       u64(33),
+			BlockNumber:      7,
+			BlockHash:        backend.headers[7].Hash(),
+		}, next(notifications, sub))
+
+		// the subscriber disconnects and misses the accusation of block 9
+		client.Close()
+		backend.head = 9
+
+		from := rpc.BlockNumber(7)
+		client, notifications, sub = subscribe(AccountabilityWatchCriteria{Addresses: watched, FromBlock: &from})
+		defer client.Close()
+		for _, want := range []struct {
+			event string
+			id    hexutil.Uint64
+			block hexutil.Uint64
+		}{{"SlashingEvent", 1, 7}, {"NewAccusation", 3, 9}} {
+			n := next(notifications, sub)
+			require.Equal(t, want.event, n.Event)
+			require.Equal(t, want.id, n.ID)
+			require.Equal(t, want.block, n.BlockNumber)
+		}
+		// live events follow the missed ones
+		backend.logsFeed.Send(logs[10])
+		n := next(notifications, sub)
+		require.Equal(t, "NewFaultProof", n.Event)
+		require.Equal(t, autapi.Address(innocent), n.Offender)
+		require.Equal(t, "Fault Proof", n.Type)
+		require.Equal(t, "PO", n.Rule)
+		require.Equal(t, (*hexutil.Big)(severity), n.Severity)
+	})
+}
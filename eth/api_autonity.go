@@ -0,0 +1,206 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+)
+
+const (
+	// maxParticipationRange is the maximum number of blocks that can be inspected
+	// by a single aut_getParticipation call.
+	maxParticipationRange = 10000
+)
+
+var (
+	errInvalidBlockRange  = errors.New("invalid block range: fromBlock is greater than toBlock")
+	errBlockRangeTooLarge = fmt.Errorf("block range too large, maximum is %d blocks", maxParticipationRange)
+)
+
+// headerRangeReader is the subset of the blockchain methods needed to serve
+// header-only range queries. HeaderRangeIterator transparently reads from both
+// the freezer and the key-value store.
+type headerRangeReader interface {
+	Config() *params.ChainConfig
+	CurrentHeader() *types.Header
+	HeaderRangeIterator(ctx context.Context, from, to uint64) *rawdb.HeaderIterator
+}
+
+// PublicAutonityAPI exposes node-level information about the Autonity
+// consensus protocol under the aut namespace. Contrary to AutonityContractAPI,
+// the data served here is derived from the local chain and not from the
+// protocol contracts state.
+type PublicAutonityAPI struct {
+	chain      headerRangeReader
+	validators validatorSetReader
+}
+
+// NewPublicAutonityAPI creates a new Autonity API instance.
+func NewPublicAutonityAPI(chain headerRangeReader, validators validatorSetReader) *PublicAutonityAPI {
+	return &PublicAutonityAPI{chain: chain, validators: validators}
+}
+
+// ParticipationOptions are the optional flags of aut_getParticipation.
+type ParticipationOptions struct {
+	// Addresses returns the signers addresses instead of their committee indices.
+	Addresses bool `json:"addresses"`
+	// Aggregate returns per-validator presence counts over the range instead of
+	// the per-block participation.
+	Aggregate bool `json:"aggregate"`
+}
+
+// BlockParticipation is the consensus participation for a single block.
+type BlockParticipation struct {
+	Number  hexutil.Uint64   `json:"number"`
+	Hash    common.Hash      `json:"hash"`
+	Round   hexutil.Uint64   `json:"round"`
+	Indices []hexutil.Uint64 `json:"indices,omitempty"`
+	Signers []autapi.Address `json:"signers,omitempty"`
+}
+
+// ValidatorPresence counts, for a validator, the number of blocks in the range
+// for which it was member of the committee and the number of blocks whose
+// quorum certificate it was part of.
+type ValidatorPresence struct {
+	Committee hexutil.Uint64 `json:"committee"`
+	Signed    hexutil.Uint64 `json:"signed"`
+}
+
+// Participation is the result of aut_getParticipation.
+type Participation struct {
+	From     hexutil.Uint64                        `json:"from"`
+	To       hexutil.Uint64                        `json:"to"`
+	Blocks   []*BlockParticipation                 `json:"blocks,omitempty"`
+	Presence map[autapi.Address]*ValidatorPresence `json:"presence,omitempty"`
+}
+
+// GetParticipation returns, for each block in [fromBlock, toBlock], the round at which the block
+// was committed and the committee members whose signature is part of the quorum certificate.
+// Only headers are read, both from the freezer and the key-value store.
+func (api *PublicAutonityAPI) GetParticipation(ctx context.Context, fromBlock, toBlock rpc.BlockNumber, opts *ParticipationOptions) (*Participation, error) {
+	if opts == nil {
+		opts = &ParticipationOptions{}
+	}
+	head := api.chain.CurrentHeader().Number.Uint64()
+	from, to := resolveBlockNumber(fromBlock, head), resolveBlockNumber(toBlock, head)
+	if from > to {
+		return nil, errInvalidBlockRange
+	}
+	if to > head {
+		return nil, fmt.Errorf("block #%d not found", to)
+	}
+	if to-from+1 > maxParticipationRange {
+		return nil, errBlockRangeTooLarge
+	}
+
+	result := &Participation{From: hexutil.Uint64(from), To: hexutil.Uint64(to)}
+	if opts.Aggregate {
+		result.Presence = make(map[autapi.Address]*ValidatorPresence)
+	}
+
+	// the parent header is needed to resolve the committee of the first block
+	start := from
+	if from > 0 {
+		start = from - 1
+	}
+	var parent *types.Header
+	it := api.chain.HeaderRangeIterator(ctx, start, to)
+	for it.Next() {
+		header := it.Header()
+		if header.Number.Uint64() >= from {
+			indices, err := quorumCertificateSigners(header, parent)
+			if err != nil {
+				return nil, fmt.Errorf("block #%d: %w", header.Number.Uint64(), err)
+			}
+			if opts.Aggregate {
+				aggregatePresence(result.Presence, parent, indices)
+			} else {
+				result.Blocks = append(result.Blocks, newBlockParticipation(header, parent, indices, opts.Addresses))
+			}
+		}
+		parent = header
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProposalSize is the result of aut_getMaxProposalSize.
+type ProposalSize struct {
+	// Cap is the configured cap on the size of a proposed block.
+	Cap hexutil.Uint64 `json:"cap"`
+	// Limit is the effective limit for a block with the gas limit of the head block.
+	Limit hexutil.Uint64 `json:"limit"`
+}
+
+// GetMaxProposalSize returns the maximum RLP size, in bytes, of a proposed block. Larger proposals
+// are rejected by the proposer and treated as invalid by the other committee members.
+func (api *PublicAutonityAPI) GetMaxProposalSize() *ProposalSize {
+	config := api.chain.Config()
+	return &ProposalSize{
+		Cap:   hexutil.Uint64(config.ProposalSizeCap()),
+		Limit: hexutil.Uint64(config.ProposalSizeLimit(api.chain.CurrentHeader().GasLimit)),
+	}
+}
+
+// quorumCertificateSigners returns the committee indices of the signers of the header's quorum certificate.
+func quorumCertificateSigners(header, parent *types.Header) ([]int, error) {
+	if header.IsGenesis() || header.QuorumCertificate.Signers == nil {
+		return nil, nil
+	}
+	// copy so that we do not modify the header when doing Signers.Validate()
+	signers := header.QuorumCertificate.Signers.Copy()
+	if err := signers.Validate(len(parent.Committee)); err != nil {
+		return nil, fmt.Errorf("invalid quorum certificate signers information: %w", err)
+	}
+	return signers.FlattenUniq(), nil
+}
+
+func newBlockParticipation(header, parent *types.Header, indices []int, addresses bool) *BlockParticipation {
+	participation := &BlockParticipation{
+		Number: hexutil.Uint64(header.Number.Uint64()),
+		Hash:   header.Hash(),
+		Round:  hexutil.Uint64(header.Round),
+	}
+	for _, index := range indices {
+		if addresses {
+			participation.Signers = append(participation.Signers, autapi.Address(parent.Committee[index].Address))
+		} else {
+			participation.Indices = append(participation.Indices, hexutil.Uint64(index))
+		}
+	}
+	return participation
+}
+
+func aggregatePresence(presence map[autapi.Address]*ValidatorPresence, parent *types.Header, indices []int) {
+	if parent == nil {
+		return
+	}
+	for _, member := range parent.Committee {
+		address := autapi.Address(member.Address)
+		if _, ok := presence[address]; !ok {
+			presence[address] = new(ValidatorPresence)
+		}
+		presence[address].Committee++
+	}
+	for _, index := range indices {
+		presence[autapi.Address(parent.Committee[index].Address)].Signed++
+	}
+}
+
+// resolveBlockNumber maps the special rpc block numbers to the local head.
+func resolveBlockNumber(number rpc.BlockNumber, head uint64) uint64 {
+	if number < 0 {
+		return head
+	}
+	return uint64(number)
+}
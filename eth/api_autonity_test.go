@@ -0,0 +1,206 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/ethdb/memorydb"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+)
+
+// rawHeaderReader serves headers straight from the database, the same way the
+// header chain does once its cache is cold.
+type rawHeaderReader struct {
+	db   ethdb.Database
+	head *types.Header
+}
+
+func (r *rawHeaderReader) Config() *params.ChainConfig { return params.TestChainConfig }
+
+func (r *rawHeaderReader) CurrentHeader() *types.Header { return r.head }
+
+func (r *rawHeaderReader) HeaderRangeIterator(ctx context.Context, from, to uint64) *rawdb.HeaderIterator {
+	return rawdb.NewHeaderIterator(ctx, r.db, from, to)
+}
+
+// newTestHeaderChain creates a chain of n+1 headers (genesis included) with a fixed committee
+// and a varying quorum certificate. The first `frozen` headers are moved to the freezer.
+func newTestHeaderChain(t *testing.T, committeeSize int, n, frozen uint64) (*rawHeaderReader, []*types.Header) {
+	db, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), t.TempDir(), "", false)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	committee := make(types.Committee, committeeSize)
+	for i := range committee {
+		key, err := blst.RandKey()
+		require.NoError(t, err)
+		committee[i] = types.CommitteeMember{
+			Address:           common.BytesToAddress([]byte{byte(i + 1)}),
+			VotingPower:       big.NewInt(int64(i + 1)),
+			ConsensusKeyBytes: key.PublicKey().Marshal(),
+			ConsensusKey:      key.PublicKey(),
+			Index:             uint64(i),
+		}
+	}
+	key, err := blst.RandKey()
+	require.NoError(t, err)
+
+	headers := make([]*types.Header, n+1)
+	for i := uint64(0); i <= n; i++ {
+		header := &types.Header{
+			Number:     new(big.Int).SetUint64(i),
+			Difficulty: big.NewInt(1),
+			MixDigest:  types.BFTDigest,
+			Time:       i,
+			Committee:  committee,
+		}
+		if i > 0 {
+			header.ParentHash = headers[i-1].Hash()
+			header.Round = i % 3
+			signers := types.NewSigners(committeeSize)
+			for j := range committee {
+				// leave out a different member at each height
+				if uint64(j) != i%uint64(committeeSize) {
+					signers.Increment(&committee[j])
+				}
+			}
+			header.QuorumCertificate = types.NewAggregateSignature(key.Sign(header.Number.Bytes()).(*blst.BlsSignature), signers)
+		}
+		headers[i] = header
+	}
+
+	var blocks []*types.Block
+	var receipts []types.Receipts
+	for _, header := range headers[:frozen] {
+		blocks = append(blocks, types.NewBlockWithHeader(header))
+		receipts = append(receipts, nil)
+	}
+	_, err = rawdb.WriteAncientBlocks(db, blocks, receipts, big.NewInt(1))
+	require.NoError(t, err)
+	for _, header := range headers[frozen:] {
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+	ancients, err := db.Ancients()
+	require.NoError(t, err)
+	require.Equal(t, frozen, ancients)
+
+	return &rawHeaderReader{db: db, head: headers[n]}, headers
+}
+
+// decodedSigners decodes the quorum certificate of headers[number] independently of the API.
+func decodedSigners(t *testing.T, headers []*types.Header, number uint64) []int {
+	if number == 0 {
+		return nil
+	}
+	signers := headers[number].QuorumCertificate.Signers.Copy()
+	require.NoError(t, signers.Validate(len(headers[number-1].Committee)))
+	return signers.FlattenUniq()
+}
+
+func TestGetParticipation(t *testing.T) {
+	const committeeSize = 4
+	chain, headers := newTestHeaderChain(t, committeeSize, 100, 40)
+	api := NewPublicAutonityAPI(chain, nil)
+
+	t.Run("indices across freezer and key-value store", func(t *testing.T) {
+		result, err := api.GetParticipation(context.Background(), 0, 100, nil)
+		require.NoError(t, err)
+		require.Equal(t, hexutil.Uint64(0), result.From)
+		require.Equal(t, hexutil.Uint64(100), result.To)
+		require.Len(t, result.Blocks, 101)
+		for i, block := range result.Blocks {
+			number := uint64(i)
+			require.Equal(t, hexutil.Uint64(number), block.Number)
+			require.Equal(t, headers[number].Hash(), block.Hash)
+			require.Equal(t, hexutil.Uint64(headers[number].Round), block.Round)
+			var want []hexutil.Uint64
+			for _, index := range decodedSigners(t, headers, number) {
+				want = append(want, hexutil.Uint64(index))
+			}
+			require.Equal(t, want, block.Indices)
+			require.Nil(t, block.Signers)
+		}
+	})
+
+	t.Run("addresses starting from a frozen parent", func(t *testing.T) {
+		result, err := api.GetParticipation(context.Background(), 40, 45, &ParticipationOptions{Addresses: true})
+		require.NoError(t, err)
+		require.Len(t, result.Blocks, 6)
+		for i, block := range result.Blocks {
+			number := uint64(40 + i)
+			require.Nil(t, block.Indices)
+			var want []autapi.Address
+			for _, index := range decodedSigners(t, headers, number) {
+				want = append(want, autapi.Address(headers[number-1].Committee[index].Address))
+			}
+			require.Equal(t, want, block.Signers)
+		}
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		result, err := api.GetParticipation(context.Background(), 1, rpc.LatestBlockNumber, &ParticipationOptions{Aggregate: true})
+		require.NoError(t, err)
+		require.Nil(t, result.Blocks)
+		require.Len(t, result.Presence, committeeSize)
+		for j, member := range headers[0].Committee {
+			signed := uint64(0)
+			for number := uint64(1); number <= 100; number++ {
+				if number%committeeSize != uint64(j) {
+					signed++
+				}
+			}
+			presence := result.Presence[autapi.Address(member.Address)]
+			require.Equal(t, hexutil.Uint64(100), presence.Committee)
+			require.Equal(t, hexutil.Uint64(signed), presence.Signed)
+		}
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		_, err := api.GetParticipation(context.Background(), 10, 5, nil)
+		require.ErrorIs(t, err, errInvalidBlockRange)
+		_, err = api.GetParticipation(context.Background(), 0, 101, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("cancelled request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := api.GetParticipation(ctx, 0, 100, nil)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestGetParticipationRangeLimit(t *testing.T) {
+	chain, _ := newTestHeaderChain(t, 2, maxParticipationRange+1, 0)
+	api := NewPublicAutonityAPI(chain, nil)
+
+	_, err := api.GetParticipation(context.Background(), 0, maxParticipationRange, nil)
+	require.ErrorIs(t, err, errBlockRangeTooLarge)
+
+	result, err := api.GetParticipation(context.Background(), 1, maxParticipationRange, &ParticipationOptions{Aggregate: true})
+	require.NoError(t, err)
+	require.Len(t, result.Presence, 2)
+}
+
+func TestGetMaxProposalSize(t *testing.T) {
+	chain := &rawHeaderReader{head: &types.Header{Number: big.NewInt(1), GasLimit: 20_000_000}}
+	result := NewPublicAutonityAPI(chain, nil).GetMaxProposalSize()
+	require.Equal(t, hexutil.Uint64(params.DefaultMaxProposalSize), result.Cap)
+	require.Equal(t, hexutil.Uint64(20_000_000/params.TxDataZeroGas+params.ProposalHeaderReserve), result.Limit)
+
+	chain.head = &types.Header{Number: big.NewInt(2), GasLimit: 100_000_000}
+	result = NewPublicAutonityAPI(chain, nil).GetMaxProposalSize()
+	require.Equal(t, result.Cap, result.Limit)
+}
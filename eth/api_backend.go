@@ -19,6 +19,7 @@ package eth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -232,6 +233,10 @@ func (b *EthAPIBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) e
 	return b.eth.BlockChain().SubscribeChainSideEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeCommitteeChangeEvent(ch chan<- CommitteeChangeEvent) event.Subscription {
+	return b.eth.committeeChanges.SubscribeCommitteeChangeEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return b.eth.BlockChain().SubscribeLogsEvent(ch)
 }
@@ -258,6 +263,40 @@ func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash)
 	return tx, blockHash, blockNumber, index, nil
 }
 
+// GetTransactionInRange looks the transaction up in the canonical blocks from and to included. The
+// block bodies of the range are scanned if the transaction is not indexed, e.g. once its index is
+// pruned by the txlookup limit, and the block of the transaction found is indexed again.
+func (b *EthAPIBackend) GetTransactionInRange(ctx context.Context, txHash common.Hash, from uint64, to uint64) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	db := b.eth.ChainDb()
+	if tx, blockHash, blockNumber, index := rawdb.ReadTransaction(db, txHash); tx != nil {
+		return tx, blockHash, blockNumber, index, nil
+	}
+	if head := b.eth.blockchain.CurrentBlock().NumberU64(); to > head {
+		to = head
+	}
+	if from > to {
+		return nil, common.Hash{}, 0, 0, nil
+	}
+	if limit := b.eth.config.RPCTxLookupRange; limit != 0 && to-from+1 > limit {
+		return nil, common.Hash{}, 0, 0, fmt.Errorf("transaction lookup range of %d blocks exceeds the configured limit (%d)", to-from+1, limit)
+	}
+	number, found := rawdb.FindTransaction(db, txHash, from, to+1)
+	if !found {
+		return nil, common.Hash{}, 0, 0, nil
+	}
+	block := b.eth.blockchain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, common.Hash{}, 0, 0, errors.New("block body is missing")
+	}
+	rawdb.WriteTxLookupEntriesByBlock(db, block)
+	for index, tx := range block.Transactions() {
+		if tx.Hash() == txHash {
+			return tx, block.Hash(), number, uint64(index), nil
+		}
+	}
+	return nil, common.Hash{}, 0, 0, nil
+}
+
 func (b *EthAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eth.txPool.Nonce(addr), nil
 }
@@ -332,7 +371,13 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.eth.bloomIndexer.Sections()
-	return params.BloomBitsBlocks, sections
+	return b.eth.bloomGenerations[len(b.eth.bloomGenerations)-1].SectionSize, sections
+}
+
+// BloomGenerations returns the section sizes the bloom index was generated with,
+// BloomStatus reporting the progress of the last one.
+func (b *EthAPIBackend) BloomGenerations() []rawdb.BloomGeneration {
+	return b.eth.bloomGenerations
 }
 
 func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
@@ -0,0 +1,90 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/accounts/abi/bind/backends"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/eth/ethconfig"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+)
+
+func TestGetTransactionInRange(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(params.Ether)}},
+	}
+	signer := types.LatestSigner(gspec.Config)
+
+	// blocks 1 to 10 include a transaction each
+	genDB := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(genDB)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), genDB, 10, func(i int, b *core.BlockGen) {
+		to := common.Address{0x01}
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: uint64(i), To: &to, Gas: 21000, GasPrice: b.BaseFee()})
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, &core.TxSenderCacher{}, nil, backends.NewInternalBackend(nil), log.Root())
+	require.NoError(t, err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	// the transactions of the blocks below 6 are no longer indexed
+	rawdb.UnindexTransactions(db, 0, 6, nil)
+	pruned := blocks[2].Transactions()[0]
+	tx, _, _, _ := rawdb.ReadTransaction(db, pruned.Hash())
+	require.Nil(t, tx)
+
+	backend := &EthAPIBackend{eth: &Ethereum{blockchain: chain, chainDb: db, config: &ethconfig.Config{RPCTxLookupRange: 4}}}
+	ctx := context.Background()
+
+	// the range lookup does not include the block of the transaction
+	tx, _, _, _, err = backend.GetTransactionInRange(ctx, pruned.Hash(), 4, 7)
+	require.NoError(t, err)
+	require.Nil(t, tx)
+
+	// the range exceeds the lookup limit
+	_, _, _, _, err = backend.GetTransactionInRange(ctx, pruned.Hash(), 1, 5)
+	require.Error(t, err)
+
+	// the range is capped at the head block
+	tx, _, _, _, err = backend.GetTransactionInRange(ctx, pruned.Hash(), 7, 100)
+	require.NoError(t, err)
+	require.Nil(t, tx)
+
+	tx, blockHash, number, index, err := backend.GetTransactionInRange(ctx, pruned.Hash(), 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, pruned.Hash(), tx.Hash())
+	require.Equal(t, blocks[2].Hash(), blockHash)
+	require.Equal(t, uint64(3), number)
+	require.Equal(t, uint64(0), index)
+
+	// the block of the transaction is indexed again
+	tx, _, number, _ = rawdb.ReadTransaction(db, pruned.Hash())
+	require.Equal(t, pruned.Hash(), tx.Hash())
+	require.Equal(t, uint64(3), number)
+
+	// the indexed transactions are found out of the range
+	indexed := blocks[8].Transactions()[0]
+	tx, _, number, _, err = backend.GetTransactionInRange(ctx, indexed.Hash(), 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, indexed.Hash(), tx.Hash())
+	require.Equal(t, uint64(9), number)
+}
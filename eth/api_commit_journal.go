@@ -0,0 +1,74 @@
+package eth
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/eth/autapi"
+)
+
+// maxCommitJournalEntries is the maximum number of entries returned by a single aut_commitJournal call.
+const maxCommitJournalEntries = 1024
+
+var errCommitJournalDisabled = errors.New("commit journal is disabled")
+
+// CommitJournalEntry is a block committed to the canonical chain.
+type CommitJournalEntry struct {
+	Seq        hexutil.Uint64   `json:"seq"`
+	Number     hexutil.Uint64   `json:"number"`
+	Hash       common.Hash      `json:"hash"`
+	ParentHash common.Hash      `json:"parentHash"`
+	Timestamp  autapi.Timestamp `json:"timestamp"`
+}
+
+// CommitJournalPage is the result of aut_commitJournal.
+type CommitJournalPage struct {
+	Entries []*CommitJournalEntry `json:"entries"`
+	// FirstSeq is the sequence number of the oldest entry kept, the entries before it were pruned.
+	FirstSeq hexutil.Uint64 `json:"firstSeq"`
+	// NextSeq is the sequence number of the next entry to be journaled.
+	NextSeq hexutil.Uint64 `json:"nextSeq"`
+}
+
+// PublicCommitJournalAPI serves the journal of the committed blocks under the aut namespace.
+type PublicCommitJournalAPI struct {
+	journal *core.CommitJournal // nil if disabled
+}
+
+// NewPublicCommitJournalAPI creates a new commit journal API instance.
+func NewPublicCommitJournalAPI(journal *core.CommitJournal) *PublicCommitJournalAPI {
+	return &PublicCommitJournalAPI{journal: journal}
+}
+
+// CommitJournal returns up to limit journal entries following afterSeq, 1024 at most. A consumer
+// resumes from the sequence number of the last entry it processed, zero to read from the start.
+// A first entry with a sequence number above afterSeq+1 means that the entries in between were pruned.
+func (api *PublicCommitJournalAPI) CommitJournal(afterSeq hexutil.Uint64, limit *hexutil.Uint64) (*CommitJournalPage, error) {
+	if api.journal == nil {
+		return nil, errCommitJournalDisabled
+	}
+	n := maxCommitJournalEntries
+	if limit != nil && uint64(*limit) < maxCommitJournalEntries {
+		n = int(*limit)
+	}
+	// the range is read last, so that the entries returned are all below NextSeq
+	entries := api.journal.Entries(uint64(afterSeq), n)
+	first, next := api.journal.Range()
+	page := &CommitJournalPage{
+		Entries:  make([]*CommitJournalEntry, 0, len(entries)),
+		FirstSeq: hexutil.Uint64(first),
+		NextSeq:  hexutil.Uint64(next),
+	}
+	for _, entry := range entries {
+		page.Entries = append(page.Entries, &CommitJournalEntry{
+			Seq:        hexutil.Uint64(entry.Seq),
+			Number:     hexutil.Uint64(entry.Number),
+			Hash:       entry.Hash,
+			ParentHash: entry.ParentHash,
+			Timestamp:  autapi.UnixTimestamp(entry.Time),
+		})
+	}
+	return page, nil
+}
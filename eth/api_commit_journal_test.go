@@ -0,0 +1,61 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/eth/autapi"
+)
+
+func TestCommitJournalAPI(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	// entries 1 to 100 were pruned
+	for seq := uint64(101); seq <= 2100; seq++ {
+		rawdb.WriteCommitJournalEntry(db, &rawdb.CommitJournalEntry{
+			Seq:        seq,
+			Number:     seq + 1000,
+			Hash:       common.BigToHash(new(big.Int).SetUint64(seq)),
+			ParentHash: common.BigToHash(new(big.Int).SetUint64(seq - 1)),
+			Time:       seq * 10,
+		})
+	}
+	rawdb.WriteCommitJournalRange(db, 101, 2101)
+	api := NewPublicCommitJournalAPI(core.NewCommitJournal(db, 10000, 0))
+
+	limit := hexutil.Uint64(3)
+	page, err := api.CommitJournal(150, &limit)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(101), page.FirstSeq)
+	require.Equal(t, hexutil.Uint64(2101), page.NextSeq)
+	require.Len(t, page.Entries, 3)
+	for i, entry := range page.Entries {
+		seq := uint64(151 + i)
+		require.Equal(t, hexutil.Uint64(seq), entry.Seq)
+		require.Equal(t, hexutil.Uint64(seq+1000), entry.Number)
+		require.Equal(t, autapi.UnixTimestamp(seq*10), entry.Timestamp)
+	}
+
+	// the pruned entries are skipped
+	page, err = api.CommitJournal(0, &limit)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(101), page.Entries[0].Seq)
+
+	// the number of entries is capped
+	page, err = api.CommitJournal(0, nil)
+	require.NoError(t, err)
+	require.Len(t, page.Entries, maxCommitJournalEntries)
+
+	// a consumer up to date gets no entry
+	page, err = api.CommitJournal(2100, nil)
+	require.NoError(t, err)
+	require.Empty(t, page.Entries)
+
+	_, err = NewPublicCommitJournalAPI(nil).CommitJournal(0, nil)
+	require.ErrorIs(t, err, errCommitJournalDisabled)
+}
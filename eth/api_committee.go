@@ -0,0 +1,145 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+)
+
+var errNegativeStake = errors.New("extra stake cannot be negative")
+
+// validatorSetReader reads the inputs of the committee selection from the protocol contract.
+type validatorSetReader interface {
+	// ValidatorSet returns the validators, in the order of the contract validator list, and the maximum committee size.
+	ValidatorSet() ([]*autonity.AutonityValidator, uint64, error)
+}
+
+// chainValidatorSet reads the validator set from the state at the head of the chain.
+type chainValidatorSet struct {
+	chain *core.BlockChain
+}
+
+func (r *chainValidatorSet) ValidatorSet() ([]*autonity.AutonityValidator, uint64, error) {
+	header := r.chain.CurrentHeader()
+	state, err := r.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, 0, err
+	}
+	contracts := r.chain.ProtocolContracts()
+	validators, err := contracts.Validators(header, state)
+	if err != nil {
+		return nil, 0, err
+	}
+	maxCommitteeSize, err := contracts.MaxCommitteeSize(header, state)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !maxCommitteeSize.IsUint64() {
+		return nil, 0, errors.New("invalid max committee size")
+	}
+	return validators, maxCommitteeSize.Uint64(), nil
+}
+
+// CommitteeEligibility is the result of aut_estimateCommitteeEligibility.
+type CommitteeEligibility struct {
+	// Selected is true if the validator would be part of the committee.
+	Selected bool `json:"selected"`
+	// Rank is the 1-based position of the validator in the committee selection order,
+	// zero if the validator does not take part in the selection.
+	Rank hexutil.Uint64 `json:"rank"`
+	// BondedStake is the hypothetical bonded stake of the validator.
+	BondedStake *hexutil.Big `json:"bondedStake"`
+	// MinimumStake is the minimum bonded stake for the validator to be selected, all the
+	// other stakes being equal. It is nil if the validator cannot be selected whatever its stake.
+	MinimumStake *hexutil.Big `json:"minimumStake"`
+	// CommitteeSize is the size of the resulting committee.
+	CommitteeSize hexutil.Uint64 `json:"committeeSize"`
+}
+
+// EstimateCommitteeEligibility estimates whether the validator would be elected in the committee if the
+// committee was selected now with extraStake additional stake bonded to it. The validator set and stakes
+// are read from the protocol contract at head. A non-registered address is treated as a new validator,
+// registered after the existing ones.
+func (api *PublicAutonityAPI) EstimateCommitteeEligibility(address common.Address, extraStake *hexutil.Big) (*CommitteeEligibility, error) {
+	extra := new(big.Int)
+	if extraStake != nil {
+		extra = extraStake.ToInt()
+	}
+	if extra.Sign() < 0 {
+		return nil, errNegativeStake
+	}
+	validators, maxCommitteeSize, err := api.validators.ValidatorSet()
+	if err != nil {
+		return nil, err
+	}
+	return estimateCommitteeEligibility(validators, maxCommitteeSize, address, extra), nil
+}
+
+// estimateCommitteeEligibility runs the committee selection of the protocol with the stake of the
+// given validator increased by extra.
+func estimateCommitteeEligibility(validators []*autonity.AutonityValidator, maxCommitteeSize uint64, address common.Address, extra *big.Int) *CommitteeEligibility {
+	var (
+		// the list position breaks ties between equal stakes
+		position   = make(map[common.Address]int, len(validators)+1)
+		candidates = make([]*types.CommitteeMember, 0, len(validators)+1)
+		others     = make([]*types.CommitteeMember, 0, len(validators))
+		stake      = new(big.Int).Set(extra)
+		active     = true
+	)
+	for i, validator := range validators {
+		position[validator.NodeAddress] = i
+		validatorStake, state := validator.BondedStake, big.NewInt(int64(validator.State))
+		if validator.NodeAddress == address {
+			stake.Add(stake, validatorStake)
+			active = state.Cmp(big.NewInt(vm.ActiveState)) == 0
+			validatorStake = stake
+		}
+		if !vm.IsCommitteeCandidate(validatorStake, state) {
+			continue
+		}
+		member := &types.CommitteeMember{Address: validator.NodeAddress, VotingPower: validatorStake}
+		candidates = append(candidates, member)
+		if validator.NodeAddress != address {
+			others = append(others, member)
+		}
+	}
+	if _, ok := position[address]; !ok {
+		// a new validator is appended to the validator list
+		position[address] = len(validators)
+		if vm.IsCommitteeCandidate(stake, big.NewInt(vm.ActiveState)) {
+			candidates = append(candidates, &types.CommitteeMember{Address: address, VotingPower: stake})
+		}
+	}
+
+	result := &CommitteeEligibility{BondedStake: (*hexutil.Big)(stake)}
+	committee := vm.SelectCommittee(candidates, maxCommitteeSize)
+	result.CommitteeSize = hexutil.Uint64(len(committee))
+	for i, member := range candidates {
+		if member.Address == address {
+			result.Rank = hexutil.Uint64(i + 1)
+			result.Selected = i < len(committee)
+			break
+		}
+	}
+
+	if !active || maxCommitteeSize == 0 {
+		return result
+	}
+	// the stake is sufficient if it ranks the validator before the last member of the committee elected without it
+	minimum := big.NewInt(vm.StakeThreshold + 1)
+	if othersCommittee := vm.SelectCommittee(others, maxCommitteeSize); uint64(len(othersCommittee)) == maxCommitteeSize {
+		last := othersCommittee[len(othersCommittee)-1]
+		minimum = new(big.Int).Set(last.VotingPower)
+		if position[address] > position[last.Address] {
+			minimum.Add(minimum, common.Big1)
+		}
+	}
+	result.MinimumStake = (*hexutil.Big)(minimum)
+	return result
+}
@@ -0,0 +1,103 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/rpc"
+)
+
+var (
+	errCommitteeBlockNotFound = errors.New("block not found")
+	errNoPendingBlock         = errors.New("no pending block available")
+)
+
+// committeeReader reads the committee of a block from its header, or from the protocol contract at its state.
+type committeeReader interface {
+	// Header returns the header of the block.
+	Header(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error)
+	// ContractCommittee returns the committee of the protocol contract at the state of the block of header.
+	ContractCommittee(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, header *types.Header) (types.Committee, error)
+}
+
+// apiCommitteeReader reads the committee through the API backend, the pending block from the miner.
+type apiCommitteeReader struct {
+	backend *EthAPIBackend
+}
+
+func (r *apiCommitteeReader) Header(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		block, _ := r.backend.eth.miner.Pending()
+		if block == nil {
+			return nil, errNoPendingBlock
+		}
+		return block.Header(), nil
+	}
+	header, err := r.backend.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errCommitteeBlockNotFound
+	}
+	return header, nil
+}
+
+func (r *apiCommitteeReader) ContractCommittee(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, header *types.Header) (types.Committee, error) {
+	var statedb *state.StateDB
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		// the pending state is not committed, it is only known by the miner
+		block, pending := r.backend.eth.miner.Pending()
+		if block == nil || pending == nil {
+			return nil, errNoPendingBlock
+		}
+		header, statedb = block.Header(), pending
+	} else {
+		var err error
+		if statedb, err = r.backend.eth.protocolState.At(header); err != nil {
+			return nil, fmt.Errorf("committee not embedded in the header of block %d and its state is not available, it may be pruned: %w", header.Number.Uint64(), err)
+		}
+	}
+	committee, err := r.backend.eth.BlockChain().ProtocolContracts().Committee(header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	return committee, statedb.Error()
+}
+
+// PublicCommitteeAPI serves the committee of the past blocks under the aut namespace.
+type PublicCommitteeAPI struct {
+	reader committeeReader
+}
+
+// NewPublicCommitteeAPI creates a new committee API instance.
+func NewPublicCommitteeAPI(reader committeeReader) *PublicCommitteeAPI {
+	return &PublicCommitteeAPI{reader: reader}
+}
+
+// GetCommittee returns the committee at the given block, latest if omitted: the addresses, voting power and
+// consensus keys of the members. It is read from the block header, or from the protocol contract at the state
+// of the block if the header does not embed it. It supersedes the view of the protocol contract of the same
+// name, which only serves the committee at the latest block.
+func (api *PublicCommitteeAPI) GetCommittee(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) ([]*autapi.CommitteeMember, error) {
+	query := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		query = *blockNrOrHash
+	}
+	header, err := api.reader.Header(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(header.Committee) > 0 {
+		return autapi.NewCommittee(header.Committee), nil
+	}
+	committee, err := api.reader.ContractCommittee(ctx, query, header)
+	if err != nil {
+		return nil, err
+	}
+	return autapi.NewCommittee(committee), nil
+}
@@ -0,0 +1,124 @@
+package eth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/accounts/abi/bind/backends"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+)
+
+// newCommitteeTestChain creates a dev chain of n blocks on top of the genesis, which deploys the protocol
+// contracts. Only the genesis header embeds the committee, the headers of the blocks do not.
+func newCommitteeTestChain(t *testing.T, n int) *core.BlockChain {
+	gspec := &core.Genesis{Config: params.TestChainConfig, Mixhash: types.BFTDigest}
+	genDB := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(genDB)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), genDB, n, nil)
+
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil, &core.TxSenderCacher{}, nil, backends.NewInternalBackend(nil), log.Root())
+	require.NoError(t, err)
+	t.Cleanup(chain.Stop)
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	return chain
+}
+
+// contractCommittee calls the protocol contract for the committee at the state of the block.
+func contractCommittee(t *testing.T, chain *core.BlockChain, header *types.Header) types.Committee {
+	statedb, err := chain.StateAt(header.Root)
+	require.NoError(t, err)
+	committee, err := chain.ProtocolContracts().Committee(header, statedb)
+	require.NoError(t, err)
+	return committee
+}
+
+// requireSameCommittee compares the committees as served over RPC.
+func requireSameCommittee(t *testing.T, expected types.Committee, actual []*autapi.CommitteeMember) {
+	require.NotEmpty(t, actual)
+	require.Equal(t, autapi.NewCommittee(expected), actual)
+}
+
+func TestGetCommittee(t *testing.T) {
+	chain := newCommitteeTestChain(t, 3)
+	reader := &apiCommitteeReader{backend: &EthAPIBackend{eth: &Ethereum{blockchain: chain, protocolState: &protocolState{chain: chain}}}}
+	api := NewPublicCommitteeAPI(reader)
+	ctx := context.Background()
+	genesis := chain.GetHeaderByNumber(0)
+	head := chain.CurrentHeader()
+
+	t.Run("epoch boundary block", func(t *testing.T) {
+		require.NotEmpty(t, genesis.Committee)
+		number := rpc.BlockNumberOrHashWithNumber(0)
+		committee, err := api.GetCommittee(ctx, &number)
+		require.NoError(t, err)
+		requireSameCommittee(t, genesis.Committee, committee)
+		requireSameCommittee(t, contractCommittee(t, chain, genesis), committee)
+
+		hash := rpc.BlockNumberOrHashWithHash(genesis.Hash(), true)
+		committee, err = api.GetCommittee(ctx, &hash)
+		require.NoError(t, err)
+		requireSameCommittee(t, genesis.Committee, committee)
+	})
+
+	t.Run("latest", func(t *testing.T) {
+		require.Empty(t, head.Committee)
+		expected := contractCommittee(t, chain, head)
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		committee, err := api.GetCommittee(ctx, &latest)
+		require.NoError(t, err)
+		requireSameCommittee(t, expected, committee)
+
+		// the block defaults to latest
+		committee, err = api.GetCommittee(ctx, nil)
+		require.NoError(t, err)
+		requireSameCommittee(t, expected, committee)
+	})
+
+	t.Run("pruned state", func(t *testing.T) {
+		header := types.CopyHeader(head)
+		header.Committee = nil
+		header.Root = common.Hash{1}
+		_, err := reader.ContractCommittee(ctx, rpc.BlockNumberOrHashWithHash(head.Hash(), false), header)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "may be pruned")
+	})
+
+	t.Run("unknown block", func(t *testing.T) {
+		number := rpc.BlockNumberOrHashWithNumber(100)
+		_, err := api.GetCommittee(ctx, &number)
+		require.ErrorIs(t, err, errCommitteeBlockNotFound)
+
+		hash := rpc.BlockNumberOrHashWithHash(common.Hash{1}, false)
+		_, err = api.GetCommittee(ctx, &hash)
+		require.Error(t, err)
+	})
+
+	t.Run("supersedes the contract view", func(t *testing.T) {
+		server := rpc.NewServer()
+		defer server.Stop()
+		require.NoError(t, server.RegisterName("aut", NewAutonityContractAPI(chain, chain.ProtocolContracts())))
+		require.NoError(t, server.RegisterName("aut", api))
+		client := rpc.DialInProc(server)
+		defer client.Close()
+
+		var committee []*autapi.CommitteeMember
+		require.NoError(t, client.Call(&committee, "aut_getCommittee"))
+		requireSameCommittee(t, contractCommittee(t, chain, head), committee)
+		require.NoError(t, client.Call(&committee, "aut_getCommittee", "0x0"))
+		requireSameCommittee(t, genesis.Committee, committee)
+	})
+}
@@ -0,0 +1,128 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/rpc"
+)
+
+// CommitteeChangeEvent is posted when the committee of the chain head differs from the one of the
+// previous head.
+type CommitteeChangeEvent struct {
+	Committee []*autapi.CommitteeMember `json:"committee"`
+	// EpochBlock is the head block the new committee was first seen at.
+	EpochBlock hexutil.Uint64 `json:"epochBlock"`
+	// Joined and Left tell whether the local node entered or exited the committee.
+	Joined bool `json:"joined"`
+	Left   bool `json:"left"`
+}
+
+// sameCommittee returns whether both committees have the same members, in the same order and with
+// the same voting power and consensus key.
+func sameCommittee(a, b types.Committee) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Address != b[i].Address ||
+			a[i].VotingPower.Cmp(b[i].VotingPower) != 0 ||
+			!bytes.Equal(a[i].ConsensusKeyBytes, b[i].ConsensusKeyBytes) {
+			return false
+		}
+	}
+	return true
+}
+
+// committeeChanges detects the committee changes along the chain heads and posts them to the
+// subscribers.
+type committeeChanges struct {
+	self common.Address
+	feed event.Feed
+
+	mu        sync.Mutex
+	committee types.Committee // committee of the last head, nil before the first one
+	member    bool            // whether the local node is part of it
+}
+
+func newCommitteeChanges(self common.Address) *committeeChanges {
+	return &committeeChanges{self: self}
+}
+
+// observe records the committee of a new chain head. A change is posted unless the head is the
+// first one observed.
+func (c *committeeChanges) observe(header *types.Header) {
+	c.mu.Lock()
+	previous, wasMember := c.committee, c.member
+	c.committee, c.member = header.Committee, header.CommitteeMember(c.self) != nil
+	member := c.member
+	c.mu.Unlock()
+
+	if previous == nil || sameCommittee(previous, header.Committee) {
+		return
+	}
+	c.feed.Send(CommitteeChangeEvent{
+		Committee:  autapi.NewCommittee(header.Committee),
+		EpochBlock: hexutil.Uint64(header.Number.Uint64()),
+		Joined:     member && !wasMember,
+		Left:       !member && wasMember,
+	})
+}
+
+// SubscribeCommitteeChangeEvent registers a subscription to the committee changes.
+func (c *committeeChanges) SubscribeCommitteeChangeEvent(ch chan<- CommitteeChangeEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// committeeChangeBackend provides the committee changes.
+type committeeChangeBackend interface {
+	SubscribeCommitteeChangeEvent(ch chan<- CommitteeChangeEvent) event.Subscription
+}
+
+// PublicCommitteeChangeAPI serves the committee change notifications under the aut namespace.
+type PublicCommitteeChangeAPI struct {
+	backend committeeChangeBackend
+}
+
+// NewPublicCommitteeChangeAPI creates a new committee change API instance.
+func NewPublicCommitteeChangeAPI(backend committeeChangeBackend) *PublicCommitteeChangeAPI {
+	return &PublicCommitteeChangeAPI{backend: backend}
+}
+
+// CommitteeChange creates a subscription that fires each time the committee of the chain head
+// differs from the one of the previous head, with the new committee and whether the local node
+// joined or left it.
+func (api *PublicCommitteeChangeAPI) CommitteeChange(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	// subscribed before returning, so that no change is missed past the subscription
+	changes := make(chan CommitteeChangeEvent)
+	changesSub := api.backend.SubscribeCommitteeChangeEvent(changes)
+
+	go func() {
+		defer changesSub.Unsubscribe()
+
+		for {
+			select {
+			case change := <-changes:
+				notifier.Notify(rpcSub.ID, change)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
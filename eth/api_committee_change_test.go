@@ -0,0 +1,75 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/node"
+	"github.com/autonity/autonity/rpc"
+)
+
+func TestCommitteeChangeSubscription(t *testing.T) {
+	self := common.Address{0xaa}
+	member := func(address common.Address, power int64) types.CommitteeMember {
+		return types.CommitteeMember{Address: address, VotingPower: big.NewInt(power), ConsensusKeyBytes: address.Bytes()}
+	}
+	var (
+		outside = types.Committee{member(common.Address{1}, 1), member(common.Address{2}, 1)}
+		joined  = types.Committee{member(common.Address{1}, 1), member(self, 2)}
+		repower = types.Committee{member(common.Address{1}, 3), member(self, 2)}
+		left    = types.Committee{member(common.Address{1}, 3)}
+	)
+	changes := newCommitteeChanges(self)
+	head := func(number uint64, committee types.Committee) *types.Header {
+		return &types.Header{Number: new(big.Int).SetUint64(number), Committee: committee}
+	}
+	// the first head observed is no change
+	changes.observe(head(0, outside))
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	apis := []rpc.API{{Namespace: "aut", Service: NewPublicCommitteeChangeAPI(changes), Public: true}}
+	require.NoError(t, node.RegisterApis(apis, []string{"aut"}, server, false))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events := make(chan CommitteeChangeEvent)
+	sub, err := client.Subscribe(ctx, "aut", events, "committeeChange")
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	next := func() CommitteeChangeEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatal("no committee change notified")
+		}
+		return CommitteeChangeEvent{}
+	}
+
+	// the chain advances with the same committee, then the local node joins
+	changes.observe(head(1, outside))
+	changes.observe(head(2, joined))
+	require.Equal(t, CommitteeChangeEvent{Committee: autapi.NewCommittee(joined), EpochBlock: hexutil.Uint64(2), Joined: true}, next())
+
+	// a voting power change is a committee change
+	changes.observe(head(3, repower))
+	require.Equal(t, CommitteeChangeEvent{Committee: autapi.NewCommittee(repower), EpochBlock: hexutil.Uint64(3)}, next())
+
+	changes.observe(head(4, repower))
+	changes.observe(head(5, left))
+	require.Equal(t, CommitteeChangeEvent{Committee: autapi.NewCommittee(left), EpochBlock: hexutil.Uint64(5), Left: true}, next())
+}
@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+)
+
+// staticValidatorSet serves a fixed validator set.
+type staticValidatorSet struct {
+	validators       []*autonity.AutonityValidator
+	maxCommitteeSize uint64
+}
+
+func (s *staticValidatorSet) ValidatorSet() ([]*autonity.AutonityValidator, uint64, error) {
+	return s.validators, s.maxCommitteeSize, nil
+}
+
+// newTestValidatorSet returns 50 active validators, validators 2k and 2k+1 having the same stake of
+// 1000-10k, apart from validator 2 which only has a stake of 100.
+func newTestValidatorSet() []*autonity.AutonityValidator {
+	validators := make([]*autonity.AutonityValidator, 50)
+	for i := range validators {
+		validators[i] = &autonity.AutonityValidator{
+			NodeAddress: common.BigToAddress(big.NewInt(int64(i + 1))),
+			BondedStake: big.NewInt(int64(1000 - 10*(i/2))),
+		}
+	}
+	validators[2].BondedStake = big.NewInt(100)
+	return validators
+}
+
+func TestEstimateCommitteeEligibility(t *testing.T) {
+	validators := newTestValidatorSet()
+	newValidator := common.HexToAddress("0xabcdef")
+
+	tests := []struct {
+		name             string
+		address          common.Address
+		extra            int64
+		maxCommitteeSize uint64
+		selected         bool
+		rank             uint64
+		minimum          *big.Int
+		committeeSize    uint64
+	}{
+		{"committee member", validators[0].NodeAddress, 0, 30, true, 1, big.NewInt(850), 30},
+		{"just under the minimum", validators[31].NodeAddress, 0, 30, false, 31, big.NewInt(851), 30},
+		{"just at the minimum", validators[31].NodeAddress, 1, 30, true, 30, big.NewInt(851), 30},
+		{"tie won by list order", validators[2].NodeAddress, 750, 30, true, 30, big.NewInt(850), 30},
+		{"tie lost by list order", validators[2].NodeAddress, 749, 30, false, 32, big.NewInt(850), 30},
+		{"new validator on a tie", newValidator, 850, 30, false, 32, big.NewInt(851), 30},
+		{"new validator at the minimum", newValidator, 851, 30, true, 30, big.NewInt(851), 30},
+		{"new validator without stake", newValidator, 0, 30, false, 0, big.NewInt(851), 30},
+		{"committee larger than the validator set", newValidator, 1, 100, true, 51, big.NewInt(1), 51},
+		{"empty committee", validators[0].NodeAddress, 0, 0, false, 1, nil, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := estimateCommitteeEligibility(validators, test.maxCommitteeSize, test.address, big.NewInt(test.extra))
+			require.Equal(t, test.selected, result.Selected)
+			require.Equal(t, hexutil.Uint64(test.rank), result.Rank)
+			require.Equal(t, (*hexutil.Big)(test.minimum), result.MinimumStake)
+			require.Equal(t, hexutil.Uint64(test.committeeSize), result.CommitteeSize)
+		})
+	}
+
+	t.Run("inactive validator", func(t *testing.T) {
+		jailed := newTestValidatorSet()
+		jailed[3].State = 2
+		result := estimateCommitteeEligibility(jailed, 30, jailed[3].NodeAddress, big.NewInt(1000))
+		require.False(t, result.Selected)
+		require.Zero(t, result.Rank)
+		require.Nil(t, result.MinimumStake)
+		require.Equal(t, hexutil.Uint64(30), result.CommitteeSize)
+	})
+
+	t.Run("selected iff the stake reaches the minimum", func(t *testing.T) {
+		for _, validator := range validators {
+			for _, extra := range []int64{0, 5, 10, 50, 500} {
+				result := estimateCommitteeEligibility(validators, 30, validator.NodeAddress, big.NewInt(extra))
+				require.Equal(t, result.BondedStake.ToInt().Cmp(result.MinimumStake.ToInt()) >= 0, result.Selected)
+			}
+		}
+	})
+
+	t.Run("input is not modified", func(t *testing.T) {
+		estimateCommitteeEligibility(validators, 30, validators[31].NodeAddress, big.NewInt(1000))
+		require.Equal(t, newTestValidatorSet(), validators)
+	})
+}
+
+func TestEstimateCommitteeEligibilityAPI(t *testing.T) {
+	validators := newTestValidatorSet()
+	api := NewPublicAutonityAPI(nil, &staticValidatorSet{validators: validators, maxCommitteeSize: 30})
+
+	result, err := api.EstimateCommitteeEligibility(validators[31].NodeAddress, nil)
+	require.NoError(t, err)
+	require.False(t, result.Selected)
+	require.Equal(t, (*hexutil.Big)(big.NewInt(850)), result.BondedStake)
+
+	_, err = api.EstimateCommitteeEligibility(validators[31].NodeAddress, (*hexutil.Big)(big.NewInt(-1)))
+	require.ErrorIs(t, err, errNegativeStake)
+}
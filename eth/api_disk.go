@@ -0,0 +1,48 @@
+package eth
+
+import (
+	"errors"
+
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core"
+)
+
+var errDiskWatchdogDisabled = errors.New("disk watchdog is disabled")
+
+// DiskStatus is the result of aut_diskStatus.
+type DiskStatus struct {
+	// Health is one of healthy, degraded when the non-essential writes are paused, or critical when
+	// the block import is suspended.
+	Health string `json:"health"`
+	// Available is the lowest free space of the chain database and freezer paths, in bytes.
+	Available     hexutil.Uint64 `json:"available"`
+	SoftThreshold hexutil.Uint64 `json:"softThreshold"`
+	HardThreshold hexutil.Uint64 `json:"hardThreshold"`
+	// Paused are the writers paused by the watchdog.
+	Paused []string `json:"paused"`
+}
+
+// PublicDiskAPI serves the state of the disk watchdog under the aut namespace.
+type PublicDiskAPI struct {
+	watchdog *core.DiskWatchdog // nil if disabled
+}
+
+// NewPublicDiskAPI creates a new disk API instance.
+func NewPublicDiskAPI(watchdog *core.DiskWatchdog) *PublicDiskAPI {
+	return &PublicDiskAPI{watchdog: watchdog}
+}
+
+// DiskStatus returns the disk health and the writers paused as of the last free space check.
+func (api *PublicDiskAPI) DiskStatus() (*DiskStatus, error) {
+	if api.watchdog == nil {
+		return nil, errDiskWatchdogDisabled
+	}
+	status := api.watchdog.Status()
+	return &DiskStatus{
+		Health:        status.Health.String(),
+		Available:     hexutil.Uint64(status.Available),
+		SoftThreshold: hexutil.Uint64(status.SoftThreshold),
+		HardThreshold: hexutil.Uint64(status.HardThreshold),
+		Paused:        status.Paused,
+	}, nil
+}
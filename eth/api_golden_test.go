@@ -0,0 +1,263 @@
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/autonity"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/params/generated"
+)
+
+var updateGolden = flag.Bool("update", false, "regenerate the golden files of the aut responses")
+
+// goldenCommittee is the committee of the golden responses.
+var goldenCommittee = types.Committee{
+	{Address: common.HexToAddress("0xbc79932ad81cf46daa9841e0bd6871aab7cfba76"), VotingPower: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether)), ConsensusKeyBytes: common.FromHex("0xa1b2c3")},
+	{Address: common.HexToAddress("0x5a443704dd4b594b382c22a083e2bd3090a6fef3"), VotingPower: big.NewInt(750), ConsensusKeyBytes: common.FromHex("0xd4e5f6")},
+}
+
+func uint64Ptr(v uint64) *hexutil.Uint64 {
+	encoded := hexutil.Uint64(v)
+	return &encoded
+}
+
+// goldenContractValidator returns the result of getValidator as unpacked out of the contract output.
+func goldenContractValidator(t *testing.T) interface{} {
+	outputs := generated.AutonityAbi.Methods["getValidator"].Outputs
+	packed, err := outputs.Pack(autonity.AutonityValidator{
+		Treasury:                 common.HexToAddress("0x850a7e3e4b6d1c0b8b5b1e7c5ea8f1dd1ca5f6d2"),
+		NodeAddress:              goldenCommittee[0].Address,
+		OracleAddress:            common.HexToAddress("0x47e9fbef8c83a1714f1951f142132e6e90f5fa5d"),
+		Enode:                    "enode://c746ded15b4fa7e398a8925d8a2e4c76d9fc8007eb8a6b8ad408a18bf66266b9d03dd9aa26c902a4ac02eb465d205c0c58b6f5063963fc752806f2681287a915@127.0.0.1:30303",
+		CommissionRate:           big.NewInt(1000),
+		BondedStake:              goldenCommittee[0].VotingPower,
+		UnbondingStake:           new(big.Int),
+		UnbondingShares:          new(big.Int),
+		SelfBondedStake:          goldenCommittee[0].VotingPower,
+		SelfUnbondingStake:       new(big.Int),
+		SelfUnbondingShares:      new(big.Int),
+		SelfUnbondingStakeLocked: new(big.Int),
+		LiquidContract:           common.HexToAddress("0xf4d9599afd90b5038b18e3b551bc21a97ed21c37"),
+		LiquidSupply:             goldenCommittee[0].VotingPower,
+		RegistrationBlock:        new(big.Int),
+		TotalSlashed:             big.NewInt(12),
+		JailReleaseBlock:         new(big.Int),
+		ProvableFaultCount:       big.NewInt(1),
+		ConsensusKey:             goldenCommittee[0].ConsensusKeyBytes,
+		State:                    0,
+	})
+	require.NoError(t, err)
+	unpacked, err := outputs.Unpack(packed)
+	require.NoError(t, err)
+	require.Len(t, unpacked, 1)
+	return unpacked[0]
+}
+
+// TestAutResponseGolden pins the JSON encoding of every aut namespace response, see the compatibility policy of
+// the autapi package.
+func TestAutResponseGolden(t *testing.T) {
+	var (
+		hash      = common.HexToHash("0x63db9b1f86e6e1a2e5a1b7c1d7fb0a7f3c2e1d0c9b8a7f6e5d4c3b2a1911cf5f")
+		txHash    = common.HexToHash("0x0d4c6cc5a4b4d2a9bd0ad2f8e4dd4b4a3f1e2a0c9d8b7a6f5e4d3c2b1a098765")
+		offender  = goldenCommittee[1].Address
+		timestamp = autapi.Timestamp(time.Date(2024, 3, 1, 10, 0, 0, 500000000, time.UTC))
+		audit     = &AccountabilityAudit{
+			OffenceHeight:    1200,
+			CommitteeHash:    hash,
+			TotalVotingPower: (*hexutil.Big)(goldenCommittee.TotalVotingPower()),
+			QuorumThreshold:  (*hexutil.Big)(big.NewInt(667)),
+			DeltaBlocks:      10,
+			RulesVersion:     1,
+		}
+		gasStats = AccountabilityGasStats{
+			Accusation:   PrecompileGasStats{Calls: 2, GasUsed: 64000},
+			Innocence:    PrecompileGasStats{Calls: 1, Failures: 1, GasUsed: 30000},
+			Misbehaviour: PrecompileGasStats{},
+		}
+	)
+	responses := map[string]interface{}{
+		"node_info": &NodeInfo{
+			ClientVersion:             "Autonity/v1.0.0/linux-amd64/go1.21",
+			ConsensusEngine:           "tendermint",
+			Methods:                   []string{"aut_getCommittee", "aut_nodeInfo"},
+			ContractVersion:           (*hexutil.Big)(big.NewInt(1)),
+			ContractCodeHash:          hash,
+			ChainID:                   (*hexutil.Big)(big.NewInt(65000000)),
+			NetworkID:                 65000000,
+			Network:                   "piccadilly",
+			AccountabilityDeltaBlocks: 10,
+			ConsensusParticipant:      true,
+		},
+		"participation": &Participation{
+			From: 100,
+			To:   100,
+			Blocks: []*BlockParticipation{{
+				Number:  100,
+				Hash:    hash,
+				Round:   1,
+				Indices: []hexutil.Uint64{0},
+				Signers: []autapi.Address{autapi.Address(goldenCommittee[0].Address)},
+			}},
+			Presence: map[autapi.Address]*ValidatorPresence{
+				autapi.Address(goldenCommittee[0].Address): {Committee: 1, Signed: 1},
+				autapi.Address(goldenCommittee[1].Address): {Committee: 1, Signed: 0},
+			},
+		},
+		"proposal_size": &ProposalSize{Cap: 4 << 20, Limit: 3 << 20},
+		"committee_eligibility": &CommitteeEligibility{
+			Selected:      true,
+			Rank:          2,
+			BondedStake:   (*hexutil.Big)(big.NewInt(750)),
+			MinimumStake:  (*hexutil.Big)(big.NewInt(501)),
+			CommitteeSize: 2,
+		},
+		"certificate": []*QuorumCertificateResult{
+			{Valid: true, SignedPower: (*hexutil.Big)(goldenCommittee.TotalVotingPower()), RequiredPower: (*hexutil.Big)(big.NewInt(667))},
+			{Valid: false, Reason: "unknown block"},
+		},
+		"commit_journal": &CommitJournalPage{
+			Entries:  []*CommitJournalEntry{{Seq: 151, Number: 1151, Hash: hash, ParentHash: txHash, Timestamp: timestamp}},
+			FirstSeq: 101,
+			NextSeq:  2101,
+		},
+		"accountability_gas": &AccountabilityGasRange{
+			IndexedFrom: 10,
+			Blocks:      []*AccountabilityGasBlock{{Number: 1210, Hash: hash, AccountabilityGasStats: gasStats}},
+			Total:       gasStats,
+		},
+		"accountability_events": &AccountabilityEventsPage{
+			Entries: []*AccountabilityJournalEntry{{
+				Seq:       1,
+				Timestamp: timestamp,
+				Action:    "submitted",
+				Type:      "Fault Proof",
+				Rule:      "PO",
+				Offender:  autapi.Address(offender),
+				ProofHash: hash,
+				Audit:     audit,
+			}},
+			FirstSeq: 1,
+			NextSeq:  2,
+			Pending: []*AccountabilitySubmission{{
+				Type:      "Accusation",
+				Rule:      "PVN",
+				Offender:  autapi.Address(offender),
+				Chunk:     1,
+				Chunks:    3,
+				Deadline:  1300,
+				TxHash:    txHash,
+				ProofHash: hash,
+			}},
+		},
+		"committee": autapi.NewCommittee(goldenCommittee),
+		"fault_detector": &FaultDetectorStatus{
+			MsgStoreMode:       "digest",
+			FirstHeight:        40,
+			Heights:            60,
+			Digests:            2500,
+			Pending:            1,
+			IncrementalRescans: 3,
+			DroppedStale:       4,
+			CappedRescans:      5,
+			DroppedOverQuota:   12,
+		},
+		"message_arrivals": &MessageArrivals{
+			Start:     timestamp,
+			Proposal:  []*hexutil.Uint64{uint64Ptr(0), nil},
+			Prevote:   []*hexutil.Uint64{uint64Ptr(120), uint64Ptr(135)},
+			Precommit: []*hexutil.Uint64{uint64Ptr(250), nil},
+		},
+		"submission_cap": &AccountabilitySubmissionCap{PerHeight: 4, PerEpoch: 64, Queued: 2},
+		"health_disk": &DiskStatus{
+			Health:        "degraded",
+			Available:     8 << 30,
+			SoftThreshold: 10 << 30,
+			HardThreshold: 2 << 30,
+			Paused:        []string{"txindexer"},
+		},
+		"health_validator": &ValidatorStatus{
+			Evaluated:               true,
+			BlockNumber:             1210,
+			InCommittee:             true,
+			MiningActive:            true,
+			CommitteeSize:           2,
+			ConnectedConsensusPeers: 1,
+		},
+		"health_state": &StateHealth{
+			Health:     "critical",
+			Phase:      "resyncing",
+			Mismatches: 0,
+			Threshold:  3,
+			DivergedAt: 1210,
+			LastError:  "invalid merkle root (remote: 63db9b1f local: 0d4c6cc5)",
+			AutoResync: true,
+			RewoundTo:  1200,
+			Deadline:   &timestamp,
+			Recoveries: 1,
+		},
+		"committee_change": &CommitteeChangeEvent{Committee: autapi.NewCommittee(goldenCommittee), EpochBlock: 1800, Joined: true},
+		"stake_concentration": func() *StakeConcentration {
+			result := stakeConcentration(goldenCommittee)
+			result.BlockNumber, result.BlockHash = 1210, hash
+			return result
+		}(),
+		"accountability_notification": &AccountabilityNotification{
+			Event:            "SlashingEvent",
+			ID:               7,
+			Offender:         autapi.Address(offender),
+			Type:             "Fault Proof",
+			Rule:             "PO",
+			OffenceHeight:    1200,
+			Severity:         (*hexutil.Big)(big.NewInt(2)),
+			SlashedAmount:    (*hexutil.Big)(big.NewInt(75)),
+			JailReleaseBlock: uint64Ptr(9000),
+			JailPeriod:       uint64Ptr(7790),
+			BlockNumber:      1210,
+			BlockHash:        hash,
+			TxHash:           txHash,
+			LogIndex:         3,
+		},
+		"registration": &RegistrationReport{
+			NodeAddress: (*autapi.Address)(&goldenCommittee[0].Address),
+			Failures:    []RegistrationIssue{{Check: "enode", Reason: "already registered", Hint: "use another node key"}},
+			Warnings:    []RegistrationIssue{},
+		},
+		"contract_validator": autapi.ContractValue(goldenContractValidator(t)),
+	}
+
+	dir := filepath.Join("testdata", "autapi", fmt.Sprintf("v%d", autapi.ResponseVersion))
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	for name, response := range responses {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := json.MarshalIndent(response, "", "  ")
+			require.NoError(t, err)
+			encoded = append(encoded, '\n')
+			file := filepath.Join(dir, name+".json")
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(file, encoded, 0644))
+				return
+			}
+			golden, err := os.ReadFile(file)
+			require.NoError(t, err, "missing golden file, regenerate with -update")
+			if !bytes.Equal(golden, encoded) {
+				t.Fatalf("the encoding of %s changed, see the compatibility policy of the autapi package\nwant:\n%s\ngot:\n%s", name, golden, encoded)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+)
+
+// nodeInfoNamespaces are the namespaces of the consensus methods listed by aut_nodeInfo.
+var nodeInfoNamespaces = []string{"aut", "tendermint", "debug"}
+
+// nodeInfoChainReader is the subset of the blockchain methods needed by aut_nodeInfo.
+type nodeInfoChainReader interface {
+	Config() *params.ChainConfig
+	CurrentHeader() *types.Header
+}
+
+// protocolInfoReader reads the version and the code hash of the protocol contract.
+type protocolInfoReader interface {
+	ProtocolInfo(header *types.Header) (*big.Int, common.Hash, error)
+}
+
+// chainProtocolInfo reads the protocol contract from the state at the given header.
+type chainProtocolInfo struct {
+	chain *core.BlockChain
+}
+
+func (r *chainProtocolInfo) ProtocolInfo(header *types.Header) (*big.Int, common.Hash, error) {
+	state, err := r.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	version, err := r.chain.ProtocolContracts().Version(header, state)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return version, state.GetCodeHash(params.AutonityContractAddress), nil
+}
+
+// NodeInfo is the result of aut_nodeInfo.
+type NodeInfo struct {
+	// ClientVersion is the version of the client software.
+	ClientVersion string `json:"clientVersion"`
+	// ConsensusEngine is the consensus engine run by the node.
+	ConsensusEngine string `json:"consensusEngine"`
+	// Methods are the aut, tendermint and debug methods callable on the serving endpoint.
+	Methods []string `json:"methods"`
+	// ContractVersion is the version of the protocol contract at head.
+	ContractVersion *hexutil.Big `json:"contractVersion"`
+	// ContractCodeHash is the code hash of the protocol contract at head.
+	ContractCodeHash common.Hash    `json:"contractCodeHash"`
+	ChainID          *hexutil.Big   `json:"chainId"`
+	NetworkID        hexutil.Uint64 `json:"networkId"`
+	// Network is the official Autonity network of both the chain ID and the network ID, empty if none.
+	Network string `json:"network,omitempty"`
+	// AccountabilityDeltaBlocks is the number of blocks awaited before accounting for a height.
+	AccountabilityDeltaBlocks hexutil.Uint64 `json:"accountabilityDeltaBlocks"`
+	// ConsensusParticipant is true if the node is a member of the committee at head.
+	ConsensusParticipant bool `json:"consensusParticipant"`
+}
+
+// PublicNodeInfoAPI exposes the capabilities of the node under the aut namespace.
+type PublicNodeInfoAPI struct {
+	chain     nodeInfoChainReader
+	protocol  protocolInfoReader
+	networkID uint64
+	address   common.Address
+}
+
+// NewPublicNodeInfoAPI creates a new node info API instance.
+func NewPublicNodeInfoAPI(chain nodeInfoChainReader, protocol protocolInfoReader, networkID uint64, address common.Address) *PublicNodeInfoAPI {
+	return &PublicNodeInfoAPI{chain: chain, protocol: protocol, networkID: networkID, address: address}
+}
+
+// NodeInfo returns the Autonity specific capabilities of the node. The listed methods are those
+// registered on the endpoint serving the request, the namespaces disabled on it are left out.
+func (api *PublicNodeInfoAPI) NodeInfo(ctx context.Context) (*NodeInfo, error) {
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errors.New("no current header")
+	}
+	version, codeHash, err := api.protocol.ProtocolInfo(header)
+	if err != nil {
+		return nil, err
+	}
+	methods := rpc.MethodsFromContext(ctx, nodeInfoNamespaces...)
+	if methods == nil {
+		methods = []string{}
+	}
+	chainID := api.chain.Config().ChainID
+	var network string
+	if official := params.AutonityNetworkByNetworkID(api.networkID); official != nil && chainID != nil &&
+		chainID.IsUint64() && official.ChainID == chainID.Uint64() {
+		network = official.Name
+	}
+	return &NodeInfo{
+		ClientVersion:             params.VersionWithMeta,
+		ConsensusEngine:           "tendermint",
+		Methods:                   methods,
+		ContractVersion:           (*hexutil.Big)(version),
+		ContractCodeHash:          codeHash,
+		ChainID:                   (*hexutil.Big)(chainID),
+		NetworkID:                 hexutil.Uint64(api.networkID),
+		Network:                   network,
+		AccountabilityDeltaBlocks: accountability.DeltaBlocks,
+		ConsensusParticipant:      header.CommitteeMember(api.address) != nil,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/node"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+)
+
+type fakeProtocolInfo struct {
+	version  *big.Int
+	codeHash common.Hash
+}
+
+func (f *fakeProtocolInfo) ProtocolInfo(*types.Header) (*big.Int, common.Hash, error) {
+	return f.version, f.codeHash, nil
+}
+
+// fakeConsensusAPI stands for the consensus engine APIs registered under the tendermint and debug namespaces.
+type fakeConsensusAPI struct{}
+
+func (fakeConsensusAPI) GetCommittee() []common.Address { return nil }
+
+func (fakeConsensusAPI) GetCoreState() string { return "" }
+
+func TestNodeInfo(t *testing.T) {
+	chain, _ := newTestHeaderChain(t, 4, 10, 0)
+	protocol := &fakeProtocolInfo{version: big.NewInt(2), codeHash: common.Hash{0xaa}}
+	apis := []rpc.API{
+		{Namespace: "aut", Service: NewPublicNodeInfoAPI(chain, protocol, 65111111, common.BytesToAddress([]byte{1})), Public: true},
+		{Namespace: "aut", Service: NewPublicAutonityAPI(chain, nil), Public: true},
+		{Namespace: "tendermint", Service: fakeConsensusAPI{}, Public: true},
+		{Namespace: "debug", Service: fakeConsensusAPI{}},
+		{Namespace: "eth", Service: fakeConsensusAPI{}, Public: true},
+	}
+
+	tests := []struct {
+		name     string
+		modules  []string
+		enabled  []string
+		disabled []string
+	}{
+		{
+			name:     "aut only",
+			modules:  []string{"aut", "eth"},
+			enabled:  []string{"aut"},
+			disabled: []string{"tendermint", "debug"},
+		},
+		{
+			name:    "aut, tendermint and debug",
+			modules: []string{"aut", "tendermint", "debug"},
+			enabled: []string{"aut", "tendermint", "debug"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := rpc.NewServer()
+			defer server.Stop()
+			require.NoError(t, node.RegisterApis(apis, test.modules, server, false))
+			client := rpc.DialInProc(server)
+			defer client.Close()
+
+			var info NodeInfo
+			require.NoError(t, client.Call(&info, "aut_nodeInfo"))
+			require.Equal(t, params.VersionWithMeta, info.ClientVersion)
+			require.Equal(t, "tendermint", info.ConsensusEngine)
+			require.Equal(t, protocol.version, info.ContractVersion.ToInt())
+			require.Equal(t, protocol.codeHash, info.ContractCodeHash)
+			require.Equal(t, params.TestChainConfig.ChainID, info.ChainID.ToInt())
+			require.Equal(t, uint64(65111111), uint64(info.NetworkID))
+			require.Empty(t, info.Network)
+			require.Equal(t, uint64(accountability.DeltaBlocks), uint64(info.AccountabilityDeltaBlocks))
+			require.True(t, info.ConsensusParticipant)
+
+			// the listing is made of the enabled namespaces only
+			require.Contains(t, info.Methods, "aut_nodeInfo")
+			require.Contains(t, info.Methods, "aut_getParticipation")
+			listed := make(map[string]bool)
+			for _, method := range info.Methods {
+				namespace := strings.SplitN(method, "_", 2)[0]
+				require.Contains(t, test.enabled, namespace, "method %s", method)
+				listed[method] = true
+			}
+			for _, namespace := range test.enabled {
+				if namespace != "aut" {
+					require.True(t, listed[namespace+"_getCommittee"], "namespace %s", namespace)
+					require.True(t, listed[namespace+"_getCoreState"], "namespace %s", namespace)
+				}
+			}
+
+			// every listed method is callable, the methods of the disabled namespaces are not
+			for method := range listed {
+				require.False(t, isMethodNotFound(client.Call(nil, method)), "method %s not callable", method)
+			}
+			for _, namespace := range test.disabled {
+				require.True(t, isMethodNotFound(client.Call(nil, namespace+"_getCommittee")), "namespace %s", namespace)
+			}
+		})
+	}
+
+	t.Run("non participant", func(t *testing.T) {
+		api := NewPublicNodeInfoAPI(chain, protocol, 1, common.Address{0xff})
+		info, err := api.NodeInfo(context.Background())
+		require.NoError(t, err)
+		require.False(t, info.ConsensusParticipant)
+		require.Empty(t, info.Methods)
+	})
+}
+
+// configuredHeaderReader serves the headers of the chain with another chain configuration.
+type configuredHeaderReader struct {
+	*rawHeaderReader
+	config *params.ChainConfig
+}
+
+func (r *configuredHeaderReader) Config() *params.ChainConfig { return r.config }
+
+func TestNodeInfoNetwork(t *testing.T) {
+	headers, _ := newTestHeaderChain(t, 4, 1, 0)
+	chain := &configuredHeaderReader{rawHeaderReader: headers, config: params.PiccadillyChainConfig}
+	protocol := &fakeProtocolInfo{version: big.NewInt(1)}
+	piccadilly := params.PiccadillyChainConfig.ChainID.Uint64()
+
+	// the official network is reported if both the chain ID and the network ID are the ones of the network
+	info, err := NewPublicNodeInfoAPI(chain, protocol, piccadilly, common.Address{}).NodeInfo(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "piccadilly", info.Network)
+	require.Equal(t, piccadilly, info.ChainID.ToInt().Uint64())
+	require.Equal(t, piccadilly, uint64(info.NetworkID))
+
+	info, err = NewPublicNodeInfoAPI(chain, protocol, 65_000_000, common.Address{}).NodeInfo(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, info.Network)
+	require.Equal(t, uint64(65_000_000), uint64(info.NetworkID))
+}
+
+func isMethodNotFound(err error) bool {
+	rpcErr, ok := err.(rpc.Error)
+	return ok && rpcErr.ErrorCode() == -32601
+}
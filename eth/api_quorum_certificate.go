@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/rlp"
+)
+
+// maxQuorumCertificateBatch is the maximum number of certificates verified by a single
+// aut_verifyQuorumCertificates call.
+const maxQuorumCertificateBatch = 256
+
+var errQuorumCertificateBatchTooLarge = fmt.Errorf("too many quorum certificates, maximum is %d", maxQuorumCertificateBatch)
+
+// QuorumCertificateArgs is a quorum certificate to verify against the local chain.
+type QuorumCertificateArgs struct {
+	// BlockNumber is the number of the block certified.
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// Certificate is the RLP encoded quorum certificate, as found in the block header.
+	Certificate hexutil.Bytes `json:"certificate"`
+}
+
+// QuorumCertificateResult is the verification result of a single quorum certificate.
+type QuorumCertificateResult struct {
+	Valid bool `json:"valid"`
+	// Reason is the cause of the verification failure.
+	Reason string `json:"reason,omitempty"`
+	// SignedPower is the voting power of the distinct signers, nil if they cannot be resolved.
+	SignedPower *hexutil.Big `json:"signedPower"`
+	// RequiredPower is the quorum of the committee of the block, nil if it cannot be loaded.
+	RequiredPower *hexutil.Big `json:"requiredPower"`
+}
+
+// VerifyQuorumCertificates verifies externally supplied quorum certificates against the local chain. Each
+// certificate must aggregate the precommits for the local block at its height, from members of the committee
+// of the parent block holding a quorum of its voting power. The certificates are verified concurrently and a
+// result is returned for each of them, in the order of the arguments. Blocks are read both from the freezer
+// and the key-value store.
+func (api *PublicAutonityAPI) VerifyQuorumCertificates(ctx context.Context, certificates []QuorumCertificateArgs) ([]*QuorumCertificateResult, error) {
+	if len(certificates) > maxQuorumCertificateBatch {
+		return nil, errQuorumCertificateBatchTooLarge
+	}
+	results := make([]*QuorumCertificateResult, len(certificates))
+	indices := make(chan int)
+	workers := min(runtime.NumCPU(), len(certificates))
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = api.verifyQuorumCertificate(ctx, certificates[i])
+			}
+		}()
+	}
+	for i := range certificates {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (api *PublicAutonityAPI) verifyQuorumCertificate(ctx context.Context, args QuorumCertificateArgs) *QuorumCertificateResult {
+	number := uint64(args.BlockNumber)
+	if number == 0 {
+		return &QuorumCertificateResult{Reason: "the genesis block has no quorum certificate"}
+	}
+	if number > api.chain.CurrentHeader().Number.Uint64() {
+		return &QuorumCertificateResult{Reason: fmt.Sprintf("block #%d not found", number)}
+	}
+	var parent, header *types.Header
+	it := api.chain.HeaderRangeIterator(ctx, number-1, number)
+	for it.Next() {
+		parent, header = header, it.Header()
+	}
+	if err := it.Error(); err != nil {
+		return &QuorumCertificateResult{Reason: err.Error()}
+	}
+	if parent == nil {
+		return &QuorumCertificateResult{Reason: fmt.Sprintf("block #%d not found", number)}
+	}
+
+	var certificate types.AggregateSignature
+	if err := rlp.DecodeBytes(args.Certificate, &certificate); err != nil {
+		return &QuorumCertificateResult{Reason: fmt.Sprintf("malformed quorum certificate: %v", err)}
+	}
+	signed, required, err := message.VerifyQuorumCertificate(certificate, header.Hash(), int64(header.Round), header.Number, parent.Committee)
+	result := &QuorumCertificateResult{
+		Valid:         err == nil,
+		SignedPower:   (*hexutil.Big)(signed),
+		RequiredPower: (*hexutil.Big)(required),
+	}
+	if err != nil {
+		result.Reason = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/ethdb/memorydb"
+	"github.com/autonity/autonity/rlp"
+)
+
+// testEpoch is a committee and the consensus keys of its members.
+type testEpoch struct {
+	committee types.Committee
+	keys      []blst.SecretKey
+}
+
+func newTestEpoch(t *testing.T, size int) *testEpoch {
+	epoch := &testEpoch{committee: make(types.Committee, size), keys: make([]blst.SecretKey, size)}
+	for i := range epoch.committee {
+		key, err := blst.RandKey()
+		require.NoError(t, err)
+		epoch.keys[i] = key
+		epoch.committee[i] = types.CommitteeMember{
+			Address:           common.BytesToAddress(key.PublicKey().Marshal()),
+			VotingPower:       big.NewInt(int64(i + 1)),
+			ConsensusKeyBytes: key.PublicKey().Marshal(),
+			ConsensusKey:      key.PublicKey(),
+			Index:             uint64(i),
+		}
+	}
+	return epoch
+}
+
+// certify returns the RLP encoded quorum certificate of the header signed by the given members of the epoch.
+func (e *testEpoch) certify(t *testing.T, header *types.Header, members ...int) hexutil.Bytes {
+	seal := message.PrepareCommittedSeal(header.Hash(), int64(header.Round), header.Number)
+	signers := types.NewSigners(len(e.committee))
+	var signatures []blst.Signature
+	for _, i := range members {
+		signers.Increment(&e.committee[i])
+		signatures = append(signatures, e.keys[i].Sign(seal[:]))
+	}
+	certificate := types.NewAggregateSignature(blst.AggregateSignatures(signatures).(*blst.BlsSignature), signers)
+	encoded, err := rlp.EncodeToBytes(&certificate)
+	require.NoError(t, err)
+	return encoded
+}
+
+// newTestEpochChain creates a chain of n+1 headers whose committee switches from the first epoch to the
+// second one at the given height. The first `frozen` headers are moved to the freezer.
+func newTestEpochChain(t *testing.T, epochs [2]*testEpoch, switchAt, n, frozen uint64) (*rawHeaderReader, []*types.Header) {
+	db, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), t.TempDir(), "", false)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	headers := make([]*types.Header, n+1)
+	for i := uint64(0); i <= n; i++ {
+		committee := epochs[0].committee
+		if i >= switchAt {
+			committee = epochs[1].committee
+		}
+		headers[i] = &types.Header{
+			Number:     new(big.Int).SetUint64(i),
+			Difficulty: big.NewInt(1),
+			MixDigest:  types.BFTDigest,
+			Time:       i,
+			Round:      i % 2,
+			Committee:  committee,
+		}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+	}
+
+	var blocks []*types.Block
+	var receipts []types.Receipts
+	for _, header := range headers[:frozen] {
+		blocks = append(blocks, types.NewBlockWithHeader(header))
+		receipts = append(receipts, nil)
+	}
+	_, err = rawdb.WriteAncientBlocks(db, blocks, receipts, big.NewInt(1))
+	require.NoError(t, err)
+	for _, header := range headers[frozen:] {
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+	return &rawHeaderReader{db: db, head: headers[n]}, headers
+}
+
+func TestVerifyQuorumCertificates(t *testing.T) {
+	// the voting powers are 1, 2, 3 and 4, for a quorum of 7
+	epochs := [2]*testEpoch{newTestEpoch(t, 4), newTestEpoch(t, 4)}
+	chain, headers := newTestEpochChain(t, epochs, 20, 30, 10)
+	api := NewPublicAutonityAPI(chain, nil)
+
+	// the committee of a block is the one of its parent
+	valid := QuorumCertificateArgs{BlockNumber: 5, Certificate: epochs[0].certify(t, headers[5], 0, 1, 2, 3)}
+	wrongEpoch := QuorumCertificateArgs{BlockNumber: 25, Certificate: epochs[0].certify(t, headers[25], 0, 1, 2, 3)}
+	insufficientPower := QuorumCertificateArgs{BlockNumber: 15, Certificate: epochs[0].certify(t, headers[15], 0, 1, 2)}
+	malformed := QuorumCertificateArgs{BlockNumber: 15, Certificate: hexutil.Bytes{0xc4, 0x82, 0x01, 0x02, 0x80}}
+
+	t.Run("valid certificates across freezer, epoch switch and key-value store", func(t *testing.T) {
+		args := []QuorumCertificateArgs{
+			valid,
+			{BlockNumber: 20, Certificate: epochs[0].certify(t, headers[20], 1, 2, 3)},
+			{BlockNumber: 21, Certificate: epochs[1].certify(t, headers[21], 0, 2, 3)},
+		}
+		results, err := api.VerifyQuorumCertificates(context.Background(), args)
+		require.NoError(t, err)
+		require.Len(t, results, len(args))
+		for _, result := range results {
+			require.True(t, result.Valid, result.Reason)
+			require.Empty(t, result.Reason)
+			require.Equal(t, int64(7), result.RequiredPower.ToInt().Int64())
+		}
+		require.Equal(t, int64(10), results[0].SignedPower.ToInt().Int64())
+		require.Equal(t, int64(9), results[1].SignedPower.ToInt().Int64())
+		require.Equal(t, int64(8), results[2].SignedPower.ToInt().Int64())
+	})
+
+	t.Run("wrong epoch committee", func(t *testing.T) {
+		results, err := api.VerifyQuorumCertificates(context.Background(), []QuorumCertificateArgs{wrongEpoch})
+		require.NoError(t, err)
+		require.False(t, results[0].Valid)
+		require.Equal(t, message.ErrQuorumCertificateSignature.Error(), results[0].Reason)
+	})
+
+	t.Run("insufficient voting power", func(t *testing.T) {
+		results, err := api.VerifyQuorumCertificates(context.Background(), []QuorumCertificateArgs{insufficientPower})
+		require.NoError(t, err)
+		require.False(t, results[0].Valid)
+		require.Equal(t, message.ErrQuorumCertificatePower.Error(), results[0].Reason)
+		require.Equal(t, int64(6), results[0].SignedPower.ToInt().Int64())
+		require.Equal(t, int64(7), results[0].RequiredPower.ToInt().Int64())
+	})
+
+	t.Run("malformed certificate", func(t *testing.T) {
+		results, err := api.VerifyQuorumCertificates(context.Background(), []QuorumCertificateArgs{malformed})
+		require.NoError(t, err)
+		require.False(t, results[0].Valid)
+		require.Contains(t, results[0].Reason, "malformed quorum certificate")
+		require.Nil(t, results[0].SignedPower)
+	})
+
+	t.Run("signature over another block", func(t *testing.T) {
+		args := []QuorumCertificateArgs{{BlockNumber: 6, Certificate: epochs[0].certify(t, headers[5], 0, 1, 2, 3)}}
+		results, err := api.VerifyQuorumCertificates(context.Background(), args)
+		require.NoError(t, err)
+		require.False(t, results[0].Valid)
+		require.Equal(t, message.ErrQuorumCertificateSignature.Error(), results[0].Reason)
+	})
+
+	t.Run("unknown blocks", func(t *testing.T) {
+		args := []QuorumCertificateArgs{{BlockNumber: 0, Certificate: valid.Certificate}, {BlockNumber: 31, Certificate: valid.Certificate}}
+		results, err := api.VerifyQuorumCertificates(context.Background(), args)
+		require.NoError(t, err)
+		require.False(t, results[0].Valid)
+		require.False(t, results[1].Valid)
+		require.Equal(t, "block #31 not found", results[1].Reason)
+	})
+
+	t.Run("batch mixing all the cases", func(t *testing.T) {
+		var args []QuorumCertificateArgs
+		for i := 0; i < 16; i++ {
+			args = append(args, valid, wrongEpoch, insufficientPower, malformed)
+		}
+		results, err := api.VerifyQuorumCertificates(context.Background(), args)
+		require.NoError(t, err)
+		require.Len(t, results, len(args))
+		for i := 0; i < len(results); i += 4 {
+			require.True(t, results[i].Valid)
+			require.Equal(t, message.ErrQuorumCertificateSignature.Error(), results[i+1].Reason)
+			require.Equal(t, message.ErrQuorumCertificatePower.Error(), results[i+2].Reason)
+			require.Contains(t, results[i+3].Reason, "malformed quorum certificate")
+		}
+	})
+
+	t.Run("batch too large", func(t *testing.T) {
+		args := make([]QuorumCertificateArgs, maxQuorumCertificateBatch+1)
+		_, err := api.VerifyQuorumCertificates(context.Background(), args)
+		require.ErrorIs(t, err, errQuorumCertificateBatchTooLarge)
+	})
+}
@@ -0,0 +1,288 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/autonity/autonity/accounts"
+	"github.com/autonity/autonity/accounts/abi"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// registrationProbeTimeout bounds the dial of the enode of the validator when probing its reachability.
+const registrationProbeTimeout = 5 * time.Second
+
+// The checks reported by aut_validateRegistration.
+const (
+	registrationCheckTreasury     = "treasury"
+	registrationCheckEnode        = "enode"
+	registrationCheckRegistered   = "registered"
+	registrationCheckProof        = "proof"
+	registrationCheckConsensusKey = "consensusKey"
+	registrationCheckNodePOP      = "nodeKeyProof"
+	registrationCheckOraclePOP    = "oracleKeyProof"
+	registrationCheckConsensusPOP = "consensusKeyProof"
+	registrationCheckFunds        = "funds"
+	registrationCheckStake        = "stake"
+	registrationCheckContract     = "contract"
+	registrationCheckReachability = "reachability"
+)
+
+const hintOwnershipProof = "regenerate the proof with `autonity genOwnershipProof` for this treasury address, " +
+	"using the node key and the oracle key of the validator"
+
+// registrationReader reads the protocol state the registration of a validator depends on.
+type registrationReader interface {
+	// Registered returns true if a validator is already registered with the node address.
+	Registered(node common.Address) (bool, error)
+	// Balances returns the Auton and the Newton balances of the account.
+	Balances(account common.Address) (*big.Int, *big.Int, error)
+	// DryRun executes registerValidator from the treasury account on top of the head state, without
+	// committing it. It returns the revert reason, empty if the registration goes through.
+	DryRun(treasury common.Address, enode string, oracle common.Address, consensusKey, signatures []byte) (string, error)
+}
+
+// chainRegistration reads the registration state from the state at the head of the chain.
+type chainRegistration struct {
+	chain *core.BlockChain
+}
+
+func (r *chainRegistration) Registered(node common.Address) (bool, error) {
+	header := r.chain.CurrentHeader()
+	state, err := r.chain.StateAt(header.Root)
+	if err != nil {
+		return false, err
+	}
+	// getValidator reverts for the addresses which are not registered
+	_, err = r.chain.ProtocolContracts().Validator(header, state, node)
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *chainRegistration) Balances(account common.Address) (*big.Int, *big.Int, error) {
+	header := r.chain.CurrentHeader()
+	state, err := r.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	var newton *big.Int
+	if err := r.chain.ProtocolContracts().AutonityContractCall(state, header, "balanceOf", &newton, account); err != nil {
+		return nil, nil, err
+	}
+	return state.GetBalance(account), newton, nil
+}
+
+func (r *chainRegistration) DryRun(treasury common.Address, enode string, oracle common.Address, consensusKey, signatures []byte) (string, error) {
+	header := r.chain.CurrentHeader()
+	state, err := r.chain.StateAt(header.Root)
+	if err != nil {
+		return "", err
+	}
+	contracts := r.chain.ProtocolContracts()
+	packedArgs, err := contracts.ABI().Pack("registerValidator", enode, oracle, consensusKey, signatures)
+	if err != nil {
+		return "", err
+	}
+	ret, err := contracts.CallContractFuncAs(state, header, treasury, packedArgs)
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		if reason, errUnpack := abi.UnpackRevert(ret); errUnpack == nil {
+			return reason, nil
+		}
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+// RegistrationParams are the arguments of a registerValidator transaction sent from the treasury account.
+type RegistrationParams struct {
+	Treasury     common.Address `json:"treasury"`
+	Enode        string         `json:"enode"`
+	Oracle       common.Address `json:"oracle"`
+	ConsensusKey hexutil.Bytes  `json:"consensusKey"`
+	Signatures   hexutil.Bytes  `json:"signatures"`
+	// Probe asks the node to dial the enode to check that it is reachable, if the node allows it.
+	Probe bool `json:"probe"`
+}
+
+// RegistrationIssue is a check of aut_validateRegistration which did not pass.
+type RegistrationIssue struct {
+	Check  string `json:"check"`
+	Reason string `json:"reason"`
+	Hint   string `json:"hint"`
+}
+
+// RegistrationReport is the result of aut_validateRegistration.
+type RegistrationReport struct {
+	// Valid is true if the registration would go through, in the state at head.
+	Valid bool `json:"valid"`
+	// NodeAddress is the address of the validator, derived from its enode. It is nil if the enode is malformed.
+	NodeAddress *autapi.Address `json:"nodeAddress"`
+	// Failures are the issues which make the registration revert.
+	Failures []RegistrationIssue `json:"failures"`
+	// Warnings are the issues which do not make the registration revert, but prevent the validator from
+	// taking part in consensus.
+	Warnings []RegistrationIssue `json:"warnings"`
+}
+
+func (r *RegistrationReport) fail(check, reason, hint string) {
+	r.Failures = append(r.Failures, RegistrationIssue{Check: check, Reason: reason, Hint: hint})
+}
+
+func (r *RegistrationReport) warn(check, reason, hint string) {
+	r.Warnings = append(r.Warnings, RegistrationIssue{Check: check, Reason: reason, Hint: hint})
+}
+
+// PublicRegistrationAPI checks validator registrations before they are sent, under the aut namespace.
+type PublicRegistrationAPI struct {
+	reader registrationReader
+	probe  bool
+	dial   func(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// NewPublicRegistrationAPI creates a new registration API instance. The reachability of the enodes is
+// only probed if probe is set.
+func NewPublicRegistrationAPI(reader registrationReader, probe bool) *PublicRegistrationAPI {
+	return &PublicRegistrationAPI{reader: reader, probe: probe, dial: net.DialTimeout}
+}
+
+// ValidateRegistration runs the checks of the registerValidator function of the protocol contract against
+// the state at head, without sending any transaction, and reports every check which does not pass.
+func (api *PublicRegistrationAPI) ValidateRegistration(params RegistrationParams) (*RegistrationReport, error) {
+	report := &RegistrationReport{Failures: []RegistrationIssue{}, Warnings: []RegistrationIssue{}}
+
+	if params.Treasury == (common.Address{}) {
+		report.fail(registrationCheckTreasury, "the treasury address is the zero address",
+			"send the registration from the treasury account of the validator")
+	}
+	node, err := enode.ParseV4NoResolve(params.Enode)
+	if err != nil {
+		report.fail(registrationCheckEnode, fmt.Sprintf("the enode is malformed: %v", err),
+			"use the enode printed by admin_nodeInfo, enode://<node public key>@<ip>:<port>")
+	} else {
+		nodeAddress := crypto.PubkeyToAddress(*node.Pubkey())
+		report.NodeAddress = (*autapi.Address)(&nodeAddress)
+		registered, err := api.reader.Registered(nodeAddress)
+		if err != nil {
+			return nil, err
+		}
+		if registered {
+			report.fail(registrationCheckRegistered, fmt.Sprintf("a validator is already registered with the node address %v", nodeAddress),
+				"a node key can only be registered once, use updateEnode to change the network address of a registered validator")
+		}
+		if node.IP() == nil || node.TCP() == 0 {
+			report.warn(registrationCheckEnode, "the enode has no IP address or TCP port",
+				"include the public IP address and the listening port of the node so that the other validators can reach it")
+		} else if params.Probe {
+			api.probeEnode(report, node)
+		}
+	}
+	api.checkProof(report, params, node)
+
+	atn, ntn, err := api.reader.Balances(params.Treasury)
+	if err != nil {
+		return nil, err
+	}
+	if atn.Sign() == 0 {
+		report.fail(registrationCheckFunds, "the treasury account has no Auton to pay for the registration transaction",
+			"fund the treasury account with Auton before sending the registration")
+	}
+	if ntn.Sign() == 0 {
+		report.warn(registrationCheckStake, "the treasury account has no Newton to bond to the validator",
+			"the registration needs no stake, but the validator is only eligible to the committee once Newton is bonded to it")
+	}
+
+	// the dry run catches the reverts the local checks do not cover, it would only repeat their failures otherwise
+	if len(report.Failures) == 0 {
+		reason, err := api.reader.DryRun(params.Treasury, params.Enode, params.Oracle, params.ConsensusKey, params.Signatures)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			report.fail(registrationCheckContract, fmt.Sprintf("the registration reverts: %s", reason),
+				"check the protocol contract requirements for the reported reason")
+		}
+	}
+	report.Valid = len(report.Failures) == 0
+	return report, nil
+}
+
+// checkProof verifies the ownership proofs of the node key, the oracle key and the consensus key, as the
+// protocol contract does. The node key proof is only checked if the enode is well-formed.
+func (api *PublicRegistrationAPI) checkProof(report *RegistrationReport, params RegistrationParams, node *enode.Node) {
+	var key blst.PublicKey
+	if len(params.ConsensusKey) != blst.BLSPubkeyLength {
+		report.fail(registrationCheckConsensusKey, fmt.Sprintf("the consensus key is %d bytes long, %d expected", len(params.ConsensusKey), blst.BLSPubkeyLength),
+			"use the consensus public key printed by `ethkey autinspect` for the node key file")
+	} else {
+		var err error
+		if key, err = blst.PublicKeyFromBytes(params.ConsensusKey); err != nil {
+			report.fail(registrationCheckConsensusKey, fmt.Sprintf("the consensus key is invalid: %v", err),
+				"use the consensus public key printed by `ethkey autinspect` for the node key file")
+		}
+	}
+	if len(params.Signatures) != crypto.AutonityPOPLen {
+		report.fail(registrationCheckProof, fmt.Sprintf("the ownership proof is %d bytes long, %d expected", len(params.Signatures), crypto.AutonityPOPLen),
+			hintOwnershipProof)
+		return
+	}
+	hash, _ := accounts.TextAndHash(params.Treasury.Bytes())
+	if node != nil {
+		pub, err := crypto.SigToPub(hash, normalizeRecoveryID(params.Signatures[:crypto.SignatureLength]))
+		if err != nil || !pub.Equal(node.Pubkey()) {
+			report.fail(registrationCheckNodePOP, "the node key ownership proof does not match the enode of the validator", hintOwnershipProof)
+		}
+	}
+	oracle, err := crypto.SigToAddr(hash, normalizeRecoveryID(params.Signatures[crypto.SignatureLength:2*crypto.SignatureLength]))
+	if err != nil || oracle != params.Oracle {
+		report.fail(registrationCheckOraclePOP, "the oracle key ownership proof does not match the oracle address", hintOwnershipProof)
+	}
+	if key == nil {
+		return
+	}
+	sig, err := blst.SignatureFromBytes(params.Signatures[2*crypto.SignatureLength:])
+	if err != nil || sig.IsZero() || crypto.BLSPOPVerify(key, sig, params.Treasury.Bytes()) != nil {
+		report.fail(registrationCheckConsensusPOP, "the consensus key ownership proof does not match the consensus key", hintOwnershipProof)
+	}
+}
+
+// probeEnode dials the enode of the validator and reports it if it cannot be reached. The probe is reported
+// as skipped if the node does not allow it.
+func (api *PublicRegistrationAPI) probeEnode(report *RegistrationReport, node *enode.Node) {
+	if !api.probe {
+		report.warn(registrationCheckReachability, "the reachability probe is disabled on this node",
+			"run the check against a node started with --rpc.registrationprobe")
+		return
+	}
+	address := net.JoinHostPort(node.IP().String(), strconv.Itoa(node.TCP()))
+	conn, err := api.dial("tcp", address, registrationProbeTimeout)
+	if err != nil {
+		report.warn(registrationCheckReachability, fmt.Sprintf("the enode cannot be reached at %s: %v", address, err),
+			"start the node and open its listening port to the network before registering")
+		return
+	}
+	conn.Close()
+}
+
+// normalizeRecoveryID returns the signature with its recovery id in the [0, 1] range. The protocol contract
+// also accepts the [27, 28] range of the signatures made with eth_sign.
+func normalizeRecoveryID(sig []byte) []byte {
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		return sig
+	}
+	normalized := common.CopyBytes(sig)
+	normalized[crypto.RecoveryIDOffset] -= 27
+	return normalized
+}
@@ -0,0 +1,256 @@
+package eth
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+type fakeRegistrationReader struct {
+	registered map[common.Address]bool
+	atn, ntn   *big.Int
+	revert     string
+	dryRuns    int
+}
+
+func (f *fakeRegistrationReader) Registered(node common.Address) (bool, error) {
+	return f.registered[node], nil
+}
+
+func (f *fakeRegistrationReader) Balances(common.Address) (*big.Int, *big.Int, error) {
+	return f.atn, f.ntn, nil
+}
+
+func (f *fakeRegistrationReader) DryRun(common.Address, string, common.Address, []byte, []byte) (string, error) {
+	f.dryRuns++
+	return f.revert, nil
+}
+
+type registrationKeys struct {
+	node, oracle *ecdsa.PrivateKey
+	consensus    blst.SecretKey
+}
+
+func newRegistrationKeys(t *testing.T) registrationKeys {
+	nodeKey, consensusKey, err := crypto.GenAutonityKeys()
+	require.NoError(t, err)
+	oracleKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return registrationKeys{node: nodeKey, oracle: oracleKey, consensus: consensusKey}
+}
+
+func newRegistrationParams(t *testing.T, keys registrationKeys, treasury common.Address) RegistrationParams {
+	signatures, err := crypto.AutonityPOPProof(keys.node, keys.oracle, treasury.Hex(), keys.consensus)
+	require.NoError(t, err)
+	return RegistrationParams{
+		Treasury:     treasury,
+		Enode:        enode.NewV4(&keys.node.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303).URLv4(),
+		Oracle:       crypto.PubkeyToAddress(keys.oracle.PublicKey),
+		ConsensusKey: keys.consensus.PublicKey().Marshal(),
+		Signatures:   signatures,
+	}
+}
+
+func newRegistrationReader() *fakeRegistrationReader {
+	return &fakeRegistrationReader{registered: map[common.Address]bool{}, atn: big.NewInt(1e18), ntn: big.NewInt(1000)}
+}
+
+// registrationChecks returns the checks of the issues, which must all come with a remediation hint.
+func registrationChecks(t *testing.T, issues []RegistrationIssue) []string {
+	checks := make([]string, len(issues))
+	for i, issue := range issues {
+		checks[i] = issue.Check
+		require.NotEmpty(t, issue.Hint, issue.Check)
+	}
+	return checks
+}
+
+func TestValidateRegistration(t *testing.T) {
+	treasury := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	keys := newRegistrationKeys(t)
+
+	t.Run("valid registration", func(t *testing.T) {
+		reader := newRegistrationReader()
+		params := newRegistrationParams(t, keys, treasury)
+		report, err := NewPublicRegistrationAPI(reader, false).ValidateRegistration(params)
+		require.NoError(t, err)
+		require.True(t, report.Valid)
+		require.Empty(t, report.Failures)
+		require.Empty(t, report.Warnings)
+		require.Equal(t, autapi.Address(crypto.PubkeyToAddress(keys.node.PublicKey)), *report.NodeAddress)
+		require.Equal(t, 1, reader.dryRuns)
+	})
+
+	t.Run("eth_sign recovery ids are accepted", func(t *testing.T) {
+		params := newRegistrationParams(t, keys, treasury)
+		params.Signatures[crypto.RecoveryIDOffset] += 27
+		params.Signatures[crypto.SignatureLength+crypto.RecoveryIDOffset] += 27
+		report, err := NewPublicRegistrationAPI(newRegistrationReader(), false).ValidateRegistration(params)
+		require.NoError(t, err)
+		require.True(t, report.Valid)
+	})
+
+	tests := []struct {
+		name     string
+		mutate   func(*RegistrationParams, *fakeRegistrationReader)
+		failures []string
+		warnings []string
+	}{
+		{
+			name:     "zero treasury",
+			mutate:   func(p *RegistrationParams, _ *fakeRegistrationReader) { p.Treasury = common.Address{} },
+			failures: []string{registrationCheckTreasury, registrationCheckNodePOP, registrationCheckOraclePOP, registrationCheckConsensusPOP},
+		},
+		{
+			name:     "malformed enode",
+			mutate:   func(p *RegistrationParams, _ *fakeRegistrationReader) { p.Enode = "enode://1234@127.0.0.1:30303" },
+			failures: []string{registrationCheckEnode},
+		},
+		{
+			name: "enode without endpoint",
+			mutate: func(p *RegistrationParams, _ *fakeRegistrationReader) {
+				p.Enode = enode.NewV4(&keys.node.PublicKey, nil, 0, 0).URLv4()
+			},
+			warnings: []string{registrationCheckEnode},
+		},
+		{
+			name: "already registered",
+			mutate: func(_ *RegistrationParams, r *fakeRegistrationReader) {
+				r.registered[crypto.PubkeyToAddress(keys.node.PublicKey)] = true
+			},
+			failures: []string{registrationCheckRegistered},
+		},
+		{
+			name: "short proof",
+			mutate: func(p *RegistrationParams, _ *fakeRegistrationReader) {
+				p.Signatures = p.Signatures[:crypto.AutonityPOPLen-1]
+			},
+			failures: []string{registrationCheckProof},
+		},
+		{
+			name:     "short consensus key",
+			mutate:   func(p *RegistrationParams, _ *fakeRegistrationReader) { p.ConsensusKey = p.ConsensusKey[1:] },
+			failures: []string{registrationCheckConsensusKey},
+		},
+		{
+			name: "invalid consensus key",
+			mutate: func(p *RegistrationParams, _ *fakeRegistrationReader) {
+				p.ConsensusKey = make([]byte, blst.BLSPubkeyLength)
+			},
+			failures: []string{registrationCheckConsensusKey},
+		},
+		{
+			name: "node key proof of another node",
+			mutate: func(p *RegistrationParams, _ *fakeRegistrationReader) {
+				other := newRegistrationKeys(t)
+				p.Enode = enode.NewV4(&other.node.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303).URLv4()
+			},
+			failures: []string{registrationCheckNodePOP},
+		},
+		{
+			name: "oracle key proof of another oracle",
+			mutate: func(p *RegistrationParams, _ *fakeRegistrationReader) {
+				p.Oracle = common.HexToAddress("0x2222222222222222222222222222222222222222")
+			},
+			failures: []string{registrationCheckOraclePOP},
+		},
+		{
+			name: "consensus key proof of another key",
+			mutate: func(p *RegistrationParams, _ *fakeRegistrationReader) {
+				p.ConsensusKey = newRegistrationKeys(t).consensus.PublicKey().Marshal()
+			},
+			failures: []string{registrationCheckConsensusPOP},
+		},
+		{
+			name: "zero consensus key proof",
+			mutate: func(p *RegistrationParams, _ *fakeRegistrationReader) {
+				copy(p.Signatures[2*crypto.SignatureLength:], make([]byte, blst.BLSSignatureLength))
+			},
+			failures: []string{registrationCheckConsensusPOP},
+		},
+		{
+			name:     "unfunded treasury",
+			mutate:   func(_ *RegistrationParams, r *fakeRegistrationReader) { r.atn = new(big.Int) },
+			failures: []string{registrationCheckFunds},
+		},
+		{
+			name:     "no stake",
+			mutate:   func(_ *RegistrationParams, r *fakeRegistrationReader) { r.ntn = new(big.Int) },
+			warnings: []string{registrationCheckStake},
+		},
+		{
+			name:     "contract revert",
+			mutate:   func(_ *RegistrationParams, r *fakeRegistrationReader) { r.revert = "invalid commission rate" },
+			failures: []string{registrationCheckContract},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := newRegistrationReader()
+			params := newRegistrationParams(t, keys, treasury)
+			tt.mutate(&params, reader)
+			report, err := NewPublicRegistrationAPI(reader, false).ValidateRegistration(params)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.failures) == 0, report.Valid)
+			require.ElementsMatch(t, tt.failures, registrationChecks(t, report.Failures))
+			require.ElementsMatch(t, tt.warnings, registrationChecks(t, report.Warnings))
+			// the dry run only runs once the local checks pass
+			if len(tt.failures) > 0 && tt.failures[0] != registrationCheckContract {
+				require.Zero(t, reader.dryRuns)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationProbe(t *testing.T) {
+	treasury := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	keys := newRegistrationKeys(t)
+	params := newRegistrationParams(t, keys, treasury)
+	params.Probe = true
+
+	t.Run("disabled", func(t *testing.T) {
+		report, err := NewPublicRegistrationAPI(newRegistrationReader(), false).ValidateRegistration(params)
+		require.NoError(t, err)
+		require.True(t, report.Valid)
+		require.Equal(t, []string{registrationCheckReachability}, registrationChecks(t, report.Warnings))
+	})
+
+	t.Run("reachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		port := listener.Addr().(*net.TCPAddr).Port
+		reachable := params
+		reachable.Enode = enode.NewV4(&keys.node.PublicKey, net.IP{127, 0, 0, 1}, port, port).URLv4()
+
+		report, err := NewPublicRegistrationAPI(newRegistrationReader(), true).ValidateRegistration(reachable)
+		require.NoError(t, err)
+		require.True(t, report.Valid)
+		require.Empty(t, report.Warnings)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		api := NewPublicRegistrationAPI(newRegistrationReader(), true)
+		var dialed string
+		api.dial = func(_, address string, _ time.Duration) (net.Conn, error) {
+			dialed = address
+			return nil, errors.New("connection refused")
+		}
+		report, err := api.ValidateRegistration(params)
+		require.NoError(t, err)
+		require.True(t, report.Valid)
+		require.Equal(t, "127.0.0.1:30303", dialed)
+		require.Equal(t, []string{registrationCheckReachability}, registrationChecks(t, report.Warnings))
+	})
+}
@@ -0,0 +1,167 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/rpc"
+)
+
+const (
+	// stakeConcentrationCacheSize is the number of blocks whose stake concentration is cached.
+	stakeConcentrationCacheSize = 128
+	// shareBasisPoints is the denominator of the power shares.
+	shareBasisPoints = 10000
+)
+
+// topShareMembers are the numbers of largest members whose combined voting power share is reported.
+var topShareMembers = []int{1, 3, 5, 10}
+
+// PowerShare is the combined voting power of the largest members of a committee.
+type PowerShare struct {
+	Members     hexutil.Uint64 `json:"members"`
+	VotingPower *hexutil.Big   `json:"votingPower"`
+	// ShareBps is the share of the total voting power, in basis points rounded down.
+	ShareBps hexutil.Uint64 `json:"shareBps"`
+}
+
+// StakeConcentration is the result of aut_stakeConcentration.
+type StakeConcentration struct {
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	CommitteeSize    hexutil.Uint64 `json:"committeeSize"`
+	TotalVotingPower *hexutil.Big   `json:"totalVotingPower"`
+	// LivenessCoefficient is the smallest number of members whose voting power exceeds 1/3 of the total,
+	// enough to halt the chain.
+	LivenessCoefficient hexutil.Uint64 `json:"livenessCoefficient"`
+	// SafetyCoefficient is the smallest number of members whose voting power exceeds 2/3 of the total,
+	// enough to finalize conflicting blocks.
+	SafetyCoefficient hexutil.Uint64 `json:"safetyCoefficient"`
+	// Gini is the Gini coefficient of the voting power, 0 for equal powers.
+	Gini      float64       `json:"gini"`
+	TopShares []*PowerShare `json:"topShares"`
+}
+
+// PublicStakeConcentrationAPI reports the concentration of the voting power of the committee under the aut
+// namespace, for the operators to monitor how close the network is to its safety and liveness thresholds.
+type PublicStakeConcentrationAPI struct {
+	reader committeeReader
+	cache  *lru.Cache // block hash -> *StakeConcentration
+}
+
+// NewPublicStakeConcentrationAPI creates a new stake concentration API instance.
+func NewPublicStakeConcentrationAPI(reader committeeReader) *PublicStakeConcentrationAPI {
+	cache, _ := lru.New(stakeConcentrationCacheSize)
+	return &PublicStakeConcentrationAPI{reader: reader, cache: cache}
+}
+
+// StakeConcentration returns the concentration of the voting power of the committee at the given block,
+// latest if omitted.
+func (api *PublicStakeConcentrationAPI) StakeConcentration(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*StakeConcentration, error) {
+	query := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		query = *blockNrOrHash
+	}
+	header, err := api.reader.Header(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// the pending block is not final, its hash does not identify its committee
+	number, pending := query.Number()
+	pending = pending && number == rpc.PendingBlockNumber
+	if cached, ok := api.cache.Get(header.Hash()); ok && !pending {
+		return cached.(*StakeConcentration), nil
+	}
+	committee := header.Committee
+	if len(committee) == 0 {
+		if committee, err = api.reader.ContractCommittee(ctx, query, header); err != nil {
+			return nil, err
+		}
+	}
+	result := stakeConcentration(committee)
+	result.BlockNumber, result.BlockHash = hexutil.Uint64(header.Number.Uint64()), header.Hash()
+	if !pending {
+		api.cache.Add(header.Hash(), result)
+	}
+	return result, nil
+}
+
+// stakeConcentration computes the concentration of the voting power of the committee.
+func stakeConcentration(committee types.Committee) *StakeConcentration {
+	powers := make([]*big.Int, len(committee))
+	for i := range committee {
+		powers[i] = committee[i].VotingPower
+	}
+	// largest first
+	sort.Slice(powers, func(i, j int) bool { return powers[i].Cmp(powers[j]) > 0 })
+	total := committee.TotalVotingPower()
+
+	result := &StakeConcentration{
+		CommitteeSize:       hexutil.Uint64(len(committee)),
+		TotalVotingPower:    (*hexutil.Big)(total),
+		LivenessCoefficient: hexutil.Uint64(nakamotoCoefficient(powers, total, 1, 3)),
+		SafetyCoefficient:   hexutil.Uint64(nakamotoCoefficient(powers, total, 2, 3)),
+		Gini:                giniCoefficient(powers, total),
+		TopShares:           make([]*PowerShare, 0, len(topShareMembers)),
+	}
+	cumulated := new(big.Int)
+	for i, power := range powers {
+		cumulated.Add(cumulated, power)
+		for _, members := range topShareMembers {
+			if members == i+1 {
+				result.TopShares = append(result.TopShares, &PowerShare{
+					Members:     hexutil.Uint64(members),
+					VotingPower: (*hexutil.Big)(new(big.Int).Set(cumulated)),
+					ShareBps:    hexutil.Uint64(share(cumulated, total)),
+				})
+			}
+		}
+	}
+	return result
+}
+
+// nakamotoCoefficient returns the smallest number of the largest powers whose sum exceeds num/den of the
+// total, 0 if there is none. The powers are sorted largest first.
+func nakamotoCoefficient(powers []*big.Int, total *big.Int, num, den int64) int {
+	threshold := new(big.Int).Mul(total, big.NewInt(num))
+	sum, scaled := new(big.Int), new(big.Int)
+	for i, power := range powers {
+		sum.Add(sum, power)
+		if scaled.Mul(sum, big.NewInt(den)).Cmp(threshold) > 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// giniCoefficient returns the Gini coefficient of the powers sorted largest first:
+// sum((2i - n - 1) * x_i) / (n * total), over the powers x_i in ascending order indexed from 1.
+func giniCoefficient(powers []*big.Int, total *big.Int) float64 {
+	n := int64(len(powers))
+	if n == 0 || total.Sign() == 0 {
+		return 0
+	}
+	numerator, term := new(big.Int), new(big.Int)
+	for i, power := range powers {
+		rank := n - int64(i) // rank in ascending order
+		term.Mul(big.NewInt(2*rank-n-1), power)
+		numerator.Add(numerator, term)
+	}
+	gini, _ := new(big.Rat).SetFrac(numerator, new(big.Int).Mul(big.NewInt(n), total)).Float64()
+	return gini
+}
+
+// share returns the share of the total, in basis points rounded down.
+func share(power, total *big.Int) uint64 {
+	if total.Sign() == 0 {
+		return 0
+	}
+	bps := new(big.Int).Mul(power, big.NewInt(shareBasisPoints))
+	return bps.Div(bps, total).Uint64()
+}
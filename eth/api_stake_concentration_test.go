@@ -0,0 +1,154 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/rpc"
+)
+
+// committeeOfPowers returns a committee of members with the given voting powers.
+func committeeOfPowers(powers ...int64) types.Committee {
+	committee := make(types.Committee, len(powers))
+	for i, power := range powers {
+		committee[i] = types.CommitteeMember{Address: common.BigToAddress(big.NewInt(int64(i + 1))), VotingPower: big.NewInt(power)}
+	}
+	return committee
+}
+
+func TestStakeConcentration(t *testing.T) {
+	type share struct {
+		members hexutil.Uint64
+		bps     hexutil.Uint64
+	}
+	tests := []struct {
+		name      string
+		powers    []int64
+		liveness  hexutil.Uint64
+		safety    hexutil.Uint64
+		gini      float64
+		topShares []share
+	}{
+		{
+			name:      "equal stakes",
+			powers:    []int64{10, 10, 10, 10},
+			liveness:  2, // 20 > 40/3
+			safety:    3, // 30 > 80/3
+			gini:      0,
+			topShares: []share{{1, 2500}, {3, 7500}},
+		},
+		{
+			name:     "thresholds are exceeded, not reached",
+			powers:   []int64{1, 1, 1},
+			liveness: 2,
+			safety:   3,
+			gini:     0,
+		},
+		{
+			name:     "one dominant validator",
+			powers:   []int64{1, 97, 1, 1},
+			liveness: 1,
+			safety:   1,
+			// ascending 1, 1, 1, 97: (-3 - 1 + 1 + 3*97) / (4 * 100)
+			gini:      0.72,
+			topShares: []share{{1, 9700}, {3, 9900}},
+		},
+		{
+			name:      "single member",
+			powers:    []int64{5},
+			liveness:  1,
+			safety:    1,
+			gini:      0,
+			topShares: []share{{1, 10000}},
+		},
+		{
+			name:     "skewed stakes",
+			powers:   []int64{50, 30, 10, 5, 3, 2},
+			liveness: 1, // 50 > 100/3
+			safety:   2, // 80 > 200/3
+			// ascending 2, 3, 5, 10, 30, 50: (-5*2 - 3*3 - 5 + 10 + 3*30 + 5*50) / (6 * 100)
+			gini:      326.0 / 600,
+			topShares: []share{{1, 5000}, {3, 9000}, {5, 9800}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := stakeConcentration(committeeOfPowers(test.powers...))
+			require.Equal(t, hexutil.Uint64(len(test.powers)), result.CommitteeSize)
+			require.Equal(t, test.liveness, result.LivenessCoefficient)
+			require.Equal(t, test.safety, result.SafetyCoefficient)
+			require.InDelta(t, test.gini, result.Gini, 1e-12)
+			if test.topShares != nil {
+				require.Len(t, result.TopShares, len(test.topShares))
+				for i, expected := range test.topShares {
+					require.Equal(t, expected.members, result.TopShares[i].Members)
+					require.Equal(t, expected.bps, result.TopShares[i].ShareBps)
+				}
+			}
+		})
+	}
+}
+
+// countingCommitteeReader serves a header and the committee of the contract, counting the reads.
+type countingCommitteeReader struct {
+	header            *types.Header
+	contractCommittee types.Committee
+	headers           int
+	contractReads     int
+}
+
+func (r *countingCommitteeReader) Header(context.Context, rpc.BlockNumberOrHash) (*types.Header, error) {
+	r.headers++
+	return r.header, nil
+}
+
+func (r *countingCommitteeReader) ContractCommittee(context.Context, rpc.BlockNumberOrHash, *types.Header) (types.Committee, error) {
+	r.contractReads++
+	return r.contractCommittee, nil
+}
+
+func TestStakeConcentrationAPI(t *testing.T) {
+	ctx := context.Background()
+	reader := &countingCommitteeReader{
+		// the header does not embed the committee, it is read from the contract
+		header:            &types.Header{Number: big.NewInt(7), Difficulty: big.NewInt(1)},
+		contractCommittee: committeeOfPowers(1, 97, 1, 1),
+	}
+	api := NewPublicStakeConcentrationAPI(reader)
+
+	result, err := api.StakeConcentration(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), uint64(result.BlockNumber))
+	require.Equal(t, reader.header.Hash(), result.BlockHash)
+	require.Equal(t, int64(100), result.TotalVotingPower.ToInt().Int64())
+	require.Equal(t, hexutil.Uint64(1), result.SafetyCoefficient)
+	require.Equal(t, 1, reader.contractReads)
+
+	// the result is cached per block hash
+	hash := rpc.BlockNumberOrHashWithHash(reader.header.Hash(), false)
+	cached, err := api.StakeConcentration(ctx, &hash)
+	require.NoError(t, err)
+	require.Same(t, result, cached)
+	require.Equal(t, 1, reader.contractReads)
+
+	// but not for the pending block
+	pending := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	_, err = api.StakeConcentration(ctx, &pending)
+	require.NoError(t, err)
+	_, err = api.StakeConcentration(ctx, &pending)
+	require.NoError(t, err)
+	require.Equal(t, 3, reader.contractReads)
+
+	// the committee embedded in the header is used
+	reader.header = &types.Header{Number: big.NewInt(8), Difficulty: big.NewInt(1), Committee: committeeOfPowers(10, 10, 10, 10)}
+	result, err = api.StakeConcentration(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(2), result.LivenessCoefficient)
+	require.Equal(t, 3, reader.contractReads)
+}
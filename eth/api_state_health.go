@@ -0,0 +1,60 @@
+package eth
+
+import (
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/eth/autapi"
+)
+
+// StateHealth is the result of aut_stateHealth.
+type StateHealth struct {
+	// Health is healthy while the local state agrees with the network, critical once it diverged.
+	Health string `json:"health"`
+	// Phase is one of participating, halted until an operator intervenes, resyncing from a verified
+	// state, or abandoned once the automatic resync did not recover in time.
+	Phase string `json:"phase"`
+	// Mismatches is the number of consecutive block imports failing with a state root mismatch.
+	Mismatches hexutil.Uint64 `json:"mismatches"`
+	Threshold  hexutil.Uint64 `json:"threshold"`
+	DivergedAt hexutil.Uint64 `json:"divergedAt"`
+	LastError  string         `json:"lastError"`
+	AutoResync bool           `json:"autoResync"`
+	RewoundTo  hexutil.Uint64 `json:"rewoundTo"`
+	// Deadline is the time at which the automatic resync is given up, only set while resyncing.
+	Deadline   *autapi.Timestamp `json:"deadline,omitempty"`
+	Recoveries hexutil.Uint64    `json:"recoveries"`
+}
+
+// PublicStateHealthAPI serves the state of the state divergence guard under the aut namespace.
+type PublicStateHealthAPI struct {
+	guard *stateDivergenceGuard
+}
+
+// NewPublicStateHealthAPI creates a new state health API instance.
+func NewPublicStateHealthAPI(guard *stateDivergenceGuard) *PublicStateHealthAPI {
+	return &PublicStateHealthAPI{guard: guard}
+}
+
+// StateHealth returns whether the local state agrees with the network and the progress of the
+// recovery otherwise.
+func (api *PublicStateHealthAPI) StateHealth() *StateHealth {
+	status := api.guard.Status()
+	health := &StateHealth{
+		Health:     "healthy",
+		Phase:      status.Phase.String(),
+		Mismatches: hexutil.Uint64(status.Mismatches),
+		Threshold:  stateMismatchThreshold,
+		DivergedAt: hexutil.Uint64(status.DivergedAt),
+		LastError:  status.LastError,
+		AutoResync: api.guard.autoResync,
+		RewoundTo:  hexutil.Uint64(status.RewoundTo),
+		Recoveries: hexutil.Uint64(status.Recoveries),
+	}
+	if status.Phase != stateParticipating {
+		health.Health = "critical"
+	}
+	if status.Phase == stateResyncing {
+		deadline := autapi.Timestamp(status.Deadline)
+		health.Deadline = &deadline
+	}
+	return health
+}
@@ -0,0 +1,111 @@
+package eth
+
+import (
+	"sort"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+)
+
+// tendermintMessageStore is the view of the MsgStore served by debug_tendermintMessages.
+type tendermintMessageStore interface {
+	RequireMessages() error
+	GetRange(from, to uint64, query func(message.Msg) bool) []message.Msg
+}
+
+// tendermintMessagesChain resolves the committee of a height, embedded in the header of its parent.
+type tendermintMessagesChain interface {
+	GetHeaderByNumber(number uint64) *types.Header
+}
+
+// TendermintMessage is a consensus message of the MsgStore as dumped by debug_tendermintMessages.
+type TendermintMessage struct {
+	Type  string         `json:"type"`
+	Code  hexutil.Uint64 `json:"code"`
+	Round hexutil.Uint64 `json:"round"`
+	Value common.Hash    `json:"value"` // block proposed or voted for, zero for a nil vote
+	Hash  common.Hash    `json:"hash"`
+	// Senders are the addresses of the signers, empty for a vote if the committee of the height is unknown.
+	Senders []common.Address `json:"senders"`
+	// Signers are the committee indexes of the signers of a vote, more than one for an aggregate.
+	Signers   []hexutil.Uint64 `json:"signers,omitempty"`
+	Signature hexutil.Bytes    `json:"signature"`
+	Payload   hexutil.Bytes    `json:"payload,omitempty"` // RLP encoded message, in verbose mode only
+}
+
+// PrivateTendermintMessagesAPI dumps the consensus messages kept by the MsgStore under the debug namespace,
+// for the operators to share the votes seen by their node when consensus stalls.
+type PrivateTendermintMessagesAPI struct {
+	store tendermintMessageStore
+	chain tendermintMessagesChain
+}
+
+// NewPrivateTendermintMessagesAPI creates a new tendermint messages API instance.
+func NewPrivateTendermintMessagesAPI(store tendermintMessageStore, chain tendermintMessagesChain) *PrivateTendermintMessagesAPI {
+	return &PrivateTendermintMessagesAPI{store: store, chain: chain}
+}
+
+// TendermintMessages returns the consensus messages of the height kept by the MsgStore, of the given round
+// only if set, ordered by round then proposals, prevotes and precommits. The payload of the messages is
+// included in verbose mode only, to keep the response small. It fails if the MsgStore keeps the digests of
+// the messages only.
+func (api *PrivateTendermintMessagesAPI) TendermintMessages(height hexutil.Uint64, round *hexutil.Uint64, verbose *bool) ([]*TendermintMessage, error) {
+	if err := api.store.RequireMessages(); err != nil {
+		return nil, err
+	}
+	msgs := api.store.GetRange(uint64(height), uint64(height), func(m message.Msg) bool {
+		return round == nil || m.R() == int64(*round)
+	})
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].R() < msgs[j].R() })
+
+	var committee types.Committee
+	if height > 0 {
+		if parent := api.chain.GetHeaderByNumber(uint64(height) - 1); parent != nil {
+			committee = parent.Committee
+		}
+	}
+	withPayload := verbose != nil && *verbose
+	result := make([]*TendermintMessage, 0, len(msgs))
+	for _, m := range msgs {
+		dumped := &TendermintMessage{
+			Type:      tendermintMessageType(m.Code()),
+			Code:      hexutil.Uint64(m.Code()),
+			Round:     hexutil.Uint64(m.R()),
+			Value:     m.Value(),
+			Hash:      m.Hash(),
+			Senders:   []common.Address{},
+			Signature: m.Signature().Marshal(),
+		}
+		switch msg := m.(type) {
+		case *message.Propose:
+			dumped.Senders = append(dumped.Senders, msg.Signer())
+		case message.Vote:
+			for _, index := range msg.Signers().FlattenUniq() {
+				dumped.Signers = append(dumped.Signers, hexutil.Uint64(index))
+				if index < len(committee) {
+					dumped.Senders = append(dumped.Senders, committee[index].Address)
+				}
+			}
+		}
+		if withPayload {
+			dumped.Payload = m.Payload()
+		}
+		result = append(result, dumped)
+	}
+	return result, nil
+}
+
+func tendermintMessageType(code uint8) string {
+	switch code {
+	case message.ProposalCode:
+		return "proposal"
+	case message.PrevoteCode:
+		return "prevote"
+	case message.PrecommitCode:
+		return "precommit"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,141 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/consensus/tendermint/core"
+	"github.com/autonity/autonity/consensus/tendermint/core/message"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto/blst"
+	"github.com/autonity/autonity/rpc"
+)
+
+// headersByNumber serves the headers of the slice by number.
+type headersByNumber []*types.Header
+
+func (h headersByNumber) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(h)) {
+		return nil
+	}
+	return h[number]
+}
+
+func TestTendermintMessages(t *testing.T) {
+	epoch := newTestEpoch(t, 4)
+	size := len(epoch.committee)
+	signer := func(i int) message.Signer {
+		return func(hash common.Hash) blst.Signature { return epoch.keys[i].Sign(hash[:]) }
+	}
+	headers := make(headersByNumber, 5)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i)), Committee: epoch.committee}
+	}
+
+	height := uint64(5)
+	value := common.Hash{0xaa}
+	store := core.NewMsgStore()
+	block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(height)})
+	proposal := message.NewPropose(0, height, -1, block, signer(1), &epoch.committee[1])
+	lateVote := message.NewPrevote(1, height, common.Hash{}, signer(2), &epoch.committee[2], size)
+	store.Save(lateVote)
+	store.Save(proposal)
+	for i := 0; i < size; i++ {
+		store.Save(message.NewPrevote(0, height, value, signer(i), &epoch.committee[i], size))
+	}
+	for i := 0; i < 3; i++ {
+		store.Save(message.NewPrecommit(0, height, value, signer(i), &epoch.committee[i], size))
+	}
+	// neither the messages of another height, nor the ones of a height of unknown committee, are dumped
+	store.Save(message.NewPrevote(0, height+1, value, signer(0), &epoch.committee[0], size))
+	store.Save(message.NewPrevote(0, 100, value, signer(3), &epoch.committee[3], size))
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("debug", NewPrivateTendermintMessagesAPI(store, headers)))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	t.Run("height", func(t *testing.T) {
+		var msgs []*TendermintMessage
+		require.NoError(t, client.Call(&msgs, "debug_tendermintMessages", hexutil.Uint64(height)))
+		require.Len(t, msgs, 1+size+3+1)
+
+		require.Equal(t, "proposal", msgs[0].Type)
+		require.Equal(t, hexutil.Uint64(message.ProposalCode), msgs[0].Code)
+		require.Equal(t, block.Hash(), msgs[0].Value)
+		require.Equal(t, proposal.Hash(), msgs[0].Hash)
+		require.Equal(t, []common.Address{epoch.committee[1].Address}, msgs[0].Senders)
+		require.Empty(t, msgs[0].Signers)
+		require.Equal(t, hexutil.Bytes(proposal.Signature().Marshal()), msgs[0].Signature)
+		require.Empty(t, msgs[0].Payload)
+
+		for i, m := range msgs[1 : 1+size] {
+			require.Equal(t, "prevote", m.Type)
+			require.Equal(t, hexutil.Uint64(0), m.Round)
+			require.Equal(t, value, m.Value)
+			require.Equal(t, []common.Address{epoch.committee[i].Address}, m.Senders)
+			require.Equal(t, []hexutil.Uint64{hexutil.Uint64(i)}, m.Signers)
+			require.NotEmpty(t, m.Signature)
+			require.Empty(t, m.Payload)
+		}
+		for _, m := range msgs[1+size : 1+size+3] {
+			require.Equal(t, "precommit", m.Type)
+			require.Equal(t, hexutil.Uint64(message.PrecommitCode), m.Code)
+		}
+		// the later round comes last, whatever the order it was received in
+		last := msgs[len(msgs)-1]
+		require.Equal(t, "prevote", last.Type)
+		require.Equal(t, hexutil.Uint64(1), last.Round)
+		require.Equal(t, common.Hash{}, last.Value)
+		require.Equal(t, lateVote.Hash(), last.Hash)
+	})
+
+	t.Run("round", func(t *testing.T) {
+		var msgs []*TendermintMessage
+		require.NoError(t, client.Call(&msgs, "debug_tendermintMessages", hexutil.Uint64(height), hexutil.Uint64(1)))
+		require.Len(t, msgs, 1)
+		require.Equal(t, lateVote.Hash(), msgs[0].Hash)
+
+		require.NoError(t, client.Call(&msgs, "debug_tendermintMessages", hexutil.Uint64(height), hexutil.Uint64(2)))
+		require.Empty(t, msgs)
+	})
+
+	t.Run("verbose", func(t *testing.T) {
+		var msgs []*TendermintMessage
+		require.NoError(t, client.Call(&msgs, "debug_tendermintMessages", hexutil.Uint64(height), nil, true))
+		require.Len(t, msgs, 1+size+3+1)
+		require.Equal(t, hexutil.Bytes(proposal.Payload()), msgs[0].Payload)
+		for _, m := range msgs {
+			require.NotEmpty(t, m.Payload)
+		}
+	})
+
+	t.Run("unknown committee", func(t *testing.T) {
+		var msgs []*TendermintMessage
+		require.NoError(t, client.Call(&msgs, "debug_tendermintMessages", hexutil.Uint64(100)))
+		require.Len(t, msgs, 1)
+		require.Empty(t, msgs[0].Senders)
+		require.Equal(t, []hexutil.Uint64{3}, msgs[0].Signers)
+	})
+
+	t.Run("unknown height", func(t *testing.T) {
+		var msgs []*TendermintMessage
+		require.NoError(t, client.Call(&msgs, "debug_tendermintMessages", hexutil.Uint64(50)))
+		require.NotNil(t, msgs)
+		require.Empty(t, msgs)
+	})
+
+	t.Run("digest mode", func(t *testing.T) {
+		store.SetMode(core.DigestMode)
+		defer store.SetMode(core.FullMode)
+		var msgs []*TendermintMessage
+		err := client.Call(&msgs, "debug_tendermintMessages", hexutil.Uint64(height))
+		require.Error(t, err)
+		require.Equal(t, core.ErrDigestMode.Error(), err.Error())
+	})
+}
@@ -0,0 +1,89 @@
+package eth
+
+import (
+	"context"
+
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// committeeEnodesReader reads the consensus committee enodes from the protocol contract.
+type committeeEnodesReader interface {
+	// CommitteeEnodes returns the head block number and the committee enodes, in the order of the contract.
+	CommitteeEnodes(ctx context.Context) (uint64, []*enode.Node, error)
+}
+
+// chainCommitteeEnodes reads the committee enodes from the protocol contracts state at the head of the chain.
+type chainCommitteeEnodes struct {
+	chain *core.BlockChain
+	state *protocolState
+}
+
+func (r *chainCommitteeEnodes) CommitteeEnodes(ctx context.Context) (uint64, []*enode.Node, error) {
+	state, header, err := r.state.Head()
+	if err != nil {
+		return 0, nil, err
+	}
+	committee, err := r.chain.ProtocolContracts().CommitteeEnodes(ctx, types.NewBlockWithHeader(header), state, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := state.Error(); err != nil {
+		return 0, nil, err
+	}
+	return header.Number.Uint64(), committee.List, nil
+}
+
+// TopologyNode is a committee member in the result of debug_topologyGraph.
+type TopologyNode struct {
+	Enode string `json:"enode"`
+	// Peers are the indexes of the members the node connects to.
+	Peers []int `json:"peers"`
+}
+
+// TopologyGraph is the result of debug_topologyGraph.
+type TopologyGraph struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// MeshDegree is the committee size up to which the members are fully meshed, 0 for a full mesh at any size.
+	MeshDegree int `json:"meshDegree"`
+	// Self is the index of the local node in the committee, -1 if it is not a member.
+	Self  int             `json:"self"`
+	Nodes []*TopologyNode `json:"nodes"`
+}
+
+// PrivateTopologyAPI serves the consensus mesh topology under the debug namespace.
+type PrivateTopologyAPI struct {
+	reader   committeeEnodesReader
+	topology networkTopology
+	self     func() enode.ID
+}
+
+// NewPrivateTopologyAPI creates a new topology API instance.
+func NewPrivateTopologyAPI(reader committeeEnodesReader, meshDegree int, self func() enode.ID) *PrivateTopologyAPI {
+	return &PrivateTopologyAPI{reader: reader, topology: NewGraphTopology(meshDegree), self: self}
+}
+
+// TopologyGraph returns the adjacency of the consensus mesh of the committee at the head block, as
+// selected by each of its members.
+func (api *PrivateTopologyAPI) TopologyGraph(ctx context.Context) (*TopologyGraph, error) {
+	number, nodes, err := api.reader.CommitteeEnodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	graph := &TopologyGraph{
+		BlockNumber: hexutil.Uint64(number),
+		MeshDegree:  api.topology.minNodes,
+		Self:        -1,
+		Nodes:       make([]*TopologyNode, len(nodes)),
+	}
+	self := api.self()
+	for i, peers := range api.topology.Graph(len(nodes)) {
+		if nodes[i].ID() == self {
+			graph.Self = i
+		}
+		graph.Nodes[i] = &TopologyNode{Enode: nodes[i].URLv4(), Peers: peers}
+	}
+	return graph, nil
+}
@@ -0,0 +1,55 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+type fakeCommitteeEnodes struct {
+	number uint64
+	nodes  []*enode.Node
+}
+
+func (f *fakeCommitteeEnodes) CommitteeEnodes(context.Context) (uint64, []*enode.Node, error) {
+	return f.number, f.nodes, nil
+}
+
+func TestTopologyGraphAPI(t *testing.T) {
+	privateKeys := make(map[*ecdsa.PrivateKey]bool)
+	reader := &fakeCommitteeEnodes{number: 42}
+	for i := 0; i < 70; i++ {
+		privateKey, node := createNewNode(t, privateKeys)
+		privateKeys[privateKey] = true
+		reader.nodes = append(reader.nodes, node)
+	}
+	self := reader.nodes[3].ID()
+	api := NewPrivateTopologyAPI(reader, 20, func() enode.ID { return self })
+
+	graph, err := api.TopologyGraph(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), uint64(graph.BlockNumber))
+	require.Equal(t, 20, graph.MeshDegree)
+	require.Equal(t, 3, graph.Self)
+	require.Len(t, graph.Nodes, len(reader.nodes))
+	// the adjacency is the one selected by each member
+	topology := NewGraphTopology(20)
+	for i, node := range graph.Nodes {
+		require.Equal(t, reader.nodes[i].URLv4(), node.Enode)
+		selected := topology.RequestSubset(reader.nodes, i)
+		require.Len(t, node.Peers, len(selected))
+		for j, peer := range node.Peers {
+			require.Equal(t, selected[j], reader.nodes[peer])
+		}
+	}
+
+	// a node outside the committee
+	self = enode.ID{}
+	graph, err = api.TopologyGraph(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, -1, graph.Self)
+}
@@ -0,0 +1,118 @@
+package eth
+
+import (
+	"sync"
+
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// validatorStatus is the participation of the local node in consensus, as last decided by the validator
+// controller.
+type validatorStatus struct {
+	mu            sync.RWMutex
+	evaluated     bool
+	number        uint64 // head block at the last decision
+	inCommittee   bool
+	miningActive  bool
+	committeeSize int
+	// committee enodes handed over to the p2p server, nil while out of the committee
+	committee map[enode.ID]struct{}
+}
+
+func newValidatorStatus() *validatorStatus {
+	return &validatorStatus{}
+}
+
+// update records the decision of the validator controller at the head block number.
+func (v *validatorStatus) update(number uint64, committeeSize int, inCommittee, miningActive bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.evaluated = true
+	v.number = number
+	v.committeeSize = committeeSize
+	v.inCommittee = inCommittee
+	v.miningActive = miningActive
+	if !inCommittee {
+		v.committee = nil
+	}
+}
+
+// setMiningActive records that the mining was stopped or restarted outside of the validator controller.
+func (v *validatorStatus) setMiningActive(active bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.miningActive = active
+}
+
+// isInCommittee reports whether the node was in the committee at the last decision.
+func (v *validatorStatus) isInCommittee() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.inCommittee
+}
+
+// setCommitteeEnodes records the committee enodes the node is meshed with.
+func (v *validatorStatus) setCommitteeEnodes(enodes []*enode.Node) {
+	committee := make(map[enode.ID]struct{}, len(enodes))
+	for _, n := range enodes {
+		committee[n.ID()] = struct{}{}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.committee = committee
+}
+
+// ValidatorStatus is the result of aut_validatorStatus.
+type ValidatorStatus struct {
+	// Evaluated is false until the validator controller decided for the first time, the other fields are
+	// meaningless until then.
+	Evaluated bool `json:"evaluated"`
+	// BlockNumber is the head block at which the participation was last evaluated.
+	BlockNumber             hexutil.Uint64 `json:"blockNumber"`
+	InCommittee             bool           `json:"inCommittee"`
+	MiningActive            bool           `json:"miningActive"`
+	CommitteeSize           hexutil.Uint64 `json:"committeeSize"`
+	ConnectedConsensusPeers hexutil.Uint64 `json:"connectedConsensusPeers"`
+}
+
+// peerLister lists the connected peers.
+type peerLister interface {
+	Peers() []*p2p.Peer
+}
+
+// PublicValidatorStatusAPI serves the participation of the local node in consensus under the aut namespace.
+type PublicValidatorStatusAPI struct {
+	status *validatorStatus
+	peers  peerLister
+}
+
+// NewPublicValidatorStatusAPI creates a new validator status API instance.
+func NewPublicValidatorStatusAPI(status *validatorStatus, peers peerLister) *PublicValidatorStatusAPI {
+	return &PublicValidatorStatusAPI{status: status, peers: peers}
+}
+
+// ValidatorStatus returns whether the local node is a member of the consensus committee and mines, the
+// committee size and the number of committee members it is connected to, as last evaluated by the node on
+// a new head block.
+func (api *PublicValidatorStatusAPI) ValidatorStatus() *ValidatorStatus {
+	api.status.mu.RLock()
+	defer api.status.mu.RUnlock()
+	result := &ValidatorStatus{
+		Evaluated:     api.status.evaluated,
+		BlockNumber:   hexutil.Uint64(api.status.number),
+		InCommittee:   api.status.inCommittee,
+		MiningActive:  api.status.miningActive,
+		CommitteeSize: hexutil.Uint64(api.status.committeeSize),
+	}
+	if len(api.status.committee) == 0 {
+		return result
+	}
+	for _, peer := range api.peers.Peers() {
+		if _, ok := api.status.committee[peer.ID()]; ok {
+			result.ConnectedConsensusPeers++
+		}
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/p2p"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+type fakePeerLister []*p2p.Peer
+
+func (l fakePeerLister) Peers() []*p2p.Peer {
+	return l
+}
+
+func TestValidatorStatusAPI(t *testing.T) {
+	// p2p.NewPeer gives the peers a random node, the committee is made of the peer nodes
+	members := make([]*p2p.Peer, 3)
+	committee := make([]*enode.Node, 3)
+	for i := range committee {
+		members[i] = p2p.NewPeer(enode.ID{}, "member", nil)
+		committee[i] = members[i].Node()
+	}
+	// two committee members and a full node are connected
+	peers := fakePeerLister{
+		members[0],
+		members[2],
+		p2p.NewPeer(enode.ID{}, "full node", nil),
+	}
+	status := newValidatorStatus()
+	api := NewPublicValidatorStatusAPI(status, peers)
+
+	require.Equal(t, &ValidatorStatus{}, api.ValidatorStatus())
+
+	// the node starts out of the committee
+	status.update(10, 3, false, false)
+	require.Equal(t, &ValidatorStatus{Evaluated: true, BlockNumber: 10, CommitteeSize: 3}, api.ValidatorStatus())
+
+	// the node joins the committee
+	status.setCommitteeEnodes(committee)
+	status.update(11, 3, true, true)
+	require.Equal(t, &ValidatorStatus{
+		Evaluated:               true,
+		BlockNumber:             11,
+		InCommittee:             true,
+		MiningActive:            true,
+		CommitteeSize:           3,
+		ConnectedConsensusPeers: 2,
+	}, api.ValidatorStatus())
+
+	// the node drops out of the committee, the connections to the former committee members do not count
+	status.update(12, 2, false, false)
+	require.Equal(t, &ValidatorStatus{Evaluated: true, BlockNumber: 12, CommitteeSize: 2}, api.ValidatorStatus())
+}
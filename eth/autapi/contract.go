@@ -0,0 +1,74 @@
+package autapi
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+)
+
+var (
+	bigType     = reflect.TypeOf((*big.Int)(nil))
+	addressType = reflect.TypeOf(common.Address{})
+)
+
+// ContractValue converts a value unpacked out of the result of a protocol contract view to its encoding:
+// the integers are hex quantities, the addresses are checksummed, the byte strings and fixed size byte
+// arrays are hex and the tuples are objects keyed by the names of their components.
+func ContractValue(value interface{}) interface{} {
+	return contractValue(reflect.ValueOf(value))
+}
+
+func contractValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Type() {
+	case bigType:
+		return (*hexutil.Big)(v.Interface().(*big.Int))
+	case addressType:
+		return Address(v.Interface().(common.Address))
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return contractValue(v.Elem())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return hexutil.Uint64(v.Uint())
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return (*hexutil.Big)(big.NewInt(v.Int()))
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			bytes := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(bytes), v)
+			return hexutil.Bytes(bytes)
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []interface{}{}
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = contractValue(v.Index(i))
+		}
+		return values
+	case reflect.Struct:
+		fields := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			fields[name] = contractValue(v.Field(i))
+		}
+		return fields
+	}
+	return v.Interface()
+}
@@ -0,0 +1,58 @@
+package autapi
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+)
+
+func TestContractValue(t *testing.T) {
+	type validator struct {
+		Treasury     common.Address `json:"treasury"`
+		BondedStake  *big.Int       `json:"bondedStake"`
+		Commission   *big.Int       `json:"commission"`
+		State        uint8          `json:"state"`
+		Enode        string         `json:"enode"`
+		ConsensusKey []byte         `json:"consensusKey"`
+		Index        int64
+		hidden       bool
+	}
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"integer", big.NewInt(300), `"0x12c"`},
+		{"nil integer", (*big.Int)(nil), `null`},
+		{"unsigned", uint64(42), `"0x2a"`},
+		{"signed", int32(-5), `"-0x5"`},
+		{"boolean", true, `true`},
+		{"string", "enode://x", `"enode://x"`},
+		{"address", common.HexToAddress("0xbc79932ad81cf46daa9841e0bd6871aab7cfba76"), `"0xbC79932aD81Cf46Daa9841e0bD6871AaB7CfBa76"`},
+		{"addresses", []common.Address{common.HexToAddress("0xa1")}, `["0x00000000000000000000000000000000000000A1"]`},
+		{"empty list", []common.Address(nil), `[]`},
+		{"bytes", []byte{1, 2}, `"0x0102"`},
+		{"fixed bytes", [4]byte{0xde, 0xad, 0xbe, 0xef}, `"0xdeadbeef"`},
+		{"tuple", validator{
+			Treasury:     common.HexToAddress("0xa1"),
+			BondedStake:  big.NewInt(1000),
+			Commission:   big.NewInt(1000),
+			State:        1,
+			Enode:        "enode://x",
+			ConsensusKey: []byte{0xab},
+			Index:        3,
+		}, `{"treasury": "0x00000000000000000000000000000000000000A1", "bondedStake": "0x3e8", "commission": "0x3e8",
+			"state": "0x1", "enode": "enode://x", "consensusKey": "0xab", "Index": "0x3"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := json.Marshal(ContractValue(test.value))
+			require.NoError(t, err)
+			require.JSONEq(t, test.expected, string(encoded))
+		})
+	}
+}
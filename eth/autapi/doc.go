@@ -0,0 +1,23 @@
+// Package autapi defines the encoding of the responses of the aut namespace RPC methods, shared by all of
+// them so that a value reads the same whichever method returns it:
+//
+//   - quantities, such as voting powers, stakes, block numbers and counters, are 0x-prefixed hex
+//     (hexutil.Big or hexutil.Uint64);
+//   - addresses are EIP-55 checksummed (Address);
+//   - hashes and byte strings are 0x-prefixed hex (common.Hash, hexutil.Bytes);
+//   - timestamps are RFC3339 strings in UTC (Timestamp);
+//   - the values returned by the protocol contract views follow the same rules (ContractValue).
+//
+// Compatibility policy: the JSON shape of every response is pinned by the golden files of the eth package
+// tests, under testdata/autapi/v<version>. Additive changes, such as a new field or a new method, are made
+// in place: the golden files of the current version are regenerated with
+//
+//	go test ./eth -run TestAutResponseGolden -update
+//
+// and the diff is reviewed with the change. Any other change, such as renaming or removing a field or
+// changing its encoding, is breaking: it requires bumping ResponseVersion, copying the golden files to the
+// directory of the new version and keeping the files of the previous versions untouched.
+package autapi
+
+// ResponseVersion is the version of the encoding of the aut namespace responses.
+const ResponseVersion = 1
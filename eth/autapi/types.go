@@ -0,0 +1,77 @@
+package autapi
+
+import (
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/core/types"
+)
+
+// Address is an address encoded EIP-55 checksummed. Any case is accepted when decoding.
+type Address common.Address
+
+// MarshalText implements encoding.TextMarshaler.
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(common.Address(a).Hex()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *Address) UnmarshalText(input []byte) error {
+	return (*common.Address)(a).UnmarshalText(input)
+}
+
+// Addresses converts the addresses to their encoding.
+func Addresses(addresses []common.Address) []Address {
+	if addresses == nil {
+		return nil
+	}
+	encoded := make([]Address, len(addresses))
+	for i, address := range addresses {
+		encoded[i] = Address(address)
+	}
+	return encoded
+}
+
+// Timestamp is a point in time encoded as an RFC3339 string in UTC, with the fractional seconds if any.
+type Timestamp time.Time
+
+// UnixTimestamp returns the timestamp of the unix time in seconds, such as a block time.
+func UnixTimestamp(seconds uint64) Timestamp {
+	return Timestamp(time.Unix(int64(seconds), 0))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(time.Time(t).UTC().Format(time.RFC3339Nano)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *Timestamp) UnmarshalText(input []byte) error {
+	parsed, err := time.Parse(time.RFC3339Nano, string(input))
+	if err != nil {
+		return err
+	}
+	*t = Timestamp(parsed)
+	return nil
+}
+
+// CommitteeMember is a member of a consensus committee.
+type CommitteeMember struct {
+	Address      Address       `json:"address"`
+	VotingPower  *hexutil.Big  `json:"votingPower"`
+	ConsensusKey hexutil.Bytes `json:"consensusKey"`
+}
+
+// NewCommittee converts the committee to its encoding.
+func NewCommittee(committee types.Committee) []*CommitteeMember {
+	encoded := make([]*CommitteeMember, len(committee))
+	for i := range committee {
+		encoded[i] = &CommitteeMember{
+			Address:      Address(committee[i].Address),
+			VotingPower:  (*hexutil.Big)(committee[i].VotingPower),
+			ConsensusKey: committee[i].ConsensusKeyBytes,
+		}
+	}
+	return encoded
+}
@@ -0,0 +1,57 @@
+package autapi
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
+)
+
+func TestAddressEncoding(t *testing.T) {
+	address := Address(common.HexToAddress("0xbc79932ad81cf46daa9841e0bd6871aab7cfba76"))
+	encoded, err := json.Marshal(address)
+	require.NoError(t, err)
+	require.Equal(t, `"0xbC79932aD81Cf46Daa9841e0bD6871AaB7CfBa76"`, string(encoded))
+
+	// any case is accepted
+	var decoded Address
+	require.NoError(t, json.Unmarshal([]byte(`"0xBC79932AD81CF46DAA9841E0BD6871AAB7CFBA76"`), &decoded))
+	require.Equal(t, address, decoded)
+	require.Error(t, json.Unmarshal([]byte(`"0xbc79"`), &decoded))
+}
+
+func TestTimestampEncoding(t *testing.T) {
+	encoded, err := json.Marshal(UnixTimestamp(1700000000))
+	require.NoError(t, err)
+	require.Equal(t, `"2023-11-14T22:13:20Z"`, string(encoded))
+
+	// the fractional seconds are kept, in UTC whatever the location
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	encoded, err = json.Marshal(Timestamp(time.Date(2024, 3, 1, 12, 0, 0, 500000000, zone)))
+	require.NoError(t, err)
+	require.Equal(t, `"2024-03-01T10:00:00.5Z"`, string(encoded))
+
+	var decoded Timestamp
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.True(t, time.Date(2024, 3, 1, 10, 0, 0, 500000000, time.UTC).Equal(time.Time(decoded)))
+	require.Error(t, json.Unmarshal([]byte(`"1700000000"`), &decoded))
+}
+
+func TestNewCommittee(t *testing.T) {
+	committee := types.Committee{
+		{Address: common.HexToAddress("0xa1"), VotingPower: big.NewInt(1000), ConsensusKeyBytes: []byte{0xab, 0xcd}},
+		{Address: common.HexToAddress("0xa2"), VotingPower: big.NewInt(0)},
+	}
+	encoded, err := json.Marshal(NewCommittee(committee))
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"address": "0x00000000000000000000000000000000000000A1", "votingPower": "0x3e8", "consensusKey": "0xabcd"},
+		{"address": "0x00000000000000000000000000000000000000A2", "votingPower": "0x0", "consensusKey": "0x"}
+	]`, string(encoded))
+	require.Empty(t, NewCommittee(nil))
+}
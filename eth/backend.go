@@ -18,9 +18,11 @@
 package eth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -29,7 +31,7 @@ import (
 	"github.com/autonity/autonity/accounts"
 	"github.com/autonity/autonity/accounts/abi/bind/backends"
 	"github.com/autonity/autonity/common"
-	"github.com/autonity/autonity/common/hexutil"
+	"github.com/autonity/autonity/common/diskusage"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/tendermint/accountability"
 	tendermintcore "github.com/autonity/autonity/consensus/tendermint/core"
@@ -62,10 +64,6 @@ import (
 	"github.com/autonity/autonity/rpc"
 )
 
-const (
-	maxFullMeshPeers = 20
-)
-
 // Config contains the configuration options of the ETH protocol.
 // Deprecated: use ethconfig.Config instead.
 type Config = ethconfig.Config
@@ -90,6 +88,7 @@ type Ethereum struct {
 
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
+	bloomGenerations  []rawdb.BloomGeneration        // Section sizes the bloom index was generated with, the indexer's last
 	closeBloomHandler chan struct{}
 
 	APIBackend *EthAPIBackend
@@ -103,12 +102,22 @@ type Ethereum struct {
 
 	p2pServer        *p2p.Server
 	topologySelector networkTopology
+	meshHistory      *p2p.MeshHistory   // nil if disabled
+	diskWatchdog     *core.DiskWatchdog // nil if disabled
+	validatorStatus  *validatorStatus   // last decision of the validator controller
+	stateGuard       *stateDivergenceGuard
+	committeeChanges *committeeChanges  // committee changes along the chain heads
+	protocolState    *protocolState     // state the protocol contracts are called against
+
+	shutdownCtx    context.Context // Cancelled when the node stops, interrupting the committee enodes parsing
+	shutdownCancel context.CancelFunc
 
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and address)
 
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
 
 	accountability *accountability.FaultDetector
+	msgStore       *tendermintcore.MsgStore // consensus messages shared by the engine and the fault detector
 }
 
 // New creates a new Ethereum object (including the
@@ -122,6 +131,12 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	if config.Tendermint == (tendermintcore.Config{}) {
+		config.Tendermint = ethconfig.Defaults.Tendermint
+	}
+	if err := config.Tendermint.Validate(); err != nil {
+		return nil, err
+	}
 	if config.Miner.GasPrice == nil || config.Miner.GasPrice.Cmp(common.Big0) <= 0 {
 		stack.Logger().Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", ethconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(ethconfig.Defaults.Miner.GasPrice)
@@ -164,6 +179,9 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
+
+			CommitJournalEntries: config.CommitJournalEntries,
+			CommitJournalAge:     config.CommitJournalAge,
 		}
 	)
 	stack.Logger().Info("Initialised chain configuration", "config", chainConfig)
@@ -172,6 +190,18 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		stack.Logger().Error("Failed to recover state", "error", err)
 	}
 
+	bloomSize, err := ethconfig.BloomBitsSectionSize(config, chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	if config.LightServ > 0 && bloomSize != params.BloomBitsBlocks {
+		// The light clients expect the bloom tries of the default section size
+		stack.Logger().Warn("Sanitizing bloombits section size for light serving", "provided", bloomSize, "updated", params.BloomBitsBlocks)
+		bloomSize = params.BloomBitsBlocks
+	}
+	bloomIndexer, bloomGenerations := core.OpenBloomIndexer(chainDb, bloomSize, params.BloomConfirms)
+	stack.Logger().Info("Initialised bloombits index", "sectionsize", bloomSize, "generations", len(bloomGenerations))
+
 	// The event mux is shared between the consensus engine and fault detector such that both of them will receive the
 	// messages from p2p protocol manager layer.
 
@@ -179,9 +209,21 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 
 	// single instance of msgStore shared by misbehaviour detector and omission fault detector.
 	msgStore := tendermintcore.NewMsgStore()
+	if config.Accountability.PersistMsgStore {
+		msgStore = tendermintcore.NewPersistentMsgStore(chainDb)
+	}
 	consensusEngine := ethconfig.CreateConsensusEngine(stack, chainConfig, config, config.Miner.Notify,
 		config.Miner.Noverify, &vmConfig, evMux, msgStore)
 
+	_, bft := consensusEngine.(consensus.BFT)
+	networkWarnings, err := checkNetworkIDs(chainConfig.ChainID, config.NetworkID, bft)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range networkWarnings {
+		stack.Logger().Warn("⚠️ NETWORK IDENTIFIERS MISMATCH ⚠️", "chainid", chainConfig.ChainID, "network", config.NetworkID, "reason", warning)
+	}
+
 	nodeKey, _ := stack.Config().AutonityKeys()
 	eth := &Ethereum{
 		config:            config,
@@ -195,11 +237,17 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		gasPrice:          config.Miner.GasPrice,
 		address:           crypto.PubkeyToAddress(nodeKey.PublicKey),
 		bloomRequests:     make(chan chan *bloombits.Retrieval),
-		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
+		bloomIndexer:      bloomIndexer,
+		bloomGenerations:  bloomGenerations,
 		p2pServer:         stack.ExecutionServer(),
-		topologySelector:  NewGraphTopology(maxFullMeshPeers),
+		topologySelector:  NewGraphTopology(config.ConsensusMeshDegree),
 		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
+		meshHistory:       p2p.NewMeshHistory(chainDb, config.MeshHistoryEntries),
+		validatorStatus:   newValidatorStatus(),
+		committeeChanges:  newCommitteeChanges(crypto.PubkeyToAddress(nodeKey.PublicKey)),
+		msgStore:          msgStore,
 	}
+	eth.shutdownCtx, eth.shutdownCancel = context.WithCancel(context.Background())
 
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -226,12 +274,22 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	eth.blockchain.InternalCallTracer().SetEnabled(config.InternalCallTracing)
 
-	// temporary solution
+	if be, ok := consensusEngine.(consensus.BFTLifecycle[*core.BlockChain]); ok {
+		if err := be.Attach(eth.blockchain, eth.blockchain.ProtocolContracts()); err != nil {
+			return nil, err
+		}
+	}
 	if be, ok := consensusEngine.(interface {
-		SetBlockchain(*core.BlockChain)
+		SetRoundJournal(ethdb.KeyValueStore)
 	}); ok {
-		be.SetBlockchain(eth.blockchain)
+		be.SetRoundJournal(chainDb)
+	}
+	if be, ok := consensusEngine.(interface {
+		SetCommitRetryDatabase(ethdb.KeyValueStore)
+	}); ok {
+		be.SetCommitRetryDatabase(chainDb)
 	}
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
@@ -241,6 +299,16 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	eth.stateGuard = newStateDivergenceGuard(eth.blockchain, func() uint64 {
+		return eth.blockchain.ProtocolContracts().Cache.EpochPeriod().Uint64()
+	}, eth.haltValidator, eth.resumeValidator, config.AutoResync, config.AutoResyncTimeout, eth.log)
+
+	if eth.diskWatchdog = newDiskWatchdog(stack, config); eth.diskWatchdog != nil {
+		eth.diskWatchdog.Register("bloom indexer", eth.bloomIndexer)
+		eth.diskWatchdog.Register("clean trie cache journal", eth.blockchain.CleanCacheJournal())
+		eth.diskWatchdog.RegisterEssential("block import", eth.blockchain)
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
@@ -258,6 +326,8 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		TxPool:         eth.txPool,
 		Network:        config.NetworkID,
 		Sync:           config.SyncMode,
+		SnapMinBlocks:  config.SnapSyncMinBlocks,
+		ProtocolFirst:  config.SnapSyncProtocolFirst,
 		BloomCache:     uint64(cacheLimit),
 		EventMux:       eth.eventMux,
 		Checkpoint:     checkpoint,
@@ -265,9 +335,19 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	}); err != nil {
 		return nil, err
 	}
+	eth.protocolState = &protocolState{chain: eth.blockchain, db: chainDb}
+	if config.SnapSyncProtocolFirst {
+		eth.protocolState.partial = eth.handler.downloader.PriorityState
+	}
 
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
-	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
+	extra, err := makeExtraData(config.Miner.ExtraData)
+	if err != nil {
+		return nil, err
+	}
+	if err := eth.miner.SetExtra(extra); err != nil {
+		return nil, err
+	}
 
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
@@ -281,7 +361,7 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 
 	// Once the chain is initialized, load accountability precompiled contracts in EVM environment before chain sync
 	//start to apply accountability TXs if there were any, otherwise it would cause sync failure.
-	accountability.LoadPrecompiles(eth.blockchain)
+	accountability.LoadPrecompiles(eth.blockchain, accountability.DefaultVerificationCacheSize)
 	// Create Fault Detector for each full node for the time being.
 	//TODO: I think it would make more sense to move this into the tendermint backend if possible
 	eth.accountability = accountability.NewFaultDetector(
@@ -291,6 +371,14 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		msgStore, eth.txPool, eth.APIBackend, nodeKey,
 		eth.blockchain.ProtocolContracts(),
 		eth.log)
+	eth.accountability.SetSubmissionConfig(config.Accountability)
+	eth.accountability.SetLateMessagePolicy(config.Accountability.LateMessageWindow, config.Accountability.MaxRescansPerHeight)
+	eth.accountability.SetOffChainRateLimit(config.Accountability.MaxOffChainMessages, config.Accountability.OffChainMessageWindow,
+		config.Accountability.MaxOffChainViolations)
+	eth.accountability.SetMsgStoreCapacity(config.Accountability.MsgStoreCapacity)
+	if err := eth.accountability.SetMsgStoreMode(config.Accountability.MsgStoreMode); err != nil {
+		return nil, err
+	}
 
 	// Setup DNS discovery iterators.
 	dnsclient := dnsdisc.NewClient(dnsdisc.Config{})
@@ -317,21 +405,29 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	return eth, nil
 }
 
-func makeExtraData(extra []byte) []byte {
-	if len(extra) == 0 {
-		// create default extradata
-		extra, _ = rlp.EncodeToBytes([]interface{}{
-			uint(params.VersionMajor<<16 | params.VersionMinor<<8 | params.VersionPatch),
-			"autonity",
-			runtime.Version(),
-			runtime.GOOS,
-		})
-	}
+var errExtraDataTooLong = errors.New("miner extra data too long")
+
+// extraDataPrefix returns the version prefix of the block extra data, identifying the client.
+func extraDataPrefix() []byte {
+	prefix, _ := rlp.EncodeToBytes([]interface{}{
+		uint(params.VersionMajor<<16 | params.VersionMinor<<8 | params.VersionPatch),
+		"autonity",
+		runtime.Version(),
+		runtime.GOOS,
+	})
+	return prefix
+}
+
+// makeExtraData returns the block extra data made of the version prefix followed by the metadata of the
+// validator, the whole fitting in params.MaximumExtraDataSize.
+func makeExtraData(metadata []byte) ([]byte, error) {
+	prefix := extraDataPrefix()
+	extra := append(prefix, metadata...)
 	if uint64(len(extra)) > params.MaximumExtraDataSize {
-		log.Warn("Miner extra data exceed limit", "extra", hexutil.Bytes(extra), "limit", params.MaximumExtraDataSize)
-		extra = nil
+		return nil, fmt.Errorf("%w: %d bytes of metadata, %d bytes available after the version prefix",
+			errExtraDataTooLong, len(metadata), params.MaximumExtraDataSize-uint64(len(prefix)))
 	}
-	return extra
+	return extra, nil
 }
 
 // APIs return the collection of RPC services the ethereum package offers.
@@ -346,7 +442,77 @@ func (s *Ethereum) APIs() []rpc.API {
 		apis = append(apis, rpc.API{
 			Namespace: "aut",
 			Version:   params.Version,
-			Service:   NewAutonityContractAPI(s.BlockChain(), s.BlockChain().ProtocolContracts()),
+			Service:   newAutonityContractAPI(s.protocolState, s.BlockChain().ProtocolContracts()),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicAutonityAPI(s.BlockChain(), &chainValidatorSet{chain: s.BlockChain()}),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicNodeInfoAPI(s.BlockChain(), &chainProtocolInfo{chain: s.BlockChain()}, s.networkID, s.address),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicCommitJournalAPI(s.BlockChain().CommitJournal()),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicAccountabilityGasAPI(s.BlockChain()),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicAccountabilityAPI(s.accountability),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPrivateAccountabilityAPI(s.accountability),
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicDiskAPI(s.diskWatchdog),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicValidatorStatusAPI(s.validatorStatus, s.p2pServer),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicStateHealthAPI(s.stateGuard),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicCommitteeChangeAPI(s.APIBackend),
+			Public:    true,
+		}, rpc.API{
+			// registered after the protocol contract API, whose getCommittee view it supersedes
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicCommitteeAPI(&apiCommitteeReader{backend: s.APIBackend}),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicStakeConcentrationAPI(&apiCommitteeReader{backend: s.APIBackend}),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicAccountabilityWatchAPI(s.APIBackend, s.accountabilityEvents),
+			Public:    true,
+		}, rpc.API{
+			Namespace: "aut",
+			Version:   params.Version,
+			Service:   NewPublicRegistrationAPI(&chainRegistration{chain: s.BlockChain()}, s.config.RPCRegistrationProbe),
 			Public:    true,
 		})
 	}
@@ -391,6 +557,16 @@ func (s *Ethereum) APIs() []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(s),
+		}, {
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   NewPrivateTendermintMessagesAPI(s.msgStore, s.blockchain),
+		}, {
+			Namespace: "debug",
+			Version:   "1.0",
+			Service: NewPrivateTopologyAPI(&chainCommitteeEnodes{chain: s.blockchain, state: s.protocolState}, s.config.ConsensusMeshDegree, func() enode.ID {
+				return s.p2pServer.Self().ID()
+			}),
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -523,6 +699,16 @@ func (s *Ethereum) StopMining() {
 func (s *Ethereum) IsMining() bool      { return s.miner.Mining() }
 func (s *Ethereum) Miner() *miner.Miner { return s.miner }
 
+// SetExtraData sets the metadata appended to the version prefix in the extra data of the blocks
+// proposed from now on.
+func (s *Ethereum) SetExtraData(metadata []byte) error {
+	extra, err := makeExtraData(metadata)
+	if err != nil {
+		return err
+	}
+	return s.miner.SetExtra(extra)
+}
+
 func (s *Ethereum) AccountManager() *accounts.Manager  { return s.accountManager }
 func (s *Ethereum) BlockChain() *core.BlockChain       { return s.blockchain }
 func (s *Ethereum) TxPool() *core.TxPool               { return s.txPool }
@@ -530,6 +716,7 @@ func (s *Ethereum) EventMux() *event.TypeMux           { return s.eventMux }
 func (s *Ethereum) Engine() consensus.Engine           { return s.engine }
 func (s *Ethereum) FD() *accountability.FaultDetector  { return s.accountability }
 func (s *Ethereum) ChainDb() ethdb.Database            { return s.chainDb }
+func (s *Ethereum) MeshHistory() *p2p.MeshHistory      { return s.meshHistory }
 func (s *Ethereum) IsListening() bool                  { return true } // Always listening
 func (s *Ethereum) Downloader() *downloader.Downloader { return s.handler.downloader }
 func (s *Ethereum) Synced() bool                       { return atomic.LoadUint32(&s.handler.acceptTxs) == 1 }
@@ -554,23 +741,35 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 // Start implements node.Lifecycle, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
 func (s *Ethereum) Start() error {
-	go s.accountability.Start()
-
-	go func() {
+	// the accountability and the validator controller drive the consensus engine, they only start
+	// once it is attached to the chain.
+	ready := engineReady(s.engine)
+	select {
+	case <-ready:
+	default:
+		s.log.Error("Consensus engine not attached to the chain, delaying the validator startup")
+	}
+	go runWhenReady(ready, s.shutdownCtx.Done(), s.accountability.Start)
+	go runWhenReady(ready, s.shutdownCtx.Done(), func() {
 		header := s.blockchain.CurrentHeader()
 		if header.Number.BitLen() == 0 && header.Time > uint64(time.Now().Unix()) {
 			s.genesisCountdown()
 		}
 		s.validatorController()
-	}()
+	})
 
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 	// Start the bloom bits servicing goroutines
-	s.startBloomHandlers(params.BloomBitsBlocks)
+	s.startBloomHandlers(s.bloomGenerations[len(s.bloomGenerations)-1].SectionSize)
 
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
 
+	if s.diskWatchdog != nil {
+		s.diskWatchdog.Start()
+	}
+	s.stateGuard.Start()
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	if s.config.LightServ > 0 {
@@ -584,36 +783,95 @@ func (s *Ethereum) Start() error {
 	return nil
 }
 
+// engineReady returns the channel closed once the engine is attached to the chain, engines without
+// a lifecycle are always ready.
+func engineReady(engine consensus.Engine) <-chan struct{} {
+	if be, ok := engine.(consensus.BFTLifecycle[*core.BlockChain]); ok {
+		return be.Ready()
+	}
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+// runWhenReady calls run once ready is closed, unless quit is closed first.
+func runWhenReady(ready, quit <-chan struct{}, run func()) {
+	select {
+	case <-ready:
+	case <-quit:
+		return
+	}
+	select {
+	case <-quit:
+	default:
+		run()
+	}
+}
+
 // This routine is responsible to communicate to devp2p who are the other consensus members
 // if the local node is part of the consensus committee or not. It also control the miner start/stop functions.
 // todo(youssef): listen to new epoch events instead
 func (s *Ethereum) validatorController() {
 	chainHeadCh := make(chan core.ChainHeadEvent)
 	chainHeadSub := s.blockchain.SubscribeChainHeadEvent(chainHeadCh)
+	// all the updates go through the updater, so that a delayed update cannot override a later one
+	enodesUpdater := p2p.NewConsensusEnodesUpdater(s.p2pServer)
+	enodesUpdater.SetRecorder(s.meshHistory.Recorder(s.p2pServer))
+	defer enodesUpdater.Stop()
 
+	// the skipped committee enodes are logged once per epoch rather than at every block
+	var (
+		skippedLogged bool
+		skippedEpoch  uint64
+	)
+	logSkippedEnodes := func(block *types.Block, errs []*types.NodeError) {
+		epoch := block.NumberU64() / max(s.blockchain.ProtocolContracts().Cache.EpochPeriod().Uint64(), 1)
+		if len(errs) == 0 || (skippedLogged && epoch == skippedEpoch) {
+			return
+		}
+		skippedLogged, skippedEpoch = true, epoch
+		for _, err := range errs {
+			s.log.Warn("Skipped invalid committee enode", "block", block.NumberU64(), "enode", err.Enode, "err", err.Err)
+		}
+	}
+
+	// the state reads are given up after repeated failures, until the head root changes
+	states := core.NewStateGuard("committee watcher", s.blockchain.StateAt, core.DefaultStateGuardFailures, s.log)
 	updateConsensusEnodes := func(block *types.Block) {
-		state, err := s.blockchain.StateAt(block.Header().Root)
+		state, err := states.StateAt(block.Header().Root)
 		if err != nil {
-			s.log.Error("Could not retrieve state at head block", "err", err)
 			return
 		}
-		committee, err := s.blockchain.ProtocolContracts().CommitteeEnodes(block, state, false)
+		committee, err := s.blockchain.ProtocolContracts().CommitteeEnodes(s.shutdownCtx, block, state, false)
 		if err != nil {
 			s.log.Error("Could not retrieve consensus whitelist at head block", "err", err)
 			return
 		}
-
-		index := s.topologySelector.MyIndex(committee.List, s.p2pServer.LocalNode())
-		s.p2pServer.UpdateConsensusEnodes(s.topologySelector.RequestSubset(committee.List, index), committee.List)
+		logSkippedEnodes(block, committee.Errors)
+		s.validatorStatus.setCommitteeEnodes(committee.List)
+
+		// the enodes are in the order of the committee, unless invalid ones were skipped
+		header := block.Header()
+		index := header.MemberIndex(s.address)
+		if len(committee.List) != len(header.Committee) {
+			index = s.topologySelector.MyIndex(committee.List, s.p2pServer.LocalNode())
+		}
+		enodesUpdater.Update(s.topologySelector.RequestSubset(committee.List, index), committee.List)
+		s.log.Debug("Updated consensus mesh", "committee", len(committee.List), "degree", s.topologySelector.Degree())
 	}
 	wasValidating := false
 	currentBlock := s.blockchain.CurrentBlock()
+	s.p2pServer.SetCurrentBlockNumber(currentBlock.NumberU64())
 	if currentBlock.Header().CommitteeMember(s.address) != nil {
 		updateConsensusEnodes(currentBlock)
-		s.miner.Start()
+		if s.stateGuard.Participating() {
+			s.miner.Start()
+		}
 		s.log.Info("Starting node as validator")
 		wasValidating = true
 	}
+	s.validatorStatus.update(currentBlock.NumberU64(), len(currentBlock.Header().Committee), wasValidating, wasValidating && s.stateGuard.Participating())
+	s.committeeChanges.observe(currentBlock.Header())
 
 	for {
 		select {
@@ -621,6 +879,7 @@ func (s *Ethereum) validatorController() {
 			// current block number is cached in server
 			s.p2pServer.SetCurrentBlockNumber(ev.Block.NumberU64())
 			header := ev.Block.Header()
+			s.committeeChanges.observe(header)
 			// check if the local node belongs to the consensus committee.
 			if header.CommitteeMember(s.address) == nil {
 				// if the local node was part of the committee set for the previous block
@@ -629,18 +888,22 @@ func (s *Ethereum) validatorController() {
 				if wasValidating {
 					s.log.Info("Local node no longer detected part of the consensus committee, mining stopped")
 					s.miner.Stop()
-					s.p2pServer.UpdateConsensusEnodes(nil, nil)
+					enodesUpdater.Update(nil, nil)
 					wasValidating = false
 				}
+				s.validatorStatus.update(ev.Block.NumberU64(), len(header.Committee), false, false)
 				continue
 			}
 			updateConsensusEnodes(ev.Block)
 			// if we were not committee in the past block we need to enable the mining engine.
-			if !wasValidating {
+			// the state divergence guard resumes the mining itself once the local state recovered
+			participating := s.stateGuard.Participating()
+			if !wasValidating && participating {
 				s.log.Info("Local node detected part of the consensus committee, mining started")
 				s.miner.Start()
 			}
 			wasValidating = true
+			s.validatorStatus.update(ev.Block.NumberU64(), len(header.Committee), true, participating)
 		// Err() channel will be closed when unsubscribing.
 		case <-chainHeadSub.Err():
 			return
@@ -651,6 +914,7 @@ func (s *Ethereum) validatorController() {
 // Stop implements node.Service, terminating all internal goroutines used by the
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
+	s.shutdownCancel()
 	// Stop AFD first,
 	s.accountability.Stop()
 	s.engine.Close()
@@ -659,6 +923,10 @@ func (s *Ethereum) Stop() error {
 	s.snapDialCandidates.Close()
 	s.handler.Stop()
 	// Then stop everything else.
+	if s.diskWatchdog != nil {
+		s.diskWatchdog.Stop()
+	}
+	s.stateGuard.Stop()
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
 	s.txPool.Stop()
@@ -668,12 +936,51 @@ func (s *Ethereum) Stop() error {
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
 
+	s.meshHistory.Close()
 	s.chainDb.Close()
 	s.eventMux.Stop()
 
 	return nil
 }
 
+// haltValidator stops the consensus participation once the local state diverged from the network.
+func (s *Ethereum) haltValidator() {
+	s.miner.Stop()
+	s.validatorStatus.setMiningActive(false)
+}
+
+// resumeValidator resumes the consensus participation once the local state recovered, the mining
+// starting as soon as the node is synced if it is in the committee.
+func (s *Ethereum) resumeValidator() {
+	if s.validatorStatus.isInCommittee() {
+		s.log.Info("Local node part of the consensus committee, mining restarted")
+		s.miner.Start()
+		s.validatorStatus.setMiningActive(true)
+	}
+}
+
+// newDiskWatchdog returns the watchdog of the free space of the chain database and freezer
+// paths, nil for an ephemeral node or if both thresholds are disabled.
+func newDiskWatchdog(stack *node.Node, config *ethconfig.Config) *core.DiskWatchdog {
+	if stack.Config().DataDir == "" || (config.DiskSoftFreeSpace == 0 && config.DiskHardFreeSpace == 0) {
+		return nil
+	}
+	// same resolution as for the database opening
+	chaindata := stack.ResolvePath("chaindata")
+	freezer := config.DatabaseFreezer
+	switch {
+	case freezer == "":
+		freezer = filepath.Join(chaindata, "ancient")
+	case !filepath.IsAbs(freezer):
+		freezer = stack.ResolvePath(freezer)
+	}
+	return core.NewDiskWatchdog(core.DiskWatchdogConfig{
+		Paths:         []string{chaindata, freezer},
+		SoftThreshold: config.DiskSoftFreeSpace * 1024 * 1024,
+		HardThreshold: config.DiskHardFreeSpace * 1024 * 1024,
+	}, diskusage.FreeSpace, stack.Logger())
+}
+
 func (s *Ethereum) genesisCountdown() {
 	genesisTime := time.Unix(int64(s.blockchain.Genesis().Time()), 0)
 	s.log.Info(fmt.Sprintf("Chain genesis time: %v", genesisTime))
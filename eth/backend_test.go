@@ -0,0 +1,69 @@
+package eth
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rlp"
+)
+
+func TestMakeExtraData(t *testing.T) {
+	prefix := extraDataPrefix()
+	available := int(params.MaximumExtraDataSize) - len(prefix)
+	require.GreaterOrEqual(t, available, 0)
+
+	extra, err := makeExtraData(nil)
+	require.NoError(t, err)
+	require.Equal(t, prefix, extra)
+
+	metadata := bytes.Repeat([]byte{0xaa}, available)
+	extra, err = makeExtraData(metadata)
+	require.NoError(t, err)
+	// the version prefix is kept for the client to be identified, the metadata follows it
+	version, rest, err := rlp.SplitList(extra)
+	require.NoError(t, err)
+	require.NotEmpty(t, version)
+	require.Equal(t, metadata, rest)
+
+	_, err = makeExtraData(append(metadata, 0xbb))
+	require.ErrorIs(t, err, errExtraDataTooLong)
+}
+
+func TestRunWhenReady(t *testing.T) {
+	t.Run("run once ready", func(t *testing.T) {
+		ready, quit, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+		go func() {
+			runWhenReady(ready, quit, func() { close(done) })
+		}()
+		select {
+		case <-done:
+			t.Fatal("run before the engine is ready")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(ready)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("run not called once the engine is ready")
+		}
+	})
+	t.Run("not run if stopped before ready", func(t *testing.T) {
+		ready, quit := make(chan struct{}), make(chan struct{})
+		close(quit)
+		runWhenReady(ready, quit, func() { t.Fatal("run after the node stopped") })
+		close(ready)
+		runWhenReady(ready, quit, func() { t.Fatal("run after the node stopped") })
+	})
+	t.Run("engines without lifecycle are ready", func(t *testing.T) {
+		select {
+		case <-engineReady(ethash.NewFaker()):
+		default:
+			t.Fatal("engine without lifecycle not ready")
+		}
+	})
+}
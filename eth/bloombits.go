@@ -43,6 +43,8 @@ const (
 
 // startBloomHandlers starts a batch of goroutines to accept bloom bit database
 // retrievals from possibly a range of filters and serving the data to satisfy.
+// The sections are read with the section size of the requesting matcher, falling
+// back to the given one.
 func (eth *Ethereum) startBloomHandlers(sectionSize uint64) {
 	for i := 0; i < bloomServiceThreads; i++ {
 		go func() {
@@ -53,11 +55,15 @@ func (eth *Ethereum) startBloomHandlers(sectionSize uint64) {
 
 				case request := <-eth.bloomRequests:
 					task := <-request
+					size := task.SectionSize
+					if size == 0 {
+						size = sectionSize
+					}
 					task.Bitsets = make([][]byte, len(task.Sections))
 					for i, section := range task.Sections {
-						head := rawdb.ReadCanonicalHash(eth.chainDb, (section+1)*sectionSize-1)
+						head := rawdb.ReadCanonicalHash(eth.chainDb, (section+1)*size-1)
 						if compVector, err := rawdb.ReadBloomBits(eth.chainDb, task.Bit, section, head); err == nil {
-							if blob, err := bitutil.DecompressBytes(compVector, int(sectionSize/8)); err == nil {
+							if blob, err := bitutil.DecompressBytes(compVector, int(size/8)); err == nil {
 								task.Bitsets[i] = blob
 							} else {
 								task.Error = err
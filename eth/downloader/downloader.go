@@ -60,6 +60,10 @@ var (
 	fsMinFullBlocks        = 64              // Number of blocks to retrieve fully even in snap sync
 )
 
+// fullSyncFallback is the sync strategy reported when snap sync was requested but the remote chain is
+// too short for it.
+const fullSyncFallback = "full (snap sync fallback)"
+
 var (
 	errBusy                    = errors.New("busy")
 	errUnknownPeer             = errors.New("peer is unknown or unhealthy")
@@ -127,6 +131,14 @@ type Downloader struct {
 	pivotHeader *types.Header // Pivot block header to dynamically push the syncing state root
 	pivotLock   sync.RWMutex  // Lock protecting pivot header reads from updates
 
+	stateHeaders     map[common.Hash]*types.Header // Pivot headers the state was synced at, by state root
+	stateHeadersLock sync.Mutex                    // Lock protecting the state headers
+
+	// Snap sync pivot selection
+	snapMinBlocks uint64        // Minimum remote chain length to snap sync, shorter chains are full synced
+	epochPeriod   func() uint64 // Epoch length, the headers from the start of the pivot epoch are fully verified
+	strategy      string        // Sync strategy of the current sync cycle, protected by syncStatsLock
+
 	snapSync       bool         // Whether to run state sync over the snap protocol
 	SnapSyncer     *snap.Syncer // TODO(karalabe): make private! hack for now
 	stateSyncStart chan *stateSync
@@ -141,10 +153,11 @@ type Downloader struct {
 	quitLock sync.Mutex    // Lock to prevent double closes
 
 	// Testing hooks
-	syncInitHook     func(uint64, uint64)  // Method to call upon initiating a new sync run
-	bodyFetchHook    func([]*types.Header) // Method to call upon starting a block body fetch
-	receiptFetchHook func([]*types.Header) // Method to call upon starting a receipt fetch
-	chainInsertHook  func([]*fetchResult)  // Method to call upon inserting a chain of blocks (possibly in multiple invocations)
+	syncInitHook     func(uint64, uint64)       // Method to call upon initiating a new sync run
+	bodyFetchHook    func([]*types.Header)      // Method to call upon starting a block body fetch
+	receiptFetchHook func([]*types.Header)      // Method to call upon starting a receipt fetch
+	chainInsertHook  func([]*fetchResult)       // Method to call upon inserting a chain of blocks (possibly in multiple invocations)
+	headerInsertHook func([]*types.Header, int) // Method to call upon inserting a batch of headers with their verification frequency
 }
 
 // LightChain encapsulates functions required to synchronise a light chain.
@@ -249,10 +262,24 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 	}
 	progress, pending := d.SnapSyncer.Progress()
 
+	var (
+		pivot         uint64
+		protocolState *types.Header
+	)
+	if mode == SnapSync {
+		d.pivotLock.RLock()
+		if d.pivotHeader != nil {
+			pivot = d.pivotHeader.Number.Uint64()
+		}
+		d.pivotLock.RUnlock()
+		protocolState = d.PriorityState()
+	}
 	return ethereum.SyncProgress{
 		StartingBlock:       d.syncStatsChainOrigin,
 		CurrentBlock:        current,
 		HighestBlock:        d.syncStatsChainHeight,
+		SyncStrategy:        d.strategy,
+		PivotBlock:          pivot,
 		SyncedAccounts:      progress.AccountSynced,
 		SyncedAccountBytes:  uint64(progress.AccountBytes),
 		SyncedBytecodes:     progress.BytecodeSynced,
@@ -265,9 +292,53 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 		HealedBytecodeBytes: uint64(progress.BytecodeHealBytes),
 		HealingTrienodes:    pending.TrienodeHeal,
 		HealingBytecode:     pending.BytecodeHeal,
+
+		ProtocolStateAvailable: protocolState != nil,
+		ProtocolStateBlock:     headerNumber(protocolState),
 	}
 }
 
+// PriorityState returns the pivot header the priority accounts of the snap syncer were synced at, or nil
+// if they are not synced yet. Their state is read from the flat snapshot until the state sync completes.
+func (d *Downloader) PriorityState() *types.Header {
+	root, ok := d.SnapSyncer.PriorityState()
+	if !ok {
+		return nil
+	}
+	d.stateHeadersLock.Lock()
+	defer d.stateHeadersLock.Unlock()
+	return d.stateHeaders[root]
+}
+
+func headerNumber(header *types.Header) uint64 {
+	if header == nil {
+		return 0
+	}
+	return header.Number.Uint64()
+}
+
+// SetSnapSyncParams configures the snap sync pivot selection for Autonity chains. Snap sync falls back
+// to full sync when the remote chain is shorter than minBlocks, zero disabling the fallback. The committee
+// and the quorum certificates are verified against the epoch headers, so all the headers from the start of
+// the pivot epoch are fully verified, rather than only the ones around the pivot.
+func (d *Downloader) SetSnapSyncParams(minBlocks uint64, epochPeriod func() uint64) {
+	d.snapMinBlocks = minBlocks
+	d.epochPeriod = epochPeriod
+}
+
+// pivotEpochStart returns the first block of the epoch of the pivot, or the pivot itself if the epoch
+// length is unknown.
+func (d *Downloader) pivotEpochStart(pivot uint64) uint64 {
+	if d.epochPeriod == nil {
+		return pivot
+	}
+	period := d.epochPeriod()
+	if period == 0 {
+		return pivot
+	}
+	return pivot - pivot%period
+}
+
 // Synchronising returns whether the downloader is currently retrieving blocks.
 func (d *Downloader) Synchronising() bool {
 	return atomic.LoadInt32(&d.synchronising) > 0
@@ -441,6 +512,14 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 	if err != nil {
 		return err
 	}
+	height := latest.Number.Uint64()
+	strategy := mode.String()
+	if mode == SnapSync && height < d.snapMinBlocks {
+		// Young chains are quickly full synced, and their state is too small to be worth a snap sync
+		log.Info("Remote chain too short for snap sync, falling back to full sync", "height", height, "threshold", d.snapMinBlocks)
+		mode, pivot, strategy = FullSync, nil, fullSyncFallback
+		atomic.StoreUint32(&d.mode, uint32(mode))
+	}
 	if mode == SnapSync && pivot == nil {
 		// If no pivot block was returned, the head is below the min full block
 		// threshold (i.e. new chain). In that case we won't really snap sync
@@ -448,7 +527,6 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 		// nil panics on an access.
 		pivot = d.blockchain.CurrentBlock().Header()
 	}
-	height := latest.Number.Uint64()
 
 	origin, err := d.findAncestor(p, latest)
 	if err != nil {
@@ -459,6 +537,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 		d.syncStatsChainOrigin = origin
 	}
 	d.syncStatsChainHeight = height
+	d.strategy = strategy
 	d.syncStatsLock.Unlock()
 
 	// Ensure our origin point is below any snap sync pivot point
@@ -466,10 +545,17 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 		if height <= uint64(fsMinFullBlocks) {
 			origin = 0
 		} else {
+			// The headers from the start of the pivot epoch must be downloaded and fully verified,
+			// even if some of them are already known locally
 			pivotNumber := pivot.Number.Uint64()
-			if pivotNumber <= origin {
-				origin = pivotNumber - 1
+			epochStart := d.pivotEpochStart(pivotNumber)
+			if epochStart <= origin {
+				origin = 0
+				if epochStart > 0 {
+					origin = epochStart - 1
+				}
 			}
+			log.Info("Selected snap sync pivot", "number", pivotNumber, "epochStart", epochStart)
 			// Write out the pivot into the database so a rollback beyond it will
 			// reenable snap sync
 			rawdb.WriteLastPivotNumber(d.stateDB, pivotNumber)
@@ -1278,9 +1364,12 @@ func (d *Downloader) processHeaders(origin uint64, td *big.Int) error {
 					d.pivotLock.RUnlock()
 
 					frequency := fsHeaderCheckFrequency
-					if chunkHeaders[len(chunkHeaders)-1].Number.Uint64()+uint64(fsHeaderForceVerify) > pivot {
+					if chunkHeaders[len(chunkHeaders)-1].Number.Uint64()+uint64(fsHeaderForceVerify) > d.pivotEpochStart(pivot) {
 						frequency = 1
 					}
+					if d.headerInsertHook != nil {
+						d.headerInsertHook(chunkHeaders, frequency)
+					}
 					if n, err := d.lightchain.InsertHeaderChain(chunkHeaders, frequency); err != nil {
 						rollbackErr = err
 
@@ -1401,7 +1490,7 @@ func (d *Downloader) processSnapSyncContent() error {
 	// Start syncing state of the reported head block. This should get us most of
 	// the state of the pivot block.
 	d.pivotLock.RLock()
-	sync := d.syncState(d.pivotHeader.Root)
+	sync := d.syncState(d.pivotHeader)
 	d.pivotLock.RUnlock()
 
 	defer func() {
@@ -1453,7 +1542,7 @@ func (d *Downloader) processSnapSyncContent() error {
 		if oldPivot == nil {
 			if pivot.Root != sync.root {
 				sync.Cancel()
-				sync = d.syncState(pivot.Root)
+				sync = d.syncState(pivot)
 
 				go closeOnErr(sync)
 			}
@@ -1491,7 +1580,7 @@ func (d *Downloader) processSnapSyncContent() error {
 			// If new pivot block found, cancel old state retrieval and restart
 			if oldPivot != P {
 				sync.Cancel()
-				sync = d.syncState(P.Header.Root)
+				sync = d.syncState(P.Header)
 
 				go closeOnErr(sync)
 				oldPivot = P
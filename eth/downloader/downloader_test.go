@@ -1369,3 +1369,69 @@ func testCheckpointEnforcement(t *testing.T, protocol uint, mode SyncMode) {
 		assertOwnChain(t, tester, len(chain.blocks))
 	}
 }
+
+// Tests that snap sync falls back to full sync when the remote chain is shorter than
+// the configured threshold, and that otherwise all the headers from the start of the
+// pivot epoch are fully verified.
+func TestSnapSyncPivotSelection66(t *testing.T) {
+	const epochPeriod = 30
+
+	tests := []struct {
+		name      string
+		chain     *testChain
+		minBlocks uint64
+		strategy  string
+	}{
+		{"short chain below threshold", testChainBase.shorten(500), 1000, fullSyncFallback},
+		{"short chain", testChainBase.shorten(500), 100, SnapSync.String()},
+		{"long chain", testChainLong, 1000, SnapSync.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTester()
+			defer tester.terminate()
+
+			tester.downloader.SetSnapSyncParams(tt.minBlocks, func() uint64 { return epochPeriod })
+
+			// Track the highest header inserted without full verification
+			var (
+				lock    sync.Mutex
+				sampled uint64
+			)
+			tester.downloader.headerInsertHook = func(headers []*types.Header, frequency int) {
+				lock.Lock()
+				defer lock.Unlock()
+				if last := headers[len(headers)-1].Number.Uint64(); frequency > 1 && last > sampled {
+					sampled = last
+				}
+			}
+			tester.newPeer("peer", eth.ETH66, tt.chain.blocks[1:])
+			if err := tester.sync("peer", nil, SnapSync); err != nil {
+				t.Fatalf("failed to synchronise blocks: %v", err)
+			}
+			assertOwnChain(t, tester, len(tt.chain.blocks))
+
+			progress := tester.downloader.Progress()
+			if progress.SyncStrategy != tt.strategy {
+				t.Fatalf("sync strategy mismatch: have %q, want %q", progress.SyncStrategy, tt.strategy)
+			}
+			if tt.strategy != SnapSync.String() {
+				if progress.PivotBlock != 0 {
+					t.Fatalf("pivot %d selected while full syncing", progress.PivotBlock)
+				}
+				return
+			}
+			pivot := progress.PivotBlock
+			if want := uint64(len(tt.chain.blocks)-1) - uint64(fsMinFullBlocks); pivot != want {
+				t.Fatalf("pivot mismatch: have %d, want %d", pivot, want)
+			}
+			epochStart := pivot - pivot%epochPeriod
+			if sampled >= epochStart {
+				t.Fatalf("header %d not fully verified, after the pivot epoch start %d", sampled, epochStart)
+			}
+			if header := tester.chain.GetHeaderByNumber(epochStart); header == nil || header.Hash() != tt.chain.blocks[epochStart].Hash() {
+				t.Fatalf("pivot epoch start %d not synchronised", epochStart)
+			}
+		})
+	}
+}
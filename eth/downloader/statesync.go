@@ -20,13 +20,21 @@ import (
 	"sync"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/log"
 )
 
-// syncState starts downloading state with the given root hash.
-func (d *Downloader) syncState(root common.Hash) *stateSync {
+// syncState starts downloading the state of the given pivot header.
+func (d *Downloader) syncState(pivot *types.Header) *stateSync {
+	d.stateHeadersLock.Lock()
+	if d.stateHeaders == nil {
+		d.stateHeaders = make(map[common.Hash]*types.Header)
+	}
+	d.stateHeaders[pivot.Root] = pivot
+	d.stateHeadersLock.Unlock()
+
 	// Create the state sync
-	s := newStateSync(d, root)
+	s := newStateSync(d, pivot.Root)
 	select {
 	case d.stateSyncStart <- s:
 		// If we tell the statesync to restart with a new root, we also need
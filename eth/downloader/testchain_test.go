@@ -48,6 +48,12 @@ var testChainBase *testChain
 // Different forks on top of the base chain:
 var testChainForkLightA, testChainForkLightB, testChainForkHeavy *testChain
 
+// A long extension of the base chain, spanning many epochs:
+var testChainLong *testChain
+
+// testChainLongLength is the number of blocks of the long test chain.
+const testChainLongLength = 50000
+
 var pregenerated bool
 
 func init() {
@@ -61,13 +67,15 @@ func init() {
 	testChainBase = newTestChain(blockCacheMaxItems+200, testGenesis)
 
 	var forkLen = int(fullMaxForkAncestry + 50)
+	var longLen = testChainLongLength - len(testChainBase.blocks)
 	var wg sync.WaitGroup
 
 	// Generate the test chains to seed the peers with
-	wg.Add(3)
+	wg.Add(4)
 	go func() { testChainForkLightA = testChainBase.makeFork(forkLen, false, 1); wg.Done() }()
 	go func() { testChainForkLightB = testChainBase.makeFork(forkLen, false, 2); wg.Done() }()
 	go func() { testChainForkHeavy = testChainBase.makeFork(forkLen, true, 3); wg.Done() }()
+	go func() { testChainLong = testChainBase.makeFork(longLen, false, 4); wg.Done() }()
 	wg.Wait()
 
 	// Generate the test peers used by the tests to avoid overloading during testing.
@@ -78,7 +86,9 @@ func init() {
 		testChainForkLightA,
 		testChainForkLightB,
 		testChainForkHeavy,
+		testChainLong,
 		testChainBase.shorten(1),
+		testChainBase.shorten(500),
 		testChainBase.shorten(blockCacheMaxItems - 15),
 		testChainBase.shorten((blockCacheMaxItems - 15) / 2),
 		testChainBase.shorten(blockCacheMaxItems - 15 - 5),
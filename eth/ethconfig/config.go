@@ -23,6 +23,7 @@ import (
 	"github.com/autonity/autonity/core/vm"
 	"github.com/autonity/autonity/event"
 
+	"fmt"
 	"math/big"
 	"os"
 	"os/user"
@@ -33,6 +34,7 @@ import (
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/eth/downloader"
 	"github.com/autonity/autonity/eth/gasprice"
@@ -64,7 +66,8 @@ var LightClientGPO = gasprice.Config{
 
 // Defaults contains default settings for use on the Ethereum main net.
 var Defaults = Config{
-	SyncMode: downloader.SnapSync,
+	SyncMode:          downloader.SnapSync,
+	SnapSyncMinBlocks: 10000,
 	Ethash: ethash.Config{
 		CacheDir:         "ethash",
 		CachesInMem:      2,
@@ -76,6 +79,12 @@ var Defaults = Config{
 	},
 	NetworkID:               65000000,
 	TxLookupLimit:           2350000,
+	MeshHistoryEntries:      10000,
+	ConsensusMeshDegree:     20,
+	CommitJournalEntries:    100000,
+	DiskSoftFreeSpace:       4096,
+	DiskHardFreeSpace:       1024,
+	AutoResyncTimeout:       30 * time.Minute,
 	LightPeers:              100,
 	UltraLightFraction:      75,
 	DatabaseCache:           512,
@@ -90,11 +99,14 @@ var Defaults = Config{
 		GasPrice: big.NewInt(500_000_000),
 		Recommit: 3 * time.Second,
 	},
-	TxPool:        core.DefaultTxPoolConfig,
-	RPCGasCap:     50000000,
-	RPCEVMTimeout: 5 * time.Second,
-	GPO:           FullNodeGPO,
-	RPCTxFeeCap:   1, // 1 ether
+	Tendermint:       tendermintcore.DefaultConfig,
+	TxPool:           core.DefaultTxPoolConfig,
+	RPCGasCap:        50000000,
+	RPCEVMTimeout:    5 * time.Second,
+	GPO:              FullNodeGPO,
+	Accountability:   accountability.DefaultConfig,
+	RPCTxFeeCap:      1, // 1 ether
+	RPCTxLookupRange: 100_000,
 }
 
 func init() {
@@ -130,6 +142,9 @@ type Config struct {
 	NetworkID uint64 // Network ID to use for selecting peers to connect to
 	SyncMode  downloader.SyncMode
 
+	SnapSyncMinBlocks     uint64 // Minimum chain length to snap sync, shorter chains are full synced (0 = no minimum)
+	SnapSyncProtocolFirst bool   // Whether to snap sync the protocol contracts state ahead of the rest of the state
+
 	// This can be set to list of enrtree:// URLs which will be queried for
 	// for nodes to connect to.
 	EthDiscoveryURLs  []string
@@ -140,6 +155,21 @@ type Config struct {
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
+	BloomBitsBlocks uint64 `toml:",omitempty"` // Number of blocks per bloombits section, 0 to derive it from the chain's block period
+
+	MeshHistoryEntries uint64 // The maximum number of consensus mesh history entries kept for forensics, 0 to disable
+
+	ConsensusMeshDegree int // Committee size up to which the consensus peers are fully meshed, 0 for a full mesh at any size
+
+	CommitJournalEntries uint64        // The maximum number of committed blocks kept in the commit journal, 0 to disable
+	CommitJournalAge     time.Duration // The maximum age of the commit journal entries, 0 for no limit
+
+	DiskSoftFreeSpace uint64 // Free disk space in MB below which the non-essential writes are paused, 0 to disable
+	DiskHardFreeSpace uint64 // Free disk space in MB below which the block import is suspended, 0 to disable
+
+	AutoResync        bool          // Whether to rewind to a verified state once the local state diverged from the network
+	AutoResyncTimeout time.Duration // The time given to the automatic resync to recover before giving up
+
 	// map of required blocks (block numbers -> hash values) to accept
 	RequiredBlocks map[uint64]common.Hash `toml:"-"`
 
@@ -177,15 +207,24 @@ type Config struct {
 	// Ethash options
 	Ethash ethash.Config
 
+	// Tendermint options
+	Tendermint tendermintcore.Config
+
 	// Transaction pool options
 	TxPool core.TxPoolConfig
 
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
+	// Accountability transactions fee management options
+	Accountability accountability.Config
+
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables tracing of the protocol contract calls made by the node itself
+	InternalCallTracing bool
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -199,6 +238,14 @@ type Config struct {
 	// send-transction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RPCTxLookupRange is the maximum number of blocks scanned by a ranged transaction lookup,
+	// for the transactions which are no longer indexed.
+	RPCTxLookupRange uint64
+
+	// RPCRegistrationProbe allows aut_validateRegistration to dial the enode of the registered validator
+	// to check that it is reachable.
+	RPCRegistrationProbe bool
+
 	// Checkpoint is a hardcoded checkpoint which can be nil.
 	Checkpoint *params.TrustedCheckpoint `toml:",omitempty"`
 
@@ -212,6 +259,28 @@ type Config struct {
 	OverrideTerminalTotalDifficulty *big.Int `toml:",omitempty"`
 }
 
+// BloomBitsSectionSize returns the number of blocks per bloombits section to
+// index the chain with. Unless configured, it is derived from the block period
+// declared by the chain so that a section spans about the same time as on a
+// chain with the block time the default size is tuned for.
+func BloomBitsSectionSize(config *Config, chainConfig *params.ChainConfig) (uint64, error) {
+	if size := config.BloomBitsBlocks; size != 0 {
+		if size&(size-1) != 0 || size < params.MinBloomBitsBlocks || size > params.MaxBloomBitsBlocks {
+			return 0, fmt.Errorf("invalid bloombits section size %d, want a power of two within [%d, %d]", size, params.MinBloomBitsBlocks, params.MaxBloomBitsBlocks)
+		}
+		return size, nil
+	}
+	if chainConfig.AutonityContractConfig == nil || chainConfig.AutonityContractConfig.BlockPeriod == 0 {
+		return params.BloomBitsBlocks, nil
+	}
+	span := params.BloomBitsBlocks * params.BloomBitsBlockTime / chainConfig.AutonityContractConfig.BlockPeriod
+	size := params.MinBloomBitsBlocks
+	for size < params.MaxBloomBitsBlocks && size*2 <= span {
+		size *= 2
+	}
+	return size, nil
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Ethereum service
 func CreateConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, config *Config, notify []string, noverify bool,
 	vmConfig *vm.Config, evMux *event.TypeMux, ms *tendermintcore.MsgStore) consensus.Engine {
@@ -242,5 +311,12 @@ func CreateConsensusEngine(ctx *node.Node, chainConfig *params.ChainConfig, conf
 
 	nodeKey, consensusKey := ctx.Config().AutonityKeys()
 	noGossip := ctx.Config().NoGossip
-	return tendermintBackend.New(nodeKey, consensusKey, vmConfig, ctx.Config().TendermintServices(), evMux, ms, ctx.Logger(), noGossip)
+	engine := tendermintBackend.New(nodeKey, consensusKey, vmConfig, ctx.Config().TendermintServices(), evMux, ms, ctx.Logger(), noGossip)
+	engine.SetTraceSampling(ctx.Config().ConsensusTraceSampling)
+	engine.SetConsensusDebug(ctx.Config().ConsensusDebug)
+	engine.SetVoteFairness(ctx.Config().ConsensusVoteFairness)
+	engine.SetMessageQueueSize(ctx.Config().ConsensusMessageQueueSize)
+	engine.SetCoreConfig(config.Tendermint)
+	engine.SetImportPacing(ctx.Config().ConsensusImportPacing)
+	return engine
 }
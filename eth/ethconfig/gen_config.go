@@ -8,6 +8,8 @@ import (
 
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/consensus/tendermint/accountability"
+	tendermintcore "github.com/autonity/autonity/consensus/tendermint/core"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/eth/downloader"
 	"github.com/autonity/autonity/eth/gasprice"
@@ -21,11 +23,22 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Genesis                         *core.Genesis `toml:",omitempty"`
 		NetworkId                       uint64
 		SyncMode                        downloader.SyncMode
+		SnapSyncMinBlocks               uint64
+		SnapSyncProtocolFirst           bool
 		EthDiscoveryURLs                []string
 		SnapDiscoveryURLs               []string
 		NoPruning                       bool
 		NoPrefetch                      bool
-		TxLookupLimit                   uint64                 `toml:",omitempty"`
+		TxLookupLimit                   uint64 `toml:",omitempty"`
+		BloomBitsBlocks                 uint64 `toml:",omitempty"`
+		MeshHistoryEntries              uint64
+		ConsensusMeshDegree             int
+		CommitJournalEntries            uint64
+		CommitJournalAge                time.Duration
+		DiskSoftFreeSpace               uint64
+		DiskHardFreeSpace               uint64
+		AutoResync                      bool
+		AutoResyncTimeout               time.Duration
 		RequiredBlocks                  map[uint64]common.Hash `toml:"-"`
 		LightServ                       int                    `toml:",omitempty"`
 		LightIngress                    int                    `toml:",omitempty"`
@@ -50,13 +63,18 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Preimages                       bool
 		Miner                           miner.Config
 		Ethash                          ethash.Config
+		Tendermint                      tendermintcore.Config
 		TxPool                          core.TxPoolConfig
 		GPO                             gasprice.Config
+		Accountability                  accountability.Config
 		EnablePreimageRecording         bool
+		InternalCallTracing             bool
 		DocRoot                         string `toml:"-"`
 		RPCGasCap                       uint64
 		RPCEVMTimeout                   time.Duration
 		RPCTxFeeCap                     float64
+		RPCTxLookupRange                uint64
+		RPCRegistrationProbe            bool
 		Checkpoint                      *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                *params.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideArrowGlacier            *big.Int                       `toml:",omitempty"`
@@ -66,11 +84,22 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkID
 	enc.SyncMode = c.SyncMode
+	enc.SnapSyncMinBlocks = c.SnapSyncMinBlocks
+	enc.SnapSyncProtocolFirst = c.SnapSyncProtocolFirst
 	enc.EthDiscoveryURLs = c.EthDiscoveryURLs
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.BloomBitsBlocks = c.BloomBitsBlocks
+	enc.MeshHistoryEntries = c.MeshHistoryEntries
+	enc.ConsensusMeshDegree = c.ConsensusMeshDegree
+	enc.CommitJournalEntries = c.CommitJournalEntries
+	enc.CommitJournalAge = c.CommitJournalAge
+	enc.DiskSoftFreeSpace = c.DiskSoftFreeSpace
+	enc.DiskHardFreeSpace = c.DiskHardFreeSpace
+	enc.AutoResync = c.AutoResync
+	enc.AutoResyncTimeout = c.AutoResyncTimeout
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
@@ -95,13 +124,18 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Preimages = c.Preimages
 	enc.Miner = c.Miner
 	enc.Ethash = c.Ethash
+	enc.Tendermint = c.Tendermint
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
+	enc.Accountability = c.Accountability
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.InternalCallTracing = c.InternalCallTracing
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCTxLookupRange = c.RPCTxLookupRange
+	enc.RPCRegistrationProbe = c.RPCRegistrationProbe
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
 	enc.OverrideArrowGlacier = c.OverrideArrowGlacier
@@ -115,11 +149,22 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Genesis                         *core.Genesis `toml:",omitempty"`
 		NetworkId                       *uint64
 		SyncMode                        *downloader.SyncMode
+		SnapSyncMinBlocks               *uint64
+		SnapSyncProtocolFirst           *bool
 		EthDiscoveryURLs                []string
 		SnapDiscoveryURLs               []string
 		NoPruning                       *bool
 		NoPrefetch                      *bool
-		TxLookupLimit                   *uint64                `toml:",omitempty"`
+		TxLookupLimit                   *uint64 `toml:",omitempty"`
+		BloomBitsBlocks                 *uint64 `toml:",omitempty"`
+		MeshHistoryEntries              *uint64
+		ConsensusMeshDegree             *int
+		CommitJournalEntries            *uint64
+		CommitJournalAge                *time.Duration
+		DiskSoftFreeSpace               *uint64
+		DiskHardFreeSpace               *uint64
+		AutoResync                      *bool
+		AutoResyncTimeout               *time.Duration
 		RequiredBlocks                  map[uint64]common.Hash `toml:"-"`
 		LightServ                       *int                   `toml:",omitempty"`
 		LightIngress                    *int                   `toml:",omitempty"`
@@ -144,13 +189,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Preimages                       *bool
 		Miner                           *miner.Config
 		Ethash                          *ethash.Config
+		Tendermint                      *tendermintcore.Config
 		TxPool                          *core.TxPoolConfig
 		GPO                             *gasprice.Config
+		Accountability                  *accountability.Config
 		EnablePreimageRecording         *bool
+		InternalCallTracing             *bool
 		DocRoot                         *string `toml:"-"`
 		RPCGasCap                       *uint64
 		RPCEVMTimeout                   *time.Duration
 		RPCTxFeeCap                     *float64
+		RPCTxLookupRange                *uint64
+		RPCRegistrationProbe            *bool
 		Checkpoint                      *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle                *params.CheckpointOracleConfig `toml:",omitempty"`
 		OverrideArrowGlacier            *big.Int                       `toml:",omitempty"`
@@ -169,6 +219,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncMode != nil {
 		c.SyncMode = *dec.SyncMode
 	}
+	if dec.SnapSyncMinBlocks != nil {
+		c.SnapSyncMinBlocks = *dec.SnapSyncMinBlocks
+	}
+	if dec.SnapSyncProtocolFirst != nil {
+		c.SnapSyncProtocolFirst = *dec.SnapSyncProtocolFirst
+	}
 	if dec.EthDiscoveryURLs != nil {
 		c.EthDiscoveryURLs = dec.EthDiscoveryURLs
 	}
@@ -184,6 +240,33 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.BloomBitsBlocks != nil {
+		c.BloomBitsBlocks = *dec.BloomBitsBlocks
+	}
+	if dec.MeshHistoryEntries != nil {
+		c.MeshHistoryEntries = *dec.MeshHistoryEntries
+	}
+	if dec.ConsensusMeshDegree != nil {
+		c.ConsensusMeshDegree = *dec.ConsensusMeshDegree
+	}
+	if dec.CommitJournalEntries != nil {
+		c.CommitJournalEntries = *dec.CommitJournalEntries
+	}
+	if dec.CommitJournalAge != nil {
+		c.CommitJournalAge = *dec.CommitJournalAge
+	}
+	if dec.DiskSoftFreeSpace != nil {
+		c.DiskSoftFreeSpace = *dec.DiskSoftFreeSpace
+	}
+	if dec.DiskHardFreeSpace != nil {
+		c.DiskHardFreeSpace = *dec.DiskHardFreeSpace
+	}
+	if dec.AutoResync != nil {
+		c.AutoResync = *dec.AutoResync
+	}
+	if dec.AutoResyncTimeout != nil {
+		c.AutoResyncTimeout = *dec.AutoResyncTimeout
+	}
 	if dec.RequiredBlocks != nil {
 		c.RequiredBlocks = dec.RequiredBlocks
 	}
@@ -256,15 +339,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Ethash != nil {
 		c.Ethash = *dec.Ethash
 	}
+	if dec.Tendermint != nil {
+		c.Tendermint = *dec.Tendermint
+	}
 	if dec.TxPool != nil {
 		c.TxPool = *dec.TxPool
 	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
+	if dec.Accountability != nil {
+		c.Accountability = *dec.Accountability
+	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.InternalCallTracing != nil {
+		c.InternalCallTracing = *dec.InternalCallTracing
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
@@ -277,6 +369,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCTxLookupRange != nil {
+		c.RPCTxLookupRange = *dec.RPCTxLookupRange
+	}
+	if dec.RPCRegistrationProbe != nil {
+		c.RPCRegistrationProbe = *dec.RPCRegistrationProbe
+	}
 	if dec.Checkpoint != nil {
 		c.Checkpoint = dec.Checkpoint
 	}
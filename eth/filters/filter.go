@@ -24,6 +24,7 @@ import (
 	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/bloombits"
+	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/ethdb"
 	"github.com/autonity/autonity/event"
@@ -47,6 +48,12 @@ type Backend interface {
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
+// bloomGeneration is a span of blocks indexed with the same bloombits section size.
+type bloomGeneration struct {
+	start, end uint64 // Indexed blocks, end excluded
+	size       uint64 // Number of blocks per section
+}
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	backend Backend
@@ -58,7 +65,8 @@ type Filter struct {
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
 
-	matcher *bloombits.Matcher
+	filters [][][]byte         // Bloombits filter the matchers are created with
+	matcher *bloombits.Matcher // Matcher of the current index generation
 }
 
 // NewRangeFilter creates a new filter which uses a bloom filter on blocks to
@@ -87,6 +95,7 @@ func NewRangeFilter(backend Backend, begin, end int64, addresses []common.Addres
 	// Create a generic filter and convert it into a range filter
 	filter := newFilter(backend, addresses, topics)
 
+	filter.filters = filters
 	filter.matcher = bloombits.NewMatcher(size, filters)
 	filter.begin = begin
 	filter.end = end
@@ -147,13 +156,16 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		logs []*types.Log
 		err  error
 	)
-	size, sections := f.backend.BloomStatus()
-	if indexed := sections * size; indexed > uint64(f.begin) {
-		if indexed > end {
-			logs, err = f.indexedLogs(ctx, end)
-		} else {
-			logs, err = f.indexedLogs(ctx, indexed-1)
+	for _, gen := range f.bloomGenerations() {
+		if gen.end <= uint64(f.begin) || gen.start > end {
+			continue
 		}
+		matcher := f.matcher
+		if size, _ := f.backend.BloomStatus(); gen.size != size {
+			matcher = bloombits.NewMatcher(gen.size, f.filters)
+		}
+		found, err := f.indexedLogs(ctx, matcher, min(end, gen.end-1))
+		logs = append(logs, found...)
 		if err != nil {
 			return logs, err
 		}
@@ -163,13 +175,38 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	return logs, err
 }
 
+// bloomGenerations returns the spans of the chain indexed in bloombits, ordered
+// and contiguous from the genesis. Backends generating the index with several
+// section sizes list them, the last one being the one of BloomStatus.
+func (f *Filter) bloomGenerations() []bloomGeneration {
+	size, sections := f.backend.BloomStatus()
+	current := bloomGeneration{end: sections * size, size: size}
+
+	backend, ok := f.backend.(interface {
+		BloomGenerations() []rawdb.BloomGeneration
+	})
+	if !ok {
+		return []bloomGeneration{current}
+	}
+	stored := backend.BloomGenerations()
+	if len(stored) == 0 {
+		return []bloomGeneration{current}
+	}
+	generations := make([]bloomGeneration, 0, len(stored))
+	for i := 0; i < len(stored)-1; i++ {
+		generations = append(generations, bloomGeneration{start: stored[i].Start, end: stored[i+1].Start, size: stored[i].SectionSize})
+	}
+	current.start = stored[len(stored)-1].Start
+	return append(generations, current)
+}
+
 // indexedLogs returns the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
-func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+func (f *Filter) indexedLogs(ctx context.Context, matcher *bloombits.Matcher, end uint64) ([]*types.Log, error) {
 	// Create a matcher session and request servicing from the backend
 	matches := make(chan uint64, 64)
 
-	session, err := f.matcher.Start(ctx, uint64(f.begin), end, matches)
+	session, err := matcher.Start(ctx, uint64(f.begin), end, matches)
 	if err != nil {
 		return nil, err
 	}
@@ -21,14 +21,18 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/bitutil"
 	"github.com/autonity/autonity/consensus/ethash"
 	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/bloombits"
 	"github.com/autonity/autonity/core/rawdb"
 	"github.com/autonity/autonity/core/types"
 	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/ethdb"
 	"github.com/autonity/autonity/params"
 )
 
@@ -227,3 +231,147 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+// generationsBackend serves a bloombits index generated with several section sizes.
+type generationsBackend struct {
+	*testBackend
+	generations []rawdb.BloomGeneration
+	sections    uint64 // Sections stored by the last generation, counted from the genesis
+}
+
+func (b *generationsBackend) BloomStatus() (uint64, uint64) {
+	return b.generations[len(b.generations)-1].SectionSize, b.sections
+}
+
+func (b *generationsBackend) BloomGenerations() []rawdb.BloomGeneration {
+	return b.generations
+}
+
+func (b *generationsBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
+	requests := make(chan chan *bloombits.Retrieval)
+
+	go session.Multiplex(16, 0, requests)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case request := <-requests:
+				task := <-request
+
+				// Reading a section the generation didn't store fails the filtering
+				task.Bitsets = make([][]byte, len(task.Sections))
+				for i, section := range task.Sections {
+					head := rawdb.ReadCanonicalHash(b.db, (section+1)*task.SectionSize-1)
+					comp, err := rawdb.ReadBloomBits(b.db, task.Bit, section, head)
+					if err == nil {
+						task.Bitsets[i], err = bitutil.DecompressBytes(comp, int(task.SectionSize/8))
+					}
+					if err != nil {
+						task.Error = err
+					}
+				}
+				request <- task
+			}
+		}
+	}()
+}
+
+// writeBloomSections stores the bloombits of the given sections of the canonical chain.
+func writeBloomSections(t *testing.T, db ethdb.Database, size, from, to uint64) {
+	for section := from; section < to; section++ {
+		gen, err := bloombits.NewGenerator(uint(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := uint64(0); i < size; i++ {
+			number := section*size + i
+			header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number)
+			if err := gen.AddBloom(uint(i), header.Bloom); err != nil {
+				t.Fatal(err)
+			}
+		}
+		head := rawdb.ReadCanonicalHash(db, (section+1)*size-1)
+		for bit := 0; bit < types.BloomBitLength; bit++ {
+			bits, err := gen.Bitset(uint(bit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rawdb.WriteBloomBits(db, uint(bit), section, head, bitutil.CompressBytes(bits))
+		}
+	}
+}
+
+func TestFiltersMixedBloomGenerations(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key1.PublicKey)
+		topic   = common.BytesToHash([]byte("topic"))
+		other   = common.BytesToHash([]byte("other"))
+
+		// Blocks emitting the logs, around the boundaries of the generations
+		logged = []uint64{10, 127, 128, 255, 256, 383, 600, 767, 768, 950}
+	)
+	defer db.Close()
+
+	genesis := core.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 1000, func(i int, gen *core.BlockGen) {
+		for _, number := range logged {
+			if uint64(i+1) != number {
+				continue
+			}
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = []*types.Log{{Address: addr, Topics: []common.Hash{topic}}}
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(types.NewTransaction(number, common.HexToAddress("0x1"), big.NewInt(1), 1, gen.BaseFee(), nil))
+		}
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// The first generation indexed the blocks before 256 in sections of 128 blocks,
+	// the second one the blocks up to 767 in sections of 256 blocks and the rest
+	// of the chain isn't indexed.
+	writeBloomSections(t, db, 128, 0, 2)
+	writeBloomSections(t, db, 256, 1, 3)
+	backend := &generationsBackend{
+		testBackend: &testBackend{db: db},
+		generations: []rawdb.BloomGeneration{{Start: 0, SectionSize: 128}, {Start: 256, SectionSize: 256}},
+		sections:    3,
+	}
+
+	tests := []struct {
+		begin, end int64
+		want       []uint64
+	}{
+		{0, -1, logged},
+		{0, 200, []uint64{10, 127, 128}},
+		{200, 300, []uint64{255, 256}},
+		{128, 767, []uint64{128, 255, 256, 383, 600, 767}},
+		{383, 900, []uint64{383, 600, 767, 768}},
+		{768, -1, []uint64{768, 950}},
+		{11, 126, nil},
+	}
+	for _, test := range tests {
+		logs, err := NewRangeFilter(backend, test.begin, test.end, []common.Address{addr}, [][]common.Hash{{topic}}).Logs(context.Background())
+		if err != nil {
+			t.Fatalf("range [%d, %d]: %v", test.begin, test.end, err)
+		}
+		var got []uint64
+		for _, log := range logs {
+			got = append(got, log.BlockNumber)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("range [%d, %d]: logs in blocks %v, want %v", test.begin, test.end, got, test.want)
+		}
+	}
+	logs, err := NewRangeFilter(backend, 0, -1, nil, [][]common.Hash{{other}}).Logs(context.Background())
+	if err != nil || len(logs) != 0 {
+		t.Errorf("unexpected logs %v, error %v", logs, err)
+	}
+}
@@ -74,6 +74,10 @@ type Oracle struct {
 	checkBlocks, percentile           int
 	maxHeaderHistory, maxBlockHistory int
 	historyCache                      *lru.Cache
+
+	// first block following the last change of the minimum base fee. The blocks before it are not sampled,
+	// their tips were paid on top of a different base fee regime.
+	feeRegimeStart uint64
 }
 
 // NewOracle returns a new gasprice oracle which can recommend suitable
@@ -116,8 +120,21 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 	}
 
 	cache, _ := lru.New(2048)
+	oracle := &Oracle{
+		backend:          backend,
+		lastPrice:        params.Default,
+		maxPrice:         maxPrice,
+		ignorePrice:      ignorePrice,
+		checkBlocks:      blocks,
+		percentile:       percent,
+		maxHeaderHistory: maxHeaderHistory,
+		maxBlockHistory:  maxBlockHistory,
+		historyCache:     cache,
+	}
+
 	headEvent := make(chan core.ChainHeadEvent, 1)
 	chainHeadEventSub := backend.SubscribeChainHeadEvent(headEvent)
+	minBaseFee := backend.MinBaseFee()
 	go func() {
 		var lastHead common.Hash
 		for {
@@ -127,23 +144,20 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 					cache.Purge()
 				}
 				lastHead = ev.Block.Hash()
+				// A new minimum base fee, updated at an epoch boundary, applies from the next block on.
+				if fee := backend.MinBaseFee(); fee.Cmp(minBaseFee) != 0 {
+					log.Debug("Minimum base fee changed, resetting the gasprice oracle samples", "number", ev.Block.NumberU64(), "old", minBaseFee, "new", fee)
+					minBaseFee = fee
+					oracle.cacheLock.Lock()
+					oracle.feeRegimeStart = ev.Block.NumberU64() + 1
+					oracle.cacheLock.Unlock()
+				}
 			case <-chainHeadEventSub.Err():
 				return
 			}
 		}
 	}()
-
-	return &Oracle{
-		backend:          backend,
-		lastPrice:        params.Default,
-		maxPrice:         maxPrice,
-		ignorePrice:      ignorePrice,
-		checkBlocks:      blocks,
-		percentile:       percent,
-		maxHeaderHistory: maxHeaderHistory,
-		maxBlockHistory:  maxBlockHistory,
-		historyCache:     cache,
-	}
+	return oracle
 }
 
 // SuggestTipCap returns a tip cap so that newly created transaction can have a
@@ -158,7 +172,7 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 
 	// If the latest gasprice is still available, return it.
 	oracle.cacheLock.RLock()
-	lastHead, lastPrice := oracle.lastHead, oracle.lastPrice
+	lastHead, lastPrice, regimeStart := oracle.lastHead, oracle.lastPrice, oracle.feeRegimeStart
 	oracle.cacheLock.RUnlock()
 	if headHash == lastHead {
 		return new(big.Int).Set(lastPrice), nil
@@ -168,7 +182,7 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 
 	// Try checking the cache again, maybe the last fetch fetched what we need
 	oracle.cacheLock.RLock()
-	lastHead, lastPrice = oracle.lastHead, oracle.lastPrice
+	lastHead, lastPrice, regimeStart = oracle.lastHead, oracle.lastPrice, oracle.feeRegimeStart
 	oracle.cacheLock.RUnlock()
 	if headHash == lastHead {
 		return new(big.Int).Set(lastPrice), nil
@@ -176,11 +190,12 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	var (
 		sent, exp int
 		number    = head.Number.Uint64()
+		lowest    = max(regimeStart, 1) // lowest block sampled
 		result    = make(chan results, oracle.checkBlocks)
 		quit      = make(chan struct{})
 		results   []*big.Int
 	)
-	for sent < oracle.checkBlocks && number > 0 {
+	for sent < oracle.checkBlocks && number >= lowest {
 		go oracle.getBlockValues(ctx, types.MakeSigner(oracle.backend.ChainConfig(), big.NewInt(int64(number))), number, sampleNumber, oracle.ignorePrice, result, quit)
 		sent++
 		exp++
@@ -203,7 +218,7 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 		// Besides, in order to collect enough data for sampling, if nothing
 		// meaningful returned, try to query more blocks. But the maximum
 		// is 2*checkBlocks.
-		if len(res.values) == 1 && len(results)+1+exp < oracle.checkBlocks*2 && number > 0 {
+		if len(res.values) == 1 && len(results)+1+exp < oracle.checkBlocks*2 && number >= lowest {
 			go oracle.getBlockValues(ctx, types.MakeSigner(oracle.backend.ChainConfig(), big.NewInt(int64(number))), number, sampleNumber, oracle.ignorePrice, result, quit)
 			sent++
 			exp++
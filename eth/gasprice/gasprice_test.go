@@ -20,7 +20,11 @@ import (
 	"context"
 	"math"
 	"math/big"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/autonity/autonity/accounts/abi/bind/backends"
 	"github.com/autonity/autonity/log"
@@ -35,6 +39,7 @@ import (
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/params"
 	"github.com/autonity/autonity/rpc"
+	"github.com/autonity/autonity/trie"
 )
 
 const testHead = 32
@@ -197,3 +202,134 @@ func TestSuggestTipCap(t *testing.T) {
 		}
 	}
 }
+
+// feeRegimeBackend serves a chain whose minimum base fee is raised at an epoch boundary, after which the
+// base fee jumps and the transactions pay lower tips.
+type feeRegimeBackend struct {
+	blocks     []*types.Block
+	head       uint64
+	minBaseFee atomic.Pointer[big.Int]
+	headFeed   event.Feed
+}
+
+func newFeeRegimeBackend(length, boundary uint64) *feeRegimeBackend {
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSigner(params.TestChainConfig)
+	b := &feeRegimeBackend{blocks: make([]*types.Block, length+1)}
+	b.minBaseFee.Store(big.NewInt(params.GWei))
+	nonce := uint64(0)
+	for number := uint64(0); number <= length; number++ {
+		baseFee, tip := big.NewInt(params.GWei), big.NewInt(10*params.GWei)
+		if number > boundary {
+			baseFee, tip = big.NewInt(50*params.GWei), big.NewInt(2*params.GWei)
+		}
+		var txs []*types.Transaction
+		for i := 0; i < sampleNumber && number > 0; i++ {
+			txs = append(txs, types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   params.TestChainConfig.ChainID,
+				Nonce:     nonce,
+				To:        &common.Address{},
+				Gas:       21000,
+				GasFeeCap: big.NewInt(200 * params.GWei),
+				GasTipCap: tip,
+			}))
+			nonce++
+		}
+		header := &types.Header{Number: new(big.Int).SetUint64(number), BaseFee: baseFee, Coinbase: common.Address{1}}
+		if number > 0 {
+			header.ParentHash = b.blocks[number-1].Hash()
+		}
+		b.blocks[number] = types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+	}
+	return b
+}
+
+func (b *feeRegimeBackend) HeaderByNumber(_ context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	block, err := b.BlockByNumber(context.Background(), number)
+	if block == nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+func (b *feeRegimeBackend) BlockByNumber(_ context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.blocks[b.head], nil
+	}
+	if number < 0 || uint64(number) > b.head {
+		return nil, nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *feeRegimeBackend) GetReceipts(context.Context, common.Hash) (types.Receipts, error) {
+	return nil, nil
+}
+
+func (b *feeRegimeBackend) PendingBlockAndReceipts() (*types.Block, types.Receipts) {
+	return nil, nil
+}
+
+func (b *feeRegimeBackend) ChainConfig() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
+func (b *feeRegimeBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.headFeed.Subscribe(ch)
+}
+
+func (b *feeRegimeBackend) MinBaseFee() *big.Int {
+	return b.minBaseFee.Load()
+}
+
+// setHead moves the head to number, raising the minimum base fee if it is the epoch boundary.
+func (b *feeRegimeBackend) setHead(number, boundary uint64) {
+	b.head = number
+	if number == boundary {
+		b.minBaseFee.Store(big.NewInt(50 * params.GWei))
+	}
+	b.headFeed.Send(core.ChainHeadEvent{Block: b.blocks[number]})
+}
+
+func TestSuggestTipCapFeeRegimeChange(t *testing.T) {
+	const (
+		length   = 30
+		boundary = 20
+	)
+	config := Config{
+		Blocks:     20,
+		Percentile: 60,
+		Default:    big.NewInt(params.GWei),
+	}
+	oldTip, newTip := big.NewInt(10*params.GWei), big.NewInt(2*params.GWei)
+	regimeStart := func(oracle *Oracle) uint64 {
+		oracle.cacheLock.RLock()
+		defer oracle.cacheLock.RUnlock()
+		return oracle.feeRegimeStart
+	}
+
+	backend := newFeeRegimeBackend(length, boundary)
+	oracle := NewOracle(backend, config)
+	for number := uint64(1); number <= length; number++ {
+		backend.setHead(number, boundary)
+		if number == boundary {
+			require.Eventually(t, func() bool { return regimeStart(oracle) == boundary+1 }, 5*time.Second, time.Millisecond)
+		}
+		tip, err := oracle.SuggestTipCap(context.Background())
+		require.NoError(t, err)
+		// the suggestion follows the new regime as soon as a block of the new regime is available
+		want := oldTip
+		if number > boundary {
+			want = newTip
+		}
+		require.Equal(t, want, tip, "head %d", number)
+	}
+
+	// the same chain sampled across the boundary lags for most of the sample window
+	backend = newFeeRegimeBackend(length, boundary)
+	oracle = NewOracle(backend, config)
+	backend.head = boundary + 5
+	tip, err := oracle.SuggestTipCap(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, oldTip, tip)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/forkid"
 	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
 	"github.com/autonity/autonity/eth/downloader"
 	"github.com/autonity/autonity/eth/fetcher"
 	"github.com/autonity/autonity/eth/protocols/eth"
@@ -37,6 +38,7 @@ import (
 	"github.com/autonity/autonity/ethdb"
 	"github.com/autonity/autonity/event"
 	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
 	"github.com/autonity/autonity/p2p"
 	"github.com/autonity/autonity/params"
 )
@@ -49,6 +51,9 @@ const (
 
 var (
 	syncChallengeTimeout = 15 * time.Second // Time allowance for a node to reply to the sync progress challenge
+
+	// genesisMismatchCounter counts the peers rejected for a genesis other than the local one on the same network ID
+	genesisMismatchCounter = metrics.NewRegisteredCounter("eth/handshake/genesismismatch", nil)
 )
 
 // txPool defines the methods needed from a transaction pool implementation to
@@ -82,6 +87,8 @@ type handlerConfig struct {
 	TxPool         txPool                    // Transaction pool to propagate from
 	Network        uint64                    // Network identifier to adfvertise
 	Sync           downloader.SyncMode       // Whether to snap or full sync
+	SnapMinBlocks  uint64                    // Minimum chain length to snap sync, shorter chains are full synced
+	ProtocolFirst  bool                      // Whether to snap sync the protocol contracts state first
 	BloomCache     uint64                    // Megabytes to alloc for snap sync bloom
 	EventMux       *event.TypeMux            // Legacy event mux, deprecate for `feed`
 	Checkpoint     *params.TrustedCheckpoint // Hard coded checkpoint for sync challenges
@@ -174,6 +181,14 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	// sync is requested. The downloader is responsible for deallocating the state
 	// bloom when it's done.
 	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.eventMux, h.chain, nil, h.removePeer)
+	h.downloader.SetSnapSyncParams(config.SnapMinBlocks, h.epochPeriod)
+	if config.ProtocolFirst {
+		accounts := make([]common.Hash, 0, len(params.ProtocolStateAccounts))
+		for _, account := range params.ProtocolStateAccounts {
+			accounts = append(accounts, crypto.Keccak256Hash(account.Bytes()))
+		}
+		h.downloader.SnapSyncer.SetPriorityAccounts(accounts)
+	}
 
 	// Construct the fetcher (short sync)
 	validator := func(header *types.Header) error {
@@ -253,6 +268,12 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 	)
 	forkID := forkid.NewID(h.chain.Config(), h.chain.Genesis().Hash(), h.chain.CurrentHeader().Number.Uint64())
 	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
+		if errors.Is(err, eth.ErrGenesisMismatch) {
+			// a copied network ID on another chain, the peer will never be of use
+			genesisMismatchCounter.Inc(1)
+			peer.Log().Warn("Ethereum peer of the same network rejected for its genesis", "err", err)
+			return p2p.DiscGenesisMismatch
+		}
 		peer.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
@@ -410,6 +431,15 @@ func (h *handler) runSnapExtension(peer *snap.Peer, handler snap.Handler) error
 	return handler(peer)
 }
 
+// epochPeriod returns the current epoch length, zero if the protocol contracts are not loaded.
+func (h *handler) epochPeriod() uint64 {
+	contracts := h.chain.ProtocolContracts()
+	if contracts == nil || contracts.Cache == nil {
+		return 0
+	}
+	return contracts.Cache.EpochPeriod().Uint64()
+}
+
 // removePeer requests disconnection of a peer.
 func (h *handler) removePeer(id string) {
 	peer := h.peers.peer(id)
@@ -232,6 +232,72 @@ func testForkIDSplit(t *testing.T, protocol uint) {
 	}
 }
 
+// Tests that the peers of the same network but of another genesis are disconnected for it.
+func TestGenesisMismatch66(t *testing.T) { testGenesisMismatch(t, eth.ETH66) }
+
+func testGenesisMismatch(t *testing.T, protocol uint) {
+	t.Parallel()
+
+	var (
+		engine = ethash.NewFaker()
+
+		dbA = rawdb.NewMemoryDatabase()
+		dbB = rawdb.NewMemoryDatabase()
+
+		gspecA = &core.Genesis{Config: params.TestChainConfig}
+		gspecB = &core.Genesis{Config: params.TestChainConfig, ExtraData: []byte("another genesis")}
+	)
+	gspecA.MustCommit(dbA)
+	gspecB.MustCommit(dbB)
+
+	chainA, _ := core.NewBlockChain(dbA, nil, params.TestChainConfig, engine, vm.Config{}, nil, &core.TxSenderCacher{}, nil, backends.NewInternalBackend(nil), log.Root())
+	chainB, _ := core.NewBlockChain(dbB, nil, params.TestChainConfig, engine, vm.Config{}, nil, &core.TxSenderCacher{}, nil, backends.NewInternalBackend(nil), log.Root())
+	defer chainA.Stop()
+	defer chainB.Stop()
+	if chainA.Genesis().Hash() == chainB.Genesis().Hash() {
+		t.Fatalf("genesis hashes should differ")
+	}
+
+	ethA, _ := newHandler(&handlerConfig{Database: dbA, Chain: chainA, TxPool: newTestTxPool(), Network: 1, Sync: downloader.FullSync, BloomCache: 1})
+	ethB, _ := newHandler(&handlerConfig{Database: dbB, Chain: chainB, TxPool: newTestTxPool(), Network: 1, Sync: downloader.FullSync, BloomCache: 1})
+	ethA.Start(1000)
+	ethB.Start(1000)
+	defer ethA.Stop()
+	defer ethB.Stop()
+
+	p2pA, p2pB := p2p.MsgPipe()
+	defer p2pA.Close()
+	defer p2pB.Close()
+
+	peerA := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{1}, "", nil, p2pA), p2pA, nil)
+	peerB := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{2}, "", nil, p2pB), p2pB, nil)
+	defer peerA.Close()
+	defer peerB.Close()
+
+	errc := make(chan error, 2)
+	go func(errc chan error) {
+		errc <- ethA.runEthPeer(peerB, func(peer *eth.Peer) error { return nil })
+	}(errc)
+	go func(errc chan error) {
+		errc <- ethB.runEthPeer(peerA, func(peer *eth.Peer) error { return nil })
+	}(errc)
+
+	var mismatches int
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errc:
+			if err == p2p.DiscGenesisMismatch {
+				mismatches++
+			}
+		case <-time.After(250 * time.Millisecond):
+			t.Fatalf("peers of another genesis not rejected")
+		}
+	}
+	if mismatches == 0 {
+		t.Fatalf("no peer disconnected for the genesis mismatch")
+	}
+}
+
 // Tests that received transactions are added to the local pool.
 func TestRecvTransactions66(t *testing.T) { testRecvTransactions(t, eth.ETH66) }
 
@@ -0,0 +1,35 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/autonity/autonity/params"
+)
+
+var errInvalidChainID = errors.New("the genesis chain ID must be positive on a BFT chain, the transactions signed for it would be replayable")
+
+// checkNetworkIDs checks the chain ID of the genesis against the configured network ID. It fails if they
+// cannot work, and returns a warning for each way they diverge from the official Autonity networks: the
+// nodes of a copied configuration find no peers, or sign transactions valid on another network.
+func checkNetworkIDs(chainID *big.Int, networkID uint64, bft bool) ([]string, error) {
+	if bft && (chainID == nil || chainID.Sign() <= 0) {
+		return nil, fmt.Errorf("%w: %v", errInvalidChainID, chainID)
+	}
+	if chainID == nil {
+		return nil, nil
+	}
+	var warnings []string
+	if chainID.IsUint64() {
+		if network := params.AutonityNetworkByChainID(chainID.Uint64()); network != nil && network.NetworkID != networkID {
+			warnings = append(warnings, fmt.Sprintf("the chain ID %d is the one of %s, whose network ID is %d and not %d: no peer of %s will be found",
+				chainID, network.Name, network.NetworkID, networkID, network.Name))
+		}
+	}
+	if network := params.AutonityNetworkByNetworkID(networkID); network != nil && (!chainID.IsUint64() || network.ChainID != chainID.Uint64()) {
+		warnings = append(warnings, fmt.Sprintf("the network ID %d is the one of %s, whose chain ID is %d and not %v: the peers of %s will be rejected for their genesis",
+			networkID, network.Name, network.ChainID, chainID, network.Name))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,43 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/params"
+)
+
+func TestCheckNetworkIDs(t *testing.T) {
+	piccadilly := params.PiccadillyChainConfig.ChainID
+	bakerloo := params.BakerlooChainConfig.ChainID
+	custom := big.NewInt(65_000_001)
+
+	tests := []struct {
+		name      string
+		chainID   *big.Int
+		networkID uint64
+		bft       bool
+		warnings  int
+		err       error
+	}{
+		{name: "official network", chainID: piccadilly, networkID: piccadilly.Uint64(), bft: true},
+		{name: "custom network", chainID: custom, networkID: 65_000_000, bft: true},
+		{name: "official chain on another network", chainID: piccadilly, networkID: 65_000_000, bft: true, warnings: 1},
+		{name: "custom chain on an official network", chainID: custom, networkID: bakerloo.Uint64(), bft: true, warnings: 1},
+		{name: "official chain on another official network", chainID: piccadilly, networkID: bakerloo.Uint64(), bft: true, warnings: 2},
+		{name: "zero chain ID", chainID: new(big.Int), networkID: 65_000_000, bft: true, err: errInvalidChainID},
+		{name: "negative chain ID", chainID: big.NewInt(-1), networkID: 65_000_000, bft: true, err: errInvalidChainID},
+		{name: "missing chain ID", networkID: 65_000_000, bft: true, err: errInvalidChainID},
+		{name: "zero chain ID without BFT", chainID: new(big.Int), networkID: 65_000_000},
+		{name: "oversized chain ID on an official network", chainID: new(big.Int).Lsh(big.NewInt(1), 64), networkID: bakerloo.Uint64(), bft: true, warnings: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := checkNetworkIDs(tt.chainID, tt.networkID, tt.bft)
+			require.ErrorIs(t, err, tt.err)
+			require.Len(t, warnings, tt.warnings)
+		})
+	}
+}
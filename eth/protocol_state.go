@@ -0,0 +1,53 @@
+package eth
+
+import (
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/params"
+)
+
+// protocolState serves the state the protocol contracts are called against. While the node snap syncs with
+// the protocol contracts state first, the state of the chain head is behind and the state of the pivot block
+// is incomplete: the protocol contracts are then served out of the partial state synced ahead at the pivot.
+// Reading any other account out of the partial state fails, with the error reported by the state.
+type protocolState struct {
+	chain *core.BlockChain
+	db    ethdb.Database
+	// partial returns the header the protocol contracts state was synced ahead at, nil if it is not.
+	partial func() *types.Header
+}
+
+// Head returns the state of the protocol contracts at the latest block it is available at, and its header.
+func (p *protocolState) Head() (*state.StateDB, *types.Header, error) {
+	head := p.chain.CurrentBlock().Header()
+	if partial := p.partialHeader(); partial != nil && partial.Number.Cmp(head.Number) > 0 {
+		statedb, err := p.partialState(partial)
+		return statedb, partial, err
+	}
+	statedb, err := p.chain.StateAt(head.Root)
+	return statedb, head, err
+}
+
+// At returns the state of the protocol contracts at the block of header.
+func (p *protocolState) At(header *types.Header) (*state.StateDB, error) {
+	statedb, err := p.chain.StateAt(header.Root)
+	if err != nil {
+		if partial := p.partialHeader(); partial != nil && partial.Root == header.Root {
+			return p.partialState(partial)
+		}
+	}
+	return statedb, err
+}
+
+func (p *protocolState) partialHeader() *types.Header {
+	if p.partial == nil {
+		return nil
+	}
+	return p.partial()
+}
+
+func (p *protocolState) partialState(header *types.Header) (*state.StateDB, error) {
+	return state.New(header.Root, state.NewPartialDatabase(p.db, params.ProtocolStateAccounts), nil)
+}
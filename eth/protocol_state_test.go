@@ -0,0 +1,130 @@
+package eth
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/state"
+	"github.com/autonity/autonity/core/state/snapshot"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/eth/autapi"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rpc"
+	"github.com/autonity/autonity/trie"
+)
+
+// syncedProtocolState writes the protocol contracts state of the chain at root into a database, as the flat
+// snapshot entries persisted by snap sync.
+func syncedProtocolState(t *testing.T, chain *core.BlockChain, root common.Hash) ethdb.Database {
+	db := rawdb.NewMemoryDatabase()
+	statedb, err := chain.StateAt(root)
+	require.NoError(t, err)
+	for _, account := range params.ProtocolStateAccounts {
+		if !statedb.Exist(account) {
+			continue
+		}
+		hash := crypto.Keccak256Hash(account.Bytes())
+		storageRoot := types.EmptyRootHash
+		if storage := statedb.StorageTrie(account); storage != nil {
+			storageRoot = storage.Hash()
+			it := trie.NewIterator(storage.NodeIterator(nil))
+			for it.Next() {
+				rawdb.WriteStorageSnapshot(db, hash, common.BytesToHash(it.Key), it.Value)
+			}
+		}
+		codeHash := statedb.GetCodeHash(account)
+		rawdb.WriteAccountSnapshot(db, hash, snapshot.SlimAccountRLP(statedb.GetNonce(account), statedb.GetBalance(account), storageRoot, codeHash.Bytes()))
+		rawdb.WriteCode(db, codeHash, statedb.GetCode(account))
+	}
+	return db
+}
+
+// callContractView calls a view of the protocol contract through the API.
+func callContractView(api *AutonityContractAPI, method string) (interface{}, error) {
+	out := api.AllMethods()[method].Call([]reflect.Value{reflect.ValueOf(api)})
+	err, _ := out[1].Interface().(error)
+	return out[0].Interface(), err
+}
+
+func TestProtocolState(t *testing.T) {
+	synced := newCommitteeTestChain(t, 3)
+	head := synced.CurrentHeader()
+	// a syncing node has the genesis state only, and the protocol contracts state synced ahead at the pivot
+	syncing := newCommitteeTestChain(t, 0)
+	var pivot *types.Header
+	partialState := &protocolState{
+		chain:   syncing,
+		db:      syncedProtocolState(t, synced, head.Root),
+		partial: func() *types.Header { return pivot },
+	}
+	ctx := context.Background()
+
+	t.Run("not synced", func(t *testing.T) {
+		_, header, err := partialState.Head()
+		require.NoError(t, err)
+		require.Equal(t, syncing.Genesis().Hash(), header.Hash())
+		_, err = partialState.At(head)
+		require.Error(t, err)
+	})
+
+	pivot = head
+	t.Run("partial state", func(t *testing.T) {
+		statedb, header, err := partialState.Head()
+		require.NoError(t, err)
+		require.Equal(t, head.Hash(), header.Hash())
+		require.NotEmpty(t, statedb.GetCode(params.AutonityContractAddress))
+		require.NoError(t, statedb.Error())
+
+		// the other accounts are not available
+		statedb.GetBalance(common.HexToAddress("0x1234"))
+		require.Error(t, statedb.Error())
+		require.Contains(t, statedb.Error().Error(), state.ErrPartialState.Error())
+
+		_, err = partialState.At(head)
+		require.NoError(t, err)
+		_, err = partialState.At(syncing.Genesis().Header())
+		require.NoError(t, err)
+	})
+
+	t.Run("contract views", func(t *testing.T) {
+		api := newAutonityContractAPI(partialState, syncing.ProtocolContracts())
+		expectedAPI := NewAutonityContractAPI(synced, synced.ProtocolContracts())
+		for _, method := range []string{"getCommittee", "getValidators", "getEpochPeriod", "getOperator"} {
+			expected, err := callContractView(expectedAPI, method)
+			require.NoError(t, err)
+			actual, err := callContractView(api, method)
+			require.NoError(t, err, method)
+			require.Equal(t, expected, actual, method)
+		}
+	})
+
+	t.Run("committee", func(t *testing.T) {
+		reader := &apiCommitteeReader{backend: &EthAPIBackend{eth: &Ethereum{blockchain: syncing, protocolState: partialState}}}
+		committee, err := reader.ContractCommittee(ctx, rpc.BlockNumberOrHashWithHash(head.Hash(), false), head)
+		require.NoError(t, err)
+		requireSameCommittee(t, contractCommittee(t, synced, head), autapi.NewCommittee(committee))
+
+		number, enodes, err := (&chainCommitteeEnodes{chain: syncing, state: partialState}).CommitteeEnodes(ctx)
+		require.NoError(t, err)
+		require.Equal(t, head.Number.Uint64(), number)
+		_, expected, err := (&chainCommitteeEnodes{chain: synced, state: &protocolState{chain: synced}}).CommitteeEnodes(ctx)
+		require.NoError(t, err)
+		require.Equal(t, expected, enodes)
+	})
+
+	// the state of the chain is served once the node synced past the pivot
+	pivot = syncing.Genesis().Header()
+	t.Run("synced", func(t *testing.T) {
+		_, header, err := partialState.Head()
+		require.NoError(t, err)
+		require.Equal(t, syncing.Genesis().Hash(), header.Hash())
+	})
+}
@@ -17,12 +17,23 @@
 package eth
 
 import (
+	"errors"
+	"sync"
+
+	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/forkid"
+	"github.com/autonity/autonity/log"
 	"github.com/autonity/autonity/p2p/enode"
 	"github.com/autonity/autonity/rlp"
 )
 
+// committeeDialBacklog is the number of dial candidates advertising another committee than ours held back
+// before one of them is dialed anyway.
+const committeeDialBacklog = 16
+
+var errNoProtocolContracts = errors.New("protocol contracts not loaded")
+
 // enrEntry is the ENR entry which advertises `eth` protocol on the discovery.
 type enrEntry struct {
 	ForkID forkid.ID // Fork identifier per EIP-2124
@@ -36,11 +47,28 @@ func (e enrEntry) ENRKey() string {
 	return "eth"
 }
 
+// committeeEntry is the ENR entry which advertises the committee of the current epoch, for the nodes of a
+// diverging chain to be told apart before dialing them.
+type committeeEntry struct {
+	Epoch uint64      // epoch identifier of the Autonity contract
+	Hash  common.Hash // hash of the committee of the epoch, see types.Committee.Hash
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// ENRKey implements enr.Entry.
+func (e committeeEntry) ENRKey() string {
+	return "committee"
+}
+
 // StartENRUpdater starts the `eth` ENR updater loop, which listens for chain
-// head events and updates the requested node record whenever a fork is passed.
+// head events and updates the requested node record whenever a fork is passed
+// or the committee changes.
 func StartENRUpdater(chain *core.BlockChain, ln *enode.LocalNode) {
 	var newHead = make(chan core.ChainHeadEvent, 10)
 	sub := chain.SubscribeChainHeadEvent(newHead)
+	committee := newCommitteeTracker(chain)
 
 	go func() {
 		defer sub.Unsubscribe()
@@ -48,6 +76,9 @@ func StartENRUpdater(chain *core.BlockChain, ln *enode.LocalNode) {
 			select {
 			case <-newHead:
 				ln.Set(currentENREntry(chain))
+				if entry := committee.entry(); entry != nil {
+					ln.Set(entry)
+				}
 			case <-sub.Err():
 				// Would be nice to sync with Stop, but there is no
 				// good way to do that.
@@ -63,3 +94,70 @@ func currentENREntry(chain *core.BlockChain) *enrEntry {
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis().Hash(), chain.CurrentHeader().Number.Uint64()),
 	}
 }
+
+// currentCommitteeEntry constructs a `committee` ENR entry based on the current head of the chain.
+func currentCommitteeEntry(chain *core.BlockChain) (*committeeEntry, error) {
+	contracts := chain.ProtocolContracts()
+	if contracts == nil {
+		return nil, errNoProtocolContracts
+	}
+	head := chain.CurrentHeader()
+	statedb, err := chain.StateAt(head.Root)
+	if err != nil {
+		return nil, err
+	}
+	epoch, err := contracts.EpochID(head, statedb)
+	if err != nil {
+		return nil, err
+	}
+	return &committeeEntry{Epoch: epoch.Uint64(), Hash: head.Committee.Hash()}, nil
+}
+
+// committeeTracker caches the `committee` ENR entry of the chain head, it is rebuilt once the head changes.
+type committeeTracker struct {
+	chain *core.BlockChain
+
+	lock    sync.Mutex
+	head    common.Hash
+	current *committeeEntry
+}
+
+func newCommitteeTracker(chain *core.BlockChain) *committeeTracker {
+	return &committeeTracker{chain: chain}
+}
+
+// entry returns the `committee` ENR entry of the chain head, nil if it cannot be built.
+func (t *committeeTracker) entry() *committeeEntry {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	head := t.chain.CurrentHeader().Hash()
+	if head == t.head {
+		return t.current
+	}
+	entry, err := currentCommitteeEntry(t.chain)
+	if err != nil {
+		log.Debug("Failed to build the committee ENR entry", "head", head, "err", err)
+	}
+	t.head, t.current = head, entry
+	return entry
+}
+
+// sameCommittee reports whether the node is not known to follow another chain than ours: it does not
+// advertise a different committee for our epoch. The nodes of another epoch, or not advertising
+// their committee, are not judged, as they can be in the middle of an epoch transition.
+func sameCommittee(ours *committeeEntry, n *enode.Node) bool {
+	var theirs committeeEntry
+	if ours == nil || n.Load(&theirs) != nil {
+		return true
+	}
+	return theirs.Epoch != ours.Epoch || theirs.Hash == ours.Hash
+}
+
+// preferCommittee deprioritizes the dial candidates advertising another committee than ours for the same
+// epoch. They are not rejected: the chains may not have diverged, our head may not be current.
+func preferCommittee(it enode.Iterator, ours func() *committeeEntry) enode.Iterator {
+	return enode.Prefer(it, func(n *enode.Node) bool {
+		return sameCommittee(ours(), n)
+	}, committeeDialBacklog)
+}
@@ -0,0 +1,98 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/p2p/enode"
+	"github.com/autonity/autonity/p2p/enr"
+)
+
+func newTestNode(t *testing.T, entries ...enr.Entry) *enode.Node {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r enr.Record
+	for _, entry := range entries {
+		r.Set(entry)
+	}
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	n, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestCommitteeENREntry(t *testing.T) {
+	entry := &committeeEntry{Epoch: 42, Hash: common.Hash{0x1}}
+	n := newTestNode(t, entry)
+
+	// the entry goes through the encoding of the record
+	parsed, err := enode.Parse(enode.ValidSchemes, n.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded committeeEntry
+	if err := parsed.Load(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Epoch != entry.Epoch || loaded.Hash != entry.Hash {
+		t.Fatalf("loaded entry %+v, want %+v", loaded, entry)
+	}
+}
+
+func TestPreferCommittee(t *testing.T) {
+	ours := &committeeEntry{Epoch: 10, Hash: common.Hash{0x1}}
+
+	var (
+		nodes     []*enode.Node
+		preferred = make(map[enode.ID]bool)
+	)
+	for i := 0; i < 3*committeeDialBacklog; i++ {
+		var (
+			n    *enode.Node
+			keep = true
+		)
+		switch i % 4 {
+		case 0: // same committee
+			n = newTestNode(t, &committeeEntry{Epoch: 10, Hash: common.Hash{0x1}})
+		case 1: // another committee for our epoch
+			n = newTestNode(t, &committeeEntry{Epoch: 10, Hash: common.Hash{0x2}})
+			keep = false
+		case 2: // in another epoch
+			n = newTestNode(t, &committeeEntry{Epoch: 11, Hash: common.Hash{0x2}})
+		case 3: // no committee advertised
+			n = newTestNode(t)
+		}
+		nodes = append(nodes, n)
+		preferred[n.ID()] = keep
+	}
+
+	// the mismatching nodes, fewer than the backlog, are held back until the others were returned
+	it := preferCommittee(enode.IterNodes(nodes), func() *committeeEntry { return ours })
+	var returned []*enode.Node
+	for it.Next() {
+		returned = append(returned, it.Node())
+	}
+	if len(returned) != len(nodes) {
+		t.Fatalf("returned %d nodes, want %d", len(returned), len(nodes))
+	}
+	for i, n := range returned {
+		if want := i < 3*len(nodes)/4; preferred[n.ID()] != want {
+			t.Fatalf("node at position %d preferred: %v, want %v", i, preferred[n.ID()], want)
+		}
+	}
+
+	// without a committee of our own, no node is deprioritized
+	it = preferCommittee(enode.IterNodes(nodes), func() *committeeEntry { return nil })
+	for i := 0; it.Next(); i++ {
+		if it.Node() != nodes[i] {
+			t.Fatalf("node %d returned out of order", i)
+		}
+	}
+}
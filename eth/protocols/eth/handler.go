@@ -95,6 +95,15 @@ type TxPool interface {
 
 // MakeProtocols constructs the P2P protocol definitions for `eth`.
 func MakeProtocols(backend Backend, network uint64, dnsdisc enode.Iterator) []p2p.Protocol {
+	// Dial the nodes advertising our committee first.
+	committee := newCommitteeTracker(backend.Chain())
+	dnsdisc = preferCommittee(dnsdisc, committee.entry)
+
+	attributes := []enr.Entry{currentENREntry(backend.Chain())}
+	if entry := committee.entry(); entry != nil {
+		attributes = append(attributes, entry)
+	}
+
 	protocols := make([]p2p.Protocol, len(ProtocolVersions))
 	for i, version := range ProtocolVersions {
 		version := version // Closure
@@ -117,7 +126,7 @@ func MakeProtocols(backend Backend, network uint64, dnsdisc enode.Iterator) []p2
 			PeerInfo: func(id enode.ID) interface{} {
 				return backend.PeerInfo(id)
 			},
-			Attributes:     []enr.Entry{currentENREntry(backend.Chain())},
+			Attributes:     attributes,
 			DialCandidates: dnsdisc,
 		}
 	}
@@ -61,7 +61,7 @@ func testHandshake(t *testing.T, protocol uint) {
 		},
 		{
 			code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), common.Hash{3}, forkID},
-			want: errGenesisMismatch,
+			want: ErrGenesisMismatch,
 		},
 		{
 			code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}},
@@ -82,8 +82,9 @@ var (
 	errInvalidMsgCode          = errors.New("invalid message code")
 	errProtocolVersionMismatch = errors.New("protocol version mismatch")
 	errNetworkIDMismatch       = errors.New("network ID mismatch")
-	errGenesisMismatch         = errors.New("genesis mismatch")
-	errForkIDRejected          = errors.New("fork ID rejected")
+	// ErrGenesisMismatch is returned by the handshake of a peer of the same network ID with another genesis.
+	ErrGenesisMismatch = errors.New("genesis mismatch")
+	errForkIDRejected  = errors.New("fork ID rejected")
 )
 
 // Packet represents a p2p message in the `eth` protocol.
@@ -0,0 +1,118 @@
+package snap
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/trie"
+)
+
+// SetPriorityAccounts sets the accounts, by hash, whose state is synced ahead of the rest of the state, for
+// it to be served before the sync completes. It must be called before the sync starts, and takes effect
+// on a fresh sync only: the tasks of a resumed sync are not split again.
+func (s *Syncer) SetPriorityAccounts(accounts []common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.priority = make(map[common.Hash]common.Hash, len(accounts))
+	for _, account := range accounts {
+		s.priority[account] = common.Hash{}
+	}
+}
+
+// PriorityState returns the state root the priority accounts were synced at, along with their code and
+// storage. It returns false while any of them is not synced yet, or if they were synced at different roots
+// as the sync pivot moved. The state is persisted as a flat snapshot only, the state trie being complete
+// once the sync is done.
+func (s *Syncer) PriorityState() (common.Hash, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var synced common.Hash
+	for _, root := range s.priority {
+		if root == (common.Hash{}) || (synced != (common.Hash{}) && root != synced) {
+			return common.Hash{}, false
+		}
+		synced = root
+	}
+	return synced, synced != (common.Hash{})
+}
+
+// splitPriorityTasks splits the account tasks of a fresh sync at the priority accounts, for each of them
+// to be the first account retrieved by its task.
+func (s *Syncer) splitPriorityTasks() {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for account := range s.priority {
+		for i, task := range s.tasks {
+			if bytes.Compare(account[:], task.Next[:]) <= 0 || bytes.Compare(account[:], task.Last[:]) > 0 {
+				continue
+			}
+			batch := ethdb.HookedBatch{
+				Batch: s.db.NewBatch(),
+				OnPut: func(key []byte, value []byte) {
+					s.accountBytes += common.StorageSize(len(key) + len(value))
+				},
+			}
+			split := &accountTask{
+				Next:     account,
+				Last:     task.Last,
+				SubTasks: make(map[common.Hash][]*storageTask),
+				genBatch: batch,
+				genTrie:  trie.NewStackTrie(batch),
+			}
+			task.Last = common.BigToHash(new(big.Int).Sub(account.Big(), common.Big1))
+			s.tasks = append(s.tasks[:i+1], append([]*accountTask{split}, s.tasks[i+1:]...)...)
+			log.Debug("Split account sync task for priority account", "account", account, "last", task.Last)
+			break
+		}
+	}
+}
+
+// prioritizedTasks returns the account tasks, the ones holding a priority account not synced yet first.
+// The caller must hold the lock.
+func (s *Syncer) prioritizedTasks() []*accountTask {
+	if len(s.priority) == 0 {
+		return s.tasks
+	}
+	tasks := make([]*accountTask, 0, len(s.tasks))
+	var rest []*accountTask
+	for _, task := range s.tasks {
+		if s.holdsPriority(task) {
+			tasks = append(tasks, task)
+		} else {
+			rest = append(rest, task)
+		}
+	}
+	return append(tasks, rest...)
+}
+
+func (s *Syncer) holdsPriority(task *accountTask) bool {
+	for account, root := range s.priority {
+		if root == (common.Hash{}) && bytes.Compare(account[:], task.Next[:]) >= 0 && bytes.Compare(account[:], task.Last[:]) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePriority marks the priority accounts the task moved past since from as synced at the current root.
+// An account absent from the state is synced once the task moves past it too.
+func (s *Syncer) resolvePriority(task *accountTask, from common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for account, root := range s.priority {
+		if root != (common.Hash{}) || bytes.Compare(account[:], from[:]) < 0 {
+			continue
+		}
+		if bytes.Compare(account[:], task.Next[:]) < 0 || (task.done && bytes.Compare(account[:], task.Last[:]) <= 0) {
+			s.priority[account] = s.root
+			log.Debug("Synced priority account", "account", account, "root", s.root)
+		}
+	}
+}
@@ -344,6 +344,9 @@ type SyncProgress struct {
 	TrienodeHealBytes  common.StorageSize // Number of state trie bytes persisted to disk
 	BytecodeHealSynced uint64             // Number of bytecodes downloaded
 	BytecodeHealBytes  common.StorageSize // Number of bytecodes persisted to disk
+
+	// Priority accounts synced -> state root they were synced at
+	Priority map[common.Hash]common.Hash `json:",omitempty"`
 }
 
 // SyncPending is analogous to SyncProgress, but it's used to report on pending
@@ -444,6 +447,8 @@ type Syncer struct {
 	storageHealed      uint64             // Number of storage slots downloaded during the healing stage
 	storageHealedBytes common.StorageSize // Number of raw storage bytes persisted to disk during the healing stage
 
+	priority map[common.Hash]common.Hash // Accounts synced first -> state root they were synced at, zero if pending
+
 	startTime time.Time // Time instance when snapshot sync started
 	logTime   time.Time // Time instance when status was last reported
 
@@ -718,6 +723,12 @@ func (s *Syncer) loadSyncStatus() {
 			s.trienodeHealBytes = progress.TrienodeHealBytes
 			s.bytecodeHealSynced = progress.BytecodeHealSynced
 			s.bytecodeHealBytes = progress.BytecodeHealBytes
+
+			s.lock.Lock()
+			for account := range s.priority {
+				s.priority[account] = progress.Priority[account]
+			}
+			s.lock.Unlock()
 			return
 		}
 	}
@@ -760,6 +771,12 @@ func (s *Syncer) loadSyncStatus() {
 		log.Debug("Created account sync task", "from", next, "last", last)
 		next = common.BigToHash(new(big.Int).Add(last.Big(), common.Big1))
 	}
+	s.lock.Lock()
+	for account := range s.priority {
+		s.priority[account] = common.Hash{}
+	}
+	s.lock.Unlock()
+	s.splitPriorityTasks()
 }
 
 // saveSyncStatus marshals the remaining sync tasks into leveldb.
@@ -791,6 +808,16 @@ func (s *Syncer) saveSyncStatus() {
 		BytecodeHealSynced: s.bytecodeHealSynced,
 		BytecodeHealBytes:  s.bytecodeHealBytes,
 	}
+	s.lock.RLock()
+	for account, root := range s.priority {
+		if root != (common.Hash{}) {
+			if progress.Priority == nil {
+				progress.Priority = make(map[common.Hash]common.Hash)
+			}
+			progress.Priority[account] = root
+		}
+	}
+	s.lock.RUnlock()
 	status, err := json.Marshal(progress)
 	if err != nil {
 		panic(err) // This can only fail during implementation
@@ -905,8 +932,8 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 	}
 	sort.Sort(sort.Reverse(idlers))
 
-	// Iterate over all the tasks and try to find a pending one
-	for _, task := range s.tasks {
+	// Iterate over all the tasks and try to find a pending one, the priority accounts first
+	for _, task := range s.prioritizedTasks() {
 		// Skip any tasks already filling
 		if task.req != nil || task.res != nil {
 			continue
@@ -1002,8 +1029,8 @@ func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 	}
 	sort.Sort(sort.Reverse(idlers))
 
-	// Iterate over all the tasks and try to find a pending one
-	for _, task := range s.tasks {
+	// Iterate over all the tasks and try to find a pending one, the priority accounts first
+	for _, task := range s.prioritizedTasks() {
 		// Skip any tasks not in the bytecode retrieval phase
 		if task.res == nil {
 			continue
@@ -1105,8 +1132,8 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 	}
 	sort.Sort(sort.Reverse(idlers))
 
-	// Iterate over all the tasks and try to find a pending one
-	for _, task := range s.tasks {
+	// Iterate over all the tasks and try to find a pending one, the priority accounts first
+	for _, task := range s.prioritizedTasks() {
 		// Skip any tasks not in the storage retrieval phase
 		if task.res == nil {
 			continue
@@ -2160,6 +2187,7 @@ func (s *Syncer) forwardAccountTask(task *accountTask) {
 		return // nothing to forward
 	}
 	task.res = nil
+	defer s.resolvePriority(task, task.Next)
 
 	// Persist the received account segements. These flat state maybe
 	// outdated during the sync, but it can be fixed later during the
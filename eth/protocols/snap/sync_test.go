@@ -751,6 +751,75 @@ func TestSyncWithStorage(t *testing.T) {
 	verifyTrie(syncer.db, sourceAccountTrie.Hash(), t)
 }
 
+// TestSyncPriorityAccounts tests that the priority accounts are retrieved first, and reported synced along
+// with their storage.
+func TestSyncPriorityAccounts(t *testing.T) {
+	t.Parallel()
+
+	var (
+		once   sync.Once
+		cancel = make(chan struct{})
+		term   = func() {
+			once.Do(func() {
+				close(cancel)
+			})
+		}
+	)
+	sourceAccountTrie, elems, storageTries, storageElems := makeAccountTrieWithStorage(200, 50, true, false)
+	priority := []common.Hash{
+		common.BytesToHash(elems[57].k),
+		common.BytesToHash(elems[150].k),
+		common.HexToHash("0x8000000000000000000000000000000000000000000000000000000000000001"), // absent from the state
+	}
+	var origins []common.Hash
+	source := newTestPeer("source", t, term)
+	source.accountTrie = sourceAccountTrie
+	source.accountValues = elems
+	source.storageTries = storageTries
+	source.storageValues = storageElems
+	source.accountRequestHandler = func(t *testPeer, id uint64, root common.Hash, origin common.Hash, limit common.Hash, cap uint64) error {
+		origins = append(origins, origin)
+		return defaultAccountRequestHandler(t, id, root, origin, limit, cap)
+	}
+	syncer := setupSyncer(source)
+	syncer.SetPriorityAccounts(priority)
+	if _, ok := syncer.PriorityState(); ok {
+		t.Fatal("priority state available before the sync")
+	}
+	done := checkStall(t, term)
+	if err := syncer.Sync(sourceAccountTrie.Hash(), cancel); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	close(done)
+	verifyTrie(syncer.db, sourceAccountTrie.Hash(), t)
+
+	if len(origins) < len(priority) {
+		t.Fatalf("account requests mismatch: have %d, want at least %d", len(origins), len(priority))
+	}
+	first := make(map[common.Hash]bool)
+	for _, origin := range origins[:len(priority)] {
+		first[origin] = true
+	}
+	for _, account := range priority {
+		if !first[account] {
+			t.Errorf("priority account %x not retrieved first, origins %x", account, origins[:len(priority)])
+		}
+	}
+	root, ok := syncer.PriorityState()
+	if !ok || root != sourceAccountTrie.Hash() {
+		t.Fatalf("priority state mismatch: have %x %v, want %x true", root, ok, sourceAccountTrie.Hash())
+	}
+	account := priority[0]
+	if len(rawdb.ReadAccountSnapshot(syncer.db, account)) == 0 {
+		t.Error("priority account not persisted")
+	}
+	for _, slot := range storageElems[account] {
+		if !bytes.Equal(rawdb.ReadStorageSnapshot(syncer.db, account, common.BytesToHash(slot.k)), slot.v) {
+			t.Fatalf("priority account slot %x not persisted", slot.k)
+		}
+	}
+}
+
 // TestMultiSyncManyUseless contains one good peer, and many which doesn't return anything valuable at all
 func TestMultiSyncManyUseless(t *testing.T) {
 	t.Parallel()
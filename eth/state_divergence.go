@@ -0,0 +1,273 @@
+package eth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/event"
+	"github.com/autonity/autonity/log"
+)
+
+// stateMismatchThreshold is the number of consecutive block imports failing with a state root mismatch
+// after which the local state is considered diverged from the network. The blocks are quorum certified,
+// so repeated mismatches point at a corruption of the local state rather than at the network.
+const stateMismatchThreshold = 3
+
+var errNoVerifiedState = errors.New("no epoch boundary with a verified state")
+
+// stateDivergencePhase is the participation of the local node in consensus as decided by the state
+// divergence guard.
+type stateDivergencePhase uint32
+
+const (
+	stateParticipating stateDivergencePhase = iota // the local state agrees with the network
+	stateHalted                                    // diverged, halted until an operator intervenes
+	stateResyncing                                 // diverged, rewound to a verified state and resyncing
+	stateAbandoned                                 // the automatic resync did not recover in time
+)
+
+func (p stateDivergencePhase) String() string {
+	switch p {
+	case stateParticipating:
+		return "participating"
+	case stateHalted:
+		return "halted"
+	case stateResyncing:
+		return "resyncing"
+	case stateAbandoned:
+		return "abandoned"
+	default:
+		return "unknown"
+	}
+}
+
+// divergenceChain is the part of the chain watched and rewound by the state divergence guard.
+type divergenceChain interface {
+	CurrentBlock() *types.Block
+	GetHeaderByNumber(number uint64) *types.Header
+	HasState(root common.Hash) bool
+	VerifyState(root common.Hash) error
+	SetHead(head uint64) error
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	SubscribeStateMismatchEvent(ch chan<- core.StateMismatchEvent) event.Subscription
+}
+
+// stateDivergenceStatus is the state of the stateDivergenceGuard.
+type stateDivergenceStatus struct {
+	Phase      stateDivergencePhase
+	Mismatches int    // consecutive imports failing with a state root mismatch
+	DivergedAt uint64 // block failing the verification when the divergence was detected
+	LastError  string // last state root mismatch
+	RewoundTo  uint64 // epoch boundary the chain was last rewound to
+	Deadline   time.Time
+	Recoveries uint64
+}
+
+// stateDivergenceGuard halts the consensus participation once the blocks certified by the network
+// repeatedly fail the local state root check, as the node would otherwise prevote nil forever. The
+// blocks keep being verified while halted and the participation is resumed once a block at or above
+// the divergence height imports. With the automatic resync enabled, the chain is rewound to the last
+// epoch boundary whose state verifies, so that the blocks are executed again from there. The resync
+// is given up after the timeout, leaving the node halted.
+type stateDivergenceGuard struct {
+	chain       divergenceChain
+	epochPeriod func() uint64
+	halt        func() // stops the consensus participation
+	resume      func() // resumes the consensus participation
+	autoResync  bool
+	timeout     time.Duration
+	log         log.Logger
+
+	mu     sync.Mutex
+	status stateDivergenceStatus
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newStateDivergenceGuard(chain divergenceChain, epochPeriod func() uint64, halt, resume func(), autoResync bool, timeout time.Duration, logger log.Logger) *stateDivergenceGuard {
+	return &stateDivergenceGuard{
+		chain:       chain,
+		epochPeriod: epochPeriod,
+		halt:        halt,
+		resume:      resume,
+		autoResync:  autoResync,
+		timeout:     timeout,
+		log:         logger,
+		quit:        make(chan struct{}),
+	}
+}
+
+// Start watches the block imports until Stop is called.
+func (g *stateDivergenceGuard) Start() {
+	mismatchCh := make(chan core.StateMismatchEvent, 16)
+	mismatchSub := g.chain.SubscribeStateMismatchEvent(mismatchCh)
+	headCh := make(chan core.ChainHeadEvent, 16)
+	headSub := g.chain.SubscribeChainHeadEvent(headCh)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer mismatchSub.Unsubscribe()
+		defer headSub.Unsubscribe()
+
+		var (
+			timer    *time.Timer
+			deadline <-chan time.Time
+		)
+		for {
+			select {
+			case ev := <-mismatchCh:
+				g.onMismatch(ev)
+			case ev := <-headCh:
+				g.onHead(ev.Block)
+			case <-deadline:
+				g.abandon()
+			case <-mismatchSub.Err():
+				return
+			case <-headSub.Err():
+				return
+			case <-g.quit:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+			// the resync is time boxed from the rewind on
+			switch status := g.Status(); {
+			case status.Phase == stateResyncing && deadline == nil:
+				timer = time.NewTimer(time.Until(status.Deadline))
+				deadline = timer.C
+			case status.Phase != stateResyncing && deadline != nil:
+				timer.Stop()
+				timer, deadline = nil, nil
+			}
+		}
+	}()
+}
+
+// Stop stops watching the block imports.
+func (g *stateDivergenceGuard) Stop() {
+	close(g.quit)
+	g.wg.Wait()
+}
+
+// Participating reports whether the node may take part in consensus.
+func (g *stateDivergenceGuard) Participating() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status.Phase == stateParticipating
+}
+
+// Status returns the state of the guard.
+func (g *stateDivergenceGuard) Status() stateDivergenceStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.status
+}
+
+func (g *stateDivergenceGuard) onMismatch(ev core.StateMismatchEvent) {
+	number := ev.Block.NumberU64()
+	g.mu.Lock()
+	g.status.Mismatches++
+	g.status.LastError = ev.Err.Error()
+	status := g.status
+	g.mu.Unlock()
+
+	g.log.Error("CRITICAL: quorum certified block failed the local state root check", "number", number,
+		"hash", ev.Block.Hash(), "mismatches", status.Mismatches, "threshold", stateMismatchThreshold, "err", ev.Err)
+	if status.Mismatches < stateMismatchThreshold {
+		return
+	}
+	switch status.Phase {
+	case stateParticipating:
+		g.mu.Lock()
+		g.status.Phase = stateHalted
+		g.status.DivergedAt = number
+		g.mu.Unlock()
+		g.log.Error("CRITICAL: local state diverged from the network, consensus participation halted", "number", number)
+		g.halt()
+		if !g.autoResync {
+			g.log.Error("CRITICAL: automatic resync disabled, manual intervention required", "number", number)
+			return
+		}
+		g.resync()
+	case stateResyncing:
+		// the state rewound to was verified, yet the blocks executed from it diverge again
+		g.log.Error("CRITICAL: local state diverged again during the automatic resync", "number", number)
+		g.abandon()
+	}
+}
+
+func (g *stateDivergenceGuard) onHead(block *types.Block) {
+	g.mu.Lock()
+	g.status.Mismatches = 0
+	status := g.status
+	if status.Phase == stateParticipating || block.NumberU64() < status.DivergedAt {
+		g.mu.Unlock()
+		return
+	}
+	g.status.Phase = stateParticipating
+	g.status.Recoveries++
+	g.mu.Unlock()
+
+	g.log.Warn("Local state agrees with the network again, consensus participation resumed", "number", block.NumberU64(),
+		"diverged", status.DivergedAt, "phase", status.Phase)
+	g.resume()
+}
+
+// resync rewinds the chain to the last epoch boundary whose state verifies, the blocks above it being
+// executed again as they are synced from the network.
+func (g *stateDivergenceGuard) resync() {
+	g.log.Warn("Starting the automatic resync, looking for the last epoch boundary with a verified state", "timeout", g.timeout)
+	number, err := g.rewind()
+	if err != nil {
+		g.log.Error("CRITICAL: automatic resync failed, manual intervention required", "err", err)
+		g.mu.Lock()
+		g.status.Phase = stateAbandoned
+		g.mu.Unlock()
+		return
+	}
+	g.mu.Lock()
+	g.status.Phase = stateResyncing
+	g.status.RewoundTo = number
+	g.status.Deadline = time.Now().Add(g.timeout)
+	g.mu.Unlock()
+	g.log.Warn("Chain rewound to a verified state, resyncing", "number", number, "diverged", g.Status().DivergedAt)
+}
+
+func (g *stateDivergenceGuard) rewind() (uint64, error) {
+	period := max(g.epochPeriod(), 1)
+	head := g.chain.CurrentBlock().NumberU64()
+	for number := head - head%period; ; number -= period {
+		if header := g.chain.GetHeaderByNumber(number); header != nil && g.chain.HasState(header.Root) {
+			g.log.Info("Verifying the state of the epoch boundary", "number", number, "root", header.Root)
+			err := g.chain.VerifyState(header.Root)
+			if err == nil {
+				g.log.Warn("Rewinding the chain to the epoch boundary", "number", number, "head", head)
+				return number, g.chain.SetHead(number)
+			}
+			g.log.Warn("State of the epoch boundary failed the verification", "number", number, "err", err)
+		}
+		if number < period {
+			return 0, errNoVerifiedState
+		}
+	}
+}
+
+func (g *stateDivergenceGuard) abandon() {
+	g.mu.Lock()
+	if g.status.Phase != stateResyncing {
+		g.mu.Unlock()
+		return
+	}
+	g.status.Phase = stateAbandoned
+	status := g.status
+	g.mu.Unlock()
+	g.log.Error("CRITICAL: automatic resync did not recover, manual intervention required", "diverged", status.DivergedAt,
+		"rewound", status.RewoundTo, "head", g.chain.CurrentBlock().NumberU64())
+}
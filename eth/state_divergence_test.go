@@ -0,0 +1,223 @@
+package eth
+
+import (
+	"bytes"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/accounts/abi/bind/backends"
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus/ethash"
+	"github.com/autonity/autonity/core"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/core/types"
+	"github.com/autonity/autonity/core/vm"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/params"
+	"github.com/autonity/autonity/rlp"
+	"github.com/autonity/autonity/trie"
+)
+
+const (
+	divergenceEpochPeriod = 4
+	divergenceImported    = 5 // blocks imported before the state is corrupted
+)
+
+// newDivergedChain creates an archive chain of 8 blocks, each sending funds from testAddr, and imports
+// the first 5 of them before corrupting the account of testAddr at the head state. The following blocks
+// fail the state root check, while the state of the epoch boundary at block 4 still verifies.
+func newDivergedChain(t *testing.T) (*core.BlockChain, []*types.Block) {
+	gspec := &core.Genesis{
+		Config:  params.TestChainConfig,
+		Mixhash: types.BFTDigest,
+		Alloc:   core.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+	}
+	genDB := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(genDB)
+	signer := types.LatestSigner(gspec.Config)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), genDB, 8, func(i int, gen *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(testAddr), common.Address{0x01}, big.NewInt(1), params.TxGas, gen.BaseFee(), nil), signer, testKey)
+		require.NoError(t, err)
+		gen.AddTx(tx)
+	})
+
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain := newArchiveChain(t, db, gspec.Config)
+	_, err := chain.InsertChain(blocks[:divergenceImported])
+	require.NoError(t, err)
+	root := chain.CurrentBlock().Root()
+	chain.Stop()
+
+	corruptAccount(t, db, root, testAddr)
+	// reopened without the trie nodes cached by the import
+	chain = newArchiveChain(t, db, gspec.Config)
+	t.Cleanup(chain.Stop)
+	require.Equal(t, uint64(divergenceImported), chain.CurrentBlock().NumberU64())
+	return chain, blocks
+}
+
+func newArchiveChain(t *testing.T, db ethdb.Database, config *params.ChainConfig) *core.BlockChain {
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{TrieCleanLimit: 16, TrieDirtyDisabled: true}, config, ethash.NewFaker(), vm.Config{}, nil, &core.TxSenderCacher{}, nil, backends.NewInternalBackend(nil), log.Root())
+	require.NoError(t, err)
+	return chain
+}
+
+// corruptAccount bumps the balance of the account in the trie node holding it, leaving the node stored
+// under its former hash.
+func corruptAccount(t *testing.T, db ethdb.Database, root common.Hash, address common.Address) {
+	accounts, err := trie.NewSecure(root, trie.NewDatabase(db))
+	require.NoError(t, err)
+	key := crypto.Keccak256(address[:])
+	var node common.Hash
+	it := accounts.NodeIterator(nil)
+	for it.Next(true) {
+		if !it.Leaf() {
+			if it.Hash() != (common.Hash{}) {
+				node = it.Hash()
+			}
+			continue
+		}
+		if !bytes.Equal(it.LeafKey(), key) {
+			continue
+		}
+		var account types.StateAccount
+		require.NoError(t, rlp.DecodeBytes(it.LeafBlob(), &account))
+		account.Balance = new(big.Int).Add(account.Balance, big.NewInt(1))
+		corrupted, err := rlp.EncodeToBytes(&account)
+		require.NoError(t, err)
+		require.Len(t, corrupted, len(it.LeafBlob()))
+
+		blob := rawdb.ReadTrieNode(db, node)
+		require.Equal(t, 1, bytes.Count(blob, it.LeafBlob()))
+		rawdb.WriteTrieNode(db, node, bytes.Replace(blob, it.LeafBlob(), corrupted, 1))
+		return
+	}
+	require.NoError(t, it.Error())
+	t.Fatal("account not found")
+}
+
+type testDivergenceGuard struct {
+	*stateDivergenceGuard
+	halts, resumes atomic.Int32
+}
+
+func newTestDivergenceGuard(t *testing.T, chain *core.BlockChain, autoResync bool, timeout time.Duration) *testDivergenceGuard {
+	guard := new(testDivergenceGuard)
+	guard.stateDivergenceGuard = newStateDivergenceGuard(chain, func() uint64 { return divergenceEpochPeriod },
+		func() { guard.halts.Add(1) }, func() { guard.resumes.Add(1) }, autoResync, timeout, log.Root())
+	guard.Start()
+	t.Cleanup(guard.Stop)
+	return guard
+}
+
+// requirePhase waits for the guard to reach the phase.
+func requirePhase(t *testing.T, guard *testDivergenceGuard, phase stateDivergencePhase) {
+	require.Eventually(t, func() bool {
+		return guard.Status().Phase == phase
+	}, 5*time.Second, 10*time.Millisecond, "phase %s, expected %s", guard.Status().Phase, phase)
+}
+
+// importDiverging imports the first block above the head, which fails the state root check, until the
+// guard detects the divergence.
+func importDiverging(t *testing.T, chain *core.BlockChain, guard *testDivergenceGuard, blocks []*types.Block) {
+	next := blocks[divergenceImported]
+	for i := 1; i <= stateMismatchThreshold; i++ {
+		require.True(t, guard.Participating())
+		_, err := chain.InsertChain(types.Blocks{next})
+		require.ErrorIs(t, err, core.ErrStateRootMismatch)
+		if i < stateMismatchThreshold {
+			require.Eventually(t, func() bool { return guard.Status().Mismatches == i }, 5*time.Second, 10*time.Millisecond)
+		}
+	}
+}
+
+func TestVerifyState(t *testing.T) {
+	chain, _ := newDivergedChain(t)
+	require.ErrorIs(t, chain.VerifyState(chain.CurrentBlock().Root()), core.ErrCorruptedState)
+	require.NoError(t, chain.VerifyState(chain.GetHeaderByNumber(divergenceEpochPeriod).Root))
+	require.NoError(t, chain.VerifyState(chain.GetHeaderByNumber(0).Root))
+}
+
+func TestStateDivergenceGuard(t *testing.T) {
+	t.Run("mismatches interrupted by an import are not a divergence", func(t *testing.T) {
+		chain, blocks := newDivergedChain(t)
+		guard := newTestDivergenceGuard(t, chain, true, time.Minute)
+		_, err := chain.InsertChain(types.Blocks{blocks[divergenceImported]})
+		require.ErrorIs(t, err, core.ErrStateRootMismatch)
+		require.Eventually(t, func() bool { return guard.Status().Mismatches == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, chain.SetHead(divergenceEpochPeriod))
+		_, err = chain.InsertChain(blocks[divergenceEpochPeriod:divergenceImported])
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return guard.Status().Mismatches == 0 }, 5*time.Second, 10*time.Millisecond)
+		require.True(t, guard.Participating())
+		require.Zero(t, guard.halts.Load())
+	})
+
+	t.Run("halted without the automatic resync", func(t *testing.T) {
+		chain, blocks := newDivergedChain(t)
+		guard := newTestDivergenceGuard(t, chain, false, time.Minute)
+		importDiverging(t, chain, guard, blocks)
+		requirePhase(t, guard, stateHalted)
+
+		require.False(t, guard.Participating())
+		require.Equal(t, int32(1), guard.halts.Load())
+		require.Zero(t, guard.resumes.Load())
+		require.Equal(t, uint64(divergenceImported+1), guard.Status().DivergedAt)
+		require.Equal(t, uint64(divergenceImported), chain.CurrentBlock().NumberU64(), "chain rewound")
+
+		health := NewPublicStateHealthAPI(guard.stateDivergenceGuard).StateHealth()
+		require.Equal(t, "critical", health.Health)
+		require.Equal(t, "halted", health.Phase)
+		require.Contains(t, health.LastError, "invalid merkle root")
+		require.Nil(t, health.Deadline)
+	})
+
+	t.Run("rewound and recovered with the automatic resync", func(t *testing.T) {
+		chain, blocks := newDivergedChain(t)
+		guard := newTestDivergenceGuard(t, chain, true, time.Minute)
+		importDiverging(t, chain, guard, blocks)
+		requirePhase(t, guard, stateResyncing)
+
+		require.Equal(t, int32(1), guard.halts.Load())
+		require.Equal(t, uint64(divergenceEpochPeriod), guard.Status().RewoundTo)
+		require.Equal(t, uint64(divergenceEpochPeriod), chain.CurrentBlock().NumberU64())
+		health := NewPublicStateHealthAPI(guard.stateDivergenceGuard).StateHealth()
+		require.Equal(t, "critical", health.Health)
+		require.NotNil(t, health.Deadline)
+
+		// the blocks below the divergence do not resume the participation
+		_, err := chain.InsertChain(blocks[divergenceEpochPeriod:divergenceImported])
+		require.NoError(t, err)
+		require.Never(t, guard.Participating, 100*time.Millisecond, 10*time.Millisecond)
+
+		_, err = chain.InsertChain(blocks[divergenceImported:])
+		require.NoError(t, err)
+		requirePhase(t, guard, stateParticipating)
+		require.Equal(t, int32(1), guard.resumes.Load())
+		require.NoError(t, chain.VerifyState(chain.CurrentBlock().Root()))
+
+		health = NewPublicStateHealthAPI(guard.stateDivergenceGuard).StateHealth()
+		require.Equal(t, "healthy", health.Health)
+		require.Equal(t, uint64(1), uint64(health.Recoveries))
+		require.Nil(t, health.Deadline)
+	})
+
+	t.Run("resync given up after the timeout", func(t *testing.T) {
+		chain, blocks := newDivergedChain(t)
+		guard := newTestDivergenceGuard(t, chain, true, 100*time.Millisecond)
+		importDiverging(t, chain, guard, blocks)
+		requirePhase(t, guard, stateAbandoned)
+
+		require.False(t, guard.Participating())
+		require.Equal(t, uint64(divergenceEpochPeriod), guard.Status().RewoundTo)
+		require.Zero(t, guard.resumes.Load())
+	})
+}
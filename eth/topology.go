@@ -11,15 +11,34 @@ const (
 	MaxDegree = 25
 	// if the network size exceeds MaxGraphSize, we divide the network in smaller sub-network of size MaxGraphSize
 	MaxGraphSize = 64
+	// committee size up to which the degree of the graph is bounded by MaxDegree
+	MaxDegreeGraphSize = 36 * MaxGraphSize
+	// max diameter of the graph, it is 2 up to MaxGraphSize nodes
+	MaxDiameter = 4
 )
 
+// networkTopology selects the committee members a node connects to on the consensus overlay. The selection
+// is a pure function of the committee enodes, in the order of the protocol contract, and of the index of
+// the node in it: every member computes the same graph. The graph of a committee of n members guarantees:
+//   - the edges are bidirectional: i selects j if and only if j selects i, and no node selects itself;
+//   - it is connected, with a diameter of 2 up to MaxGraphSize nodes and MaxDiameter beyond;
+//   - below the configured minimum of nodes, the members are fully meshed, with a degree of n-1. From it,
+//     the degree is 2*(ceil(sqrt(n))-1) at most up to MaxGraphSize nodes, and MaxDegree at most up to
+//     MaxDegreeGraphSize nodes. It grows slowly with the number of components beyond.
+//
+// A node outside the committee connects to all the members.
 type networkTopology struct {
-	minNodes int
+	minNodes int // committee size up to which the nodes are fully meshed, 0 for a full mesh at any size
+	degree   int // number of peers selected for the last committee
 }
 
+// NewGraphTopology creates a topology connecting all the committee nodes while the committee has fewer
+// than minNodes members, and along a low diameter graph beyond. A minNodes of 0 always connects all of
+// them.
 func NewGraphTopology(minNodes int) networkTopology {
 	return networkTopology{
 		minNodes: minNodes,
+		degree:   minNodes,
 	}
 }
 
@@ -27,6 +46,18 @@ func (g *networkTopology) SetMinNodes(n int) {
 	g.minNodes = n
 }
 
+// Degree returns the number of peers selected for the last committee, the configured minimum until
+// a subset is requested.
+func (g *networkTopology) Degree() int {
+	return g.degree
+}
+
+// DegreeBound returns the maximum degree of the committee members for committees up to MaxDegreeGraphSize
+// members.
+func (g *networkTopology) DegreeBound() int {
+	return max(MaxDegree, g.minNodes-2)
+}
+
 func (g *networkTopology) computeSquareRoot(n int) int {
 	return int(math.Ceil(math.Sqrt(float64(n))))
 }
@@ -85,14 +116,16 @@ func (g *networkTopology) componentCount(nodeCount int) int {
 func (g *networkTopology) componentEndIndex(totalNodes int) []int {
 	components := g.componentCount(totalNodes)
 	componentEndIndex := make([]int, components)
-	for i := 0; i < components-1; i++ {
-		componentEndIndex[i] = (i + 1) * MaxGraphSize
-	}
-	componentEndIndex[components-1] = totalNodes
-	// For any two components, a and b, 2*size(a) >= size(b) is followed when creating the component
-	// Otherwise the nodes from the smaller component will have too many edges
-	if components > 1 && g.componentSize(componentEndIndex, components-1) < (MaxGraphSize+1)/2 {
-		componentEndIndex[components-2] -= MaxGraphSize / 2
+	// The components are balanced: their sizes differ by one at most, so that a node of a smaller component
+	// is connected to two nodes of a larger one at most once per pair of components.
+	size, larger := totalNodes/components, totalNodes%components
+	end := 0
+	for i := 0; i < components; i++ {
+		end += size
+		if i < larger {
+			end++
+		}
+		componentEndIndex[i] = end
 	}
 	return componentEndIndex
 }
@@ -121,11 +154,14 @@ func (g *networkTopology) componentIndex(componentEndIndex []int, nodeIndex int)
 // Consider two components a and b such that they are connected directly by an edge where size(a) >= size(b)
 // But to connect the component, we need to create edge between some nodes from component a and b
 // Lets number all the nodes in component a from 0 to (a-1) and all the nodes in component b from 0 to (b-1)
-// Each node, c from component a will be connected to another node, d from compoent b such that d = c % size(b).
-// For example, for two compoents a and b with size = 3, all the ndoes in each component will be numbered from 0 to 2
-// Then node i = {0,1,2} from component a will be connected to node i from component b
-// For two components a with size 3 and b with size 2, nodes will be numbered form 0 to 2 (component a) and from 0 to 1 (component b)
-// Then node i = {0,1} from component a will be connected to node i from component b, and node = 2 from component a will be connected to node 0 from component b
+// Each node, c from component a will be connected to another node, d from component b such that
+// d = (k - c) mod size(b), where k is the sum of the indexes of the two components. The mapping is its own
+// inverse, so that the edges are bidirectional between two components of the same size.
+// For example, for two components a and b with size = 3 and k = 1, node 0 from component a is connected to
+// node 1 from component b, node 1 to node 0 and node 2 to node 2.
+// As the components are balanced, size(a) <= size(b) + 1: the single node of a left over is connected to
+// node k mod size(b), which is a different node for each of the components b is connected to, so that the
+// extra edges are spread over the nodes of b.
 func (g *networkTopology) adjacentNodesIndex(myIndex, totalNodes int) []int {
 	if totalNodes <= MaxGraphSize {
 		return g.edges(myIndex, totalNodes)
@@ -143,29 +179,27 @@ func (g *networkTopology) adjacentNodesIndex(myIndex, totalNodes int) []int {
 	componentConnections := g.adjacentNodesIndex(myComponentIndex, componentCount)
 	for _, componentIndex := range componentConnections {
 		componentSize := g.componentSize(componentEndIndex, componentIndex)
-		peerRelativeIndex := relativeIndex
+		k := myComponentIndex + componentIndex
 		if myComponentSize >= componentSize {
-			if peerRelativeIndex >= componentSize {
-				// for a < 2*b and a >= b, (a % b) can be written as (a - b)
-				// components are divided in g.componentEndIndex(int) in such a way that we have
-				// peerRelativeIndex < 2*componentSize
-				peerRelativeIndex -= componentSize
-			}
+			peerRelativeIndex := mod(k-relativeIndex, componentSize)
 			peerIndex := g.indexFromRelativeIndex(componentEndIndex[componentIndex], peerRelativeIndex)
 			connections = append(connections, peerIndex)
 		} else {
-			factor := (componentSize + myComponentSize - 1) / myComponentSize // factor = math.Ceil(componentSize/myComponentSize)
-			for factor > 0 && peerRelativeIndex < componentSize {
+			// the nodes of the larger component which are mapped to this node
+			for peerRelativeIndex := mod(k-relativeIndex, myComponentSize); peerRelativeIndex < componentSize; peerRelativeIndex += myComponentSize {
 				peerIndex := g.indexFromRelativeIndex(componentEndIndex[componentIndex], peerRelativeIndex)
 				connections = append(connections, peerIndex)
-				peerRelativeIndex += myComponentSize
-				factor--
 			}
 		}
 	}
 	return connections
 }
 
+// mod returns the non-negative remainder of a divided by b.
+func mod(a, b int) int {
+	return ((a % b) + b) % b
+}
+
 func (g *networkTopology) MyIndex(nodes []*enode.Node, localNode *enode.LocalNode) int {
 	for i, node := range nodes {
 		if node.ID() == localNode.ID() {
@@ -175,22 +209,54 @@ func (g *networkTopology) MyIndex(nodes []*enode.Node, localNode *enode.LocalNod
 	return -1
 }
 
+// fullMesh returns true if the committee members are all connected to each other.
+func (g *networkTopology) fullMesh(totalNodes int) bool {
+	return g.minNodes == 0 || totalNodes < g.minNodes
+}
+
+// subset returns the indexes of the members the member at myIndex connects to, in a committee of totalNodes
+// members. It is a pure function of its arguments and of the configured minimum of nodes.
+func (g *networkTopology) subset(myIndex, totalNodes int) []int {
+	if g.fullMesh(totalNodes) {
+		subset := make([]int, 0, totalNodes-1)
+		for i := 0; i < totalNodes; i++ {
+			if i != myIndex {
+				subset = append(subset, i)
+			}
+		}
+		return subset
+	}
+	return g.adjacentNodesIndex(myIndex, totalNodes)
+}
+
+// Graph returns the adjacency lists of the members of a committee of totalNodes members, by index.
+func (g *networkTopology) Graph(totalNodes int) [][]int {
+	graph := make([][]int, totalNodes)
+	for i := range graph {
+		graph[i] = g.subset(i, totalNodes)
+	}
+	return graph
+}
+
 // the input array (nodes []*enode.Node) must be same for everyone in order to create a connected graph
 // Returns the list of adjacentNodes to connect with localNode. Given that the order of the input array nodes is same
-// for everyone, connecting to only adjacentNodes will create a connected graph with diameter <= 4
+// for everyone, connecting to only adjacentNodes will create a connected graph with diameter <= MaxDiameter
 func (g *networkTopology) RequestSubset(nodes []*enode.Node, myIndex int) []*enode.Node {
-	if len(nodes) < g.minNodes {
-		// connect to all nodes
-		return nodes
-	}
-	// If the node is not in committee, it has all slots available, so connect to all committee nodes
-	if myIndex == -1 {
+	// Connect to all nodes below the full mesh size. If the node is not in committee, it has all
+	// slots available, so connect to all committee nodes as well
+	if g.fullMesh(len(nodes)) || myIndex == -1 {
+		g.degree = len(nodes)
+		if myIndex != -1 {
+			g.degree--
+		}
 		return nodes
 	}
+	// the degree of the graph grows with the committee
 	adjacentNodes := g.adjacentNodesIndex(myIndex, len(nodes))
 	connections := make([]*enode.Node, 0, len(adjacentNodes))
 	for _, index := range adjacentNodes {
 		connections = append(connections, nodes[index])
 	}
+	g.degree = len(connections)
 	return connections
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/eth/ethconfig"
 	"github.com/autonity/autonity/p2p/enode"
 	"github.com/autonity/autonity/params"
 )
@@ -26,7 +27,7 @@ func TestEdgeDirection(t *testing.T) {
 		edgeChecker[i] = make([]int, nodeCount)
 	}
 	connections := make([][]*enode.Node, nodeCount)
-	topology := NewGraphTopology(0)
+	topology := NewGraphTopology(1)
 	for i := 0; i < nodeCount; i++ {
 		testID := i + 1
 		privateKey, newNode := createNewNode(t, privateKeys)
@@ -114,7 +115,7 @@ type graphTester struct {
 func (graph *graphTester) initiateGraph(targetDiameter, totalNodeCount int) {
 	graph.totalNodeCount = totalNodeCount
 	graph.targetDiameter = targetDiameter
-	graph.topology = NewGraphTopology(0)
+	graph.topology = NewGraphTopology(1)
 	graph.nodes = make([]*enode.Node, 0, totalNodeCount)
 	graph.privateKeys = make(map[*ecdsa.PrivateKey]bool)
 	graph.nodesIndex = make(map[*enode.Node]int)
@@ -277,3 +278,192 @@ func (graph *graphTester) bfs(sourceIndex, nodeCount int, dis []int) {
 		}
 	}
 }
+
+func TestMeshDegree(t *testing.T) {
+	privateKeys := make(map[*ecdsa.PrivateKey]bool)
+	nodes := make([]*enode.Node, 0, 100)
+	for i := 0; i < 100; i++ {
+		privateKey, node := createNewNode(t, privateKeys)
+		privateKeys[privateKey] = true
+		nodes = append(nodes, node)
+	}
+
+	t.Run("full mesh at any size", func(t *testing.T) {
+		topology := NewGraphTopology(0)
+		require.Len(t, topology.RequestSubset(nodes, 3), len(nodes))
+		require.Equal(t, len(nodes)-1, topology.Degree())
+	})
+
+	t.Run("degree larger than committee", func(t *testing.T) {
+		topology := NewGraphTopology(len(nodes) + 1)
+		require.Len(t, topology.RequestSubset(nodes, 3), len(nodes))
+		require.Equal(t, len(nodes)-1, topology.Degree())
+		// a node outside the committee connects to all of it
+		require.Len(t, topology.RequestSubset(nodes, -1), len(nodes))
+		require.Equal(t, len(nodes), topology.Degree())
+	})
+
+	t.Run("default", func(t *testing.T) {
+		topology := NewGraphTopology(ethconfig.Defaults.ConsensusMeshDegree)
+		require.Equal(t, 20, topology.Degree())
+		small := nodes[:ethconfig.Defaults.ConsensusMeshDegree-1]
+		require.Len(t, topology.RequestSubset(small, 3), len(small))
+		require.Equal(t, len(small)-1, topology.Degree())
+
+		// the degree is recomputed for a committee larger than the configured one
+		edges := topology.RequestSubset(nodes, 3)
+		require.Less(t, len(edges), len(nodes))
+		require.Equal(t, len(edges), topology.Degree())
+	})
+}
+
+// topologyCorpus records the graphs of committee configurations around the thresholds of the topology, so
+// that any change of the selection, which would split the network during a rolling upgrade, is detected.
+var topologyCorpus = []struct {
+	committeeSize int
+	meshDegree    int
+	edges         int
+	maxDegree     int
+	diameter      int
+	hash          string // hash of the adjacency lists
+}{
+	{committeeSize: 1, meshDegree: 1, edges: 0, maxDegree: 0, diameter: 0, hash: "0x88ff657d75369af8"},
+	{committeeSize: 2, meshDegree: 1, edges: 1, maxDegree: 1, diameter: 1, hash: "0xab1233608f11292b"},
+	{committeeSize: 3, meshDegree: 1, edges: 2, maxDegree: 2, diameter: 2, hash: "0xe2fa508caf83d2c4"},
+	{committeeSize: 19, meshDegree: 1, edges: 63, maxDegree: 7, diameter: 2, hash: "0x3e36c13ba1a067b7"},
+	{committeeSize: 1, meshDegree: 20, edges: 0, maxDegree: 0, diameter: 0, hash: "0x88ff657d75369af8"},
+	{committeeSize: 19, meshDegree: 20, edges: 171, maxDegree: 18, diameter: 1, hash: "0x0abb6cd55b500388"},
+	{committeeSize: 20, meshDegree: 20, edges: 70, maxDegree: 7, diameter: 2, hash: "0xc6d7599b82116369"},
+	{committeeSize: 21, meshDegree: 20, edges: 74, maxDegree: 8, diameter: 2, hash: "0x9bb9796b1d2b8bd7"},
+	{committeeSize: 57, meshDegree: 20, edges: 371, maxDegree: 14, diameter: 2, hash: "0x2c74f4df814b15e5"},
+	{committeeSize: 64, meshDegree: 20, edges: 448, maxDegree: 14, diameter: 2, hash: "0x049135f4791e896f"},
+	{committeeSize: 65, meshDegree: 20, edges: 332, maxDegree: 12, diameter: 3, hash: "0x6529c26b7b237b12"},
+	{committeeSize: 96, meshDegree: 20, edges: 612, maxDegree: 13, diameter: 3, hash: "0xced205e9319171bf"},
+	{committeeSize: 128, meshDegree: 20, edges: 960, maxDegree: 15, diameter: 3, hash: "0x7cc12e4760e693de"},
+	{committeeSize: 129, meshDegree: 20, edges: 797, maxDegree: 14, diameter: 4, hash: "0xa0e5600d4189e72e"},
+	{committeeSize: 130, meshDegree: 20, edges: 806, maxDegree: 14, diameter: 4, hash: "0xa1ea6ea411d85915"},
+	{committeeSize: 200, meshDegree: 20, edges: 1404, maxDegree: 15, diameter: 4, hash: "0x553391be5d41cf51"},
+	{committeeSize: 256, meshDegree: 20, edges: 2048, maxDegree: 16, diameter: 4, hash: "0x9232b4f97e8e902d"},
+	{committeeSize: 500, meshDegree: 20, edges: 4299, maxDegree: 19, diameter: 4, hash: "0x7e982737aa4df875"},
+	{committeeSize: 1000, meshDegree: 20, edges: 9848, maxDegree: 21, diameter: 4, hash: "0x8ef919c99a6c68de"},
+	{committeeSize: 1017, meshDegree: 20, edges: 10130, maxDegree: 21, diameter: 4, hash: "0x77bbcc9231ac6ab6"},
+	{committeeSize: 1024, meshDegree: 20, edges: 10240, maxDegree: 20, diameter: 4, hash: "0x4a2bd4ef8bde4075"},
+	{committeeSize: 1913, meshDegree: 20, edges: 21966, maxDegree: 24, diameter: 4, hash: "0xdba0d21cc4a5b411"},
+	{committeeSize: 2304, meshDegree: 20, edges: 27648, maxDegree: 24, diameter: 4, hash: "0x776e54d183d70936"},
+	{committeeSize: 99, meshDegree: 100, edges: 4851, maxDegree: 98, diameter: 1, hash: "0x39c87e6c9c1ac3f9"},
+	{committeeSize: 100, meshDegree: 100, edges: 652, maxDegree: 14, diameter: 3, hash: "0x900c0c078d1ca83e"},
+}
+
+// topologyProperties checks the guarantees of the topology on the graph of a committee, and returns its number
+// of edges, max degree and diameter.
+func topologyProperties(t *testing.T, graph [][]int, degreeBound int) (int, int, int) {
+	edges, maxDegree := 0, 0
+	adjacency := make([]map[int]bool, len(graph))
+	for i, peers := range graph {
+		adjacency[i] = make(map[int]bool, len(peers))
+		for _, peer := range peers {
+			if peer == i || adjacency[i][peer] {
+				t.Fatalf("self loop or duplicate edge %d-%d", i, peer)
+			}
+			adjacency[i][peer] = true
+		}
+		edges += len(peers)
+		maxDegree = max(maxDegree, len(peers))
+	}
+	for i, peers := range graph {
+		for _, peer := range peers {
+			if !adjacency[peer][i] {
+				t.Fatalf("edge %d-%d is not bidirectional", i, peer)
+			}
+		}
+	}
+	require.LessOrEqual(t, maxDegree, degreeBound)
+
+	diameter := 0
+	distance := make([]int, len(graph))
+	for source := range graph {
+		for i := range distance {
+			distance[i] = -1
+		}
+		distance[source] = 0
+		queue := []int{source}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			for _, peer := range graph[node] {
+				if distance[peer] == -1 {
+					distance[peer] = distance[node] + 1
+					diameter = max(diameter, distance[peer])
+					queue = append(queue, peer)
+				}
+			}
+		}
+		for i, d := range distance {
+			if d == -1 {
+				t.Fatalf("node %d is not connected to node %d", i, source)
+			}
+		}
+	}
+	return edges / 2, maxDegree, diameter
+}
+
+func topologyHash(graph [][]int) string {
+	return crypto.Keccak256Hash([]byte(fmt.Sprint(graph))).Hex()[:18]
+}
+
+func TestTopologyProperties(t *testing.T) {
+	for _, meshDegree := range []int{1, ethconfig.Defaults.ConsensusMeshDegree} {
+		topology := NewGraphTopology(meshDegree)
+		for n := 1; n <= 500; n++ {
+			_, _, diameter := topologyProperties(t, topology.Graph(n), topology.DegreeBound())
+			if n <= MaxGraphSize {
+				require.LessOrEqual(t, diameter, 2, "committee size %d", n)
+			} else {
+				require.LessOrEqual(t, diameter, MaxDiameter, "committee size %d", n)
+			}
+		}
+	}
+}
+
+func TestTopologyDeterminism(t *testing.T) {
+	privateKeys := make(map[*ecdsa.PrivateKey]bool)
+	nodes := make([]*enode.Node, 0, 300)
+	indexes := make(map[enode.ID]int)
+	for i := 0; i < cap(nodes); i++ {
+		privateKey, node := createNewNode(t, privateKeys)
+		privateKeys[privateKey] = true
+		indexes[node.ID()] = i
+		nodes = append(nodes, node)
+	}
+	// the selection of a node does not depend on the previous selections of the instance
+	used := NewGraphTopology(ethconfig.Defaults.ConsensusMeshDegree)
+	for _, n := range []int{300, 1, 65, 19, 20, 300, 64} {
+		fresh := NewGraphTopology(ethconfig.Defaults.ConsensusMeshDegree)
+		graph := fresh.Graph(n)
+		require.Equal(t, graph, used.Graph(n))
+		for myIndex := 0; myIndex < n; myIndex++ {
+			selected := make([]int, 0, len(graph[myIndex]))
+			for _, node := range used.RequestSubset(nodes[:n], myIndex) {
+				if indexes[node.ID()] != myIndex {
+					selected = append(selected, indexes[node.ID()])
+				}
+			}
+			require.Equal(t, graph[myIndex], selected, "committee size %d, index %d", n, myIndex)
+			require.Equal(t, len(selected), used.Degree())
+		}
+	}
+}
+
+func TestTopologyCorpus(t *testing.T) {
+	for _, c := range topologyCorpus {
+		t.Run(fmt.Sprintf("%d nodes, mesh degree %d", c.committeeSize, c.meshDegree), func(t *testing.T) {
+			topology := NewGraphTopology(c.meshDegree)
+			graph := topology.Graph(c.committeeSize)
+			edges, maxDegree, diameter := topologyProperties(t, graph, max(topology.DegreeBound(), c.maxDegree))
+			require.Equal(t, c.edges, edges)
+			require.Equal(t, c.maxDegree, maxDegree)
+			require.Equal(t, c.diameter, diameter)
+			require.Equal(t, c.hash, topologyHash(graph))
+		})
+	}
+}
@@ -599,6 +599,12 @@ type rpcProgress struct {
 	HealedBytecodeBytes hexutil.Uint64
 	HealingTrienodes    hexutil.Uint64
 	HealingBytecode     hexutil.Uint64
+
+	SyncStrategy string
+	PivotBlock   hexutil.Uint64
+
+	ProtocolStateAvailable bool
+	ProtocolStateBlock     hexutil.Uint64
 }
 
 func (p *rpcProgress) toSyncProgress() *ethereum.SyncProgress {
@@ -623,5 +629,10 @@ func (p *rpcProgress) toSyncProgress() *ethereum.SyncProgress {
 		HealedBytecodeBytes: uint64(p.HealedBytecodeBytes),
 		HealingTrienodes:    uint64(p.HealingTrienodes),
 		HealingBytecode:     uint64(p.HealingBytecode),
+		SyncStrategy:        p.SyncStrategy,
+		PivotBlock:          uint64(p.PivotBlock),
+
+		ProtocolStateAvailable: p.ProtocolStateAvailable,
+		ProtocolStateBlock:     uint64(p.ProtocolStateBlock),
 	}
 }
@@ -122,6 +122,12 @@ type SyncProgress struct {
 
 	HealingTrienodes uint64 // Number of state trie nodes pending
 	HealingBytecode  uint64 // Number of bytecodes pending
+
+	SyncStrategy string // Sync strategy chosen for the current sync cycle
+	PivotBlock   uint64 // Snap sync pivot block number, zero if not snap syncing
+
+	ProtocolStateAvailable bool   // Whether the protocol contracts state is synced ahead of the rest of the state
+	ProtocolStateBlock     uint64 // Block number the protocol contracts state was synced at
 }
 
 // ChainSyncReader wraps access to the node's current sync status. If there's no
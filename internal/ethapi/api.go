@@ -67,7 +67,8 @@ func (s *PublicEthereumAPI) GasPrice(ctx context.Context) (*hexutil.Big, error)
 		return nil, err
 	}
 	if head := s.b.CurrentHeader(); head.BaseFee != nil {
-		tipcap.Add(tipcap, head.BaseFee)
+		// the next blocks cannot go below the minimum base fee, which may have been raised since the head
+		tipcap.Add(tipcap, math.BigMax(head.BaseFee, s.b.MinBaseFee()))
 	}
 	return (*hexutil.Big)(tipcap), err
 }
@@ -146,6 +147,11 @@ func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 		"healedBytecodeBytes": hexutil.Uint64(progress.HealedBytecodeBytes),
 		"healingTrienodes":    hexutil.Uint64(progress.HealingTrienodes),
 		"healingBytecode":     hexutil.Uint64(progress.HealingBytecode),
+		"syncStrategy":        progress.SyncStrategy,
+		"pivotBlock":          hexutil.Uint64(progress.PivotBlock),
+
+		"protocolStateAvailable": progress.ProtocolStateAvailable,
+		"protocolStateBlock":     hexutil.Uint64(progress.ProtocolStateBlock),
 	}, nil
 }
 
@@ -1574,6 +1580,20 @@ func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, has
 	return nil, nil
 }
 
+// GetTransactionByHashInRange returns the transaction for the given hash, looked up in the blocks
+// from and to included if it is no longer indexed.
+func (s *PublicTransactionPoolAPI) GetTransactionByHashInRange(ctx context.Context, hash common.Hash, from hexutil.Uint64, to hexutil.Uint64) (*RPCTransaction, error) {
+	tx, blockHash, blockNumber, index, err := s.b.GetTransactionInRange(ctx, hash, uint64(from), uint64(to))
+	if tx == nil || err != nil {
+		return nil, err
+	}
+	header, err := s.b.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCTransaction(tx, blockHash, blockNumber, index, header.BaseFee, s.b.ChainConfig()), nil
+}
+
 // GetRawTransactionByHash returns the bytes of the transaction for the given hash.
 func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
 	// Retrieve a finalized transaction, or a pooled otherwise
@@ -84,6 +84,11 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'unbanPeer',
+			call: 'admin_unbanPeer',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -142,6 +147,10 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Property({
+			name: 'consensusBans',
+			getter: 'admin_consensusBans'
+		}),
 		new web3._extend.Property({
 			name: 'datadir',
 			getter: 'admin_datadir'
@@ -408,6 +417,63 @@ web3._extend({
 			params: 2,
 			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
 		}),
+		new web3._extend.Method({
+			name: 'meshHistory',
+			call: 'debug_meshHistory',
+			params: 2,
+			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'exportAccountabilityHistory',
+			call: 'debug_exportAccountabilityHistory',
+			params: 3,
+			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null],
+		}),
+		new web3._extend.Method({
+			name: 'dumpCoreState',
+			call: 'debug_dumpCoreState',
+		}),
+		new web3._extend.Method({
+			name: 'consensusTraces',
+			call: 'debug_consensusTraces',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'startConsensusTimingDump',
+			call: 'debug_startConsensusTimingDump',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'stopConsensusTimingDump',
+			call: 'debug_stopConsensusTimingDump',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'decodeConsensusPayload',
+			call: 'debug_decodeConsensusPayload',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'setInternalCallTracing',
+			call: 'debug_setInternalCallTracing',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'internalCallTraces',
+			call: 'debug_internalCallTraces',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'trieCleanJournal',
+			call: 'debug_trieCleanJournal',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'accusationRates',
+			call: 'debug_accusationRates',
+			params: 0,
+		}),
 	],
 	properties: []
 });
@@ -487,6 +553,12 @@ web3._extend({
 			call: 'eth_getRawTransactionByHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getTransactionInRange',
+			call: 'eth_getTransactionByHashInRange',
+			params: 3,
+			inputFormatter: [null, web3._extend.utils.toHex, web3._extend.utils.toHex]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransactionFromBlock',
 			call: function(args) {
@@ -566,6 +638,11 @@ web3._extend({
 			call: 'miner_setExtra',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'setExtraData',
+			call: 'miner_setExtraData',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'setGasPrice',
 			call: 'miner_setGasPrice',
@@ -794,6 +871,26 @@ web3._extend({
 			name: 'getCoreState',
 			call: 'tendermint_getCoreState',
 			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getCommitteeDivergence',
+			call: 'tendermint_getCommitteeDivergence',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getCommitImportStatus',
+			call: 'tendermint_getCommitImportStatus',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getClockSkew',
+			call: 'tendermint_getClockSkew',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getLiveness',
+			call: 'tendermint_getLiveness',
+			params: 0
 		})
 	]
 });
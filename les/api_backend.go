@@ -215,6 +215,12 @@ func (b *LesApiBackend) GetTransaction(ctx context.Context, txHash common.Hash)
 	return light.GetTransaction(ctx, b.eth.odr, txHash)
 }
 
+// GetTransactionInRange retrieves the transaction from the server, the range is not used by the
+// light client.
+func (b *LesApiBackend) GetTransactionInRange(ctx context.Context, txHash common.Hash, from uint64, to uint64) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	return light.GetTransaction(ctx, b.eth.odr, txHash)
+}
+
 func (b *LesApiBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eth.txPool.GetNonce(ctx, addr)
 }
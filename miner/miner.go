@@ -52,7 +52,7 @@ type Config struct {
 	Etherbase  common.Address `toml:",omitempty"` // Public address for block mining rewards (default = first account)
 	Notify     []string       `toml:",omitempty"` // HTTP URL list to be notified of new work packages (only useful in ethash).
 	NotifyFull bool           `toml:",omitempty"` // Notify with pending block headers instead of work packages
-	ExtraData  hexutil.Bytes  `toml:",omitempty"` // Block extra data set by the miner
+	ExtraData  hexutil.Bytes  `toml:",omitempty"` // Metadata appended to the client version in the block extra data
 	GasFloor   uint64         // Target gas floor for mined blocks.
 	GasCeil    uint64         // Target gas ceiling for mined blocks.
 	GasPrice   *big.Int       // Minimum gas price for mining a transaction
@@ -198,17 +198,26 @@ func (miner *Miner) startWorker() {
 // force the start of the worker, without waiting for chain sync completion
 // this is useful if you want to run a single node network and still do consensus
 func (miner *Miner) ForceStart() {
-	miner.forceStartCh <- struct{}{}
+	select {
+	case miner.forceStartCh <- struct{}{}:
+	case <-miner.exitCh:
+	}
 }
 
 // Start signals that the mining should start
 // mining will actually start once we are synced with the network (unless forcing start)
 func (miner *Miner) Start() {
-	miner.startCh <- struct{}{}
+	select {
+	case miner.startCh <- struct{}{}:
+	case <-miner.exitCh:
+	}
 }
 
 func (miner *Miner) Stop() {
-	miner.stopCh <- struct{}{}
+	select {
+	case miner.stopCh <- struct{}{}:
+	case <-miner.exitCh:
+	}
 }
 
 func (miner *Miner) Close() {
@@ -18,7 +18,10 @@
 package miner
 
 import (
+	"context"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -28,6 +31,7 @@ import (
 	"github.com/autonity/autonity/log"
 
 	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/consensus"
 	"github.com/autonity/autonity/consensus/ethash"
 	"github.com/autonity/autonity/core"
 	"github.com/autonity/autonity/core/rawdb"
@@ -290,6 +294,92 @@ func TestEnterExitCommittee(t *testing.T) {
 	})
 }
 
+// sealingCounterEngine is a BFT engine keeping track of the number of sealing
+// loops which have been started and not yet torn down.
+type sealingCounterEngine struct {
+	*ethash.Ethash
+	active    int32
+	maxActive int32
+}
+
+func (e *sealingCounterEngine) Start(ctx context.Context) error {
+	active := atomic.AddInt32(&e.active, 1)
+	for {
+		max := atomic.LoadInt32(&e.maxActive)
+		if active <= max || atomic.CompareAndSwapInt32(&e.maxActive, max, active) {
+			break
+		}
+	}
+	// widen the window in which a concurrent start could slip through
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+func (e *sealingCounterEngine) Close() error {
+	atomic.AddInt32(&e.active, -1)
+	return nil
+}
+
+func TestCommitteeFlipStress(t *testing.T) {
+	engine := &sealingCounterEngine{Ethash: ethash.New(ethash.Config{}, []string{}, false)}
+	engine.SetThreads(-1)
+	miner, mux := createMinerWithEngine(t, engine)
+	defer miner.Close()
+	mux.Post(downloader.DoneEvent{})
+
+	var wg sync.WaitGroup
+	// the RPC path forcing the start while the committee changes
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			miner.ForceStart()
+		}
+	}()
+	// racing start and stop on the worker directly
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			miner.worker.start()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			miner.worker.stop()
+		}
+	}()
+	// committee membership flips every block
+	for i := 0; i < 50; i++ {
+		if i%2 == 0 {
+			miner.Start()
+		} else {
+			miner.Stop()
+		}
+		active := atomic.LoadInt32(&miner.worker.activeSealers)
+		require.True(t, active == 0 || active == 1, "active sealing loops: %d", active)
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&engine.maxActive))
+
+	miner.Start()
+	waitForMiningState(t, miner, true)
+	require.Equal(t, workerRunning, miner.worker.lifecycleState())
+	require.Equal(t, int32(1), atomic.LoadInt32(&miner.worker.activeSealers))
+	require.Equal(t, int32(1), atomic.LoadInt32(&engine.active))
+
+	// a duplicate start is a no-op
+	miner.worker.start()
+	require.Equal(t, int32(1), atomic.LoadInt32(&engine.active))
+
+	miner.Stop()
+	waitForMiningState(t, miner, false)
+	require.Equal(t, workerStopped, miner.worker.lifecycleState())
+	require.Equal(t, int32(0), atomic.LoadInt32(&miner.worker.activeSealers))
+	require.Equal(t, int32(0), atomic.LoadInt32(&engine.active))
+}
+
 // waitForMiningState waits until either
 // * the desired mining state was reached
 // * a timeout was reached which fails the test
@@ -307,6 +397,13 @@ func waitForMiningState(t *testing.T, m *Miner, mining bool) {
 }
 
 func createMiner(t *testing.T) (*Miner, *event.TypeMux) {
+	// Create consensus engine
+	engine := ethash.New(ethash.Config{}, []string{}, false)
+	engine.SetThreads(-1)
+	return createMinerWithEngine(t, engine)
+}
+
+func createMinerWithEngine(t *testing.T, engine consensus.Engine) (*Miner, *event.TypeMux) {
 	// Create Ethash config
 	config := Config{
 		Etherbase: common.HexToAddress("123456789"),
@@ -321,9 +418,6 @@ func createMiner(t *testing.T) (*Miner, *event.TypeMux) {
 	}
 	// Create event Mux
 	mux := new(event.TypeMux)
-	// Create consensus engine
-	engine := ethash.New(ethash.Config{}, []string{}, false)
-	engine.SetThreads(-1)
 	// Create isLocalBlock
 	isLocalBlock := func(block *types.Header) bool {
 		return true
@@ -91,6 +91,7 @@ type environment struct {
 	ancestors mapset.Set     // ancestor set (used for checking uncle parent validity)
 	family    mapset.Set     // family set (used for checking uncle invalidity)
 	tcount    int            // tx count in cycle
+	size      uint64         // encoded size of the included transactions
 	gasPool   *core.GasPool  // available gas used to pack transactions
 	coinbase  common.Address
 
@@ -108,6 +109,7 @@ func (env *environment) copy() *environment {
 		ancestors: env.ancestors.Clone(),
 		family:    env.family.Clone(),
 		tcount:    env.tcount,
+		size:      env.size,
 		coinbase:  env.coinbase,
 		header:    types.CopyHeader(env.header),
 		receipts:  copyReceipts(env.receipts),
@@ -160,6 +162,30 @@ const (
 	commitInterruptResubmit
 )
 
+// workerState is the lifecycle state of the sealing worker.
+type workerState int32
+
+const (
+	workerStopped workerState = iota
+	workerStarting
+	workerRunning
+	workerStopping
+)
+
+func (s workerState) String() string {
+	switch s {
+	case workerStopped:
+		return "stopped"
+	case workerStarting:
+		return "starting"
+	case workerRunning:
+		return "running"
+	case workerStopping:
+		return "stopping"
+	}
+	return "unknown"
+}
+
 // newWorkReq represents a request for new sealing work submitting with relative interrupt notifier.
 type newWorkReq struct {
 	interrupt *int32
@@ -229,9 +255,17 @@ type worker struct {
 	snapshotReceipts types.Receipts
 	snapshotState    *state.StateDB
 
+	// lifecycle state machine of the sealing worker. Transitions are guarded by stateMu,
+	// stateCond is broadcast once a transition completes so that a start (stop) issued
+	// while a stop (start) is in progress waits for it instead of racing with it.
+	stateMu   sync.Mutex
+	stateCond *sync.Cond
+	state     workerState
+
 	// atomic status counters
-	running int32 // The indicator whether the consensus engine is running or not.
-	newTxs  int32 // New arrival transaction count since last sealing work submitting.
+	running       int32 // The indicator whether the consensus engine is running or not.
+	activeSealers int32 // The number of sealing loops currently active, it must never exceed one.
+	newTxs        int32 // New arrival transaction count since last sealing work submitting.
 
 	// noempty is the flag used to control whether the feature of pre-seal empty
 	// block is enabled. The default value is false(pre-seal is enabled by default).
@@ -275,6 +309,7 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
 	}
+	worker.stateCond = sync.NewCond(&worker.stateMu)
 	// Subscribe NewTxsEvent for tx pool
 	worker.txsSub = eth.TxPool().SubscribeNewTxsEvent(worker.txsCh)
 	// Subscribe events for blockchain
@@ -361,23 +396,76 @@ func (w *worker) pendingBlockAndReceipts() (*types.Block, types.Receipts) {
 }
 
 // start sets the running status as 1 and triggers new work submitting.
+// It is idempotent: starting a running worker is a no-op and starting a worker
+// which is being stopped waits for the teardown to complete first.
 func (w *worker) start() {
+	if !w.beginTransition(workerRunning, workerStarting) {
+		w.eth.Logger().Debug("Sealing worker already running, ignoring start")
+		return
+	}
 	if pos, ok := w.engine.(consensus.BFT); ok {
 		err := pos.Start(context.Background())
 		if err != nil && err != backend.ErrStartedEngine {
 			w.eth.Logger().Error("Error starting Consensus Engine", "block", w.chain.CurrentBlock(), "error", err)
 		}
 	}
+	if n := atomic.AddInt32(&w.activeSealers, 1); n != 1 {
+		w.eth.Logger().Error("Unexpected number of active sealing loops", "active", n)
+	}
 	atomic.StoreInt32(&w.running, 1)
-	w.startCh <- struct{}{}
+	w.endTransition(workerRunning)
+
+	select {
+	case w.startCh <- struct{}{}:
+	case <-w.exitCh:
+	}
 }
 
 // stop sets the running status as 0.
+// It is idempotent: stopping a stopped worker is a no-op and stopping a worker
+// which is being started waits for the start to complete first.
 func (w *worker) stop() {
+	if !w.beginTransition(workerStopped, workerStopping) {
+		w.eth.Logger().Debug("Sealing worker already stopped, ignoring stop")
+		return
+	}
 	atomic.StoreInt32(&w.running, 0)
 	if err := w.engine.Close(); err != nil {
 		w.eth.Logger().Debug("Error stopping Consensus Engine", "error", err)
 	}
+	atomic.AddInt32(&w.activeSealers, -1)
+	w.endTransition(workerStopped)
+}
+
+// beginTransition waits for any in-flight transition to complete, then moves the
+// worker into the intermediate state `via`. It returns false if the worker is
+// already in the `target` state, in which case nothing needs to be done.
+func (w *worker) beginTransition(target, via workerState) bool {
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+	for w.state == workerStarting || w.state == workerStopping {
+		w.stateCond.Wait()
+	}
+	if w.state == target {
+		return false
+	}
+	w.state = via
+	return true
+}
+
+// endTransition completes the current transition and wakes up the waiters.
+func (w *worker) endTransition(state workerState) {
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+	w.state = state
+	w.stateCond.Broadcast()
+}
+
+// lifecycleState returns the current lifecycle state of the worker.
+func (w *worker) lifecycleState() workerState {
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+	return w.state
 }
 
 // isRunning returns an indicator whether worker is running or not.
@@ -428,6 +516,14 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 	defer timer.Stop()
 	<-timer.C // discard the initial tick
 
+	// the consensus engine may ask for a fresh candidate block, e.g. to retrigger a missed proposal
+	var candidateReqCh chan struct{}
+	if r, ok := w.engine.(consensus.CandidateRequester); ok {
+		candidateReqCh = make(chan struct{}, 1)
+		sub := r.SubscribeCandidateRequests(candidateReqCh)
+		defer sub.Unsubscribe()
+	}
+
 	// commit aborts in-flight transaction execution with given signal and resubmits a new one.
 	commit := func(noempty bool, s int32) {
 		if interrupt != nil {
@@ -468,6 +564,12 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 			}
 			commit(false, commitInterruptNewHead)
 
+		case <-candidateReqCh:
+			if w.isRunning() {
+				timestamp = time.Now().Unix()
+				commit(false, commitInterruptResubmit)
+			}
+
 		case <-timer.C:
 			// If sealing is running resubmit a new work cycle periodically to pull in
 			// higher priced transactions. Disable this overhead for pending blocks.
@@ -865,6 +967,11 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
 	}
+	// the proposal must fit in the size limit, the header share is reserved upfront
+	var sizeBudget uint64
+	if limit := w.chainConfig.ProposalSizeLimit(gasLimit); limit > params.ProposalHeaderReserve {
+		sizeBudget = limit - params.ProposalHeaderReserve
+	}
 	var coalescedLogs []*types.Log
 
 	for {
@@ -911,6 +1018,12 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 			txs.Pop()
 			continue
 		}
+		// Skip the account if the transaction does not fit in the proposal anymore
+		if txSize := uint64(tx.Size()); env.size+txSize > sizeBudget {
+			w.eth.Logger().Trace("Proposal size limit reached", "sender", from, "size", txSize, "used", env.size, "budget", sizeBudget)
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), env.tcount)
 
@@ -935,6 +1048,7 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
 			env.tcount++
+			env.size += uint64(tx.Size())
 			txs.Shift()
 
 		case errors.Is(err, core.ErrTxTypeNotSupported):
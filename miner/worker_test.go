@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"bytes"
 	"math/big"
 	"math/rand"
 	"os"
@@ -151,7 +152,9 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 
 	te, ok := engine.(*tendermintBackend.Backend)
 	if ok {
-		te.SetBlockchain(chain)
+		if err := te.Attach(chain, chain.ProtocolContracts()); err != nil {
+			t.Fatal(err)
+		}
 	}
 
 	// Generate a small n-block chain and an uncle block for it
@@ -465,6 +468,48 @@ func testRegenerateMiningBlock(t *testing.T, chainConfig *params.ChainConfig, en
 	}
 }
 
+// TestSetExtra checks that the extra data set at runtime is used by the next block proposed.
+func TestSetExtra(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	extraCh := make(chan []byte, 16)
+	w.newTaskHook = func(task *task) {
+		select {
+		case extraCh <- task.block.Extra():
+		default:
+		}
+	}
+	w.skipSealHook = func(task *task) bool {
+		return true
+	}
+	waitExtra := func(want []byte) {
+		timeout := time.NewTimer(3 * time.Second)
+		defer timeout.Stop()
+		for {
+			select {
+			case extra := <-extraCh:
+				if bytes.Equal(extra, want) {
+					return
+				}
+			case <-timeout.C:
+				t.Fatalf("no block proposed with extra data %x", want)
+			}
+		}
+	}
+
+	w.setExtra([]byte("first"))
+	w.start()
+	waitExtra([]byte("first"))
+
+	// the new transactions get a new block proposed
+	w.setExtra([]byte("second"))
+	b.txPool.AddLocals(newTxs)
+	waitExtra([]byte("second"))
+}
+
 func TestAdjustIntervalEthash(t *testing.T) {
 	testAdjustInterval(t, ethashChainConfig, ethash.NewFaker())
 }
@@ -561,3 +606,44 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+func TestCommitTransactionsProposalSize(t *testing.T) {
+	txs := make(types.Transactions, 4)
+	var size uint64
+	for i := range txs {
+		txs[i], _ = types.SignTx(types.NewTransaction(uint64(i), testUserAddress, big.NewInt(1000), params.TxGas, new(big.Int).SetUint64(params.InitialBaseFee*2), nil), types.HomesteadSigner{}, testBankKey)
+		size += uint64(txs[i].Size())
+	}
+
+	tests := []struct {
+		name     string
+		budget   uint64
+		included int
+	}{
+		{"just under the limit", size, len(txs)},
+		{"just over the limit", size - 1, len(txs) - 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chainConfig := *ethashChainConfig
+			chainConfig.MaxProposalSize = params.ProposalHeaderReserve + test.budget
+			w, _ := newTestWorker(t, &chainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+			defer w.close()
+
+			env, err := w.prepareWork(&generateParams{timestamp: uint64(time.Now().Unix()), coinbase: testUserAddress})
+			if err != nil {
+				t.Fatalf("failed to prepare work: %v", err)
+			}
+			defer env.discard()
+
+			pending := types.NewTransactionsByPriceAndNonce(env.signer, map[common.Address]types.Transactions{testBankAddress: txs}, env.header.BaseFee)
+			w.commitTransactions(env, pending, nil)
+			if len(env.txs) != test.included {
+				t.Fatalf("included transactions mismatch: have %d, want %d", len(env.txs), test.included)
+			}
+			if env.size > test.budget {
+				t.Fatalf("proposal transactions size %d exceeds the budget %d", env.size, test.budget)
+			}
+		})
+	}
+}
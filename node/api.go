@@ -125,6 +125,32 @@ func (api *privateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// ConsensusBans returns the peers banned from the consensus network for protocol violations.
+func (api *privateAdminAPI) ConsensusBans() ([]*p2p.ConsensusBan, error) {
+	server := api.node.ConsensusServer()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.ConsensusBans(), nil
+}
+
+// UnbanPeer lifts the consensus network ban of a peer, given by node ID or enode URL.
+func (api *privateAdminAPI) UnbanPeer(id string) (bool, error) {
+	server := api.node.ConsensusServer()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	nodeID, err := enode.ParseID(id)
+	if err != nil {
+		node, err := enode.Parse(enode.ValidSchemes, id)
+		if err != nil {
+			return false, fmt.Errorf("invalid node ID or enode: %v", err)
+		}
+		nodeID = node.ID()
+	}
+	return server.UnbanPeer(nodeID), nil
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *privateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/autonity/autonity/crypto/blst"
 
@@ -43,6 +44,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirACNNodeDatabase = "acnnodes"           // Path within the datadir to store the consensus network node infos
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -201,6 +203,38 @@ type Config struct {
 	AllowUnprotectedTxs bool `toml:",omitempty"`
 	NoGossip            bool `toml:",omitempty"`
 	tendermintServices  *interfaces.Services
+
+	// ConsensusTraceSampling is the sampling rate of the consensus message traces, one in
+	// ConsensusTraceSampling messages is traced. Tracing is disabled if zero.
+	ConsensusTraceSampling uint64 `toml:",omitempty"`
+
+	// ConsensusDebug enables the debug RPC calls altering the consensus state, such as
+	// debug_retriggerProposal.
+	ConsensusDebug bool `toml:",omitempty"`
+
+	// ConsensusVoteFairness is the maximum number of proposals dispatched to consensus in a row while
+	// votes are waiting. The default is used if zero.
+	ConsensusVoteFairness uint64 `toml:",omitempty"`
+
+	// ConsensusMessageQueueSize is the maximum number of consensus messages queued by consensus, the
+	// oldest future height messages are dropped first. The default is used if zero.
+	ConsensusMessageQueueSize uint64 `toml:",omitempty"`
+
+	// ConsensusImportPacing is the import backlog, in blocks, above which consensus delays the start of
+	// the next height for the import to catch up. Pacing is disabled if zero.
+	ConsensusImportPacing uint64 `toml:",omitempty"`
+
+	// ConsensusCompressionThreshold is the size in bytes from which the consensus message payloads
+	// are compressed, for the peers supporting it. Compression is disabled if zero.
+	ConsensusCompressionThreshold uint64 `toml:",omitempty"`
+
+	// ConsensusProtocolVersions are the consensus network protocol versions advertised to the
+	// peers, all the supported versions if empty. Each peer is served on the highest common version.
+	ConsensusProtocolVersions []uint `toml:",omitempty"`
+
+	// ConsensusHandshakeTimeout is the deadline of the consensus protocol handshake, the
+	// connections not completing it in time are dropped.
+	ConsensusHandshakeTimeout time.Duration `toml:",omitempty"`
 }
 
 func (c *Config) SetTendermintServices(handler *interfaces.Services) {
@@ -237,6 +271,9 @@ func (c *Config) SetTendermintServices(handler *interfaces.Services) {
 	} else {
 		c.tendermintServices.Gossiper = func(b interfaces.Backend) interfaces.Gossiper { return b.Gossiper() }
 	}
+	// a nil source is replaced by the default one in the backend
+	c.tendermintServices.Rand = handler.Rand
+	c.tendermintServices.ClockSkew = handler.ClockSkew
 }
 
 func (c *Config) TendermintServices() *interfaces.Services {
@@ -276,6 +313,14 @@ func (c *Config) NodeDB() string {
 	return c.ResolvePath(datadirNodeDatabase)
 }
 
+// ConsensusNodeDB returns the path to the consensus network node database, which holds the peer bans.
+func (c *Config) ConsensusNodeDB() string {
+	if c.DataDir == "" {
+		return "" // ephemeral
+	}
+	return c.ResolvePath(datadirACNNodeDatabase)
+}
+
 // DefaultIPCEndpoint returns the IPC path used by default.
 func DefaultIPCEndpoint(clientIdentifier string) string {
 	if clientIdentifier == "" {
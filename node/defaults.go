@@ -22,6 +22,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/autonity/autonity/p2p"
 	"github.com/autonity/autonity/p2p/nat"
@@ -62,6 +63,8 @@ var DefaultConfig = Config{
 		MaxPendingPeers: 100,
 		NAT:             nat.Any(),
 	},
+	ConsensusCompressionThreshold: 4 * 1024,
+	ConsensusHandshakeTimeout:     5 * time.Second,
 }
 
 // DefaultDataDir is the default data directory to use for the databases and other
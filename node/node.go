@@ -144,6 +144,9 @@ func New(conf *Config) (*Node, error) {
 	node.consensusServer.Config.PrivateKey, _ = node.config.AutonityKeys()
 	node.consensusServer.Config.Name = node.config.NodeName()
 	node.consensusServer.Config.Logger = node.log
+	if node.consensusServer.Config.NodeDatabase == "" {
+		node.consensusServer.Config.NodeDatabase = node.config.ConsensusNodeDB()
+	}
 	// Check HTTP/WS prefixes are valid.
 	if err := validatePrefix("HTTP", conf.HTTPPathPrefix); err != nil {
 		return nil, err
@@ -0,0 +1,236 @@
+package p2p
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/p2p/enode"
+	"github.com/autonity/autonity/rlp"
+)
+
+const (
+	// committeeBanCooldown bounds the ban of a member of the current committee. The consensus network
+	// cannot afford to be partitioned from a validator, so a committee member is only refused for a short
+	// time after each offense, doubled with each offense not yet forgiven up to maxCommitteeBanCooldown,
+	// for a misbehaving member not to be redialed in a loop. Its offenses are still recorded and the full
+	// ban applies if it leaves the committee.
+	committeeBanCooldown    = time.Minute
+	maxCommitteeBanCooldown = 16 * time.Minute
+	// banDecayPeriod is the time after which one offense of a peer is forgiven.
+	banDecayPeriod = 24 * time.Hour
+	// maxBanDuration caps the ban duration of repeat offenders.
+	maxBanDuration = 30 * 24 * time.Hour
+)
+
+// BanSeverity grades the consensus protocol violations.
+type BanSeverity uint8
+
+const (
+	BanMinor    BanSeverity = iota // e.g. rate-limit violations
+	BanMajor                       // e.g. accusation spam
+	BanCritical                    // e.g. forged signatures
+)
+
+// banDurations is the ban duration of a first offense, doubled on each repeat offense.
+var banDurations = [...]time.Duration{
+	BanMinor:    10 * time.Minute,
+	BanMajor:    time.Hour,
+	BanCritical: 24 * time.Hour,
+}
+
+var banSeverityToString = [...]string{
+	BanMinor:    "minor",
+	BanMajor:    "major",
+	BanCritical: "critical",
+}
+
+func (s BanSeverity) String() string {
+	if int(s) >= len(banSeverityToString) {
+		return fmt.Sprintf("unknown severity %d", s)
+	}
+	return banSeverityToString[s]
+}
+
+func (s BanSeverity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ConsensusBan is the ban of a peer from the consensus network.
+type ConsensusBan struct {
+	ID          enode.ID    `json:"id"`
+	Reason      string      `json:"reason"`      // reason of the last offense
+	Severity    BanSeverity `json:"severity"`    // highest severity of the offenses covered by the ban
+	Offenses    uint64      `json:"offenses"`    // number of offenses, decays over time
+	LastOffense time.Time   `json:"lastOffense"` // time of the last offense
+	Expiry      time.Time   `json:"expiry"`
+}
+
+// banEntry is the ban representation in the node database.
+type banEntry struct {
+	Reason      string
+	Severity    uint8
+	Offenses    uint64
+	LastOffense uint64 // unix seconds
+	Expiry      uint64 // unix seconds
+}
+
+// decayedOffenses returns the number of offenses still accounted at the given time.
+func (b *ConsensusBan) decayedOffenses(now time.Time) uint64 {
+	forgiven := uint64(now.Sub(b.LastOffense) / banDecayPeriod)
+	if forgiven >= b.Offenses {
+		return 0
+	}
+	return b.Offenses - forgiven
+}
+
+// committeeCooldown returns the time a committee member is refused for after its last offense.
+func (b *ConsensusBan) committeeCooldown(now time.Time) time.Duration {
+	cooldown := committeeBanCooldown
+	for i := uint64(1); i < b.decayedOffenses(now) && cooldown < maxCommitteeBanCooldown; i++ {
+		cooldown *= 2
+	}
+	return min(cooldown, maxCommitteeBanCooldown)
+}
+
+// consensusBans is the ban list of the consensus network. Peers are banned when they are disconnected
+// for a consensus protocol violation, for a duration escalating with their repeat offenses. The bans are
+// persisted in the node database, so that they survive restarts, and expire automatically.
+type consensusBans struct {
+	db   *enode.DB
+	now  func() time.Time
+	log  log.Logger
+	mu   sync.Mutex
+	bans map[enode.ID]*ConsensusBan
+}
+
+func newConsensusBans(db *enode.DB, now func() time.Time, logger log.Logger) *consensusBans {
+	b := &consensusBans{db: db, now: now, log: logger, bans: make(map[enode.ID]*ConsensusBan)}
+	for id, blob := range db.Bans() {
+		var entry banEntry
+		if err := rlp.DecodeBytes(blob, &entry); err != nil {
+			logger.Warn("Dropping invalid consensus ban", "id", id, "err", err)
+			db.DeleteBan(id)
+			continue
+		}
+		b.bans[id] = &ConsensusBan{
+			ID:          id,
+			Reason:      entry.Reason,
+			Severity:    BanSeverity(entry.Severity),
+			Offenses:    entry.Offenses,
+			LastOffense: time.Unix(int64(entry.LastOffense), 0),
+			Expiry:      time.Unix(int64(entry.Expiry), 0),
+		}
+	}
+	b.expire()
+	return b
+}
+
+// record bans a peer for an offense. The ban duration doubles with each offense not yet forgiven.
+func (b *consensusBans) record(id enode.ID, reason string, severity BanSeverity) *ConsensusBan {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// bans are persisted with a precision of a second
+	now := time.Unix(b.now().Unix(), 0)
+	ban := &ConsensusBan{ID: id, Reason: reason, Severity: severity, Offenses: 1, LastOffense: now}
+	if previous, ok := b.bans[id]; ok {
+		ban.Offenses += previous.decayedOffenses(now)
+		if previous.Expiry.After(now) && previous.Severity > severity {
+			ban.Severity = previous.Severity
+		}
+		ban.Expiry = previous.Expiry
+	}
+	duration := banDurations[min(int(severity), len(banDurations)-1)]
+	for i := uint64(1); i < ban.Offenses && duration < maxBanDuration; i++ {
+		duration *= 2
+	}
+	duration = min(duration, maxBanDuration)
+	if expiry := now.Add(duration); expiry.After(ban.Expiry) {
+		ban.Expiry = expiry
+	}
+	b.bans[id] = ban
+	b.store(ban)
+	b.log.Info("Banned consensus peer", "id", id, "reason", reason, "severity", ban.Severity, "offenses", ban.Offenses, "expiry", ban.Expiry)
+	return ban
+}
+
+// banned reports whether the peer is banned. The ban of a committee member only lasts its cooldown.
+func (b *consensusBans) banned(id enode.ID, committee bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ban, ok := b.bans[id]
+	if !ok {
+		return false
+	}
+	now := b.now()
+	if committee && !now.Before(ban.LastOffense.Add(ban.committeeCooldown(now))) {
+		return false
+	}
+	return now.Before(ban.Expiry)
+}
+
+// list returns the active bans, sorted by node ID.
+func (b *consensusBans) list() []*ConsensusBan {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.expire()
+	now := b.now()
+	bans := make([]*ConsensusBan, 0, len(b.bans))
+	for _, ban := range b.bans {
+		if now.Before(ban.Expiry) {
+			banCopy := *ban
+			bans = append(bans, &banCopy)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bytes.Compare(bans[i].ID[:], bans[j].ID[:]) < 0
+	})
+	return bans
+}
+
+// unban lifts the ban of a peer and forgives its offenses.
+func (b *consensusBans) unban(id enode.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.bans[id]; !ok {
+		return false
+	}
+	delete(b.bans, id)
+	b.db.DeleteBan(id)
+	return true
+}
+
+// expire drops the bans which are over and whose offenses have all been forgiven.
+func (b *consensusBans) expire() {
+	now := b.now()
+	for id, ban := range b.bans {
+		if !now.Before(ban.Expiry) && ban.decayedOffenses(now) == 0 {
+			delete(b.bans, id)
+			b.db.DeleteBan(id)
+		}
+	}
+}
+
+func (b *consensusBans) store(ban *ConsensusBan) {
+	blob, err := rlp.EncodeToBytes(&banEntry{
+		Reason:      ban.Reason,
+		Severity:    uint8(ban.Severity),
+		Offenses:    ban.Offenses,
+		LastOffense: uint64(ban.LastOffense.Unix()),
+		Expiry:      uint64(ban.Expiry.Unix()),
+	})
+	if err != nil {
+		b.log.Error("Failed to encode consensus ban", "id", ban.ID, "err", err)
+		return
+	}
+	if err := b.db.UpdateBan(ban.ID, blob); err != nil {
+		b.log.Error("Failed to store consensus ban", "id", ban.ID, "err", err)
+	}
+}
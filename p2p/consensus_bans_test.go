@@ -0,0 +1,168 @@
+package p2p
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// testBanClock is a settable clock for the ban list.
+type testBanClock struct {
+	now time.Time
+}
+
+func (c *testBanClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testBanClock) Run(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestConsensusBans(t *testing.T, db *enode.DB) (*consensusBans, *testBanClock) {
+	if db == nil {
+		var err error
+		db, err = enode.OpenDB("")
+		require.NoError(t, err)
+		t.Cleanup(db.Close)
+	}
+	clock := &testBanClock{now: time.Unix(1700000000, 0)}
+	return newConsensusBans(db, clock.Now, log.Root()), clock
+}
+
+func TestConsensusBans(t *testing.T) {
+	id := enode.ID{1}
+
+	t.Run("bans expire automatically", func(t *testing.T) {
+		bans, clock := newTestConsensusBans(t, nil)
+		bans.record(id, "rate limit", BanMinor)
+		require.True(t, bans.banned(id, false))
+		require.Len(t, bans.list(), 1)
+
+		clock.Run(banDurations[BanMinor] - time.Second)
+		require.True(t, bans.banned(id, false))
+		clock.Run(time.Second)
+		require.False(t, bans.banned(id, false))
+		require.Empty(t, bans.list())
+
+		// the entry is dropped once its offense is forgiven
+		require.NotNil(t, bans.db.Ban(id))
+		clock.Run(banDecayPeriod)
+		bans.list()
+		require.Nil(t, bans.db.Ban(id))
+	})
+
+	t.Run("repeat offenses escalate the ban", func(t *testing.T) {
+		bans, clock := newTestConsensusBans(t, nil)
+		for offense := 1; offense <= 3; offense++ {
+			ban := bans.record(id, "accusation spam", BanMajor)
+			duration := banDurations[BanMajor] << (offense - 1)
+			require.Equal(t, uint64(offense), ban.Offenses)
+			require.Equal(t, clock.Now().Add(duration), ban.Expiry)
+			clock.Run(duration)
+			require.False(t, bans.banned(id, false))
+		}
+	})
+
+	t.Run("escalation is capped", func(t *testing.T) {
+		bans, clock := newTestConsensusBans(t, nil)
+		var ban *ConsensusBan
+		for i := 0; i < 20; i++ {
+			ban = bans.record(id, "bad signature", BanCritical)
+		}
+		require.Equal(t, clock.Now().Add(maxBanDuration), ban.Expiry)
+	})
+
+	t.Run("offenses decay", func(t *testing.T) {
+		bans, clock := newTestConsensusBans(t, nil)
+		bans.record(id, "rate limit", BanMinor)
+		bans.record(id, "rate limit", BanMinor)
+		clock.Run(banDecayPeriod)
+		ban := bans.record(id, "rate limit", BanMinor)
+		require.Equal(t, uint64(2), ban.Offenses)
+		require.Equal(t, clock.Now().Add(2*banDurations[BanMinor]), ban.Expiry)
+
+		clock.Run(2 * banDecayPeriod)
+		ban = bans.record(id, "rate limit", BanMinor)
+		require.Equal(t, uint64(1), ban.Offenses)
+	})
+
+	t.Run("a minor offense does not shorten a ban", func(t *testing.T) {
+		bans, clock := newTestConsensusBans(t, nil)
+		critical := bans.record(id, "bad signature", BanCritical)
+		clock.Run(time.Minute)
+		ban := bans.record(id, "rate limit", BanMinor)
+		require.Equal(t, critical.Expiry, ban.Expiry)
+		require.Equal(t, BanCritical, ban.Severity)
+		require.Equal(t, "rate limit", ban.Reason)
+	})
+
+	t.Run("committee members are only banned for the cooldown", func(t *testing.T) {
+		bans, clock := newTestConsensusBans(t, nil)
+		bans.record(id, "bad signature", BanCritical)
+		require.True(t, bans.banned(id, true))
+		clock.Run(committeeBanCooldown)
+		require.False(t, bans.banned(id, true))
+		// the ban still applies if the node leaves the committee
+		require.True(t, bans.banned(id, false))
+
+		// a new offense starts a new cooldown
+		bans.record(id, "bad signature", BanCritical)
+		require.True(t, bans.banned(id, true))
+	})
+
+	t.Run("repeat offenses escalate the committee cooldown", func(t *testing.T) {
+		bans, clock := newTestConsensusBans(t, nil)
+		for offense := 1; offense <= 6; offense++ {
+			bans.record(id, "accusation spam", BanMajor)
+			cooldown := min(committeeBanCooldown<<(offense-1), maxCommitteeBanCooldown)
+			clock.Run(cooldown - time.Second)
+			require.True(t, bans.banned(id, true), "offense %d", offense)
+			clock.Run(time.Second)
+			require.False(t, bans.banned(id, true), "offense %d", offense)
+		}
+	})
+
+	t.Run("unban", func(t *testing.T) {
+		bans, _ := newTestConsensusBans(t, nil)
+		require.False(t, bans.unban(id))
+		bans.record(id, "bad signature", BanCritical)
+		require.True(t, bans.unban(id))
+		require.False(t, bans.banned(id, false))
+		require.Nil(t, bans.db.Ban(id))
+
+		// the offenses are forgiven
+		ban := bans.record(id, "bad signature", BanCritical)
+		require.Equal(t, uint64(1), ban.Offenses)
+	})
+
+	t.Run("bans persist across restarts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nodes")
+		db, err := enode.OpenDB(path)
+		require.NoError(t, err)
+		bans, clock := newTestConsensusBans(t, db)
+		bans.record(id, "bad signature", BanCritical)
+		bans.record(id, "bad signature", BanCritical)
+		bans.record(enode.ID{2}, "rate limit", BanMinor)
+		want := bans.list()
+		db.Close()
+
+		db, err = enode.OpenDB(path)
+		require.NoError(t, err)
+		defer db.Close()
+		restored := newConsensusBans(db, clock.Now, log.Root())
+		require.Equal(t, want, restored.list())
+		require.True(t, restored.banned(id, false))
+
+		// the expired bans are dropped on load
+		clock.Run(banDurations[BanMinor] + banDecayPeriod)
+		restored = newConsensusBans(db, clock.Now, log.Root())
+		require.Len(t, restored.list(), 1)
+		require.Nil(t, db.Ban(enode.ID{2}))
+	})
+}
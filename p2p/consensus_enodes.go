@@ -0,0 +1,151 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/mclock"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/metrics"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// consensusEnodesUpdateInterval is the minimum time between two consensus enodes updates.
+const consensusEnodesUpdateInterval = time.Second
+
+var (
+	consensusEnodesAppliedMeter = metrics.NewRegisteredMeter("p2p/consensus/enodes/applied", nil)
+	consensusEnodesSkippedMeter = metrics.NewRegisteredMeter("p2p/consensus/enodes/skipped", nil)
+)
+
+// ConsensusEnodesServer is the p2p server method driven by the ConsensusEnodesUpdater.
+type ConsensusEnodesServer interface {
+	UpdateConsensusEnodes(newCommitteeSubset []*enode.Node, newCommittee []*enode.Node)
+}
+
+// ConsensusEnodesRecorder is notified of the consensus enodes updates applied to the p2p server.
+// It is called with the updater lock held and must not block.
+type ConsensusEnodesRecorder interface {
+	RecordConsensusEnodes(subset, committee []*enode.Node)
+}
+
+// ConsensusEnodesUpdater sits between the chain head watchers and the p2p server. It skips the
+// updates which do not change the committee enodes, and applies the others at most once per
+// update interval: an update arriving too early is delayed and superseded by any later one.
+type ConsensusEnodesUpdater struct {
+	server   ConsensusEnodesServer
+	clock    mclock.Clock
+	interval time.Duration
+	recorder ConsensusEnodesRecorder
+
+	mu          sync.Mutex
+	applied     common.Hash // digest of the last applied update
+	lastApplied mclock.AbsTime
+	initialized bool
+	pending     *consensusEnodesUpdate
+	timer       mclock.Timer
+	stopped     bool
+}
+
+type consensusEnodesUpdate struct {
+	subset, committee []*enode.Node
+	digest            common.Hash
+}
+
+// NewConsensusEnodesUpdater creates an updater forwarding to server.
+func NewConsensusEnodesUpdater(server ConsensusEnodesServer) *ConsensusEnodesUpdater {
+	return newConsensusEnodesUpdater(server, mclock.System{}, consensusEnodesUpdateInterval)
+}
+
+func newConsensusEnodesUpdater(server ConsensusEnodesServer, clock mclock.Clock, interval time.Duration) *ConsensusEnodesUpdater {
+	return &ConsensusEnodesUpdater{server: server, clock: clock, interval: interval}
+}
+
+// SetRecorder sets the recorder of the applied updates.
+func (u *ConsensusEnodesUpdater) SetRecorder(recorder ConsensusEnodesRecorder) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.recorder = recorder
+}
+
+// Update hands the committee enodes and the subset to connect to over to the p2p server,
+// unless they are the same as the last ones handed over.
+func (u *ConsensusEnodesUpdater) Update(subset, committee []*enode.Node) {
+	update := &consensusEnodesUpdate{subset: subset, committee: committee, digest: consensusEnodesDigest(subset, committee)}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.stopped {
+		return
+	}
+	if u.pending != nil {
+		// the delayed update is superseded
+		consensusEnodesSkippedMeter.Mark(1)
+		u.pending = nil
+	}
+	if u.initialized && update.digest == u.applied {
+		consensusEnodesSkippedMeter.Mark(1)
+		return
+	}
+	if wait := u.interval - time.Duration(u.clock.Now()-u.lastApplied); u.initialized && wait > 0 {
+		u.pending = update
+		if u.timer == nil {
+			u.timer = u.clock.AfterFunc(wait, u.flush)
+		}
+		return
+	}
+	u.apply(update)
+}
+
+// Stop drops the delayed update, if any, and makes any further update a no-op.
+func (u *ConsensusEnodesUpdater) Stop() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.stopped = true
+	u.pending = nil
+	if u.timer != nil {
+		u.timer.Stop()
+		u.timer = nil
+	}
+}
+
+func (u *ConsensusEnodesUpdater) flush() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.timer = nil
+	if u.pending == nil || u.stopped {
+		return
+	}
+	update := u.pending
+	u.pending = nil
+	u.apply(update)
+}
+
+// apply must be called with the lock held, so that the updates reach the server in order.
+func (u *ConsensusEnodesUpdater) apply(update *consensusEnodesUpdate) {
+	u.server.UpdateConsensusEnodes(update.subset, update.committee)
+	if u.recorder != nil {
+		u.recorder.RecordConsensusEnodes(update.subset, update.committee)
+	}
+	u.applied = update.digest
+	u.lastApplied = u.clock.Now()
+	u.initialized = true
+	consensusEnodesAppliedMeter.Mark(1)
+}
+
+// consensusEnodesDigest hashes the enodes URLs of both lists, a change of endpoint is a change.
+func consensusEnodesDigest(subset, committee []*enode.Node) common.Hash {
+	hasher := crypto.NewKeccakState()
+	for _, list := range [][]*enode.Node{subset, committee} {
+		for _, node := range list {
+			hasher.Write([]byte(node.URLv4()))
+			hasher.Write([]byte{0})
+		}
+		// list separator, so that moving a node from one list to the other changes the digest
+		hasher.Write([]byte{1})
+	}
+	var digest common.Hash
+	hasher.Read(digest[:])
+	return digest
+}
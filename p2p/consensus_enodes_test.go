@@ -0,0 +1,119 @@
+package p2p
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common/mclock"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+type consensusEnodesCall struct {
+	subset, committee []*enode.Node
+}
+
+// recordingEnodesServer records the consensus enodes updates it receives.
+type recordingEnodesServer struct {
+	mu    sync.Mutex
+	calls []consensusEnodesCall
+}
+
+func (s *recordingEnodesServer) UpdateConsensusEnodes(subset []*enode.Node, committee []*enode.Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, consensusEnodesCall{subset: subset, committee: committee})
+}
+
+func (s *recordingEnodesServer) Calls() []consensusEnodesCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]consensusEnodesCall(nil), s.calls...)
+}
+
+func newCommitteeEnodes(n int) []*enode.Node {
+	nodes := make([]*enode.Node, n)
+	for i := range nodes {
+		nodes[i] = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, byte(i + 1)}, 30303, 30303)
+	}
+	return nodes
+}
+
+func TestConsensusEnodesUpdater(t *testing.T) {
+	committee := newCommitteeEnodes(4)
+
+	t.Run("identical lists across heads are applied once", func(t *testing.T) {
+		server := new(recordingEnodesServer)
+		clock := new(mclock.Simulated)
+		updater := newConsensusEnodesUpdater(server, clock, time.Second)
+
+		for head := 0; head < 10; head++ {
+			// a fresh copy of the same lists at each head, as parsed from the contract
+			updater.Update(append([]*enode.Node(nil), committee[:2]...), append([]*enode.Node(nil), committee...))
+			clock.Run(2 * time.Second)
+		}
+		require.Len(t, server.Calls(), 1)
+	})
+
+	t.Run("differing lists are applied", func(t *testing.T) {
+		server := new(recordingEnodesServer)
+		clock := new(mclock.Simulated)
+		updater := newConsensusEnodesUpdater(server, clock, time.Second)
+
+		updater.Update(committee[:2], committee)
+		clock.Run(2 * time.Second)
+		// same committee, different subset
+		updater.Update(committee[1:3], committee)
+		clock.Run(2 * time.Second)
+		// new endpoint for a committee member
+		moved := append([]*enode.Node(nil), committee...)
+		moved[3] = enode.NewV4(committee[3].Pubkey(), net.IP{10, 0, 0, 4}, 30303, 30303)
+		updater.Update(committee[1:3], moved)
+		clock.Run(2 * time.Second)
+		updater.Update(nil, nil)
+
+		calls := server.Calls()
+		require.Len(t, calls, 4)
+		require.Equal(t, committee[1:3], calls[1].subset)
+		require.Equal(t, moved, calls[2].committee)
+		require.Nil(t, calls[3].committee)
+	})
+
+	t.Run("bursts are rate limited to the latest update", func(t *testing.T) {
+		server := new(recordingEnodesServer)
+		clock := new(mclock.Simulated)
+		updater := newConsensusEnodesUpdater(server, clock, time.Second)
+
+		updater.Update(committee[:1], committee)
+		updater.Update(committee[:2], committee)
+		updater.Update(committee[:3], committee)
+		require.Len(t, server.Calls(), 1)
+
+		clock.Run(time.Second)
+		calls := server.Calls()
+		require.Len(t, calls, 2)
+		require.Equal(t, committee[:3], calls[1].subset)
+
+		// a burst reverting to the applied lists is dropped entirely
+		updater.Update(committee[:1], committee)
+		updater.Update(committee[:3], committee)
+		clock.Run(time.Second)
+		require.Len(t, server.Calls(), 2)
+	})
+
+	t.Run("stop drops the delayed update", func(t *testing.T) {
+		server := new(recordingEnodesServer)
+		clock := new(mclock.Simulated)
+		updater := newConsensusEnodesUpdater(server, clock, time.Second)
+
+		updater.Update(committee[:1], committee)
+		updater.Update(committee[:2], committee)
+		updater.Stop()
+		clock.Run(time.Second)
+		updater.Update(committee[:3], committee)
+		require.Len(t, server.Calls(), 1)
+	})
+}
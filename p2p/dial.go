@@ -79,6 +79,7 @@ var (
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNetRestrict      = errors.New("not contained in netrestrict list")
 	errNoPort           = errors.New("node does not provide TCP port")
+	errBanned           = errors.New("node is banned")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -140,6 +141,7 @@ type dialConfig struct {
 	rand           *mrand.Rand
 	trusted        *sync.Map
 	net            Network
+	banned         func(enode.ID) bool // reports whether a node is banned, disabled if nil
 }
 
 func (cfg dialConfig) withDefaults() dialConfig {
@@ -551,6 +553,11 @@ func (t *dialTask) resolve(d *dialScheduler) bool {
 
 // dial performs the actual connection attempt.
 func (t *dialTask) dial(d *dialScheduler, dest *enode.Node) error {
+	// the ban is checked at dial time, a static node is then retried once its dial history expires
+	if d.banned != nil && d.banned(dest.ID()) {
+		d.log.Trace("Skipping dial to banned node", "id", dest.ID(), "conn", t.flags)
+		return errBanned
+	}
 	fd, err := d.dialer.Dial(d.ctx, t.dest)
 	if err != nil {
 		d.log.Trace("Dial error", "id", t.dest.ID(), "addr", nodeAddr(t.dest), "conn", t.flags, "err", cleanupDialErr(err))
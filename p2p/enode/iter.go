@@ -123,6 +123,53 @@ func (f *filterIter) Next() bool {
 	return false
 }
 
+// Prefer wraps an iterator such that the nodes for which the 'check' function returns
+// false are deprioritized rather than dropped: they are held back, up to 'backlog' of
+// them, and returned once the backlog is full or once the wrapped iterator ended.
+func Prefer(it Iterator, check func(*Node) bool, backlog int) Iterator {
+	return &preferIter{it: it, check: check, backlog: max(backlog, 1)}
+}
+
+type preferIter struct {
+	it       Iterator
+	check    func(*Node) bool
+	backlog  int
+	deferred []*Node
+	cur      *Node
+	ended    bool
+}
+
+func (p *preferIter) Next() bool {
+	for !p.ended && len(p.deferred) < p.backlog {
+		if !p.it.Next() {
+			p.ended = true
+			break
+		}
+		n := p.it.Node()
+		if p.check(n) {
+			p.cur = n
+			return true
+		}
+		p.deferred = append(p.deferred, n)
+	}
+	if len(p.deferred) == 0 {
+		p.cur = nil
+		return false
+	}
+	p.cur = p.deferred[0]
+	p.deferred[0] = nil
+	p.deferred = p.deferred[1:]
+	return true
+}
+
+func (p *preferIter) Node() *Node {
+	return p.cur
+}
+
+func (p *preferIter) Close() {
+	p.it.Close()
+}
+
 // FairMix aggregates multiple node iterators. The mixer itself is an iterator which ends
 // only when Close is called. Source iterators added via AddSource are removed from the
 // mix when they end.
@@ -70,6 +70,29 @@ func TestFilterNodes(t *testing.T) {
 	}
 }
 
+func TestPreferNodes(t *testing.T) {
+	nodes := make([]*Node, 10)
+	for i := range nodes {
+		nodes[i] = testNode(uint64(i), uint64(i))
+	}
+
+	// the odd nodes are held back until two of them are waiting, or the iterator ended
+	it := Prefer(IterNodes(nodes), func(n *Node) bool {
+		return n.Seq()%2 == 0
+	}, 2)
+	for _, i := range []int{0, 2, 1, 4, 3, 6, 5, 8, 7, 9} {
+		if !it.Next() {
+			t.Fatal("Next returned false")
+		}
+		if it.Node() != nodes[i] {
+			t.Fatalf("iterator returned node %d, want %d", it.Node().Seq(), i)
+		}
+	}
+	if it.Next() {
+		t.Fatal("Next returned true after underlying iterator has ended")
+	}
+}
+
 func checkNodes(t *testing.T, nodes []*Node, wantLen int) {
 	if len(nodes) != wantLen {
 		t.Errorf("slice has %d nodes, want %d", len(nodes), wantLen)
@@ -26,6 +26,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/autonity/autonity/common"
 	"github.com/autonity/autonity/rlp"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
@@ -40,6 +41,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbBanPrefix    = "ban:" // Identifier to prefix consensus ban entries with, the full key is "ban:<ID>"
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -442,6 +444,47 @@ func (db *DB) storeLocalSeq(id ID, n uint64) {
 	db.storeUint64(localItemKey(id, dbLocalSeq), n)
 }
 
+// banKey returns the database key for the ban entry of a node.
+func banKey(id ID) []byte {
+	return append([]byte(dbBanPrefix), id[:]...)
+}
+
+// Ban retrieves the encoded ban entry of a node, nil if the node is not banned.
+func (db *DB) Ban(id ID) []byte {
+	blob, err := db.lvl.Get(banKey(id), nil)
+	if err != nil {
+		return nil
+	}
+	return blob
+}
+
+// UpdateBan stores the encoded ban entry of a node.
+func (db *DB) UpdateBan(id ID, blob []byte) error {
+	return db.lvl.Put(banKey(id), blob, nil)
+}
+
+// DeleteBan deletes the ban entry of a node.
+func (db *DB) DeleteBan(id ID) {
+	db.lvl.Delete(banKey(id), nil)
+}
+
+// Bans retrieves all the encoded ban entries.
+func (db *DB) Bans() map[ID][]byte {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbBanPrefix)), nil)
+	defer it.Release()
+
+	bans := make(map[ID][]byte)
+	for it.Next() {
+		var id ID
+		if len(it.Key()) != len(dbBanPrefix)+len(id) {
+			continue
+		}
+		copy(id[:], it.Key()[len(dbBanPrefix):])
+		bans[id] = common.CopyBytes(it.Value())
+	}
+	return bans
+}
+
 // QuerySeeds retrieves random nodes to be used as potential seed nodes
 // for bootstrapping.
 func (db *DB) QuerySeeds(n int, maxAge time.Duration) []*Node {
@@ -102,6 +102,23 @@ func ParseACNV4(rawurl string) (*Node, error) {
 	return parseComplete(rawurl, V4ResolveFunc, acnProtoParams)
 }
 
+// ParseV4WithResolver parses a node URL like ParseV4, resolving the host of the node with resolve.
+func ParseV4WithResolver(rawurl string, resolve func(host string) ([]net.IP, error)) (*Node, error) {
+	if m := incompleteNodeURL.FindStringSubmatch(rawurl); m != nil {
+		id, err := parsePubkey(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w (%v)", ErrInvalidPublicKey, err)
+		}
+		return NewV4(id, nil, 0, 0), nil
+	}
+	return parseCompleteWithResolver(rawurl, resolve, ethProtoParams)
+}
+
+// ParseACNV4WithResolver parses a node URL like ParseACNV4, resolving the host of the node with resolve.
+func ParseACNV4WithResolver(rawurl string, resolve func(host string) ([]net.IP, error)) (*Node, error) {
+	return parseCompleteWithResolver(rawurl, resolve, acnProtoParams)
+}
+
 // ParseV4NoResolve returns a node object without attempting to resolve. Useful to manipulate
 // a enode string.
 func ParseV4NoResolve(rawurl string) (*Node, error) {
@@ -226,7 +243,14 @@ func acnProtoParams(u *url.URL) (string, uint64, uint64, error) {
 	return acnIP, acnPort, 0, nil
 }
 
+// parseComplete parses a complete node URL, resolving its host with V4ResolveFunc whatever the
+// resolver given. The enode check precompile depends on this behaviour, it must not change.
 func parseComplete(rawurl string, _ func(host string) ([]net.IP, error),
+	protoParser func(u *url.URL) (string, uint64, uint64, error)) (*Node, error) {
+	return parseCompleteWithResolver(rawurl, V4ResolveFunc, protoParser)
+}
+
+func parseCompleteWithResolver(rawurl string, resolve func(host string) ([]net.IP, error),
 	protoParser func(u *url.URL) (string, uint64, uint64, error)) (*Node, error) {
 	var (
 		id *ecdsa.PublicKey
@@ -254,7 +278,7 @@ func parseComplete(rawurl string, _ func(host string) ([]net.IP, error),
 
 	// host is not an ip address
 	if ip = net.ParseIP(host); ip == nil {
-		return NewV4WithHost(id, host, int(tcpPort), int(udpPort), V4ResolveFunc)
+		return NewV4WithHost(id, host, int(tcpPort), int(udpPort), resolve)
 	}
 	return NewV4(id, ip, int(tcpPort), int(udpPort)), nil
 }
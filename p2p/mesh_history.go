@@ -0,0 +1,243 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/log"
+	"github.com/autonity/autonity/metrics"
+	"github.com/autonity/autonity/p2p/enode"
+	"github.com/autonity/autonity/rlp"
+)
+
+// meshHistoryQueueSize is the number of mesh history entries waiting to be written, further entries are dropped.
+const meshHistoryQueueSize = 64
+
+var meshHistoryDroppedMeter = metrics.NewRegisteredMeter("p2p/meshhistory/dropped", nil)
+
+// MeshMembership is the consensus mesh membership of a p2p server after a consensus enodes update.
+type MeshMembership struct {
+	Block         uint64      `json:"block"` // head block when the update was applied
+	Time          time.Time   `json:"time"`
+	Network       string      `json:"network"`
+	CommitteeHash common.Hash `json:"committeeHash"` // zero if the local node is not in the committee
+	Selected      []enode.ID  `json:"selected"`      // subset of the committee selected by the topology
+	Connected     []enode.ID  `json:"connected"`     // committee members connected right after the update
+}
+
+// meshHistoryEntry is the mesh membership representation in the database.
+type meshHistoryEntry struct {
+	Block         uint64
+	Time          uint64 // unix milliseconds
+	Network       string
+	CommitteeHash common.Hash
+	Selected      []enode.ID
+	Connected     []enode.ID
+}
+
+// meshHistoryRecord is a recorded update waiting to be written.
+type meshHistoryRecord struct {
+	entry     *meshHistoryEntry
+	committee []*enode.Node
+	connected func() []enode.ID
+}
+
+// MeshHistory is a capped log of the consensus mesh memberships of the node, kept in the chain database
+// for post-incident forensics. An entry is recorded for each consensus enodes update applied to a p2p
+// server, the oldest entries are pruned once the cap is reached. The entries are written by a background
+// routine so that recording an update never blocks the caller.
+type MeshHistory struct {
+	db         ethdb.KeyValueStore
+	maxEntries uint64
+	queue      chan *meshHistoryRecord
+	quit       chan struct{}
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+	log        log.Logger
+
+	mu sync.Mutex // serializes the accesses to the range of the entries
+}
+
+// NewMeshHistory creates a mesh history keeping up to maxEntries entries in db. It returns nil,
+// which records nothing, if maxEntries is zero.
+func NewMeshHistory(db ethdb.KeyValueStore, maxEntries uint64) *MeshHistory {
+	if maxEntries == 0 {
+		return nil
+	}
+	h := &MeshHistory{
+		db:         db,
+		maxEntries: maxEntries,
+		queue:      make(chan *meshHistoryRecord, meshHistoryQueueSize),
+		quit:       make(chan struct{}),
+		log:        log.New("module", "meshhistory"),
+	}
+	h.wg.Add(1)
+	go h.loop()
+	return h
+}
+
+// Recorder returns the recorder of the consensus enodes updates applied to the server.
+func (h *MeshHistory) Recorder(srv *Server) ConsensusEnodesRecorder {
+	if h == nil {
+		return nil
+	}
+	return &meshRecorder{
+		history: h,
+		network: srv.Net.String(),
+		block:   srv.currentBlock.Load,
+		connected: func() []enode.ID {
+			var ids []enode.ID
+			for _, p := range srv.Peers() {
+				ids = append(ids, p.ID())
+			}
+			return ids
+		},
+	}
+}
+
+// Entries returns the mesh memberships applied in [from, to], preceded for each network by the
+// membership in force at from, if any.
+func (h *MeshHistory) Entries(from, to uint64) []*MeshMembership {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var (
+		entries []*MeshMembership
+		prior   = make(map[string]*MeshMembership)
+		order   []string // networks with a prior membership, in order of appearance
+	)
+	tail, head := rawdb.ReadMeshHistoryRange(h.db)
+	for seq := tail; seq < head; seq++ {
+		var entry meshHistoryEntry
+		if err := rlp.DecodeBytes(rawdb.ReadMeshHistoryEntry(h.db, seq), &entry); err != nil {
+			h.log.Warn("Skipping invalid mesh history entry", "seq", seq, "err", err)
+			continue
+		}
+		switch {
+		case entry.Block < from:
+			if prior[entry.Network] == nil {
+				order = append(order, entry.Network)
+			}
+			prior[entry.Network] = newMeshMembership(&entry)
+		case entry.Block <= to:
+			entries = append(entries, newMeshMembership(&entry))
+		}
+	}
+	priors := make([]*MeshMembership, 0, len(order))
+	for _, network := range order {
+		priors = append(priors, prior[network])
+	}
+	return append(priors, entries...)
+}
+
+// Close writes the pending entries and stops the background routine.
+func (h *MeshHistory) Close() {
+	if h == nil {
+		return
+	}
+	h.closeOnce.Do(func() {
+		close(h.quit)
+		h.wg.Wait()
+	})
+}
+
+func (h *MeshHistory) loop() {
+	defer h.wg.Done()
+	for {
+		select {
+		case record := <-h.queue:
+			h.write(record)
+		case <-h.quit:
+			for {
+				select {
+				case record := <-h.queue:
+					h.write(record)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *MeshHistory) write(record *meshHistoryRecord) {
+	entry := record.entry
+	if len(record.committee) > 0 {
+		members := make(map[enode.ID]struct{}, len(record.committee))
+		for _, node := range record.committee {
+			members[node.ID()] = struct{}{}
+		}
+		for _, id := range record.connected() {
+			if _, ok := members[id]; ok {
+				entry.Connected = append(entry.Connected, id)
+			}
+		}
+	}
+	blob, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		h.log.Error("Failed to encode mesh history entry", "err", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tail, head := rawdb.ReadMeshHistoryRange(h.db)
+	batch := h.db.NewBatch()
+	rawdb.WriteMeshHistoryEntry(batch, head, blob)
+	head++
+	for ; head-tail > h.maxEntries; tail++ {
+		rawdb.DeleteMeshHistoryEntry(batch, tail)
+	}
+	rawdb.WriteMeshHistoryRange(batch, tail, head)
+	if err := batch.Write(); err != nil {
+		h.log.Error("Failed to write mesh history entry", "err", err)
+	}
+}
+
+// meshRecorder records the consensus enodes updates applied to a p2p server into the mesh history.
+type meshRecorder struct {
+	history   *MeshHistory
+	network   string
+	block     func() uint64
+	connected func() []enode.ID
+}
+
+func (r *meshRecorder) RecordConsensusEnodes(subset, committee []*enode.Node) {
+	entry := &meshHistoryEntry{
+		Block:    r.block(),
+		Time:     uint64(time.Now().UnixMilli()),
+		Network:  r.network,
+		Selected: make([]enode.ID, 0, len(subset)),
+	}
+	for _, node := range subset {
+		entry.Selected = append(entry.Selected, node.ID())
+	}
+	if len(committee) > 0 {
+		ids := make([]byte, 0, len(committee)*len(enode.ID{}))
+		for _, node := range committee {
+			id := node.ID()
+			ids = append(ids, id[:]...)
+		}
+		entry.CommitteeHash = crypto.Keccak256Hash(ids)
+	}
+	select {
+	case r.history.queue <- &meshHistoryRecord{entry: entry, committee: committee, connected: r.connected}:
+	default:
+		meshHistoryDroppedMeter.Mark(1)
+	}
+}
+
+func newMeshMembership(entry *meshHistoryEntry) *MeshMembership {
+	return &MeshMembership{
+		Block:         entry.Block,
+		Time:          time.UnixMilli(int64(entry.Time)),
+		Network:       entry.Network,
+		CommitteeHash: entry.CommitteeHash,
+		Selected:      entry.Selected,
+		Connected:     entry.Connected,
+	}
+}
@@ -0,0 +1,179 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/common/mclock"
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/crypto"
+	"github.com/autonity/autonity/ethdb"
+	"github.com/autonity/autonity/p2p/enode"
+)
+
+// newTestMeshRecorder returns a recorder of the given network whose head block and connected peers are set by the test.
+func newTestMeshRecorder(history *MeshHistory, network string, block *uint64, connected *[]enode.ID) *meshRecorder {
+	return &meshRecorder{
+		history:   history,
+		network:   network,
+		block:     func() uint64 { return *block },
+		connected: func() []enode.ID { return *connected },
+	}
+}
+
+func enodeIDs(nodes []*enode.Node) []enode.ID {
+	ids := make([]enode.ID, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.ID())
+	}
+	return ids
+}
+
+// waitMeshEntries waits for the background routine to write the n-th entry, the connected peers being read then.
+func waitMeshEntries(t *testing.T, db ethdb.KeyValueReader, n uint64) {
+	require.Eventually(t, func() bool {
+		_, head := rawdb.ReadMeshHistoryRange(db)
+		return head == n
+	}, time.Second, time.Millisecond)
+}
+
+func meshBlocks(entries []*MeshMembership) []uint64 {
+	var blocks []uint64
+	for _, entry := range entries {
+		blocks = append(blocks, entry.Block)
+	}
+	return blocks
+}
+
+func TestMeshHistory(t *testing.T) {
+	var (
+		committee = newCommitteeEnodes(5)
+		outsider  = newCommitteeEnodes(1)[0]
+		db        = rawdb.NewMemoryDatabase()
+		history   = NewMeshHistory(db, 100)
+		clock     = new(mclock.Simulated)
+		block     uint64
+		connected []enode.ID
+	)
+	updater := newConsensusEnodesUpdater(new(recordingEnodesServer), clock, time.Second)
+	updater.SetRecorder(newTestMeshRecorder(history, "execution", &block, &connected))
+
+	block, connected = 10, enodeIDs([]*enode.Node{committee[0], committee[1], outsider})
+	updater.Update(committee[:2], committee[:4])
+	waitMeshEntries(t, db, 1)
+	clock.Run(2 * time.Second)
+	// a committee change, committee[0] leaves and committee[4] joins
+	block, connected = 20, enodeIDs(committee[1:3])
+	updater.Update(committee[1:3], committee[1:5])
+	waitMeshEntries(t, db, 2)
+	clock.Run(2 * time.Second)
+	// the local node leaves the committee
+	block, connected = 30, enodeIDs([]*enode.Node{outsider})
+	updater.Update(nil, nil)
+	history.Close()
+
+	t.Run("the memberships are recorded in order", func(t *testing.T) {
+		entries := history.Entries(0, 100)
+		require.Equal(t, []uint64{10, 20, 30}, meshBlocks(entries))
+
+		require.Equal(t, "execution", entries[0].Network)
+		require.Equal(t, enodeIDs(committee[:2]), entries[0].Selected)
+		// the connected peers out of the committee are left out
+		require.Equal(t, enodeIDs(committee[:2]), entries[0].Connected)
+		require.Equal(t, crypto.Keccak256Hash(concatIDs(committee[:4])), entries[0].CommitteeHash)
+
+		require.Equal(t, enodeIDs(committee[1:3]), entries[1].Selected)
+		require.Equal(t, enodeIDs(committee[1:3]), entries[1].Connected)
+		require.Equal(t, crypto.Keccak256Hash(concatIDs(committee[1:5])), entries[1].CommitteeHash)
+
+		require.Empty(t, entries[2].Selected)
+		require.Empty(t, entries[2].Connected)
+		require.Equal(t, common.Hash{}, entries[2].CommitteeHash)
+	})
+
+	t.Run("the membership in force at the start of the range is included", func(t *testing.T) {
+		entries := history.Entries(25, 35)
+		require.Equal(t, []uint64{20, 30}, meshBlocks(entries))
+		require.Equal(t, []uint64{20}, meshBlocks(history.Entries(21, 29)))
+		require.Equal(t, []uint64{10, 20}, meshBlocks(history.Entries(15, 20)))
+		require.Empty(t, history.Entries(0, 5))
+	})
+}
+
+func TestMeshHistoryNetworks(t *testing.T) {
+	var (
+		committee = newCommitteeEnodes(3)
+		history   = NewMeshHistory(rawdb.NewMemoryDatabase(), 100)
+		block     uint64
+		connected []enode.ID
+	)
+	execution := newTestMeshRecorder(history, "execution", &block, &connected)
+	consensus := newTestMeshRecorder(history, "consensus", &block, &connected)
+
+	block = 10
+	consensus.RecordConsensusEnodes(committee, committee)
+	block = 11
+	execution.RecordConsensusEnodes(committee[:1], committee)
+	block = 12
+	execution.RecordConsensusEnodes(committee[:2], committee)
+	block = 20
+	execution.RecordConsensusEnodes(committee[1:], committee)
+	history.Close()
+
+	// the prior membership of each network is included, in order of first appearance
+	entries := history.Entries(15, 25)
+	require.Equal(t, []uint64{10, 12, 20}, meshBlocks(entries))
+	require.Equal(t, "consensus", entries[0].Network)
+	require.Equal(t, "execution", entries[1].Network)
+	require.Equal(t, enodeIDs(committee[:2]), entries[1].Selected)
+}
+
+func TestMeshHistoryPruning(t *testing.T) {
+	var (
+		committee = newCommitteeEnodes(2)
+		db        = rawdb.NewMemoryDatabase()
+		history   = NewMeshHistory(db, 3)
+		block     uint64
+		connected []enode.ID
+	)
+	recorder := newTestMeshRecorder(history, "execution", &block, &connected)
+	for block = 1; block <= 5; block++ {
+		recorder.RecordConsensusEnodes(committee[:1], committee)
+	}
+	history.Close()
+
+	require.Equal(t, []uint64{3, 4, 5}, meshBlocks(history.Entries(0, 10)))
+	tail, head := rawdb.ReadMeshHistoryRange(db)
+	require.Equal(t, uint64(2), tail)
+	require.Equal(t, uint64(5), head)
+	for seq := uint64(0); seq < tail; seq++ {
+		require.Nil(t, rawdb.ReadMeshHistoryEntry(db, seq))
+	}
+
+	// the entries are kept across restarts
+	history = NewMeshHistory(db, 3)
+	recorder = newTestMeshRecorder(history, "execution", &block, &connected)
+	block = 6
+	recorder.RecordConsensusEnodes(committee, committee)
+	history.Close()
+	require.Equal(t, []uint64{4, 5, 6}, meshBlocks(history.Entries(0, 10)))
+}
+
+func TestMeshHistoryDisabled(t *testing.T) {
+	history := NewMeshHistory(rawdb.NewMemoryDatabase(), 0)
+	require.Nil(t, history)
+	require.Nil(t, history.Recorder(&Server{}))
+	history.Close()
+}
+
+func concatIDs(nodes []*enode.Node) []byte {
+	var ids []byte
+	for _, node := range nodes {
+		id := node.ID()
+		ids = append(ids, id[:]...)
+	}
+	return ids
+}
@@ -74,7 +74,12 @@ const (
 	DiscSuspended
 	DiscPeerNotInCommittee
 	DiscPeerOutsideTopology
+	DiscIdentityMismatch
 	DiscSubprotocolError = 0x10
+
+	DiscHandshakeTimeout DiscReason = 0x11
+	DiscIdleStream       DiscReason = 0x12
+	DiscGenesisMismatch  DiscReason = 0x13
 )
 
 var discReasonToString = [...]string{
@@ -93,7 +98,11 @@ var discReasonToString = [...]string{
 	DiscSuspended:           "suspended node",
 	DiscPeerNotInCommittee:  "validator is not part of committee",
 	DiscPeerOutsideTopology: "peer outside topology",
+	DiscIdentityMismatch:    "consensus identity mismatch",
 	DiscSubprotocolError:    "subprotocol error",
+	DiscHandshakeTimeout:    "protocol handshake timeout",
+	DiscIdleStream:          "no protocol message since handshake",
+	DiscGenesisMismatch:     "genesis mismatch on the same network",
 }
 
 func (d DiscReason) String() string {
@@ -133,6 +142,7 @@ func discReasonForError(err error) DiscReason {
 // ProtocolError defines the error occurred due to violation of protocol rules, namely ACN or ETH
 type ProtocolError struct {
 	Suspension func() uint64 // number of blocks for which peer is barred from making successful connection
+	Severity   BanSeverity   // severity of the violation, used to ban the peer from the consensus network
 	Code       int
 	Message    string
 }
@@ -67,8 +67,9 @@ const (
 	discmixTimeout = 5 * time.Second
 
 	// Connectivity defaults.
-	defaultMaxPendingPeers = 50
-	defaultDialRatio       = 3
+	defaultMaxPendingPeers   = 50
+	defaultDialRatio         = 3
+	defaultCommitteeOverflow = 5
 
 	// This time limits inbound connection attempts per source IP.
 	inboundThrottleTime = 30 * time.Second
@@ -102,6 +103,11 @@ type Config struct {
 	// Setting DialRatio to zero defaults it to 3.
 	DialRatio int `toml:",omitempty"`
 
+	// CommitteeOverflow is the number of connections to committee members which are
+	// allowed on top of MaxPeers when all the slots are taken by committee peers.
+	// Setting CommitteeOverflow to zero defaults it to 5.
+	CommitteeOverflow int `toml:",omitempty"`
+
 	// NoDiscovery can be used to disable the peer discovery mechanism.
 	// Disabling is useful for protocol debugging (manual topology).
 	NoDiscovery bool
@@ -225,6 +231,7 @@ type Server struct {
 	// State of run loop and listenLoop.
 	inboundHistory expHeap[mclock.AbsTime]
 	suspended      safeExpHeap[uint64]
+	bans           *consensusBans // persistent bans, consensus network only
 
 	committee       []*enode.Node
 	committeeSubset []*enode.Node
@@ -467,6 +474,22 @@ func (srv *Server) inCommittee(id enode.ID) bool {
 	return false
 }
 
+// ConsensusBans returns the active bans of the consensus network, nil for other networks.
+func (srv *Server) ConsensusBans() []*ConsensusBan {
+	if srv.bans == nil {
+		return nil
+	}
+	return srv.bans.list()
+}
+
+// UnbanPeer lifts the consensus network ban of the given node, it returns false if the node is not banned.
+func (srv *Server) UnbanPeer(id enode.ID) bool {
+	if srv.bans == nil {
+		return false
+	}
+	return srv.bans.unban(id)
+}
+
 func (srv *Server) SetCurrentBlockNumber(num uint64) {
 	srv.currentBlock.Store(num)
 }
@@ -611,12 +634,15 @@ func (srv *Server) setupLocalNode() error {
 
 	sort.Sort(capsByNameAndVersion(srv.ourHandshake.Caps))
 
-	// We are creating a memoryDB for consensus server by passing an empty NodeDatabase path
+	// An empty NodeDatabase path creates a memoryDB
 	db, err := enode.OpenDB(srv.Config.NodeDatabase)
 	if err != nil {
 		return err
 	}
 	srv.nodedb = db
+	if srv.Net == Consensus {
+		srv.bans = newConsensusBans(db, time.Now, srv.log)
+	}
 	// Create the local node.
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey, srv.log)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
@@ -741,6 +767,11 @@ func (srv *Server) setupDialScheduler() {
 		trusted:        &srv.trusted,
 		net:            srv.Net,
 	}
+	if srv.bans != nil {
+		config.banned = func(id enode.ID) bool {
+			return srv.bans.banned(id, srv.inCommittee(id))
+		}
+	}
 	if srv.ntab != nil {
 		config.resolver = srv.ntab
 	}
@@ -922,23 +953,87 @@ running:
 	}
 }
 
+// enforcePeersLimit disconnects the non-committee peers exceeding either MaxPeers or the
+// slots left once the ones reserved to the committee are subtracted. Committee peers are
+// never evicted in favour of non-committee peers.
 func (srv *Server) enforcePeersLimit(peers map[enode.ID]*Peer) {
-	if srv.Net == Consensus || len(peers) <= srv.MaxPeers {
+	if srv.Net == Consensus {
 		return
 	}
+	candidates := srv.evictionCandidates(peers)
+	excess := len(peers) - srv.MaxPeers
+	if e := len(candidates) - srv.maxNonCommitteePeers(); e > excess {
+		excess = e
+	}
+	for i := 0; i < excess && i < len(candidates); i++ {
+		srv.log.Debug("Evicting non-committee peer", "id", candidates[i].ID(), "server", srv.Net.String())
+		candidates[i].Disconnect(DiscTooManyPeers)
+	}
+}
+
+// evictionCandidates returns the peers which can be disconnected to make room
+// for committee peers, the most recently connected first.
+func (srv *Server) evictionCandidates(peers map[enode.ID]*Peer) []*Peer {
+	var candidates []*Peer
 	for _, p := range peers {
-		if !srv.inCommittee(p.ID()) {
-			p.Disconnect(DiscTooManyPeers)
+		if !p.rw.is(trustedConn) && !srv.isCommitteePeer(p.ID()) {
+			candidates = append(candidates, p)
 		}
 	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].created != candidates[j].created {
+			return candidates[i].created > candidates[j].created
+		}
+		return bytes.Compare(candidates[i].ID().Bytes(), candidates[j].ID().Bytes()) < 0
+	})
+	return candidates
+}
+
+// isCommitteePeer reports whether the node is member of the current committee and
+// as such entitled to the reserved slots.
+func (srv *Server) isCommitteePeer(id enode.ID) bool {
+	return srv.Net != Consensus && srv.inCommittee(id)
+}
+
+// reservedCommitteeSlots returns the number of slots reserved to committee peers, which
+// is the size of the consensus mesh. It is zero when the local node is not in the committee.
+func (srv *Server) reservedCommitteeSlots() int {
+	if srv.Net == Consensus {
+		return 0
+	}
+	srv.enodeMu.RLock()
+	defer srv.enodeMu.RUnlock()
+	return len(srv.committeeSubset)
+}
+
+// maxNonCommitteePeers returns the number of slots available to non-committee peers.
+func (srv *Server) maxNonCommitteePeers() int {
+	return srv.MaxPeers - srv.reservedCommitteeSlots()
+}
+
+// maxCommitteePeers returns the total number of peers above which committee peers are refused.
+func (srv *Server) maxCommitteePeers() int {
+	limit := srv.MaxPeers
+	if reserved := srv.reservedCommitteeSlots(); reserved > limit {
+		limit = reserved
+	}
+	if srv.CommitteeOverflow == 0 {
+		return limit + defaultCommitteeOverflow
+	}
+	return limit + srv.CommitteeOverflow
 }
 
 func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	srv.suspended.expire(srv.currentBlock.Load(), nil)
+	committee := srv.isCommitteePeer(c.node.ID())
 	switch {
-	case !c.is(trustedConn) && len(peers) >= srv.MaxPeers:
+	case committee && len(peers) >= srv.maxCommitteePeers() && len(srv.evictionCandidates(peers)) == 0:
 		return DiscTooManyPeers
-	case !c.is(trustedConn) && c.is(inboundConn) && inboundCount >= srv.maxInboundConns():
+	case !committee && !c.is(trustedConn) && len(peers) >= srv.MaxPeers:
+		return DiscTooManyPeers
+	case !committee && !c.is(trustedConn) && len(srv.evictionCandidates(peers)) >= srv.maxNonCommitteePeers():
+		return DiscTooManyPeers
+	case !committee && !c.is(trustedConn) && c.is(inboundConn) && inboundCount >= srv.maxInboundConns():
 		return DiscTooManyPeers
 	case peers[c.node.ID()] != nil:
 		return DiscAlreadyConnected
@@ -946,6 +1041,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscSelf
 	case srv.suspended.contains(c.node.ID().String()):
 		return DiscSuspended
+	case srv.bans != nil && srv.bans.banned(c.node.ID(), srv.inCommittee(c.node.ID())):
+		return DiscSuspended
 	case srv.Net == Consensus && !srv.inCommittee(c.node.ID()):
 		return DiscPeerNotInCommittee
 	case srv.Net == Execution && srv.inCommittee(c.node.ID()) && !srv.inCommitteeSubset(c.node.ID()):
@@ -1072,6 +1169,9 @@ func (srv *Server) processPeerSuspension(pd peerDrop) {
 	var protoError *ProtocolError
 	if errors.As(pd.err, &protoError) {
 		srv.suspended.add(pd.ID().String(), srv.currentBlock.Load()+protoError.Suspension())
+		if srv.bans != nil {
+			srv.bans.record(pd.ID(), protoError.Message, protoError.Severity)
+		}
 	} else if errors.As(pd.err, &p2pError) {
 		switch p2pError.code {
 		case errInvalidMsgCode, errInvalidMsg:
@@ -267,6 +267,123 @@ func TestServerAtCap(t *testing.T) {
 	}
 }
 
+// This test checks that the slots reserved to the committee survive peer pressure: committee
+// connections are never evicted and non-committee peers are disconnected to make room for them.
+func TestServerCommitteeReservation(t *testing.T) {
+	key := newkey()
+	srv := &Server{
+		Net: Execution,
+		Config: Config{
+			PrivateKey:        newkey(),
+			MaxPeers:          4,
+			CommitteeOverflow: 1,
+			NoDial:            true,
+			NoDiscovery:       true,
+			Logger:            testlog.Logger(t, log.LvlTrace),
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+	defer srv.Stop()
+
+	newconn := func(id enode.ID) *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(&key.PublicKey, fd, nil)
+		node := enode.SignNull(new(enr.Record), id)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, node: node, cont: make(chan error)}
+	}
+	connected := func(id enode.ID) bool {
+		for _, p := range srv.Peers() {
+			if p.ID() == id {
+				return true
+			}
+		}
+		return false
+	}
+	waitPeerCount := func(count int) {
+		t.Helper()
+		for i := 0; i < 100 && srv.PeerCount() != count; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if n := srv.PeerCount(); n != count {
+			t.Fatalf("peer count mismatch: have %d, want %d", n, count)
+		}
+	}
+
+	// fill up the peer set with non-committee peers
+	var others []enode.ID
+	for i := 0; i < 4; i++ {
+		id := randomID()
+		if err := srv.checkpoint(newconn(id), srv.checkpointAddPeer); err != nil {
+			t.Fatalf("could not add conn %d: %v", i, err)
+		}
+		others = append(others, id)
+	}
+	if err := srv.checkpoint(newconn(randomID()), srv.checkpointPostHandshake); err != DiscTooManyPeers {
+		t.Fatal("wrong error for insert:", err)
+	}
+
+	// the local node enters a committee with a consensus mesh of three peers
+	committee := []*enode.Node{newNode(randomID(), ""), newNode(randomID(), ""), newNode(randomID(), "")}
+	srv.UpdateConsensusEnodes(committee, committee)
+	if reserved := srv.reservedCommitteeSlots(); reserved != 3 {
+		t.Fatalf("reserved slots mismatch: have %d, want 3", reserved)
+	}
+
+	// committee peers connect despite the server being full, non-committee peers are evicted instead
+	for i, node := range committee {
+		if err := srv.checkpoint(newconn(node.ID()), srv.checkpointAddPeer); err != nil {
+			t.Fatalf("could not add committee conn %d: %v", i, err)
+		}
+	}
+	waitPeerCount(4)
+	for _, node := range committee {
+		if !connected(node.ID()) {
+			t.Fatalf("committee peer %v was evicted", node.ID())
+		}
+	}
+	if !connected(others[0]) {
+		t.Fatal("oldest non-committee peer should have survived")
+	}
+
+	// non-committee churn cannot take the reserved slots
+	for i := 0; i < 10; i++ {
+		if err := srv.checkpoint(newconn(randomID()), srv.checkpointPostHandshake); err != DiscTooManyPeers {
+			t.Fatal("wrong error for non-committee insert:", err)
+		}
+	}
+
+	// committee peers can exceed MaxPeers only by the overflow once nothing is left to evict
+	srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+		peers[others[0]].Disconnect(DiscRequested)
+	})
+	waitPeerCount(3)
+	for i := 0; i < 2; i++ {
+		id := randomID()
+		srv.AddTrustedPeer(newNode(id, ""))
+		c := newconn(id)
+		if err := srv.checkpoint(c, srv.checkpointPostHandshake); err != nil {
+			t.Fatalf("unexpected error for trusted conn %d @posthandshake: %v", i, err)
+		}
+		if err := srv.checkpoint(c, srv.checkpointAddPeer); err != nil {
+			t.Fatalf("could not add trusted conn %d: %v", i, err)
+		}
+	}
+	waitPeerCount(5)
+	committee = append(committee, newNode(randomID(), ""))
+	srv.UpdateConsensusEnodes(committee, committee)
+	if err := srv.checkpoint(newconn(committee[3].ID()), srv.checkpointPostHandshake); err != DiscTooManyPeers {
+		t.Fatal("wrong error for committee insert above overflow:", err)
+	}
+
+	// the reservation is released when leaving the committee
+	srv.UpdateConsensusEnodes(nil, nil)
+	if reserved := srv.reservedCommitteeSlots(); reserved != 0 {
+		t.Fatalf("reserved slots mismatch: have %d, want 0", reserved)
+	}
+}
+
 func TestServerPeerLimits(t *testing.T) {
 	srvkey := newkey()
 	clientkey := newkey()
@@ -712,7 +712,7 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, AsmConfig{}, nil, nil, false}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, AsmConfig{}, nil, nil, 0, false}
 
 	TestNodeKeys = []string{
 		"b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291",
@@ -785,6 +785,7 @@ var (
 		},
 		DefaultNonStakableVestingGenesis,
 		DefaultStakableVestingGenesis,
+		0,
 		false,
 	}
 )
@@ -900,6 +901,10 @@ type ChainConfig struct {
 	NonStakableVestingConfig *NonStakableVestingGenesis  `json:"nonStakableVesting,omitempty"`
 	StakableVestingConfig    *StakableVestingGenesis     `json:"stakableVesting,omitempty"`
 
+	// MaxProposalSize is the cap, in bytes, on the RLP size of a proposed block.
+	// DefaultMaxProposalSize is used if zero, and it cannot exceed ProposalSizeCeiling.
+	MaxProposalSize uint64 `json:"maxProposalSize,omitempty"`
+
 	// true if run in testmode, false by default
 	TestMode bool `json:"testMode,omitempty"`
 }
@@ -1015,6 +1020,29 @@ func (c *ChainConfig) IsArrowGlacier(num *big.Int) bool {
 	return isForked(c.ArrowGlacierBlock, num)
 }
 
+// ProposalSizeCap returns the configured cap on the RLP size of a proposed block.
+func (c *ChainConfig) ProposalSizeCap() uint64 {
+	limit := c.MaxProposalSize
+	if limit == 0 {
+		limit = DefaultMaxProposalSize
+	}
+	if limit > ProposalSizeCeiling {
+		limit = ProposalSizeCeiling
+	}
+	return limit
+}
+
+// ProposalSizeLimit returns the maximum RLP size of a proposed block with the given gas limit.
+// Every transaction byte costs at least TxDataZeroGas, so a valid block cannot carry more
+// transaction bytes than its gas limit allows: the limit is the smaller of that bound and the cap.
+func (c *ChainConfig) ProposalSizeLimit(gasLimit uint64) uint64 {
+	limit := c.ProposalSizeCap()
+	if bound := gasLimit/TxDataZeroGas + ProposalHeaderReserve; bound < limit {
+		limit = bound
+	}
+	return limit
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -96,3 +96,23 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestProposalSizeLimit(t *testing.T) {
+	tests := []struct {
+		maxProposalSize uint64
+		gasLimit        uint64
+		want            uint64
+	}{
+		{maxProposalSize: 0, gasLimit: 1 << 40, want: DefaultMaxProposalSize},
+		{maxProposalSize: 1 << 20, gasLimit: 1 << 40, want: 1 << 20},
+		{maxProposalSize: 1 << 30, gasLimit: 1 << 40, want: ProposalSizeCeiling},
+		{maxProposalSize: 0, gasLimit: 8_000_000, want: 8_000_000/TxDataZeroGas + ProposalHeaderReserve},
+		{maxProposalSize: 0, gasLimit: 0, want: ProposalHeaderReserve},
+	}
+	for _, test := range tests {
+		config := &ChainConfig{MaxProposalSize: test.maxProposalSize}
+		if have := config.ProposalSizeLimit(test.gasLimit); have != test.want {
+			t.Errorf("limit mismatch: maxProposalSize %d, gasLimit %d: have %d, want %d", test.maxProposalSize, test.gasLimit, have, test.want)
+		}
+	}
+}
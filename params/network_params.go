@@ -24,6 +24,14 @@ const (
 	// contains on the server side.
 	BloomBitsBlocks uint64 = 4096
 
+	// BloomBitsBlockTime is the block time in seconds BloomBitsBlocks is tuned for.
+	BloomBitsBlockTime uint64 = 13
+
+	// MinBloomBitsBlocks and MaxBloomBitsBlocks bound the number of blocks per bloom
+	// bit section a full node can be configured with.
+	MinBloomBitsBlocks uint64 = 1024
+	MaxBloomBitsBlocks uint64 = 65536
+
 	// BloomBitsBlocksClient is the number of blocks a single bloom bit section vector
 	// contains on the light client side
 	BloomBitsBlocksClient uint64 = 32768
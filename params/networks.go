@@ -0,0 +1,35 @@
+package params
+
+// AutonityNetwork is an official Autonity network, identified by the chain ID of its genesis and by the
+// network ID its nodes handshake with.
+type AutonityNetwork struct {
+	Name      string
+	ChainID   uint64
+	NetworkID uint64
+}
+
+// AutonityNetworks are the official Autonity networks. Their network ID is their chain ID.
+var AutonityNetworks = []AutonityNetwork{
+	{Name: "piccadilly", ChainID: PiccadillyChainConfig.ChainID.Uint64(), NetworkID: PiccadillyChainConfig.ChainID.Uint64()},
+	{Name: "bakerloo", ChainID: BakerlooChainConfig.ChainID.Uint64(), NetworkID: BakerlooChainConfig.ChainID.Uint64()},
+}
+
+// AutonityNetworkByChainID returns the official network of the chain ID, nil if none.
+func AutonityNetworkByChainID(chainID uint64) *AutonityNetwork {
+	for i := range AutonityNetworks {
+		if AutonityNetworks[i].ChainID == chainID {
+			return &AutonityNetworks[i]
+		}
+	}
+	return nil
+}
+
+// AutonityNetworkByNetworkID returns the official network of the network ID, nil if none.
+func AutonityNetworkByNetworkID(networkID uint64) *AutonityNetwork {
+	for i := range AutonityNetworks {
+		if AutonityNetworks[i].NetworkID == networkID {
+			return &AutonityNetworks[i]
+		}
+	}
+	return nil
+}
@@ -91,6 +91,27 @@ var (
 	InflationControllerContractAddress = crypto.CreateAddress(DeployerAddress, 7)
 	StakableVestingContractAddress     = crypto.CreateAddress(DeployerAddress, 8)
 	NonStakableVestingContractAddress  = crypto.CreateAddress(DeployerAddress, 9)
+
+	// ProtocolStateAccounts are the accounts the protocol contracts are called against: the deployer the
+	// calls are made from, and the protocol contracts.
+	ProtocolStateAccounts = []common.Address{
+		DeployerAddress,
+		AutonityContractAddress,
+		AccountabilityContractAddress,
+		OracleContractAddress,
+		ACUContractAddress,
+		SupplyControlContractAddress,
+		StabilizationContractAddress,
+		UpgradeManagerContractAddress,
+		InflationControllerContractAddress,
+		StakableVestingContractAddress,
+		NonStakableVestingContractAddress,
+	}
+
+	// precompiled contracts verifying the proofs of the accountability events
+	CheckAccusationAddress   = common.BytesToAddress([]byte{0xfc})
+	CheckInnocenceAddress    = common.BytesToAddress([]byte{0xfd})
+	CheckMisbehaviourAddress = common.BytesToAddress([]byte{0xfe})
 )
 
 type AutonityContractGenesis struct {
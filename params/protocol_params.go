@@ -168,6 +168,15 @@ const (
 // Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
 var Bls12381MultiExpDiscountTable = [128]uint64{1200, 888, 764, 641, 594, 547, 500, 453, 438, 423, 408, 394, 379, 364, 349, 334, 330, 326, 322, 318, 314, 310, 306, 302, 298, 294, 289, 285, 281, 277, 273, 269, 268, 266, 265, 263, 262, 260, 259, 257, 256, 254, 253, 251, 250, 248, 247, 245, 244, 242, 241, 239, 238, 236, 235, 233, 232, 231, 229, 228, 226, 225, 223, 222, 221, 220, 219, 219, 218, 217, 216, 216, 215, 214, 213, 213, 212, 211, 211, 210, 209, 208, 208, 207, 206, 205, 205, 204, 203, 202, 202, 201, 200, 199, 199, 198, 197, 196, 196, 195, 194, 193, 193, 192, 191, 191, 190, 189, 188, 188, 187, 186, 185, 185, 184, 183, 182, 182, 181, 180, 179, 179, 178, 177, 176, 176, 175, 174}
 
+// Proposal size limits. A proposal is relayed as a single devp2p message, which is capped at 10MB
+// by both the eth and the consensus protocols.
+const (
+	ProposalMessageOverhead uint64 = 1024                                   // Upper bound of the proposal message fields other than the block
+	ProposalSizeCeiling     uint64 = 10*1024*1024 - ProposalMessageOverhead // Largest proposed block that fits in a devp2p message
+	DefaultMaxProposalSize  uint64 = 8 * 1024 * 1024                        // Default cap on the size of a proposed block
+	ProposalHeaderReserve   uint64 = 256 * 1024                             // Share of the proposal size reserved to the header and the block envelope
+)
+
 var (
 	DifficultyBoundDivisor = big.NewInt(2048)   // The bound divisor of the difficulty, used in the update calculations.
 	GenesisDifficulty      = big.NewInt(0)      // Difficulty of the Genesis block.
@@ -181,3 +181,19 @@ func PeerInfoFromContext(ctx context.Context) PeerInfo {
 	info, _ := ctx.Value(peerInfoContextKey{}).(PeerInfo)
 	return info
 }
+
+type serviceRegistryContextKey struct{}
+
+// MethodsFromContext returns the sorted names of the methods callable on the server handling
+// the current method call, restricted to the given namespaces. As each endpoint only registers
+// the modules it exposes, the list reflects the endpoint the client is connected to.
+// Use this with the context passed to RPC method handler functions.
+//
+// Nil is returned if no server is present in ctx.
+func MethodsFromContext(ctx context.Context, namespaces ...string) []string {
+	reg, _ := ctx.Value(serviceRegistryContextKey{}).(*serviceRegistry)
+	if reg == nil {
+		return nil
+	}
+	return reg.methods(namespaces)
+}
@@ -19,10 +19,12 @@ package rpc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
 	"net"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -45,12 +47,44 @@ func TestServerRegisterName(t *testing.T) {
 		t.Fatalf("Expected service calc to be registered")
 	}
 
-	wantCallbacks := 10
+	wantCallbacks := 11
 	if len(svc.callbacks) != wantCallbacks {
 		t.Errorf("Expected %d callbacks for service 'service', got %d", wantCallbacks, len(svc.callbacks))
 	}
 }
 
+func TestServerMethodsFromContext(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var methods []string
+	if err := client.Call(&methods, "test_methods", []string{"rpc", "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(methods, []string{"rpc_modules"}) {
+		t.Errorf("wrong methods: %v", methods)
+	}
+	if err := client.Call(&methods, "test_methods", []string{"test"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != len(server.services.services["test"].callbacks) {
+		t.Fatalf("wrong number of methods: %v", methods)
+	}
+	for _, method := range methods {
+		if !strings.HasPrefix(method, "test_") {
+			t.Errorf("method %s listed from another namespace", method)
+		}
+	}
+	if MethodsFromContext(context.Background(), "test") != nil {
+		t.Error("methods listed without a server")
+	}
+}
+
 func TestServer(t *testing.T) {
 	files, err := ioutil.ReadDir("testdata")
 	if err != nil {
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"unicode"
@@ -115,6 +116,20 @@ func (r *serviceRegistry) callback(method string) *callback {
 	return r.services[elem[0]].callbacks[elem[1]]
 }
 
+// methods returns the sorted names of the methods of the given services.
+func (r *serviceRegistry) methods(services []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var methods []string
+	for _, name := range services {
+		for method := range r.services[name].callbacks {
+			methods = append(methods, name+serviceMethodSeparator+method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 // subscription returns a subscription callback in the given service.
 func (r *serviceRegistry) subscription(service, name string) *callback {
 	r.mu.Lock()
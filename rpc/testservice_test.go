@@ -84,6 +84,10 @@ func (s *testService) PeerInfo(ctx context.Context) PeerInfo {
 	return PeerInfoFromContext(ctx)
 }
 
+func (s *testService) Methods(ctx context.Context, namespaces []string) []string {
+	return MethodsFromContext(ctx, namespaces...)
+}
+
 func (s *testService) Sleep(ctx context.Context, duration time.Duration) {
 	time.Sleep(duration)
 }
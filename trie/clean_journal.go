@@ -0,0 +1,369 @@
+package trie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/golang/snappy"
+
+	"github.com/autonity/autonity/common"
+	"github.com/autonity/autonity/log"
+)
+
+// The clean cache journal is a single file holding the buckets of the fastcache dump as independent
+// records, so that a journal cut short by a crash still restores the buckets written before it:
+//
+//	header:  magic "ACJ1" | maxBucketChunks uint64 | records uint32
+//	record:  length uint32 | bucket | crc32 of the bucket
+//	trailer: 0 uint32 | crc32 of the record checksums
+//
+// The integers are little endian, a bucket is encoded as in the fastcache data files. The journal is
+// written to a temporary file renamed over the previous journal once complete.
+const (
+	cleanJournalMagic      = "ACJ1"
+	cleanJournalHeaderSize = len(cleanJournalMagic) + 8 + 4
+
+	// fastcache internals the bucket records are parsed with, as of fastcache v1.6.0
+	fastcacheBuckets   = 512
+	fastcacheChunkSize = 64 * 1024
+)
+
+var (
+	cleanJournalTable = crc32.MakeTable(crc32.Castagnoli)
+
+	// the files of an interrupted journal write
+	cleanJournalTempPattern = ".tmp-*"
+	fastcacheDataFile       = regexp.MustCompile(`^data\.\d+\.bin$`)
+
+	errCleanJournalHeader    = errors.New("invalid journal header")
+	errCleanJournalRecord    = errors.New("invalid record length")
+	errCleanJournalChecksum  = errors.New("record checksum mismatch")
+	errCleanJournalTruncated = errors.New("journal truncated")
+)
+
+// CleanJournalStatus is the outcome of the last load of the clean cache journal.
+type CleanJournalStatus struct {
+	Path      string        `json:"path"`
+	Format    string        `json:"format"`    // none if no journal was found, legacy for a fastcache directory
+	Records   int           `json:"records"`   // buckets stored in the journal
+	Recovered int           `json:"recovered"` // buckets restored to the cache
+	Discarded int           `json:"discarded"` // buckets lost to the corruption
+	Truncated bool          `json:"truncated"` // whether the corrupted tail was truncated
+	Error     string        `json:"error,omitempty"`
+	Elapsed   time.Duration `json:"elapsed"`
+}
+
+// loadCleanJournal loads the clean cache of maxBytes from the journal at path. The intact records of
+// a corrupted journal are restored and the rest is truncated; the cache is empty if nothing can be
+// restored. Loading never fails.
+func loadCleanJournal(path string, maxBytes int) (*fastcache.Cache, CleanJournalStatus) {
+	start := time.Now()
+	status := CleanJournalStatus{Path: path, Format: "none"}
+	cache, err := readCleanJournal(path, maxBytes, &status)
+	status.Elapsed = time.Since(start)
+	switch {
+	case err != nil:
+		status.Error = err.Error()
+		log.Warn("Trie clean cache journal partially restored", "path", path, "records", status.Records,
+			"recovered", status.Recovered, "discarded", status.Discarded, "truncated", status.Truncated, "err", err)
+	case status.Format != "none":
+		log.Info("Loaded trie clean cache journal", "path", path, "format", status.Format, "records", status.Recovered,
+			"elapsed", common.PrettyDuration(status.Elapsed))
+	}
+	if cache == nil {
+		cache = fastcache.New(maxBytes)
+	}
+	return cache, status
+}
+
+func readCleanJournal(path string, maxBytes int, status *CleanJournalStatus) (*fastcache.Cache, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		// journal written by fastcache, before the records were framed
+		status.Format = "legacy"
+		return fastcache.LoadFromFileOrNew(path, maxBytes), nil
+	}
+	status.Format = "framed"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	var header [cleanJournalHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil || string(header[:len(cleanJournalMagic)]) != cleanJournalMagic {
+		return nil, errCleanJournalHeader
+	}
+	chunks := binary.LittleEndian.Uint64(header[len(cleanJournalMagic):])
+	status.Records = int(binary.LittleEndian.Uint32(header[len(cleanJournalMagic)+8:]))
+	status.Discarded = status.Records
+	if chunks != fastcacheBucketChunks(maxBytes) {
+		// the cache size changed, its buckets can't be restored
+		return nil, fmt.Errorf("journal of %d chunks per bucket, want %d", chunks, fastcacheBucketChunks(maxBytes))
+	}
+
+	// restore the intact records to a fastcache directory, loaded from once complete
+	dir, err := os.MkdirTemp(filepath.Dir(path), filepath.Base(path)+cleanJournalTempPattern)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "metadata.bin"), binary.LittleEndian.AppendUint64(nil, chunks), 0644); err != nil {
+		return nil, err
+	}
+	data, err := os.Create(filepath.Join(dir, "data.0.bin"))
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+	zw := snappy.NewBufferedWriter(data)
+
+	var (
+		offset    = int64(cleanJournalHeaderSize)
+		checksums = crc32.New(cleanJournalTable)
+		corrupted error
+	)
+	for {
+		record, err := readCleanJournalRecord(r, chunks)
+		if err != nil {
+			corrupted = err
+			break
+		}
+		if record == nil {
+			// trailer, the journal is complete if it matches the records read
+			var sum [4]byte
+			if _, err := io.ReadFull(r, sum[:]); err != nil || binary.LittleEndian.Uint32(sum[:]) != checksums.Sum32() || status.Recovered != status.Records {
+				corrupted = errCleanJournalTruncated
+			}
+			break
+		}
+		if _, err := zw.Write(record[:len(record)-4]); err != nil {
+			return nil, err
+		}
+		checksums.Write(record[len(record)-4:])
+		offset += int64(4 + len(record))
+		status.Recovered++
+	}
+	status.Discarded = max(status.Records-status.Recovered, 0)
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if corrupted != nil {
+		// drop the corrupted tail, the journal is replaced on the next write anyway
+		status.Truncated = os.Truncate(path, offset) == nil
+	}
+	if status.Recovered == 0 {
+		return nil, corrupted
+	}
+	// the buckets missing from the directory are initialised empty
+	cache, err := fastcache.LoadFromFile(dir)
+	if err != nil {
+		status.Discarded, status.Recovered = status.Records, 0
+		return nil, err
+	}
+	return cache, corrupted
+}
+
+// readCleanJournalRecord returns the next bucket record followed by its checksum, or nil for the trailer.
+func readCleanJournalRecord(r io.Reader, chunks uint64) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, errCleanJournalTruncated
+	}
+	size := binary.LittleEndian.Uint32(length[:])
+	if size == 0 {
+		return nil, nil
+	}
+	// a bucket can't exceed its chunks and the index of the entries they hold, of 4 bytes at least
+	if uint64(size) > 5*8+5*chunks*fastcacheChunkSize {
+		return nil, fmt.Errorf("%w: %d bytes", errCleanJournalRecord, size)
+	}
+	record := make([]byte, size+4)
+	if _, err := io.ReadFull(r, record); err != nil {
+		return nil, errCleanJournalTruncated
+	}
+	if crc32.Checksum(record[:size], cleanJournalTable) != binary.LittleEndian.Uint32(record[size:]) {
+		return nil, errCleanJournalChecksum
+	}
+	return record, nil
+}
+
+// saveCleanJournal writes the clean cache to the journal at path, replaced atomically.
+func saveCleanJournal(cache *fastcache.Cache, path string, threads int) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// remove the leftovers of an interrupted write
+	stale, _ := filepath.Glob(filepath.Join(dir, filepath.Base(path)+cleanJournalTempPattern))
+	for _, name := range stale {
+		os.RemoveAll(name)
+	}
+	dump, err := os.MkdirTemp(dir, filepath.Base(path)+cleanJournalTempPattern)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dump)
+	// fastcache saves to a new directory
+	dump = filepath.Join(dump, "cache")
+	if err := cache.SaveToFileConcurrent(dump, threads); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+cleanJournalTempPattern)
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if err := writeCleanJournal(f, dump); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// a directory left by fastcache can't be renamed over
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeCleanJournal frames the buckets of the fastcache dump in dir to f.
+func writeCleanJournal(f *os.File, dir string) error {
+	metadata, err := os.ReadFile(filepath.Join(dir, "metadata.bin"))
+	if err != nil {
+		return err
+	}
+	if len(metadata) != 8 {
+		return fmt.Errorf("invalid fastcache metadata of %d bytes", len(metadata))
+	}
+	chunks := binary.LittleEndian.Uint64(metadata)
+	w := bufio.NewWriter(f)
+	header := append([]byte(cleanJournalMagic), metadata...)
+	if _, err := w.Write(append(header, 0, 0, 0, 0)); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var (
+		records   uint32
+		checksums = crc32.New(cleanJournalTable)
+		buf       bytes.Buffer
+	)
+	for _, entry := range entries {
+		if !fastcacheDataFile.MatchString(entry.Name()) {
+			continue
+		}
+		data, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		zr := snappy.NewReader(bufio.NewReader(data))
+		for {
+			buf.Reset()
+			if err := copyFastcacheBucket(&buf, zr, chunks); err == io.EOF {
+				break
+			} else if err != nil {
+				data.Close()
+				return fmt.Errorf("cannot read the buckets of %s: %w", entry.Name(), err)
+			}
+			sum := crc32.Checksum(buf.Bytes(), cleanJournalTable)
+			record := binary.LittleEndian.AppendUint32(nil, uint32(buf.Len()))
+			record = append(record, buf.Bytes()...)
+			record = binary.LittleEndian.AppendUint32(record, sum)
+			if _, err := w.Write(record); err != nil {
+				data.Close()
+				return err
+			}
+			checksums.Write(record[len(record)-4:])
+			records++
+		}
+		data.Close()
+	}
+	if _, err := w.Write(binary.LittleEndian.AppendUint32(make([]byte, 4), checksums.Sum32())); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	// the record count is only known once they are written
+	_, err = f.WriteAt(binary.LittleEndian.AppendUint32(nil, records), int64(len(cleanJournalMagic)+8))
+	return err
+}
+
+// copyFastcacheBucket copies a bucket of a fastcache data file, with its number, to w. It returns io.EOF
+// at the end of the file.
+func copyFastcacheBucket(w *bytes.Buffer, r io.Reader, chunks uint64) error {
+	var word [8]byte
+	readWord := func() (uint64, error) {
+		if _, err := io.ReadFull(r, word[:]); err != nil {
+			return 0, err
+		}
+		w.Write(word[:])
+		return binary.LittleEndian.Uint64(word[:]), nil
+	}
+	// bucket number, index and generation
+	if num, err := readWord(); err != nil {
+		return err
+	} else if num >= fastcacheBuckets {
+		return fmt.Errorf("bucket %d out of range", num)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := readWord(); err != nil {
+			return io.ErrUnexpectedEOF
+		}
+	}
+	entries, err := readWord()
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if _, err := io.CopyN(w, r, int64(entries*16)); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	used, err := readWord()
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if used > chunks {
+		return fmt.Errorf("bucket of %d chunks, at most %d", used, chunks)
+	}
+	if _, err := io.CopyN(w, r, int64(used*fastcacheChunkSize)); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// fastcacheBucketChunks returns the number of chunks per bucket of a fastcache of maxBytes.
+func fastcacheBucketChunks(maxBytes int) uint64 {
+	bucketBytes := uint64((maxBytes + fastcacheBuckets - 1) / fastcacheBuckets)
+	return (bucketBytes + fastcacheChunkSize - 1) / fastcacheChunkSize
+}
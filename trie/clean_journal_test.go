@@ -0,0 +1,204 @@
+package trie
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autonity/autonity/core/rawdb"
+	"github.com/autonity/autonity/crypto"
+)
+
+const testCleanCacheSize = 32 // MB
+
+func newTestCleanCache(t *testing.T, entries int) (*fastcache.Cache, [][]byte) {
+	cache := fastcache.New(testCleanCacheSize * 1024 * 1024)
+	keys := make([][]byte, entries)
+	for i := range keys {
+		keys[i] = crypto.Keccak256(binary.BigEndian.AppendUint64(nil, uint64(i)))
+		cache.Set(keys[i], append(keys[i], keys[i]...))
+	}
+	return cache, keys
+}
+
+// cachedKeys returns the number of keys held by the cache.
+func cachedKeys(cache *fastcache.Cache, keys [][]byte) int {
+	var n int
+	for _, key := range keys {
+		if cache.Has(key) {
+			n++
+		}
+	}
+	return n
+}
+
+// cleanJournalRecordEnds returns the offsets the journal records end at.
+func cleanJournalRecordEnds(t *testing.T, journal []byte) []int {
+	var (
+		ends   []int
+		offset = cleanJournalHeaderSize
+	)
+	for {
+		size := int(binary.LittleEndian.Uint32(journal[offset:]))
+		if size == 0 {
+			return ends
+		}
+		offset += 4 + size + 4
+		ends = append(ends, offset)
+	}
+}
+
+func TestCleanJournalRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triecache")
+	cache, keys := newTestCleanCache(t, 20000)
+	require.NoError(t, saveCleanJournal(cache, path, 2))
+
+	loaded, status := loadCleanJournal(path, testCleanCacheSize*1024*1024)
+	require.Equal(t, "framed", status.Format)
+	require.Equal(t, fastcacheBuckets, status.Records)
+	require.Equal(t, fastcacheBuckets, status.Recovered)
+	require.Zero(t, status.Discarded)
+	require.Empty(t, status.Error)
+	require.Equal(t, len(keys), cachedKeys(loaded, keys))
+	for _, key := range keys[:10] {
+		require.Equal(t, append(key, key...), loaded.Get(nil, key))
+	}
+}
+
+func TestCleanJournalCorruption(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "triecache")
+	cache, keys := newTestCleanCache(t, 20000)
+	require.NoError(t, saveCleanJournal(cache, path, 1))
+	journal, err := os.ReadFile(path)
+	require.NoError(t, err)
+	ends := cleanJournalRecordEnds(t, journal)
+	require.Len(t, ends, fastcacheBuckets)
+
+	tests := []struct {
+		name      string
+		offset    int  // corrupted byte
+		truncate  bool // the journal is cut at offset instead
+		recovered int
+	}{
+		{"header", 1, false, 0},
+		{"first record", cleanJournalHeaderSize + 10, false, 0},
+		{"middle record", ends[200] + 100, false, 201},
+		{"record length", ends[300], false, 301},
+		{"last record", ends[len(ends)-1] - 1, false, len(ends) - 1},
+		{"trailer", len(journal) - 1, false, len(ends)},
+		{"cut in a record", ends[100] + 7, true, 101},
+		{"cut before the trailer", ends[len(ends)-1], true, len(ends)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			corrupted := append([]byte{}, journal...)
+			if test.truncate {
+				corrupted = corrupted[:test.offset]
+			} else {
+				corrupted[test.offset] ^= 0xff
+			}
+			require.NoError(t, os.WriteFile(path, corrupted, 0644))
+
+			loaded, status := loadCleanJournal(path, testCleanCacheSize*1024*1024)
+			require.NotNil(t, loaded)
+			require.NotEmpty(t, status.Error)
+			require.Equal(t, test.recovered, status.Recovered)
+			require.Equal(t, max(status.Records-test.recovered, 0), status.Discarded)
+
+			restored := cachedKeys(loaded, keys)
+			switch test.recovered {
+			case 0:
+				require.Zero(t, restored)
+			case len(ends):
+				require.Equal(t, len(keys), restored)
+			default:
+				require.Greater(t, restored, 0)
+				require.Less(t, restored, len(keys))
+			}
+			if test.name != "header" {
+				// the corrupted tail is dropped, the intact records are kept
+				require.True(t, status.Truncated)
+				info, err := os.Stat(path)
+				require.NoError(t, err)
+				want := int64(cleanJournalHeaderSize)
+				if test.recovered > 0 {
+					want = int64(ends[test.recovered-1])
+				}
+				require.Equal(t, want, info.Size())
+
+				_, status = loadCleanJournal(path, testCleanCacheSize*1024*1024)
+				require.Equal(t, test.recovered, status.Recovered)
+			}
+		})
+	}
+}
+
+func TestCleanJournalCacheSizeChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triecache")
+	cache, keys := newTestCleanCache(t, 1000)
+	require.NoError(t, saveCleanJournal(cache, path, 1))
+
+	loaded, status := loadCleanJournal(path, 4*testCleanCacheSize*1024*1024)
+	require.NotEmpty(t, status.Error)
+	require.Zero(t, status.Recovered)
+	require.Equal(t, fastcacheBuckets, status.Discarded)
+	require.Zero(t, cachedKeys(loaded, keys))
+}
+
+func TestCleanJournalAtomicWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "triecache")
+	old, oldKeys := newTestCleanCache(t, 100)
+	require.NoError(t, saveCleanJournal(old, path, 1))
+
+	// an interrupted write leaves its temporary file, the journal is untouched
+	stale, err := os.CreateTemp(dir, "triecache"+cleanJournalTempPattern)
+	require.NoError(t, err)
+	_, err = stale.Write([]byte(cleanJournalMagic + "partial"))
+	require.NoError(t, err)
+	require.NoError(t, stale.Close())
+
+	loaded, status := loadCleanJournal(path, testCleanCacheSize*1024*1024)
+	require.Empty(t, status.Error)
+	require.Equal(t, len(oldKeys), cachedKeys(loaded, oldKeys))
+
+	// the next write replaces the journal and removes the leftovers
+	cache, keys := newTestCleanCache(t, 500)
+	cache.Reset()
+	cache.Set(keys[0], keys[0])
+	require.NoError(t, saveCleanJournal(cache, path, 1))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "triecache", entries[0].Name())
+
+	loaded, _ = loadCleanJournal(path, testCleanCacheSize*1024*1024)
+	require.Equal(t, 1, cachedKeys(loaded, keys))
+	require.Zero(t, cachedKeys(loaded, oldKeys[1:]))
+}
+
+func TestCleanJournalLegacy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "triecache")
+	cache, keys := newTestCleanCache(t, 1000)
+	require.NoError(t, cache.SaveToFile(path))
+
+	db := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &Config{Cache: testCleanCacheSize, Journal: path})
+	require.Equal(t, "legacy", db.CleanJournalStatus().Format)
+	require.Equal(t, len(keys), cachedKeys(db.cleans, keys))
+
+	// the fastcache directory is replaced by the journal file
+	require.NoError(t, db.SaveCache(path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.False(t, info.IsDir())
+
+	db = NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &Config{Cache: testCleanCacheSize, Journal: path})
+	require.Equal(t, "framed", db.CleanJournalStatus().Format)
+	require.Equal(t, len(keys), cachedKeys(db.cleans, keys))
+}
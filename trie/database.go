@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/fastcache"
@@ -88,6 +89,9 @@ type Database struct {
 	childrenSize  common.StorageSize // Storage size of the external children tracking
 	preimagesSize common.StorageSize // Storage size of the preimages cache
 
+	journalPaused atomic.Bool        // the clean cache is not saved to disk, see PauseJournal
+	journalStatus CleanJournalStatus // outcome of the clean cache journal load
+
 	lock sync.RWMutex
 }
 
@@ -290,17 +294,21 @@ func NewDatabase(diskdb ethdb.KeyValueStore) *Database {
 // before its written out to disk or garbage collected. It also acts as a read cache
 // for nodes loaded from disk.
 func NewDatabaseWithConfig(diskdb ethdb.KeyValueStore, config *Config) *Database {
-	var cleans *fastcache.Cache
+	var (
+		cleans        *fastcache.Cache
+		journalStatus CleanJournalStatus
+	)
 	if config != nil && config.Cache > 0 {
 		if config.Journal == "" {
 			cleans = fastcache.New(config.Cache * 1024 * 1024)
 		} else {
-			cleans = fastcache.LoadFromFileOrNew(config.Journal, config.Cache*1024*1024)
+			cleans, journalStatus = loadCleanJournal(config.Journal, config.Cache*1024*1024)
 		}
 	}
 	db := &Database{
-		diskdb: diskdb,
-		cleans: cleans,
+		diskdb:        diskdb,
+		cleans:        cleans,
+		journalStatus: journalStatus,
 		dirties: map[common.Hash]*cachedNode{{}: {
 			children: make(map[common.Hash]uint16),
 		}},
@@ -854,10 +862,14 @@ func (db *Database) saveCache(dir string, threads int) error {
 	if db.cleans == nil {
 		return nil
 	}
+	if db.journalPaused.Load() {
+		log.Warn("Clean trie cache journal paused, not writing it to disk", "path", dir)
+		return nil
+	}
 	log.Info("Writing clean trie cache to disk", "path", dir, "threads", threads)
 
 	start := time.Now()
-	err := db.cleans.SaveToFileConcurrent(dir, threads)
+	err := saveCleanJournal(db.cleans, dir, threads)
 	if err != nil {
 		log.Error("Failed to persist clean trie cache", "error", err)
 		return err
@@ -866,12 +878,27 @@ func (db *Database) saveCache(dir string, threads int) error {
 	return nil
 }
 
+// CleanJournalStatus returns the outcome of the clean cache journal load.
+func (db *Database) CleanJournalStatus() CleanJournalStatus {
+	return db.journalStatus
+}
+
 // SaveCache atomically saves fast cache data to the given dir using all
 // available CPU cores.
 func (db *Database) SaveCache(dir string) error {
 	return db.saveCache(dir, runtime.GOMAXPROCS(0))
 }
 
+// PauseJournal stops saving the clean cache to disk, until ResumeJournal is called.
+func (db *Database) PauseJournal() {
+	db.journalPaused.Store(true)
+}
+
+// ResumeJournal resumes saving the clean cache to disk.
+func (db *Database) ResumeJournal() {
+	db.journalPaused.Store(false)
+}
+
 // SaveCachePeriodically atomically saves fast cache data to the given dir with
 // the specified interval. All dump operation will only use a single CPU core.
 func (db *Database) SaveCachePeriodically(dir string, interval time.Duration, stopCh <-chan struct{}) {